@@ -49,6 +49,7 @@ func (src *AzureCluster) ConvertTo(dstRaw conversion.Hub) error {
 	}
 
 	dst.Spec.NetworkSpec.PrivateDNSZoneName = restored.Spec.NetworkSpec.PrivateDNSZoneName
+	dst.Spec.NetworkSpec.SecurityRuleSets = restored.Spec.NetworkSpec.SecurityRuleSets
 
 	dst.Spec.NetworkSpec.APIServerLB.FrontendIPsCount = restored.Spec.NetworkSpec.APIServerLB.FrontendIPsCount
 	dst.Spec.NetworkSpec.APIServerLB.IdleTimeoutInMinutes = restored.Spec.NetworkSpec.APIServerLB.IdleTimeoutInMinutes
@@ -87,6 +88,14 @@ func (src *AzureCluster) ConvertTo(dstRaw conversion.Hub) error {
 				}
 				dst.Spec.NetworkSpec.Subnets[i].SecurityGroup.SecurityRules = append(dst.Spec.NetworkSpec.Subnets[i].SecurityGroup.SecurityRules, restoredOutboundRules...)
 				dst.Spec.NetworkSpec.Subnets[i].NatGateway = restoredSubnet.NatGateway
+				dst.Spec.NetworkSpec.Subnets[i].SecurityGroup.DefaultDenyOutbound = restoredSubnet.SecurityGroup.DefaultDenyOutbound
+				dst.Spec.NetworkSpec.Subnets[i].SecurityGroup.RuleSets = restoredSubnet.SecurityGroup.RuleSets
+				dst.Spec.NetworkSpec.Subnets[i].SecurityGroup.Location = restoredSubnet.SecurityGroup.Location
+				dst.Spec.NetworkSpec.Subnets[i].SecurityGroup.ResourceGroup = restoredSubnet.SecurityGroup.ResourceGroup
+				dst.Spec.NetworkSpec.Subnets[i].SecurityGroup.PriorityAssignment = restoredSubnet.SecurityGroup.PriorityAssignment
+				dst.Spec.NetworkSpec.Subnets[i].SecurityGroup.RuleNamePrefix = restoredSubnet.SecurityGroup.RuleNamePrefix
+				dst.Spec.NetworkSpec.Subnets[i].SecurityGroup.InheritedResourceGroupTags = restoredSubnet.SecurityGroup.InheritedResourceGroupTags
+				dst.Spec.NetworkSpec.Subnets[i].SecurityGroup.FlowLog = restoredSubnet.SecurityGroup.FlowLog
 
 				break
 			}
@@ -94,6 +103,10 @@ func (src *AzureCluster) ConvertTo(dstRaw conversion.Hub) error {
 	}
 
 	dst.Status.LongRunningOperationStates = restored.Status.LongRunningOperationStates
+	dst.Status.CompletedOperations = restored.Status.CompletedOperations
+	dst.Status.SecurityGroups = restored.Status.SecurityGroups
+	dst.Status.SecurityGroupsProgress = restored.Status.SecurityGroupsProgress
+	dst.Status.PendingChanges = restored.Status.PendingChanges
 
 	// Restore list of virtual network peerings
 	dst.Spec.NetworkSpec.Vnet.Peerings = restored.Spec.NetworkSpec.Vnet.Peerings
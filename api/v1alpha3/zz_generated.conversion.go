@@ -748,6 +748,10 @@ func autoConvert_v1beta1_AzureClusterStatus_To_v1alpha3_AzureClusterStatus(in *v
 		out.Conditions = nil
 	}
 	// WARNING: in.LongRunningOperationStates requires manual conversion: does not exist in peer-type
+	// WARNING: in.CompletedOperations requires manual conversion: does not exist in peer-type
+	// WARNING: in.SecurityGroups requires manual conversion: does not exist in peer-type
+	// WARNING: in.SecurityGroupsProgress requires manual conversion: does not exist in peer-type
+	// WARNING: in.PendingChanges requires manual conversion: does not exist in peer-type
 	return nil
 }
 
@@ -946,6 +950,7 @@ func autoConvert_v1beta1_AzureMachineStatus_To_v1alpha3_AzureMachineStatus(in *v
 		out.Conditions = nil
 	}
 	// WARNING: in.LongRunningOperationStates requires manual conversion: does not exist in peer-type
+	// WARNING: in.CompletedOperations requires manual conversion: does not exist in peer-type
 	return nil
 }
 
@@ -1257,6 +1262,9 @@ func autoConvert_v1beta1_Future_To_v1alpha3_Future(in *v1beta1.Future, out *Futu
 	// WARNING: in.ServiceName requires manual conversion: does not exist in peer-type
 	out.Name = in.Name
 	// WARNING: in.Data requires manual conversion: does not exist in peer-type
+	// WARNING: in.StartTime requires manual conversion: does not exist in peer-type
+	// WARNING: in.Status requires manual conversion: does not exist in peer-type
+	// WARNING: in.ParametersHash requires manual conversion: does not exist in peer-type
 	return nil
 }
 
@@ -42,6 +42,45 @@ func (src *AzureCluster) ConvertTo(dstRaw conversion.Hub) error {
 	// Restore list of virtual network peerings
 	dst.Spec.NetworkSpec.Vnet.Peerings = restored.Spec.NetworkSpec.Vnet.Peerings
 
+	// Restore the start time of long-running operations
+	dst.Status.LongRunningOperationStates = restored.Status.LongRunningOperationStates
+
+	// Restore the summary of the reconciled security groups
+	dst.Status.CompletedOperations = restored.Status.CompletedOperations
+	dst.Status.SecurityGroups = restored.Status.SecurityGroups
+	dst.Status.SecurityGroupsProgress = restored.Status.SecurityGroupsProgress
+	dst.Status.PendingChanges = restored.Status.PendingChanges
+
+	// Restore the port ranges, address prefixes and application security groups of security rules, and the default
+	// deny outbound option, the rule sets and the location of security groups
+	dst.Spec.NetworkSpec.SecurityRuleSets = restored.Spec.NetworkSpec.SecurityRuleSets
+	for i, subnet := range dst.Spec.NetworkSpec.Subnets {
+		for _, restoredSubnet := range restored.Spec.NetworkSpec.Subnets {
+			if subnet.Name == restoredSubnet.Name {
+				restoreSecurityRules(dst.Spec.NetworkSpec.Subnets[i].SecurityGroup.SecurityRules, restoredSubnet.SecurityGroup.SecurityRules)
+				dst.Spec.NetworkSpec.Subnets[i].SecurityGroup.DefaultDenyOutbound = restoredSubnet.SecurityGroup.DefaultDenyOutbound
+				dst.Spec.NetworkSpec.Subnets[i].SecurityGroup.RuleSets = restoredSubnet.SecurityGroup.RuleSets
+				dst.Spec.NetworkSpec.Subnets[i].SecurityGroup.Location = restoredSubnet.SecurityGroup.Location
+				dst.Spec.NetworkSpec.Subnets[i].SecurityGroup.ResourceGroup = restoredSubnet.SecurityGroup.ResourceGroup
+				dst.Spec.NetworkSpec.Subnets[i].SecurityGroup.PriorityAssignment = restoredSubnet.SecurityGroup.PriorityAssignment
+				dst.Spec.NetworkSpec.Subnets[i].SecurityGroup.RuleNamePrefix = restoredSubnet.SecurityGroup.RuleNamePrefix
+				dst.Spec.NetworkSpec.Subnets[i].SecurityGroup.InheritedResourceGroupTags = restoredSubnet.SecurityGroup.InheritedResourceGroupTags
+				dst.Spec.NetworkSpec.Subnets[i].SecurityGroup.FlowLog = restoredSubnet.SecurityGroup.FlowLog
+			}
+		}
+	}
+	if dst.Spec.BastionSpec.AzureBastion != nil && restored.Spec.BastionSpec.AzureBastion != nil {
+		restoreSecurityRules(dst.Spec.BastionSpec.AzureBastion.Subnet.SecurityGroup.SecurityRules, restored.Spec.BastionSpec.AzureBastion.Subnet.SecurityGroup.SecurityRules)
+		dst.Spec.BastionSpec.AzureBastion.Subnet.SecurityGroup.DefaultDenyOutbound = restored.Spec.BastionSpec.AzureBastion.Subnet.SecurityGroup.DefaultDenyOutbound
+		dst.Spec.BastionSpec.AzureBastion.Subnet.SecurityGroup.RuleSets = restored.Spec.BastionSpec.AzureBastion.Subnet.SecurityGroup.RuleSets
+		dst.Spec.BastionSpec.AzureBastion.Subnet.SecurityGroup.Location = restored.Spec.BastionSpec.AzureBastion.Subnet.SecurityGroup.Location
+		dst.Spec.BastionSpec.AzureBastion.Subnet.SecurityGroup.ResourceGroup = restored.Spec.BastionSpec.AzureBastion.Subnet.SecurityGroup.ResourceGroup
+		dst.Spec.BastionSpec.AzureBastion.Subnet.SecurityGroup.PriorityAssignment = restored.Spec.BastionSpec.AzureBastion.Subnet.SecurityGroup.PriorityAssignment
+		dst.Spec.BastionSpec.AzureBastion.Subnet.SecurityGroup.RuleNamePrefix = restored.Spec.BastionSpec.AzureBastion.Subnet.SecurityGroup.RuleNamePrefix
+		dst.Spec.BastionSpec.AzureBastion.Subnet.SecurityGroup.InheritedResourceGroupTags = restored.Spec.BastionSpec.AzureBastion.Subnet.SecurityGroup.InheritedResourceGroupTags
+		dst.Spec.BastionSpec.AzureBastion.Subnet.SecurityGroup.FlowLog = restored.Spec.BastionSpec.AzureBastion.Subnet.SecurityGroup.FlowLog
+	}
+
 	return nil
 }
 
@@ -261,7 +300,14 @@ func Convert_v1alpha4_SecurityGroup_To_v1beta1_SecurityGroup(in *SecurityGroup,
 	}
 
 	// Convert SecurityGroupClass fields
-	out.SecurityRules = *(*infrav1beta1.SecurityRules)(unsafe.Pointer(&in.SecurityRules))
+	if in.SecurityRules != nil {
+		out.SecurityRules = make(infrav1beta1.SecurityRules, len(in.SecurityRules))
+		for i := range in.SecurityRules {
+			if err := Convert_v1alpha4_SecurityRule_To_v1beta1_SecurityRule(&in.SecurityRules[i], &out.SecurityRules[i], s); err != nil {
+				return err
+			}
+		}
+	}
 	out.Tags = *(*infrav1beta1.Tags)(&in.Tags)
 
 	return nil
@@ -274,7 +320,14 @@ func Convert_v1beta1_SecurityGroup_To_v1alpha4_SecurityGroup(in *infrav1beta1.Se
 	}
 
 	// Convert SecurityGroupClass fields
-	out.SecurityRules = *(*SecurityRules)(unsafe.Pointer(&in.SecurityRules))
+	if in.SecurityRules != nil {
+		out.SecurityRules = make(SecurityRules, len(in.SecurityRules))
+		for i := range in.SecurityRules {
+			if err := Convert_v1beta1_SecurityRule_To_v1alpha4_SecurityRule(&in.SecurityRules[i], &out.SecurityRules[i], s); err != nil {
+				return err
+			}
+		}
+	}
 	out.Tags = *(*Tags)(&in.Tags)
 
 	return nil
@@ -299,3 +352,34 @@ func Convert_v1beta1_NatGateway_To_v1alpha4_NatGateway(in *infrav1beta1.NatGatew
 	out.Name = in.Name
 	return nil
 }
+
+// Convert_v1beta1_AzureClusterStatus_To_v1alpha4_AzureClusterStatus converts from the Hub version (v1beta1) of the AzureClusterStatus to this version.
+func Convert_v1beta1_AzureClusterStatus_To_v1alpha4_AzureClusterStatus(in *infrav1beta1.AzureClusterStatus, out *AzureClusterStatus, s apiconversion.Scope) error { //nolint
+	return autoConvert_v1beta1_AzureClusterStatus_To_v1alpha4_AzureClusterStatus(in, out, s)
+}
+
+// Convert_v1beta1_Future_To_v1alpha4_Future converts from the Hub version (v1beta1) of the Future to this version.
+func Convert_v1beta1_Future_To_v1alpha4_Future(in *infrav1beta1.Future, out *Future, s apiconversion.Scope) error { //nolint
+	return autoConvert_v1beta1_Future_To_v1alpha4_Future(in, out, s)
+}
+
+// Convert_v1beta1_SecurityRule_To_v1alpha4_SecurityRule converts from the Hub version (v1beta1) of the SecurityRule to this version.
+func Convert_v1beta1_SecurityRule_To_v1alpha4_SecurityRule(in *infrav1beta1.SecurityRule, out *SecurityRule, s apiconversion.Scope) error { //nolint
+	return autoConvert_v1beta1_SecurityRule_To_v1alpha4_SecurityRule(in, out, s)
+}
+
+// restoreSecurityRules restores the fields of the security rules that do not exist in this version.
+func restoreSecurityRules(dst, restored infrav1beta1.SecurityRules) {
+	for i := range dst {
+		if i < len(restored) && dst[i].Name == restored[i].Name {
+			dst[i].SourcePortRanges = restored[i].SourcePortRanges
+			dst[i].DestinationPortRanges = restored[i].DestinationPortRanges
+			dst[i].Sources = restored[i].Sources
+			dst[i].Destinations = restored[i].Destinations
+			dst[i].SourceApplicationSecurityGroups = restored[i].SourceApplicationSecurityGroups
+			dst[i].DestinationApplicationSecurityGroups = restored[i].DestinationApplicationSecurityGroups
+			dst[i].DestinationSubnet = restored[i].DestinationSubnet
+			dst[i].SourcesFrom = restored[i].SourcesFrom
+		}
+	}
+}
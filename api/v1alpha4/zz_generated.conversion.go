@@ -137,11 +137,6 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
-	if err := s.AddGeneratedConversionFunc((*v1beta1.AzureClusterStatus)(nil), (*AzureClusterStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1beta1_AzureClusterStatus_To_v1alpha4_AzureClusterStatus(a.(*v1beta1.AzureClusterStatus), b.(*AzureClusterStatus), scope)
-	}); err != nil {
-		return err
-	}
 	if err := s.AddGeneratedConversionFunc((*AzureMachine)(nil), (*v1beta1.AzureMachine)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha4_AzureMachine_To_v1beta1_AzureMachine(a.(*AzureMachine), b.(*v1beta1.AzureMachine), scope)
 	}); err != nil {
@@ -177,11 +172,6 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
-	if err := s.AddGeneratedConversionFunc((*v1beta1.AzureMachineStatus)(nil), (*AzureMachineStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1beta1_AzureMachineStatus_To_v1alpha4_AzureMachineStatus(a.(*v1beta1.AzureMachineStatus), b.(*AzureMachineStatus), scope)
-	}); err != nil {
-		return err
-	}
 	if err := s.AddGeneratedConversionFunc((*AzureMachineTemplate)(nil), (*v1beta1.AzureMachineTemplate)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha4_AzureMachineTemplate_To_v1beta1_AzureMachineTemplate(a.(*AzureMachineTemplate), b.(*v1beta1.AzureMachineTemplate), scope)
 	}); err != nil {
@@ -312,11 +302,6 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
-	if err := s.AddGeneratedConversionFunc((*v1beta1.Future)(nil), (*Future)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1beta1_Future_To_v1alpha4_Future(a.(*v1beta1.Future), b.(*Future), scope)
-	}); err != nil {
-		return err
-	}
 	if err := s.AddGeneratedConversionFunc((*Image)(nil), (*v1beta1.Image)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha4_Image_To_v1beta1_Image(a.(*Image), b.(*v1beta1.Image), scope)
 	}); err != nil {
@@ -402,11 +387,6 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
-	if err := s.AddGeneratedConversionFunc((*v1beta1.SecurityRule)(nil), (*SecurityRule)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1beta1_SecurityRule_To_v1alpha4_SecurityRule(a.(*v1beta1.SecurityRule), b.(*SecurityRule), scope)
-	}); err != nil {
-		return err
-	}
 	if err := s.AddGeneratedConversionFunc((*SpotVMOptions)(nil), (*v1beta1.SpotVMOptions)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha4_SpotVMOptions_To_v1beta1_SpotVMOptions(a.(*SpotVMOptions), b.(*v1beta1.SpotVMOptions), scope)
 	}); err != nil {
@@ -472,6 +452,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddConversionFunc((*v1beta1.AzureClusterStatus)(nil), (*AzureClusterStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_AzureClusterStatus_To_v1alpha4_AzureClusterStatus(a.(*v1beta1.AzureClusterStatus), b.(*AzureClusterStatus), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddConversionFunc((*v1beta1.AzureMachineStatus)(nil), (*AzureMachineStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_AzureMachineStatus_To_v1alpha4_AzureMachineStatus(a.(*v1beta1.AzureMachineStatus), b.(*AzureMachineStatus), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddConversionFunc((*v1beta1.AzureMachineTemplateResource)(nil), (*AzureMachineTemplateResource)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1beta1_AzureMachineTemplateResource_To_v1alpha4_AzureMachineTemplateResource(a.(*v1beta1.AzureMachineTemplateResource), b.(*AzureMachineTemplateResource), scope)
 	}); err != nil {
@@ -482,6 +472,11 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddConversionFunc((*v1beta1.Future)(nil), (*Future)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_Future_To_v1alpha4_Future(a.(*v1beta1.Future), b.(*Future), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddConversionFunc((*v1beta1.LoadBalancerSpec)(nil), (*LoadBalancerSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1beta1_LoadBalancerSpec_To_v1alpha4_LoadBalancerSpec(a.(*v1beta1.LoadBalancerSpec), b.(*LoadBalancerSpec), scope)
 	}); err != nil {
@@ -502,6 +497,11 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddConversionFunc((*v1beta1.SecurityRule)(nil), (*SecurityRule)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_SecurityRule_To_v1alpha4_SecurityRule(a.(*v1beta1.SecurityRule), b.(*SecurityRule), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddConversionFunc((*v1beta1.SubnetSpec)(nil), (*SubnetSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1beta1_SubnetSpec_To_v1alpha4_SubnetSpec(a.(*v1beta1.SubnetSpec), b.(*SubnetSpec), scope)
 	}); err != nil {
@@ -870,7 +870,17 @@ func autoConvert_v1alpha4_AzureClusterStatus_To_v1beta1_AzureClusterStatus(in *A
 	} else {
 		out.Conditions = nil
 	}
-	out.LongRunningOperationStates = *(*v1beta1.Futures)(unsafe.Pointer(&in.LongRunningOperationStates))
+	if in.LongRunningOperationStates != nil {
+		in, out := &in.LongRunningOperationStates, &out.LongRunningOperationStates
+		*out = make(v1beta1.Futures, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha4_Future_To_v1beta1_Future(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.LongRunningOperationStates = nil
+	}
 	return nil
 }
 
@@ -905,15 +915,24 @@ func autoConvert_v1beta1_AzureClusterStatus_To_v1alpha4_AzureClusterStatus(in *v
 	} else {
 		out.Conditions = nil
 	}
-	out.LongRunningOperationStates = *(*Futures)(unsafe.Pointer(&in.LongRunningOperationStates))
+	if in.LongRunningOperationStates != nil {
+		in, out := &in.LongRunningOperationStates, &out.LongRunningOperationStates
+		*out = make(Futures, len(*in))
+		for i := range *in {
+			if err := Convert_v1beta1_Future_To_v1alpha4_Future(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.LongRunningOperationStates = nil
+	}
+	// WARNING: in.CompletedOperations requires manual conversion: does not exist in peer-type
+	// WARNING: in.SecurityGroups requires manual conversion: does not exist in peer-type
+	// WARNING: in.SecurityGroupsProgress requires manual conversion: does not exist in peer-type
+	// WARNING: in.PendingChanges requires manual conversion: does not exist in peer-type
 	return nil
 }
 
-// Convert_v1beta1_AzureClusterStatus_To_v1alpha4_AzureClusterStatus is an autogenerated conversion function.
-func Convert_v1beta1_AzureClusterStatus_To_v1alpha4_AzureClusterStatus(in *v1beta1.AzureClusterStatus, out *AzureClusterStatus, s conversion.Scope) error {
-	return autoConvert_v1beta1_AzureClusterStatus_To_v1alpha4_AzureClusterStatus(in, out, s)
-}
-
 func autoConvert_v1alpha4_AzureMachine_To_v1beta1_AzureMachine(in *AzureMachine, out *v1beta1.AzureMachine, s conversion.Scope) error {
 	out.ObjectMeta = in.ObjectMeta
 	if err := Convert_v1alpha4_AzureMachineSpec_To_v1beta1_AzureMachineSpec(&in.Spec, &out.Spec, s); err != nil {
@@ -1061,7 +1080,17 @@ func autoConvert_v1alpha4_AzureMachineStatus_To_v1beta1_AzureMachineStatus(in *A
 	} else {
 		out.Conditions = nil
 	}
-	out.LongRunningOperationStates = *(*v1beta1.Futures)(unsafe.Pointer(&in.LongRunningOperationStates))
+	if in.LongRunningOperationStates != nil {
+		in, out := &in.LongRunningOperationStates, &out.LongRunningOperationStates
+		*out = make(v1beta1.Futures, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha4_Future_To_v1beta1_Future(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.LongRunningOperationStates = nil
+	}
 	return nil
 }
 
@@ -1087,15 +1116,21 @@ func autoConvert_v1beta1_AzureMachineStatus_To_v1alpha4_AzureMachineStatus(in *v
 	} else {
 		out.Conditions = nil
 	}
-	out.LongRunningOperationStates = *(*Futures)(unsafe.Pointer(&in.LongRunningOperationStates))
+	if in.LongRunningOperationStates != nil {
+		in, out := &in.LongRunningOperationStates, &out.LongRunningOperationStates
+		*out = make(Futures, len(*in))
+		for i := range *in {
+			if err := Convert_v1beta1_Future_To_v1alpha4_Future(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.LongRunningOperationStates = nil
+	}
+	// WARNING: in.CompletedOperations requires manual conversion: does not exist in peer-type
 	return nil
 }
 
-// Convert_v1beta1_AzureMachineStatus_To_v1alpha4_AzureMachineStatus is an autogenerated conversion function.
-func Convert_v1beta1_AzureMachineStatus_To_v1alpha4_AzureMachineStatus(in *v1beta1.AzureMachineStatus, out *AzureMachineStatus, s conversion.Scope) error {
-	return autoConvert_v1beta1_AzureMachineStatus_To_v1alpha4_AzureMachineStatus(in, out, s)
-}
-
 func autoConvert_v1alpha4_AzureMachineTemplate_To_v1beta1_AzureMachineTemplate(in *AzureMachineTemplate, out *v1beta1.AzureMachineTemplate, s conversion.Scope) error {
 	out.ObjectMeta = in.ObjectMeta
 	if err := Convert_v1alpha4_AzureMachineTemplateSpec_To_v1beta1_AzureMachineTemplateSpec(&in.Spec, &out.Spec, s); err != nil {
@@ -1492,14 +1527,12 @@ func autoConvert_v1beta1_Future_To_v1alpha4_Future(in *v1beta1.Future, out *Futu
 	out.ServiceName = in.ServiceName
 	out.Name = in.Name
 	out.Data = in.Data
+	// WARNING: in.StartTime requires manual conversion: does not exist in peer-type
+	// WARNING: in.Status requires manual conversion: does not exist in peer-type
+	// WARNING: in.ParametersHash requires manual conversion: does not exist in peer-type
 	return nil
 }
 
-// Convert_v1beta1_Future_To_v1alpha4_Future is an autogenerated conversion function.
-func Convert_v1beta1_Future_To_v1alpha4_Future(in *v1beta1.Future, out *Future, s conversion.Scope) error {
-	return autoConvert_v1beta1_Future_To_v1alpha4_Future(in, out, s)
-}
-
 func autoConvert_v1alpha4_Image_To_v1beta1_Image(in *Image, out *v1beta1.Image, s conversion.Scope) error {
 	out.ID = (*string)(unsafe.Pointer(in.ID))
 	out.SharedGallery = (*v1beta1.AzureSharedGalleryImage)(unsafe.Pointer(in.SharedGallery))
@@ -1873,14 +1906,17 @@ func autoConvert_v1beta1_SecurityRule_To_v1alpha4_SecurityRule(in *v1beta1.Secur
 	out.DestinationPorts = (*string)(unsafe.Pointer(in.DestinationPorts))
 	out.Source = (*string)(unsafe.Pointer(in.Source))
 	out.Destination = (*string)(unsafe.Pointer(in.Destination))
+	// WARNING: in.SourcePortRanges requires manual conversion: does not exist in peer-type
+	// WARNING: in.DestinationPortRanges requires manual conversion: does not exist in peer-type
+	// WARNING: in.Sources requires manual conversion: does not exist in peer-type
+	// WARNING: in.Destinations requires manual conversion: does not exist in peer-type
+	// WARNING: in.SourceApplicationSecurityGroups requires manual conversion: does not exist in peer-type
+	// WARNING: in.DestinationApplicationSecurityGroups requires manual conversion: does not exist in peer-type
+	// WARNING: in.DestinationSubnet requires manual conversion: does not exist in peer-type
+	// WARNING: in.SourcesFrom requires manual conversion: does not exist in peer-type
 	return nil
 }
 
-// Convert_v1beta1_SecurityRule_To_v1alpha4_SecurityRule is an autogenerated conversion function.
-func Convert_v1beta1_SecurityRule_To_v1alpha4_SecurityRule(in *v1beta1.SecurityRule, out *SecurityRule, s conversion.Scope) error {
-	return autoConvert_v1beta1_SecurityRule_To_v1alpha4_SecurityRule(in, out, s)
-}
-
 func autoConvert_v1alpha4_SpotVMOptions_To_v1beta1_SpotVMOptions(in *SpotVMOptions, out *v1beta1.SpotVMOptions, s conversion.Scope) error {
 	out.MaxPrice = (*resource.Quantity)(unsafe.Pointer(in.MaxPrice))
 	return nil
@@ -0,0 +1,88 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import "strings"
+
+// azureRegions are the names of the Azure regions of the public, US government and China clouds.
+var azureRegions = map[string]bool{
+	"australiacentral":   true,
+	"australiacentral2":  true,
+	"australiaeast":      true,
+	"australiasoutheast": true,
+	"brazilsouth":        true,
+	"brazilsoutheast":    true,
+	"canadacentral":      true,
+	"canadaeast":         true,
+	"centralindia":       true,
+	"centralus":          true,
+	"centraluseuap":      true,
+	"chinaeast":          true,
+	"chinaeast2":         true,
+	"chinaeast3":         true,
+	"chinanorth":         true,
+	"chinanorth2":        true,
+	"chinanorth3":        true,
+	"eastasia":           true,
+	"eastus":             true,
+	"eastus2":            true,
+	"eastus2euap":        true,
+	"francecentral":      true,
+	"francesouth":        true,
+	"germanynorth":       true,
+	"germanywestcentral": true,
+	"japaneast":          true,
+	"japanwest":          true,
+	"jioindiacentral":    true,
+	"jioindiawest":       true,
+	"koreacentral":       true,
+	"koreasouth":         true,
+	"northcentralus":     true,
+	"northeurope":        true,
+	"norwayeast":         true,
+	"norwaywest":         true,
+	"qatarcentral":       true,
+	"southafricanorth":   true,
+	"southafricawest":    true,
+	"southcentralus":     true,
+	"southeastasia":      true,
+	"southindia":         true,
+	"swedencentral":      true,
+	"switzerlandnorth":   true,
+	"switzerlandwest":    true,
+	"uaecentral":         true,
+	"uaenorth":           true,
+	"uksouth":            true,
+	"ukwest":             true,
+	"usdodcentral":       true,
+	"usdodeast":          true,
+	"usgovarizona":       true,
+	"usgovtexas":         true,
+	"usgovvirginia":      true,
+	"westcentralus":      true,
+	"westeurope":         true,
+	"westindia":          true,
+	"westus":             true,
+	"westus2":            true,
+	"westus3":            true,
+}
+
+// isAzureRegion returns true if the location is the name of an Azure region, e.g. "eastus", or its display name,
+// e.g. "East US".
+func isAzureRegion(location string) bool {
+	return azureRegions[strings.ToLower(strings.ReplaceAll(location, " ", ""))]
+}
@@ -74,6 +74,25 @@ type AzureClusterStatus struct {
 	// next reconciliation loop.
 	// +optional
 	LongRunningOperationStates Futures `json:"longRunningOperationStates,omitempty"`
+
+	// CompletedOperations records how long the long-running operations that recently completed took, for post-mortem
+	// inspection. Only the most recent operations are kept, for a short time.
+	// +optional
+	CompletedOperations []CompletedOperation `json:"completedOperations,omitempty"`
+
+	// SecurityGroups summarizes the rules programmed on the security groups of the cluster when they were last reconciled.
+	// +optional
+	SecurityGroups []SecurityGroupStatus `json:"securityGroups,omitempty"`
+
+	// SecurityGroupsProgress counts the security groups of the cluster by the outcome of their last reconcile.
+	// +optional
+	SecurityGroupsProgress *SecurityGroupsProgress `json:"securityGroupsProgress,omitempty"`
+
+	// PendingChanges lists the rule changes computed for the security groups of the cluster but not applied, while
+	// security groups are reconciled in dry-run mode. The changes of a security group are cleared once it is reconciled
+	// with its changes applied.
+	// +optional
+	PendingChanges []SecurityGroupPendingChanges `json:"pendingChanges,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -127,6 +146,16 @@ func (c *AzureCluster) SetFutures(futures Futures) {
 	c.Status.LongRunningOperationStates = futures
 }
 
+// GetCompletedOperations returns the recently completed long-running operations of an AzureCluster API object.
+func (c *AzureCluster) GetCompletedOperations() []CompletedOperation {
+	return c.Status.CompletedOperations
+}
+
+// SetCompletedOperations will set the given recently completed long-running operations on an AzureCluster object.
+func (c *AzureCluster) SetCompletedOperations(operations []CompletedOperation) {
+	c.Status.CompletedOperations = operations
+}
+
 func init() {
 	SchemeBuilder.Register(&AzureCluster{}, &AzureClusterList{})
 }
@@ -21,6 +21,7 @@ import (
 	"net"
 	"reflect"
 	"regexp"
+	"strings"
 
 	valid "github.com/asaskevich/govalidator"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -51,8 +52,61 @@ const (
 	// https://docs.microsoft.com/en-us/azure/virtual-network/network-security-groups-overview#security-rules
 	minRulePriority = 100
 	maxRulePriority = 4096
+	// DenyAllOutboundRulePriority is the priority reserved for the rule denying all outbound traffic of the security
+	// groups with DefaultDenyOutbound enabled. The rule is terminal: Azure stops at the first matching rule, so it has
+	// the lowest user precedence for every outbound allow rule to be evaluated before it. It still takes precedence
+	// over the default outbound rules of Azure, whose priorities start at 65000.
+	DenyAllOutboundRulePriority = maxRulePriority
+	// SecurityRuleNamePrefixRegex is the pattern of the prefixes of security rule names: a prefixed rule name must
+	// still start with a letter or a number and may contain letters, numbers, underscores, periods and hyphens.
+	// https://docs.microsoft.com/en-us/azure/azure-resource-manager/management/resource-name-rules#microsoftnetwork
+	SecurityRuleNamePrefixRegex = `^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`
+	// MaxSecurityRuleNameLength is the maximum length of a security rule name accepted by Azure, prefix included.
+	MaxSecurityRuleNameLength = 80
+	// maxSecurityRuleNamePrefixLength is the maximum length of a security rule name prefix.
+	maxSecurityRuleNamePrefixLength = 40
+	// MaxSecurityRuleDescriptionLength is the maximum length of a security rule description. Azure accepts 140
+	// characters, of which CAPZ takes 18 to mark the rules it manages.
+	MaxSecurityRuleDescriptionLength = 122
 )
 
+// securityRuleServiceTags are the Azure service tags that can be used as the source or destination of a security rule.
+// Tags can be scoped to a region, e.g. Storage.WestUS.
+// https://docs.microsoft.com/en-us/azure/virtual-network/service-tags-overview#available-service-tags
+var securityRuleServiceTags = []string{
+	"ActionGroup",
+	"ApiManagement",
+	"AppService",
+	"AppServiceManagement",
+	"AzureActiveDirectory",
+	"AzureBackup",
+	"AzureCloud",
+	"AzureConnectors",
+	"AzureContainerRegistry",
+	"AzureCosmosDB",
+	"AzureDatabricks",
+	"AzureDataLake",
+	"AzureEventGrid",
+	"AzureFrontDoor",
+	"AzureKeyVault",
+	"AzureLoadBalancer",
+	"AzureMachineLearning",
+	"AzureMonitor",
+	"AzureResourceManager",
+	"AzureTrafficManager",
+	"BatchNodeManagement",
+	"EventHub",
+	"GatewayManager",
+	"GuestAndHybridManagement",
+	"Internet",
+	"MicrosoftContainerRegistry",
+	"ServiceBus",
+	"ServiceFabric",
+	"Sql",
+	"Storage",
+	"VirtualNetwork",
+}
+
 // validateCluster validates a cluster.
 func (c *AzureCluster) validateCluster(old *AzureCluster) error {
 	var allErrs field.ErrorList
@@ -148,6 +202,15 @@ func validateNetworkSpec(networkSpec NetworkSpec, old NetworkSpec, fldPath *fiel
 
 	allErrs = append(allErrs, validatePrivateDNSZoneName(networkSpec.PrivateDNSZoneName, networkSpec.APIServerLB.Type, fldPath.Child("privateDNSZoneName"))...)
 
+	allErrs = append(allErrs, validateSecurityRuleSets(networkSpec.SecurityRuleSets, networkSpec.Subnets, fldPath)...)
+
+	for i, subnet := range networkSpec.Subnets {
+		if err := validateSecurityGroupLocation(subnet.SecurityGroup.Location, fldPath.Child("subnets").Index(i).Child("securityGroup").Child("location")); err != nil {
+			allErrs = append(allErrs, err)
+		}
+		allErrs = append(allErrs, validateSecurityRulePriorityAssignment(subnet.SecurityGroup.PriorityAssignment, fldPath.Child("subnets").Index(i).Child("securityGroup").Child("priorityAssignment"))...)
+	}
+
 	if len(allErrs) == 0 {
 		return nil
 	}
@@ -185,14 +248,10 @@ func validateSubnets(subnets Subnets, vnet VnetSpec, fldPath *field.Path) field.
 				requiredSubnetRoles[role] = true
 			}
 		}
-		for _, rule := range subnet.SecurityGroup.SecurityRules {
-			if err := validateSecurityRule(
-				rule,
-				fldPath.Index(i).Child("securityGroup").Child("securityRules").Index(i),
-			); err != nil {
-				allErrs = append(allErrs, err)
-			}
-		}
+		allErrs = append(allErrs, ValidateSecurityRules(subnet.SecurityGroup.SecurityRules, fldPath.Index(i).Child("securityGroup").Child("securityRules"))...)
+		allErrs = append(allErrs, validateDefaultDenyOutbound(subnet.SecurityGroup, fldPath.Index(i).Child("securityGroup"))...)
+		allErrs = append(allErrs, validateSecurityRuleNamePrefix(subnet.SecurityGroup, fldPath.Index(i).Child("securityGroup"))...)
+		allErrs = append(allErrs, validateDestinationSubnets(subnet.SecurityGroup, subnets, fldPath.Index(i).Child("securityGroup"))...)
 		allErrs = append(allErrs, validateSubnetCIDR(subnet.CIDRBlocks, vnet.CIDRBlocks, fldPath.Index(i).Child("cidrBlocks"))...)
 	}
 	for k, v := range requiredSubnetRoles {
@@ -204,6 +263,120 @@ func validateSubnets(subnets Subnets, vnet VnetSpec, fldPath *field.Path) field.
 	return allErrs
 }
 
+// validateDestinationSubnets validates that the rules of a security group with a destination subnet reference one of the
+// subnets of the cluster.
+func validateDestinationSubnets(securityGroup SecurityGroup, subnets Subnets, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	for i, rule := range securityGroup.SecurityRules {
+		if rule.DestinationSubnet == "" {
+			continue
+		}
+		found := false
+		for _, subnet := range subnets {
+			if subnet.Name == rule.DestinationSubnet {
+				found = true
+				break
+			}
+		}
+		if !found {
+			allErrs = append(allErrs, field.NotFound(fldPath.Child("securityRules").Index(i).Child("destinationSubnet"), rule.DestinationSubnet))
+		}
+	}
+	return allErrs
+}
+
+// validateSecurityRuleSets validates that the security rule sets have unique names and valid rules, and that the
+// security groups of the subnets only reference existing rule sets.
+func validateSecurityRuleSets(ruleSets []SecurityRuleSet, subnets Subnets, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	names := make(map[string]bool, len(ruleSets))
+	for i, ruleSet := range ruleSets {
+		ruleSetPath := fldPath.Child("securityRuleSets").Index(i)
+		if ruleSet.Name == "" {
+			allErrs = append(allErrs, field.Required(ruleSetPath.Child("name"), "security rule sets must have a name"))
+		} else if names[ruleSet.Name] {
+			allErrs = append(allErrs, field.Duplicate(ruleSetPath.Child("name"), ruleSet.Name))
+		}
+		names[ruleSet.Name] = true
+		allErrs = append(allErrs, ValidateSecurityRules(ruleSet.SecurityRules, ruleSetPath.Child("securityRules"))...)
+	}
+	for i, subnet := range subnets {
+		for j, name := range subnet.SecurityGroup.RuleSets {
+			if !names[name] {
+				allErrs = append(allErrs, field.NotFound(fldPath.Child("subnets").Index(i).Child("securityGroup").Child("ruleSets").Index(j), name))
+			}
+		}
+	}
+	return allErrs
+}
+
+// validateSecurityGroupLocation validates that the location of a security group, if set, is an Azure region.
+func validateSecurityGroupLocation(location string, fldPath *field.Path) *field.Error {
+	if location != "" && !isAzureRegion(location) {
+		return field.Invalid(fldPath, location, "location must be the name of an Azure region, e.g. eastus")
+	}
+	return nil
+}
+
+// validateSecurityRulePriorityAssignment validates that the priorities assigned to the security rules without a priority
+// start within the priorities Azure accepts for user rules.
+func validateSecurityRulePriorityAssignment(assignment *SecurityRulePriorityAssignment, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if assignment == nil {
+		return allErrs
+	}
+	if assignment.Step < 0 || assignment.Step > 1000 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("step"), assignment.Step, "step must be between 1 and 1000"))
+	}
+	if start := assignment.InboundStart; start != 0 && (start < 100 || start > 4096) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("inboundStart"), start, "inboundStart must be between 100 and 4096"))
+	}
+	if start := assignment.OutboundStart; start != 0 && (start < 100 || start > 4096) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("outboundStart"), start, "outboundStart must be between 100 and 4096"))
+	}
+	return allErrs
+}
+
+// validateDefaultDenyOutbound validates that no outbound rule of a security group with DefaultDenyOutbound enabled uses
+// the priority reserved for the deny all outbound rule.
+func validateDefaultDenyOutbound(securityGroup SecurityGroup, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if !securityGroup.DefaultDenyOutbound {
+		return allErrs
+	}
+	for i, rule := range securityGroup.SecurityRules {
+		if rule.Direction == SecurityRuleDirectionOutbound && rule.Priority == DenyAllOutboundRulePriority {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("securityRules").Index(i).Child("priority"),
+				fmt.Sprintf("priority %d is reserved for the deny all outbound rule when defaultDenyOutbound is enabled", DenyAllOutboundRulePriority)))
+		}
+	}
+	return allErrs
+}
+
+// validateSecurityRuleNamePrefix validates that the rule name prefix of a security group, if set, matches
+// SecurityRuleNamePrefixRegex and that the prefixed names of its rules are not longer than Azure accepts.
+func validateSecurityRuleNamePrefix(securityGroup SecurityGroup, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	prefix := securityGroup.RuleNamePrefix
+	if prefix == "" {
+		return allErrs
+	}
+	if len(prefix) > maxSecurityRuleNamePrefixLength {
+		return append(allErrs, field.TooLong(fldPath.Child("ruleNamePrefix"), prefix, maxSecurityRuleNamePrefixLength))
+	}
+	if success, _ := regexp.MatchString(SecurityRuleNamePrefixRegex, prefix); !success {
+		return append(allErrs, field.Invalid(fldPath.Child("ruleNamePrefix"), prefix,
+			fmt.Sprintf("ruleNamePrefix doesn't match regex %s", SecurityRuleNamePrefixRegex)))
+	}
+	for i, rule := range securityGroup.SecurityRules {
+		if len(prefix)+len(rule.Name) > MaxSecurityRuleNameLength {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("securityRules").Index(i).Child("name"), rule.Name,
+				fmt.Sprintf("name prefixed with %s is longer than %d characters", prefix, MaxSecurityRuleNameLength)))
+		}
+	}
+	return allErrs
+}
+
 // validateSubnetName validates the Name of a Subnet.
 func validateSubnetName(name string, fldPath *field.Path) *field.Error {
 	if success, _ := regexp.Match(subnetRegex, []byte(name)); !success {
@@ -298,15 +471,153 @@ func validateInternalLBIPAddress(address string, cidrs []string, fldPath *field.
 		fmt.Sprintf("Internal LB IP address needs to be in control plane subnet range (%s)", cidrs))
 }
 
+// ValidateSecurityRules validates the security rules of a security group: each rule must be valid on its own, and a
+// rule setting a priority must not share it with an earlier rule of the same direction. It is a pure function shared by
+// the webhooks and the reconciliation of security groups, so that the rules accepted at admission time are the ones
+// accepted when the security group is reconciled.
+func ValidateSecurityRules(rules SecurityRules, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	seen := make(map[SecurityRuleDirection]map[int32]string)
+	for i, rule := range rules {
+		if err := validateSecurityRule(rule, fldPath.Index(i)); err != nil {
+			allErrs = append(allErrs, err)
+			continue
+		}
+		// Rules without a priority are assigned one that is not taken when the security group is reconciled.
+		if rule.Priority == 0 {
+			continue
+		}
+		if seen[rule.Direction] == nil {
+			seen[rule.Direction] = make(map[int32]string)
+		}
+		if other, ok := seen[rule.Direction][rule.Priority]; ok {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i).Child("priority"), rule.Priority,
+				fmt.Sprintf("rules %s and %s have the same priority %d in direction %s", other, rule.Name, rule.Priority, rule.Direction)))
+			continue
+		}
+		seen[rule.Direction][rule.Priority] = rule.Name
+	}
+	return allErrs
+}
+
 // validateSecurityRule validates a SecurityRule.
 func validateSecurityRule(rule SecurityRule, fldPath *field.Path) *field.Error {
-	if rule.Priority < minRulePriority || rule.Priority > maxRulePriority {
+	// An unset priority is assigned when the security group is reconciled.
+	if rule.Priority != 0 && (rule.Priority < minRulePriority || rule.Priority > maxRulePriority) {
 		return field.Invalid(fldPath, rule.Priority, fmt.Sprintf("security rule priorities should be between %d and %d", minRulePriority, maxRulePriority))
 	}
+	if len(strings.TrimSpace(rule.Description)) > MaxSecurityRuleDescriptionLength {
+		return field.TooLong(fldPath.Child("description"), rule.Description, MaxSecurityRuleDescriptionLength)
+	}
+	if rule.SourcePorts != nil && len(rule.SourcePortRanges) > 0 {
+		return field.Forbidden(fldPath.Child("sourcePortRanges"), "security rules cannot set both sourcePorts and sourcePortRanges")
+	}
+	if rule.DestinationPorts != nil && len(rule.DestinationPortRanges) > 0 {
+		return field.Forbidden(fldPath.Child("destinationPortRanges"), "security rules cannot set both destinationPorts and destinationPortRanges")
+	}
+	if rule.Source != nil && len(rule.Sources) > 0 {
+		return field.Forbidden(fldPath.Child("sources"), "security rules cannot set both source and sources")
+	}
+	if rule.Destination != nil && len(rule.Destinations) > 0 {
+		return field.Forbidden(fldPath.Child("destinations"), "security rules cannot set both destination and destinations")
+	}
+	// A side of a rule is matched either by address prefixes or by application security groups.
+	if len(rule.SourceApplicationSecurityGroups) > 0 && (rule.Source != nil || len(rule.Sources) > 0) {
+		return field.Forbidden(fldPath.Child("sourceApplicationSecurityGroups"), "security rules cannot set both source address prefixes and sourceApplicationSecurityGroups")
+	}
+	if len(rule.DestinationApplicationSecurityGroups) > 0 && (rule.Destination != nil || len(rule.Destinations) > 0) {
+		return field.Forbidden(fldPath.Child("destinationApplicationSecurityGroups"), "security rules cannot set both destination address prefixes and destinationApplicationSecurityGroups")
+	}
+	if rule.DestinationSubnet != "" && (rule.Destination != nil || len(rule.Destinations) > 0 || len(rule.DestinationApplicationSecurityGroups) > 0) {
+		return field.Forbidden(fldPath.Child("destinationSubnet"), "security rules cannot set both destinationSubnet and another destination")
+	}
+	if rule.SourcesFrom != nil {
+		if err := validateSecurityRuleSourcesFrom(rule, fldPath.Child("sourcesFrom")); err != nil {
+			return err
+		}
+	}
+	if !rule.Protocol.HasPorts() {
+		// ICMP, ESP and AH have no ports, Azure only accepts a wildcard port range for their rules.
+		protocol := strings.ToUpper(string(rule.Protocol))
+		if !isWildcardPort(rule.SourcePorts) {
+			return field.Invalid(fldPath.Child("sourcePorts"), *rule.SourcePorts, fmt.Sprintf("%s security rules cannot have ports other than '*'", protocol))
+		}
+		if !isWildcardPort(rule.DestinationPorts) {
+			return field.Invalid(fldPath.Child("destinationPorts"), *rule.DestinationPorts, fmt.Sprintf("%s security rules cannot have ports other than '*'", protocol))
+		}
+		if len(rule.SourcePortRanges) > 0 {
+			return field.Forbidden(fldPath.Child("sourcePortRanges"), fmt.Sprintf("%s security rules cannot have port ranges", protocol))
+		}
+		if len(rule.DestinationPortRanges) > 0 {
+			return field.Forbidden(fldPath.Child("destinationPortRanges"), fmt.Sprintf("%s security rules cannot have port ranges", protocol))
+		}
+	}
+	if err := validateSecurityRuleAddress(rule.Source, fldPath.Child("source")); err != nil {
+		return err
+	}
+	if err := validateSecurityRuleAddress(rule.Destination, fldPath.Child("destination")); err != nil {
+		return err
+	}
+	for i := range rule.Sources {
+		if err := validateSecurityRuleAddress(&rule.Sources[i], fldPath.Child("sources").Index(i)); err != nil {
+			return err
+		}
+	}
+	for i := range rule.Destinations {
+		if err := validateSecurityRuleAddress(&rule.Destinations[i], fldPath.Child("destinations").Index(i)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
 
+// validateSecurityRuleSourcesFrom validates that a SecurityRule reading its sources from a ConfigMap or Secret sets no
+// other source and references the key of an object.
+func validateSecurityRuleSourcesFrom(rule SecurityRule, fldPath *field.Path) *field.Error {
+	if rule.Source != nil || len(rule.Sources) > 0 || len(rule.SourceApplicationSecurityGroups) > 0 {
+		return field.Forbidden(fldPath, "security rules cannot set both sourcesFrom and another source")
+	}
+	ref := rule.SourcesFrom
+	if ref.Kind != SecurityRuleSourcesFromConfigMap && ref.Kind != SecurityRuleSourcesFromSecret {
+		return field.NotSupported(fldPath.Child("kind"), ref.Kind, []string{SecurityRuleSourcesFromConfigMap, SecurityRuleSourcesFromSecret})
+	}
+	if ref.Name == "" {
+		return field.Required(fldPath.Child("name"), "the name of the object listing the sources is required")
+	}
+	if ref.Key == "" {
+		return field.Required(fldPath.Child("key"), "the key listing the sources is required")
+	}
 	return nil
 }
 
+// isWildcardPort returns true if the ports of a SecurityRule are unset or '*'.
+func isWildcardPort(ports *string) bool {
+	return ports == nil || *ports == "" || *ports == "*"
+}
+
+// validateSecurityRuleAddress validates that the source or destination of a SecurityRule is either '*', an IPv4 or IPv6
+// address, an IPv4 or IPv6 CIDR or a known service tag.
+func validateSecurityRuleAddress(address *string, fldPath *field.Path) *field.Error {
+	if address == nil || *address == "" || *address == "*" {
+		return nil
+	}
+	if net.ParseIP(*address) != nil {
+		return nil
+	}
+	if _, _, err := net.ParseCIDR(*address); err == nil {
+		return nil
+	}
+	// Regional service tags, e.g. Storage.WestUS, are scoped versions of the service tag before the period.
+	tag := strings.SplitN(*address, ".", 2)[0]
+	for _, serviceTag := range securityRuleServiceTags {
+		if strings.EqualFold(tag, serviceTag) {
+			return nil
+		}
+	}
+	return field.Invalid(fldPath, *address, "security rule addresses should be '*', an IPv4 or IPv6 address, an IPv4 or IPv6 CIDR or a known Azure service tag")
+}
+
 func validateAPIServerLB(lb LoadBalancerSpec, old LoadBalancerSpec, cidrs []string, fldPath *field.Path) field.ErrorList {
 	var allErrs field.ErrorList
 
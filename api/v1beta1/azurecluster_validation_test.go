@@ -17,6 +17,7 @@ limitations under the License.
 package v1beta1
 
 import (
+	"strings"
 	"testing"
 
 	. "github.com/onsi/gomega"
@@ -624,6 +625,14 @@ func TestValidateSecurityRule(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "security rule - unset priority",
+			validRule: SecurityRule{
+				Name:        "allow_apiserver",
+				Description: "Allow K8s API Server",
+			},
+			wantErr: false,
+		},
 		{
 			name: "security rule - invalid low priority",
 			validRule: SecurityRule{
@@ -642,6 +651,342 @@ func TestValidateSecurityRule(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "security rule - valid ICMP rule",
+			validRule: SecurityRule{
+				Name:        "allow_ping",
+				Description: "Allow ping",
+				Priority:    101,
+				Protocol:    SecurityGroupProtocolICMP,
+				SourcePorts: pointer.String("*"),
+				Source:      pointer.String("10.0.0.0/16"),
+			},
+			wantErr: false,
+		},
+		{
+			name: "security rule - ICMP rule with a port",
+			validRule: SecurityRule{
+				Name:             "allow_ping",
+				Description:      "Allow ping",
+				Priority:         101,
+				Protocol:         SecurityGroupProtocolICMP,
+				DestinationPorts: pointer.String("22"),
+			},
+			wantErr: true,
+		},
+		{
+			name: "security rule - ICMP rule with port ranges",
+			validRule: SecurityRule{
+				Name:                  "allow_ping",
+				Description:           "Allow ping",
+				Priority:              101,
+				Protocol:              SecurityGroupProtocolICMP,
+				DestinationPortRanges: []string{"80", "443"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "security rule - valid ESP rule",
+			validRule: SecurityRule{
+				Name:             "allow_ipsec_esp",
+				Description:      "Allow IPsec ESP",
+				Priority:         101,
+				Protocol:         SecurityGroupProtocolESP,
+				SourcePorts:      pointer.String("*"),
+				DestinationPorts: pointer.String("*"),
+				Source:           pointer.String("10.0.0.0/16"),
+			},
+			wantErr: false,
+		},
+		{
+			name: "security rule - ESP rule with a port",
+			validRule: SecurityRule{
+				Name:             "allow_ipsec_esp",
+				Description:      "Allow IPsec ESP",
+				Priority:         101,
+				Protocol:         SecurityGroupProtocolESP,
+				DestinationPorts: pointer.String("4500"),
+			},
+			wantErr: true,
+		},
+		{
+			name: "security rule - valid AH rule",
+			validRule: SecurityRule{
+				Name:        "allow_ipsec_ah",
+				Description: "Allow IPsec AH",
+				Priority:    101,
+				Protocol:    SecurityGroupProtocolAH,
+				Source:      pointer.String("10.0.0.0/16"),
+			},
+			wantErr: false,
+		},
+		{
+			name: "security rule - AH rule with port ranges",
+			validRule: SecurityRule{
+				Name:             "allow_ipsec_ah",
+				Description:      "Allow IPsec AH",
+				Priority:         101,
+				Protocol:         SecurityGroupProtocolAH,
+				SourcePortRanges: []string{"500", "4500"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "security rule - valid CIDR and IP address",
+			validRule: SecurityRule{
+				Name:        "allow_apiserver",
+				Description: "Allow K8s API Server",
+				Priority:    101,
+				Source:      pointer.String("10.0.0.0/16"),
+				Destination: pointer.String("10.1.0.4"),
+			},
+			wantErr: false,
+		},
+		{
+			name: "security rule - valid IPv6 CIDR and IP address",
+			validRule: SecurityRule{
+				Name:        "allow_apiserver",
+				Description: "Allow K8s API Server",
+				Priority:    101,
+				Source:      pointer.String("2001:db8::/64"),
+				Destination: pointer.String("2001:db8:1::4"),
+			},
+			wantErr: false,
+		},
+		{
+			name: "security rule - invalid IPv6 CIDR",
+			validRule: SecurityRule{
+				Name:        "allow_apiserver",
+				Description: "Allow K8s API Server",
+				Priority:    101,
+				Source:      pointer.String("2001:db8::/129"),
+			},
+			wantErr: true,
+		},
+		{
+			name: "security rule - valid dual-stack augmented rule",
+			validRule: SecurityRule{
+				Name:         "allow_nodes",
+				Description:  "Allow node to node traffic",
+				Priority:     101,
+				Sources:      []string{"10.0.0.0/16", "2001:db8::/64"},
+				Destinations: []string{"10.1.0.0/16", "2001:db8:1::/64"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "security rule - invalid IPv6 address in sources",
+			validRule: SecurityRule{
+				Name:        "allow_nodes",
+				Description: "Allow node to node traffic",
+				Priority:    101,
+				Sources:     []string{"10.0.0.0/16", "2001:db8:::1"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "security rule - valid service tags",
+			validRule: SecurityRule{
+				Name:        "allow_apiserver",
+				Description: "Allow K8s API Server",
+				Priority:    101,
+				Source:      pointer.String("AzureLoadBalancer"),
+				Destination: pointer.String("VirtualNetwork"),
+			},
+			wantErr: false,
+		},
+		{
+			name: "security rule - valid regional service tag",
+			validRule: SecurityRule{
+				Name:        "allow_storage",
+				Description: "Allow Storage",
+				Priority:    101,
+				Source:      pointer.String("*"),
+				Destination: pointer.String("Storage.WestUS"),
+			},
+			wantErr: false,
+		},
+		{
+			name: "security rule - invalid source",
+			validRule: SecurityRule{
+				Name:        "allow_apiserver",
+				Description: "Allow K8s API Server",
+				Priority:    101,
+				Source:      pointer.String("NotAServiceTag"),
+			},
+			wantErr: true,
+		},
+		{
+			name: "security rule - valid augmented rule",
+			validRule: SecurityRule{
+				Name:                  "allow_node_ports",
+				Description:           "Allow node ports",
+				Priority:              101,
+				SourcePorts:           pointer.String("*"),
+				DestinationPortRanges: []string{"80", "443", "30000-32767"},
+				Sources:               []string{"10.0.0.0/16", "10.1.0.0/16"},
+				Destination:           pointer.String("*"),
+			},
+			wantErr: false,
+		},
+		{
+			name: "security rule - destination ports set in both singular and plural forms",
+			validRule: SecurityRule{
+				Name:                  "allow_node_ports",
+				Description:           "Allow node ports",
+				Priority:              101,
+				DestinationPorts:      pointer.String("80"),
+				DestinationPortRanges: []string{"443"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "security rule - sources set in both singular and plural forms",
+			validRule: SecurityRule{
+				Name:        "allow_node_ports",
+				Description: "Allow node ports",
+				Priority:    101,
+				Source:      pointer.String("10.0.0.0/16"),
+				Sources:     []string{"10.1.0.0/16"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "security rule - invalid address in destinations",
+			validRule: SecurityRule{
+				Name:         "allow_node_ports",
+				Description:  "Allow node ports",
+				Priority:     101,
+				Destinations: []string{"10.1.0.0/16", "not-an-address"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "security rule - valid rule with application security groups",
+			validRule: SecurityRule{
+				Name:                                 "allow_web_to_db",
+				Description:                          "Allow web to db",
+				Priority:                             101,
+				SourcePorts:                          pointer.String("*"),
+				DestinationPorts:                     pointer.String("5432"),
+				SourceApplicationSecurityGroups:      []string{"/subscriptions/123/resourceGroups/test-rg/providers/Microsoft.Network/applicationSecurityGroups/web"},
+				DestinationApplicationSecurityGroups: []string{"/subscriptions/123/resourceGroups/test-rg/providers/Microsoft.Network/applicationSecurityGroups/db"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "security rule - source set as both address prefix and application security groups",
+			validRule: SecurityRule{
+				Name:                            "allow_web_to_db",
+				Description:                     "Allow web to db",
+				Priority:                        101,
+				Source:                          pointer.String("*"),
+				SourceApplicationSecurityGroups: []string{"/subscriptions/123/resourceGroups/test-rg/providers/Microsoft.Network/applicationSecurityGroups/web"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "security rule - destinations set as both address prefixes and application security groups",
+			validRule: SecurityRule{
+				Name:                                 "allow_web_to_db",
+				Description:                          "Allow web to db",
+				Priority:                             101,
+				Destinations:                         []string{"10.1.0.0/16"},
+				DestinationApplicationSecurityGroups: []string{"/subscriptions/123/resourceGroups/test-rg/providers/Microsoft.Network/applicationSecurityGroups/db"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "security rule - valid rule with destination subnet",
+			validRule: SecurityRule{
+				Name:              "allow_node_subnet",
+				Description:       "Allow HTTPS to the nodes",
+				Priority:          101,
+				DestinationPorts:  pointer.String("443"),
+				DestinationSubnet: "node-subnet",
+			},
+			wantErr: false,
+		},
+		{
+			name: "security rule - destination set as both address prefix and destination subnet",
+			validRule: SecurityRule{
+				Name:              "allow_node_subnet",
+				Description:       "Allow HTTPS to the nodes",
+				Priority:          101,
+				Destination:       pointer.String("10.1.0.0/16"),
+				DestinationSubnet: "node-subnet",
+			},
+			wantErr: true,
+		},
+		{
+			name: "security rule - invalid destination CIDR",
+			validRule: SecurityRule{
+				Name:        "allow_apiserver",
+				Description: "Allow K8s API Server",
+				Priority:    101,
+				Destination: pointer.String("10.0.0.0/33"),
+			},
+			wantErr: true,
+		},
+		{
+			name: "security rule - sources from a ConfigMap",
+			validRule: SecurityRule{
+				Name:        "allow_admins",
+				Description: "Allow the admin allowlist",
+				Priority:    101,
+				SourcesFrom: &SecurityRuleSourcesReference{Kind: SecurityRuleSourcesFromConfigMap, Name: "admin-allowlist", Key: "cidrs"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "security rule - sources from both a Secret and sources",
+			validRule: SecurityRule{
+				Name:        "allow_admins",
+				Description: "Allow the admin allowlist",
+				Priority:    101,
+				Sources:     []string{"10.0.0.0/16"},
+				SourcesFrom: &SecurityRuleSourcesReference{Kind: SecurityRuleSourcesFromSecret, Name: "admin-allowlist", Key: "cidrs"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "security rule - sources from an unsupported kind",
+			validRule: SecurityRule{
+				Name:        "allow_admins",
+				Description: "Allow the admin allowlist",
+				Priority:    101,
+				SourcesFrom: &SecurityRuleSourcesReference{Kind: "Pod", Name: "admin-allowlist", Key: "cidrs"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "security rule - sources from an object without a key",
+			validRule: SecurityRule{
+				Name:        "allow_admins",
+				Description: "Allow the admin allowlist",
+				Priority:    101,
+				SourcesFrom: &SecurityRuleSourcesReference{Kind: SecurityRuleSourcesFromConfigMap, Name: "admin-allowlist"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "security rule - longest description",
+			validRule: SecurityRule{
+				Name:        "allow_apiserver",
+				Description: strings.Repeat("a", MaxSecurityRuleDescriptionLength),
+				Priority:    101,
+			},
+			wantErr: false,
+		},
+		{
+			name: "security rule - description too long",
+			validRule: SecurityRule{
+				Name:        "allow_apiserver",
+				Description: strings.Repeat("a", MaxSecurityRuleDescriptionLength+1),
+				Priority:    101,
+			},
+			wantErr: true,
+		},
 	}
 	for _, testCase := range tests {
 		testCase := testCase
@@ -660,6 +1005,120 @@ func TestValidateSecurityRule(t *testing.T) {
 	}
 }
 
+func TestValidateDefaultDenyOutbound(t *testing.T) {
+	g := NewWithT(t)
+
+	outboundRule := func(priority int32) SecurityRule {
+		return SecurityRule{
+			Name:      "allow_outbound",
+			Direction: SecurityRuleDirectionOutbound,
+			Priority:  priority,
+		}
+	}
+
+	tests := []struct {
+		name          string
+		securityGroup SecurityGroup
+		wantErr       bool
+	}{
+		{
+			name: "default deny outbound - outbound rule below the reserved priority",
+			securityGroup: SecurityGroup{SecurityGroupClass: SecurityGroupClass{
+				DefaultDenyOutbound: true,
+				SecurityRules:       SecurityRules{outboundRule(4095)},
+			}},
+			wantErr: false,
+		},
+		{
+			name: "default deny outbound - outbound rule at the reserved priority",
+			securityGroup: SecurityGroup{SecurityGroupClass: SecurityGroupClass{
+				DefaultDenyOutbound: true,
+				SecurityRules:       SecurityRules{outboundRule(4096)},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "default deny outbound - inbound rule at the reserved priority",
+			securityGroup: SecurityGroup{SecurityGroupClass: SecurityGroupClass{
+				DefaultDenyOutbound: true,
+				SecurityRules: SecurityRules{{
+					Name:      "allow_inbound",
+					Direction: SecurityRuleDirectionInbound,
+					Priority:  4096,
+				}},
+			}},
+			wantErr: false,
+		},
+		{
+			name: "default deny outbound disabled - outbound rule at the reserved priority",
+			securityGroup: SecurityGroup{SecurityGroupClass: SecurityGroupClass{
+				SecurityRules: SecurityRules{outboundRule(4096)},
+			}},
+			wantErr: false,
+		},
+	}
+	for _, testCase := range tests {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			errs := validateDefaultDenyOutbound(
+				testCase.securityGroup,
+				field.NewPath("spec").Child("networkSpec").Child("subnets").Index(0).Child("securityGroup"),
+			)
+			if testCase.wantErr {
+				g.Expect(errs).NotTo(BeEmpty())
+			} else {
+				g.Expect(errs).To(BeEmpty())
+			}
+		})
+	}
+}
+
+func TestValidateDestinationSubnets(t *testing.T) {
+	g := NewWithT(t)
+
+	subnets := Subnets{
+		{Name: "control-plane-subnet", SubnetClassSpec: SubnetClassSpec{Role: SubnetControlPlane}},
+		{Name: "node-subnet", SubnetClassSpec: SubnetClassSpec{Role: SubnetNode}},
+	}
+	tests := []struct {
+		name              string
+		destinationSubnet string
+		wantErr           bool
+	}{
+		{
+			name:              "destination subnet of the cluster",
+			destinationSubnet: "node-subnet",
+			wantErr:           false,
+		},
+		{
+			name:              "destination subnet not of the cluster",
+			destinationSubnet: "other-subnet",
+			wantErr:           true,
+		},
+		{
+			name:              "no destination subnet",
+			destinationSubnet: "",
+			wantErr:           false,
+		},
+	}
+	for _, testCase := range tests {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			securityGroup := SecurityGroup{SecurityGroupClass: SecurityGroupClass{
+				SecurityRules: SecurityRules{{Name: "allow_node_subnet", DestinationSubnet: testCase.destinationSubnet}},
+			}}
+			errs := validateDestinationSubnets(securityGroup, subnets, field.NewPath("spec").Child("networkSpec").Child("subnets").Index(0).Child("securityGroup"))
+			if testCase.wantErr {
+				g.Expect(errs).NotTo(BeEmpty())
+			} else {
+				g.Expect(errs).To(BeEmpty())
+			}
+		})
+	}
+}
+
 func TestValidateAPIServerLB(t *testing.T) {
 	g := NewWithT(t)
 
@@ -1378,3 +1837,284 @@ func createValidAPIServerInternalLB() LoadBalancerSpec {
 		},
 	}
 }
+
+func TestValidateSecurityRuleSets(t *testing.T) {
+	baseline := SecurityRuleSet{
+		Name:          "baseline",
+		SecurityRules: SecurityRules{{Name: "allow_ssh", Priority: 2200}},
+	}
+	tests := []struct {
+		name     string
+		ruleSets []SecurityRuleSet
+		refs     []string
+		wantErr  bool
+	}{
+		{
+			name:     "referenced rule set exists",
+			ruleSets: []SecurityRuleSet{baseline},
+			refs:     []string{"baseline"},
+			wantErr:  false,
+		},
+		{
+			name:     "referenced rule set does not exist",
+			ruleSets: []SecurityRuleSet{baseline},
+			refs:     []string{"baseline", "monitoring"},
+			wantErr:  true,
+		},
+		{
+			name:     "rule sets with the same name",
+			ruleSets: []SecurityRuleSet{baseline, baseline},
+			wantErr:  true,
+		},
+		{
+			name:     "rule set without a name",
+			ruleSets: []SecurityRuleSet{{SecurityRules: baseline.SecurityRules}},
+			wantErr:  true,
+		},
+		{
+			name:     "rule set with an invalid rule",
+			ruleSets: []SecurityRuleSet{{Name: "baseline", SecurityRules: SecurityRules{{Name: "allow_ssh", Priority: 50}}}},
+			wantErr:  true,
+		},
+	}
+	for _, testCase := range tests {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			subnets := Subnets{
+				{Name: "control-plane-subnet", SecurityGroup: SecurityGroup{SecurityGroupClass: SecurityGroupClass{RuleSets: testCase.refs}}},
+			}
+			errs := validateSecurityRuleSets(testCase.ruleSets, subnets, field.NewPath("spec").Child("networkSpec"))
+			if testCase.wantErr {
+				g.Expect(errs).NotTo(BeEmpty())
+			} else {
+				g.Expect(errs).To(BeEmpty())
+			}
+		})
+	}
+}
+
+func TestValidateSecurityGroupLocation(t *testing.T) {
+	tests := []struct {
+		name     string
+		location string
+		wantErr  bool
+	}{
+		{
+			name:     "location is not set",
+			location: "",
+			wantErr:  false,
+		},
+		{
+			name:     "name of an Azure region",
+			location: "westus2",
+			wantErr:  false,
+		},
+		{
+			name:     "display name of an Azure region",
+			location: "West US 2",
+			wantErr:  false,
+		},
+		{
+			name:     "unknown region",
+			location: "westus9",
+			wantErr:  true,
+		},
+	}
+	for _, testCase := range tests {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			err := validateSecurityGroupLocation(testCase.location, field.NewPath("spec").Child("networkSpec").Child("subnets").Index(0).Child("securityGroup").Child("location"))
+			if testCase.wantErr {
+				g.Expect(err).NotTo(BeNil())
+			} else {
+				g.Expect(err).To(BeNil())
+			}
+		})
+	}
+}
+
+func TestValidateSecurityRuleNamePrefix(t *testing.T) {
+	g := NewWithT(t)
+
+	tests := []struct {
+		name          string
+		securityGroup SecurityGroup
+		wantErr       bool
+	}{
+		{
+			name:          "no prefix",
+			securityGroup: SecurityGroup{SecurityGroupClass: SecurityGroupClass{SecurityRules: SecurityRules{{Name: "allow_ssh"}}}},
+			wantErr:       false,
+		},
+		{
+			name: "valid prefix",
+			securityGroup: SecurityGroup{SecurityGroupClass: SecurityGroupClass{
+				RuleNamePrefix: "corp-k8s.",
+				SecurityRules:  SecurityRules{{Name: "allow_ssh"}},
+			}},
+			wantErr: false,
+		},
+		{
+			name:          "prefix starting with an underscore",
+			securityGroup: SecurityGroup{SecurityGroupClass: SecurityGroupClass{RuleNamePrefix: "_corp"}},
+			wantErr:       true,
+		},
+		{
+			name:          "prefix with a space",
+			securityGroup: SecurityGroup{SecurityGroupClass: SecurityGroupClass{RuleNamePrefix: "corp k8s"}},
+			wantErr:       true,
+		},
+		{
+			name:          "prefix too long",
+			securityGroup: SecurityGroup{SecurityGroupClass: SecurityGroupClass{RuleNamePrefix: strings.Repeat("a", 41)}},
+			wantErr:       true,
+		},
+		{
+			name: "prefixed rule name too long",
+			securityGroup: SecurityGroup{SecurityGroupClass: SecurityGroupClass{
+				RuleNamePrefix: strings.Repeat("a", 40),
+				SecurityRules:  SecurityRules{{Name: strings.Repeat("b", 41)}},
+			}},
+			wantErr: true,
+		},
+	}
+	for _, testCase := range tests {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			errs := validateSecurityRuleNamePrefix(
+				testCase.securityGroup,
+				field.NewPath("spec").Child("networkSpec").Child("subnets").Index(0).Child("securityGroup"),
+			)
+			if testCase.wantErr {
+				g.Expect(errs).NotTo(BeEmpty())
+			} else {
+				g.Expect(errs).To(BeEmpty())
+			}
+		})
+	}
+}
+
+func TestValidateSecurityRulePriorityAssignment(t *testing.T) {
+	tests := []struct {
+		name       string
+		assignment *SecurityRulePriorityAssignment
+		wantErr    bool
+	}{
+		{
+			name:       "priority assignment is not set",
+			assignment: nil,
+			wantErr:    false,
+		},
+		{
+			name:       "defaults",
+			assignment: &SecurityRulePriorityAssignment{},
+			wantErr:    false,
+		},
+		{
+			name:       "step and starts within the accepted priorities",
+			assignment: &SecurityRulePriorityAssignment{Step: 100, InboundStart: 1000, OutboundStart: 2000},
+			wantErr:    false,
+		},
+		{
+			name:       "negative step",
+			assignment: &SecurityRulePriorityAssignment{Step: -10},
+			wantErr:    true,
+		},
+		{
+			name:       "inbound start below the accepted priorities",
+			assignment: &SecurityRulePriorityAssignment{InboundStart: 99},
+			wantErr:    true,
+		},
+		{
+			name:       "outbound start above the accepted priorities",
+			assignment: &SecurityRulePriorityAssignment{OutboundStart: 4097},
+			wantErr:    true,
+		},
+	}
+	for _, testCase := range tests {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			errs := validateSecurityRulePriorityAssignment(testCase.assignment, field.NewPath("spec").Child("networkSpec").Child("subnets").Index(0).Child("securityGroup").Child("priorityAssignment"))
+			if testCase.wantErr {
+				g.Expect(errs).NotTo(BeEmpty())
+			} else {
+				g.Expect(errs).To(BeEmpty())
+			}
+		})
+	}
+}
+
+func TestValidateSecurityRules(t *testing.T) {
+	rule := func(name string, direction SecurityRuleDirection, priority int32) SecurityRule {
+		return SecurityRule{
+			Name:      name,
+			Protocol:  SecurityGroupProtocolTCP,
+			Direction: direction,
+			Priority:  priority,
+		}
+	}
+	tests := []struct {
+		name       string
+		rules      SecurityRules
+		wantFields []string
+	}{
+		{
+			name:  "no rules",
+			rules: nil,
+		},
+		{
+			name: "unique priorities within each direction",
+			rules: SecurityRules{
+				rule("in_a", SecurityRuleDirectionInbound, 100),
+				rule("out_a", SecurityRuleDirectionOutbound, 100),
+				rule("in_b", SecurityRuleDirectionInbound, 0),
+				rule("in_c", SecurityRuleDirectionInbound, 0),
+			},
+		},
+		{
+			name: "duplicate priority in the same direction",
+			rules: SecurityRules{
+				rule("in_a", SecurityRuleDirectionInbound, 100),
+				rule("in_b", SecurityRuleDirectionInbound, 100),
+			},
+			wantFields: []string{"securityRules[1].priority"},
+		},
+		{
+			name: "invalid rules are listed",
+			rules: SecurityRules{
+				rule("in_a", SecurityRuleDirectionInbound, 99),
+				func() SecurityRule {
+					icmp := rule("in_b", SecurityRuleDirectionInbound, 200)
+					icmp.Protocol = SecurityGroupProtocolICMP
+					icmp.DestinationPorts = pointer.StringPtr("22")
+					return icmp
+				}(),
+				func() SecurityRule {
+					cidr := rule("in_c", SecurityRuleDirectionInbound, 300)
+					cidr.Source = pointer.StringPtr("10.0.0.0/33")
+					return cidr
+				}(),
+			},
+			wantFields: []string{"securityRules[0]", "securityRules[1].destinationPorts", "securityRules[2].source"},
+		},
+	}
+	for _, testCase := range tests {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			var fields []string
+			for _, err := range ValidateSecurityRules(testCase.rules, field.NewPath("securityRules")) {
+				fields = append(fields, err.Field)
+			}
+			g.Expect(fields).To(Equal(testCase.wantFields))
+		})
+	}
+}
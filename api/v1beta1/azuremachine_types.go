@@ -183,6 +183,11 @@ type AzureMachineStatus struct {
 	// next reconciliation loop.
 	// +optional
 	LongRunningOperationStates Futures `json:"longRunningOperationStates,omitempty"`
+
+	// CompletedOperations records how long the long-running operations that recently completed took, for post-mortem
+	// inspection. Only the most recent operations are kept, for a short time.
+	// +optional
+	CompletedOperations []CompletedOperation `json:"completedOperations,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -236,6 +241,16 @@ func (m *AzureMachine) SetFutures(futures Futures) {
 	m.Status.LongRunningOperationStates = futures
 }
 
+// GetCompletedOperations returns the recently completed long-running operations of an AzureMachine API object.
+func (m *AzureMachine) GetCompletedOperations() []CompletedOperation {
+	return m.Status.CompletedOperations
+}
+
+// SetCompletedOperations will set the given recently completed long-running operations on an AzureMachine object.
+func (m *AzureMachine) SetCompletedOperations(operations []CompletedOperation) {
+	m.Status.CompletedOperations = operations
+}
+
 func init() {
 	SchemeBuilder.Register(&AzureMachine{}, &AzureMachineList{})
 }
@@ -129,6 +129,17 @@ const (
 	DeletedReason = "Deleted"
 	// DeletionFailedReason means the resource failed to be deleted.
 	DeletionFailedReason = "DeletionFailed"
+	// DeletionBlockedReason means the resource cannot be deleted until the resources that still reference it are released.
+	DeletionBlockedReason = "DeletionBlocked"
 	// UpdatingReason means the resource is being updated.
 	UpdatingReason = "Updating"
+	// AzureThrottledReason means the requests for the resource were throttled by Azure and will be retried.
+	AzureThrottledReason = "AzureThrottled"
+	// QuotaExceededReason means the resource was not created because it would exceed a quota of the subscription.
+	QuotaExceededReason = "QuotaExceeded"
+	// ConcurrencyLimitedReason means the operation on the resource was not started because too many Azure requests
+	// were in flight, and will be retried.
+	ConcurrencyLimitedReason = "ConcurrencyLimited"
+	// ResourceMustExistReason means the resource is only adopted by CAPZ and does not exist, so it must be created first.
+	ResourceMustExistReason = "ResourceMustExist"
 )
@@ -151,6 +151,13 @@ func SpecVersionHashTagKey() string {
 	return fmt.Sprintf("%s%s", NameAzureProviderPrefix, "spec-version-hash")
 }
 
+// OwnedSecurityRulesTagKey generates the key of the security group tag listing, comma-separated, the names of the
+// security rules owned by the cluster when the security group is shared with other writers. Lists too long for a
+// single tag value continue in tags whose key has the suffix "_1", "_2" and so on.
+func OwnedSecurityRulesTagKey(name string) string {
+	return fmt.Sprintf("%s%s%s", NameAzureProviderPrefix, "owned-security-rules_", name)
+}
+
 // ClusterTagKey generates the key for resources associated with a cluster.
 func ClusterTagKey(name string) string {
 	return fmt.Sprintf("%s%s", NameAzureProviderOwned, name)
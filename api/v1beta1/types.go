@@ -17,8 +17,11 @@ limitations under the License.
 package v1beta1
 
 import (
+	"fmt"
+
 	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 const (
@@ -44,7 +47,8 @@ const (
 
 // Future contains the data needed for an Azure long-running operation to continue across reconcile loops.
 type Future struct {
-	// Type describes the type of future, such as update, create, delete, etc.
+	// Type describes the type of request the future was derived from, which is PUT for creates and full updates,
+	// PATCH for partial updates that merge the parameters into the existing resource, or DELETE.
 	Type string `json:"type"`
 
 	// ResourceGroup is the Azure resource group for the resource.
@@ -61,6 +65,49 @@ type Future struct {
 
 	// Data is the base64 url encoded json Azure AutoRest Future.
 	Data string `json:"data"`
+
+	// StartTime is the time at which the long-running operation was started.
+	// Futures older than the configured TTL are considered stale and are reset.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// Status is the last known state of the long-running operation, such as InProgress or Succeeded.
+	// It is informational only, Data remains authoritative.
+	// +optional
+	Status string `json:"status,omitempty"`
+
+	// ParametersHash is the hash of the parameters the operation was started with.
+	// When set, an operation whose parameters no longer match the desired parameters is abandoned and restarted
+	// with the desired parameters.
+	// +optional
+	ParametersHash string `json:"parametersHash,omitempty"`
+}
+
+// CompletedOperation records how long a long-running operation took to complete. Completed operations are only kept
+// for a short time, for post-mortem inspection.
+type CompletedOperation struct {
+	// Type describes the type of request the operation was derived from, which is PUT, PATCH or DELETE.
+	Type string `json:"type"`
+
+	// ResourceGroup is the Azure resource group for the resource.
+	// +optional
+	ResourceGroup string `json:"resourceGroup,omitempty"`
+
+	// ServiceName is the name of the Azure service.
+	ServiceName string `json:"serviceName"`
+
+	// Name is the name of the Azure resource.
+	Name string `json:"name"`
+
+	// StartTime is the time at which the long-running operation was started.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime is the time at which the long-running operation was seen done.
+	CompletionTime metav1.Time `json:"completionTime"`
+
+	// Duration is the time the long-running operation took to complete.
+	Duration metav1.Duration `json:"duration"`
 }
 
 // NetworkSpec specifies what the Azure networking resources should look like.
@@ -185,8 +232,22 @@ const (
 	SecurityGroupProtocolUDP = SecurityGroupProtocol("Udp")
 	// SecurityGroupProtocolICMP represents the ICMP protocol.
 	SecurityGroupProtocolICMP = SecurityGroupProtocol("Icmp")
+	// SecurityGroupProtocolESP represents the ESP protocol of IPsec.
+	SecurityGroupProtocolESP = SecurityGroupProtocol("Esp")
+	// SecurityGroupProtocolAH represents the AH protocol of IPsec.
+	SecurityGroupProtocolAH = SecurityGroupProtocol("Ah")
 )
 
+// HasPorts returns false for the protocols that have no ports, i.e. ICMP, ESP and AH. Azure only accepts a wildcard
+// port range for rules of such protocols.
+func (p SecurityGroupProtocol) HasPorts() bool {
+	switch p {
+	case SecurityGroupProtocolICMP, SecurityGroupProtocolESP, SecurityGroupProtocolAH:
+		return false
+	}
+	return true
+}
+
 // SecurityRuleDirection defines the direction type for a security group rule.
 type SecurityRuleDirection string
 
@@ -204,13 +265,13 @@ type SecurityRule struct {
 	Name string `json:"name"`
 	// A description for this rule. Restricted to 140 chars.
 	Description string `json:"description"`
-	// Protocol specifies the protocol type. "Tcp", "Udp", "Icmp", or "*".
-	// +kubebuilder:validation:Enum=Tcp;Udp;Icmp;*
+	// Protocol specifies the protocol type. "Tcp", "Udp", "Icmp", "Esp", "Ah", or "*".
+	// +kubebuilder:validation:Enum=Tcp;Udp;Icmp;Esp;Ah;*
 	Protocol SecurityGroupProtocol `json:"protocol"`
 	// Direction indicates whether the rule applies to inbound, or outbound traffic. "Inbound" or "Outbound".
 	// +kubebuilder:validation:Enum=Inbound;Outbound
 	Direction SecurityRuleDirection `json:"direction"`
-	// Priority is a number between 100 and 4096. Each rule should have a unique value for priority. Rules are processed in priority order, with lower numbers processed before higher numbers. Once traffic matches a rule, processing stops.
+	// Priority is a number between 100 and 4096. Each rule should have a unique value for priority. Rules are processed in priority order, with lower numbers processed before higher numbers. Once traffic matches a rule, processing stops. When unset, a priority is assigned in declaration order within the rule's direction, starting at 100 in steps of 10 and skipping the priorities set explicitly.
 	// +optional
 	Priority int32 `json:"priority,omitempty"`
 	// SourcePorts specifies source port or range. Integer or range between 0 and 65535. Asterix '*' can also be used to match all ports.
@@ -225,11 +286,147 @@ type SecurityRule struct {
 	// Destination is the destination address prefix. CIDR or destination IP range. Asterix '*' can also be used to match all source IPs. Default tags such as 'VirtualNetwork', 'AzureLoadBalancer' and 'Internet' can also be used.
 	// +optional
 	Destination *string `json:"destination,omitempty"`
+	// SourcePortRanges specifies multiple source ports or ranges, making this an augmented security rule. It cannot be used together with SourcePorts.
+	// +optional
+	SourcePortRanges []string `json:"sourcePortRanges,omitempty"`
+	// DestinationPortRanges specifies multiple destination ports or ranges, making this an augmented security rule. It cannot be used together with DestinationPorts.
+	// +optional
+	DestinationPortRanges []string `json:"destinationPortRanges,omitempty"`
+	// Sources specifies multiple source CIDRs or IP ranges, making this an augmented security rule. IPv4 and IPv6 prefixes can be mixed, e.g. for dual-stack clusters. It cannot be used together with Source.
+	// +optional
+	Sources []string `json:"sources,omitempty"`
+	// Destinations specifies multiple destination CIDRs or IP ranges, making this an augmented security rule. IPv4 and IPv6 prefixes can be mixed, e.g. for dual-stack clusters. It cannot be used together with Destination.
+	// +optional
+	Destinations []string `json:"destinations,omitempty"`
+	// SourceApplicationSecurityGroups specifies the IDs of the application security groups traffic originates from, so that the rule follows the membership of the groups rather than fixed addresses. It cannot be used together with Source or Sources.
+	// +optional
+	SourceApplicationSecurityGroups []string `json:"sourceApplicationSecurityGroups,omitempty"`
+	// DestinationApplicationSecurityGroups specifies the IDs of the application security groups traffic is sent to, so that the rule follows the membership of the groups rather than fixed addresses. It cannot be used together with Destination or Destinations.
+	// +optional
+	DestinationApplicationSecurityGroups []string `json:"destinationApplicationSecurityGroups,omitempty"`
+	// DestinationSubnet is the name of a subnet of the cluster whose CIDR blocks are the destination of the rule. The CIDR blocks are resolved when the security group is reconciled so that the rule follows changes of the subnet. A subnet with multiple CIDR blocks makes this an augmented security rule. It cannot be used together with Destination, Destinations or DestinationApplicationSecurityGroups.
+	// +optional
+	DestinationSubnet string `json:"destinationSubnet,omitempty"`
+	// SourcesFrom references a ConfigMap or Secret listing the source CIDRs or IP ranges of the rule, e.g. an allowlist maintained outside of the cluster spec. The list is read again every time the security group is reconciled so that the rule follows its changes. It cannot be used together with Source, Sources or SourceApplicationSecurityGroups.
+	// +optional
+	SourcesFrom *SecurityRuleSourcesReference `json:"sourcesFrom,omitempty"`
 }
 
 // SecurityRules is a slice of Azure security rules for security groups.
 type SecurityRules []SecurityRule
 
+// SecurityRuleSourcesReference references the key of a ConfigMap or Secret listing the source CIDRs or IP ranges of a
+// security rule, separated by commas, spaces or new lines. The object is in the namespace of the AzureCluster, so that
+// an AzureCluster cannot reference objects of other namespaces.
+type SecurityRuleSourcesReference struct {
+	// Kind is the kind of the object, ConfigMap or Secret.
+	// +kubebuilder:validation:Enum=ConfigMap;Secret
+	Kind string `json:"kind"`
+	// Name is the name of the object.
+	Name string `json:"name"`
+	// Key is the key of the data of the object listing the sources.
+	Key string `json:"key"`
+}
+
+const (
+	// SecurityRuleSourcesFromConfigMap is the kind of a ConfigMap referenced by SecurityRuleSourcesReference.
+	SecurityRuleSourcesFromConfigMap = "ConfigMap"
+	// SecurityRuleSourcesFromSecret is the kind of a Secret referenced by SecurityRuleSourcesReference.
+	SecurityRuleSourcesFromSecret = "Secret"
+)
+
+// SecurityRuleSet is a named set of security rules shared by several security groups.
+type SecurityRuleSet struct {
+	// Name is the name the security groups reference the rule set by.
+	Name string `json:"name"`
+	// SecurityRules are the security rules added to the security groups referencing the rule set.
+	// +optional
+	SecurityRules SecurityRules `json:"securityRules,omitempty"`
+}
+
+// SecurityGroupStatus summarizes the rules programmed on a security group reconciled by CAPZ.
+type SecurityGroupStatus struct {
+	// Name is the name of the security group.
+	Name string `json:"name"`
+
+	// Rules are the rules managed by CAPZ found on the security group after it was last reconciled.
+	// +optional
+	Rules []SecurityRuleStatus `json:"rules,omitempty"`
+}
+
+// SecurityRuleStatus summarizes a security rule programmed on a security group.
+type SecurityRuleStatus struct {
+	// Name is the name of the rule.
+	Name string `json:"name"`
+
+	// Priority is the priority of the rule.
+	// +optional
+	Priority int32 `json:"priority,omitempty"`
+
+	// Direction indicates whether the rule applies to inbound, or outbound traffic.
+	// +optional
+	Direction SecurityRuleDirection `json:"direction,omitempty"`
+
+	// Protocol is the protocol the rule applies to.
+	// +optional
+	Protocol SecurityGroupProtocol `json:"protocol,omitempty"`
+
+	// SourcePorts are the source ports or ranges the rule applies to.
+	// +optional
+	SourcePorts []string `json:"sourcePorts,omitempty"`
+
+	// DestinationPorts are the destination ports or ranges the rule applies to.
+	// +optional
+	DestinationPorts []string `json:"destinationPorts,omitempty"`
+
+	// ETag is the entity tag of the rule in Azure, which changes every time the rule is updated.
+	// +optional
+	ETag string `json:"etag,omitempty"`
+
+	// LastAppliedTime is when the rule was last found created or updated on the security group, i.e. when the PUT of
+	// the security group that last changed the rule completed.
+	// +optional
+	LastAppliedTime *metav1.Time `json:"lastAppliedTime,omitempty"`
+}
+
+// SecurityGroupsProgress counts the security groups of a cluster by the outcome of their last reconcile.
+type SecurityGroupsProgress struct {
+	// Total is the number of security groups that were reconciled.
+	Total int32 `json:"total"`
+
+	// Ready is the number of security groups that are created and up to date.
+	Ready int32 `json:"ready"`
+
+	// InProgress is the number of security groups whose create or update is still in progress.
+	InProgress int32 `json:"inProgress"`
+
+	// Failed is the number of security groups that failed to be created or updated.
+	Failed int32 `json:"failed"`
+}
+
+// String returns a summary of the progress, e.g. "3/5 security groups ready, 1 in progress, 1 failed".
+func (p SecurityGroupsProgress) String() string {
+	return fmt.Sprintf("%d/%d security groups ready, %d in progress, %d failed", p.Ready, p.Total, p.InProgress, p.Failed)
+}
+
+// SecurityGroupPendingChanges is the rule diff of a security group computed in dry-run mode but not applied.
+type SecurityGroupPendingChanges struct {
+	// Name is the name of the security group.
+	Name string `json:"name"`
+
+	// AddedRules are the names of the rules that would be created.
+	// +optional
+	AddedRules []string `json:"addedRules,omitempty"`
+
+	// RemovedRules are the names of the rules that would be deleted.
+	// +optional
+	RemovedRules []string `json:"removedRules,omitempty"`
+
+	// ModifiedRules are the names of the existing rules that would be updated.
+	// +optional
+	ModifiedRules []string `json:"modifiedRules,omitempty"`
+}
+
 // LoadBalancerSpec defines an Azure load balancer.
 type LoadBalancerSpec struct {
 	// ID is the Azure resource ID of the load balancer.
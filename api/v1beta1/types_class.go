@@ -57,6 +57,11 @@ type NetworkClassSpec struct {
 	// PrivateDNSZoneName defines the zone name for the Azure Private DNS.
 	// +optional
 	PrivateDNSZoneName string `json:"privateDNSZoneName,omitempty"`
+
+	// SecurityRuleSets are named sets of security rules defined once and shared by the security groups that reference
+	// them in their ruleSets, e.g. baseline rules of both the control plane and the node security groups.
+	// +optional
+	SecurityRuleSets []SecurityRuleSet `json:"securityRuleSets,omitempty"`
 }
 
 // VnetClassSpec defines the VnetSpec properties that may be shared across several Azure clusters.
@@ -96,10 +101,86 @@ type LoadBalancerClassSpec struct {
 type SecurityGroupClass struct {
 	// +optional
 	SecurityRules SecurityRules `json:"securityRules,omitempty"`
+	// DefaultDenyOutbound, when true, adds a rule denying all outbound traffic at priority 4096, which is reserved for
+	// it, so that only the outbound traffic allowed by the security rules is permitted. Rules are evaluated by
+	// increasing priority number until one matches, so the deny rule has the last user priority for the outbound allow
+	// rules to be evaluated before it, while still taking precedence over the default rules of Azure allowing outbound
+	// traffic.
+	// +optional
+	DefaultDenyOutbound bool `json:"defaultDenyOutbound,omitempty"`
+	// RuleSets are the names of the security rule sets of the network spec whose rules are added to the security
+	// group, in order. A security rule of the security group with the name of a rule of a rule set overrides it, as
+	// does a rule of a later rule set.
+	// +optional
+	RuleSets []string `json:"ruleSets,omitempty"`
+	// Location is the Azure region of the security group, e.g. for a multi-region setup. It defaults to the location
+	// of the cluster.
+	// +optional
+	Location string `json:"location,omitempty"`
+	// ResourceGroup is the resource group of the security group, e.g. for security groups managed by a network
+	// team. It defaults to the resource group of the cluster. Security groups outside of the resource group of the
+	// cluster are not deleted with it and are deleted one by one instead.
+	// +optional
+	ResourceGroup string `json:"resourceGroup,omitempty"`
+	// PriorityAssignment configures the priorities assigned to the security rules without a priority.
+	// +optional
+	PriorityAssignment *SecurityRulePriorityAssignment `json:"priorityAssignment,omitempty"`
+	// RuleNamePrefix is prepended to the names of the security rules created in Azure, e.g. to follow the naming
+	// conventions of an organization. Rules are recognized as managed by CAPZ from the marker at the end of their
+	// description rather than from their name, so the prefix can be changed: the rules are then renamed.
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`
+	// +kubebuilder:validation:MaxLength=40
+	// +optional
+	RuleNamePrefix string `json:"ruleNamePrefix,omitempty"`
+	// InheritedResourceGroupTags are the keys of the tags of the resource group of the security group that are added to
+	// the security group, e.g. for the cost center tags of organizations relying on tag inheritance. The tags of the
+	// resource group are read on every reconcile, so that the security group is updated when they change. The
+	// additional tags of the cluster take precedence over the inherited tags with the same key.
+	// +optional
+	InheritedResourceGroupTags []string `json:"inheritedResourceGroupTags,omitempty"`
+	// FlowLog, if set, enables the flow logs of the security group. The flow log is deleted with the security group, or
+	// with the cluster.
+	// +optional
+	FlowLog *SecurityGroupFlowLog `json:"flowLog,omitempty"`
 	// +optional
 	Tags Tags `json:"tags,omitempty"`
 }
 
+// SecurityGroupFlowLog configures the flow log of a security group. Flow logs are created in the network watcher of
+// the region of the security group.
+type SecurityGroupFlowLog struct {
+	// StorageAccountID is the resource ID of the storage account the flow log records are written to. It must be in the
+	// region of the security group.
+	StorageAccountID string `json:"storageAccountID"`
+	// RetentionDays is the number of days the flow log records are kept for. The records are kept forever when zero.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=365
+	// +optional
+	RetentionDays int32 `json:"retentionDays,omitempty"`
+}
+
+// SecurityRulePriorityAssignment configures the priorities assigned to the security rules without a priority. They are
+// numbered in declaration order within each direction, from the start of the direction in steps of Step. The priorities
+// between two assigned priorities are never assigned, so that rules with an explicit priority can be inserted between
+// rules without a priority later on without renumbering them.
+type SecurityRulePriorityAssignment struct {
+	// Step is the gap between the priorities assigned to consecutive rules of the same direction. Defaults to 10.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=1000
+	// +optional
+	Step int32 `json:"step,omitempty"`
+	// InboundStart is the priority assigned to the first inbound rule without a priority. Defaults to 100.
+	// +kubebuilder:validation:Minimum=100
+	// +kubebuilder:validation:Maximum=4096
+	// +optional
+	InboundStart int32 `json:"inboundStart,omitempty"`
+	// OutboundStart is the priority assigned to the first outbound rule without a priority. Defaults to 100.
+	// +kubebuilder:validation:Minimum=100
+	// +kubebuilder:validation:Maximum=4096
+	// +optional
+	OutboundStart int32 `json:"outboundStart,omitempty"`
+}
+
 // FrontendIPClass defines the FrontendIP properties that may be shared across several Azure clusters.
 type FrontendIPClass struct {
 	// +optional
@@ -334,7 +334,35 @@ func (in *AzureClusterStatus) DeepCopyInto(out *AzureClusterStatus) {
 	if in.LongRunningOperationStates != nil {
 		in, out := &in.LongRunningOperationStates, &out.LongRunningOperationStates
 		*out = make(Futures, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CompletedOperations != nil {
+		in, out := &in.CompletedOperations, &out.CompletedOperations
+		*out = make([]CompletedOperation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SecurityGroups != nil {
+		in, out := &in.SecurityGroups, &out.SecurityGroups
+		*out = make([]SecurityGroupStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SecurityGroupsProgress != nil {
+		in, out := &in.SecurityGroupsProgress, &out.SecurityGroupsProgress
+		*out = new(SecurityGroupsProgress)
+		**out = **in
+	}
+	if in.PendingChanges != nil {
+		in, out := &in.PendingChanges, &out.PendingChanges
+		*out = make([]SecurityGroupPendingChanges, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
@@ -613,7 +641,16 @@ func (in *AzureMachineStatus) DeepCopyInto(out *AzureMachineStatus) {
 	if in.LongRunningOperationStates != nil {
 		in, out := &in.LongRunningOperationStates, &out.LongRunningOperationStates
 		*out = make(Futures, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CompletedOperations != nil {
+		in, out := &in.CompletedOperations, &out.CompletedOperations
+		*out = make([]CompletedOperation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
@@ -883,6 +920,27 @@ func (in *CloudProviderConfigOverrides) DeepCopy() *CloudProviderConfigOverrides
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CompletedOperation) DeepCopyInto(out *CompletedOperation) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	in.CompletionTime.DeepCopyInto(&out.CompletionTime)
+	out.Duration = in.Duration
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CompletedOperation.
+func (in *CompletedOperation) DeepCopy() *CompletedOperation {
+	if in == nil {
+		return nil
+	}
+	out := new(CompletedOperation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DataDisk) DeepCopyInto(out *DataDisk) {
 	*out = *in
@@ -977,6 +1035,10 @@ func (in *FrontendIPClass) DeepCopy() *FrontendIPClass {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Future) DeepCopyInto(out *Future) {
 	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Future.
@@ -994,7 +1056,9 @@ func (in Futures) DeepCopyInto(out *Futures) {
 	{
 		in := &in
 		*out = make(Futures, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
@@ -1141,6 +1205,13 @@ func (in *NatGatewayClassSpec) DeepCopy() *NatGatewayClassSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NetworkClassSpec) DeepCopyInto(out *NetworkClassSpec) {
 	*out = *in
+	if in.SecurityRuleSets != nil {
+		in, out := &in.SecurityRuleSets, &out.SecurityRuleSets
+		*out = make([]SecurityRuleSet, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkClassSpec.
@@ -1175,7 +1246,7 @@ func (in *NetworkSpec) DeepCopyInto(out *NetworkSpec) {
 		*out = new(LoadBalancerSpec)
 		(*in).DeepCopyInto(*out)
 	}
-	out.NetworkClassSpec = in.NetworkClassSpec
+	in.NetworkClassSpec.DeepCopyInto(&out.NetworkClassSpec)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkSpec.
@@ -1191,7 +1262,7 @@ func (in *NetworkSpec) DeepCopy() *NetworkSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NetworkTemplateSpec) DeepCopyInto(out *NetworkTemplateSpec) {
 	*out = *in
-	out.NetworkClassSpec = in.NetworkClassSpec
+	in.NetworkClassSpec.DeepCopyInto(&out.NetworkClassSpec)
 	in.Vnet.DeepCopyInto(&out.Vnet)
 	if in.Subnets != nil {
 		in, out := &in.Subnets, &out.Subnets
@@ -1350,6 +1421,26 @@ func (in *SecurityGroupClass) DeepCopyInto(out *SecurityGroupClass) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.RuleSets != nil {
+		in, out := &in.RuleSets, &out.RuleSets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PriorityAssignment != nil {
+		in, out := &in.PriorityAssignment, &out.PriorityAssignment
+		*out = new(SecurityRulePriorityAssignment)
+		**out = **in
+	}
+	if in.InheritedResourceGroupTags != nil {
+		in, out := &in.InheritedResourceGroupTags, &out.InheritedResourceGroupTags
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.FlowLog != nil {
+		in, out := &in.FlowLog, &out.FlowLog
+		*out = new(SecurityGroupFlowLog)
+		**out = **in
+	}
 	if in.Tags != nil {
 		in, out := &in.Tags, &out.Tags
 		*out = make(Tags, len(*in))
@@ -1369,6 +1460,88 @@ func (in *SecurityGroupClass) DeepCopy() *SecurityGroupClass {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecurityGroupFlowLog) DeepCopyInto(out *SecurityGroupFlowLog) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecurityGroupFlowLog.
+func (in *SecurityGroupFlowLog) DeepCopy() *SecurityGroupFlowLog {
+	if in == nil {
+		return nil
+	}
+	out := new(SecurityGroupFlowLog)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecurityGroupPendingChanges) DeepCopyInto(out *SecurityGroupPendingChanges) {
+	*out = *in
+	if in.AddedRules != nil {
+		in, out := &in.AddedRules, &out.AddedRules
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RemovedRules != nil {
+		in, out := &in.RemovedRules, &out.RemovedRules
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ModifiedRules != nil {
+		in, out := &in.ModifiedRules, &out.ModifiedRules
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecurityGroupPendingChanges.
+func (in *SecurityGroupPendingChanges) DeepCopy() *SecurityGroupPendingChanges {
+	if in == nil {
+		return nil
+	}
+	out := new(SecurityGroupPendingChanges)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecurityGroupStatus) DeepCopyInto(out *SecurityGroupStatus) {
+	*out = *in
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]SecurityRuleStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecurityGroupStatus.
+func (in *SecurityGroupStatus) DeepCopy() *SecurityGroupStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SecurityGroupStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecurityGroupsProgress) DeepCopyInto(out *SecurityGroupsProgress) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecurityGroupsProgress.
+func (in *SecurityGroupsProgress) DeepCopy() *SecurityGroupsProgress {
+	if in == nil {
+		return nil
+	}
+	out := new(SecurityGroupsProgress)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SecurityProfile) DeepCopyInto(out *SecurityProfile) {
 	*out = *in
@@ -1412,6 +1585,41 @@ func (in *SecurityRule) DeepCopyInto(out *SecurityRule) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.SourcePortRanges != nil {
+		in, out := &in.SourcePortRanges, &out.SourcePortRanges
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DestinationPortRanges != nil {
+		in, out := &in.DestinationPortRanges, &out.DestinationPortRanges
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Sources != nil {
+		in, out := &in.Sources, &out.Sources
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Destinations != nil {
+		in, out := &in.Destinations, &out.Destinations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SourceApplicationSecurityGroups != nil {
+		in, out := &in.SourceApplicationSecurityGroups, &out.SourceApplicationSecurityGroups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DestinationApplicationSecurityGroups != nil {
+		in, out := &in.DestinationApplicationSecurityGroups, &out.DestinationApplicationSecurityGroups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SourcesFrom != nil {
+		in, out := &in.SourcesFrom, &out.SourcesFrom
+		*out = new(SecurityRuleSourcesReference)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecurityRule.
@@ -1424,6 +1632,87 @@ func (in *SecurityRule) DeepCopy() *SecurityRule {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecurityRulePriorityAssignment) DeepCopyInto(out *SecurityRulePriorityAssignment) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecurityRulePriorityAssignment.
+func (in *SecurityRulePriorityAssignment) DeepCopy() *SecurityRulePriorityAssignment {
+	if in == nil {
+		return nil
+	}
+	out := new(SecurityRulePriorityAssignment)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecurityRuleSet) DeepCopyInto(out *SecurityRuleSet) {
+	*out = *in
+	if in.SecurityRules != nil {
+		in, out := &in.SecurityRules, &out.SecurityRules
+		*out = make(SecurityRules, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecurityRuleSet.
+func (in *SecurityRuleSet) DeepCopy() *SecurityRuleSet {
+	if in == nil {
+		return nil
+	}
+	out := new(SecurityRuleSet)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecurityRuleSourcesReference) DeepCopyInto(out *SecurityRuleSourcesReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecurityRuleSourcesReference.
+func (in *SecurityRuleSourcesReference) DeepCopy() *SecurityRuleSourcesReference {
+	if in == nil {
+		return nil
+	}
+	out := new(SecurityRuleSourcesReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecurityRuleStatus) DeepCopyInto(out *SecurityRuleStatus) {
+	*out = *in
+	if in.SourcePorts != nil {
+		in, out := &in.SourcePorts, &out.SourcePorts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DestinationPorts != nil {
+		in, out := &in.DestinationPorts, &out.DestinationPorts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastAppliedTime != nil {
+		in, out := &in.LastAppliedTime, &out.LastAppliedTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecurityRuleStatus.
+func (in *SecurityRuleStatus) DeepCopy() *SecurityRuleStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SecurityRuleStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in SecurityRules) DeepCopyInto(out *SecurityRules) {
 	{
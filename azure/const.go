@@ -28,4 +28,98 @@ const (
 	// See https://kubernetes.io/docs/concepts/overview/working-with-objects/annotations/
 	// for annotation formatting rules.
 	RGTagsLastAppliedAnnotation = "sigs.k8s.io/cluster-api-provider-azure-last-applied-tags-rg"
+
+	// PruneSecurityRulesAnnotation is the key for the Azure Cluster object annotation
+	// which, when set to "true", deletes the rules managed by CAPZ that are no longer part of the spec of their
+	// security group. Such rules are left in place otherwise.
+	// See https://kubernetes.io/docs/concepts/overview/working-with-objects/annotations/
+	// for annotation formatting rules.
+	PruneSecurityRulesAnnotation = "sigs.k8s.io/cluster-api-provider-azure-prune-security-rules"
+
+	// DefaultDenyOutboundAnnotation is the key for the Azure Cluster object annotation
+	// which, when set to "true", adds the rule denying all outbound traffic to all the security groups of the cluster,
+	// as if DefaultDenyOutbound was enabled on each of them.
+	// See https://kubernetes.io/docs/concepts/overview/working-with-objects/annotations/
+	// for annotation formatting rules.
+	DefaultDenyOutboundAnnotation = "sigs.k8s.io/cluster-api-provider-azure-default-deny-outbound"
+
+	// SecurityRulesAdditiveSafeModeAnnotation is the key for the Azure Cluster object annotation
+	// which, when set to "true", reconciles the security groups in additive safe mode: rules are added and updated
+	// right away, but the rules pruned with PruneSecurityRulesAnnotation are only deleted once their deletion is
+	// confirmed with ConfirmedRuleDeletionsAnnotation.
+	// See https://kubernetes.io/docs/concepts/overview/working-with-objects/annotations/
+	// for annotation formatting rules.
+	SecurityRulesAdditiveSafeModeAnnotation = "sigs.k8s.io/cluster-api-provider-azure-security-rules-additive-safe-mode"
+
+	// ConfirmedRuleDeletionsAnnotation is the key for the Azure Cluster object annotation
+	// which lists, comma-separated, the names of the security rules whose deletion is confirmed
+	// when security groups are reconciled in additive safe mode.
+	// See https://kubernetes.io/docs/concepts/overview/working-with-objects/annotations/
+	// for annotation formatting rules.
+	ConfirmedRuleDeletionsAnnotation = "sigs.k8s.io/cluster-api-provider-azure-confirmed-rule-deletions"
+
+	// SecurityGroupDiagnosticsWorkspaceAnnotation is the key for the Azure Cluster object annotation
+	// which holds the ID of the Log Analytics workspace the logs of the security groups are sent to.
+	// See https://kubernetes.io/docs/concepts/overview/working-with-objects/annotations/
+	// for annotation formatting rules.
+	SecurityGroupDiagnosticsWorkspaceAnnotation = "sigs.k8s.io/cluster-api-provider-azure-security-group-diagnostics-workspace"
+
+	// AppliedParametersAnnotation is the key for the Azure Cluster object annotation
+	// which tracks, as JSON, the hash of the parameters last applied to each resource and the hash
+	// of the resource Azure returned then, so that unchanged resources are not updated again.
+	// See https://kubernetes.io/docs/concepts/overview/working-with-objects/annotations/
+	// for annotation formatting rules.
+	AppliedParametersAnnotation = "sigs.k8s.io/cluster-api-provider-azure-applied-parameters"
+
+	// PausedServicesAnnotation is the key for the annotation of the objects owning Azure resources
+	// which lists, comma-separated, the names of the services whose reconcile is paused, e.g. "securitygroups".
+	// The resources of a paused service are neither created, updated nor deleted.
+	// See https://kubernetes.io/docs/concepts/overview/working-with-objects/annotations/
+	// for annotation formatting rules.
+	PausedServicesAnnotation = "sigs.k8s.io/cluster-api-provider-azure-paused-services"
+
+	// SharedSecurityGroupsAnnotation is the key for the Azure Cluster object annotation
+	// which, when set to "true", reconciles the security groups in shared ownership mode so that
+	// CAPZ only manages its own rules of security groups that other controllers write to as well.
+	// See https://kubernetes.io/docs/concepts/overview/working-with-objects/annotations/
+	// for annotation formatting rules.
+	SharedSecurityGroupsAnnotation = "sigs.k8s.io/cluster-api-provider-azure-shared-security-groups"
+
+	// IgnoreSecurityGroupTagDriftAnnotation is the key for the Azure Cluster object annotation
+	// which, when set to "true", does not update the security groups only because their additional tags were
+	// removed or changed out of band. The tags are added back the next time the rules of a security group are updated.
+	// See https://kubernetes.io/docs/concepts/overview/working-with-objects/annotations/
+	// for annotation formatting rules.
+	IgnoreSecurityGroupTagDriftAnnotation = "sigs.k8s.io/cluster-api-provider-azure-ignore-security-group-tag-drift"
+
+	// FirewallPolicyAnnotation is the key for the Azure Cluster object annotation
+	// which references, by resource ID, the Azure Firewall policy filtering the traffic of the cluster. The rules of the
+	// security groups whose traffic is already allowed or denied by a network rule of the policy are not created.
+	// See https://kubernetes.io/docs/concepts/overview/working-with-objects/annotations/
+	// for annotation formatting rules.
+	FirewallPolicyAnnotation = "sigs.k8s.io/cluster-api-provider-azure-firewall-policy"
+
+	// ResetOperationAnnotation is the key for the Azure Cluster object annotation
+	// which lists, comma-separated, the long running operation states to forcibly delete, e.g. to recover from a
+	// corrupt future. An entry is either a service name, e.g. "securitygroups", to reset the operations of every
+	// resource of the service, or a service and resource name, e.g. "securitygroups/my-nsg".
+	// The annotation is removed once the operations are reset.
+	// See https://kubernetes.io/docs/concepts/overview/working-with-objects/annotations/
+	// for annotation formatting rules.
+	ResetOperationAnnotation = "sigs.k8s.io/cluster-api-provider-azure-reset-operation"
+
+	// SecurityGroupsDryRunAnnotation is the key for the Azure Cluster object annotation
+	// which, when set to "true", reconciles the security groups in dry-run mode: their rule changes are computed and
+	// written to the pendingChanges of the AzureCluster status for review, but not applied.
+	// See https://kubernetes.io/docs/concepts/overview/working-with-objects/annotations/
+	// for annotation formatting rules.
+	SecurityGroupsDryRunAnnotation = "sigs.k8s.io/cluster-api-provider-azure-security-groups-dry-run"
+
+	// SecurityGroupsQuotaCheckAnnotation is the key for the Azure Cluster object annotation
+	// which, when set to "true", checks the quota of security groups of their region before creating them, so that
+	// a security group is not created once the quota is exhausted. The check reads the usages of the region, which
+	// is an additional request to Azure for every security group created or updated.
+	// See https://kubernetes.io/docs/concepts/overview/working-with-objects/annotations/
+	// for annotation formatting rules.
+	SecurityGroupsQuotaCheckAnnotation = "sigs.k8s.io/cluster-api-provider-azure-security-groups-quota-check"
 )
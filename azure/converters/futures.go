@@ -18,6 +18,7 @@ package converters
 
 import (
 	"encoding/base64"
+	"encoding/json"
 
 	azureautorest "github.com/Azure/go-autorest/autorest/azure"
 	"github.com/pkg/errors"
@@ -37,6 +38,7 @@ func SDKToFuture(future azureautorest.FutureAPI, futureType, service, resourceNa
 		ServiceName:   service,
 		Name:          resourceName,
 		Data:          base64.URLEncoding.EncodeToString(jsonData),
+		Status:        future.Status(),
 	}, nil
 }
 
@@ -52,3 +54,30 @@ func FutureToSDK(future infrav1.Future) (azureautorest.FutureAPI, error) {
 	}
 	return &genericFuture, nil
 }
+
+// PollerToFuture converts the resume token of a track2 SDK runtime.Poller to an infrav1.Future.
+func PollerToFuture(resumeToken, futureType, service, resourceName, rgName string) (*infrav1.Future, error) {
+	if !json.Valid([]byte(resumeToken)) {
+		return nil, errors.New("poller resume token is not valid JSON")
+	}
+
+	return &infrav1.Future{
+		Type:          futureType,
+		ResourceGroup: rgName,
+		ServiceName:   service,
+		Name:          resourceName,
+		Data:          base64.URLEncoding.EncodeToString([]byte(resumeToken)),
+	}, nil
+}
+
+// FutureToResumeToken converts an infrav1.Future to the resume token of a track2 SDK runtime.Poller.
+func FutureToResumeToken(future infrav1.Future) (string, error) {
+	tokenData, err := base64.URLEncoding.DecodeString(future.Data)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to base64 decode future data")
+	}
+	if !json.Valid(tokenData) {
+		return "", errors.New("future data is not a valid poller resume token")
+	}
+	return string(tokenData), nil
+}
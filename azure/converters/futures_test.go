@@ -92,6 +92,7 @@ func Test_SDKToFuture(t *testing.T) {
 					Name:          "test-resource",
 					ResourceGroup: "test-group",
 					Data:          "eyJtZXRob2QiOiJERUxFVEUiLCJwb2xsaW5nTWV0aG9kIjoiIiwicG9sbGluZ1VSSSI6IiIsImxyb1N0YXRlIjoiU3VjY2VlZGVkIiwicmVzdWx0VVJJIjoiIn0=",
+					Status:        "Succeeded",
 				}))
 			},
 		},
@@ -155,3 +156,90 @@ func Test_FutureToSDK(t *testing.T) {
 		})
 	}
 }
+
+func Test_PollerToFuture(t *testing.T) {
+	cases := []struct {
+		name        string
+		resumeToken string
+		expect      func(*GomegaWithT, *infrav1.Future, error)
+	}{
+		{
+			name:        "resume token is not valid JSON",
+			resumeToken: "not a resume token",
+			expect: func(g *GomegaWithT, f *infrav1.Future, err error) {
+				g.Expect(err).Should(MatchError("poller resume token is not valid JSON"))
+			},
+		},
+		{
+			name:        "valid resume token",
+			resumeToken: `{"type":"TestPoller","token":{}}`,
+			expect: func(g *GomegaWithT, f *infrav1.Future, err error) {
+				g.Expect(err).Should(BeNil())
+				g.Expect(f).Should(BeEquivalentTo(&infrav1.Future{
+					Type:          infrav1.DeleteFuture,
+					ServiceName:   "test-service",
+					Name:          "test-resource",
+					ResourceGroup: "test-group",
+					Data:          "eyJ0eXBlIjoiVGVzdFBvbGxlciIsInRva2VuIjp7fX0=",
+				}))
+			},
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			g := NewGomegaWithT(t)
+			result, err := PollerToFuture(c.resumeToken, infrav1.DeleteFuture, "test-service", "test-resource", "test-group")
+			c.expect(g, result, err)
+		})
+	}
+}
+
+func Test_FutureToResumeToken(t *testing.T) {
+	cases := []struct {
+		name   string
+		future infrav1.Future
+		expect func(*GomegaWithT, string, error)
+	}{
+		{
+			name:   "data is not base64 encoded",
+			future: decodedDataFuture,
+			expect: func(g *GomegaWithT, token string, err error) {
+				g.Expect(err.Error()).Should(ContainSubstring("failed to base64 decode future data"))
+			},
+		},
+		{
+			name:   "base64 data is not a valid resume token",
+			future: invalidFuture,
+			expect: func(g *GomegaWithT, token string, err error) {
+				g.Expect(err).Should(MatchError("future data is not a valid poller resume token"))
+			},
+		},
+		{
+			name: "valid resume token",
+			future: infrav1.Future{
+				Type:          infrav1.DeleteFuture,
+				ServiceName:   "test-service",
+				Name:          "test-resource",
+				ResourceGroup: "test-group",
+				Data:          "eyJ0eXBlIjoiVGVzdFBvbGxlciIsInRva2VuIjp7fX0=",
+			},
+			expect: func(g *GomegaWithT, token string, err error) {
+				g.Expect(err).Should(BeNil())
+				g.Expect(token).Should(Equal(`{"type":"TestPoller","token":{}}`))
+			},
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			g := NewGomegaWithT(t)
+			result, err := FutureToResumeToken(c.future)
+			c.expect(g, result, err)
+		})
+	}
+}
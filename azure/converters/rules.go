@@ -37,6 +37,33 @@ func SecurityRuleToSDK(rule infrav1.SecurityRule) network.SecurityRule {
 		},
 	}
 
+	// Augmented security rules list multiple port ranges and address prefixes in place of the singular fields.
+	if len(rule.SourcePortRanges) > 0 {
+		secRule.SourcePortRange = nil
+		secRule.SourcePortRanges = to.StringSlicePtr(rule.SourcePortRanges)
+	}
+	if len(rule.DestinationPortRanges) > 0 {
+		secRule.DestinationPortRange = nil
+		secRule.DestinationPortRanges = to.StringSlicePtr(rule.DestinationPortRanges)
+	}
+	if len(rule.Sources) > 0 {
+		secRule.SourceAddressPrefix = nil
+		secRule.SourceAddressPrefixes = to.StringSlicePtr(rule.Sources)
+	}
+	if len(rule.Destinations) > 0 {
+		secRule.DestinationAddressPrefix = nil
+		secRule.DestinationAddressPrefixes = to.StringSlicePtr(rule.Destinations)
+	}
+	// Application security groups match the network interfaces that are members of the groups in place of addresses.
+	if len(rule.SourceApplicationSecurityGroups) > 0 {
+		secRule.SourceAddressPrefix = nil
+		secRule.SourceApplicationSecurityGroups = applicationSecurityGroups(rule.SourceApplicationSecurityGroups)
+	}
+	if len(rule.DestinationApplicationSecurityGroups) > 0 {
+		secRule.DestinationAddressPrefix = nil
+		secRule.DestinationApplicationSecurityGroups = applicationSecurityGroups(rule.DestinationApplicationSecurityGroups)
+	}
+
 	switch rule.Protocol {
 	case infrav1.SecurityGroupProtocolAll:
 		secRule.Protocol = network.SecurityRuleProtocolAsterisk
@@ -46,6 +73,17 @@ func SecurityRuleToSDK(rule infrav1.SecurityRule) network.SecurityRule {
 		secRule.Protocol = network.SecurityRuleProtocolUDP
 	case infrav1.SecurityGroupProtocolICMP:
 		secRule.Protocol = network.SecurityRuleProtocolIcmp
+	case infrav1.SecurityGroupProtocolESP:
+		secRule.Protocol = network.SecurityRuleProtocolEsp
+	case infrav1.SecurityGroupProtocolAH:
+		secRule.Protocol = network.SecurityRuleProtocolAh
+	}
+	if !rule.Protocol.HasPorts() {
+		// ICMP, ESP and AH have no ports, Azure only accepts a wildcard port range for their rules.
+		secRule.SourcePortRange = to.StringPtr("*")
+		secRule.SourcePortRanges = nil
+		secRule.DestinationPortRange = to.StringPtr("*")
+		secRule.DestinationPortRanges = nil
 	}
 
 	switch rule.Direction {
@@ -57,3 +95,12 @@ func SecurityRuleToSDK(rule infrav1.SecurityRule) network.SecurityRule {
 
 	return secRule
 }
+
+// applicationSecurityGroups returns references to the application security groups with the given IDs.
+func applicationSecurityGroups(ids []string) *[]network.ApplicationSecurityGroup {
+	groups := make([]network.ApplicationSecurityGroup, len(ids))
+	for i, id := range ids {
+		groups[i] = network.ApplicationSecurityGroup{ID: to.StringPtr(id)}
+	}
+	return &groups
+}
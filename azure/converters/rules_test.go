@@ -0,0 +1,240 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package converters
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-02-01/network"
+	"github.com/Azure/go-autorest/autorest/to"
+	. "github.com/onsi/gomega"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+)
+
+func TestSecurityRuleToSDK(t *testing.T) {
+	cases := []struct {
+		name   string
+		rule   infrav1.SecurityRule
+		expect network.SecurityRule
+	}{
+		{
+			name: "simple rule",
+			rule: infrav1.SecurityRule{
+				Name:             "allow_ssh",
+				Description:      "Allow SSH",
+				Priority:         2200,
+				Protocol:         infrav1.SecurityGroupProtocolTCP,
+				Direction:        infrav1.SecurityRuleDirectionInbound,
+				Source:           to.StringPtr("*"),
+				SourcePorts:      to.StringPtr("*"),
+				Destination:      to.StringPtr("*"),
+				DestinationPorts: to.StringPtr("22"),
+			},
+			expect: network.SecurityRule{
+				Name: to.StringPtr("allow_ssh"),
+				SecurityRulePropertiesFormat: &network.SecurityRulePropertiesFormat{
+					Description:              to.StringPtr("Allow SSH"),
+					SourceAddressPrefix:      to.StringPtr("*"),
+					SourcePortRange:          to.StringPtr("*"),
+					DestinationAddressPrefix: to.StringPtr("*"),
+					DestinationPortRange:     to.StringPtr("22"),
+					Access:                   network.SecurityRuleAccessAllow,
+					Priority:                 to.Int32Ptr(2200),
+					Protocol:                 network.SecurityRuleProtocolTCP,
+					Direction:                network.SecurityRuleDirectionInbound,
+				},
+			},
+		},
+		{
+			name: "augmented rule",
+			rule: infrav1.SecurityRule{
+				Name:                  "allow_node_ports",
+				Description:           "Allow node ports",
+				Priority:              2201,
+				Protocol:              infrav1.SecurityGroupProtocolTCP,
+				Direction:             infrav1.SecurityRuleDirectionInbound,
+				Sources:               []string{"10.0.0.0/16", "10.1.0.0/16"},
+				SourcePortRanges:      []string{"1024-2048", "4096"},
+				Destinations:          []string{"10.2.0.4", "10.2.0.5"},
+				DestinationPortRanges: []string{"80", "443"},
+			},
+			expect: network.SecurityRule{
+				Name: to.StringPtr("allow_node_ports"),
+				SecurityRulePropertiesFormat: &network.SecurityRulePropertiesFormat{
+					Description:                to.StringPtr("Allow node ports"),
+					SourceAddressPrefixes:      to.StringSlicePtr([]string{"10.0.0.0/16", "10.1.0.0/16"}),
+					SourcePortRanges:           to.StringSlicePtr([]string{"1024-2048", "4096"}),
+					DestinationAddressPrefixes: to.StringSlicePtr([]string{"10.2.0.4", "10.2.0.5"}),
+					DestinationPortRanges:      to.StringSlicePtr([]string{"80", "443"}),
+					Access:                     network.SecurityRuleAccessAllow,
+					Priority:                   to.Int32Ptr(2201),
+					Protocol:                   network.SecurityRuleProtocolTCP,
+					Direction:                  network.SecurityRuleDirectionInbound,
+				},
+			},
+		},
+		{
+			name: "dual-stack augmented rule",
+			rule: infrav1.SecurityRule{
+				Name:             "allow_nodes",
+				Description:      "Allow node to node traffic",
+				Priority:         2203,
+				Protocol:         infrav1.SecurityGroupProtocolAll,
+				Direction:        infrav1.SecurityRuleDirectionInbound,
+				Sources:          []string{"10.0.0.0/16", "2001:db8::/64"},
+				SourcePorts:      to.StringPtr("*"),
+				Destinations:     []string{"10.1.0.0/16", "2001:db8:1::/64"},
+				DestinationPorts: to.StringPtr("*"),
+			},
+			expect: network.SecurityRule{
+				Name: to.StringPtr("allow_nodes"),
+				SecurityRulePropertiesFormat: &network.SecurityRulePropertiesFormat{
+					Description:                to.StringPtr("Allow node to node traffic"),
+					SourceAddressPrefixes:      to.StringSlicePtr([]string{"10.0.0.0/16", "2001:db8::/64"}),
+					SourcePortRange:            to.StringPtr("*"),
+					DestinationAddressPrefixes: to.StringSlicePtr([]string{"10.1.0.0/16", "2001:db8:1::/64"}),
+					DestinationPortRange:       to.StringPtr("*"),
+					Access:                     network.SecurityRuleAccessAllow,
+					Priority:                   to.Int32Ptr(2203),
+					Protocol:                   network.SecurityRuleProtocolAsterisk,
+					Direction:                  network.SecurityRuleDirectionInbound,
+				},
+			},
+		},
+		{
+			name: "ICMP rule",
+			rule: infrav1.SecurityRule{
+				Name:        "allow_ping",
+				Description: "Allow ping",
+				Priority:    2202,
+				Protocol:    infrav1.SecurityGroupProtocolICMP,
+				Direction:   infrav1.SecurityRuleDirectionInbound,
+				Source:      to.StringPtr("10.0.0.0/16"),
+				Destination: to.StringPtr("*"),
+			},
+			expect: network.SecurityRule{
+				Name: to.StringPtr("allow_ping"),
+				SecurityRulePropertiesFormat: &network.SecurityRulePropertiesFormat{
+					Description:              to.StringPtr("Allow ping"),
+					SourceAddressPrefix:      to.StringPtr("10.0.0.0/16"),
+					SourcePortRange:          to.StringPtr("*"),
+					DestinationAddressPrefix: to.StringPtr("*"),
+					DestinationPortRange:     to.StringPtr("*"),
+					Access:                   network.SecurityRuleAccessAllow,
+					Priority:                 to.Int32Ptr(2202),
+					Protocol:                 network.SecurityRuleProtocolIcmp,
+					Direction:                network.SecurityRuleDirectionInbound,
+				},
+			},
+		},
+		{
+			name: "ESP rule ignores ports",
+			rule: infrav1.SecurityRule{
+				Name:                  "allow_ipsec_esp",
+				Description:           "Allow IPsec ESP",
+				Priority:              2202,
+				Protocol:              infrav1.SecurityGroupProtocolESP,
+				Direction:             infrav1.SecurityRuleDirectionInbound,
+				SourcePorts:           to.StringPtr("500"),
+				DestinationPortRanges: []string{"500", "4500"},
+				Source:                to.StringPtr("10.0.0.0/16"),
+				Destination:           to.StringPtr("*"),
+			},
+			expect: network.SecurityRule{
+				Name: to.StringPtr("allow_ipsec_esp"),
+				SecurityRulePropertiesFormat: &network.SecurityRulePropertiesFormat{
+					Description:              to.StringPtr("Allow IPsec ESP"),
+					SourceAddressPrefix:      to.StringPtr("10.0.0.0/16"),
+					SourcePortRange:          to.StringPtr("*"),
+					DestinationAddressPrefix: to.StringPtr("*"),
+					DestinationPortRange:     to.StringPtr("*"),
+					Access:                   network.SecurityRuleAccessAllow,
+					Priority:                 to.Int32Ptr(2202),
+					Protocol:                 network.SecurityRuleProtocolEsp,
+					Direction:                network.SecurityRuleDirectionInbound,
+				},
+			},
+		},
+		{
+			name: "AH rule",
+			rule: infrav1.SecurityRule{
+				Name:        "allow_ipsec_ah",
+				Description: "Allow IPsec AH",
+				Priority:    2203,
+				Protocol:    infrav1.SecurityGroupProtocolAH,
+				Direction:   infrav1.SecurityRuleDirectionOutbound,
+				Source:      to.StringPtr("*"),
+				Destination: to.StringPtr("10.1.0.0/16"),
+			},
+			expect: network.SecurityRule{
+				Name: to.StringPtr("allow_ipsec_ah"),
+				SecurityRulePropertiesFormat: &network.SecurityRulePropertiesFormat{
+					Description:              to.StringPtr("Allow IPsec AH"),
+					SourceAddressPrefix:      to.StringPtr("*"),
+					SourcePortRange:          to.StringPtr("*"),
+					DestinationAddressPrefix: to.StringPtr("10.1.0.0/16"),
+					DestinationPortRange:     to.StringPtr("*"),
+					Access:                   network.SecurityRuleAccessAllow,
+					Priority:                 to.Int32Ptr(2203),
+					Protocol:                 network.SecurityRuleProtocolAh,
+					Direction:                network.SecurityRuleDirectionOutbound,
+				},
+			},
+		},
+		{
+			name: "rule with application security groups",
+			rule: infrav1.SecurityRule{
+				Name:                                 "allow_web_to_db",
+				Description:                          "Allow web to db",
+				Priority:                             2202,
+				Protocol:                             infrav1.SecurityGroupProtocolTCP,
+				Direction:                            infrav1.SecurityRuleDirectionInbound,
+				SourcePorts:                          to.StringPtr("*"),
+				DestinationPorts:                     to.StringPtr("5432"),
+				SourceApplicationSecurityGroups:      []string{"/subscriptions/123/resourceGroups/test-rg/providers/Microsoft.Network/applicationSecurityGroups/web"},
+				DestinationApplicationSecurityGroups: []string{"/subscriptions/123/resourceGroups/test-rg/providers/Microsoft.Network/applicationSecurityGroups/db"},
+			},
+			expect: network.SecurityRule{
+				Name: to.StringPtr("allow_web_to_db"),
+				SecurityRulePropertiesFormat: &network.SecurityRulePropertiesFormat{
+					Description:          to.StringPtr("Allow web to db"),
+					SourcePortRange:      to.StringPtr("*"),
+					DestinationPortRange: to.StringPtr("5432"),
+					SourceApplicationSecurityGroups: &[]network.ApplicationSecurityGroup{
+						{ID: to.StringPtr("/subscriptions/123/resourceGroups/test-rg/providers/Microsoft.Network/applicationSecurityGroups/web")},
+					},
+					DestinationApplicationSecurityGroups: &[]network.ApplicationSecurityGroup{
+						{ID: to.StringPtr("/subscriptions/123/resourceGroups/test-rg/providers/Microsoft.Network/applicationSecurityGroups/db")},
+					},
+					Access:    network.SecurityRuleAccessAllow,
+					Priority:  to.Int32Ptr(2202),
+					Protocol:  network.SecurityRuleProtocolTCP,
+					Direction: network.SecurityRuleDirectionInbound,
+				},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+			g.Expect(SecurityRuleToSDK(c.rule)).To(Equal(c.expect))
+		})
+	}
+}
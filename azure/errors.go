@@ -19,9 +19,12 @@ package azure
 import (
 	"errors"
 	"fmt"
+	"net/http"
+	"strings"
 	"time"
 
 	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/adal"
 	"github.com/Azure/go-autorest/autorest/azure"
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 )
@@ -50,6 +53,174 @@ func ResourceConflict(err error) bool {
 	return errors.As(err, &derr) && derr.StatusCode == 409
 }
 
+// ResourceInUse parses the error to check if the resource cannot be deleted because other resources still reference it,
+// e.g. a security group that is still associated with subnets.
+func ResourceInUse(err error) bool {
+	derr := autorest.DetailedError{}
+	return errors.As(err, &derr) && inUseErrorCodes[serviceErrorCode(derr)]
+}
+
+// ThrottledRetryAfter parses the error to check if it's a throttling error (429). If it is, it returns the delay
+// requested by the Retry-After header of the response, or defaultDelay if the header is missing or invalid.
+func ThrottledRetryAfter(err error, defaultDelay time.Duration) (time.Duration, bool) {
+	derr := autorest.DetailedError{}
+	if !errors.As(err, &derr) || derr.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+	if derr.Response == nil {
+		return defaultDelay, true
+	}
+	return autorest.GetRetryAfter(derr.Response, defaultDelay), true
+}
+
+// authorizerPackageTypes are the package types of the errors returned by the autorest authorizers when they fail to get
+// a token for a request.
+var authorizerPackageTypes = map[string]bool{
+	"azure.BearerAuthorizer":         true,
+	"azure.multiTenantSPTAuthorizer": true,
+}
+
+// IsAuthorizerError returns true if the target is an error of a request that was never sent to Azure because the
+// authorizer failed to get a token for it, e.g. because refreshing the token of the service principal failed. Such an
+// error says nothing about the state of the resource and the request succeeds once a token can be got again.
+func IsAuthorizerError(target error) bool {
+	reconcileErr := &ReconcileError{}
+	if errors.As(target, reconcileErr) {
+		return IsAuthorizerError(reconcileErr.error)
+	}
+	var refreshErr adal.TokenRefreshError
+	if errors.As(target, &refreshErr) {
+		return true
+	}
+	derr := autorest.DetailedError{}
+	for errors.As(target, &derr) {
+		if authorizerPackageTypes[derr.PackageType] {
+			return true
+		}
+		target = derr.Original
+	}
+	return false
+}
+
+// throttledErrorCodes are ARM error codes of requests rejected because a request rate limit was exceeded. They may be
+// reported as the error of a failed long running operation rather than with a 429 status code.
+var throttledErrorCodes = map[string]bool{
+	"SubscriptionRequestsThrottled": true,
+	"TooManyRequests":               true,
+}
+
+// IsThrottled returns true if the target is an error of a request throttled by Azure, i.e. a 429 response or a
+// response with a known throttling ARM error code. Throttled requests succeed once retried later.
+func IsThrottled(target error) bool {
+	reconcileErr := &ReconcileError{}
+	if errors.As(target, reconcileErr) {
+		return IsThrottled(reconcileErr.error)
+	}
+	derr := autorest.DetailedError{}
+	if !errors.As(target, &derr) {
+		return false
+	}
+	return derr.StatusCode == http.StatusTooManyRequests || throttledErrorCodes[serviceErrorCode(derr)]
+}
+
+// terminalErrorCodes are ARM error codes of requests that will never succeed if retried as is, e.g. because of an
+// invalid spec or missing permissions, regardless of the status code of the response.
+var terminalErrorCodes = map[string]bool{
+	"AuthorizationFailed":             true,
+	"InvalidParameter":                true,
+	"InvalidRequestContent":           true,
+	"InvalidRequestFormat":            true,
+	"InvalidResourceName":             true,
+	"InvalidResourceReference":        true,
+	"InvalidTemplate":                 true,
+	"LinkedAuthorizationFailed":       true,
+	"MissingSubscriptionRegistration": true,
+	"SubscriptionNotFound":            true,
+}
+
+// retryableErrorCodes are ARM error codes returned with a 4xx status code for requests that succeed once a
+// dependency is ready, e.g. when another operation on the resource is still in progress.
+var retryableErrorCodes = map[string]bool{
+	"AnotherOperationInProgress":       true,
+	"ReferencedResourceNotProvisioned": true,
+	"RetryableError":                   true,
+}
+
+// inUseErrorCodes are ARM error codes returned with a 4xx status code for delete requests that succeed once the
+// resources that still reference the resource are released, e.g. when deleting a subnet that is still used by network
+// interfaces.
+var inUseErrorCodes = map[string]bool{
+	"InUseNetworkSecurityGroupCannotBeDeleted": true,
+	"InUseRouteTableCannotBeDeleted":           true,
+	"InUseSubnetCannotBeDeleted":               true,
+}
+
+// IsTerminalError returns true if the target is an error that retrying the request will not fix: a terminal
+// ReconcileError, a bad request (400) or forbidden (403) response, or a response with a known terminal ARM error code.
+// Timeouts, throttling (429), server errors (5xx) and authorizer errors, whose responses are the ones of the token
+// request, are not terminal.
+func IsTerminalError(target error) bool {
+	reconcileErr := &ReconcileError{}
+	if errors.As(target, reconcileErr) {
+		return reconcileErr.IsTerminal()
+	}
+	if IsAuthorizerError(target) {
+		return false
+	}
+	derr := autorest.DetailedError{}
+	if !errors.As(target, &derr) {
+		return false
+	}
+	code := serviceErrorCode(derr)
+	if retryableErrorCodes[code] || inUseErrorCodes[code] || throttledErrorCodes[code] {
+		return false
+	}
+	if terminalErrorCodes[code] {
+		return true
+	}
+	switch derr.StatusCode {
+	case http.StatusBadRequest, http.StatusForbidden:
+		return true
+	default:
+		return false
+	}
+}
+
+// serviceErrorCode returns the ARM error code of the service error wrapped by derr, if any.
+func serviceErrorCode(derr autorest.DetailedError) string {
+	serr := &azure.ServiceError{}
+	if errors.As(derr.Original, &serr) {
+		return serr.Code
+	}
+	rerr := &azure.RequestError{}
+	if errors.As(derr.Original, &rerr) && rerr.ServiceError != nil {
+		return rerr.ServiceError.Code
+	}
+	return ""
+}
+
+// RequestID returns the ID Azure assigned to the request that failed with the error, i.e. the x-ms-request-id header
+// of the response, so that the failure can be looked up in the Azure Activity Log. It returns an empty string if the
+// error is not the error of an Azure request or Azure did not return a request ID.
+func RequestID(err error) string {
+	reconcileErr := &ReconcileError{}
+	if errors.As(err, reconcileErr) {
+		return RequestID(reconcileErr.error)
+	}
+	derr := autorest.DetailedError{}
+	if !errors.As(err, &derr) {
+		return ""
+	}
+	if id := azure.ExtractRequestID(derr.Response); id != "" {
+		return id
+	}
+	rerr := &azure.RequestError{}
+	if errors.As(derr.Original, &rerr) {
+		return rerr.RequestID
+	}
+	return ""
+}
+
 // VMDeletedError is returned when a virtual machine is deleted outside of capz.
 type VMDeletedError struct {
 	ProviderID string
@@ -129,6 +300,8 @@ func WithTerminalError(err error) ReconcileError {
 // OperationNotDoneError is used to represent a long-running operation that is not yet complete.
 type OperationNotDoneError struct {
 	Future *infrav1.Future
+	// requeueAfter is the delay of the ReconcileError wrapping the error, see AsOperationNotDoneError.
+	requeueAfter time.Duration
 }
 
 // NewOperationNotDoneError returns a new OperationNotDoneError wrapping a Future.
@@ -156,3 +329,174 @@ func IsOperationNotDoneError(target error) bool {
 	}
 	return errors.As(target, &OperationNotDoneError{})
 }
+
+// OperationType returns the type of the operation that is not done, e.g. PUT or DELETE.
+func (onde OperationNotDoneError) OperationType() string {
+	if onde.Future == nil {
+		return ""
+	}
+	return onde.Future.Type
+}
+
+// ResourceGroup returns the resource group of the resource of the operation that is not done.
+func (onde OperationNotDoneError) ResourceGroup() string {
+	if onde.Future == nil {
+		return ""
+	}
+	return onde.Future.ResourceGroup
+}
+
+// ResourceName returns the name of the resource of the operation that is not done.
+func (onde OperationNotDoneError) ResourceName() string {
+	if onde.Future == nil {
+		return ""
+	}
+	return onde.Future.Name
+}
+
+// ServiceName returns the name of the service that started the operation that is not done.
+func (onde OperationNotDoneError) ServiceName() string {
+	if onde.Future == nil {
+		return ""
+	}
+	return onde.Future.ServiceName
+}
+
+// RequeueAfter returns how long to wait before checking on the operation again. It is only known for an error returned
+// by AsOperationNotDoneError from a transient ReconcileError, and is zero otherwise.
+func (onde OperationNotDoneError) RequeueAfter() time.Duration {
+	return onde.requeueAfter
+}
+
+// AsOperationNotDoneError returns the OperationNotDoneError of the target, if any, e.g. to log the operation that is
+// not done as structured fields. If the target is a transient ReconcileError, the delay after which it is requeued is
+// returned by the RequeueAfter method of the OperationNotDoneError.
+func AsOperationNotDoneError(target error) (OperationNotDoneError, bool) {
+	var requeueAfter time.Duration
+	var reconcileErr ReconcileError
+	if errors.As(target, &reconcileErr) {
+		if reconcileErr.IsTransient() {
+			requeueAfter = reconcileErr.RequeueAfter()
+		}
+		target = reconcileErr.error
+	}
+	var notDoneErr OperationNotDoneError
+	if !errors.As(target, &notDoneErr) {
+		return OperationNotDoneError{}, false
+	}
+	notDoneErr.requeueAfter = requeueAfter
+	return notDoneErr, true
+}
+
+// RequeueAfter returns how long a controller should wait before reconciling again after a reconcile failed with err,
+// and whether err is transient, so that controllers can set the RequeueAfter of their reconcile.Result instead of
+// returning err. For an OperationNotDoneError, it is the interval after which the operation is polled again.
+func RequeueAfter(err error) (time.Duration, bool) {
+	var reconcileErr ReconcileError
+	if !errors.As(err, &reconcileErr) || !reconcileErr.IsTransient() {
+		return 0, false
+	}
+	return reconcileErr.RequeueAfter(), true
+}
+
+// QuotaExceededError is used to represent the creation of a resource that would exceed a quota of the subscription.
+type QuotaExceededError struct {
+	Requirement QuotaRequirement
+	// Current is the current usage of the quota.
+	Current int64
+	// Limit is the limit of the quota.
+	Limit int64
+}
+
+// Error returns the error represented as a string.
+func (qee QuotaExceededError) Error() string {
+	return fmt.Sprintf("quota %s in %s would be exceeded: %d of %d used, %d more needed", qee.Requirement.Name, qee.Requirement.Location, qee.Current, qee.Limit, qee.Requirement.Amount)
+}
+
+// IsQuotaExceededError returns true if the target is a QuotaExceededError.
+func IsQuotaExceededError(target error) bool {
+	reconcileErr := &ReconcileError{}
+	if errors.As(target, reconcileErr) {
+		return IsQuotaExceededError(reconcileErr.error)
+	}
+	return errors.As(target, &QuotaExceededError{})
+}
+
+// ResourceMustExistError is used to represent an adopt-only resource that does not exist, see AdoptOnlySpecGetter.
+type ResourceMustExistError struct {
+	ResourceGroup string
+	ResourceName  string
+	ServiceName   string
+}
+
+// Error returns the error represented as a string.
+func (rmee ResourceMustExistError) Error() string {
+	return fmt.Sprintf("resource %s/%s (service: %s) does not exist, it must be created before it can be adopted", rmee.ResourceGroup, rmee.ResourceName, rmee.ServiceName)
+}
+
+// IsResourceMustExistError returns true if the target is a ResourceMustExistError.
+func IsResourceMustExistError(target error) bool {
+	reconcileErr := &ReconcileError{}
+	if errors.As(target, reconcileErr) {
+		return IsResourceMustExistError(reconcileErr.error)
+	}
+	return errors.As(target, &ResourceMustExistError{})
+}
+
+// ConcurrencyLimitedError is used to represent an operation that was not started because the Azure requests in flight
+// already reach the limit shared with other services, see async.WithConcurrencyLimiter. It is requeued.
+type ConcurrencyLimitedError struct {
+	// Type is the type of the operation, e.g. PUT or DELETE.
+	Type          string
+	ResourceGroup string
+	ResourceName  string
+	ServiceName   string
+}
+
+// Error returns the error represented as a string.
+func (cle ConcurrencyLimitedError) Error() string {
+	return fmt.Sprintf("too many Azure requests in flight, waiting to start %s operation on resource %s/%s (service: %s)", cle.Type, cle.ResourceGroup, cle.ResourceName, cle.ServiceName)
+}
+
+// IsConcurrencyLimitedError returns true if the target is a ConcurrencyLimitedError.
+func IsConcurrencyLimitedError(target error) bool {
+	reconcileErr := &ReconcileError{}
+	if errors.As(target, reconcileErr) {
+		return IsConcurrencyLimitedError(reconcileErr.error)
+	}
+	return errors.As(target, &ConcurrencyLimitedError{})
+}
+
+// ProvisioningStateError is used to represent a resource that Azure reports in a provisioning state other than
+// Succeeded after it was reconciled, e.g. because it is still being updated by another client or failed to provision.
+type ProvisioningStateError struct {
+	ResourceName string
+	State        infrav1.ProvisioningState
+}
+
+// Error returns the error represented as a string.
+func (pse ProvisioningStateError) Error() string {
+	return fmt.Sprintf("resource %s is in provisioning state %s", pse.ResourceName, pse.State)
+}
+
+// InProgress returns true if the resource is still being provisioned, i.e. its provisioning state may still become
+// Succeeded without any change.
+func (pse ProvisioningStateError) InProgress() bool {
+	for _, state := range []infrav1.ProvisioningState{infrav1.Creating, infrav1.Updating, infrav1.Deleting, infrav1.Migrating, "Accepted"} {
+		if strings.EqualFold(string(pse.State), string(state)) {
+			return true
+		}
+	}
+	return false
+}
+
+// AsProvisioningStateError returns the ProvisioningStateError wrapped by the target, if any.
+func AsProvisioningStateError(target error) (ProvisioningStateError, bool) {
+	reconcileErr := &ReconcileError{}
+	if errors.As(target, reconcileErr) {
+		return AsProvisioningStateError(reconcileErr.error)
+	}
+	pse := ProvisioningStateError{}
+	ok := errors.As(target, &pse)
+	return pse, ok
+}
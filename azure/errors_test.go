@@ -0,0 +1,481 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/azure"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+)
+
+func TestIsTerminalError(t *testing.T) {
+	responseError := func(statusCode int) error {
+		return autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: statusCode}, "")
+	}
+	serviceError := func(statusCode int, code string) error {
+		err := autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: statusCode}, "")
+		err.Original = &azure.ServiceError{Code: code}
+		return err
+	}
+	requestError := func(statusCode int, code string) error {
+		err := autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: statusCode}, "")
+		err.Original = &azure.RequestError{ServiceError: &azure.ServiceError{Code: code}}
+		return err
+	}
+
+	testcases := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{
+			name:     "nil error",
+			err:      nil,
+			expected: false,
+		},
+		{
+			name:     "non-Azure error",
+			err:      errors.New("timeout"),
+			expected: false,
+		},
+		{
+			name:     "bad request",
+			err:      responseError(http.StatusBadRequest),
+			expected: true,
+		},
+		{
+			name:     "forbidden",
+			err:      errors.Wrap(responseError(http.StatusForbidden), "failed to create resource"),
+			expected: true,
+		},
+		{
+			name:     "not found",
+			err:      responseError(http.StatusNotFound),
+			expected: false,
+		},
+		{
+			name:     "throttled",
+			err:      responseError(http.StatusTooManyRequests),
+			expected: false,
+		},
+		{
+			name:     "server error",
+			err:      responseError(http.StatusInternalServerError),
+			expected: false,
+		},
+		{
+			name:     "terminal error code of a failed long running operation",
+			err:      serviceError(http.StatusOK, "InvalidParameter"),
+			expected: true,
+		},
+		{
+			name:     "authorizer failed to refresh the token with a bad request",
+			err:      autorest.NewErrorWithError(errors.New("adal: Refresh request failed"), "azure.BearerAuthorizer", "WithAuthorization", &http.Response{StatusCode: http.StatusBadRequest}, "Failed to refresh the Token"),
+			expected: false,
+		},
+		{
+			name:     "terminal error code of a request error",
+			err:      requestError(http.StatusConflict, "LinkedAuthorizationFailed"),
+			expected: true,
+		},
+		{
+			name:     "retryable error code of a bad request",
+			err:      serviceError(http.StatusBadRequest, "InUseSubnetCannotBeDeleted"),
+			expected: false,
+		},
+		{
+			name:     "terminal reconcile error",
+			err:      WithTerminalError(errors.New("invalid spec")),
+			expected: true,
+		},
+		{
+			name:     "transient reconcile error",
+			err:      WithTransientError(errors.New("not ready"), time.Minute),
+			expected: false,
+		},
+		{
+			name:     "transient reconcile error wrapping a bad request",
+			err:      WithTransientError(responseError(http.StatusBadRequest), time.Minute),
+			expected: false,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			g.Expect(IsTerminalError(tc.err)).To(Equal(tc.expected))
+		})
+	}
+}
+
+func TestResourceInUse(t *testing.T) {
+	serviceError := func(statusCode int, code string) error {
+		err := autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: statusCode}, "")
+		err.Original = &azure.ServiceError{Code: code}
+		return err
+	}
+
+	testcases := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{
+			name:     "nil error",
+			err:      nil,
+			expected: false,
+		},
+		{
+			name:     "non-Azure error",
+			err:      errors.New("timeout"),
+			expected: false,
+		},
+		{
+			name:     "security group in use",
+			err:      errors.Wrap(serviceError(http.StatusBadRequest, "InUseNetworkSecurityGroupCannotBeDeleted"), "failed to delete resource"),
+			expected: true,
+		},
+		{
+			name:     "other error code",
+			err:      serviceError(http.StatusBadRequest, "InvalidParameter"),
+			expected: false,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			g.Expect(ResourceInUse(tc.err)).To(Equal(tc.expected))
+		})
+	}
+}
+
+func TestIsThrottled(t *testing.T) {
+	serviceError := func(statusCode int, code string) error {
+		err := autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: statusCode}, "")
+		err.Original = &azure.ServiceError{Code: code}
+		return err
+	}
+
+	testcases := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{
+			name:     "nil error",
+			err:      nil,
+			expected: false,
+		},
+		{
+			name:     "non-Azure error",
+			err:      errors.New("timeout"),
+			expected: false,
+		},
+		{
+			name:     "too many requests",
+			err:      WithTransientError(autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: http.StatusTooManyRequests}, ""), time.Minute),
+			expected: true,
+		},
+		{
+			name:     "throttling error code of a failed long running operation",
+			err:      errors.Wrap(serviceError(http.StatusOK, "SubscriptionRequestsThrottled"), "failed to create resource"),
+			expected: true,
+		},
+		{
+			name:     "server error",
+			err:      serviceError(http.StatusInternalServerError, "InternalServerError"),
+			expected: false,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			g.Expect(IsThrottled(tc.err)).To(Equal(tc.expected))
+		})
+	}
+}
+
+func TestIsAuthorizerError(t *testing.T) {
+	authorizerError := autorest.NewErrorWithError(errors.New("adal: Refresh request failed"), "azure.BearerAuthorizer", "WithAuthorization", &http.Response{StatusCode: http.StatusUnauthorized}, "Failed to refresh the Token")
+
+	testcases := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{
+			name:     "nil error",
+			err:      nil,
+			expected: false,
+		},
+		{
+			name:     "non-Azure error",
+			err:      errors.New("timeout"),
+			expected: false,
+		},
+		{
+			name:     "authorizer failed to refresh the token",
+			err:      errors.Wrap(authorizerError, "failed to get resource"),
+			expected: true,
+		},
+		{
+			name:     "client failed to prepare a request the authorizer failed to refresh the token of",
+			err:      WithTransientError(autorest.DetailedError{PackageType: "network.SecurityGroupsClient", Method: "Get", Original: authorizerError}, time.Minute),
+			expected: true,
+		},
+		{
+			name:     "unauthorized response of Azure",
+			err:      autorest.NewErrorWithResponse("network.SecurityGroupsClient", "Get", &http.Response{StatusCode: http.StatusUnauthorized}, ""),
+			expected: false,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			g.Expect(IsAuthorizerError(tc.err)).To(Equal(tc.expected))
+		})
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	withHeader := func(requestID string) *http.Response {
+		header := http.Header{}
+		header.Set("x-ms-request-id", requestID)
+		return &http.Response{StatusCode: http.StatusInternalServerError, Header: header}
+	}
+	requestError := autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: http.StatusBadRequest}, "")
+	requestError.Original = &azure.RequestError{RequestID: "from-request-error"}
+
+	testcases := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{
+			name:     "nil error",
+			err:      nil,
+			expected: "",
+		},
+		{
+			name:     "non-Azure error",
+			err:      errors.New("timeout"),
+			expected: "",
+		},
+		{
+			name:     "response without a request ID",
+			err:      autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: http.StatusInternalServerError}, ""),
+			expected: "",
+		},
+		{
+			name:     "request ID header of a wrapped error",
+			err:      WithTransientError(errors.Wrap(autorest.NewErrorWithResponse("", "", withHeader("1234"), ""), "failed to create resource"), time.Minute),
+			expected: "1234",
+		},
+		{
+			name:     "request ID of the request error",
+			err:      requestError,
+			expected: "from-request-error",
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			g.Expect(RequestID(tc.err)).To(Equal(tc.expected))
+		})
+	}
+}
+
+func TestAsProvisioningStateError(t *testing.T) {
+	testcases := []struct {
+		name               string
+		err                error
+		expected           bool
+		expectedInProgress bool
+	}{
+		{
+			name:     "nil error",
+			err:      nil,
+			expected: false,
+		},
+		{
+			name:     "other error",
+			err:      errors.New("timeout"),
+			expected: false,
+		},
+		{
+			name:               "resource still updating",
+			err:                WithTransientError(ProvisioningStateError{ResourceName: "test-nsg", State: "updating"}, 0),
+			expected:           true,
+			expectedInProgress: true,
+		},
+		{
+			name:               "resource failed to provision",
+			err:                errors.Wrap(ProvisioningStateError{ResourceName: "test-nsg", State: infrav1.Failed}, "failed to reconcile"),
+			expected:           true,
+			expectedInProgress: false,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			pse, ok := AsProvisioningStateError(tc.err)
+			g.Expect(ok).To(Equal(tc.expected))
+			g.Expect(pse.InProgress()).To(Equal(tc.expectedInProgress))
+		})
+	}
+}
+
+func TestAsOperationNotDoneError(t *testing.T) {
+	future := &infrav1.Future{Type: infrav1.PutFuture, ServiceName: "securitygroups", Name: "test-nsg", ResourceGroup: "test-group"}
+
+	testcases := []struct {
+		name                 string
+		err                  error
+		expected             bool
+		expectedRequeueAfter time.Duration
+	}{
+		{
+			name:     "nil error",
+			err:      nil,
+			expected: false,
+		},
+		{
+			name:     "other error",
+			err:      WithTransientError(errors.New("timeout"), 15*time.Second),
+			expected: false,
+		},
+		{
+			name:     "operation not done",
+			err:      NewOperationNotDoneError(future),
+			expected: true,
+		},
+		{
+			name:                 "transient operation not done error",
+			err:                  WithTransientError(NewOperationNotDoneError(future), 15*time.Second),
+			expected:             true,
+			expectedRequeueAfter: 15 * time.Second,
+		},
+		{
+			name:                 "wrapped operation not done error",
+			err:                  errors.Wrap(WithTransientError(errors.Wrap(NewOperationNotDoneError(future), "waiting for dependencies"), time.Minute), "failed to reconcile"),
+			expected:             true,
+			expectedRequeueAfter: time.Minute,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			onde, ok := AsOperationNotDoneError(tc.err)
+			g.Expect(ok).To(Equal(tc.expected))
+			if !tc.expected {
+				return
+			}
+			g.Expect(onde.OperationType()).To(Equal(infrav1.PutFuture))
+			g.Expect(onde.ResourceGroup()).To(Equal("test-group"))
+			g.Expect(onde.ResourceName()).To(Equal("test-nsg"))
+			g.Expect(onde.ServiceName()).To(Equal("securitygroups"))
+			g.Expect(onde.RequeueAfter()).To(Equal(tc.expectedRequeueAfter))
+			g.Expect(onde.Error()).To(Equal("operation type PUT on Azure resource test-group/test-nsg is not done"))
+		})
+	}
+}
+
+func TestOperationNotDoneErrorWithoutFuture(t *testing.T) {
+	g := NewWithT(t)
+	onde := OperationNotDoneError{}
+	g.Expect(onde.OperationType()).To(BeEmpty())
+	g.Expect(onde.ResourceGroup()).To(BeEmpty())
+	g.Expect(onde.ResourceName()).To(BeEmpty())
+	g.Expect(onde.ServiceName()).To(BeEmpty())
+	g.Expect(onde.RequeueAfter()).To(BeZero())
+}
+
+func TestRequeueAfter(t *testing.T) {
+	future := &infrav1.Future{Type: infrav1.PutFuture, ServiceName: "securitygroups", Name: "test-nsg", ResourceGroup: "test-group"}
+
+	testcases := []struct {
+		name                 string
+		err                  error
+		expected             bool
+		expectedRequeueAfter time.Duration
+	}{
+		{
+			name:     "nil error",
+			err:      nil,
+			expected: false,
+		},
+		{
+			name:     "error that is not a reconcile error",
+			err:      errors.New("timeout"),
+			expected: false,
+		},
+		{
+			name:     "terminal error",
+			err:      WithTerminalError(errors.New("invalid configuration")),
+			expected: false,
+		},
+		{
+			name:                 "transient error",
+			err:                  WithTransientError(errors.New("timeout"), 15*time.Second),
+			expected:             true,
+			expectedRequeueAfter: 15 * time.Second,
+		},
+		{
+			name:                 "wrapped operation not done error",
+			err:                  errors.Wrap(WithTransientError(NewOperationNotDoneError(future), 42*time.Second), "failed to reconcile"),
+			expected:             true,
+			expectedRequeueAfter: 42 * time.Second,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			requeueAfter, ok := RequeueAfter(tc.err)
+			g.Expect(ok).To(Equal(tc.expected))
+			g.Expect(requeueAfter).To(Equal(tc.expectedRequeueAfter))
+		})
+	}
+}
@@ -86,10 +86,12 @@ type ClusterDescriber interface {
 type AsyncStatusUpdater interface {
 	SetLongRunningOperationState(*infrav1.Future)
 	GetLongRunningOperationState(string, string) *infrav1.Future
+	GetLongRunningOperationStates() []*infrav1.Future
 	DeleteLongRunningOperationState(string, string)
 	UpdatePutStatus(clusterv1.ConditionType, string, error)
 	UpdateDeleteStatus(clusterv1.ConditionType, string, error)
 	UpdatePatchStatus(clusterv1.ConditionType, string, error)
+	IsServicePaused(string) bool
 }
 
 // ClusterScoper combines the ClusterDescriber and NetworkDescriber interfaces.
@@ -112,3 +114,69 @@ type ResourceSpecGetter interface {
 	// If no update is needed on the resource, Parameters should return nil.
 	Parameters(existing interface{}) (params interface{}, err error)
 }
+
+// PatchSpecGetter is a ResourceSpecGetter whose existing resource can be updated with a PATCH of its parameters
+// instead of being replaced by a PUT.
+type PatchSpecGetter interface {
+	ResourceSpecGetter
+	// UsePatch returns true if the existing resource should be updated with a PATCH request.
+	UsePatch() bool
+}
+
+// ResultTransformer is a ResourceSpecGetter that maps the result of the operations on its resource, e.g. to extract
+// its provisioning state or a computed property, so that services do not have to type assert the SDK type.
+type ResultTransformer interface {
+	ResourceSpecGetter
+	// TransformResult takes the SDK result of a completed operation on the resource and returns the value passed to the service.
+	TransformResult(result interface{}) (interface{}, error)
+}
+
+// QuotaSpecGetter is a ResourceSpecGetter whose resource counts against a quota of the subscription, e.g. the number
+// of security groups per region, so that its creation can be checked against the quota before it is requested.
+type QuotaSpecGetter interface {
+	ResourceSpecGetter
+	// QuotaRequirement returns the quota the creation of the resource consumes.
+	QuotaRequirement() QuotaRequirement
+}
+
+// QuotaRequirement is the usage of a quota of the subscription consumed by the creation of a resource.
+type QuotaRequirement struct {
+	// Location is the region of the quota.
+	Location string
+	// Name is the name of the quota as reported by the usage APIs of Azure, e.g. "NetworkSecurityGroups".
+	Name string
+	// Amount is how much of the quota the creation consumes.
+	Amount int64
+}
+
+// AdoptOnlySpecGetter is a ResourceSpecGetter whose resource may have to be created by the user beforehand, in which
+// case CAPZ only adopts the existing resource and never creates it.
+type AdoptOnlySpecGetter interface {
+	ResourceSpecGetter
+	// AdoptOnly returns true if the resource must already exist rather than be created when it is missing.
+	AdoptOnly() bool
+}
+
+// SubscriptionSpecGetter is a ResourceSpecGetter whose resource may be in a subscription other than the one of the
+// cluster, so that it is not mistaken for a resource with the same name in another subscription.
+type SubscriptionSpecGetter interface {
+	ResourceSpecGetter
+	// SubscriptionID returns the ID of the subscription the resource is in, or an empty string for the subscription of
+	// the cluster.
+	SubscriptionID() string
+}
+
+// DependentSpecGetter is a ResourceSpecGetter whose resource can only be created once other resources are ready, e.g.
+// a subnet referencing a security group.
+type DependentSpecGetter interface {
+	ResourceSpecGetter
+	// DependsOn returns the references of the resources the resource depends on, each made of the name of the service
+	// managing the resource, the name of its resource group and the name of the resource, e.g.
+	// "securitygroups/my-rg/node-nsg", see DependencyRef.
+	DependsOn() []string
+}
+
+// DependencyRef returns the reference of a resource returned by DependentSpecGetter.DependsOn.
+func DependencyRef(serviceName, resourceGroup, resourceName string) string {
+	return serviceName + "/" + resourceGroup + "/" + resourceName
+}
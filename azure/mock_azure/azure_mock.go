@@ -809,6 +809,34 @@ func (mr *MockAsyncStatusUpdaterMockRecorder) GetLongRunningOperationState(arg0,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLongRunningOperationState", reflect.TypeOf((*MockAsyncStatusUpdater)(nil).GetLongRunningOperationState), arg0, arg1)
 }
 
+// GetLongRunningOperationStates mocks base method.
+func (m *MockAsyncStatusUpdater) GetLongRunningOperationStates() []*v1beta1.Future {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLongRunningOperationStates")
+	ret0, _ := ret[0].([]*v1beta1.Future)
+	return ret0
+}
+
+// GetLongRunningOperationStates indicates an expected call of GetLongRunningOperationStates.
+func (mr *MockAsyncStatusUpdaterMockRecorder) GetLongRunningOperationStates() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLongRunningOperationStates", reflect.TypeOf((*MockAsyncStatusUpdater)(nil).GetLongRunningOperationStates))
+}
+
+// IsServicePaused mocks base method.
+func (m *MockAsyncStatusUpdater) IsServicePaused(arg0 string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsServicePaused", arg0)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsServicePaused indicates an expected call of IsServicePaused.
+func (mr *MockAsyncStatusUpdaterMockRecorder) IsServicePaused(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsServicePaused", reflect.TypeOf((*MockAsyncStatusUpdater)(nil).IsServicePaused), arg0)
+}
+
 // SetLongRunningOperationState mocks base method.
 func (m *MockAsyncStatusUpdater) SetLongRunningOperationState(arg0 *v1beta1.Future) {
 	m.ctrl.T.Helper()
@@ -1391,3 +1419,286 @@ func (mr *MockResourceSpecGetterMockRecorder) ResourceName() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResourceName", reflect.TypeOf((*MockResourceSpecGetter)(nil).ResourceName))
 }
+
+// MockPatchSpecGetter is a mock of PatchSpecGetter interface.
+type MockPatchSpecGetter struct {
+	ctrl     *gomock.Controller
+	recorder *MockPatchSpecGetterMockRecorder
+}
+
+// MockPatchSpecGetterMockRecorder is the mock recorder for MockPatchSpecGetter.
+type MockPatchSpecGetterMockRecorder struct {
+	mock *MockPatchSpecGetter
+}
+
+// NewMockPatchSpecGetter creates a new mock instance.
+func NewMockPatchSpecGetter(ctrl *gomock.Controller) *MockPatchSpecGetter {
+	mock := &MockPatchSpecGetter{ctrl: ctrl}
+	mock.recorder = &MockPatchSpecGetterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPatchSpecGetter) EXPECT() *MockPatchSpecGetterMockRecorder {
+	return m.recorder
+}
+
+// OwnerResourceName mocks base method.
+func (m *MockPatchSpecGetter) OwnerResourceName() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OwnerResourceName")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// OwnerResourceName indicates an expected call of OwnerResourceName.
+func (mr *MockPatchSpecGetterMockRecorder) OwnerResourceName() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OwnerResourceName", reflect.TypeOf((*MockPatchSpecGetter)(nil).OwnerResourceName))
+}
+
+// Parameters mocks base method.
+func (m *MockPatchSpecGetter) Parameters(existing interface{}) (interface{}, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Parameters", existing)
+	ret0, _ := ret[0].(interface{})
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Parameters indicates an expected call of Parameters.
+func (mr *MockPatchSpecGetterMockRecorder) Parameters(existing interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Parameters", reflect.TypeOf((*MockPatchSpecGetter)(nil).Parameters), existing)
+}
+
+// ResourceGroupName mocks base method.
+func (m *MockPatchSpecGetter) ResourceGroupName() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResourceGroupName")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// ResourceGroupName indicates an expected call of ResourceGroupName.
+func (mr *MockPatchSpecGetterMockRecorder) ResourceGroupName() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResourceGroupName", reflect.TypeOf((*MockPatchSpecGetter)(nil).ResourceGroupName))
+}
+
+// ResourceName mocks base method.
+func (m *MockPatchSpecGetter) ResourceName() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResourceName")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// ResourceName indicates an expected call of ResourceName.
+func (mr *MockPatchSpecGetterMockRecorder) ResourceName() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResourceName", reflect.TypeOf((*MockPatchSpecGetter)(nil).ResourceName))
+}
+
+// UsePatch mocks base method.
+func (m *MockPatchSpecGetter) UsePatch() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UsePatch")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// UsePatch indicates an expected call of UsePatch.
+func (mr *MockPatchSpecGetterMockRecorder) UsePatch() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UsePatch", reflect.TypeOf((*MockPatchSpecGetter)(nil).UsePatch))
+}
+
+// MockResultTransformer is a mock of ResultTransformer interface.
+type MockResultTransformer struct {
+	ctrl     *gomock.Controller
+	recorder *MockResultTransformerMockRecorder
+}
+
+// MockResultTransformerMockRecorder is the mock recorder for MockResultTransformer.
+type MockResultTransformerMockRecorder struct {
+	mock *MockResultTransformer
+}
+
+// NewMockResultTransformer creates a new mock instance.
+func NewMockResultTransformer(ctrl *gomock.Controller) *MockResultTransformer {
+	mock := &MockResultTransformer{ctrl: ctrl}
+	mock.recorder = &MockResultTransformerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockResultTransformer) EXPECT() *MockResultTransformerMockRecorder {
+	return m.recorder
+}
+
+// OwnerResourceName mocks base method.
+func (m *MockResultTransformer) OwnerResourceName() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OwnerResourceName")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// OwnerResourceName indicates an expected call of OwnerResourceName.
+func (mr *MockResultTransformerMockRecorder) OwnerResourceName() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OwnerResourceName", reflect.TypeOf((*MockResultTransformer)(nil).OwnerResourceName))
+}
+
+// Parameters mocks base method.
+func (m *MockResultTransformer) Parameters(existing interface{}) (interface{}, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Parameters", existing)
+	ret0, _ := ret[0].(interface{})
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Parameters indicates an expected call of Parameters.
+func (mr *MockResultTransformerMockRecorder) Parameters(existing interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Parameters", reflect.TypeOf((*MockResultTransformer)(nil).Parameters), existing)
+}
+
+// ResourceGroupName mocks base method.
+func (m *MockResultTransformer) ResourceGroupName() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResourceGroupName")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// ResourceGroupName indicates an expected call of ResourceGroupName.
+func (mr *MockResultTransformerMockRecorder) ResourceGroupName() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResourceGroupName", reflect.TypeOf((*MockResultTransformer)(nil).ResourceGroupName))
+}
+
+// ResourceName mocks base method.
+func (m *MockResultTransformer) ResourceName() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResourceName")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// ResourceName indicates an expected call of ResourceName.
+func (mr *MockResultTransformerMockRecorder) ResourceName() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResourceName", reflect.TypeOf((*MockResultTransformer)(nil).ResourceName))
+}
+
+// TransformResult mocks base method.
+func (m *MockResultTransformer) TransformResult(result interface{}) (interface{}, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TransformResult", result)
+	ret0, _ := ret[0].(interface{})
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TransformResult indicates an expected call of TransformResult.
+func (mr *MockResultTransformerMockRecorder) TransformResult(result interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransformResult", reflect.TypeOf((*MockResultTransformer)(nil).TransformResult), result)
+}
+
+// MockDependentSpecGetter is a mock of DependentSpecGetter interface.
+type MockDependentSpecGetter struct {
+	ctrl     *gomock.Controller
+	recorder *MockDependentSpecGetterMockRecorder
+}
+
+// MockDependentSpecGetterMockRecorder is the mock recorder for MockDependentSpecGetter.
+type MockDependentSpecGetterMockRecorder struct {
+	mock *MockDependentSpecGetter
+}
+
+// NewMockDependentSpecGetter creates a new mock instance.
+func NewMockDependentSpecGetter(ctrl *gomock.Controller) *MockDependentSpecGetter {
+	mock := &MockDependentSpecGetter{ctrl: ctrl}
+	mock.recorder = &MockDependentSpecGetterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDependentSpecGetter) EXPECT() *MockDependentSpecGetterMockRecorder {
+	return m.recorder
+}
+
+// DependsOn mocks base method.
+func (m *MockDependentSpecGetter) DependsOn() []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DependsOn")
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// DependsOn indicates an expected call of DependsOn.
+func (mr *MockDependentSpecGetterMockRecorder) DependsOn() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DependsOn", reflect.TypeOf((*MockDependentSpecGetter)(nil).DependsOn))
+}
+
+// OwnerResourceName mocks base method.
+func (m *MockDependentSpecGetter) OwnerResourceName() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OwnerResourceName")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// OwnerResourceName indicates an expected call of OwnerResourceName.
+func (mr *MockDependentSpecGetterMockRecorder) OwnerResourceName() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OwnerResourceName", reflect.TypeOf((*MockDependentSpecGetter)(nil).OwnerResourceName))
+}
+
+// Parameters mocks base method.
+func (m *MockDependentSpecGetter) Parameters(existing interface{}) (interface{}, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Parameters", existing)
+	ret0, _ := ret[0].(interface{})
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Parameters indicates an expected call of Parameters.
+func (mr *MockDependentSpecGetterMockRecorder) Parameters(existing interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Parameters", reflect.TypeOf((*MockDependentSpecGetter)(nil).Parameters), existing)
+}
+
+// ResourceGroupName mocks base method.
+func (m *MockDependentSpecGetter) ResourceGroupName() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResourceGroupName")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// ResourceGroupName indicates an expected call of ResourceGroupName.
+func (mr *MockDependentSpecGetterMockRecorder) ResourceGroupName() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResourceGroupName", reflect.TypeOf((*MockDependentSpecGetter)(nil).ResourceGroupName))
+}
+
+// ResourceName mocks base method.
+func (m *MockDependentSpecGetter) ResourceName() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResourceName")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// ResourceName indicates an expected call of ResourceName.
+func (mr *MockDependentSpecGetterMockRecorder) ResourceName() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResourceName", reflect.TypeOf((*MockDependentSpecGetter)(nil).ResourceName))
+}
@@ -23,10 +23,13 @@ import (
 	"hash/fnv"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/Azure/go-autorest/autorest"
 	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/net"
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 	"sigs.k8s.io/cluster-api-provider-azure/azure"
@@ -53,6 +56,8 @@ type ClusterScopeParams struct {
 	Client       client.Client
 	Cluster      *clusterv1.Cluster
 	AzureCluster *infrav1.AzureCluster
+	// ConditionSeverities overrides the severity of the conditions set by the service status updates.
+	ConditionSeverities ConditionSeverities
 }
 
 // NewClusterScope creates a new Scope from the supplied parameters.
@@ -90,18 +95,20 @@ func NewClusterScope(ctx context.Context, params ClusterScopeParams) (*ClusterSc
 	}
 
 	return &ClusterScope{
-		Client:       params.Client,
-		AzureClients: params.AzureClients,
-		Cluster:      params.Cluster,
-		AzureCluster: params.AzureCluster,
-		patchHelper:  helper,
+		Client:              params.Client,
+		AzureClients:        params.AzureClients,
+		Cluster:             params.Cluster,
+		AzureCluster:        params.AzureCluster,
+		patchHelper:         helper,
+		conditionSeverities: params.ConditionSeverities,
 	}, nil
 }
 
 // ClusterScope defines the basic context for an actuator to operate upon.
 type ClusterScope struct {
-	Client      client.Client
-	patchHelper *patch.Helper
+	Client              client.Client
+	patchHelper         *patch.Helper
+	conditionSeverities ConditionSeverities
 
 	AzureClients
 	Cluster      *clusterv1.Cluster
@@ -279,19 +286,271 @@ func (s *ClusterScope) NatGatewaySpecs() []azure.ResourceSpecGetter {
 
 // NSGSpecs returns the security group specs.
 func (s *ClusterScope) NSGSpecs() []azure.ResourceSpecGetter {
+	confirmedDeletions := s.confirmedRuleDeletions()
+	workspaceID := s.securityGroupDiagnosticsWorkspaceID()
+	subnetCIDRs := s.subnetCIDRs()
+	sharedOwnership := strings.EqualFold(strings.TrimSpace(s.AzureCluster.GetAnnotations()[azure.SharedSecurityGroupsAnnotation]), "true")
+	ignoreTagDrift := strings.EqualFold(strings.TrimSpace(s.AzureCluster.GetAnnotations()[azure.IgnoreSecurityGroupTagDriftAnnotation]), "true")
+	pruneRules := strings.EqualFold(strings.TrimSpace(s.AzureCluster.GetAnnotations()[azure.PruneSecurityRulesAnnotation]), "true")
+	additiveSafeMode := strings.EqualFold(strings.TrimSpace(s.AzureCluster.GetAnnotations()[azure.SecurityRulesAdditiveSafeModeAnnotation]), "true")
+	defaultDenyOutbound := strings.EqualFold(strings.TrimSpace(s.AzureCluster.GetAnnotations()[azure.DefaultDenyOutboundAnnotation]), "true")
+	firewallPolicyID := strings.TrimSpace(s.AzureCluster.GetAnnotations()[azure.FirewallPolicyAnnotation])
 	nsgspecs := make([]azure.ResourceSpecGetter, len(s.AzureCluster.Spec.NetworkSpec.Subnets))
 	for i, subnet := range s.AzureCluster.Spec.NetworkSpec.Subnets {
-		nsgspecs[i] = &securitygroups.NSGSpec{
-			Name:          subnet.SecurityGroup.Name,
-			SecurityRules: subnet.SecurityGroup.SecurityRules,
-			ResourceGroup: s.ResourceGroup(),
-			Location:      s.Location(),
+		location := subnet.SecurityGroup.Location
+		if location == "" {
+			location = s.Location()
+		}
+		spec := &securitygroups.NSGSpec{
+			Name:                   subnet.SecurityGroup.Name,
+			SecurityRules:          subnet.SecurityGroup.SecurityRules,
+			ResourceGroup:          s.securityGroupResourceGroup(subnet.SecurityGroup),
+			Location:               location,
+			ClusterName:            s.ClusterName(),
+			Reconciled:             s.securityGroupReconciled(subnet.SecurityGroup.Name),
+			AdditionalTags:         s.AdditionalTags(),
+			IgnoreTagDrift:         ignoreTagDrift,
+			DefaultDenyOutbound:    subnet.SecurityGroup.DefaultDenyOutbound || defaultDenyOutbound,
+			PruneRules:             pruneRules,
+			AdditiveSafeMode:       additiveSafeMode,
+			ConfirmedRuleDeletions: confirmedDeletions,
+			SubnetCIDRs:            subnetCIDRs,
+			SharedOwnership:        sharedOwnership,
+			RuleSets:               s.securityRuleSets(subnet.SecurityGroup.RuleSets),
+			PriorityAssignment:     subnet.SecurityGroup.PriorityAssignment,
+			RuleNamePrefix:         subnet.SecurityGroup.RuleNamePrefix,
+			InheritedTagKeys:       subnet.SecurityGroup.InheritedResourceGroupTags,
+			FirewallPolicyID:       firewallPolicyID,
+		}
+		if flowLog := subnet.SecurityGroup.FlowLog; flowLog != nil {
+			spec.FlowLog = &securitygroups.FlowLogSpec{
+				StorageAccountID: flowLog.StorageAccountID,
+				RetentionDays:    flowLog.RetentionDays,
+			}
+		}
+		if workspaceID != "" {
+			spec.DiagnosticSettings = &securitygroups.DiagnosticSettingsSpec{WorkspaceID: workspaceID}
 		}
+		nsgspecs[i] = spec
 	}
 
 	return nsgspecs
 }
 
+// securityGroupReconciled returns true if the security group with the given name was already reconciled for the
+// cluster, i.e. it is summarized in the AzureCluster status. The security groups of a cluster reconciled by an earlier
+// release, which neither recorded them in the status nor tagged them as owned, are all reconciled if the
+// SecurityGroupsReady condition is true and no progress was recorded yet.
+func (s *ClusterScope) securityGroupReconciled(name string) bool {
+	for _, status := range s.AzureCluster.Status.SecurityGroups {
+		if strings.EqualFold(status.Name, name) {
+			return true
+		}
+	}
+	return s.AzureCluster.Status.SecurityGroupsProgress == nil && conditions.IsTrue(s.AzureCluster, infrav1.SecurityGroupsReadyCondition)
+}
+
+// securityGroupResourceGroup returns the resource group of a security group, which defaults to the resource group of
+// the cluster.
+func (s *ClusterScope) securityGroupResourceGroup(securityGroup infrav1.SecurityGroup) string {
+	if securityGroup.ResourceGroup != "" {
+		return securityGroup.ResourceGroup
+	}
+	return s.ResourceGroup()
+}
+
+// HasSecurityGroupsOutsideResourceGroup returns true if a security group of the cluster lives outside of the resource
+// group of the cluster, and is therefore not deleted with it.
+func (s *ClusterScope) HasSecurityGroupsOutsideResourceGroup() bool {
+	for _, subnet := range s.AzureCluster.Spec.NetworkSpec.Subnets {
+		if subnet.SecurityGroup.Name != "" && !strings.EqualFold(s.securityGroupResourceGroup(subnet.SecurityGroup), s.ResourceGroup()) {
+			return true
+		}
+	}
+	return false
+}
+
+// securityRuleSets returns the security rule sets of the network spec with the given names, in order. Unknown names
+// are rejected by the webhook and skipped.
+func (s *ClusterScope) securityRuleSets(names []string) []infrav1.SecurityRuleSet {
+	var ruleSets []infrav1.SecurityRuleSet
+	for _, name := range names {
+		for _, ruleSet := range s.AzureCluster.Spec.NetworkSpec.SecurityRuleSets {
+			if ruleSet.Name == name {
+				ruleSets = append(ruleSets, ruleSet)
+				break
+			}
+		}
+	}
+	return ruleSets
+}
+
+// SecurityRuleSources returns the data of the key of the ConfigMap or Secret referenced by a security rule to read its
+// sources from. The object is always read from the namespace of the AzureCluster.
+func (s *ClusterScope) SecurityRuleSources(ctx context.Context, ref infrav1.SecurityRuleSourcesReference) (string, error) {
+	key := client.ObjectKey{Namespace: s.AzureCluster.Namespace, Name: ref.Name}
+	switch ref.Kind {
+	case infrav1.SecurityRuleSourcesFromConfigMap:
+		configMap := &corev1.ConfigMap{}
+		if err := s.Client.Get(ctx, key, configMap); err != nil {
+			return "", errors.Wrapf(err, "failed to get ConfigMap %s", key)
+		}
+		data, ok := configMap.Data[ref.Key]
+		if !ok {
+			return "", errors.Errorf("key %s not found in ConfigMap %s", ref.Key, key)
+		}
+		return data, nil
+	case infrav1.SecurityRuleSourcesFromSecret:
+		secret := &corev1.Secret{}
+		if err := s.Client.Get(ctx, key, secret); err != nil {
+			return "", errors.Wrapf(err, "failed to get Secret %s", key)
+		}
+		data, ok := secret.Data[ref.Key]
+		if !ok {
+			return "", errors.Errorf("key %s not found in Secret %s", ref.Key, key)
+		}
+		return string(data), nil
+	default:
+		return "", errors.Errorf("unsupported kind %s, the sources of a security rule can only be read from a ConfigMap or a Secret", ref.Kind)
+	}
+}
+
+// SetSecurityGroupStatus records the summary of a reconciled security group in the AzureCluster status, replacing
+// the previous summary of the same security group. Rules whose etag is unchanged since the previous summary keep
+// their last applied time, the others were created or updated since and are stamped with the current time.
+func (s *ClusterScope) SetSecurityGroupStatus(status infrav1.SecurityGroupStatus) {
+	now := metav1.Now()
+	for i, existing := range s.AzureCluster.Status.SecurityGroups {
+		if strings.EqualFold(existing.Name, status.Name) {
+			setRulesLastAppliedTime(status.Rules, existing.Rules, now)
+			s.AzureCluster.Status.SecurityGroups[i] = status
+			return
+		}
+	}
+	setRulesLastAppliedTime(status.Rules, nil, now)
+	s.AzureCluster.Status.SecurityGroups = append(s.AzureCluster.Status.SecurityGroups, status)
+}
+
+// setRulesLastAppliedTime sets the last applied time of rules from the previous summary of the same rules.
+func setRulesLastAppliedTime(rules, previous []infrav1.SecurityRuleStatus, now metav1.Time) {
+	for i := range rules {
+		rules[i].LastAppliedTime = now.DeepCopy()
+		for _, prev := range previous {
+			if prev.Name == rules[i].Name && prev.ETag == rules[i].ETag && prev.LastAppliedTime != nil {
+				rules[i].LastAppliedTime = prev.LastAppliedTime.DeepCopy()
+				break
+			}
+		}
+	}
+}
+
+// SetSecurityGroupsProgress records in the AzureCluster status how many security groups are ready, in progress or
+// failed. The summary is also added to the message of the SecurityGroupsReady condition while it is false, e.g.
+// "3/5 security groups ready, 2 in progress, 0 failed".
+func (s *ClusterScope) SetSecurityGroupsProgress(progress infrav1.SecurityGroupsProgress) {
+	previous := s.AzureCluster.Status.SecurityGroupsProgress
+	s.AzureCluster.Status.SecurityGroupsProgress = &progress
+	condition := conditions.Get(s.AzureCluster, infrav1.SecurityGroupsReadyCondition)
+	if condition == nil || condition.Status != corev1.ConditionFalse {
+		return
+	}
+	// The condition keeps the message of a previous reconcile if it was not updated since, replace its summary.
+	message := condition.Message
+	if previous != nil {
+		message = strings.TrimSuffix(message, fmt.Sprintf(" (%s)", *previous))
+	}
+	condition.Message = fmt.Sprintf("%s (%s)", message, progress)
+	conditions.Set(s.AzureCluster, condition)
+}
+
+// SetSecurityGroupPendingChanges records the rule changes of a security group that were computed in dry-run mode but
+// not applied in the AzureCluster status, replacing the previous changes of the same security group.
+func (s *ClusterScope) SetSecurityGroupPendingChanges(changes infrav1.SecurityGroupPendingChanges) {
+	for i, existing := range s.AzureCluster.Status.PendingChanges {
+		if strings.EqualFold(existing.Name, changes.Name) {
+			s.AzureCluster.Status.PendingChanges[i] = changes
+			return
+		}
+	}
+	s.AzureCluster.Status.PendingChanges = append(s.AzureCluster.Status.PendingChanges, changes)
+}
+
+// DeleteSecurityGroupPendingChanges removes the pending rule changes of a security group from the AzureCluster status.
+func (s *ClusterScope) DeleteSecurityGroupPendingChanges(name string) {
+	pending := s.AzureCluster.Status.PendingChanges[:0]
+	for _, changes := range s.AzureCluster.Status.PendingChanges {
+		if !strings.EqualFold(changes.Name, name) {
+			pending = append(pending, changes)
+		}
+	}
+	if len(pending) == 0 {
+		pending = nil
+	}
+	s.AzureCluster.Status.PendingChanges = pending
+}
+
+// SecurityGroupsDryRun returns true if the AzureCluster asks for its security groups to be reconciled in dry-run mode
+// with the security groups dry-run annotation. Security groups are never deleted in dry-run mode, so the annotation is
+// ignored once the AzureCluster is being deleted.
+func (s *ClusterScope) SecurityGroupsDryRun() bool {
+	if !s.AzureCluster.DeletionTimestamp.IsZero() {
+		return false
+	}
+	return strings.EqualFold(strings.TrimSpace(s.AzureCluster.GetAnnotations()[azure.SecurityGroupsDryRunAnnotation]), "true")
+}
+
+// SecurityGroupsQuotaCheck returns true if the AzureCluster asks for the quota of security groups to be checked before
+// they are created with the security groups quota check annotation.
+func (s *ClusterScope) SecurityGroupsQuotaCheck() bool {
+	return strings.EqualFold(strings.TrimSpace(s.AzureCluster.GetAnnotations()[azure.SecurityGroupsQuotaCheckAnnotation]), "true")
+}
+
+// confirmedRuleDeletions returns the names of the security rules whose deletion is confirmed on the AzureCluster.
+func (s *ClusterScope) confirmedRuleDeletions() []string {
+	value, ok := s.AzureCluster.GetAnnotations()[azure.ConfirmedRuleDeletionsAnnotation]
+	if !ok {
+		return nil
+	}
+	var names []string
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// subnetCIDRs returns the CIDR blocks of the subnets of the cluster by name, as currently known in the AzureCluster spec.
+func (s *ClusterScope) subnetCIDRs() map[string][]string {
+	cidrs := make(map[string][]string, len(s.AzureCluster.Spec.NetworkSpec.Subnets))
+	for _, subnet := range s.AzureCluster.Spec.NetworkSpec.Subnets {
+		cidrs[subnet.Name] = subnet.CIDRBlocks
+	}
+	return cidrs
+}
+
+// securityGroupDiagnosticsWorkspaceID returns the ID of the Log Analytics workspace the logs of the security groups
+// are sent to, or an empty string if they are not sent anywhere.
+func (s *ClusterScope) securityGroupDiagnosticsWorkspaceID() string {
+	return strings.TrimSpace(s.AzureCluster.GetAnnotations()[azure.SecurityGroupDiagnosticsWorkspaceAnnotation])
+}
+
+// SecurityGroupDiagnosticSettingsEnabled returns true if the logs of the security groups are sent to a Log Analytics
+// workspace.
+func (s *ClusterScope) SecurityGroupDiagnosticSettingsEnabled() bool {
+	return s.securityGroupDiagnosticsWorkspaceID() != ""
+}
+
+// SecurityGroupFlowLogsEnabled returns true if the flow logs of any security group are enabled.
+func (s *ClusterScope) SecurityGroupFlowLogsEnabled() bool {
+	for _, subnet := range s.AzureCluster.Spec.NetworkSpec.Subnets {
+		if subnet.SecurityGroup.FlowLog != nil {
+			return true
+		}
+	}
+	return false
+}
+
 // SubnetSpecs returns the subnets specs.
 func (s *ClusterScope) SubnetSpecs() []azure.ResourceSpecGetter {
 	numberOfSubnets := len(s.AzureCluster.Spec.NetworkSpec.Subnets)
@@ -303,17 +562,18 @@ func (s *ClusterScope) SubnetSpecs() []azure.ResourceSpecGetter {
 
 	for _, subnet := range s.AzureCluster.Spec.NetworkSpec.Subnets {
 		subnetSpec := &subnets.SubnetSpec{
-			Name:              subnet.Name,
-			ResourceGroup:     s.ResourceGroup(),
-			SubscriptionID:    s.SubscriptionID(),
-			CIDRs:             subnet.CIDRBlocks,
-			VNetName:          s.Vnet().Name,
-			VNetResourceGroup: s.Vnet().ResourceGroup,
-			IsVNetManaged:     s.IsVnetManaged(),
-			RouteTableName:    subnet.RouteTable.Name,
-			SecurityGroupName: subnet.SecurityGroup.Name,
-			Role:              subnet.Role,
-			NatGatewayName:    subnet.NatGateway.Name,
+			Name:                       subnet.Name,
+			ResourceGroup:              s.ResourceGroup(),
+			SubscriptionID:             s.SubscriptionID(),
+			CIDRs:                      subnet.CIDRBlocks,
+			VNetName:                   s.Vnet().Name,
+			VNetResourceGroup:          s.Vnet().ResourceGroup,
+			IsVNetManaged:              s.IsVnetManaged(),
+			RouteTableName:             subnet.RouteTable.Name,
+			SecurityGroupName:          subnet.SecurityGroup.Name,
+			SecurityGroupResourceGroup: s.securityGroupResourceGroup(subnet.SecurityGroup),
+			Role:                       subnet.Role,
+			NatGatewayName:             subnet.NatGateway.Name,
 		}
 		subnetSpecs = append(subnetSpecs, subnetSpec)
 	}
@@ -321,16 +581,17 @@ func (s *ClusterScope) SubnetSpecs() []azure.ResourceSpecGetter {
 	if s.IsAzureBastionEnabled() {
 		azureBastionSubnet := s.AzureCluster.Spec.BastionSpec.AzureBastion.Subnet
 		subnetSpecs = append(subnetSpecs, &subnets.SubnetSpec{
-			Name:              azureBastionSubnet.Name,
-			ResourceGroup:     s.ResourceGroup(),
-			SubscriptionID:    s.SubscriptionID(),
-			CIDRs:             azureBastionSubnet.CIDRBlocks,
-			VNetName:          s.Vnet().Name,
-			VNetResourceGroup: s.Vnet().ResourceGroup,
-			IsVNetManaged:     s.IsVnetManaged(),
-			SecurityGroupName: azureBastionSubnet.SecurityGroup.Name,
-			RouteTableName:    azureBastionSubnet.RouteTable.Name,
-			Role:              azureBastionSubnet.Role,
+			Name:                       azureBastionSubnet.Name,
+			ResourceGroup:              s.ResourceGroup(),
+			SubscriptionID:             s.SubscriptionID(),
+			CIDRs:                      azureBastionSubnet.CIDRBlocks,
+			VNetName:                   s.Vnet().Name,
+			VNetResourceGroup:          s.Vnet().ResourceGroup,
+			IsVNetManaged:              s.IsVnetManaged(),
+			SecurityGroupName:          azureBastionSubnet.SecurityGroup.Name,
+			SecurityGroupResourceGroup: s.securityGroupResourceGroup(azureBastionSubnet.SecurityGroup),
+			RouteTableName:             azureBastionSubnet.RouteTable.Name,
+			Role:                       azureBastionSubnet.Role,
 		})
 	}
 
@@ -850,36 +1111,187 @@ func (s *ClusterScope) GetLongRunningOperationState(name, service string) *infra
 	return futures.Get(s.AzureCluster, name, service)
 }
 
+// GetLongRunningOperationStates will get all the futures on the AzureCluster status.
+func (s *ClusterScope) GetLongRunningOperationStates() []*infrav1.Future {
+	return futures.List(s.AzureCluster)
+}
+
 // DeleteLongRunningOperationState will delete the future from the AzureCluster status.
 func (s *ClusterScope) DeleteLongRunningOperationState(name, service string) {
 	futures.Delete(s.AzureCluster, name, service)
 }
 
+// GetLongRunningOperationStateInResourceGroup will get the future of the resource in the given resource group on the
+// AzureCluster status, e.g. for security groups with the same name in different resource groups.
+func (s *ClusterScope) GetLongRunningOperationStateInResourceGroup(name, service, resourceGroup string) *infrav1.Future {
+	return futures.GetInResourceGroup(s.AzureCluster, name, service, resourceGroup)
+}
+
+// DeleteLongRunningOperationStateInResourceGroup will delete the future of the resource in the given resource group
+// from the AzureCluster status.
+func (s *ClusterScope) DeleteLongRunningOperationStateInResourceGroup(name, service, resourceGroup string) {
+	futures.DeleteInResourceGroup(s.AzureCluster, name, service, resourceGroup)
+}
+
+// SetCompletedOperation records a completed long-running operation on the AzureCluster status for a short time.
+func (s *ClusterScope) SetCompletedOperation(operation infrav1.CompletedOperation) {
+	futures.SetCompleted(s.AzureCluster, operation, time.Now())
+}
+
+// ResetLongRunningOperationState forcibly deletes the future of the resource from the AzureCluster status, e.g. to
+// recover from a corrupt future without waiting for it to expire. The futures of resources with the same name in
+// different resource groups are all reset. It is a no-op if no future is stored.
+func (s *ClusterScope) ResetLongRunningOperationState(ctx context.Context, name, service string) {
+	_, log, done := tele.StartSpanWithLogger(ctx, "scope.ClusterScope.ResetLongRunningOperationState")
+	defer done()
+
+	for future := s.GetLongRunningOperationState(name, service); future != nil; future = s.GetLongRunningOperationState(name, service) {
+		s.DeleteLongRunningOperationState(name, service)
+		log.Info("reset long running operation state", "service", service, "resource", name, "resourceGroup", future.ResourceGroup, "type", future.Type)
+	}
+}
+
+// ResetOperations resets the long running operation states listed in the reset operation annotation of the
+// AzureCluster, then removes the annotation so that they are only reset once.
+func (s *ClusterScope) ResetOperations(ctx context.Context) {
+	value, ok := s.AzureCluster.Annotations[azure.ResetOperationAnnotation]
+	if !ok {
+		return
+	}
+	for _, entry := range strings.Split(value, ",") {
+		parts := strings.SplitN(strings.TrimSpace(entry), "/", 2)
+		service := strings.TrimSpace(parts[0])
+		if service == "" {
+			continue
+		}
+		for _, future := range s.GetLongRunningOperationStates() {
+			if !strings.EqualFold(future.ServiceName, service) {
+				continue
+			}
+			if len(parts) == 2 && future.Name != strings.TrimSpace(parts[1]) {
+				continue
+			}
+			s.ResetLongRunningOperationState(ctx, future.Name, future.ServiceName)
+		}
+	}
+	delete(s.AzureCluster.Annotations, azure.ResetOperationAnnotation)
+}
+
+// IsServicePaused returns true if the cluster or the AzureCluster is paused, or if the AzureCluster lists the service in
+// its paused services annotation.
+func (s *ClusterScope) IsServicePaused(service string) bool {
+	return servicePaused(s.Cluster, s.AzureCluster, service)
+}
+
+// AppliedParameters returns the hash of the parameters last applied to a resource and the hash of the resource Azure
+// returned then, as recorded in the AzureCluster annotations.
+func (s *ClusterScope) AppliedParameters(name, service, resourceGroup string) (parametersHash, resourceHash string) {
+	applied, err := s.AnnotationJSON(azure.AppliedParametersAnnotation)
+	if err != nil {
+		return "", ""
+	}
+	entry, _ := applied[appliedParametersKey(name, service, resourceGroup)].(map[string]interface{})
+	parametersHash, _ = entry["parameters"].(string)
+	resourceHash, _ = entry["resource"].(string)
+	return parametersHash, resourceHash
+}
+
+// SetAppliedParameters records the hash of the parameters applied to a resource and the hash of the resource Azure
+// returned in the AzureCluster annotations.
+func (s *ClusterScope) SetAppliedParameters(name, service, resourceGroup, parametersHash, resourceHash string) {
+	applied, err := s.AnnotationJSON(azure.AppliedParametersAnnotation)
+	if err != nil {
+		// An unreadable annotation only records hashes, so it is replaced.
+		applied = map[string]interface{}{}
+	}
+	applied[appliedParametersKey(name, service, resourceGroup)] = map[string]interface{}{
+		"parameters": parametersHash,
+		"resource":   resourceHash,
+	}
+	// The hashes are strings, so they can always be marshalled.
+	_ = s.UpdateAnnotationJSON(azure.AppliedParametersAnnotation, applied)
+}
+
+// DeleteAppliedParameters removes the hashes recorded for a resource from the AzureCluster annotations.
+func (s *ClusterScope) DeleteAppliedParameters(name, service, resourceGroup string) {
+	applied, err := s.AnnotationJSON(azure.AppliedParametersAnnotation)
+	key := appliedParametersKey(name, service, resourceGroup)
+	if _, ok := applied[key]; err == nil && !ok {
+		return
+	}
+	delete(applied, key)
+	if err != nil || len(applied) == 0 {
+		delete(s.AzureCluster.Annotations, azure.AppliedParametersAnnotation)
+		return
+	}
+	// The hashes are strings, so they can always be marshalled.
+	_ = s.UpdateAnnotationJSON(azure.AppliedParametersAnnotation, applied)
+}
+
+// appliedParametersKey returns the key of the hashes recorded for a resource in the applied parameters annotation, so
+// that resources with the same name in different resource groups do not share a record. Records of previous versions,
+// keyed without the resource group, are never found, so their resources are only updated once more.
+func appliedParametersKey(name, service, resourceGroup string) string {
+	return service + "/" + resourceGroup + "/" + name
+}
+
 // UpdateDeleteStatus updates a condition on the AzureCluster status after a DELETE operation.
 func (s *ClusterScope) UpdateDeleteStatus(condition clusterv1.ConditionType, service string, err error) {
 	switch {
 	case err == nil:
-		conditions.MarkFalse(s.AzureCluster, condition, infrav1.DeletedReason, clusterv1.ConditionSeverityInfo, "%s successfully deleted", service)
+		conditions.MarkFalse(s.AzureCluster, condition, infrav1.DeletedReason, s.conditionSeverities.severity(OutcomeDeleted), "%s successfully deleted", service)
 	case errors.Is(err, azure.ErrNotOwned):
 		// do nothing
 	case azure.IsOperationNotDoneError(err):
-		conditions.MarkFalse(s.AzureCluster, condition, infrav1.DeletingReason, clusterv1.ConditionSeverityInfo, "%s deleting", service)
+		conditions.MarkFalse(s.AzureCluster, condition, infrav1.DeletingReason, s.conditionSeverities.severity(OutcomeInProgress), "%s deleting", service)
+	case azure.ResourceInUse(err):
+		conditions.MarkFalse(s.AzureCluster, condition, infrav1.DeletionBlockedReason, s.conditionSeverities.severity(OutcomeFailed), "%s deletion blocked by resources that still reference it. err: %s", service, err.Error())
+	case azure.IsThrottled(err):
+		conditions.MarkFalse(s.AzureCluster, condition, infrav1.AzureThrottledReason, s.conditionSeverities.severity(OutcomeThrottled), "%s deletion throttled by Azure, will be retried. err: %s", service, err.Error())
+	case azure.IsConcurrencyLimitedError(err):
+		conditions.MarkFalse(s.AzureCluster, condition, infrav1.ConcurrencyLimitedReason, s.conditionSeverities.severity(OutcomeThrottled), "%s deletion waiting for other Azure requests to complete, will be retried. err: %s", service, err.Error())
 	default:
-		conditions.MarkFalse(s.AzureCluster, condition, infrav1.DeletionFailedReason, clusterv1.ConditionSeverityError, "%s failed to delete. err: %s", service, err.Error())
+		conditions.MarkFalse(s.AzureCluster, condition, infrav1.DeletionFailedReason, s.conditionSeverities.severity(OutcomeFailed), "%s failed to delete. err: %s", service, err.Error())
 	}
 }
 
 // UpdatePutStatus updates a condition on the AzureCluster status after a PUT operation.
 func (s *ClusterScope) UpdatePutStatus(condition clusterv1.ConditionType, service string, err error) {
+	// Resources that are still being provisioned get the reason matching their provisioning state, while failed
+	// ones are reported as such below.
+	provisioningErr, isProvisioning := azure.AsProvisioningStateError(err)
+	isProvisioning = isProvisioning && provisioningErr.InProgress()
 	switch {
 	case err == nil:
 		conditions.MarkTrue(s.AzureCluster, condition)
 	case errors.Is(err, azure.ErrNotOwned):
 		// do nothing
 	case azure.IsOperationNotDoneError(err):
-		conditions.MarkFalse(s.AzureCluster, condition, infrav1.CreatingReason, clusterv1.ConditionSeverityInfo, "%s creating or updating", service)
+		conditions.MarkFalse(s.AzureCluster, condition, infrav1.CreatingReason, s.conditionSeverities.severity(OutcomeInProgress), "%s creating or updating", service)
+	case isProvisioning:
+		conditions.MarkFalse(s.AzureCluster, condition, provisioningStateReason(provisioningErr.State), s.conditionSeverities.severity(OutcomeInProgress), "%s %s", service, provisioningErr.Error())
+	case azure.IsThrottled(err):
+		conditions.MarkFalse(s.AzureCluster, condition, infrav1.AzureThrottledReason, s.conditionSeverities.severity(OutcomeThrottled), "%s creation or update throttled by Azure, will be retried. err: %s", service, err.Error())
+	case azure.IsConcurrencyLimitedError(err):
+		conditions.MarkFalse(s.AzureCluster, condition, infrav1.ConcurrencyLimitedReason, s.conditionSeverities.severity(OutcomeThrottled), "%s creation or update waiting for other Azure requests to complete, will be retried. err: %s", service, err.Error())
+	case azure.IsQuotaExceededError(err):
+		conditions.MarkFalse(s.AzureCluster, condition, infrav1.QuotaExceededReason, s.conditionSeverities.severity(OutcomeFailed), "%s creation would exceed the quota of the subscription. err: %s", service, err.Error())
+	case azure.IsResourceMustExistError(err):
+		conditions.MarkFalse(s.AzureCluster, condition, infrav1.ResourceMustExistReason, s.conditionSeverities.severity(OutcomeFailed), "%s is only adopted and must be created beforehand. err: %s", service, err.Error())
+	default:
+		conditions.MarkFalse(s.AzureCluster, condition, infrav1.FailedReason, s.conditionSeverities.severity(OutcomeFailed), "%s failed to create or update. err: %s", service, err.Error())
+	}
+}
+
+// provisioningStateReason returns the condition reason of a resource in an in-progress provisioning state.
+func provisioningStateReason(state infrav1.ProvisioningState) string {
+	switch {
+	case strings.EqualFold(string(state), string(infrav1.Creating)):
+		return infrav1.CreatingReason
+	case strings.EqualFold(string(state), string(infrav1.Deleting)):
+		return infrav1.DeletingReason
 	default:
-		conditions.MarkFalse(s.AzureCluster, condition, infrav1.FailedReason, clusterv1.ConditionSeverityError, "%s failed to create or update. err: %s", service, err.Error())
+		return infrav1.UpdatingReason
 	}
 }
 
@@ -891,9 +1303,11 @@ func (s *ClusterScope) UpdatePatchStatus(condition clusterv1.ConditionType, serv
 	case errors.Is(err, azure.ErrNotOwned):
 		// do nothing
 	case azure.IsOperationNotDoneError(err):
-		conditions.MarkFalse(s.AzureCluster, condition, infrav1.UpdatingReason, clusterv1.ConditionSeverityInfo, "%s updating", service)
+		conditions.MarkFalse(s.AzureCluster, condition, infrav1.UpdatingReason, s.conditionSeverities.severity(OutcomeInProgress), "%s updating", service)
+	case azure.IsThrottled(err):
+		conditions.MarkFalse(s.AzureCluster, condition, infrav1.AzureThrottledReason, s.conditionSeverities.severity(OutcomeThrottled), "%s update throttled by Azure, will be retried. err: %s", service, err.Error())
 	default:
-		conditions.MarkFalse(s.AzureCluster, condition, infrav1.FailedReason, clusterv1.ConditionSeverityError, "%s failed to update. err: %s", service, err.Error())
+		conditions.MarkFalse(s.AzureCluster, condition, infrav1.FailedReason, s.conditionSeverities.severity(OutcomeFailed), "%s failed to update. err: %s", service, err.Error())
 	}
 }
 
@@ -19,13 +19,20 @@ package scope
 import (
 	"context"
 	"testing"
+	"time"
 
+	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-02-01/network"
 	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/to"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/securitygroups"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
@@ -356,3 +363,588 @@ func TestOutboundLBName(t *testing.T) {
 		})
 	}
 }
+
+func TestAppliedParameters(t *testing.T) {
+	g := NewWithT(t)
+	clusterScope := &ClusterScope{AzureCluster: &infrav1.AzureCluster{}}
+
+	parametersHash, resourceHash := clusterScope.AppliedParameters("my-nsg", "securitygroups", "my-rg")
+	g.Expect(parametersHash).To(BeEmpty())
+	g.Expect(resourceHash).To(BeEmpty())
+
+	clusterScope.SetAppliedParameters("my-nsg", "securitygroups", "my-rg", "parameters-hash", "resource-hash")
+	clusterScope.SetAppliedParameters("other-nsg", "securitygroups", "my-rg", "other-parameters-hash", "other-resource-hash")
+	parametersHash, resourceHash = clusterScope.AppliedParameters("my-nsg", "securitygroups", "my-rg")
+	g.Expect(parametersHash).To(Equal("parameters-hash"))
+	g.Expect(resourceHash).To(Equal("resource-hash"))
+
+	// A resource with the same name in another resource group does not share the record.
+	parametersHash, _ = clusterScope.AppliedParameters("my-nsg", "securitygroups", "other-rg")
+	g.Expect(parametersHash).To(BeEmpty())
+	clusterScope.SetAppliedParameters("my-nsg", "securitygroups", "other-rg", "other-rg-parameters-hash", "other-rg-resource-hash")
+	parametersHash, _ = clusterScope.AppliedParameters("my-nsg", "securitygroups", "my-rg")
+	g.Expect(parametersHash).To(Equal("parameters-hash"))
+
+	clusterScope.DeleteAppliedParameters("my-nsg", "securitygroups", "my-rg")
+	parametersHash, _ = clusterScope.AppliedParameters("my-nsg", "securitygroups", "my-rg")
+	g.Expect(parametersHash).To(BeEmpty())
+	parametersHash, _ = clusterScope.AppliedParameters("my-nsg", "securitygroups", "other-rg")
+	g.Expect(parametersHash).To(Equal("other-rg-parameters-hash"))
+	parametersHash, _ = clusterScope.AppliedParameters("other-nsg", "securitygroups", "my-rg")
+	g.Expect(parametersHash).To(Equal("other-parameters-hash"))
+
+	clusterScope.DeleteAppliedParameters("my-nsg", "securitygroups", "other-rg")
+	clusterScope.DeleteAppliedParameters("other-nsg", "securitygroups", "my-rg")
+	g.Expect(clusterScope.AzureCluster.Annotations).NotTo(HaveKey("sigs.k8s.io/cluster-api-provider-azure-applied-parameters"))
+}
+
+func TestIsServicePaused(t *testing.T) {
+	tests := []struct {
+		name        string
+		clusterSpec clusterv1.ClusterSpec
+		annotations map[string]string
+		expected    bool
+	}{
+		{
+			name:     "service is not paused",
+			expected: false,
+		},
+		{
+			name:        "service is listed in the paused services annotation",
+			annotations: map[string]string{"sigs.k8s.io/cluster-api-provider-azure-paused-services": "routetables, SecurityGroups"},
+			expected:    true,
+		},
+		{
+			name:        "other services are paused",
+			annotations: map[string]string{"sigs.k8s.io/cluster-api-provider-azure-paused-services": "routetables"},
+			expected:    false,
+		},
+		{
+			name:        "azure cluster is paused",
+			annotations: map[string]string{clusterv1.PausedAnnotation: ""},
+			expected:    true,
+		},
+		{
+			name:        "cluster is paused",
+			clusterSpec: clusterv1.ClusterSpec{Paused: true},
+			expected:    true,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			clusterScope := &ClusterScope{
+				Cluster:      &clusterv1.Cluster{Spec: tc.clusterSpec},
+				AzureCluster: &infrav1.AzureCluster{ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations}},
+			}
+			g.Expect(clusterScope.IsServicePaused("securitygroups")).To(Equal(tc.expected))
+		})
+	}
+}
+
+func TestSetSecurityGroupsProgress(t *testing.T) {
+	g := NewWithT(t)
+	clusterScope := &ClusterScope{AzureCluster: &infrav1.AzureCluster{}}
+	progress := infrav1.SecurityGroupsProgress{Total: 5, Ready: 3, InProgress: 2}
+
+	conditions.MarkTrue(clusterScope.AzureCluster, infrav1.SecurityGroupsReadyCondition)
+	clusterScope.SetSecurityGroupsProgress(progress)
+	g.Expect(clusterScope.AzureCluster.Status.SecurityGroupsProgress).To(Equal(&progress))
+	g.Expect(conditions.GetMessage(clusterScope.AzureCluster, infrav1.SecurityGroupsReadyCondition)).To(BeEmpty())
+
+	conditions.MarkFalse(clusterScope.AzureCluster, infrav1.SecurityGroupsReadyCondition, infrav1.CreatingReason, clusterv1.ConditionSeverityInfo, "operation in progress")
+	clusterScope.SetSecurityGroupsProgress(progress)
+	g.Expect(conditions.GetMessage(clusterScope.AzureCluster, infrav1.SecurityGroupsReadyCondition)).To(Equal("operation in progress (3/5 security groups ready, 2 in progress, 0 failed)"))
+
+	// The condition did not transition on the next reconcile, so only its summary is replaced.
+	progress = infrav1.SecurityGroupsProgress{Total: 5, Ready: 4, InProgress: 1}
+	clusterScope.SetSecurityGroupsProgress(progress)
+	g.Expect(conditions.GetMessage(clusterScope.AzureCluster, infrav1.SecurityGroupsReadyCondition)).To(Equal("operation in progress (4/5 security groups ready, 1 in progress, 0 failed)"))
+}
+
+func TestSetSecurityGroupStatusLastAppliedTime(t *testing.T) {
+	g := NewWithT(t)
+	applied := metav1.NewTime(metav1.Now().Add(-time.Hour))
+	clusterScope := &ClusterScope{AzureCluster: &infrav1.AzureCluster{
+		Status: infrav1.AzureClusterStatus{
+			SecurityGroups: []infrav1.SecurityGroupStatus{{
+				Name: "nsg-1",
+				Rules: []infrav1.SecurityRuleStatus{
+					{Name: "allow_ssh", ETag: "etag-1", LastAppliedTime: &applied},
+					{Name: "allow_https", ETag: "etag-1", LastAppliedTime: &applied},
+				},
+			}},
+		},
+	}}
+
+	// allow_ssh is unchanged, allow_https was updated and allow_vpn was created by the last PUT.
+	clusterScope.SetSecurityGroupStatus(infrav1.SecurityGroupStatus{
+		Name: "NSG-1",
+		Rules: []infrav1.SecurityRuleStatus{
+			{Name: "allow_ssh", ETag: "etag-1"},
+			{Name: "allow_https", ETag: "etag-2"},
+			{Name: "allow_vpn", ETag: "etag-2"},
+		},
+	})
+	g.Expect(clusterScope.AzureCluster.Status.SecurityGroups).To(HaveLen(1))
+	rules := clusterScope.AzureCluster.Status.SecurityGroups[0].Rules
+	g.Expect(rules[0].LastAppliedTime).To(Equal(&applied))
+	g.Expect(rules[1].LastAppliedTime).NotTo(BeNil())
+	g.Expect(rules[1].LastAppliedTime.After(applied.Time)).To(BeTrue())
+	g.Expect(rules[2].LastAppliedTime).To(Equal(rules[1].LastAppliedTime))
+
+	// The rules of a security group reconciled for the first time were all applied now.
+	clusterScope.SetSecurityGroupStatus(infrav1.SecurityGroupStatus{Name: "nsg-2", Rules: []infrav1.SecurityRuleStatus{{Name: "allow_ssh", ETag: "etag-1"}}})
+	g.Expect(clusterScope.AzureCluster.Status.SecurityGroups).To(HaveLen(2))
+	g.Expect(clusterScope.AzureCluster.Status.SecurityGroups[1].Rules[0].LastAppliedTime).NotTo(BeNil())
+}
+
+func TestSecurityGroupPendingChanges(t *testing.T) {
+	g := NewWithT(t)
+	clusterScope := &ClusterScope{AzureCluster: &infrav1.AzureCluster{}}
+
+	clusterScope.SetSecurityGroupPendingChanges(infrav1.SecurityGroupPendingChanges{Name: "nsg-1", AddedRules: []string{"allow_ssh"}})
+	clusterScope.SetSecurityGroupPendingChanges(infrav1.SecurityGroupPendingChanges{Name: "nsg-2", RemovedRules: []string{"allow_vpn"}})
+	clusterScope.SetSecurityGroupPendingChanges(infrav1.SecurityGroupPendingChanges{Name: "NSG-1", ModifiedRules: []string{"allow_ssh"}})
+	g.Expect(clusterScope.AzureCluster.Status.PendingChanges).To(Equal([]infrav1.SecurityGroupPendingChanges{
+		{Name: "NSG-1", ModifiedRules: []string{"allow_ssh"}},
+		{Name: "nsg-2", RemovedRules: []string{"allow_vpn"}},
+	}))
+
+	clusterScope.DeleteSecurityGroupPendingChanges("nsg-1")
+	g.Expect(clusterScope.AzureCluster.Status.PendingChanges).To(Equal([]infrav1.SecurityGroupPendingChanges{{Name: "nsg-2", RemovedRules: []string{"allow_vpn"}}}))
+	clusterScope.DeleteSecurityGroupPendingChanges("nsg-2")
+	g.Expect(clusterScope.AzureCluster.Status.PendingChanges).To(BeNil())
+}
+
+func TestSecurityGroupsDryRun(t *testing.T) {
+	g := NewWithT(t)
+	clusterScope := &ClusterScope{AzureCluster: &infrav1.AzureCluster{}}
+	g.Expect(clusterScope.SecurityGroupsDryRun()).To(BeFalse())
+
+	clusterScope.AzureCluster.Annotations = map[string]string{azure.SecurityGroupsDryRunAnnotation: "true"}
+	g.Expect(clusterScope.SecurityGroupsDryRun()).To(BeTrue())
+
+	// Security groups are deleted with the cluster even if the annotation is set.
+	now := metav1.Now()
+	clusterScope.AzureCluster.DeletionTimestamp = &now
+	g.Expect(clusterScope.SecurityGroupsDryRun()).To(BeFalse())
+}
+
+func TestSecurityGroupsQuotaCheck(t *testing.T) {
+	g := NewWithT(t)
+	clusterScope := &ClusterScope{AzureCluster: &infrav1.AzureCluster{}}
+	g.Expect(clusterScope.SecurityGroupsQuotaCheck()).To(BeFalse())
+
+	clusterScope.AzureCluster.Annotations = map[string]string{azure.SecurityGroupsQuotaCheckAnnotation: " True "}
+	g.Expect(clusterScope.SecurityGroupsQuotaCheck()).To(BeTrue())
+
+	clusterScope.AzureCluster.Annotations = map[string]string{azure.SecurityGroupsQuotaCheckAnnotation: "false"}
+	g.Expect(clusterScope.SecurityGroupsQuotaCheck()).To(BeFalse())
+}
+
+func TestNSGSpecsAdditiveSafeMode(t *testing.T) {
+	g := NewWithT(t)
+	clusterScope := &ClusterScope{
+		Cluster: &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "my-cluster"}},
+		AzureCluster: &infrav1.AzureCluster{
+			Spec: infrav1.AzureClusterSpec{
+				ResourceGroup: "my-rg",
+				NetworkSpec: infrav1.NetworkSpec{
+					Subnets: infrav1.Subnets{
+						{Name: "node-subnet", SecurityGroup: infrav1.SecurityGroup{Name: "node-nsg"}},
+					},
+				},
+			},
+		},
+	}
+	// The security group has a managed rule that was removed from the spec.
+	existing := network.SecurityGroup{
+		SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
+			SecurityRules: &[]network.SecurityRule{{
+				Name: to.StringPtr("allow_vpn"),
+				SecurityRulePropertiesFormat: &network.SecurityRulePropertiesFormat{
+					Description: to.StringPtr("allow the VPN (managed by capz)"),
+					Direction:   network.SecurityRuleDirectionInbound,
+					Priority:    to.Int32Ptr(2300),
+				},
+			}},
+		},
+	}
+	ruleChanges := func() securitygroups.RuleChanges {
+		return clusterScope.NSGSpecs()[0].(*securitygroups.NSGSpec).RuleChanges(existing)
+	}
+
+	// Removed rules are left in place by default.
+	changes := ruleChanges()
+	g.Expect(changes.Deletions).To(BeEmpty())
+	g.Expect(changes.HeldDeletions).To(BeEmpty())
+
+	clusterScope.AzureCluster.Annotations = map[string]string{azure.PruneSecurityRulesAnnotation: "true"}
+	g.Expect(ruleChanges().Deletions).To(Equal([]string{"allow_vpn"}))
+
+	// In additive safe mode, the deletion is held until it is confirmed.
+	clusterScope.AzureCluster.Annotations[azure.SecurityRulesAdditiveSafeModeAnnotation] = "true"
+	changes = ruleChanges()
+	g.Expect(changes.Deletions).To(BeEmpty())
+	g.Expect(changes.HeldDeletions).To(Equal([]string{"allow_vpn"}))
+
+	clusterScope.AzureCluster.Annotations[azure.ConfirmedRuleDeletionsAnnotation] = "allow_vpn"
+	changes = ruleChanges()
+	g.Expect(changes.Deletions).To(Equal([]string{"allow_vpn"}))
+	g.Expect(changes.HeldDeletions).To(BeEmpty())
+}
+
+func TestNSGSpecsDefaultDenyOutbound(t *testing.T) {
+	g := NewWithT(t)
+	clusterScope := &ClusterScope{
+		Cluster: &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "my-cluster"}},
+		AzureCluster: &infrav1.AzureCluster{
+			Spec: infrav1.AzureClusterSpec{
+				ResourceGroup: "my-rg",
+				NetworkSpec: infrav1.NetworkSpec{
+					Subnets: infrav1.Subnets{
+						{Name: "control-plane-subnet", SecurityGroup: infrav1.SecurityGroup{Name: "control-plane-nsg"}},
+						{Name: "node-subnet", SecurityGroup: infrav1.SecurityGroup{Name: "node-nsg", SecurityGroupClass: infrav1.SecurityGroupClass{DefaultDenyOutbound: true}}},
+					},
+				},
+			},
+		},
+	}
+	defaultDenyOutbound := func() []bool {
+		var enabled []bool
+		for _, spec := range clusterScope.NSGSpecs() {
+			enabled = append(enabled, spec.(*securitygroups.NSGSpec).DefaultDenyOutbound)
+		}
+		return enabled
+	}
+
+	// Outbound traffic is only denied by default for the security groups enabling it.
+	g.Expect(defaultDenyOutbound()).To(Equal([]bool{false, true}))
+
+	// The annotation denies outbound traffic by default for all the security groups of the cluster.
+	clusterScope.AzureCluster.Annotations = map[string]string{azure.DefaultDenyOutboundAnnotation: "true"}
+	g.Expect(defaultDenyOutbound()).To(Equal([]bool{true, true}))
+}
+
+func TestNSGSpecsFlowLog(t *testing.T) {
+	g := NewWithT(t)
+	clusterScope := &ClusterScope{
+		Cluster: &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "my-cluster"}},
+		AzureCluster: &infrav1.AzureCluster{
+			Spec: infrav1.AzureClusterSpec{
+				ResourceGroup: "my-rg",
+				NetworkSpec: infrav1.NetworkSpec{
+					Subnets: infrav1.Subnets{
+						{Name: "control-plane-subnet", SecurityGroup: infrav1.SecurityGroup{Name: "control-plane-nsg"}},
+						{Name: "node-subnet", SecurityGroup: infrav1.SecurityGroup{Name: "node-nsg"}},
+					},
+				},
+			},
+		},
+	}
+
+	// Neither flow logs nor diagnostic settings are enabled by default, so there is nothing to delete with the cluster.
+	g.Expect(clusterScope.SecurityGroupFlowLogsEnabled()).To(BeFalse())
+	g.Expect(clusterScope.SecurityGroupDiagnosticSettingsEnabled()).To(BeFalse())
+	for _, spec := range clusterScope.NSGSpecs() {
+		g.Expect(spec.(*securitygroups.NSGSpec).FlowLog).To(BeNil())
+	}
+
+	clusterScope.AzureCluster.Spec.NetworkSpec.Subnets[1].SecurityGroup.FlowLog = &infrav1.SecurityGroupFlowLog{
+		StorageAccountID: "/subscriptions/123/resourceGroups/logs-rg/providers/Microsoft.Storage/storageAccounts/flowlogs",
+		RetentionDays:    30,
+	}
+	g.Expect(clusterScope.SecurityGroupFlowLogsEnabled()).To(BeTrue())
+	specs := clusterScope.NSGSpecs()
+	g.Expect(specs[0].(*securitygroups.NSGSpec).FlowLog).To(BeNil())
+	g.Expect(specs[1].(*securitygroups.NSGSpec).FlowLog).To(Equal(&securitygroups.FlowLogSpec{
+		StorageAccountID: "/subscriptions/123/resourceGroups/logs-rg/providers/Microsoft.Storage/storageAccounts/flowlogs",
+		RetentionDays:    30,
+	}))
+
+	clusterScope.AzureCluster.Annotations = map[string]string{azure.SecurityGroupDiagnosticsWorkspaceAnnotation: "my-workspace-id"}
+	g.Expect(clusterScope.SecurityGroupDiagnosticSettingsEnabled()).To(BeTrue())
+}
+
+func TestSecurityRuleSets(t *testing.T) {
+	g := NewWithT(t)
+	baseline := infrav1.SecurityRuleSet{Name: "baseline", SecurityRules: infrav1.SecurityRules{{Name: "allow_ssh", Priority: 2200}}}
+	monitoring := infrav1.SecurityRuleSet{Name: "monitoring", SecurityRules: infrav1.SecurityRules{{Name: "allow_metrics", Priority: 2300}}}
+	clusterScope := &ClusterScope{AzureCluster: &infrav1.AzureCluster{
+		Spec: infrav1.AzureClusterSpec{
+			NetworkSpec: infrav1.NetworkSpec{
+				NetworkClassSpec: infrav1.NetworkClassSpec{SecurityRuleSets: []infrav1.SecurityRuleSet{baseline, monitoring}},
+			},
+		},
+	}}
+
+	g.Expect(clusterScope.securityRuleSets(nil)).To(BeNil())
+	g.Expect(clusterScope.securityRuleSets([]string{"monitoring", "baseline"})).To(Equal([]infrav1.SecurityRuleSet{monitoring, baseline}))
+	g.Expect(clusterScope.securityRuleSets([]string{"unknown", "baseline"})).To(Equal([]infrav1.SecurityRuleSet{baseline}))
+}
+
+func TestNSGSpecsLocation(t *testing.T) {
+	g := NewWithT(t)
+	clusterScope := &ClusterScope{
+		Cluster: &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "my-cluster"}},
+		AzureCluster: &infrav1.AzureCluster{
+			Spec: infrav1.AzureClusterSpec{
+				ResourceGroup: "my-rg",
+				AzureClusterClassSpec: infrav1.AzureClusterClassSpec{
+					Location: "eastus",
+				},
+				NetworkSpec: infrav1.NetworkSpec{
+					Subnets: infrav1.Subnets{
+						{Name: "control-plane-subnet", SecurityGroup: infrav1.SecurityGroup{Name: "control-plane-nsg"}},
+						{Name: "node-subnet", SecurityGroup: infrav1.SecurityGroup{Name: "node-nsg", SecurityGroupClass: infrav1.SecurityGroupClass{Location: "westus2"}}},
+					},
+				},
+			},
+		},
+	}
+
+	specs := clusterScope.NSGSpecs()
+	g.Expect(specs).To(HaveLen(2))
+	for i, expected := range []string{"eastus", "westus2"} {
+		parameters, err := specs[i].Parameters(nil)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(specs[i].(*securitygroups.NSGSpec).Location).To(Equal(expected))
+		g.Expect(parameters.(network.SecurityGroup).Location).To(Equal(to.StringPtr(expected)))
+	}
+}
+
+func TestNSGSpecsResourceGroup(t *testing.T) {
+	g := NewWithT(t)
+	clusterScope := &ClusterScope{
+		Cluster: &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "my-cluster"}},
+		AzureCluster: &infrav1.AzureCluster{
+			Spec: infrav1.AzureClusterSpec{
+				ResourceGroup: "my-rg",
+				NetworkSpec: infrav1.NetworkSpec{
+					Vnet: infrav1.VnetSpec{Name: "my-vnet", ResourceGroup: "my-rg"},
+					Subnets: infrav1.Subnets{
+						{Name: "control-plane-subnet", SecurityGroup: infrav1.SecurityGroup{Name: "control-plane-nsg"}},
+						{Name: "node-subnet", SecurityGroup: infrav1.SecurityGroup{Name: "node-nsg", SecurityGroupClass: infrav1.SecurityGroupClass{ResourceGroup: "network-rg"}}},
+					},
+				},
+			},
+		},
+	}
+
+	nsgSpecs := clusterScope.NSGSpecs()
+	subnetSpecs := clusterScope.SubnetSpecs()
+	g.Expect(nsgSpecs).To(HaveLen(2))
+	g.Expect(subnetSpecs).To(HaveLen(2))
+	for i, expected := range []string{"my-rg", "network-rg"} {
+		g.Expect(nsgSpecs[i].ResourceGroupName()).To(Equal(expected))
+		parameters, err := subnetSpecs[i].Parameters(nil)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(parameters.(network.Subnet).NetworkSecurityGroup.ID).To(Equal(to.StringPtr(azure.SecurityGroupID(clusterScope.SubscriptionID(), expected, nsgSpecs[i].ResourceName()))))
+	}
+	g.Expect(clusterScope.HasSecurityGroupsOutsideResourceGroup()).To(BeTrue())
+
+	clusterScope.AzureCluster.Spec.NetworkSpec.Subnets[1].SecurityGroup.ResourceGroup = "MY-RG"
+	g.Expect(clusterScope.HasSecurityGroupsOutsideResourceGroup()).To(BeFalse())
+}
+
+func TestNSGSpecsReconciled(t *testing.T) {
+	testcases := []struct {
+		name     string
+		status   infrav1.AzureClusterStatus
+		expected []bool
+	}{
+		{
+			name:     "security groups of a new cluster are not reconciled",
+			expected: []bool{false, false},
+		},
+		{
+			name: "security groups of a cluster reconciled before the upgrade are reconciled",
+			status: infrav1.AzureClusterStatus{
+				Conditions: clusterv1.Conditions{{Type: infrav1.SecurityGroupsReadyCondition, Status: corev1.ConditionTrue}},
+			},
+			expected: []bool{true, true},
+		},
+		{
+			name: "security groups summarized in the status are reconciled",
+			status: infrav1.AzureClusterStatus{
+				Conditions:             clusterv1.Conditions{{Type: infrav1.SecurityGroupsReadyCondition, Status: corev1.ConditionTrue}},
+				SecurityGroups:         []infrav1.SecurityGroupStatus{{Name: "CONTROL-PLANE-NSG"}},
+				SecurityGroupsProgress: &infrav1.SecurityGroupsProgress{Total: 2, Ready: 1, InProgress: 1},
+			},
+			expected: []bool{true, false},
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			clusterScope := &ClusterScope{
+				Cluster: &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "my-cluster"}},
+				AzureCluster: &infrav1.AzureCluster{
+					Spec: infrav1.AzureClusterSpec{
+						ResourceGroup: "my-rg",
+						NetworkSpec: infrav1.NetworkSpec{
+							Subnets: infrav1.Subnets{
+								{Name: "control-plane-subnet", SecurityGroup: infrav1.SecurityGroup{Name: "control-plane-nsg"}},
+								{Name: "node-subnet", SecurityGroup: infrav1.SecurityGroup{Name: "node-nsg"}},
+							},
+						},
+					},
+					Status: tc.status,
+				},
+			}
+
+			specs := clusterScope.NSGSpecs()
+			g.Expect(specs).To(HaveLen(len(tc.expected)))
+			for i, expected := range tc.expected {
+				g.Expect(specs[i].(*securitygroups.NSGSpec).Reconciled).To(Equal(expected))
+			}
+		})
+	}
+}
+
+func TestNSGSpecsFirewallPolicy(t *testing.T) {
+	g := NewWithT(t)
+	policyID := "/subscriptions/123/resourceGroups/hub-rg/providers/Microsoft.Network/firewallPolicies/hub-policy"
+	clusterScope := &ClusterScope{
+		Cluster: &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "my-cluster"}},
+		AzureCluster: &infrav1.AzureCluster{
+			Spec: infrav1.AzureClusterSpec{
+				ResourceGroup: "my-rg",
+				NetworkSpec: infrav1.NetworkSpec{
+					Subnets: infrav1.Subnets{
+						{Name: "node-subnet", SecurityGroup: infrav1.SecurityGroup{Name: "node-nsg"}},
+					},
+				},
+			},
+		},
+	}
+
+	specs := clusterScope.NSGSpecs()
+	g.Expect(specs[0].(*securitygroups.NSGSpec).FirewallPolicyID).To(BeEmpty())
+
+	clusterScope.AzureCluster.Annotations = map[string]string{azure.FirewallPolicyAnnotation: " " + policyID + " "}
+	specs = clusterScope.NSGSpecs()
+	g.Expect(specs[0].(*securitygroups.NSGSpec).FirewallPolicyID).To(Equal(policyID))
+}
+
+func TestSecurityRuleSources(t *testing.T) {
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "admin-allowlist"},
+		Data:       map[string]string{"cidrs": "10.0.0.0/16"},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "vpn-allowlist"},
+		Data:       map[string][]byte{"cidrs": []byte("192.168.0.0/24")},
+	}
+	otherNamespaceSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "firewall", Name: "firewall-allowlist"},
+		Data:       map[string][]byte{"cidrs": []byte("172.16.0.0/12")},
+	}
+	tests := []struct {
+		name          string
+		ref           infrav1.SecurityRuleSourcesReference
+		expected      string
+		expectedError string
+	}{
+		{
+			name:     "ConfigMap in the namespace of the AzureCluster",
+			ref:      infrav1.SecurityRuleSourcesReference{Kind: infrav1.SecurityRuleSourcesFromConfigMap, Name: "admin-allowlist", Key: "cidrs"},
+			expected: "10.0.0.0/16",
+		},
+		{
+			name:     "Secret in the namespace of the AzureCluster",
+			ref:      infrav1.SecurityRuleSourcesReference{Kind: infrav1.SecurityRuleSourcesFromSecret, Name: "vpn-allowlist", Key: "cidrs"},
+			expected: "192.168.0.0/24",
+		},
+		{
+			name:          "Secret in another namespace is not read",
+			ref:           infrav1.SecurityRuleSourcesReference{Kind: infrav1.SecurityRuleSourcesFromSecret, Name: "firewall-allowlist", Key: "cidrs"},
+			expectedError: "failed to get Secret default/firewall-allowlist",
+		},
+		{
+			name:          "missing object",
+			ref:           infrav1.SecurityRuleSourcesReference{Kind: infrav1.SecurityRuleSourcesFromSecret, Name: "admin-allowlist", Key: "cidrs"},
+			expectedError: "failed to get Secret default/admin-allowlist",
+		},
+		{
+			name:          "missing key",
+			ref:           infrav1.SecurityRuleSourcesReference{Kind: infrav1.SecurityRuleSourcesFromConfigMap, Name: "admin-allowlist", Key: "ips"},
+			expectedError: "key ips not found in ConfigMap default/admin-allowlist",
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			clusterScope := &ClusterScope{
+				Client:       fake.NewClientBuilder().WithObjects(configMap, secret, otherNamespaceSecret).Build(),
+				AzureCluster: &infrav1.AzureCluster{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "my-cluster"}},
+			}
+			data, err := clusterScope.SecurityRuleSources(context.TODO(), tc.ref)
+			if tc.expectedError != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tc.expectedError)))
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(data).To(Equal(tc.expected))
+		})
+	}
+}
+
+func TestResetOperations(t *testing.T) {
+	futures := infrav1.Futures{
+		{Type: infrav1.PutFuture, ServiceName: "securitygroups", Name: "nsg-1", ResourceGroup: "test-group", Data: "data"},
+		{Type: infrav1.PutFuture, ServiceName: "securitygroups", Name: "nsg-2", ResourceGroup: "test-group", Data: "data"},
+		{Type: infrav1.DeleteFuture, ServiceName: "routetables", Name: "rt-1", ResourceGroup: "test-group", Data: "data"},
+	}
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		expected    []string
+	}{
+		{
+			name:     "no reset operation annotation",
+			expected: []string{"nsg-1", "nsg-2", "rt-1"},
+		},
+		{
+			name:        "operations of a service are reset",
+			annotations: map[string]string{"sigs.k8s.io/cluster-api-provider-azure-reset-operation": "SecurityGroups"},
+			expected:    []string{"rt-1"},
+		},
+		{
+			name:        "operation of a resource is reset",
+			annotations: map[string]string{"sigs.k8s.io/cluster-api-provider-azure-reset-operation": "securitygroups/nsg-2, routetables/rt-1"},
+			expected:    []string{"nsg-1"},
+		},
+		{
+			name:        "nothing is stored for the service",
+			annotations: map[string]string{"sigs.k8s.io/cluster-api-provider-azure-reset-operation": "publicips,securitygroups/nsg-3"},
+			expected:    []string{"nsg-1", "nsg-2", "rt-1"},
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			clusterScope := &ClusterScope{
+				AzureCluster: &infrav1.AzureCluster{
+					ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations},
+					Status:     infrav1.AzureClusterStatus{LongRunningOperationStates: append(infrav1.Futures{}, futures...)},
+				},
+			}
+			clusterScope.ResetOperations(context.TODO())
+
+			var names []string
+			for _, future := range clusterScope.GetLongRunningOperationStates() {
+				names = append(names, future.Name)
+			}
+			g.Expect(names).To(Equal(tc.expected))
+			g.Expect(clusterScope.AzureCluster.Annotations).NotTo(HaveKey("sigs.k8s.io/cluster-api-provider-azure-reset-operation"))
+		})
+	}
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scope
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// ReconcileOutcome is the outcome of a service reconcile that is reported through a condition.
+type ReconcileOutcome string
+
+const (
+	// OutcomeInProgress means the operation on the resource is still in progress.
+	OutcomeInProgress ReconcileOutcome = "InProgress"
+	// OutcomeFailed means the operation on the resource failed.
+	OutcomeFailed ReconcileOutcome = "Failed"
+	// OutcomeDeleted means the resource was successfully deleted.
+	OutcomeDeleted ReconcileOutcome = "Deleted"
+	// OutcomeThrottled means the operation on the resource was throttled by Azure and will be retried.
+	OutcomeThrottled ReconcileOutcome = "Throttled"
+)
+
+// defaultConditionSeverities are the severities used for outcomes that are not configured.
+var defaultConditionSeverities = ConditionSeverities{
+	OutcomeInProgress: clusterv1.ConditionSeverityInfo,
+	OutcomeFailed:     clusterv1.ConditionSeverityError,
+	OutcomeDeleted:    clusterv1.ConditionSeverityInfo,
+	OutcomeThrottled:  clusterv1.ConditionSeverityWarning,
+}
+
+// ConditionSeverities maps reconcile outcomes to the severity of the condition reporting them.
+// Outcomes that are missing from the map are reported with their default severity.
+type ConditionSeverities map[ReconcileOutcome]clusterv1.ConditionSeverity
+
+// ParseConditionSeverities parses the severities of the outcomes given as a map of outcome names to severity names, e.g.
+// from the condition-severities flag of the manager. Outcome and severity names are case insensitive.
+func ParseConditionSeverities(severities map[string]string) (ConditionSeverities, error) {
+	if len(severities) == 0 {
+		return nil, nil
+	}
+	parsed := make(ConditionSeverities, len(severities))
+	for name, severityName := range severities {
+		outcome, ok := parseReconcileOutcome(name)
+		if !ok {
+			return nil, errors.Errorf("unknown reconcile outcome %q, expected one of %s, %s, %s or %s", name, OutcomeInProgress, OutcomeFailed, OutcomeDeleted, OutcomeThrottled)
+		}
+		severity, ok := parseConditionSeverity(severityName)
+		if !ok {
+			return nil, errors.Errorf("unknown severity %q for reconcile outcome %s, expected one of %s, %s or %s", severityName, outcome, clusterv1.ConditionSeverityInfo, clusterv1.ConditionSeverityWarning, clusterv1.ConditionSeverityError)
+		}
+		parsed[outcome] = severity
+	}
+	return parsed, nil
+}
+
+// parseReconcileOutcome returns the reconcile outcome with the given name, ignoring case.
+func parseReconcileOutcome(name string) (ReconcileOutcome, bool) {
+	for outcome := range defaultConditionSeverities {
+		if strings.EqualFold(strings.TrimSpace(name), string(outcome)) {
+			return outcome, true
+		}
+	}
+	return "", false
+}
+
+// parseConditionSeverity returns the condition severity with the given name, ignoring case.
+func parseConditionSeverity(name string) (clusterv1.ConditionSeverity, bool) {
+	for _, severity := range []clusterv1.ConditionSeverity{clusterv1.ConditionSeverityInfo, clusterv1.ConditionSeverityWarning, clusterv1.ConditionSeverityError} {
+		if strings.EqualFold(strings.TrimSpace(name), string(severity)) {
+			return severity, true
+		}
+	}
+	return "", false
+}
+
+// severity returns the configured severity for the outcome, falling back to the default.
+func (cs ConditionSeverities) severity(outcome ReconcileOutcome) clusterv1.ConditionSeverity {
+	if severity, ok := cs[outcome]; ok {
+		return severity
+	}
+	return defaultConditionSeverities[outcome]
+}
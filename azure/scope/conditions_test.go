@@ -0,0 +1,255 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scope
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Azure/go-autorest/autorest"
+	azureautorest "github.com/Azure/go-autorest/autorest/azure"
+	. "github.com/onsi/gomega"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+)
+
+func TestClusterScopeConditionSeverities(t *testing.T) {
+	notDoneErr := azure.NewOperationNotDoneError(&infrav1.Future{})
+	failedErr := errors.New("failed")
+	inUseErr := autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: http.StatusBadRequest}, "")
+	inUseErr.Original = &azureautorest.ServiceError{Code: "InUseNetworkSecurityGroupCannotBeDeleted"}
+	updatingErr := azure.WithTransientError(azure.ProvisioningStateError{ResourceName: "test-nsg", State: infrav1.Updating}, 0)
+	provisioningFailedErr := azure.ProvisioningStateError{ResourceName: "test-nsg", State: infrav1.Failed}
+	throttledErr := autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: http.StatusTooManyRequests}, "")
+	quotaErr := azure.QuotaExceededError{Requirement: azure.QuotaRequirement{Location: "eastus", Name: "NetworkSecurityGroups", Amount: 1}, Current: 5000, Limit: 5000}
+
+	tests := []struct {
+		name             string
+		severities       ConditionSeverities
+		update           func(s *ClusterScope)
+		expectedReason   string
+		expectedSeverity clusterv1.ConditionSeverity
+	}{
+		{
+			name: "put in progress uses default severity",
+			update: func(s *ClusterScope) {
+				s.UpdatePutStatus(infrav1.SecurityGroupsReadyCondition, "securitygroups", notDoneErr)
+			},
+			expectedReason:   infrav1.CreatingReason,
+			expectedSeverity: clusterv1.ConditionSeverityInfo,
+		},
+		{
+			name: "put failure uses default severity",
+			update: func(s *ClusterScope) {
+				s.UpdatePutStatus(infrav1.SecurityGroupsReadyCondition, "securitygroups", failedErr)
+			},
+			expectedReason:   infrav1.FailedReason,
+			expectedSeverity: clusterv1.ConditionSeverityError,
+		},
+		{
+			name: "put of a resource still updating uses updating reason",
+			update: func(s *ClusterScope) {
+				s.UpdatePutStatus(infrav1.SecurityGroupsReadyCondition, "securitygroups", updatingErr)
+			},
+			expectedReason:   infrav1.UpdatingReason,
+			expectedSeverity: clusterv1.ConditionSeverityInfo,
+		},
+		{
+			name: "put of a resource that failed to provision uses failed reason",
+			update: func(s *ClusterScope) {
+				s.UpdatePutStatus(infrav1.SecurityGroupsReadyCondition, "securitygroups", provisioningFailedErr)
+			},
+			expectedReason:   infrav1.FailedReason,
+			expectedSeverity: clusterv1.ConditionSeverityError,
+		},
+		{
+			name: "throttled put uses throttled reason",
+			update: func(s *ClusterScope) {
+				s.UpdatePutStatus(infrav1.SecurityGroupsReadyCondition, "securitygroups", azure.WithTransientError(throttledErr, 0))
+			},
+			expectedReason:   infrav1.AzureThrottledReason,
+			expectedSeverity: clusterv1.ConditionSeverityWarning,
+		},
+		{
+			name: "put exceeding a quota uses quota exceeded reason",
+			update: func(s *ClusterScope) {
+				s.UpdatePutStatus(infrav1.SecurityGroupsReadyCondition, "securitygroups", azure.WithTerminalError(fmt.Errorf("cannot create resource: %w", quotaErr)))
+			},
+			expectedReason:   infrav1.QuotaExceededReason,
+			expectedSeverity: clusterv1.ConditionSeverityError,
+		},
+		{
+			name: "put of a missing adopt-only resource uses resource must exist reason",
+			update: func(s *ClusterScope) {
+				s.UpdatePutStatus(infrav1.SecurityGroupsReadyCondition, "securitygroups", azure.WithTerminalError(azure.ResourceMustExistError{ResourceGroup: "test-group", ResourceName: "test-nsg", ServiceName: "securitygroups"}))
+			},
+			expectedReason:   infrav1.ResourceMustExistReason,
+			expectedSeverity: clusterv1.ConditionSeverityError,
+		},
+		{
+			name: "put waiting for other Azure requests uses concurrency limited reason",
+			update: func(s *ClusterScope) {
+				s.UpdatePutStatus(infrav1.SecurityGroupsReadyCondition, "securitygroups", azure.WithTransientError(azure.ConcurrencyLimitedError{Type: infrav1.PutFuture, ResourceGroup: "test-group", ResourceName: "test-nsg", ServiceName: "securitygroups"}, 0))
+			},
+			expectedReason:   infrav1.ConcurrencyLimitedReason,
+			expectedSeverity: clusterv1.ConditionSeverityWarning,
+		},
+		{
+			name: "delete waiting for other Azure requests uses concurrency limited reason",
+			update: func(s *ClusterScope) {
+				s.UpdateDeleteStatus(infrav1.SecurityGroupsReadyCondition, "securitygroups", azure.WithTransientError(azure.ConcurrencyLimitedError{Type: infrav1.DeleteFuture, ResourceGroup: "test-group", ResourceName: "test-nsg", ServiceName: "securitygroups"}, 0))
+			},
+			expectedReason:   infrav1.ConcurrencyLimitedReason,
+			expectedSeverity: clusterv1.ConditionSeverityWarning,
+		},
+		{
+			name:       "throttled delete uses remapped severity",
+			severities: ConditionSeverities{OutcomeThrottled: clusterv1.ConditionSeverityInfo},
+			update: func(s *ClusterScope) {
+				s.UpdateDeleteStatus(infrav1.SecurityGroupsReadyCondition, "securitygroups", throttledErr)
+			},
+			expectedReason:   infrav1.AzureThrottledReason,
+			expectedSeverity: clusterv1.ConditionSeverityInfo,
+		},
+		{
+			name:       "put in progress uses remapped severity",
+			severities: ConditionSeverities{OutcomeInProgress: clusterv1.ConditionSeverityWarning},
+			update: func(s *ClusterScope) {
+				s.UpdatePutStatus(infrav1.SecurityGroupsReadyCondition, "securitygroups", notDoneErr)
+			},
+			expectedReason:   infrav1.CreatingReason,
+			expectedSeverity: clusterv1.ConditionSeverityWarning,
+		},
+		{
+			name:       "patch failure uses remapped severity",
+			severities: ConditionSeverities{OutcomeFailed: clusterv1.ConditionSeverityWarning},
+			update: func(s *ClusterScope) {
+				s.UpdatePatchStatus(infrav1.SecurityGroupsReadyCondition, "securitygroups", failedErr)
+			},
+			expectedReason:   infrav1.FailedReason,
+			expectedSeverity: clusterv1.ConditionSeverityWarning,
+		},
+		{
+			name:       "delete success uses remapped severity, other outcomes keep their default",
+			severities: ConditionSeverities{OutcomeFailed: clusterv1.ConditionSeverityWarning},
+			update: func(s *ClusterScope) {
+				s.UpdateDeleteStatus(infrav1.SecurityGroupsReadyCondition, "securitygroups", nil)
+			},
+			expectedReason:   infrav1.DeletedReason,
+			expectedSeverity: clusterv1.ConditionSeverityInfo,
+		},
+		{
+			name: "delete blocked by resources in use uses failure severity",
+			update: func(s *ClusterScope) {
+				s.UpdateDeleteStatus(infrav1.SecurityGroupsReadyCondition, "securitygroups", inUseErr)
+			},
+			expectedReason:   infrav1.DeletionBlockedReason,
+			expectedSeverity: clusterv1.ConditionSeverityError,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			s := &ClusterScope{
+				AzureCluster:        &infrav1.AzureCluster{},
+				conditionSeverities: tc.severities,
+			}
+			tc.update(s)
+			condition := conditions.Get(s.AzureCluster, infrav1.SecurityGroupsReadyCondition)
+			g.Expect(condition).NotTo(BeNil())
+			g.Expect(condition.Reason).To(Equal(tc.expectedReason))
+			g.Expect(condition.Severity).To(Equal(tc.expectedSeverity))
+		})
+	}
+}
+
+func TestParseConditionSeverities(t *testing.T) {
+	tests := []struct {
+		name          string
+		severities    map[string]string
+		expected      ConditionSeverities
+		expectedError string
+	}{
+		{
+			name:     "no severities",
+			expected: nil,
+		},
+		{
+			name:       "severities are parsed ignoring case",
+			severities: map[string]string{"throttled": "info", "Failed": "Warning", " InProgress ": "Warning"},
+			expected: ConditionSeverities{
+				OutcomeThrottled:  clusterv1.ConditionSeverityInfo,
+				OutcomeFailed:     clusterv1.ConditionSeverityWarning,
+				OutcomeInProgress: clusterv1.ConditionSeverityWarning,
+			},
+		},
+		{
+			name:          "unknown outcome",
+			severities:    map[string]string{"Succeeded": "Info"},
+			expectedError: `unknown reconcile outcome "Succeeded", expected one of InProgress, Failed, Deleted or Throttled`,
+		},
+		{
+			name:          "unknown severity",
+			severities:    map[string]string{"Deleted": "Critical"},
+			expectedError: `unknown severity "Critical" for reconcile outcome Deleted, expected one of Info, Warning or Error`,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			severities, err := ParseConditionSeverities(tc.severities)
+			if tc.expectedError != "" {
+				g.Expect(err).To(MatchError(tc.expectedError))
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(severities).To(Equal(tc.expected))
+		})
+	}
+}
+
+func TestClusterScopeConditionTransitions(t *testing.T) {
+	g := NewWithT(t)
+	s := &ClusterScope{AzureCluster: &infrav1.AzureCluster{}}
+
+	s.UpdatePutStatus(infrav1.SecurityGroupsReadyCondition, "securitygroups", errors.New("failed"))
+	failed := conditions.Get(s.AzureCluster, infrav1.SecurityGroupsReadyCondition).DeepCopy()
+	g.Expect(failed.Reason).To(Equal(infrav1.FailedReason))
+	g.Expect(failed.Message).To(Equal("securitygroups failed to create or update. err: failed"))
+
+	// The same failure does not change the condition, so it is left as is.
+	s.UpdatePutStatus(infrav1.SecurityGroupsReadyCondition, "securitygroups", errors.New("failed"))
+	g.Expect(conditions.Get(s.AzureCluster, infrav1.SecurityGroupsReadyCondition)).To(Equal(failed))
+
+	// Another failure of the same kind updates the message of the condition.
+	s.UpdatePutStatus(infrav1.SecurityGroupsReadyCondition, "securitygroups", errors.New("failed again"))
+	g.Expect(conditions.GetReason(s.AzureCluster, infrav1.SecurityGroupsReadyCondition)).To(Equal(infrav1.FailedReason))
+	g.Expect(conditions.GetMessage(s.AzureCluster, infrav1.SecurityGroupsReadyCondition)).To(Equal("securitygroups failed to create or update. err: failed again"))
+
+	s.UpdatePutStatus(infrav1.SecurityGroupsReadyCondition, "securitygroups", azure.NewOperationNotDoneError(&infrav1.Future{}))
+	g.Expect(conditions.GetReason(s.AzureCluster, infrav1.SecurityGroupsReadyCondition)).To(Equal(infrav1.CreatingReason))
+	g.Expect(conditions.GetMessage(s.AzureCluster, infrav1.SecurityGroupsReadyCondition)).To(Equal("securitygroups creating or updating"))
+
+	s.UpdatePutStatus(infrav1.SecurityGroupsReadyCondition, "securitygroups", nil)
+	g.Expect(conditions.IsTrue(s.AzureCluster, infrav1.SecurityGroupsReadyCondition)).To(BeTrue())
+}
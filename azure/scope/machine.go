@@ -655,11 +655,26 @@ func (m *MachineScope) GetLongRunningOperationState(name, service string) *infra
 	return futures.Get(m.AzureMachine, name, service)
 }
 
+// GetLongRunningOperationStates will get all the futures on the AzureMachine status.
+func (m *MachineScope) GetLongRunningOperationStates() []*infrav1.Future {
+	return futures.List(m.AzureMachine)
+}
+
 // DeleteLongRunningOperationState will delete the future from the AzureMachine status.
 func (m *MachineScope) DeleteLongRunningOperationState(name, service string) {
 	futures.Delete(m.AzureMachine, name, service)
 }
 
+// SetCompletedOperation records a completed long-running operation on the AzureMachine status for a short time.
+func (m *MachineScope) SetCompletedOperation(operation infrav1.CompletedOperation) {
+	futures.SetCompleted(m.AzureMachine, operation, time.Now())
+}
+
+// IsServicePaused returns true if the AzureMachine is paused or lists the service in its paused services annotation.
+func (m *MachineScope) IsServicePaused(service string) bool {
+	return servicePaused(nil, m.AzureMachine, service)
+}
+
 // UpdateDeleteStatus updates a condition on the AzureMachine status after a DELETE operation.
 func (m *MachineScope) UpdateDeleteStatus(condition clusterv1.ConditionType, service string, err error) {
 	switch {
@@ -379,11 +379,21 @@ func (m *MachinePoolScope) GetLongRunningOperationState(name, service string) *i
 	return futures.Get(m.AzureMachinePool, name, service)
 }
 
+// GetLongRunningOperationStates will get all the futures on the AzureMachinePool status.
+func (m *MachinePoolScope) GetLongRunningOperationStates() []*infrav1.Future {
+	return futures.List(m.AzureMachinePool)
+}
+
 // DeleteLongRunningOperationState will delete the future from the AzureMachinePool status.
 func (m *MachinePoolScope) DeleteLongRunningOperationState(name, service string) {
 	futures.Delete(m.AzureMachinePool, name, service)
 }
 
+// IsServicePaused returns true if the AzureMachinePool is paused or lists the service in its paused services annotation.
+func (m *MachinePoolScope) IsServicePaused(service string) bool {
+	return servicePaused(nil, m.AzureMachinePool, service)
+}
+
 // setProvisioningStateAndConditions sets the AzureMachinePool provisioning state and conditions.
 func (m *MachinePoolScope) setProvisioningStateAndConditions(v infrav1.ProvisioningState) {
 	m.AzureMachinePool.Status.ProvisioningState = &v
@@ -174,11 +174,21 @@ func (s *MachinePoolMachineScope) GetLongRunningOperationState(name, service str
 	return futures.Get(s.AzureMachinePoolMachine, name, service)
 }
 
+// GetLongRunningOperationStates will get all the futures on the AzureMachinePoolMachine status.
+func (s *MachinePoolMachineScope) GetLongRunningOperationStates() []*infrav1.Future {
+	return futures.List(s.AzureMachinePoolMachine)
+}
+
 // DeleteLongRunningOperationState will delete the future from the AzureMachinePoolMachine status.
 func (s *MachinePoolMachineScope) DeleteLongRunningOperationState(name, service string) {
 	futures.Delete(s.AzureMachinePoolMachine, name, service)
 }
 
+// IsServicePaused returns true if the AzureMachinePoolMachine is paused or lists the service in its paused services annotation.
+func (s *MachinePoolMachineScope) IsServicePaused(service string) bool {
+	return servicePaused(nil, s.AzureMachinePoolMachine, service)
+}
+
 // UpdateDeleteStatus updates a condition on the AzureMachinePoolMachine status after a DELETE operation.
 func (s *MachinePoolMachineScope) UpdateDeleteStatus(condition clusterv1.ConditionType, service string, err error) {
 	switch {
@@ -685,11 +685,22 @@ func (s *ManagedControlPlaneScope) GetLongRunningOperationState(name, service st
 	return futures.Get(s.ControlPlane, name, service)
 }
 
+// GetLongRunningOperationStates will get all the futures on the AzureManagedControlPlane status.
+func (s *ManagedControlPlaneScope) GetLongRunningOperationStates() []*infrav1.Future {
+	return futures.List(s.ControlPlane)
+}
+
 // DeleteLongRunningOperationState will delete the future from the AzureManagedControlPlane status.
 func (s *ManagedControlPlaneScope) DeleteLongRunningOperationState(name, service string) {
 	futures.Delete(s.ControlPlane, name, service)
 }
 
+// IsServicePaused returns true if the cluster or the AzureManagedControlPlane is paused, or if the AzureManagedControlPlane lists the service in
+// its paused services annotation.
+func (s *ManagedControlPlaneScope) IsServicePaused(service string) bool {
+	return servicePaused(s.Cluster, s.ControlPlane, service)
+}
+
 // UpdateDeleteStatus updates a condition on the AzureManagedControlPlane status after a DELETE operation.
 func (s *ManagedControlPlaneScope) UpdateDeleteStatus(condition clusterv1.ConditionType, service string, err error) {
 	switch {
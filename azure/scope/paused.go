@@ -0,0 +1,41 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scope
+
+import (
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/annotations"
+)
+
+// servicePaused returns true if the reconcile of the service is paused, either for every service because the cluster,
+// if known, or the object is paused, or for this service only because the object lists it in its paused services
+// annotation.
+func servicePaused(cluster *clusterv1.Cluster, obj metav1.Object, serviceName string) bool {
+	if (cluster != nil && cluster.Spec.Paused) || annotations.HasPaused(obj) {
+		return true
+	}
+	for _, name := range strings.Split(obj.GetAnnotations()[azure.PausedServicesAnnotation], ",") {
+		if strings.EqualFold(strings.TrimSpace(name), serviceName) {
+			return true
+		}
+	}
+	return false
+}
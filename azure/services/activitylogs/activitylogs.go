@@ -0,0 +1,137 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package activitylogs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/services/preview/monitor/mgmt/2019-06-01/insights"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
+)
+
+const (
+	// lookbackPeriod is how far back the Activity Log is searched for the entries of an operation.
+	lookbackPeriod = time.Hour
+
+	failedStatus   = "Failed"
+	policyCategory = "Policy"
+
+	policyDeniedCode         = "RequestDisallowedByPolicy"
+	denyAssignmentDeniedCode = "DenyAssignmentAuthorizationFailed"
+	authorizationFailedCode  = "AuthorizationFailed"
+)
+
+// Resolver looks up the Azure Activity Log entries of failed operations to find out why they failed.
+type Resolver struct {
+	client client
+	now    func() time.Time
+}
+
+// NewResolver creates a new Activity Log resolver.
+func NewResolver(auth azure.Authorizer) *Resolver {
+	return &Resolver{
+		client: newClient(auth),
+		now:    time.Now,
+	}
+}
+
+// statusMessage is the format of the statusMessage property of Activity Log entries.
+type statusMessage struct {
+	Error struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// ResolveFailure returns the reason of the failure recorded in the Activity Log for the operations with the given
+// correlation ID. Policy and deny assignment denials take precedence over other failures. An empty reason is returned
+// if no failure was recorded.
+func (r *Resolver) ResolveFailure(ctx context.Context, correlationID string) (string, error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "activitylogs.Resolver.ResolveFailure")
+	defer done()
+
+	events, err := r.client.ListByCorrelationID(ctx, correlationID, r.now().Add(-lookbackPeriod))
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get activity log entries for correlation ID %s", correlationID)
+	}
+
+	var reason string
+	for _, event := range events {
+		if !isFailure(event) {
+			continue
+		}
+		eventReason, denied := failureReason(event)
+		if denied {
+			return eventReason, nil
+		}
+		if reason == "" {
+			reason = eventReason
+		}
+	}
+	return reason, nil
+}
+
+// isFailure returns true if the entry records a failed operation or a policy denial.
+func isFailure(event insights.EventData) bool {
+	return localizedValue(event.Status) == failedStatus || isPolicyDenial(event)
+}
+
+// isPolicyDenial returns true if the entry records Azure Policy denying a request.
+func isPolicyDenial(event insights.EventData) bool {
+	return localizedValue(event.Category) == policyCategory && strings.Contains(strings.ToLower(localizedValue(event.OperationName)), "/deny/")
+}
+
+// failureReason returns a description of the failure recorded by the entry, and whether it is a policy or deny
+// assignment denial.
+func failureReason(event insights.EventData) (reason string, denied bool) {
+	code, message := localizedValue(event.SubStatus), to.String(event.Description)
+	if raw := to.String(event.Properties["statusMessage"]); raw != "" {
+		var status statusMessage
+		if err := json.Unmarshal([]byte(raw), &status); err == nil && status.Error.Code != "" {
+			code, message = status.Error.Code, status.Error.Message
+		} else {
+			message = raw
+		}
+	}
+	operation := localizedValue(event.OperationName)
+
+	switch {
+	case strings.EqualFold(code, policyDeniedCode) || isPolicyDenial(event):
+		return fmt.Sprintf("%s was denied by Azure Policy: %s", operation, message), true
+	case strings.EqualFold(code, denyAssignmentDeniedCode):
+		return fmt.Sprintf("%s was denied by a deny assignment: %s", operation, message), true
+	case strings.EqualFold(code, authorizationFailedCode):
+		return fmt.Sprintf("%s was not authorized: %s", operation, message), false
+	default:
+		return fmt.Sprintf("%s failed (%s): %s", operation, code, message), false
+	}
+}
+
+// localizedValue returns the invariant value of a localizable string.
+func localizedValue(s *insights.LocalizableString) string {
+	if s == nil {
+		return ""
+	}
+	return to.String(s.Value)
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package activitylogs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/services/preview/monitor/mgmt/2019-06-01/insights"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/activitylogs/mock_activitylogs"
+	gomockinternal "sigs.k8s.io/cluster-api-provider-azure/internal/test/matchers/gomock"
+)
+
+const fakeCorrelationID = "00000000-1111-2222-3333-444444444444"
+
+var (
+	fakeNow = time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	startedEvent = insights.EventData{
+		CorrelationID: to.StringPtr(fakeCorrelationID),
+		OperationName: &insights.LocalizableString{Value: to.StringPtr("Microsoft.Network/networkSecurityGroups/write")},
+		Category:      &insights.LocalizableString{Value: to.StringPtr("Administrative")},
+		Status:        &insights.LocalizableString{Value: to.StringPtr("Started")},
+	}
+	failedEvent = insights.EventData{
+		CorrelationID: to.StringPtr(fakeCorrelationID),
+		OperationName: &insights.LocalizableString{Value: to.StringPtr("Microsoft.Network/networkSecurityGroups/write")},
+		Category:      &insights.LocalizableString{Value: to.StringPtr("Administrative")},
+		Status:        &insights.LocalizableString{Value: to.StringPtr("Failed")},
+		SubStatus:     &insights.LocalizableString{Value: to.StringPtr("Conflict")},
+		Properties: map[string]*string{
+			"statusMessage": to.StringPtr(`{"error":{"code":"InUseNetworkSecurityGroupCannotBeDeleted","message":"Network security group is in use."}}`),
+		},
+	}
+	policyDeniedEvent = insights.EventData{
+		CorrelationID: to.StringPtr(fakeCorrelationID),
+		OperationName: &insights.LocalizableString{Value: to.StringPtr("Microsoft.Network/networkSecurityGroups/write")},
+		Category:      &insights.LocalizableString{Value: to.StringPtr("Administrative")},
+		Status:        &insights.LocalizableString{Value: to.StringPtr("Failed")},
+		Properties: map[string]*string{
+			"statusMessage": to.StringPtr(`{"error":{"code":"RequestDisallowedByPolicy","message":"Resource 'my-nsg' was disallowed by policy 'deny-open-ssh'."}}`),
+		},
+	}
+	denyAssignmentEvent = insights.EventData{
+		CorrelationID: to.StringPtr(fakeCorrelationID),
+		OperationName: &insights.LocalizableString{Value: to.StringPtr("Microsoft.Network/networkSecurityGroups/delete")},
+		Category:      &insights.LocalizableString{Value: to.StringPtr("Administrative")},
+		Status:        &insights.LocalizableString{Value: to.StringPtr("Failed")},
+		Properties: map[string]*string{
+			"statusMessage": to.StringPtr(`{"error":{"code":"DenyAssignmentAuthorizationFailed","message":"The client has permission but it is blocked by deny assignment 'managed-rg-lock'."}}`),
+		},
+	}
+)
+
+func TestResolveFailure(t *testing.T) {
+	testcases := []struct {
+		name           string
+		expect         func(m *mock_activitylogs.MockclientMockRecorder)
+		expectedReason string
+		expectedError  string
+	}{
+		{
+			name: "no failure was recorded",
+			expect: func(m *mock_activitylogs.MockclientMockRecorder) {
+				m.ListByCorrelationID(gomockinternal.AContext(), fakeCorrelationID, fakeNow.Add(-lookbackPeriod)).Return([]insights.EventData{startedEvent}, nil)
+			},
+			expectedReason: "",
+		},
+		{
+			name: "operation failed",
+			expect: func(m *mock_activitylogs.MockclientMockRecorder) {
+				m.ListByCorrelationID(gomockinternal.AContext(), fakeCorrelationID, fakeNow.Add(-lookbackPeriod)).Return([]insights.EventData{startedEvent, failedEvent}, nil)
+			},
+			expectedReason: "Microsoft.Network/networkSecurityGroups/write failed (InUseNetworkSecurityGroupCannotBeDeleted): Network security group is in use.",
+		},
+		{
+			name: "policy denial takes precedence over other failures",
+			expect: func(m *mock_activitylogs.MockclientMockRecorder) {
+				m.ListByCorrelationID(gomockinternal.AContext(), fakeCorrelationID, fakeNow.Add(-lookbackPeriod)).Return([]insights.EventData{failedEvent, policyDeniedEvent}, nil)
+			},
+			expectedReason: "Microsoft.Network/networkSecurityGroups/write was denied by Azure Policy: Resource 'my-nsg' was disallowed by policy 'deny-open-ssh'.",
+		},
+		{
+			name: "operation was blocked by a deny assignment",
+			expect: func(m *mock_activitylogs.MockclientMockRecorder) {
+				m.ListByCorrelationID(gomockinternal.AContext(), fakeCorrelationID, fakeNow.Add(-lookbackPeriod)).Return([]insights.EventData{startedEvent, denyAssignmentEvent}, nil)
+			},
+			expectedReason: "Microsoft.Network/networkSecurityGroups/delete was denied by a deny assignment: The client has permission but it is blocked by deny assignment 'managed-rg-lock'.",
+		},
+		{
+			name: "activity log cannot be queried",
+			expect: func(m *mock_activitylogs.MockclientMockRecorder) {
+				m.ListByCorrelationID(gomockinternal.AContext(), fakeCorrelationID, fakeNow.Add(-lookbackPeriod)).Return(nil, errors.New("#: Internal Server Error: StatusCode=500"))
+			},
+			expectedError: "failed to get activity log entries for correlation ID 00000000-1111-2222-3333-444444444444: #: Internal Server Error: StatusCode=500",
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			clientMock := mock_activitylogs.NewMockclient(mockCtrl)
+
+			tc.expect(clientMock.EXPECT())
+
+			r := &Resolver{
+				client: clientMock,
+				now:    func() time.Time { return fakeNow },
+			}
+
+			reason, err := r.ResolveFailure(context.TODO(), fakeCorrelationID)
+			if tc.expectedError != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err).To(MatchError(tc.expectedError))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(reason).To(Equal(tc.expectedReason))
+			}
+		})
+	}
+}
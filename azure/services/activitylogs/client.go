@@ -0,0 +1,76 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package activitylogs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/services/preview/monitor/mgmt/2019-06-01/insights"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
+)
+
+// client wraps go-sdk.
+type client interface {
+	ListByCorrelationID(ctx context.Context, correlationID string, since time.Time) ([]insights.EventData, error)
+}
+
+// azureClient contains the Azure go-sdk Client.
+type azureClient struct {
+	activityLogs insights.ActivityLogsClient
+}
+
+var _ client = (*azureClient)(nil)
+
+// newClient creates a new activity logs client from subscription ID.
+func newClient(auth azure.Authorizer) *azureClient {
+	c := newActivityLogsClient(auth.SubscriptionID(), auth.BaseURI(), auth.Authorizer())
+	return &azureClient{c}
+}
+
+// newActivityLogsClient creates a new activity logs client from subscription ID.
+func newActivityLogsClient(subscriptionID string, baseURI string, authorizer autorest.Authorizer) insights.ActivityLogsClient {
+	activityLogsClient := insights.NewActivityLogsClientWithBaseURI(baseURI, subscriptionID)
+	azure.SetAutoRestClientDefaults(&activityLogsClient.Client, authorizer)
+	return activityLogsClient
+}
+
+// ListByCorrelationID returns the Activity Log entries with the given correlation ID that were submitted after since.
+func (ac *azureClient) ListByCorrelationID(ctx context.Context, correlationID string, since time.Time) ([]insights.EventData, error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "activitylogs.AzureClient.ListByCorrelationID")
+	defer done()
+
+	filter := fmt.Sprintf("eventTimestamp ge '%s' and correlationId eq '%s'", since.UTC().Format(time.RFC3339), correlationID)
+	iter, err := ac.activityLogs.ListComplete(ctx, filter, "")
+	if err != nil {
+		return nil, errors.Wrap(err, "could not list activity log entries")
+	}
+
+	var events []insights.EventData
+	for iter.NotDone() {
+		events = append(events, iter.Value())
+		if err := iter.NextWithContext(ctx); err != nil {
+			return events, errors.Wrap(err, "could not iterate activity log entries")
+		}
+	}
+
+	return events, nil
+}
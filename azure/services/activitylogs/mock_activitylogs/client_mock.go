@@ -0,0 +1,68 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ../client.go
+
+// Package mock_activitylogs is a generated GoMock package.
+package mock_activitylogs
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	insights "github.com/Azure/azure-sdk-for-go/services/preview/monitor/mgmt/2019-06-01/insights"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// Mockclient is a mock of client interface.
+type Mockclient struct {
+	ctrl     *gomock.Controller
+	recorder *MockclientMockRecorder
+}
+
+// MockclientMockRecorder is the mock recorder for Mockclient.
+type MockclientMockRecorder struct {
+	mock *Mockclient
+}
+
+// NewMockclient creates a new mock instance.
+func NewMockclient(ctrl *gomock.Controller) *Mockclient {
+	mock := &Mockclient{ctrl: ctrl}
+	mock.recorder = &MockclientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *Mockclient) EXPECT() *MockclientMockRecorder {
+	return m.recorder
+}
+
+// ListByCorrelationID mocks base method.
+func (m *Mockclient) ListByCorrelationID(ctx context.Context, correlationID string, since time.Time) ([]insights.EventData, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByCorrelationID", ctx, correlationID, since)
+	ret0, _ := ret[0].([]insights.EventData)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByCorrelationID indicates an expected call of ListByCorrelationID.
+func (mr *MockclientMockRecorder) ListByCorrelationID(ctx, correlationID, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByCorrelationID", reflect.TypeOf((*Mockclient)(nil).ListByCorrelationID), ctx, correlationID, since)
+}
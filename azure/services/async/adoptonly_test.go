@@ -0,0 +1,130 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package async
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/async/mock_async"
+	gomockinternal "sigs.k8s.io/cluster-api-provider-azure/internal/test/matchers/gomock"
+)
+
+// adoptOnlySpec is a spec whose resource is only adopted when adoptOnly is true.
+type adoptOnlySpec struct {
+	dependentSpec
+	adoptOnly bool
+}
+
+func (s *adoptOnlySpec) Parameters(existing interface{}) (interface{}, error) {
+	return &fakeResourceParameters, nil
+}
+
+func (s *adoptOnlySpec) AdoptOnly() bool {
+	return s.adoptOnly
+}
+
+// TestCreateResourceAdoptOnly tests that CreateResource does not create the missing resource of an adopt-only spec.
+func TestCreateResourceAdoptOnly(t *testing.T) {
+	testcases := []struct {
+		name          string
+		spec          azure.ResourceSpecGetter
+		expectedError string
+		expect        func(c *mock_async.MockCreatorMockRecorder)
+	}{
+		{
+			name:          "missing adopt-only resource is not created",
+			spec:          &adoptOnlySpec{dependentSpec: dependentSpec{name: "test-resource"}, adoptOnly: true},
+			expectedError: "resource test-group/test-resource (service: test-service) does not exist, it must be created before it can be adopted",
+			expect: func(c *mock_async.MockCreatorMockRecorder) {
+				c.Get(gomockinternal.AContext(), gomock.Any()).Return(nil, fakeNotFoundError)
+			},
+		},
+		{
+			name: "existing adopt-only resource is updated",
+			spec: &adoptOnlySpec{dependentSpec: dependentSpec{name: "test-resource"}, adoptOnly: true},
+			expect: func(c *mock_async.MockCreatorMockRecorder) {
+				c.Get(gomockinternal.AContext(), gomock.Any()).Return(&fakeExistingResource, nil)
+				c.CreateOrUpdateAsync(gomockinternal.AContext(), gomock.Any(), &fakeResourceParameters).Return("test-resource", nil, nil)
+			},
+		},
+		{
+			name: "missing resource of a spec that is not adopt-only is created",
+			spec: &adoptOnlySpec{dependentSpec: dependentSpec{name: "test-resource"}},
+			expect: func(c *mock_async.MockCreatorMockRecorder) {
+				c.Get(gomockinternal.AContext(), gomock.Any()).Return(nil, fakeNotFoundError)
+				c.CreateOrUpdateAsync(gomockinternal.AContext(), gomock.Any(), &fakeResourceParameters).Return("test-resource", nil, nil)
+			},
+		},
+		{
+			name:          "adopt-only resource that cannot be read is not created",
+			spec:          &adoptOnlySpec{dependentSpec: dependentSpec{name: "test-resource"}, adoptOnly: true},
+			expectedError: "failed to get existing resource test-group/test-resource (service: test-service)",
+			expect: func(c *mock_async.MockCreatorMockRecorder) {
+				c.Get(gomockinternal.AContext(), gomock.Any()).Return(nil, fakeInternalError)
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			scopeMock := newMockFutureScope(mockCtrl)
+			creatorMock := mock_async.NewMockCreator(mockCtrl)
+
+			scopeMock.EXPECT().GetLongRunningOperationState("test-resource", "test-service").Return(nil)
+			tc.expect(creatorMock.EXPECT())
+
+			s := New(scopeMock, creatorMock, nil)
+			result, changed, err := s.CreateResource(context.TODO(), tc.spec, "test-service")
+			if tc.expectedError != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tc.expectedError)))
+				g.Expect(changed).To(BeFalse())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(result).To(Equal("test-resource"))
+			g.Expect(changed).To(BeTrue())
+		})
+	}
+}
+
+func TestCreateResourceAdoptOnlyIsTerminal(t *testing.T) {
+	g := NewWithT(t)
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	scopeMock := newMockFutureScope(mockCtrl)
+	creatorMock := mock_async.NewMockCreator(mockCtrl)
+
+	scopeMock.EXPECT().GetLongRunningOperationState("test-resource", "test-service").Return(nil)
+	creatorMock.EXPECT().Get(gomockinternal.AContext(), gomock.Any()).Return(nil, fakeNotFoundError)
+
+	s := New(scopeMock, creatorMock, nil)
+	_, _, err := s.CreateResource(context.TODO(), &adoptOnlySpec{dependentSpec: dependentSpec{name: "test-resource"}, adoptOnly: true}, "test-service")
+	g.Expect(azure.IsResourceMustExistError(err)).To(BeTrue())
+	var reconcileErr azure.ReconcileError
+	g.Expect(errors.As(err, &reconcileErr)).To(BeTrue())
+	g.Expect(reconcileErr.IsTerminal()).To(BeTrue())
+}
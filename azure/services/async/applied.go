@@ -0,0 +1,91 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package async
+
+import (
+	"context"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
+)
+
+// appliedParametersScope returns the scope of the service as an AppliedParametersScope if the service records the
+// parameters applied to resources and the scope can persist them.
+func (s *Service) appliedParametersScope() (AppliedParametersScope, bool) {
+	if !s.appliedParametersGuard {
+		return nil, false
+	}
+	scope, ok := s.Scope.(AppliedParametersScope)
+	return scope, ok
+}
+
+// parametersApplied returns true if the desired parameters of an existing resource are the ones last applied to it
+// and the resource was not modified since. The record of the applied parameters is dropped if the resource was
+// modified, e.g. outside of CAPZ, so that the resource is updated again.
+func (s *Service) parametersApplied(ctx context.Context, existing interface{}, parameters interface{}, resourceName, rgName, serviceName string) bool {
+	scope, ok := s.appliedParametersScope()
+	if !ok || existing == nil {
+		return false
+	}
+	appliedHash, resourceHash := scope.AppliedParameters(resourceName, serviceName, rgName)
+	if appliedHash == "" || resourceHash == "" {
+		return false
+	}
+	if parametersHash(existing) != resourceHash {
+		_, log, done := tele.StartSpanWithLogger(ctx, "async.Service.parametersApplied")
+		defer done()
+
+		log.V(2).Info("resource was modified since its parameters were applied", "service", serviceName, "resource", resourceName)
+		scope.DeleteAppliedParameters(resourceName, serviceName, rgName)
+		return false
+	}
+	return parametersHash(parameters) == appliedHash
+}
+
+// recordAppliedParameters records the hash of the parameters applied to a resource and of the resource Azure returned.
+// It must only be called once Azure reported that the parameters were applied successfully.
+func (s *Service) recordAppliedParameters(resourceName, rgName, serviceName, appliedHash string, result interface{}) {
+	scope, ok := s.appliedParametersScope()
+	if !ok {
+		return
+	}
+	resourceHash := ""
+	if result != nil {
+		resourceHash = parametersHash(result)
+	}
+	if appliedHash == "" || resourceHash == "" {
+		scope.DeleteAppliedParameters(resourceName, serviceName, rgName)
+		return
+	}
+	scope.SetAppliedParameters(resourceName, serviceName, rgName, appliedHash, resourceHash)
+}
+
+// recordPolledParameters records the parameters applied by the operation tracked by the future once it was polled to
+// success. Delete operations apply no parameters.
+func (s *Service) recordPolledParameters(future *infrav1.Future, resourceName, serviceName string, result interface{}) {
+	if future.Type == infrav1.DeleteFuture {
+		return
+	}
+	s.recordAppliedParameters(resourceName, future.ResourceGroup, serviceName, future.ParametersHash, result)
+}
+
+// forgetAppliedParameters drops the record of the parameters applied to a resource, e.g. because it is deleted.
+func (s *Service) forgetAppliedParameters(resourceName, rgName, serviceName string) {
+	if scope, ok := s.appliedParametersScope(); ok {
+		scope.DeleteAppliedParameters(resourceName, serviceName, rgName)
+	}
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package async
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2019-05-01/resources"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/mock_azure"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/async/mock_async"
+	gomockinternal "sigs.k8s.io/cluster-api-provider-azure/internal/test/matchers/gomock"
+)
+
+// appliedParametersFutureScope is a FutureScope that records the parameters applied to resources.
+type appliedParametersFutureScope struct {
+	*mock_async.MockFutureScope
+	*mock_async.MockAppliedParametersScope
+}
+
+// TestAppliedParametersGuard tests that a resource is not created or updated again when its desired parameters were
+// already applied and the resource was not modified since.
+func TestAppliedParametersGuard(t *testing.T) {
+	existing := resources.GenericResource{Location: to.StringPtr("test-location")}
+	modified := resources.GenericResource{Location: to.StringPtr("other-location")}
+	updated := resources.GenericResource{Location: to.StringPtr("test-location"), Kind: to.StringPtr("updated")}
+
+	testcases := []struct {
+		name            string
+		expectedChanged bool
+		expectedResult  interface{}
+		expect          func(s *mock_async.MockAppliedParametersScopeMockRecorder, c *mock_async.MockCreatorMockRecorder)
+	}{
+		{
+			name:            "parameters were applied and resource is unchanged, skips the update",
+			expectedChanged: false,
+			expectedResult:  existing,
+			expect: func(s *mock_async.MockAppliedParametersScopeMockRecorder, c *mock_async.MockCreatorMockRecorder) {
+				s.AppliedParameters("test-resource", "test-service", "test-group").Return(parametersHash("parameters"), parametersHash(existing))
+			},
+		},
+		{
+			name:            "resource was modified since the parameters were applied, updates the resource",
+			expectedChanged: true,
+			expectedResult:  updated,
+			expect: func(s *mock_async.MockAppliedParametersScopeMockRecorder, c *mock_async.MockCreatorMockRecorder) {
+				s.AppliedParameters("test-resource", "test-service", "test-group").Return(parametersHash("parameters"), parametersHash(modified))
+				s.DeleteAppliedParameters("test-resource", "test-service", "test-group")
+				c.CreateOrUpdateAsync(gomockinternal.AContext(), gomock.Any(), "parameters").Return(updated, nil, nil)
+				s.SetAppliedParameters("test-resource", "test-service", "test-group", parametersHash("parameters"), parametersHash(updated))
+			},
+		},
+		{
+			name:            "desired parameters changed, updates the resource",
+			expectedChanged: true,
+			expectedResult:  updated,
+			expect: func(s *mock_async.MockAppliedParametersScopeMockRecorder, c *mock_async.MockCreatorMockRecorder) {
+				s.AppliedParameters("test-resource", "test-service", "test-group").Return(parametersHash("old-parameters"), parametersHash(existing))
+				c.CreateOrUpdateAsync(gomockinternal.AContext(), gomock.Any(), "parameters").Return(updated, nil, nil)
+				s.SetAppliedParameters("test-resource", "test-service", "test-group", parametersHash("parameters"), parametersHash(updated))
+			},
+		},
+		{
+			name:            "no parameters were recorded, updates the resource",
+			expectedChanged: true,
+			expectedResult:  updated,
+			expect: func(s *mock_async.MockAppliedParametersScopeMockRecorder, c *mock_async.MockCreatorMockRecorder) {
+				s.AppliedParameters("test-resource", "test-service", "test-group").Return("", "")
+				c.CreateOrUpdateAsync(gomockinternal.AContext(), gomock.Any(), "parameters").Return(updated, nil, nil)
+				s.SetAppliedParameters("test-resource", "test-service", "test-group", parametersHash("parameters"), parametersHash(updated))
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			futureScopeMock := newMockFutureScope(mockCtrl)
+			appliedScopeMock := mock_async.NewMockAppliedParametersScope(mockCtrl)
+			creatorMock := mock_async.NewMockCreator(mockCtrl)
+			specMock := mock_azure.NewMockResourceSpecGetter(mockCtrl)
+
+			specMock.EXPECT().ResourceName().Return("test-resource").AnyTimes()
+			specMock.EXPECT().ResourceGroupName().Return("test-group").AnyTimes()
+			specMock.EXPECT().Parameters(existing).Return("parameters", nil)
+			futureScopeMock.EXPECT().GetLongRunningOperationState("test-resource", "test-service").Return(nil)
+			creatorMock.EXPECT().Get(gomockinternal.AContext(), specMock).Return(existing, nil)
+			tc.expect(appliedScopeMock.EXPECT(), creatorMock.EXPECT())
+
+			s := New(&appliedParametersFutureScope{futureScopeMock, appliedScopeMock}, creatorMock, nil, WithAppliedParametersGuard())
+			result, changed, err := s.CreateResource(context.TODO(), specMock, "test-service")
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(changed).To(Equal(tc.expectedChanged))
+			g.Expect(result).To(Equal(tc.expectedResult))
+		})
+	}
+}
+
+// TestAppliedParametersGuardAsync tests that the parameters of an operation are recorded when it completes.
+func TestAppliedParametersGuardAsync(t *testing.T) {
+	g := NewWithT(t)
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	futureScopeMock := newMockFutureScope(mockCtrl)
+	appliedScopeMock := mock_async.NewMockAppliedParametersScope(mockCtrl)
+	creatorMock := mock_async.NewMockCreator(mockCtrl)
+	specMock := mock_azure.NewMockResourceSpecGetter(mockCtrl)
+
+	future := validCreateFuture
+	future.ParametersHash = parametersHash("parameters")
+	created := resources.GenericResource{Location: to.StringPtr("test-location")}
+	specMock.EXPECT().ResourceName().Return("test-resource").AnyTimes()
+	specMock.EXPECT().ResourceGroupName().Return("test-group").AnyTimes()
+	futureScopeMock.EXPECT().GetLongRunningOperationState("test-resource", "test-service").Return(&future).Times(2)
+	creatorMock.EXPECT().IsDone(gomockinternal.AContext(), gomock.Any()).Return(true, nil)
+	creatorMock.EXPECT().Result(gomockinternal.AContext(), gomock.Any(), future.Type).Return(created, nil)
+	futureScopeMock.EXPECT().DeleteLongRunningOperationState("test-resource", "test-service")
+	appliedScopeMock.EXPECT().SetAppliedParameters("test-resource", "test-service", "test-group", parametersHash("parameters"), parametersHash(created))
+
+	s := New(&appliedParametersFutureScope{futureScopeMock, appliedScopeMock}, creatorMock, nil, WithAppliedParametersGuard())
+	s.operations = newOperationTracker()
+	result, changed, err := s.CreateResource(context.TODO(), specMock, "test-service")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(changed).To(BeFalse())
+	g.Expect(result).To(Equal(created))
+}
+
+// TestAppliedParametersGuardRefresh tests that the parameters of an operation that could not be polled are not recorded
+// when the resource shows it is done, since a failed update also leaves the resource provisioned successfully.
+func TestAppliedParametersGuardRefresh(t *testing.T) {
+	g := NewWithT(t)
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	futureScopeMock := newMockFutureScope(mockCtrl)
+	appliedScopeMock := mock_async.NewMockAppliedParametersScope(mockCtrl)
+	creatorMock := mock_async.NewMockCreator(mockCtrl)
+	stateGetterMock := mock_async.NewMockProvisioningStateGetter(mockCtrl)
+
+	future := validCreateFuture
+	future.ParametersHash = parametersHash("parameters")
+	existing := resources.GenericResource{Location: to.StringPtr("test-location")}
+	futureScopeMock.EXPECT().GetLongRunningOperationState("test-resource", "test-service").Return(&future)
+	creatorMock.EXPECT().Get(gomockinternal.AContext(), gomock.Any()).Return(existing, nil)
+	stateGetterMock.EXPECT().ProvisioningState(existing).Return(string(infrav1.Succeeded), true)
+	futureScopeMock.EXPECT().DeleteLongRunningOperationState("test-resource", "test-service")
+	appliedScopeMock.EXPECT().DeleteAppliedParameters("test-resource", "test-service", "test-group")
+
+	s := New(&appliedParametersFutureScope{futureScopeMock, appliedScopeMock}, provisioningStateCreator{creatorMock, stateGetterMock}, nil, WithAppliedParametersGuard())
+	s.operations = newOperationTracker()
+	result, done, err := s.RefreshOperation(context.TODO(), testSpec("test-resource"), "test-service")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(done).To(BeTrue())
+	g.Expect(result).To(Equal(existing))
+}
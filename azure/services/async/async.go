@@ -18,10 +18,20 @@ package async
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/rand"
+	"strings"
 	"time"
 
 	azureautorest "github.com/Azure/go-autorest/autorest/azure"
+	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/trace"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 	"sigs.k8s.io/cluster-api-provider-azure/azure"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/converters"
@@ -29,156 +39,1370 @@ import (
 	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
 )
 
+// ErrResourceNotFound is returned by GetResource when the resource does not exist.
+var ErrResourceNotFound = errors.New("resource not found")
+
+// Change describes the operation a Service in dry-run mode would have started on a resource.
+type Change struct {
+	ServiceName   string
+	ResourceGroup string
+	ResourceName  string
+	// Type is the type of the operation, e.g. infrav1.PutFuture or infrav1.PatchFuture.
+	Type string
+	// Existing is the current state of the resource, or nil if the resource would be created.
+	Existing interface{}
+	// Parameters are the parameters the resource would be created or updated with.
+	Parameters interface{}
+}
+
+// defaultFutureTTL is how long a long-running operation state is kept before it is considered stale.
+const defaultFutureTTL = 2 * time.Hour
+
 // Service is an implementation of the Reconciler interface. It handles asynchronous creation and deletion of resources.
 type Service struct {
 	Scope FutureScope
 	Creator
 	Deleter
+	// PollerCreator and PollerDeleter, if set, are clients of the track2 SDK used instead of Creator and Deleter.
+	PollerCreator PollerCreator
+	PollerDeleter PollerDeleter
+
+	migrator  FutureMigrator
+	scheduler *FairScheduler
+	owner     string
+	resolver  FailureResolver
+	// rateLimiter, if set, bounds the rate of the create and delete requests of all the services sharing it.
+	rateLimiter *RateLimiter
+	// concurrencyLimiter, if set, bounds the create and delete requests in flight of all the services sharing it. Each
+	// request of the service holds concurrencyWeight while it is being made.
+	concurrencyLimiter *ConcurrencyLimiter
+	concurrencyWeight  int64
+	// operations tracks the polls and start time of each ongoing operation.
+	operations *operationTracker
+	// requeueAfter is the interval after which resources with an ongoing operation are reconciled again.
+	requeueAfter time.Duration
+	// maxRequeueAfter is the cap of the exponential backoff of the requeue interval. Backoff is disabled when zero.
+	maxRequeueAfter time.Duration
+	// jitter, if set, spreads the requeue intervals so that operations started together are not polled together.
+	jitter *jitter
+	// futureTTL is the age after which a long-running operation state is reset. Stale states are kept when zero.
+	futureTTL time.Duration
+	// maxAttempts is the number of times an operation is checked on before giving up on it. There is no limit when zero.
+	maxAttempts int
+	// reconcileTimeout is the timeout of the reconcile of the service using the async service. The default timeout is
+	// used when zero.
+	reconcileTimeout time.Duration
+	// dryRun, when true, skips the operations that create, update or delete resources.
+	dryRun bool
+	// checkExistenceBeforeDelete, when true, gets a resource before deleting it and skips the delete if it is not found.
+	checkExistenceBeforeDelete bool
+	// notFoundGracePeriod is how long after a create operation is stored a resource that is not found is considered
+	// as still being created rather than missing. Resources that are not found are always created when zero.
+	notFoundGracePeriod time.Duration
+	// restartOnSpecChange, when true, abandons the create or update operations whose parameters are no longer the
+	// desired ones and starts new operations with the desired parameters.
+	restartOnSpecChange bool
+	// appliedParametersGuard, when true, skips the create or update of resources whose parameters were already
+	// applied and that were not modified since.
+	appliedParametersGuard bool
+	// pollAfterCreate, when true, checks once on a create or update operation right after starting it, before
+	// requeueing.
+	pollAfterCreate bool
+	// corrID, if set, is the correlation ID sent with every Azure request instead of the one of the reconcile context.
+	corrID tele.CorrID
+	// recorder, if set, records events on eventObject when operations complete or fail.
+	recorder    record.EventRecorder
+	eventObject runtime.Object
+	// preOpHook and postOpHook, if set, are called before each create, update or delete request and after each
+	// operation completes.
+	preOpHook  PreOpHook
+	postOpHook PostOpHook
+	// quotaChecker, if set, checks that the resources of specs declaring a quota requirement can be created without
+	// exceeding the quota.
+	quotaChecker QuotaChecker
+}
+
+// Option is a configuration option supplied to New.
+type Option func(*Service)
+
+// WithFutureMigrator configures the service to try converting futures stored in an incompatible format
+// before resetting them.
+func WithFutureMigrator(migrator FutureMigrator) Option {
+	return func(s *Service) {
+		s.migrator = migrator
+	}
+}
+
+// WithFairScheduler configures the service to take a slot from a scheduler shared with other services before starting
+// an operation. The owner identifies the object owning the resources, e.g. the cluster, so that slots are shared fairly.
+func WithFairScheduler(scheduler *FairScheduler, owner string) Option {
+	return func(s *Service) {
+		s.scheduler = scheduler
+		s.owner = owner
+	}
+}
+
+// WithRateLimiter configures the service to take a token from a rate limiter shared with other services before making a
+// create or delete request, and to back off all the services sharing it while Azure throttles requests. A request that
+// has to wait is requeued after the delay returned by the rate limiter rather than blocking the reconcile.
+func WithRateLimiter(limiter *RateLimiter) Option {
+	return func(s *Service) {
+		s.rateLimiter = limiter
+	}
+}
+
+// WithConcurrencyLimiter configures the service to acquire the given weight from a concurrency limiter shared with
+// other services before making a create or delete request, and to release it once the request was made. A request
+// that cannot acquire its weight is requeued with an azure.ConcurrencyLimitedError rather than blocking the reconcile.
+// The weight defaults to 1 if it is not positive, and is clamped to the capacity of the limiter since a heavier
+// request could never be made.
+func WithConcurrencyLimiter(limiter *ConcurrencyLimiter, weight int64) Option {
+	return func(s *Service) {
+		if weight <= 0 {
+			weight = 1
+		}
+		if limiter != nil && weight > limiter.Capacity() {
+			weight = limiter.Capacity()
+		}
+		s.concurrencyLimiter = limiter
+		s.concurrencyWeight = weight
+	}
+}
+
+// WithFailureResolver configures the service to look up why an operation it started failed and to add the reason to
+// the returned error. Each lookup costs extra Azure API calls, so this is opt-in.
+func WithFailureResolver(resolver FailureResolver) Option {
+	return func(s *Service) {
+		s.resolver = resolver
+	}
+}
+
+// WithRequeueAfter configures the interval after which resources with an ongoing operation are reconciled again.
+// It defaults to reconciler.DefaultReconcilerRequeue. A longer RETRY-AFTER header returned by Azure takes precedence.
+func WithRequeueAfter(requeueAfter time.Duration) Option {
+	return func(s *Service) {
+		s.requeueAfter = requeueAfter
+	}
+}
+
+// WithBackoff configures the service to double the requeue interval each time an ongoing operation is found not done,
+// up to maxRequeueAfter, so that operations that take a long time to complete are polled less often.
+// The interval goes back to its initial value once the operation is done.
+func WithBackoff(maxRequeueAfter time.Duration) Option {
+	return func(s *Service) {
+		s.maxRequeueAfter = maxRequeueAfter
+	}
+}
+
+// WithJitter configures the service to move the requeue intervals of resources with an ongoing operation randomly by up
+// to ±factor of their value, e.g. 0.1 for ±10%, so that the operations of clusters created at the same time are not all
+// polled at the same time. A longer RETRY-AFTER header returned by Azure still takes precedence. Random values are drawn
+// from source, e.g. a seeded source to get the same intervals in tests, or from a source seeded with the current time
+// if source is nil. The factor is capped at 1.
+func WithJitter(factor float64, source rand.Source) Option {
+	return func(s *Service) {
+		s.jitter = newJitter(factor, source)
+	}
+}
+
+// WithFutureTTL configures how long a long-running operation state is kept before it is reset and the operation is
+// restarted, e.g. because the operation is no longer known to Azure after a long controller outage.
+// It defaults to 2 hours. A zero TTL never resets operation states because of their age.
+func WithFutureTTL(ttl time.Duration) Option {
+	return func(s *Service) {
+		s.futureTTL = ttl
+	}
+}
+
+// WithMaxAttempts configures the service to give up on a long-running operation once it has been checked on maxAttempts
+// times without completing successfully, e.g. because of a quota error that never clears. The long-running operation
+// state is then reset and a terminal error with the last error is returned, so that the object is no longer requeued
+// and its condition is marked as failed. Attempts are counted by each controller process. There is no limit by default.
+func WithMaxAttempts(maxAttempts int) Option {
+	return func(s *Service) {
+		s.maxAttempts = maxAttempts
+	}
+}
+
+// WithReconcileTimeout configures the timeout of the Reconcile and Delete of the service using the async service, for
+// services whose operations legitimately take longer than reconciler.DefaultAzureServiceReconcileTimeout.
+func WithReconcileTimeout(timeout time.Duration) Option {
+	return func(s *Service) {
+		s.reconcileTimeout = timeout
+	}
+}
+
+// WithDryRun configures the service to compute the changes a reconcile would make without making them. CreateResource
+// gets the resource and computes its parameters, then returns a *Change instead of creating or updating the resource,
+// and DeleteResource returns without deleting it. Operations already in progress are still tracked to completion.
+func WithDryRun() Option {
+	return func(s *Service) {
+		s.dryRun = true
+	}
+}
+
+// WithExistenceCheckBeforeDelete configures the service to get a resource before deleting it when no delete operation
+// is in progress, and to skip the delete request if the resource is not found, e.g. while waiting for the resources
+// depending on it to be deleted during the teardown of a cluster. The resource is got with the client of the creates,
+// so the check is skipped for services without one.
+func WithExistenceCheckBeforeDelete() Option {
+	return func(s *Service) {
+		s.checkExistenceBeforeDelete = true
+	}
+}
+
+// WithNotFoundGracePeriod configures the service to consider a resource that is not found as still being created, rather
+// than to create it again, when a create operation of the resource was stored less than gracePeriod ago, e.g. by a
+// reconcile whose long-running operation state is not visible yet. This avoids starting a duplicate create while
+// Azure does not report the resource yet. Create operations are tracked by each controller process.
+func WithNotFoundGracePeriod(gracePeriod time.Duration) Option {
+	return func(s *Service) {
+		s.notFoundGracePeriod = gracePeriod
+	}
+}
+
+// WithRestartOnSpecChange configures the service to abandon an ongoing create or update operation when the desired
+// parameters of the resource change while it is in progress, e.g. because the user edited the spec, and to start a
+// new operation with the desired parameters instead of waiting for the stale one to complete. A hash of the parameters
+// of each operation is stored in its future for the comparison, which costs a GET of the resource each time the
+// operation is checked on. Operations stored without a hash, e.g. by a previous version, are tracked to completion.
+func WithRestartOnSpecChange() Option {
+	return func(s *Service) {
+		s.restartOnSpecChange = true
+	}
+}
+
+// WithAppliedParametersGuard configures the service to record a hash of the parameters applied to each resource, and of
+// the resource Azure returned then, in the scope if it is an AppliedParametersScope. A resource whose desired
+// parameters match the recorded ones is not created or updated again, e.g. after a controller restart, unless it was
+// modified outside of the service since, in which case the record is dropped.
+func WithAppliedParametersGuard() Option {
+	return func(s *Service) {
+		s.appliedParametersGuard = true
+	}
+}
+
+// WithPollAfterCreate configures the service to check once whether a create or update operation is done right after
+// starting it, rather than only after the requeue interval, so that operations completing within seconds are done in
+// the reconcile that started them. It costs an extra request for each operation that is not done yet, so it is meant
+// for services whose resources are usually created or updated quickly.
+func WithPollAfterCreate() Option {
+	return func(s *Service) {
+		s.pollAfterCreate = true
+	}
+}
+
+// WithCorrelationID configures the service to send corrID as x-ms-correlation-request-id with every Azure request it
+// makes, e.g. an ID derived from the object owning the resources with tele.CorrIDFromObject, so that the operations
+// of a reconcile can be looked up by a stable ID. By default, the correlation ID of the reconcile context is sent.
+func WithCorrelationID(corrID tele.CorrID) Option {
+	return func(s *Service) {
+		s.corrID = corrID
+	}
+}
+
+// WithEventRecorder configures the service to record events on the given object, e.g. the cluster owning the
+// resources, when a long-running operation completes and when an operation fails with a terminal error.
+func WithEventRecorder(recorder record.EventRecorder, object runtime.Object) Option {
+	return func(s *Service) {
+		s.recorder = recorder
+		s.eventObject = object
+	}
+}
+
+// WithOperationHooks configures the service to call pre before each create, update or delete request, and post once
+// the operation completed, e.g. for auditing or to notify external systems. Either hook may be nil. The hooks do not
+// change how operations are processed, except that an error returned by pre aborts the operation.
+func WithOperationHooks(pre PreOpHook, post PostOpHook) Option {
+	return func(s *Service) {
+		s.preOpHook = pre
+		s.postOpHook = post
+	}
+}
+
+// WithQuotaCheck configures the service to check, before creating a resource whose spec declares a quota requirement
+// with azure.QuotaSpecGetter, that the creation would not exceed the quota. A creation that would exceed it is not
+// requested and returns a terminal azure.QuotaExceededError instead of failing after a full operation. Specs without a
+// quota requirement and updates of existing resources are not checked.
+func WithQuotaCheck(checker QuotaChecker) Option {
+	return func(s *Service) {
+		s.quotaChecker = checker
+	}
 }
 
 // New creates a new async service.
-func New(scope FutureScope, createClient Creator, deleteClient Deleter) *Service {
-	return &Service{
-		Scope:   scope,
-		Creator: createClient,
-		Deleter: deleteClient,
+func New(scope FutureScope, createClient Creator, deleteClient Deleter, opts ...Option) *Service {
+	s := &Service{
+		Scope:        scope,
+		Creator:      createClient,
+		Deleter:      deleteClient,
+		operations:   trackedOperations,
+		requeueAfter: reconciler.DefaultReconcilerRequeue,
+		futureTTL:    defaultFutureTTL,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// NewWithPollers creates a new async service for clients of the track2 SDK, whose long-running operations are tracked
+// with the resume token of their runtime.Poller instead of a future.
+func NewWithPollers(scope FutureScope, createClient PollerCreator, deleteClient PollerDeleter, opts ...Option) *Service {
+	s := New(scope, nil, nil, opts...)
+	s.PollerCreator = createClient
+	s.PollerDeleter = deleteClient
+	return s
+}
+
+// ReconcileTimeout returns the timeout configured with WithReconcileTimeout, or zero if the default timeout is used.
+func (s *Service) ReconcileTimeout() time.Duration {
+	return s.reconcileTimeout
+}
+
+// HasOngoingOperation returns true if a valid long-running operation state is stored for the resource. It only reads
+// the stored state and never calls Azure. A stored future that cannot be decoded is migrated, as processOngoingOperation
+// does, and only reset if it cannot be migrated since it cannot be processed.
+func (s *Service) HasOngoingOperation(ctx context.Context, spec azure.ResourceSpecGetter, serviceName string) bool {
+	resourceName := spec.ResourceName()
+	future := s.getFuture(spec, resourceName, serviceName)
+	if future == nil {
+		return false
+	}
+	if err := s.decodeFuture(*future); err != nil {
+		if s.PollerCreator == nil && s.PollerDeleter == nil {
+			if _, _, err := s.migrateFuture(ctx, future, err); err == nil {
+				return true
+			}
+		}
+		s.deleteFuture(resourceName, future.ResourceGroup, serviceName)
+		return false
+	}
+	return true
+}
+
+// decodeFuture returns an error if the future cannot be decoded into a resume token, for clients of the track2 SDK,
+// or into an SDK future otherwise.
+func (s *Service) decodeFuture(future infrav1.Future) error {
+	if s.PollerCreator != nil || s.PollerDeleter != nil {
+		_, err := converters.FutureToResumeToken(future)
+		return err
+	}
+	_, err := converters.FutureToSDK(future)
+	return err
+}
+
+// getFuture returns the long-running operation state of the resource of the spec. Resources with the same name in
+// different resource groups are only told apart if the scope is a ResourceGroupFutureScope.
+func (s *Service) getFuture(spec azure.ResourceSpecGetter, resourceName, serviceName string) *infrav1.Future {
+	if scope, ok := s.Scope.(ResourceGroupFutureScope); ok {
+		return scope.GetLongRunningOperationStateInResourceGroup(resourceName, serviceName, spec.ResourceGroupName())
+	}
+	return s.Scope.GetLongRunningOperationState(resourceName, serviceName)
+}
+
+// deleteFuture deletes the long-running operation state of the resource in the given resource group. Resources with the
+// same name in different resource groups are only told apart if the scope is a ResourceGroupFutureScope.
+func (s *Service) deleteFuture(resourceName, rgName, serviceName string) {
+	if scope, ok := s.Scope.(ResourceGroupFutureScope); ok {
+		scope.DeleteLongRunningOperationStateInResourceGroup(resourceName, serviceName, rgName)
+		return
 	}
+	s.Scope.DeleteLongRunningOperationState(resourceName, serviceName)
 }
 
 // processOngoingOperation is a helper function that will process an ongoing operation to check if it is done.
-// If it is not done, it will return a transient error.
-func processOngoingOperation(ctx context.Context, scope FutureScope, client FutureHandler, resourceName string, serviceName string) (result interface{}, err error) {
+// If it is not done, it will return a transient error. The client is the FutureHandler, or the PollerHandler for
+// clients of the track2 SDK, that started the operation. If the operation cannot be checked on, e.g. because its
+// stored state is stale, the resource is got to find out whether the operation is done anyway, see refreshOperation.
+func (s *Service) processOngoingOperation(ctx context.Context, client interface{}, spec azure.ResourceSpecGetter, resourceName string, serviceName string) (result interface{}, err error) {
 	ctx, log, done := tele.StartSpanWithLogger(ctx, "async.Service.processOngoingOperation")
 	defer done()
 
-	future := scope.GetLongRunningOperationState(resourceName, serviceName)
+	future := s.getFuture(spec, resourceName, serviceName)
 	if future == nil {
 		log.V(2).Info("no long running operation found", "service", serviceName, "resource", resourceName)
 		return nil, nil
 	}
+	if s.isStale(future) {
+		// The operation may no longer be known to Azure, e.g. after restoring a backup or a long controller outage.
+		// Reset it like undecodable future data so that the operation is restarted instead of failing forever.
+		log.Info("WARNING: long running operation is older than the TTL, resetting long-running operation state", "service", serviceName, "resource", resourceName, "startTime", future.StartTime, "ttl", s.futureTTL)
+		s.deleteFuture(resourceName, future.ResourceGroup, serviceName)
+		s.operations.finish(future)
+		return nil, errors.Errorf("long running operation started at %s is older than %s, resetting long-running operation state", future.StartTime.UTC().Format(time.RFC3339), s.futureTTL)
+	}
+	if poller, ok := client.(PollerHandler); ok {
+		return s.resumePoller(ctx, poller, future, spec, resourceName, serviceName)
+	}
+	handler, ok := client.(FutureHandler)
+	if !ok {
+		return nil, errors.Errorf("client of service %s cannot check on the progress of long running operations", serviceName)
+	}
 	sdkFuture, err := converters.FutureToSDK(*future)
 	if err != nil {
-		// Reset the future data to avoid getting stuck in a bad loop.
-		// In theory, this should never happen, but if for some reason the future that is already stored in Status isn't properly formatted
-		// and we don't reset it we would be stuck in an infinite loop trying to parse it.
-		scope.DeleteLongRunningOperationState(resourceName, serviceName)
+		// The future may have been stored by a previous version using a different format, try to migrate it
+		// so that we keep track of the ongoing operation.
+		var migrated *infrav1.Future
+		sdkFuture, migrated, err = s.migrateFuture(ctx, future, err)
+		if err != nil {
+			if result, done := s.refreshOperation(ctx, spec, resourceName, future, nil); done {
+				return result, nil
+			}
+			// Reset the future data to avoid getting stuck in a bad loop.
+			// In theory, this should never happen, but if for some reason the future that is already stored in Status isn't properly formatted
+			// and we don't reset it we would be stuck in an infinite loop trying to parse it.
+			log.Info("WARNING: could not decode long running operation data, resetting long-running operation state", "service", serviceName, "resource", resourceName, "type", future.Type, "error", err.Error())
+			s.deleteFuture(resourceName, future.ResourceGroup, serviceName)
+			s.operations.finish(future)
+			return nil, errors.Wrap(err, "could not decode future data, resetting long-running operation state")
+		}
+		future = migrated
+	}
+
+	iterations := s.operations.poll(future)
+	logFutureState(log, future, sdkFuture, iterations)
+	isDone, err := handler.IsDone(ctx, sdkFuture)
+	if azure.IsAuthorizerError(err) {
+		return nil, s.authorizerFailed(future, err)
+	}
+	if azure.ResourceGroupNotFound(err) {
+		return nil, s.resourceGroupNotFound(ctx, log, future, resourceName, serviceName, iterations, err)
+	}
+	if err != nil {
+		// An operation that failed is never completed from its resource, e.g. a failed update leaves the resource
+		// provisioned successfully with its previous state.
+		if !operationFailed(sdkFuture.Status()) {
+			if result, done := s.refreshOperation(ctx, spec, resourceName, future, err); done {
+				return result, nil
+			}
+		}
+		err = errors.Wrap(withRequestID(err), "failed checking if the operation was complete")
+		if exhaustedErr := s.attemptsExhausted(future, iterations, err); exhaustedErr != nil {
+			return nil, exhaustedErr
+		}
+		return nil, s.requeueIfThrottled(err)
+	}
+
+	if !isDone {
+		// Operation is still in progress, update conditions and requeue.
+		log.V(2).Info("long running operation is still ongoing", "service", serviceName, "resource", resourceName, "status", sdkFuture.Status())
+		if exhaustedErr := s.attemptsExhausted(future, iterations, azure.NewOperationNotDoneError(future)); exhaustedErr != nil {
+			return nil, exhaustedErr
+		}
+		s.updateStatus(future, sdkFuture.Status())
+		return nil, azure.WithTransientError(azure.NewOperationNotDoneError(future), retryAfter(sdkFuture, s.jitter.apply(s.pollInterval(iterations))))
+	}
+
+	// Resource has been created/deleted/updated.
+	log.V(2).Info("long running operation has completed", "service", serviceName, "resource", resourceName)
+	result, err = handler.Result(ctx, sdkFuture, future.Type)
+	if azure.IsAuthorizerError(err) {
+		return nil, s.authorizerFailed(future, err)
+	}
+	if err != nil {
+		// The operation failed and is still tracked, so that the next attempts to complete it are counted.
+		if exhaustedErr := s.attemptsExhausted(future, iterations, err); exhaustedErr != nil {
+			return nil, exhaustedErr
+		}
+		return result, err
+	}
+	s.completeOperation(ctx, log, future, resourceName, serviceName, iterations, result)
+	s.recordPolledParameters(future, resourceName, serviceName, result)
+	return result, nil
+}
+
+// completeOperation forgets the completed operation tracked by the future of the resource: its completion is recorded
+// and its long-running operation state is deleted.
+func (s *Service) completeOperation(ctx context.Context, log logr.Logger, future *infrav1.Future, resourceName, serviceName string, iterations int, result interface{}) {
+	_, duration, started := s.operations.finish(future)
+	recordOperationCompletion(ctx, future, iterations, duration, started)
+	s.recordCompletedOperation(log, future, iterations, duration, started)
+	s.deleteFuture(resourceName, future.ResourceGroup, serviceName)
+	s.recordEvent(corev1.EventTypeNormal, "OperationCompleted", "%s operation on resource %s/%s (service: %s) completed", future.Type, future.ResourceGroup, resourceName, serviceName)
+}
+
+// logFutureState logs the decoded state of a long-running operation each time it is checked on, so that operations
+// that never complete can be debugged.
+func logFutureState(log logr.Logger, future *infrav1.Future, sdkFuture azureautorest.FutureAPI, iterations int) {
+	kvs := []interface{}{
+		"service", future.ServiceName, "resource", future.Name, "resourceGroup", future.ResourceGroup, "type", future.Type,
+		"pollingMethod", sdkFuture.PollingMethod(), "lroState", sdkFuture.Status(), "iterations", iterations,
+	}
+	if future.StartTime != nil {
+		kvs = append(kvs, "elapsed", time.Since(future.StartTime.Time).Round(time.Second).String())
+	}
+	log.V(4).Info("checking on long running operation", kvs...)
+}
+
+// attemptsExhausted gives up on the operation if it has been checked on maxAttempts times without completing: the
+// long-running operation state is reset and a terminal error with the last error is returned so that the object is
+// not requeued and its condition is marked as failed. It returns nil if the operation can be checked on again.
+func (s *Service) attemptsExhausted(future *infrav1.Future, attempts int, lastErr error) error {
+	if s.maxAttempts <= 0 || attempts < s.maxAttempts {
+		return nil
+	}
+	s.deleteFuture(future.Name, future.ResourceGroup, future.ServiceName)
+	s.operations.finish(future)
+	// The last error is not wrapped so that the terminal error is never mistaken for an operation that is not done.
+	return s.operationFailed(azure.WithTerminalError(errors.Errorf("%s operation on resource %s/%s (service: %s) did not complete after %d attempts, resetting long-running operation state: %s",
+		future.Type, future.ResourceGroup, future.Name, future.ServiceName, attempts, lastErr.Error())))
+}
+
+// authorizerFailed returns a transient error requeueing the operation tracked by the future, which could not be checked
+// on because the authorizer failed to get a token. The request was never sent to Azure, so the poll is not counted
+// towards the maximum number of attempts and the long-running operation state is left untouched.
+func (s *Service) authorizerFailed(future *infrav1.Future, err error) error {
+	s.operations.unpoll(future)
+	return azure.WithTransientError(errors.Wrapf(err, "failed to authorize checking on %s operation on resource %s/%s (service: %s)", future.Type, future.ResourceGroup, future.Name, future.ServiceName), s.jitter.apply(s.requeueAfter))
+}
+
+// resourceGroupNotFound handles the operation tracked by the future when checking on it failed with err because the
+// resource group of the resource was deleted out of band, which would otherwise fail the same way forever. A delete
+// operation is complete since the resource is gone with its resource group. A create or update operation is reset like
+// a stale operation, so that it is restarted once the resource group exists again.
+func (s *Service) resourceGroupNotFound(ctx context.Context, log logr.Logger, future *infrav1.Future, resourceName, serviceName string, iterations int, err error) error {
+	if future.Type == infrav1.DeleteFuture {
+		log.Info("resource group of the resource was deleted, completing delete operation", "service", serviceName, "resource", resourceName, "resourceGroup", future.ResourceGroup)
+		s.completeOperation(ctx, log, future, resourceName, serviceName, iterations, nil)
+		return nil
+	}
+	log.Info("WARNING: resource group of the resource was deleted, resetting long-running operation state", "service", serviceName, "resource", resourceName, "resourceGroup", future.ResourceGroup, "type", future.Type)
+	s.deleteFuture(resourceName, future.ResourceGroup, serviceName)
+	s.operations.finish(future)
+	return errors.Wrapf(withRequestID(err), "resource group %s of resource %s was not found, resetting long-running operation state", future.ResourceGroup, resourceName)
+}
+
+// updateStatus stores the last known state of the operation in the future so that it is visible in the status of the
+// object. The state is informational only, so the future is only stored again if the state changed.
+func (s *Service) updateStatus(future *infrav1.Future, status string) {
+	if status == "" || status == future.Status {
+		return
+	}
+	updated := future.DeepCopy()
+	updated.Status = status
+	s.Scope.SetLongRunningOperationState(updated)
+}
+
+// resumePoller checks if the operation tracked by the resume token of a track2 SDK poller is done. If it is not done,
+// it will return a transient error.
+func (s *Service) resumePoller(ctx context.Context, client PollerHandler, future *infrav1.Future, spec azure.ResourceSpecGetter, resourceName string, serviceName string) (result interface{}, err error) {
+	ctx, log, done := tele.StartSpanWithLogger(ctx, "async.Service.resumePoller")
+	defer done()
+
+	resumeToken, err := converters.FutureToResumeToken(*future)
+	if err != nil {
+		if result, done := s.refreshOperation(ctx, spec, resourceName, future, nil); done {
+			return result, nil
+		}
+		// Reset the future data to avoid getting stuck in a bad loop, like for undecodable track1 futures.
+		s.deleteFuture(resourceName, future.ResourceGroup, serviceName)
+		s.operations.finish(future)
 		return nil, errors.Wrap(err, "could not decode future data, resetting long-running operation state")
 	}
 
-	isDone, err := client.IsDone(ctx, sdkFuture)
+	iterations := s.operations.poll(future)
+	isDone, result, err := client.ResumePoller(ctx, *future, resumeToken)
+	if azure.IsAuthorizerError(err) {
+		return nil, s.authorizerFailed(future, err)
+	}
+	if azure.ResourceGroupNotFound(err) {
+		return nil, s.resourceGroupNotFound(ctx, log, future, resourceName, serviceName, iterations, err)
+	}
 	if err != nil {
-		return nil, errors.Wrap(err, "failed checking if the operation was complete")
+		// The poller reports the failure of the operation itself as done, see PollerHandler.
+		if !isDone {
+			if result, done := s.refreshOperation(ctx, spec, resourceName, future, err); done {
+				return result, nil
+			}
+		}
+		err = errors.Wrap(err, "failed to resume long running operation")
+		if exhaustedErr := s.attemptsExhausted(future, iterations, err); exhaustedErr != nil {
+			return nil, exhaustedErr
+		}
+		return nil, s.requeueIfThrottled(err)
 	}
 
 	if !isDone {
 		// Operation is still in progress, update conditions and requeue.
 		log.V(2).Info("long running operation is still ongoing", "service", serviceName, "resource", resourceName)
-		return nil, azure.WithTransientError(azure.NewOperationNotDoneError(future), retryAfter(sdkFuture))
+		if exhaustedErr := s.attemptsExhausted(future, iterations, azure.NewOperationNotDoneError(future)); exhaustedErr != nil {
+			return nil, exhaustedErr
+		}
+		return nil, azure.WithTransientError(azure.NewOperationNotDoneError(future), s.jitter.apply(s.pollInterval(iterations)))
 	}
 
 	// Resource has been created/deleted/updated.
 	log.V(2).Info("long running operation has completed", "service", serviceName, "resource", resourceName)
-	result, err = client.Result(ctx, sdkFuture, future.Type)
-	if err == nil {
-		scope.DeleteLongRunningOperationState(resourceName, serviceName)
+	s.completeOperation(ctx, log, future, resourceName, serviceName, iterations, result)
+	s.recordPolledParameters(future, resourceName, serviceName, result)
+	return result, nil
+}
+
+// isStale returns true if the future was started longer than the TTL ago. Futures without a start time, e.g. stored by
+// a previous version, are never stale.
+func (s *Service) isStale(future *infrav1.Future) bool {
+	if s.futureTTL <= 0 || future.StartTime == nil {
+		return false
 	}
-	return result, err
+	return time.Since(future.StartTime.Time) > s.futureTTL
 }
 
 // CreateResource implements the logic for creating a resource Asynchronously.
-func (s *Service) CreateResource(ctx context.Context, spec azure.ResourceSpecGetter, serviceName string) (result interface{}, err error) {
+// It returns true if a create or update request was sent to Azure, whether or not the operation completed, and false
+// if the resource was up to date or an ongoing operation was checked on. The resource of an azure.AdoptOnlySpecGetter
+// that does not exist is not created, a terminal azure.ResourceMustExistError is returned instead.
+func (s *Service) CreateResource(ctx context.Context, spec azure.ResourceSpecGetter, serviceName string) (result interface{}, changed bool, err error) {
+	ctx = s.withCorrID(ctx)
 	ctx, log, done := tele.StartSpanWithLogger(ctx, "async.Service.CreateResource")
 	defer done()
 
 	resourceName := spec.ResourceName()
 	rgName := spec.ResourceGroupName()
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(resourceAttributes(serviceName, rgName, resourceName)...)
+	defer func() { setRequeuedAttribute(span, err) }()
+	// operationType is set once an operation is started or tracked, so that the post-operation hook is called when it
+	// completes.
+	var operationType string
+	defer func() {
+		if operationType != "" {
+			evict(ctx, spec, serviceName)
+		}
+		s.runPostOpHook(ctx, spec, serviceName, operationType, result, err)
+	}()
+
+	if s.Scope.IsServicePaused(serviceName) {
+		// The ongoing operation, if any, is left untouched so that it is tracked again once the service is resumed.
+		log.Info("service is paused, skipping create or update of resource", "service", serviceName, "resource", resourceName, "resourceGroup", rgName)
+		return nil, false, nil
+	}
 
 	// Check if there is an ongoing long running operation.
-	future := s.Scope.GetLongRunningOperationState(resourceName, serviceName)
+	future := s.getFuture(spec, resourceName, serviceName)
 	if future != nil {
-		return processOngoingOperation(ctx, s.Scope, s.Creator, resourceName, serviceName)
+		restart, err := s.specChanged(ctx, spec, future, serviceName)
+		if err != nil {
+			return nil, false, err
+		}
+		if restart {
+			log.Info("desired parameters changed while a long running operation was in progress, abandoning the operation", "service", serviceName, "resource", resourceName, "resourceGroup", rgName, "type", future.Type)
+			s.deleteFuture(resourceName, rgName, serviceName)
+			s.operations.finish(future)
+			future = nil
+		}
+	}
+	if future != nil {
+		operationType = future.Type
+		var client interface{} = s.Creator
+		if s.PollerCreator != nil {
+			client = s.PollerCreator
+		}
+		result, err := s.processOngoingOperation(ctx, client, spec, resourceName, serviceName)
+		if err != nil {
+			return result, false, err
+		}
+		result, err = s.transformResult(spec, result, serviceName)
+		return result, false, err
 	}
 
 	// Get the resource if it already exists, and use it to construct the desired resource parameters.
 	var existingResource interface{}
-	if existing, err := s.Creator.Get(ctx, spec); err != nil && !azure.ResourceNotFound(err) {
-		return nil, errors.Wrapf(err, "failed to get existing resource %s/%s (service: %s)", rgName, resourceName, serviceName)
+	if existing, err := s.get(ctx, spec, serviceName); err != nil && !azure.ResourceNotFound(err) {
+		return nil, false, s.requeueIfThrottled(errors.Wrapf(withRequestID(err), "failed to get existing resource %s/%s (service: %s)", rgName, resourceName, serviceName))
 	} else if err == nil {
 		existingResource = existing
 		log.V(2).Info("successfully got existing resource", "service", serviceName, "resource", resourceName, "resourceGroup", rgName)
+	} else if adoptOnly(spec) {
+		// Creating the resource from scratch would not give the resource the user was expected to provide.
+		return nil, false, azure.WithTerminalError(azure.ResourceMustExistError{ResourceGroup: rgName, ResourceName: resourceName, ServiceName: serviceName})
+	} else if s.notFoundGracePeriod > 0 && s.operations.createdWithin(serviceName, rgName, resourceName, s.notFoundGracePeriod) {
+		// The resource was just created by another reconcile, creating it again would race two PUTs.
+		log.V(2).Info("resource not found but being created, waiting for it to be found", "service", serviceName, "resource", resourceName, "resourceGroup", rgName)
+		future := &infrav1.Future{Type: infrav1.PutFuture, ServiceName: serviceName, Name: resourceName, ResourceGroup: rgName}
+		return nil, false, azure.WithTransientError(azure.NewOperationNotDoneError(future), s.jitter.apply(s.requeueAfter))
 	}
 
 	// Construct parameters using the resource spec and information from the existing resource, if there is one.
 	parameters, err := spec.Parameters(existingResource)
 	if err != nil {
-		return nil, errors.Wrapf(err, "failed to get desired parameters for resource %s/%s (service: %s)", rgName, resourceName, serviceName)
+		return nil, false, errors.Wrapf(withRequestID(err), "failed to get desired parameters for resource %s/%s (service: %s)", rgName, resourceName, serviceName)
 	} else if parameters == nil {
 		// Nothing to do, don't create or update the resource and return the existing resource.
 		log.V(2).Info("resource up to date", "service", serviceName, "resource", resourceName, "resourceGroup", rgName)
-		return existingResource, nil
+		result, err = s.transformResult(spec, existingResource, serviceName)
+		return result, false, err
+	}
+
+	if s.parametersApplied(ctx, existingResource, parameters, resourceName, rgName, serviceName) {
+		log.V(2).Info("resource unchanged since its parameters were applied", "service", serviceName, "resource", resourceName, "resourceGroup", rgName)
+		result, err = s.transformResult(spec, existingResource, serviceName)
+		return result, false, err
+	}
+
+	// Update the existing resource with a PATCH if both the spec and the client support it, otherwise replace it.
+	futureType := infrav1.PutFuture
+	updater, canPatch := s.Creator.(Updater)
+	if patchSpec, ok := spec.(azure.PatchSpecGetter); ok && canPatch && existingResource != nil && patchSpec.UsePatch() {
+		futureType = infrav1.PatchFuture
+	}
+
+	if s.dryRun {
+		log.Info("dry run: skipping create or update of resource", "service", serviceName, "resource", resourceName, "resourceGroup", rgName)
+		return &Change{
+			ServiceName:   serviceName,
+			ResourceGroup: rgName,
+			ResourceName:  resourceName,
+			Type:          futureType,
+			Existing:      existingResource,
+			Parameters:    parameters,
+		}, false, nil
+	}
+
+	if existingResource == nil {
+		if err := s.checkQuota(ctx, spec, resourceName, rgName, serviceName); err != nil {
+			return nil, false, err
+		}
 	}
 
 	// Create or update the resource with the desired parameters.
+	release, ok := s.acquireOperationSlot()
+	if !ok {
+		return nil, false, azure.WithTransientError(errors.Errorf("waiting for an operation slot to create resource %s/%s (service: %s)", rgName, resourceName, serviceName), s.jitter.apply(s.requeueAfter))
+	}
+	defer release()
+	releaseRequest, ok := s.acquireRequest()
+	if !ok {
+		return nil, false, azure.WithTransientError(azure.ConcurrencyLimitedError{Type: futureType, ResourceGroup: rgName, ResourceName: resourceName, ServiceName: serviceName}, s.jitter.apply(s.requeueAfter))
+	}
+	defer releaseRequest()
+	if delay := s.rateLimitDelay(); delay > 0 {
+		return nil, false, azure.WithTransientError(errors.Errorf("rate limited, waiting to create resource %s/%s (service: %s)", rgName, resourceName, serviceName), s.jitter.apply(delay))
+	}
+	if err := s.runPreOpHook(ctx, spec, resourceName, rgName, serviceName, futureType); err != nil {
+		return nil, false, err
+	}
+	operationType = futureType
+	defer func() { s.recordRequest(err) }()
 	log.V(2).Info("creating resource", "service", serviceName, "resource", resourceName, "resourceGroup", rgName)
-	result, sdkFuture, err := s.Creator.CreateOrUpdateAsync(ctx, spec, parameters)
+	if s.PollerCreator != nil {
+		result, err := s.beginCreateOrUpdate(ctx, spec, parameters, resourceName, rgName, serviceName)
+		if s.pollAfterCreate && azure.IsOperationNotDoneError(err) {
+			return s.pollCreatedOperation(ctx, spec, s.PollerCreator, serviceName)
+		}
+		if err != nil {
+			return result, true, err
+		}
+		result, err = s.transformResult(spec, result, serviceName)
+		return result, true, err
+	}
+	var sdkFuture azureautorest.FutureAPI
+	if futureType == infrav1.PatchFuture {
+		result, sdkFuture, err = updater.UpdateAsync(ctx, spec, parameters)
+	} else {
+		result, sdkFuture, err = s.Creator.CreateOrUpdateAsync(ctx, spec, parameters)
+	}
 	if sdkFuture != nil {
-		future, err := converters.SDKToFuture(sdkFuture, infrav1.PutFuture, serviceName, resourceName, rgName)
+		future, err := converters.SDKToFuture(sdkFuture, futureType, serviceName, resourceName, rgName)
 		if err != nil {
-			return nil, errors.Wrapf(err, "failed to create resource %s/%s (service: %s)", rgName, resourceName, serviceName)
+			return nil, true, errors.Wrapf(err, "failed to create resource %s/%s (service: %s)", rgName, resourceName, serviceName)
 		}
+		s.setParametersHash(future, parameters)
 		s.Scope.SetLongRunningOperationState(future)
-		return nil, azure.WithTransientError(azure.NewOperationNotDoneError(future), retryAfter(sdkFuture))
+		s.operations.start(future)
+		recordOperationTimeout(ctx, future)
+		if s.pollAfterCreate {
+			return s.pollCreatedOperation(ctx, spec, s.Creator, serviceName)
+		}
+		return nil, true, azure.WithTransientError(azure.NewOperationNotDoneError(future), retryAfter(sdkFuture, s.jitter.apply(s.requeueAfter)))
 	} else if err != nil {
-		return nil, errors.Wrapf(err, "failed to create resource %s/%s (service: %s)", rgName, resourceName, serviceName)
+		return nil, true, s.operationFailed(s.classifyFailure(errors.Wrapf(s.resolveFailure(ctx, withRequestID(err)), "failed to create resource %s/%s (service: %s)", rgName, resourceName, serviceName)))
 	}
 
 	log.V(2).Info("successfully created resource", "service", serviceName, "resource", resourceName, "resourceGroup", rgName)
-	return result, nil
+	s.recordAppliedParameters(resourceName, rgName, serviceName, parametersHash(parameters), result)
+	result, err = s.transformResult(spec, result, serviceName)
+	return result, true, err
+}
+
+// pollCreatedOperation checks once on the create or update operation just started for the resource, with the client
+// that started it. The result is the one of CreateResource: the resource if the operation is done, or else an error
+// requeueing the resource until it is.
+func (s *Service) pollCreatedOperation(ctx context.Context, spec azure.ResourceSpecGetter, client interface{}, serviceName string) (result interface{}, changed bool, err error) {
+	result, err = s.processOngoingOperation(ctx, client, spec, spec.ResourceName(), serviceName)
+	if err != nil {
+		return result, true, err
+	}
+	result, err = s.transformResult(spec, result, serviceName)
+	return result, true, err
+}
+
+// specChanged returns true if the desired parameters of the resource no longer match the parameters the ongoing
+// operation tracked by the future was started with. It returns false if the service does not restart operations on
+// spec changes or the future has no parameters hash.
+func (s *Service) specChanged(ctx context.Context, spec azure.ResourceSpecGetter, future *infrav1.Future, serviceName string) (bool, error) {
+	if !s.restartOnSpecChange || s.dryRun || future.ParametersHash == "" {
+		return false, nil
+	}
+	resourceName := spec.ResourceName()
+	rgName := spec.ResourceGroupName()
+
+	var existingResource interface{}
+	if existing, err := s.get(ctx, spec, serviceName); err != nil && !azure.ResourceNotFound(err) {
+		return false, s.requeueIfThrottled(errors.Wrapf(withRequestID(err), "failed to get existing resource %s/%s (service: %s)", rgName, resourceName, serviceName))
+	} else if err == nil {
+		existingResource = existing
+	}
+	parameters, err := spec.Parameters(existingResource)
+	if err != nil {
+		return false, errors.Wrapf(withRequestID(err), "failed to get desired parameters for resource %s/%s (service: %s)", rgName, resourceName, serviceName)
+	}
+	// There are no parameters when the resource already matches the spec, e.g. because Azure reports the resource
+	// with the parameters of the ongoing operation.
+	hash := parametersHash(parameters)
+	return parameters != nil && hash != "" && hash != future.ParametersHash, nil
+}
+
+// setParametersHash stores the hash of the parameters an operation was started with in its future, if the service
+// restarts operations on spec changes or records the parameters applied to resources.
+func (s *Service) setParametersHash(future *infrav1.Future, parameters interface{}) {
+	if s.restartOnSpecChange || s.appliedParametersGuard {
+		future.ParametersHash = parametersHash(parameters)
+	}
+}
+
+// parametersHash returns a base64 url encoded sha256 hash of the parameters of an operation, or an empty string if
+// they cannot be serialized.
+func parametersHash(parameters interface{}) string {
+	data, err := json.Marshal(parameters)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return base64.URLEncoding.EncodeToString(sum[:])
 }
 
-// DeleteResource implements the logic for deleting a resource Asynchronously.
-func (s *Service) DeleteResource(ctx context.Context, spec azure.ResourceSpecGetter, serviceName string) (err error) {
+// adoptOnly returns true if the spec is an AdoptOnlySpecGetter whose resource must not be created when it is missing.
+func adoptOnly(spec azure.ResourceSpecGetter) bool {
+	adoptOnlySpec, ok := spec.(azure.AdoptOnlySpecGetter)
+	return ok && adoptOnlySpec.AdoptOnly()
+}
+
+// transformResult maps the result of a completed operation on the resource of the spec if the spec is a
+// ResultTransformer, so that services get the same value whether the operation completed right away or not.
+func (s *Service) transformResult(spec azure.ResourceSpecGetter, result interface{}, serviceName string) (interface{}, error) {
+	transformer, ok := spec.(azure.ResultTransformer)
+	if !ok || result == nil {
+		return result, nil
+	}
+	transformed, err := transformer.TransformResult(result)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to transform result of resource %s/%s (service: %s)", spec.ResourceGroupName(), spec.ResourceName(), serviceName)
+	}
+	return transformed, nil
+}
+
+// DeleteResource implements the logic for deleting a resource Asynchronously. It returns true if the resource was
+// already deleted, i.e. Azure did not find it, so that services can tell it from a resource they deleted.
+func (s *Service) DeleteResource(ctx context.Context, spec azure.ResourceSpecGetter, serviceName string) (notFound bool, err error) {
+	ctx = s.withCorrID(ctx)
 	ctx, log, done := tele.StartSpanWithLogger(ctx, "async.Service.DeleteResource")
 	defer done()
 
 	resourceName := spec.ResourceName()
 	rgName := spec.ResourceGroupName()
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(resourceAttributes(serviceName, rgName, resourceName)...)
+	defer func() { setRequeuedAttribute(span, err) }()
+	var operationType string
+	defer func() {
+		if operationType != "" {
+			evict(ctx, spec, serviceName)
+		}
+		s.runPostOpHook(ctx, spec, serviceName, operationType, nil, err)
+	}()
+
+	if s.Scope.IsServicePaused(serviceName) {
+		log.Info("service is paused, skipping delete of resource", "service", serviceName, "resource", resourceName, "resourceGroup", rgName)
+		return false, nil
+	}
 
 	// Check if there is an ongoing long running operation.
-	future := s.Scope.GetLongRunningOperationState(resourceName, serviceName)
+	future := s.getFuture(spec, resourceName, serviceName)
 	if future != nil {
-		_, err := processOngoingOperation(ctx, s.Scope, s.Deleter, resourceName, serviceName)
-		return err
+		operationType = future.Type
+		if s.PollerDeleter != nil {
+			_, err := s.processOngoingOperation(ctx, s.PollerDeleter, spec, resourceName, serviceName)
+			return false, err
+		}
+		_, err := s.processOngoingOperation(ctx, s.Deleter, spec, resourceName, serviceName)
+		return false, err
+	}
+
+	if s.dryRun {
+		log.Info("dry run: skipping delete of resource", "service", serviceName, "resource", resourceName, "resourceGroup", rgName)
+		return false, nil
+	}
+
+	if s.checkExistenceBeforeDelete {
+		if s.getter() != nil {
+			if _, err := s.get(ctx, spec, serviceName); azure.ResourceNotFound(err) {
+				log.V(2).Info("resource was already deleted, skipping delete", "service", serviceName, "resource", resourceName, "resourceGroup", rgName)
+				return true, nil
+			} else if err != nil {
+				return false, s.requeueIfThrottled(errors.Wrapf(withRequestID(err), "failed to get existing resource %s/%s (service: %s)", rgName, resourceName, serviceName))
+			}
+		}
 	}
 
 	// No long running operation is active, so delete the resource.
+	release, ok := s.acquireOperationSlot()
+	if !ok {
+		return false, azure.WithTransientError(errors.Errorf("waiting for an operation slot to delete resource %s/%s (service: %s)", rgName, resourceName, serviceName), s.jitter.apply(s.requeueAfter))
+	}
+	defer release()
+	releaseRequest, ok := s.acquireRequest()
+	if !ok {
+		return false, azure.WithTransientError(azure.ConcurrencyLimitedError{Type: infrav1.DeleteFuture, ResourceGroup: rgName, ResourceName: resourceName, ServiceName: serviceName}, s.jitter.apply(s.requeueAfter))
+	}
+	defer releaseRequest()
+	if delay := s.rateLimitDelay(); delay > 0 {
+		return false, azure.WithTransientError(errors.Errorf("rate limited, waiting to delete resource %s/%s (service: %s)", rgName, resourceName, serviceName), s.jitter.apply(delay))
+	}
+	if err := s.runPreOpHook(ctx, spec, resourceName, rgName, serviceName, infrav1.DeleteFuture); err != nil {
+		return false, err
+	}
+	operationType = infrav1.DeleteFuture
+	defer func() { s.recordRequest(err) }()
 	log.V(2).Info("deleting resource", "service", serviceName, "resource", resourceName, "resourceGroup", rgName)
+	defer func() {
+		// A delete that was never sent to Azure leaves the resource as it was.
+		if !azure.IsAuthorizerError(err) {
+			s.forgetAppliedParameters(resourceName, rgName, serviceName)
+		}
+	}()
+	if s.PollerDeleter != nil {
+		return s.beginDelete(ctx, spec, resourceName, rgName, serviceName)
+	}
 	sdkFuture, err := s.Deleter.DeleteAsync(ctx, spec)
+	if sdkFuture != nil && errors.Is(ctx.Err(), context.Canceled) && s.cancelOperation(ctx, sdkFuture) {
+		// The reconcile was cancelled, e.g. because the controller is shutting down, and so was the operation.
+		return false, errors.Errorf("delete of resource %s/%s (service: %s) was cancelled", rgName, resourceName, serviceName)
+	}
 	if sdkFuture != nil {
 		future, err := converters.SDKToFuture(sdkFuture, infrav1.DeleteFuture, serviceName, resourceName, rgName)
 		if err != nil {
-			return errors.Wrapf(err, "failed to delete resource %s/%s (service: %s)", rgName, resourceName, serviceName)
+			return false, errors.Wrapf(err, "failed to delete resource %s/%s (service: %s)", rgName, resourceName, serviceName)
 		}
 		s.Scope.SetLongRunningOperationState(future)
-		return azure.WithTransientError(azure.NewOperationNotDoneError(future), retryAfter(sdkFuture))
+		s.operations.start(future)
+		recordOperationTimeout(ctx, future)
+		return false, azure.WithTransientError(azure.NewOperationNotDoneError(future), retryAfter(sdkFuture, s.jitter.apply(s.requeueAfter)))
 	} else if err != nil {
 		if azure.ResourceNotFound(err) {
 			// already deleted
-			return nil
+			log.V(2).Info("resource was already deleted", "service", serviceName, "resource", resourceName, "resourceGroup", rgName)
+			return true, nil
 		}
-		return errors.Wrapf(err, "failed to delete resource %s/%s (service: %s)", rgName, resourceName, serviceName)
+		return false, s.operationFailed(s.classifyFailure(errors.Wrapf(s.resolveFailure(ctx, withRequestID(err)), "failed to delete resource %s/%s (service: %s)", rgName, resourceName, serviceName)))
+	}
+
+	log.V(2).Info("successfully deleted resource", "service", serviceName, "resource", resourceName, "resourceGroup", rgName)
+	return false, nil
+}
+
+// WaitForOperation blocks until the long running operation of the resource of the spec completes, e.g. for tests or
+// command line tools that cannot requeue. It checks on the operation like the controllers do, waiting between checks
+// as long as they would be requeued, and returns the result of the completed operation. It returns an error if the
+// operation failed or ctx is done before the operation completed; nil is returned if there is no ongoing operation.
+func (s *Service) WaitForOperation(ctx context.Context, spec azure.ResourceSpecGetter, serviceName string) (result interface{}, err error) {
+	ctx = s.withCorrID(ctx)
+	ctx, log, done := tele.StartSpanWithLogger(ctx, "async.Service.WaitForOperation")
+	defer done()
+
+	resourceName := spec.ResourceName()
+	for polls := 1; ; polls++ {
+		future := s.getFuture(spec, resourceName, serviceName)
+		if future == nil {
+			return nil, nil
+		}
+		var client interface{}
+		if future.Type == infrav1.DeleteFuture {
+			client = s.Deleter
+			if s.PollerDeleter != nil {
+				client = s.PollerDeleter
+			}
+		} else {
+			client = s.Creator
+			if s.PollerCreator != nil {
+				client = s.PollerCreator
+			}
+		}
+
+		result, err = s.processOngoingOperation(ctx, client, spec, resourceName, serviceName)
+		if !azure.IsOperationNotDoneError(err) {
+			evict(ctx, spec, serviceName)
+		}
+		if err == nil {
+			if future.Type == infrav1.DeleteFuture {
+				return result, nil
+			}
+			return s.transformResult(spec, result, serviceName)
+		}
+		if !azure.IsOperationNotDoneError(err) {
+			return result, err
+		}
+
+		wait := s.pollInterval(polls)
+		var reconcileErr azure.ReconcileError
+		if errors.As(err, &reconcileErr) && reconcileErr.RequeueAfter() > 0 {
+			wait = reconcileErr.RequeueAfter()
+		}
+		log.V(4).Info("waiting for long running operation to complete", "service", serviceName, "resource", resourceName, "wait", wait)
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, errors.Wrapf(ctx.Err(), "waiting for operation on resource %s/%s (service: %s) to complete", spec.ResourceGroupName(), resourceName, serviceName)
+		case <-timer.C:
+		}
+	}
+}
+
+// withCorrID returns ctx with the correlation ID to send to Azure, the configured one or else the one of ctx. The
+// correlation ID is recorded on the span of the caller, e.g. securitygroups.Service.Reconcile, so that it is visible in
+// its traces along with the spans of the requests.
+func (s *Service) withCorrID(ctx context.Context) context.Context {
+	corrID := s.corrID
+	if corrID == "" {
+		id, ok := tele.CorrIDFromCtx(ctx)
+		if !ok {
+			// A new correlation ID is created with the span of the request.
+			return ctx
+		}
+		corrID = id
+	}
+	return tele.WithCorrID(ctx, corrID)
+}
+
+// beginCreateOrUpdate creates or updates the resource with the track2 SDK client and stores the resume token of its
+// poller if the operation did not complete right away.
+func (s *Service) beginCreateOrUpdate(ctx context.Context, spec azure.ResourceSpecGetter, parameters interface{}, resourceName, rgName, serviceName string) (result interface{}, err error) {
+	ctx, log, done := tele.StartSpanWithLogger(ctx, "async.Service.beginCreateOrUpdate")
+	defer done()
+
+	result, resumeToken, err := s.PollerCreator.BeginCreateOrUpdate(ctx, spec, parameters)
+	if err != nil {
+		return nil, s.operationFailed(s.classifyFailure(errors.Wrapf(s.resolveFailure(ctx, withRequestID(err)), "failed to create resource %s/%s (service: %s)", rgName, resourceName, serviceName)))
+	}
+	if resumeToken != "" {
+		future, err := converters.PollerToFuture(resumeToken, infrav1.PutFuture, serviceName, resourceName, rgName)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create resource %s/%s (service: %s)", rgName, resourceName, serviceName)
+		}
+		s.setParametersHash(future, parameters)
+		s.Scope.SetLongRunningOperationState(future)
+		s.operations.start(future)
+		recordOperationTimeout(ctx, future)
+		return nil, azure.WithTransientError(azure.NewOperationNotDoneError(future), s.jitter.apply(s.requeueAfter))
+	}
+
+	log.V(2).Info("successfully created resource", "service", serviceName, "resource", resourceName, "resourceGroup", rgName)
+	s.recordAppliedParameters(resourceName, rgName, serviceName, parametersHash(parameters), result)
+	return result, nil
+}
+
+// beginDelete deletes the resource with the track2 SDK client and stores the resume token of its poller if the
+// operation did not complete right away. It returns true if the resource was already deleted.
+func (s *Service) beginDelete(ctx context.Context, spec azure.ResourceSpecGetter, resourceName, rgName, serviceName string) (notFound bool, err error) {
+	ctx, log, done := tele.StartSpanWithLogger(ctx, "async.Service.beginDelete")
+	defer done()
+
+	resumeToken, err := s.PollerDeleter.BeginDelete(ctx, spec)
+	if err != nil {
+		if azure.ResourceNotFound(err) {
+			// already deleted
+			log.V(2).Info("resource was already deleted", "service", serviceName, "resource", resourceName, "resourceGroup", rgName)
+			return true, nil
+		}
+		return false, s.operationFailed(s.classifyFailure(errors.Wrapf(s.resolveFailure(ctx, withRequestID(err)), "failed to delete resource %s/%s (service: %s)", rgName, resourceName, serviceName)))
+	}
+	if resumeToken != "" {
+		future, err := converters.PollerToFuture(resumeToken, infrav1.DeleteFuture, serviceName, resourceName, rgName)
+		if err != nil {
+			return false, errors.Wrapf(err, "failed to delete resource %s/%s (service: %s)", rgName, resourceName, serviceName)
+		}
+		s.Scope.SetLongRunningOperationState(future)
+		s.operations.start(future)
+		recordOperationTimeout(ctx, future)
+		return false, azure.WithTransientError(azure.NewOperationNotDoneError(future), s.jitter.apply(s.requeueAfter))
 	}
 
 	log.V(2).Info("successfully deleted resource", "service", serviceName, "resource", resourceName, "resourceGroup", rgName)
-	return nil
+	return false, nil
+}
+
+// getter returns the client used to get resources, the track2 SDK client if the service uses one.
+func (s *Service) getter() Getter {
+	if s.PollerCreator != nil {
+		return s.PollerCreator
+	}
+	return s.Creator
+}
+
+// GetResource gets the current state of a resource without creating or updating it.
+// It returns an error wrapping ErrResourceNotFound if the resource does not exist.
+func (s *Service) GetResource(ctx context.Context, spec azure.ResourceSpecGetter, serviceName string) (result interface{}, err error) {
+	ctx = s.withCorrID(ctx)
+	ctx, log, done := tele.StartSpanWithLogger(ctx, "async.Service.GetResource")
+	defer done()
+
+	resourceName := spec.ResourceName()
+	rgName := spec.ResourceGroupName()
+
+	result, err = s.get(ctx, spec, serviceName)
+	if azure.ResourceNotFound(err) {
+		return nil, errors.Wrapf(ErrResourceNotFound, "resource %s/%s (service: %s)", rgName, resourceName, serviceName)
+	} else if err != nil {
+		return nil, s.requeueIfThrottled(errors.Wrapf(withRequestID(err), "failed to get resource %s/%s (service: %s)", rgName, resourceName, serviceName))
+	}
+
+	log.V(2).Info("successfully got resource", "service", serviceName, "resource", resourceName, "resourceGroup", rgName)
+	return result, nil
+}
+
+// ProvisioningState returns the provisioning state of a resource returned by the service, e.g. by CreateResource, if
+// its client is a ProvisioningStateGetter. It returns false if the provisioning state is unknown.
+func (s *Service) ProvisioningState(resource interface{}) (state string, ok bool) {
+	getter, ok := s.getter().(ProvisioningStateGetter)
+	if !ok || resource == nil {
+		return "", false
+	}
+	return getter.ProvisioningState(resource)
+}
+
+// cancelOperation cancels the operation tracked by the future if the Deleter supports it and returns true if it was
+// cancelled. The given context is already cancelled, so the operation is cancelled using a new one.
+func (s *Service) cancelOperation(ctx context.Context, sdkFuture azureautorest.FutureAPI) bool {
+	_, log, done := tele.StartSpanWithLogger(ctx, "async.Service.cancelOperation")
+	defer done()
+
+	canceler, ok := s.Deleter.(Canceler)
+	if !ok {
+		return false
+	}
+	cancelCtx, cancel := context.WithTimeout(context.Background(), reconciler.DefaultAzureCallTimeout)
+	defer cancel()
+	if err := canceler.Cancel(cancelCtx, sdkFuture); err != nil {
+		log.V(2).Info("failed to cancel operation, keeping track of it", "error", err.Error())
+		return false
+	}
+	return true
+}
+
+// migrateFuture attempts to convert a future that could not be decoded into the current format using the configured
+// FutureMigrator. On success, the migrated future replaces the stored one and is returned with its decoded SDK future.
+// If no migrator is configured or the migration fails, the original decode error is returned.
+func (s *Service) migrateFuture(ctx context.Context, future *infrav1.Future, decodeErr error) (azureautorest.FutureAPI, *infrav1.Future, error) {
+	_, log, done := tele.StartSpanWithLogger(ctx, "async.Service.migrateFuture")
+	defer done()
+
+	if s.migrator == nil {
+		return nil, nil, decodeErr
+	}
+	migrated, err := s.migrator.MigrateFuture(*future)
+	if err != nil {
+		log.V(2).Info("failed to migrate future data", "service", future.ServiceName, "resource", future.Name, "error", err.Error())
+		return nil, nil, decodeErr
+	}
+	sdkFuture, err := converters.FutureToSDK(*migrated)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to decode migrated future data")
+	}
+	log.V(2).Info("successfully migrated future data", "service", future.ServiceName, "resource", future.Name)
+	s.Scope.SetLongRunningOperationState(migrated)
+	return sdkFuture, migrated, nil
+}
+
+// resolveFailure adds the reason found in the Activity Log for the operation started with the correlation ID of ctx to
+// the error, if a failure resolver is configured. The error is returned unchanged if the reason cannot be resolved.
+func (s *Service) resolveFailure(ctx context.Context, err error) error {
+	if s.resolver == nil {
+		return err
+	}
+	if _, throttled := azure.ThrottledRetryAfter(err, 0); throttled {
+		// Throttled requests are retried, looking them up would only add to the throttling.
+		return err
+	}
+	if azure.IsAuthorizerError(err) {
+		// The request was never sent to Azure, so there is nothing to look up.
+		return err
+	}
+	ctx, log, done := tele.StartSpanWithLogger(ctx, "async.Service.resolveFailure")
+	defer done()
+
+	corrID, ok := tele.CorrIDFromCtx(ctx)
+	if !ok {
+		return err
+	}
+	reason, resolveErr := s.resolver.ResolveFailure(ctx, string(corrID))
+	if resolveErr != nil {
+		log.V(2).Info("failed to resolve operation failure from the activity log", "correlationID", corrID, "error", resolveErr.Error())
+		return err
+	}
+	if reason == "" {
+		return err
+	}
+	return errors.Wrapf(err, "activity log: %s", reason)
+}
+
+// withRequestID adds the ID Azure assigned to the failed request to the error, if there is one, so that the failure can
+// be looked up in the Azure Activity Log.
+func withRequestID(err error) error {
+	if id := azure.RequestID(err); id != "" {
+		return errors.Wrapf(err, "request ID %s", id)
+	}
+	return err
+}
+
+// requeueIfThrottled turns the error into a transient error if it was caused by Azure throttling requests, so that the
+// request is retried after the delay requested by the Retry-After header instead of failing. Requests the authorizer
+// failed to get a token for were never sent to Azure, they are retried after the default delay.
+func (s *Service) requeueIfThrottled(err error) error {
+	if delay, ok := azure.ThrottledRetryAfter(err, s.requeueAfter); ok {
+		if s.rateLimiter != nil {
+			// The requests of all the services sharing the rate limiter are backed off, not just this one.
+			delay = s.rateLimiter.Throttled(delay)
+		}
+		return azure.WithTransientError(err, delay)
+	}
+	if azure.IsAuthorizerError(err) {
+		return azure.WithTransientError(err, s.jitter.apply(s.requeueAfter))
+	}
+	return err
+}
+
+// classifyFailure turns the error of a failed create or delete request into a transient error if the request was
+// throttled, or into a terminal error if retrying the request will never succeed, e.g. because of an invalid spec or
+// missing permissions, so that the object is not requeued forever.
+func (s *Service) classifyFailure(err error) error {
+	err = s.requeueIfThrottled(err)
+	var reconcileErr azure.ReconcileError
+	if !errors.As(err, &reconcileErr) && azure.IsTerminalError(err) {
+		return azure.WithTerminalError(err)
+	}
+	return err
+}
+
+// operationFailed records a warning event for an operation that failed with a terminal error and returns the error.
+// Transient errors are retried, so no event is recorded for them.
+func (s *Service) operationFailed(err error) error {
+	var reconcileErr azure.ReconcileError
+	if errors.As(err, &reconcileErr) && reconcileErr.IsTransient() {
+		return err
+	}
+	s.recordEvent(corev1.EventTypeWarning, "OperationFailed", "%s", err.Error())
+	return err
+}
+
+// recordEvent records an event on the event object, if an event recorder is configured.
+func (s *Service) recordEvent(eventType, reason, messageFmt string, args ...interface{}) {
+	if s.recorder == nil {
+		return
+	}
+	s.recorder.Eventf(s.eventObject, eventType, reason, messageFmt, args...)
+}
+
+// acquireOperationSlot takes a slot from the fair scheduler, if one is configured, before an operation is started.
+// It returns false if no slot is available. The returned function releases the slot.
+func (s *Service) acquireOperationSlot() (release func(), ok bool) {
+	if s.scheduler == nil {
+		return func() {}, true
+	}
+	if !s.scheduler.TryAcquire(s.owner) {
+		return nil, false
+	}
+	return s.scheduler.Release, true
+}
+
+// acquireRequest acquires the weight of the service from the concurrency limiter, if one is configured, before a create
+// or delete request is made. It returns false if the requests in flight leave no room for the request. The returned
+// function releases the weight.
+func (s *Service) acquireRequest() (release func(), ok bool) {
+	if s.concurrencyLimiter == nil {
+		return func() {}, true
+	}
+	if !s.concurrencyLimiter.TryAcquire(s.concurrencyWeight) {
+		return nil, false
+	}
+	return func() { s.concurrencyLimiter.Release(s.concurrencyWeight) }, true
+}
+
+// rateLimitDelay takes a token from the rate limiter, if one is configured, before a create or delete request is made.
+// It returns how long to wait before trying again, or zero if the request may be made now.
+func (s *Service) rateLimitDelay() time.Duration {
+	if s.rateLimiter == nil {
+		return 0
+	}
+	return s.rateLimiter.Reserve()
+}
+
+// recordRequest resets the backoff of the rate limiter, if one is configured, once a request was sent to Azure and not
+// throttled. Throttled requests are recorded by requeueIfThrottled.
+func (s *Service) recordRequest(err error) {
+	if s.rateLimiter != nil && !azure.IsThrottled(err) && !azure.IsAuthorizerError(err) {
+		s.rateLimiter.Succeeded()
+	}
+}
+
+// CoalesceSpecs removes specs that refer to the same Azure resource as an earlier spec in the list, so that a single
+// operation is tracked per resource. It returns the remaining specs in their original order along with the duplicates
+// that were dropped. Azure resource names are case-insensitive, so specs are compared case-insensitively.
+func CoalesceSpecs(specs []azure.ResourceSpecGetter) (unique []azure.ResourceSpecGetter, duplicates []azure.ResourceSpecGetter) {
+	seen := make(map[string]bool, len(specs))
+	for _, spec := range specs {
+		key := resourceKey(spec)
+		if seen[key] {
+			duplicates = append(duplicates, spec)
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, spec)
+	}
+	return unique, duplicates
+}
+
+// resourceKey returns a string uniquely identifying the Azure resource a spec refers to. The subscription is only known
+// for SubscriptionSpecGetters, the resources of the other specs are in the subscription of the cluster.
+func resourceKey(spec azure.ResourceSpecGetter) string {
+	var subscriptionID string
+	if subscriptionSpec, ok := spec.(azure.SubscriptionSpecGetter); ok {
+		subscriptionID = subscriptionSpec.SubscriptionID()
+	}
+	return strings.ToLower(strings.Join([]string{subscriptionID, spec.ResourceGroupName(), spec.OwnerResourceName(), spec.ResourceName()}, "/"))
+}
+
+// pollInterval returns the requeue interval after an operation has been polled the given number of times.
+func (s *Service) pollInterval(polls int) time.Duration {
+	if s.maxRequeueAfter <= s.requeueAfter {
+		return s.requeueAfter
+	}
+	interval := s.requeueAfter
+	for i := 1; i < polls; i++ {
+		interval *= 2
+		if interval >= s.maxRequeueAfter {
+			return s.maxRequeueAfter
+		}
+	}
+	return interval
 }
 
-// retryAfter returns the max between the `RETRY-AFTER` header and the default requeue time.
+// retryAfter returns the max between the `RETRY-AFTER` header and the requeue time of the service.
 // This ensures we respect the retry-after header if it is set and avoid retrying too often during an API throttling event.
-func retryAfter(sdkFuture azureautorest.FutureAPI) time.Duration {
+func retryAfter(sdkFuture azureautorest.FutureAPI, requeueAfter time.Duration) time.Duration {
 	retryAfter, _ := sdkFuture.GetPollingDelay()
-	if retryAfter < reconciler.DefaultReconcilerRequeue {
-		retryAfter = reconciler.DefaultReconcilerRequeue
+	if retryAfter < requeueAfter {
+		retryAfter = requeueAfter
 	}
 	return retryAfter
 }
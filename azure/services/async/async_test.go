@@ -20,17 +20,26 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2019-05-01/resources"
 	"github.com/Azure/go-autorest/autorest"
 	azureautorest "github.com/Azure/go-autorest/autorest/azure"
+	"github.com/go-logr/logr/funcr"
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/mock_azure"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/async/mock_async"
 	gomockinternal "sigs.k8s.io/cluster-api-provider-azure/internal/test/matchers/gomock"
+	"sigs.k8s.io/cluster-api-provider-azure/util/reconciler"
+	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
+	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
 var (
@@ -40,6 +49,7 @@ var (
 		Name:          "test-resource",
 		ResourceGroup: "test-group",
 		Data:          "eyJtZXRob2QiOiJQVVQiLCJwb2xsaW5nTWV0aG9kIjoiTG9jYXRpb24iLCJscm9TdGF0ZSI6IkluUHJvZ3Jlc3MifQ==",
+		Status:        "InProgress",
 	}
 	validDeleteFuture = infrav1.Future{
 		Type:          infrav1.DeleteFuture,
@@ -47,6 +57,7 @@ var (
 		Name:          "test-resource",
 		ResourceGroup: "test-group",
 		Data:          "eyJtZXRob2QiOiJERUxFVEUiLCJwb2xsaW5nTWV0aG9kIjoiTG9jYXRpb24iLCJscm9TdGF0ZSI6IkluUHJvZ3Jlc3MifQ==",
+		Status:        "InProgress",
 	}
 	invalidFuture = infrav1.Future{
 		Type:          infrav1.DeleteFuture,
@@ -59,11 +70,36 @@ var (
 	fakeResourceParameters = resources.GenericResource{}
 	fakeInternalError      = autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 500}, "Internal Server Error")
 	fakeNotFoundError      = autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 404}, "Not Found")
-	errCtxExceeded         = errors.New("ctx exceeded")
+	// fakeResourceGroupNotFoundError is the error polling an operation on a resource whose resource group was deleted.
+	fakeResourceGroupNotFoundError = autorest.DetailedError{
+		Original:   &azureautorest.ServiceError{Code: "ResourceGroupNotFound", Message: "Resource group 'test-group' could not be found."},
+		StatusCode: 404,
+	}
+	errCtxExceeded = errors.New("ctx exceeded")
 )
 
+// testSpec returns a spec of the resource in the test-group resource group.
+func testSpec(name string) azure.ResourceSpecGetter {
+	return &dependentSpec{name: name}
+}
+
+// newMockFutureScope returns a mock FutureScope whose services are not paused.
+func newMockFutureScope(mockCtrl *gomock.Controller) *mock_async.MockFutureScope {
+	scopeMock := mock_async.NewMockFutureScope(mockCtrl)
+	scopeMock.EXPECT().IsServicePaused(gomock.Any()).Return(false).AnyTimes()
+	return scopeMock
+}
+
 // TestProcessOngoingOperation tests the processOngoingOperation function.
 func TestProcessOngoingOperation(t *testing.T) {
+	staleDeleteFuture := validDeleteFuture
+	staleDeleteFuture.StartTime = &metav1.Time{Time: time.Now().Add(-3 * time.Hour)}
+	recentDeleteFuture := validDeleteFuture
+	recentDeleteFuture.StartTime = &metav1.Time{Time: time.Now().Add(-time.Hour)}
+	// Futures stored by a previous version have no status.
+	noStatusDeleteFuture := validDeleteFuture
+	noStatusDeleteFuture.Status = ""
+
 	testcases := []struct {
 		name           string
 		resourceName   string
@@ -91,6 +127,26 @@ func TestProcessOngoingOperation(t *testing.T) {
 				s.DeleteLongRunningOperationState("test-resource", "test-service")
 			},
 		},
+		{
+			name:          "future is older than the TTL",
+			expectedError: "is older than 2h0m0s, resetting long-running operation state",
+			resourceName:  "test-resource",
+			serviceName:   "test-service",
+			expect: func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockFutureHandlerMockRecorder) {
+				s.GetLongRunningOperationState("test-resource", "test-service").Return(&staleDeleteFuture)
+				s.DeleteLongRunningOperationState("test-resource", "test-service")
+			},
+		},
+		{
+			name:          "future is not older than the TTL",
+			expectedError: "operation type DELETE on Azure resource test-group/test-resource is not done",
+			resourceName:  "test-resource",
+			serviceName:   "test-service",
+			expect: func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockFutureHandlerMockRecorder) {
+				s.GetLongRunningOperationState("test-resource", "test-service").Return(&recentDeleteFuture)
+				c.IsDone(gomockinternal.AContext(), gomock.AssignableToTypeOf(&azureautorest.Future{})).Return(false, nil)
+			},
+		},
 		{
 			name:          "fail to check if ongoing operation is done",
 			expectedError: "failed checking if the operation was complete",
@@ -101,6 +157,28 @@ func TestProcessOngoingOperation(t *testing.T) {
 				c.IsDone(gomockinternal.AContext(), gomock.AssignableToTypeOf(&azureautorest.Future{})).Return(false, fakeInternalError)
 			},
 		},
+		{
+			name:          "resource group of an ongoing delete operation was deleted",
+			expectedError: "",
+			resourceName:  "test-resource",
+			serviceName:   "test-service",
+			expect: func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockFutureHandlerMockRecorder) {
+				s.GetLongRunningOperationState("test-resource", "test-service").Return(&validDeleteFuture)
+				c.IsDone(gomockinternal.AContext(), gomock.AssignableToTypeOf(&azureautorest.Future{})).Return(false, fakeResourceGroupNotFoundError)
+				s.DeleteLongRunningOperationState("test-resource", "test-service")
+			},
+		},
+		{
+			name:          "resource group of an ongoing create operation was deleted",
+			expectedError: "resource group test-group of resource test-resource was not found, resetting long-running operation state",
+			resourceName:  "test-resource",
+			serviceName:   "test-service",
+			expect: func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockFutureHandlerMockRecorder) {
+				s.GetLongRunningOperationState("test-resource", "test-service").Return(&validCreateFuture)
+				c.IsDone(gomockinternal.AContext(), gomock.AssignableToTypeOf(&azureautorest.Future{})).Return(false, fakeResourceGroupNotFoundError)
+				s.DeleteLongRunningOperationState("test-resource", "test-service")
+			},
+		},
 		{
 			name:          "ongoing operation is not done",
 			expectedError: "operation type DELETE on Azure resource test-group/test-resource is not done",
@@ -111,6 +189,17 @@ func TestProcessOngoingOperation(t *testing.T) {
 				c.IsDone(gomockinternal.AContext(), gomock.AssignableToTypeOf(&azureautorest.Future{})).Return(false, nil)
 			},
 		},
+		{
+			name:          "ongoing operation is not done and its status is updated",
+			expectedError: "operation type DELETE on Azure resource test-group/test-resource is not done",
+			resourceName:  "test-resource",
+			serviceName:   "test-service",
+			expect: func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockFutureHandlerMockRecorder) {
+				s.GetLongRunningOperationState("test-resource", "test-service").Return(&noStatusDeleteFuture)
+				c.IsDone(gomockinternal.AContext(), gomock.AssignableToTypeOf(&azureautorest.Future{})).Return(false, nil)
+				s.SetLongRunningOperationState(&validDeleteFuture)
+			},
+		},
 		{
 			name:           "operation is done",
 			expectedError:  "",
@@ -134,12 +223,13 @@ func TestProcessOngoingOperation(t *testing.T) {
 			t.Parallel()
 			mockCtrl := gomock.NewController(t)
 			defer mockCtrl.Finish()
-			scopeMock := mock_async.NewMockFutureScope(mockCtrl)
+			scopeMock := newMockFutureScope(mockCtrl)
 			clientMock := mock_async.NewMockFutureHandler(mockCtrl)
 
 			tc.expect(scopeMock.EXPECT(), clientMock.EXPECT())
 
-			result, err := processOngoingOperation(context.TODO(), scopeMock, clientMock, tc.resourceName, tc.serviceName)
+			s := New(scopeMock, nil, nil)
+			result, err := s.processOngoingOperation(context.TODO(), clientMock, testSpec(tc.resourceName), tc.resourceName, tc.serviceName)
 			if tc.expectedError != "" {
 				g.Expect(err).To(HaveOccurred())
 				g.Expect(err.Error()).To(ContainSubstring(tc.expectedError))
@@ -155,6 +245,144 @@ func TestProcessOngoingOperation(t *testing.T) {
 	}
 }
 
+// TestProcessOngoingOperationLogsFutureState tests that the decoded state of a future is logged each time it is
+// checked on, and that undecodable futures are still reset.
+func TestProcessOngoingOperationLogsFutureState(t *testing.T) {
+	startedDeleteFuture := validDeleteFuture
+	startedDeleteFuture.StartTime = &metav1.Time{Time: time.Now().Add(-time.Hour)}
+
+	testcases := []struct {
+		name          string
+		expectedError string
+		expectedLogs  []string
+		resetLogged   bool
+		expect        func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockFutureHandlerMockRecorder)
+	}{
+		{
+			name:          "future is decoded",
+			expectedError: "operation type DELETE on Azure resource test-group/test-resource is not done",
+			expectedLogs:  []string{`"msg"="checking on long running operation"`, `"pollingMethod"="Location"`, `"lroState"="InProgress"`, `"iterations"=1`, `"elapsed"="1h0m0s"`},
+			expect: func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockFutureHandlerMockRecorder) {
+				s.GetLongRunningOperationState("test-resource", "test-service").Return(&startedDeleteFuture)
+				c.IsDone(gomockinternal.AContext(), gomock.AssignableToTypeOf(&azureautorest.Future{})).Return(false, nil)
+			},
+		},
+		{
+			name:          "future data is not valid",
+			expectedError: "could not decode future data, resetting long-running operation state",
+			resetLogged:   true,
+			expect: func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockFutureHandlerMockRecorder) {
+				s.GetLongRunningOperationState("test-resource", "test-service").Return(&invalidFuture)
+				s.DeleteLongRunningOperationState("test-resource", "test-service")
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			scopeMock := newMockFutureScope(mockCtrl)
+			clientMock := mock_async.NewMockFutureHandler(mockCtrl)
+
+			tc.expect(scopeMock.EXPECT(), clientMock.EXPECT())
+
+			var logs []string
+			logger := funcr.New(func(prefix, args string) {
+				logs = append(logs, args)
+			}, funcr.Options{Verbosity: 4})
+			ctx := log.IntoContext(context.TODO(), logger)
+
+			s := New(scopeMock, nil, nil)
+			s.operations = newOperationTracker()
+			_, err := s.processOngoingOperation(ctx, clientMock, testSpec("test-resource"), "test-resource", "test-service")
+			g.Expect(err).To(HaveOccurred())
+			g.Expect(err.Error()).To(ContainSubstring(tc.expectedError))
+
+			stateLogs := []string{}
+			resetLogged := false
+			for _, line := range logs {
+				if strings.Contains(line, "checking on long running operation") {
+					stateLogs = append(stateLogs, line)
+				}
+				resetLogged = resetLogged || strings.Contains(line, "resetting long-running operation state")
+			}
+			g.Expect(resetLogged).To(Equal(tc.resetLogged))
+			if len(tc.expectedLogs) == 0 {
+				g.Expect(stateLogs).To(BeEmpty())
+				return
+			}
+			g.Expect(stateLogs).To(HaveLen(1))
+			for _, expected := range tc.expectedLogs {
+				g.Expect(stateLogs[0]).To(ContainSubstring(expected))
+			}
+		})
+	}
+}
+
+// TestProcessOngoingOperationMigration tests that processOngoingOperation migrates futures stored in an incompatible format.
+func TestProcessOngoingOperationMigration(t *testing.T) {
+	testcases := []struct {
+		name          string
+		expectedError string
+		expect        func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockFutureHandlerMockRecorder, m *mock_async.MockFutureMigratorMockRecorder)
+	}{
+		{
+			name:          "future data is migrated and the operation is still ongoing",
+			expectedError: "operation type DELETE on Azure resource test-group/test-resource is not done",
+			expect: func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockFutureHandlerMockRecorder, m *mock_async.MockFutureMigratorMockRecorder) {
+				s.GetLongRunningOperationState("test-resource", "test-service").Return(&invalidFuture)
+				m.MigrateFuture(invalidFuture).Return(&validDeleteFuture, nil)
+				s.SetLongRunningOperationState(&validDeleteFuture)
+				c.IsDone(gomockinternal.AContext(), gomock.AssignableToTypeOf(&azureautorest.Future{})).Return(false, nil)
+			},
+		},
+		{
+			name:          "future data cannot be migrated",
+			expectedError: "could not decode future data, resetting long-running operation state",
+			expect: func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockFutureHandlerMockRecorder, m *mock_async.MockFutureMigratorMockRecorder) {
+				s.GetLongRunningOperationState("test-resource", "test-service").Return(&invalidFuture)
+				m.MigrateFuture(invalidFuture).Return(nil, errors.New("unknown future format"))
+				s.DeleteLongRunningOperationState("test-resource", "test-service")
+			},
+		},
+		{
+			name:          "migrated future data is still not valid",
+			expectedError: "failed to decode migrated future data",
+			expect: func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockFutureHandlerMockRecorder, m *mock_async.MockFutureMigratorMockRecorder) {
+				s.GetLongRunningOperationState("test-resource", "test-service").Return(&invalidFuture)
+				m.MigrateFuture(invalidFuture).Return(&invalidFuture, nil)
+				s.DeleteLongRunningOperationState("test-resource", "test-service")
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			scopeMock := newMockFutureScope(mockCtrl)
+			clientMock := mock_async.NewMockFutureHandler(mockCtrl)
+			migratorMock := mock_async.NewMockFutureMigrator(mockCtrl)
+
+			tc.expect(scopeMock.EXPECT(), clientMock.EXPECT(), migratorMock.EXPECT())
+
+			s := New(scopeMock, nil, nil, WithFutureMigrator(migratorMock))
+			result, err := s.processOngoingOperation(context.TODO(), clientMock, testSpec("test-resource"), "test-resource", "test-service")
+			g.Expect(err).To(HaveOccurred())
+			g.Expect(err.Error()).To(ContainSubstring(tc.expectedError))
+			g.Expect(result).To(BeNil())
+		})
+	}
+}
+
 // TestCreateResource tests the CreateResource function.
 func TestCreateResource(t *testing.T) {
 	testcases := []struct {
@@ -162,7 +390,9 @@ func TestCreateResource(t *testing.T) {
 		serviceName    string
 		expectedError  string
 		expectedResult interface{}
-		expect         func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockCreatorMockRecorder, r *mock_azure.MockResourceSpecGetterMockRecorder)
+		// expectedChanged is true if a create or update request is sent.
+		expectedChanged bool
+		expect          func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockCreatorMockRecorder, r *mock_azure.MockResourceSpecGetterMockRecorder)
 	}{
 		{
 			name:          "create operation is already in progress",
@@ -176,10 +406,11 @@ func TestCreateResource(t *testing.T) {
 			},
 		},
 		{
-			name:           "create async returns success",
-			expectedError:  "",
-			expectedResult: "test-resource",
-			serviceName:    "test-service",
+			name:            "create async returns success",
+			expectedChanged: true,
+			expectedError:   "",
+			expectedResult:  "test-resource",
+			serviceName:     "test-service",
 			expect: func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockCreatorMockRecorder, r *mock_azure.MockResourceSpecGetterMockRecorder) {
 				r.ResourceName().Return("test-resource")
 				r.ResourceGroupName().Return("test-group")
@@ -201,10 +432,11 @@ func TestCreateResource(t *testing.T) {
 			},
 		},
 		{
-			name:           "async get returns not found",
-			expectedError:  "",
-			serviceName:    "test-service",
-			expectedResult: &fakeExistingResource,
+			name:            "async get returns not found",
+			expectedChanged: true,
+			expectedError:   "",
+			serviceName:     "test-service",
+			expectedResult:  &fakeExistingResource,
 			expect: func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockCreatorMockRecorder, r *mock_azure.MockResourceSpecGetterMockRecorder) {
 				r.ResourceName().Return("test-resource")
 				r.ResourceGroupName().Return("test-group")
@@ -240,9 +472,10 @@ func TestCreateResource(t *testing.T) {
 			},
 		},
 		{
-			name:          "error occurs while running async create",
-			expectedError: "failed to create resource test-group/test-resource (service: test-service)",
-			serviceName:   "test-service",
+			name:            "error occurs while running async create",
+			expectedChanged: true,
+			expectedError:   "failed to create resource test-group/test-resource (service: test-service)",
+			serviceName:     "test-service",
 			expect: func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockCreatorMockRecorder, r *mock_azure.MockResourceSpecGetterMockRecorder) {
 				r.ResourceName().Return("test-resource")
 				r.ResourceGroupName().Return("test-group")
@@ -253,9 +486,10 @@ func TestCreateResource(t *testing.T) {
 			},
 		},
 		{
-			name:          "create async exits before completing",
-			expectedError: "operation type PUT on Azure resource test-group/test-resource is not done. Object will be requeued after 15s",
-			serviceName:   "test-service",
+			name:            "create async exits before completing",
+			expectedChanged: true,
+			expectedError:   "operation type PUT on Azure resource test-group/test-resource is not done. Object will be requeued after 15s",
+			serviceName:     "test-service",
 			expect: func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockCreatorMockRecorder, r *mock_azure.MockResourceSpecGetterMockRecorder) {
 				r.ResourceName().Return("test-resource")
 				r.ResourceGroupName().Return("test-group")
@@ -276,14 +510,15 @@ func TestCreateResource(t *testing.T) {
 			t.Parallel()
 			mockCtrl := gomock.NewController(t)
 			defer mockCtrl.Finish()
-			scopeMock := mock_async.NewMockFutureScope(mockCtrl)
+			scopeMock := newMockFutureScope(mockCtrl)
 			creatorMock := mock_async.NewMockCreator(mockCtrl)
 			specMock := mock_azure.NewMockResourceSpecGetter(mockCtrl)
 
 			tc.expect(scopeMock.EXPECT(), creatorMock.EXPECT(), specMock.EXPECT())
 
 			s := New(scopeMock, creatorMock, nil)
-			result, err := s.CreateResource(context.TODO(), specMock, tc.serviceName)
+			result, changed, err := s.CreateResource(context.TODO(), specMock, tc.serviceName)
+			g.Expect(changed).To(Equal(tc.expectedChanged))
 			if tc.expectedError != "" {
 				g.Expect(err).To(HaveOccurred())
 				g.Expect(err.Error()).To(ContainSubstring(tc.expectedError))
@@ -298,10 +533,11 @@ func TestCreateResource(t *testing.T) {
 // TestDeleteResource tests the DeleteResource function.
 func TestDeleteResource(t *testing.T) {
 	testcases := []struct {
-		name          string
-		serviceName   string
-		expectedError string
-		expect        func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockDeleterMockRecorder, r *mock_azure.MockResourceSpecGetterMockRecorder)
+		name             string
+		serviceName      string
+		expectedError    string
+		expectedNotFound bool
+		expect           func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockDeleterMockRecorder, r *mock_azure.MockResourceSpecGetterMockRecorder)
 	}{
 		{
 			name:          "delete operation is already in progress",
@@ -326,9 +562,10 @@ func TestDeleteResource(t *testing.T) {
 			},
 		},
 		{
-			name:          "delete async returns not found",
-			expectedError: "",
-			serviceName:   "test-service",
+			name:             "delete async returns not found",
+			expectedError:    "",
+			expectedNotFound: true,
+			serviceName:      "test-service",
 			expect: func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockDeleterMockRecorder, r *mock_azure.MockResourceSpecGetterMockRecorder) {
 				r.ResourceName().Return("test-resource")
 				r.ResourceGroupName().Return("test-group")
@@ -369,20 +606,1864 @@ func TestDeleteResource(t *testing.T) {
 			t.Parallel()
 			mockCtrl := gomock.NewController(t)
 			defer mockCtrl.Finish()
-			scopeMock := mock_async.NewMockFutureScope(mockCtrl)
+			scopeMock := newMockFutureScope(mockCtrl)
 			deleterMock := mock_async.NewMockDeleter(mockCtrl)
 			specMock := mock_azure.NewMockResourceSpecGetter(mockCtrl)
 
 			tc.expect(scopeMock.EXPECT(), deleterMock.EXPECT(), specMock.EXPECT())
 
 			s := New(scopeMock, nil, deleterMock)
-			err := s.DeleteResource(context.TODO(), specMock, tc.serviceName)
+			notFound, err := s.DeleteResource(context.TODO(), specMock, tc.serviceName)
 			if tc.expectedError != "" {
 				g.Expect(err).To(HaveOccurred())
 				g.Expect(err.Error()).To(ContainSubstring(tc.expectedError))
 			} else {
 				g.Expect(err).NotTo(HaveOccurred())
 			}
+			g.Expect(notFound).To(Equal(tc.expectedNotFound))
+		})
+	}
+}
+
+// TestCoalesceSpecs tests the CoalesceSpecs function.
+func TestCoalesceSpecs(t *testing.T) {
+	g := NewWithT(t)
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	newSpec := func(rg, name string) *mock_azure.MockResourceSpecGetter {
+		spec := mock_azure.NewMockResourceSpecGetter(mockCtrl)
+		spec.EXPECT().ResourceGroupName().Return(rg).AnyTimes()
+		spec.EXPECT().OwnerResourceName().Return("").AnyTimes()
+		spec.EXPECT().ResourceName().Return(name).AnyTimes()
+		return spec
+	}
+	first := newSpec("test-group", "test-resource")
+	equivalent := newSpec("Test-Group", "TEST-resource")
+	otherGroup := newSpec("other-group", "test-resource")
+	other := newSpec("test-group", "other-resource")
+
+	unique, duplicates := CoalesceSpecs([]azure.ResourceSpecGetter{first, equivalent, otherGroup, other})
+	g.Expect(unique).To(Equal([]azure.ResourceSpecGetter{first, otherGroup, other}))
+	g.Expect(duplicates).To(Equal([]azure.ResourceSpecGetter{equivalent}))
+}
+
+// subscriptionSpec is a ResourceSpecGetter whose resource is in the given subscription.
+type subscriptionSpec struct {
+	resourceGroupSpec
+	subscriptionID string
+}
+
+func (s *subscriptionSpec) SubscriptionID() string { return s.subscriptionID }
+
+// TestCoalesceSpecsSubscriptions tests that CoalesceSpecs keeps the specs of resources with the same name in different
+// subscriptions, and only drops the duplicates within the same subscription.
+func TestCoalesceSpecsSubscriptions(t *testing.T) {
+	g := NewWithT(t)
+
+	first := &subscriptionSpec{resourceGroupSpec: resourceGroupSpec{name: "test-resource", resourceGroup: "test-group"}, subscriptionID: "sub-1"}
+	otherSubscription := &subscriptionSpec{resourceGroupSpec: resourceGroupSpec{name: "test-resource", resourceGroup: "test-group"}, subscriptionID: "sub-2"}
+	equivalent := &subscriptionSpec{resourceGroupSpec: resourceGroupSpec{name: "TEST-resource", resourceGroup: "test-group"}, subscriptionID: "SUB-2"}
+	clusterSubscription := &resourceGroupSpec{name: "test-resource", resourceGroup: "test-group"}
+
+	unique, duplicates := CoalesceSpecs([]azure.ResourceSpecGetter{first, otherSubscription, equivalent, clusterSubscription})
+	g.Expect(unique).To(Equal([]azure.ResourceSpecGetter{first, otherSubscription, clusterSubscription}))
+	g.Expect(duplicates).To(Equal([]azure.ResourceSpecGetter{equivalent}))
+}
+
+// TestCreateResourceWaitsForOperationSlot tests that CreateResource requeues when the fair scheduler has no slot available.
+func TestCreateResourceWaitsForOperationSlot(t *testing.T) {
+	g := NewWithT(t)
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	scopeMock := newMockFutureScope(mockCtrl)
+	creatorMock := mock_async.NewMockCreator(mockCtrl)
+	specMock := mock_azure.NewMockResourceSpecGetter(mockCtrl)
+
+	scheduler := NewFairScheduler(1)
+	g.Expect(scheduler.TryAcquire("other-cluster")).To(BeTrue())
+
+	specMock.EXPECT().ResourceName().Return("test-resource")
+	specMock.EXPECT().ResourceGroupName().Return("test-group")
+	scopeMock.EXPECT().GetLongRunningOperationState("test-resource", "test-service").Return(nil)
+	creatorMock.EXPECT().Get(gomockinternal.AContext(), specMock).Return(&fakeExistingResource, nil)
+	specMock.EXPECT().Parameters(&fakeExistingResource).Return(&fakeResourceParameters, nil)
+
+	s := New(scopeMock, creatorMock, nil, WithFairScheduler(scheduler, "test-cluster"))
+	_, _, err := s.CreateResource(context.TODO(), specMock, "test-service")
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(ContainSubstring("waiting for an operation slot to create resource test-group/test-resource (service: test-service)"))
+
+	// Once the slot is released, the operation is started.
+	scheduler.Release()
+	specMock.EXPECT().ResourceName().Return("test-resource")
+	specMock.EXPECT().ResourceGroupName().Return("test-group")
+	scopeMock.EXPECT().GetLongRunningOperationState("test-resource", "test-service").Return(nil)
+	creatorMock.EXPECT().Get(gomockinternal.AContext(), specMock).Return(&fakeExistingResource, nil)
+	specMock.EXPECT().Parameters(&fakeExistingResource).Return(&fakeResourceParameters, nil)
+	creatorMock.EXPECT().CreateOrUpdateAsync(gomockinternal.AContext(), specMock, &fakeResourceParameters).Return("test-resource", nil, nil)
+	result, _, err := s.CreateResource(context.TODO(), specMock, "test-service")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result).To(Equal("test-resource"))
+}
+
+// TestCreateResourceWaitsForRateLimiter tests that CreateResource requeues when the shared rate limiter is backed off.
+func TestCreateResourceWaitsForRateLimiter(t *testing.T) {
+	g := NewWithT(t)
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	scopeMock := newMockFutureScope(mockCtrl)
+	creatorMock := mock_async.NewMockCreator(mockCtrl)
+	specMock := mock_azure.NewMockResourceSpecGetter(mockCtrl)
+
+	// Another service sharing the rate limiter was throttled.
+	limiter := NewRateLimiter(0, 0, time.Minute)
+	limiter.Throttled(30 * time.Second)
+
+	specMock.EXPECT().ResourceName().Return("test-resource")
+	specMock.EXPECT().ResourceGroupName().Return("test-group")
+	scopeMock.EXPECT().GetLongRunningOperationState("test-resource", "test-service").Return(nil)
+	creatorMock.EXPECT().Get(gomockinternal.AContext(), specMock).Return(&fakeExistingResource, nil)
+	specMock.EXPECT().Parameters(&fakeExistingResource).Return(&fakeResourceParameters, nil)
+
+	s := New(scopeMock, creatorMock, nil, WithRateLimiter(limiter))
+	_, _, err := s.CreateResource(context.TODO(), specMock, "test-service")
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(ContainSubstring("rate limited, waiting to create resource test-group/test-resource (service: test-service)"))
+	var reconcileErr azure.ReconcileError
+	g.Expect(errors.As(err, &reconcileErr)).To(BeTrue())
+	g.Expect(reconcileErr.IsTransient()).To(BeTrue())
+	g.Expect(reconcileErr.RequeueAfter()).To(BeNumerically("~", 30*time.Second, time.Second))
+}
+
+// TestCreateResourceResolvesFailure tests that CreateResource adds the reason found in the activity log to the error of a failed operation.
+func TestCreateResourceResolvesFailure(t *testing.T) {
+	testcases := []struct {
+		name          string
+		expect        func(r *mock_async.MockFailureResolverMockRecorder)
+		expectedError string
+	}{
+		{
+			name: "reason is found in the activity log",
+			expect: func(r *mock_async.MockFailureResolverMockRecorder) {
+				r.ResolveFailure(gomockinternal.AContext(), "test-correlation-id").Return("Microsoft.Resources/resources/write was denied by Azure Policy: not allowed", nil)
+			},
+			expectedError: "failed to create resource test-group/test-resource (service: test-service): activity log: Microsoft.Resources/resources/write was denied by Azure Policy: not allowed: #: Internal Server Error: StatusCode=500",
+		},
+		{
+			name: "reason is not found in the activity log",
+			expect: func(r *mock_async.MockFailureResolverMockRecorder) {
+				r.ResolveFailure(gomockinternal.AContext(), "test-correlation-id").Return("", nil)
+			},
+			expectedError: "failed to create resource test-group/test-resource (service: test-service): #: Internal Server Error: StatusCode=500",
+		},
+		{
+			name: "activity log lookup fails",
+			expect: func(r *mock_async.MockFailureResolverMockRecorder) {
+				r.ResolveFailure(gomockinternal.AContext(), "test-correlation-id").Return("", errors.New("lookup failed"))
+			},
+			expectedError: "failed to create resource test-group/test-resource (service: test-service): #: Internal Server Error: StatusCode=500",
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			scopeMock := newMockFutureScope(mockCtrl)
+			creatorMock := mock_async.NewMockCreator(mockCtrl)
+			resolverMock := mock_async.NewMockFailureResolver(mockCtrl)
+			specMock := mock_azure.NewMockResourceSpecGetter(mockCtrl)
+
+			specMock.EXPECT().ResourceName().Return("test-resource")
+			specMock.EXPECT().ResourceGroupName().Return("test-group")
+			scopeMock.EXPECT().GetLongRunningOperationState("test-resource", "test-service").Return(nil)
+			creatorMock.EXPECT().Get(gomockinternal.AContext(), specMock).Return(&fakeExistingResource, nil)
+			specMock.EXPECT().Parameters(&fakeExistingResource).Return(&fakeResourceParameters, nil)
+			creatorMock.EXPECT().CreateOrUpdateAsync(gomockinternal.AContext(), specMock, &fakeResourceParameters).Return(nil, nil, fakeInternalError)
+			tc.expect(resolverMock.EXPECT())
+
+			ctx := context.WithValue(context.TODO(), tele.CorrIDKeyVal, tele.CorrID("test-correlation-id"))
+			s := New(scopeMock, creatorMock, nil, WithFailureResolver(resolverMock))
+			_, _, err := s.CreateResource(ctx, specMock, "test-service")
+			g.Expect(err).To(HaveOccurred())
+			g.Expect(err).To(MatchError(tc.expectedError))
+		})
+	}
+}
+
+// TestFailedRequestsIncludeRequestID tests that the errors of failed requests include the ID Azure assigned to the request.
+func TestFailedRequestsIncludeRequestID(t *testing.T) {
+	g := NewWithT(t)
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	scopeMock := newMockFutureScope(mockCtrl)
+	creatorMock := mock_async.NewMockCreator(mockCtrl)
+	deleterMock := mock_async.NewMockDeleter(mockCtrl)
+	specMock := mock_azure.NewMockResourceSpecGetter(mockCtrl)
+
+	header := http.Header{}
+	header.Set("x-ms-request-id", "6b8f9a4e-0000-0000-0000-000000000000")
+	failed := autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: http.StatusInternalServerError, Header: header}, "Internal Server Error")
+
+	specMock.EXPECT().ResourceName().Return("test-resource").Times(2)
+	specMock.EXPECT().ResourceGroupName().Return("test-group").Times(2)
+	scopeMock.EXPECT().GetLongRunningOperationState("test-resource", "test-service").Return(nil).Times(2)
+	creatorMock.EXPECT().Get(gomockinternal.AContext(), specMock).Return(&fakeExistingResource, nil)
+	specMock.EXPECT().Parameters(&fakeExistingResource).Return(&fakeResourceParameters, nil)
+	creatorMock.EXPECT().CreateOrUpdateAsync(gomockinternal.AContext(), specMock, &fakeResourceParameters).Return(nil, nil, failed)
+	deleterMock.EXPECT().DeleteAsync(gomockinternal.AContext(), specMock).Return(nil, failed)
+
+	s := New(scopeMock, creatorMock, deleterMock)
+	_, _, err := s.CreateResource(context.TODO(), specMock, "test-service")
+	g.Expect(err).To(MatchError("failed to create resource test-group/test-resource (service: test-service): request ID 6b8f9a4e-0000-0000-0000-000000000000: #: Internal Server Error: StatusCode=500"))
+	_, err = s.DeleteResource(context.TODO(), specMock, "test-service")
+	g.Expect(err).To(MatchError("failed to delete resource test-group/test-resource (service: test-service): request ID 6b8f9a4e-0000-0000-0000-000000000000: #: Internal Server Error: StatusCode=500"))
+}
+
+// TestHasOngoingOperation tests the HasOngoingOperation function.
+func TestHasOngoingOperation(t *testing.T) {
+	testcases := []struct {
+		name     string
+		expected bool
+		expect   func(s *mock_async.MockFutureScopeMockRecorder, m *mock_async.MockFutureMigratorMockRecorder)
+	}{
+		{
+			name:     "no future data stored in status",
+			expected: false,
+			expect: func(s *mock_async.MockFutureScopeMockRecorder, m *mock_async.MockFutureMigratorMockRecorder) {
+				s.GetLongRunningOperationState("test-resource", "test-service").Return(nil)
+			},
+		},
+		{
+			name:     "valid future data stored in status",
+			expected: true,
+			expect: func(s *mock_async.MockFutureScopeMockRecorder, m *mock_async.MockFutureMigratorMockRecorder) {
+				s.GetLongRunningOperationState("test-resource", "test-service").Return(&validCreateFuture)
+			},
+		},
+		{
+			name:     "invalid future data stored in status is reset",
+			expected: false,
+			expect: func(s *mock_async.MockFutureScopeMockRecorder, m *mock_async.MockFutureMigratorMockRecorder) {
+				s.GetLongRunningOperationState("test-resource", "test-service").Return(&invalidFuture)
+				m.MigrateFuture(invalidFuture).Return(nil, errors.New("unknown future format"))
+				s.DeleteLongRunningOperationState("test-resource", "test-service")
+			},
+		},
+		{
+			name:     "invalid future data stored in status is migrated",
+			expected: true,
+			expect: func(s *mock_async.MockFutureScopeMockRecorder, m *mock_async.MockFutureMigratorMockRecorder) {
+				s.GetLongRunningOperationState("test-resource", "test-service").Return(&invalidFuture)
+				m.MigrateFuture(invalidFuture).Return(&validDeleteFuture, nil)
+				s.SetLongRunningOperationState(&validDeleteFuture)
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			scopeMock := newMockFutureScope(mockCtrl)
+			specMock := mock_azure.NewMockResourceSpecGetter(mockCtrl)
+
+			migratorMock := mock_async.NewMockFutureMigrator(mockCtrl)
+
+			specMock.EXPECT().ResourceName().Return("test-resource")
+			tc.expect(scopeMock.EXPECT(), migratorMock.EXPECT())
+
+			s := New(scopeMock, nil, nil, WithFutureMigrator(migratorMock))
+			g.Expect(s.HasOngoingOperation(context.TODO(), specMock, "test-service")).To(Equal(tc.expected))
+		})
+	}
+}
+
+// TestProcessOngoingOperationIterations tests that the polls of an ongoing operation are counted until it completes.
+func TestProcessOngoingOperationIterations(t *testing.T) {
+	g := NewWithT(t)
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	scopeMock := newMockFutureScope(mockCtrl)
+	clientMock := mock_async.NewMockFutureHandler(mockCtrl)
+
+	s := New(scopeMock, nil, nil)
+	s.operations = newOperationTracker()
+
+	scopeMock.EXPECT().GetLongRunningOperationState("test-resource", "test-service").Return(&validDeleteFuture).Times(3)
+	gomock.InOrder(
+		clientMock.EXPECT().IsDone(gomockinternal.AContext(), gomock.AssignableToTypeOf(&azureautorest.Future{})).Return(false, nil).Times(2),
+		clientMock.EXPECT().IsDone(gomockinternal.AContext(), gomock.AssignableToTypeOf(&azureautorest.Future{})).Return(true, nil),
+	)
+	clientMock.EXPECT().Result(gomockinternal.AContext(), gomock.AssignableToTypeOf(&azureautorest.Future{}), infrav1.DeleteFuture).Return(&fakeExistingResource, nil)
+	scopeMock.EXPECT().DeleteLongRunningOperationState("test-resource", "test-service")
+
+	for i := 1; i <= 2; i++ {
+		_, err := s.processOngoingOperation(context.TODO(), clientMock, testSpec("test-resource"), "test-resource", "test-service")
+		g.Expect(azure.IsOperationNotDoneError(err)).To(BeTrue())
+		g.Expect(s.operations.polls(&validDeleteFuture)).To(Equal(i))
+	}
+
+	result, err := s.processOngoingOperation(context.TODO(), clientMock, testSpec("test-resource"), "test-resource", "test-service")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result).To(Equal(&fakeExistingResource))
+	g.Expect(s.operations.polls(&validDeleteFuture)).To(BeZero())
+}
+
+// completedOperationFutureScope is a FutureScope that records completed operations.
+type completedOperationFutureScope struct {
+	*mock_async.MockFutureScope
+	*mock_async.MockCompletedOperationScope
+}
+
+// TestProcessOngoingOperationRecordsCompletedOperation tests that the time a completed operation took is recorded
+// before its long-running operation state is deleted.
+func TestProcessOngoingOperationRecordsCompletedOperation(t *testing.T) {
+	g := NewWithT(t)
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	scopeMock := newMockFutureScope(mockCtrl)
+	completedScopeMock := mock_async.NewMockCompletedOperationScope(mockCtrl)
+	clientMock := mock_async.NewMockFutureHandler(mockCtrl)
+
+	s := New(completedOperationFutureScope{scopeMock, completedScopeMock}, nil, nil)
+	s.operations = newOperationTracker()
+
+	startTime := metav1.NewTime(time.Now().Add(-5 * time.Minute))
+	future := validDeleteFuture
+	future.StartTime = &startTime
+	var completed infrav1.CompletedOperation
+	scopeMock.EXPECT().GetLongRunningOperationState("test-resource", "test-service").Return(&future)
+	clientMock.EXPECT().IsDone(gomockinternal.AContext(), gomock.AssignableToTypeOf(&azureautorest.Future{})).Return(true, nil)
+	clientMock.EXPECT().Result(gomockinternal.AContext(), gomock.AssignableToTypeOf(&azureautorest.Future{}), infrav1.DeleteFuture).Return(nil, nil)
+	gomock.InOrder(
+		completedScopeMock.EXPECT().SetCompletedOperation(gomock.Any()).Do(func(op infrav1.CompletedOperation) { completed = op }),
+		scopeMock.EXPECT().DeleteLongRunningOperationState("test-resource", "test-service"),
+	)
+
+	_, err := s.processOngoingOperation(context.TODO(), clientMock, testSpec("test-resource"), "test-resource", "test-service")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(completed.Type).To(Equal(infrav1.DeleteFuture))
+	g.Expect(completed.ServiceName).To(Equal("test-service"))
+	g.Expect(completed.Name).To(Equal("test-resource"))
+	g.Expect(completed.ResourceGroup).To(Equal("test-group"))
+	g.Expect(completed.StartTime).To(Equal(&startTime))
+	g.Expect(completed.Duration.Duration).To(BeNumerically(">=", 5*time.Minute))
+	g.Expect(completed.CompletionTime.Sub(startTime.Time)).To(Equal(completed.Duration.Duration))
+}
+
+// TestRequeueAfter tests that the requeue interval of the service is carried by the errors of ongoing operations.
+func TestRequeueAfter(t *testing.T) {
+	testcases := []struct {
+		name          string
+		opts          []Option
+		expectedError string
+		expectedAfter time.Duration
+	}{
+		{
+			name:          "default requeue interval",
+			expectedError: "operation type PUT on Azure resource test-group/test-resource is not done. Object will be requeued after 15s",
+			expectedAfter: 15 * time.Second,
+		},
+		{
+			name:          "custom requeue interval",
+			opts:          []Option{WithRequeueAfter(5 * time.Second)},
+			expectedError: "operation type PUT on Azure resource test-group/test-resource is not done. Object will be requeued after 5s",
+			expectedAfter: 5 * time.Second,
+		},
+		{
+			name:          "jittered requeue interval",
+			opts:          []Option{WithJitter(0.1, fixedSource(0))},
+			expectedError: "operation type PUT on Azure resource test-group/test-resource is not done. Object will be requeued after 13.5s",
+			expectedAfter: 13500 * time.Millisecond,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			scopeMock := newMockFutureScope(mockCtrl)
+			creatorMock := mock_async.NewMockCreator(mockCtrl)
+			specMock := mock_azure.NewMockResourceSpecGetter(mockCtrl)
+
+			specMock.EXPECT().ResourceName().Return("test-resource")
+			specMock.EXPECT().ResourceGroupName().Return("test-group")
+			scopeMock.EXPECT().GetLongRunningOperationState("test-resource", "test-service").Times(2).Return(&validCreateFuture)
+			creatorMock.EXPECT().IsDone(gomockinternal.AContext(), gomock.AssignableToTypeOf(&azureautorest.Future{})).Return(false, nil)
+
+			s := New(scopeMock, creatorMock, nil, tc.opts...)
+			_, _, err := s.CreateResource(context.TODO(), specMock, "test-service")
+			g.Expect(err).To(MatchError(tc.expectedError))
+			var recErr azure.ReconcileError
+			g.Expect(errors.As(err, &recErr)).To(BeTrue())
+			g.Expect(recErr.RequeueAfter()).To(Equal(tc.expectedAfter))
+		})
+	}
+}
+
+// TestCreateResourcePollAfterCreate tests that a create operation is checked on right after it is started when the
+// service polls after creates, so that quick operations complete within a single reconcile.
+func TestCreateResourcePollAfterCreate(t *testing.T) {
+	testcases := []struct {
+		name           string
+		expectedError  string
+		expectedResult interface{}
+		expect         func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockCreatorMockRecorder)
+	}{
+		{
+			name:           "create operation completes right away",
+			expectedResult: &fakeExistingResource,
+			expect: func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockCreatorMockRecorder) {
+				c.IsDone(gomockinternal.AContext(), gomock.AssignableToTypeOf(&azureautorest.Future{})).Return(true, nil)
+				c.Result(gomockinternal.AContext(), gomock.AssignableToTypeOf(&azureautorest.Future{}), infrav1.PutFuture).Return(&fakeExistingResource, nil)
+				s.DeleteLongRunningOperationState("test-resource", "test-service")
+			},
+		},
+		{
+			name:          "create operation is not done right away",
+			expectedError: "operation type PUT on Azure resource test-group/test-resource is not done. Object will be requeued after 15s",
+			expect: func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockCreatorMockRecorder) {
+				c.IsDone(gomockinternal.AContext(), gomock.AssignableToTypeOf(&azureautorest.Future{})).Return(false, nil)
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			scopeMock := newMockFutureScope(mockCtrl)
+			creatorMock := mock_async.NewMockCreator(mockCtrl)
+			specMock := mock_azure.NewMockResourceSpecGetter(mockCtrl)
+
+			specMock.EXPECT().ResourceName().Return("test-resource").AnyTimes()
+			specMock.EXPECT().ResourceGroupName().Return("test-group").AnyTimes()
+			gomock.InOrder(
+				scopeMock.EXPECT().GetLongRunningOperationState("test-resource", "test-service").Return(nil),
+				scopeMock.EXPECT().SetLongRunningOperationState(gomock.AssignableToTypeOf(&infrav1.Future{})),
+				scopeMock.EXPECT().GetLongRunningOperationState("test-resource", "test-service").Return(&validCreateFuture),
+			)
+			creatorMock.EXPECT().Get(gomockinternal.AContext(), specMock).Return(nil, fakeNotFoundError)
+			specMock.EXPECT().Parameters(nil).Return(&fakeResourceParameters, nil)
+			creatorMock.EXPECT().CreateOrUpdateAsync(gomockinternal.AContext(), specMock, &fakeResourceParameters).Return(nil, &azureautorest.Future{}, errCtxExceeded)
+			tc.expect(scopeMock.EXPECT(), creatorMock.EXPECT())
+
+			s := New(scopeMock, creatorMock, nil, WithPollAfterCreate())
+			s.operations = newOperationTracker()
+			result, changed, err := s.CreateResource(context.TODO(), specMock, "test-service")
+			g.Expect(changed).To(BeTrue())
+			if tc.expectedError != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(azure.IsOperationNotDoneError(err)).To(BeTrue())
+				g.Expect(err.Error()).To(ContainSubstring(tc.expectedError))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(result).To(Equal(tc.expectedResult))
+			}
+		})
+	}
+}
+
+// TestProcessOngoingOperationBackoff tests that the requeue interval grows while an operation is not done.
+func TestProcessOngoingOperationBackoff(t *testing.T) {
+	g := NewWithT(t)
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	scopeMock := newMockFutureScope(mockCtrl)
+	clientMock := mock_async.NewMockFutureHandler(mockCtrl)
+
+	s := New(scopeMock, nil, nil, WithBackoff(time.Minute))
+	s.operations = newOperationTracker()
+
+	scopeMock.EXPECT().GetLongRunningOperationState("test-resource", "test-service").Return(&validDeleteFuture).Times(6)
+	gomock.InOrder(
+		clientMock.EXPECT().IsDone(gomockinternal.AContext(), gomock.AssignableToTypeOf(&azureautorest.Future{})).Return(false, nil).Times(4),
+		clientMock.EXPECT().IsDone(gomockinternal.AContext(), gomock.AssignableToTypeOf(&azureautorest.Future{})).Return(true, nil),
+		clientMock.EXPECT().IsDone(gomockinternal.AContext(), gomock.AssignableToTypeOf(&azureautorest.Future{})).Return(false, nil),
+	)
+	clientMock.EXPECT().Result(gomockinternal.AContext(), gomock.AssignableToTypeOf(&azureautorest.Future{}), infrav1.DeleteFuture).Return(nil, nil)
+	scopeMock.EXPECT().DeleteLongRunningOperationState("test-resource", "test-service")
+
+	requeueAfter := func() time.Duration {
+		_, err := s.processOngoingOperation(context.TODO(), clientMock, testSpec("test-resource"), "test-resource", "test-service")
+		var recErr azure.ReconcileError
+		g.Expect(errors.As(err, &recErr)).To(BeTrue())
+		return recErr.RequeueAfter()
+	}
+
+	g.Expect(requeueAfter()).To(Equal(15 * time.Second))
+	g.Expect(requeueAfter()).To(Equal(30 * time.Second))
+	g.Expect(requeueAfter()).To(Equal(time.Minute))
+	g.Expect(requeueAfter()).To(Equal(time.Minute))
+
+	// Once the operation is done, the backoff is reset.
+	_, err := s.processOngoingOperation(context.TODO(), clientMock, testSpec("test-resource"), "test-resource", "test-service")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(requeueAfter()).To(Equal(15 * time.Second))
+}
+
+// TestPollers tests that operations of track2 SDK clients are tracked with the resume token of their poller.
+func TestPollers(t *testing.T) {
+	pollerFuture := func(futureType string) *infrav1.Future {
+		return &infrav1.Future{
+			Type:          futureType,
+			ServiceName:   "test-service",
+			Name:          "test-resource",
+			ResourceGroup: "test-group",
+			Data:          "eyJ0eXBlIjoiVGVzdFBvbGxlciIsInRva2VuIjp7fX0=",
+		}
+	}
+	resumeToken := `{"type":"TestPoller","token":{}}`
+
+	testcases := []struct {
+		name           string
+		run            func(s *Service, scope *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockPollerCreatorMockRecorder, d *mock_async.MockPollerDeleterMockRecorder, spec *mock_azure.MockResourceSpecGetter) (interface{}, error)
+		expectedResult interface{}
+		expectedError  string
+	}{
+		{
+			name: "create starts a poller",
+			run: func(s *Service, scope *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockPollerCreatorMockRecorder, d *mock_async.MockPollerDeleterMockRecorder, spec *mock_azure.MockResourceSpecGetter) (interface{}, error) {
+				spec.EXPECT().ResourceName().Return("test-resource")
+				spec.EXPECT().ResourceGroupName().Return("test-group")
+				scope.GetLongRunningOperationState("test-resource", "test-service").Return(nil)
+				c.Get(gomockinternal.AContext(), spec).Return(nil, fakeNotFoundError)
+				spec.EXPECT().Parameters(nil).Return(&fakeResourceParameters, nil)
+				c.BeginCreateOrUpdate(gomockinternal.AContext(), spec, &fakeResourceParameters).Return(nil, resumeToken, nil)
+				scope.SetLongRunningOperationState(pollerFuture(infrav1.PutFuture))
+				result, _, err := s.CreateResource(context.TODO(), spec, "test-service")
+				return result, err
+			},
+			expectedError: "operation type PUT on Azure resource test-group/test-resource is not done. Object will be requeued after 15s",
+		},
+		{
+			name: "create completes right away",
+			run: func(s *Service, scope *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockPollerCreatorMockRecorder, d *mock_async.MockPollerDeleterMockRecorder, spec *mock_azure.MockResourceSpecGetter) (interface{}, error) {
+				spec.EXPECT().ResourceName().Return("test-resource")
+				spec.EXPECT().ResourceGroupName().Return("test-group")
+				scope.GetLongRunningOperationState("test-resource", "test-service").Return(nil)
+				c.Get(gomockinternal.AContext(), spec).Return(&fakeExistingResource, nil)
+				spec.EXPECT().Parameters(&fakeExistingResource).Return(&fakeResourceParameters, nil)
+				c.BeginCreateOrUpdate(gomockinternal.AContext(), spec, &fakeResourceParameters).Return(&fakeExistingResource, "", nil)
+				result, _, err := s.CreateResource(context.TODO(), spec, "test-service")
+				return result, err
+			},
+			expectedResult: &fakeExistingResource,
+		},
+		{
+			name: "ongoing create is resumed and done",
+			run: func(s *Service, scope *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockPollerCreatorMockRecorder, d *mock_async.MockPollerDeleterMockRecorder, spec *mock_azure.MockResourceSpecGetter) (interface{}, error) {
+				spec.EXPECT().ResourceName().Return("test-resource")
+				spec.EXPECT().ResourceGroupName().Return("test-group")
+				scope.GetLongRunningOperationState("test-resource", "test-service").Return(pollerFuture(infrav1.PutFuture)).Times(2)
+				c.ResumePoller(gomockinternal.AContext(), *pollerFuture(infrav1.PutFuture), resumeToken).Return(true, &fakeExistingResource, nil)
+				scope.DeleteLongRunningOperationState("test-resource", "test-service")
+				result, _, err := s.CreateResource(context.TODO(), spec, "test-service")
+				return result, err
+			},
+			expectedResult: &fakeExistingResource,
+		},
+		{
+			name: "ongoing create is resumed and failed",
+			run: func(s *Service, scope *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockPollerCreatorMockRecorder, d *mock_async.MockPollerDeleterMockRecorder, spec *mock_azure.MockResourceSpecGetter) (interface{}, error) {
+				spec.EXPECT().ResourceName().Return("test-resource")
+				spec.EXPECT().ResourceGroupName().Return("test-group")
+				scope.GetLongRunningOperationState("test-resource", "test-service").Return(pollerFuture(infrav1.PutFuture)).Times(2)
+				// The failed operation is not completed from the resource, which is not got.
+				c.ResumePoller(gomockinternal.AContext(), *pollerFuture(infrav1.PutFuture), resumeToken).Return(true, nil, fakeInternalError)
+				result, _, err := s.CreateResource(context.TODO(), spec, "test-service")
+				return result, err
+			},
+			expectedError: "failed to resume long running operation: #: Internal Server Error: StatusCode=500",
+		},
+		{
+			name: "ongoing delete is resumed and not done",
+			run: func(s *Service, scope *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockPollerCreatorMockRecorder, d *mock_async.MockPollerDeleterMockRecorder, spec *mock_azure.MockResourceSpecGetter) (interface{}, error) {
+				spec.EXPECT().ResourceName().Return("test-resource")
+				spec.EXPECT().ResourceGroupName().Return("test-group")
+				scope.GetLongRunningOperationState("test-resource", "test-service").Return(pollerFuture(infrav1.DeleteFuture)).Times(2)
+				d.ResumePoller(gomockinternal.AContext(), *pollerFuture(infrav1.DeleteFuture), resumeToken).Return(false, nil, nil)
+				_, err := s.DeleteResource(context.TODO(), spec, "test-service")
+				return nil, err
+			},
+			expectedError: "operation type DELETE on Azure resource test-group/test-resource is not done. Object will be requeued after 15s",
+		},
+		{
+			name: "ongoing delete with an invalid resume token is reset",
+			run: func(s *Service, scope *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockPollerCreatorMockRecorder, d *mock_async.MockPollerDeleterMockRecorder, spec *mock_azure.MockResourceSpecGetter) (interface{}, error) {
+				spec.EXPECT().ResourceName().Return("test-resource")
+				spec.EXPECT().ResourceGroupName().Return("test-group")
+				scope.GetLongRunningOperationState("test-resource", "test-service").Return(&invalidFuture).Times(2)
+				c.Get(gomockinternal.AContext(), spec).Return(&fakeExistingResource, nil)
+				scope.DeleteLongRunningOperationState("test-resource", "test-service")
+				_, err := s.DeleteResource(context.TODO(), spec, "test-service")
+				return nil, err
+			},
+			expectedError: "could not decode future data, resetting long-running operation state: future data is not a valid poller resume token",
+		},
+		{
+			name: "delete of a resource that does not exist",
+			run: func(s *Service, scope *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockPollerCreatorMockRecorder, d *mock_async.MockPollerDeleterMockRecorder, spec *mock_azure.MockResourceSpecGetter) (interface{}, error) {
+				spec.EXPECT().ResourceName().Return("test-resource")
+				spec.EXPECT().ResourceGroupName().Return("test-group")
+				scope.GetLongRunningOperationState("test-resource", "test-service").Return(nil)
+				d.BeginDelete(gomockinternal.AContext(), spec).Return("", fakeNotFoundError)
+				return s.DeleteResource(context.TODO(), spec, "test-service")
+			},
+			expectedResult: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			scopeMock := newMockFutureScope(mockCtrl)
+			creatorMock := mock_async.NewMockPollerCreator(mockCtrl)
+			deleterMock := mock_async.NewMockPollerDeleter(mockCtrl)
+			specMock := mock_azure.NewMockResourceSpecGetter(mockCtrl)
+
+			s := NewWithPollers(scopeMock, creatorMock, deleterMock)
+			s.operations = newOperationTracker()
+			result, err := tc.run(s, scopeMock.EXPECT(), creatorMock.EXPECT(), deleterMock.EXPECT(), specMock)
+			if tc.expectedError != "" {
+				g.Expect(err).To(MatchError(tc.expectedError))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+			if tc.expectedResult != nil {
+				g.Expect(result).To(Equal(tc.expectedResult))
+			} else {
+				g.Expect(result).To(BeNil())
+			}
+		})
+	}
+}
+
+// TestCorrelationID tests that Azure requests carry the configured correlation ID, or else the one of the context.
+func TestCorrelationID(t *testing.T) {
+	objectCorrID := tele.CorrIDFromObject("test-uid", 2)
+
+	testcases := []struct {
+		name           string
+		opts           []Option
+		ctx            context.Context
+		expectedCorrID tele.CorrID
+	}{
+		{
+			name:           "correlation ID of the context",
+			ctx:            context.WithValue(context.TODO(), tele.CorrIDKeyVal, tele.CorrID("test-correlation-id")),
+			expectedCorrID: "test-correlation-id",
+		},
+		{
+			name:           "configured correlation ID replaces the one of the context",
+			opts:           []Option{WithCorrelationID(objectCorrID)},
+			ctx:            context.WithValue(context.TODO(), tele.CorrIDKeyVal, tele.CorrID("test-correlation-id")),
+			expectedCorrID: objectCorrID,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			scopeMock := newMockFutureScope(mockCtrl)
+			creatorMock := mock_async.NewMockCreator(mockCtrl)
+			specMock := mock_azure.NewMockResourceSpecGetter(mockCtrl)
+
+			specMock.EXPECT().ResourceName().Return("test-resource")
+			specMock.EXPECT().ResourceGroupName().Return("test-group")
+			var corrID tele.CorrID
+			creatorMock.EXPECT().Get(gomockinternal.AContext(), specMock).DoAndReturn(func(ctx context.Context, spec azure.ResourceSpecGetter) (interface{}, error) {
+				corrID, _ = tele.CorrIDFromCtx(ctx)
+				return &fakeExistingResource, nil
+			})
+
+			s := New(scopeMock, creatorMock, nil, tc.opts...)
+			_, err := s.GetResource(tc.ctx, specMock, "test-service")
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(corrID).To(Equal(tc.expectedCorrID))
+		})
+	}
+}
+
+// TestMaxAttempts tests that long-running operations are given up on after the configured number of attempts.
+func TestMaxAttempts(t *testing.T) {
+	testcases := []struct {
+		name          string
+		attempts      int
+		expectedError string
+		terminal      bool
+		expect        func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockFutureHandlerMockRecorder)
+	}{
+		{
+			name:          "operation not done before the last attempt is requeued",
+			attempts:      1,
+			expectedError: "operation type DELETE on Azure resource test-group/test-resource is not done. Object will be requeued after 15s",
+			expect: func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockFutureHandlerMockRecorder) {
+				c.IsDone(gomockinternal.AContext(), gomock.AssignableToTypeOf(&azureautorest.Future{})).Return(false, nil)
+			},
+		},
+		{
+			name:          "operation not done at the last attempt is given up on",
+			attempts:      2,
+			expectedError: "reconcile error that cannot be recovered occurred: DELETE operation on resource test-group/test-resource (service: test-service) did not complete after 3 attempts, resetting long-running operation state: operation type DELETE on Azure resource test-group/test-resource is not done. Object will not be requeued",
+			terminal:      true,
+			expect: func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockFutureHandlerMockRecorder) {
+				c.IsDone(gomockinternal.AContext(), gomock.AssignableToTypeOf(&azureautorest.Future{})).Return(false, nil)
+				s.DeleteLongRunningOperationState("test-resource", "test-service")
+			},
+		},
+		{
+			name:          "operation failing at the last attempt is given up on",
+			attempts:      2,
+			expectedError: "reconcile error that cannot be recovered occurred: DELETE operation on resource test-group/test-resource (service: test-service) did not complete after 3 attempts, resetting long-running operation state: #: Internal Server Error: StatusCode=500. Object will not be requeued",
+			terminal:      true,
+			expect: func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockFutureHandlerMockRecorder) {
+				c.IsDone(gomockinternal.AContext(), gomock.AssignableToTypeOf(&azureautorest.Future{})).Return(true, nil)
+				c.Result(gomockinternal.AContext(), gomock.AssignableToTypeOf(&azureautorest.Future{}), infrav1.DeleteFuture).Return(nil, fakeInternalError)
+				s.DeleteLongRunningOperationState("test-resource", "test-service")
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			scopeMock := newMockFutureScope(mockCtrl)
+			clientMock := mock_async.NewMockFutureHandler(mockCtrl)
+
+			scopeMock.EXPECT().GetLongRunningOperationState("test-resource", "test-service").Return(&validDeleteFuture)
+			tc.expect(scopeMock.EXPECT(), clientMock.EXPECT())
+
+			s := New(scopeMock, nil, nil, WithMaxAttempts(3))
+			s.operations = newOperationTracker()
+			for i := 0; i < tc.attempts; i++ {
+				s.operations.poll(&validDeleteFuture)
+			}
+			_, err := s.processOngoingOperation(context.TODO(), clientMock, testSpec("test-resource"), "test-resource", "test-service")
+			g.Expect(err).To(MatchError(tc.expectedError))
+			var recErr azure.ReconcileError
+			g.Expect(errors.As(err, &recErr)).To(BeTrue())
+			g.Expect(recErr.IsTerminal()).To(Equal(tc.terminal))
+			g.Expect(azure.IsOperationNotDoneError(err)).To(Equal(!tc.terminal))
+		})
+	}
+}
+
+// TestThrottledRequestsAreRequeued tests that throttled requests are requeued after the delay requested by Azure.
+func TestThrottledRequestsAreRequeued(t *testing.T) {
+	throttledError := func(retryAfter string) error {
+		header := http.Header{}
+		if retryAfter != "" {
+			header.Set("Retry-After", retryAfter)
+		}
+		return autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: http.StatusTooManyRequests, Header: header}, "Too Many Requests")
+	}
+
+	testcases := []struct {
+		name          string
+		run           func(s *Service, scope *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockCreatorMockRecorder, d *mock_async.MockDeleterMockRecorder, spec *mock_azure.MockResourceSpecGetter) error
+		expectedError string
+		expectedAfter time.Duration
+	}{
+		{
+			name: "checking if the operation is done is throttled",
+			run: func(s *Service, scope *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockCreatorMockRecorder, d *mock_async.MockDeleterMockRecorder, spec *mock_azure.MockResourceSpecGetter) error {
+				scope.GetLongRunningOperationState("test-resource", "test-service").Return(&validDeleteFuture)
+				d.IsDone(gomockinternal.AContext(), gomock.AssignableToTypeOf(&azureautorest.Future{})).Return(false, throttledError("30"))
+				_, err := s.processOngoingOperation(context.TODO(), s.Deleter, testSpec("test-resource"), "test-resource", "test-service")
+				return err
+			},
+			expectedError: "failed checking if the operation was complete: #: Too Many Requests: StatusCode=429. Object will be requeued after 30s",
+			expectedAfter: 30 * time.Second,
+		},
+		{
+			name: "create is throttled",
+			run: func(s *Service, scope *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockCreatorMockRecorder, d *mock_async.MockDeleterMockRecorder, spec *mock_azure.MockResourceSpecGetter) error {
+				spec.EXPECT().ResourceName().Return("test-resource")
+				spec.EXPECT().ResourceGroupName().Return("test-group")
+				scope.GetLongRunningOperationState("test-resource", "test-service").Return(nil)
+				c.Get(gomockinternal.AContext(), spec).Return(&fakeExistingResource, nil)
+				spec.EXPECT().Parameters(&fakeExistingResource).Return(&fakeResourceParameters, nil)
+				c.CreateOrUpdateAsync(gomockinternal.AContext(), spec, &fakeResourceParameters).Return(nil, nil, throttledError("45"))
+				_, _, err := s.CreateResource(context.TODO(), spec, "test-service")
+				return err
+			},
+			expectedError: "failed to create resource test-group/test-resource (service: test-service): #: Too Many Requests: StatusCode=429. Object will be requeued after 45s",
+			expectedAfter: 45 * time.Second,
+		},
+		{
+			name: "delete is throttled without a Retry-After header",
+			run: func(s *Service, scope *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockCreatorMockRecorder, d *mock_async.MockDeleterMockRecorder, spec *mock_azure.MockResourceSpecGetter) error {
+				spec.EXPECT().ResourceName().Return("test-resource")
+				spec.EXPECT().ResourceGroupName().Return("test-group")
+				scope.GetLongRunningOperationState("test-resource", "test-service").Return(nil)
+				d.DeleteAsync(gomockinternal.AContext(), spec).Return(nil, throttledError(""))
+				_, err := s.DeleteResource(context.TODO(), spec, "test-service")
+				return err
+			},
+			expectedError: "failed to delete resource test-group/test-resource (service: test-service): #: Too Many Requests: StatusCode=429. Object will be requeued after 15s",
+			expectedAfter: 15 * time.Second,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			scopeMock := newMockFutureScope(mockCtrl)
+			creatorMock := mock_async.NewMockCreator(mockCtrl)
+			deleterMock := mock_async.NewMockDeleter(mockCtrl)
+			specMock := mock_azure.NewMockResourceSpecGetter(mockCtrl)
+
+			s := New(scopeMock, creatorMock, deleterMock)
+			s.operations = newOperationTracker()
+			err := tc.run(s, scopeMock.EXPECT(), creatorMock.EXPECT(), deleterMock.EXPECT(), specMock)
+			g.Expect(err).To(MatchError(tc.expectedError))
+			var recErr azure.ReconcileError
+			g.Expect(errors.As(err, &recErr)).To(BeTrue())
+			g.Expect(recErr.IsTransient()).To(BeTrue())
+			g.Expect(recErr.RequeueAfter()).To(Equal(tc.expectedAfter))
+		})
+	}
+}
+
+// TestAuthorizerFailuresKeepOperations tests that requests the authorizer failed to get a token for are requeued without
+// resetting the ongoing operation or counting the failed poll as an attempt.
+func TestAuthorizerFailuresKeepOperations(t *testing.T) {
+	authorizerError := autorest.NewErrorWithError(errors.New("adal: Refresh request failed"), "azure.BearerAuthorizer", "WithAuthorization", &http.Response{StatusCode: http.StatusBadRequest}, "Failed to refresh the Token")
+
+	testcases := []struct {
+		name          string
+		run           func(s *Service, scope *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockCreatorMockRecorder, d *mock_async.MockDeleterMockRecorder, spec *mock_azure.MockResourceSpecGetter) error
+		expectedError string
+	}{
+		{
+			name: "checking on an ongoing create fails to get a token",
+			run: func(s *Service, scope *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockCreatorMockRecorder, d *mock_async.MockDeleterMockRecorder, spec *mock_azure.MockResourceSpecGetter) error {
+				spec.EXPECT().ResourceName().Return("test-resource")
+				spec.EXPECT().ResourceGroupName().Return("test-group")
+				scope.GetLongRunningOperationState("test-resource", "test-service").Return(&validCreateFuture).Times(2)
+				c.IsDone(gomockinternal.AContext(), gomock.AssignableToTypeOf(&azureautorest.Future{})).Return(false, authorizerError)
+				_, _, err := s.CreateResource(context.TODO(), spec, "test-service")
+				return err
+			},
+			expectedError: "failed to authorize checking on PUT operation on resource test-group/test-resource (service: test-service): azure.BearerAuthorizer#WithAuthorization: Failed to refresh the Token: StatusCode=400 -- Original Error: adal: Refresh request failed. Object will be requeued after 15s",
+		},
+		{
+			name: "getting the result of an ongoing delete fails to get a token",
+			run: func(s *Service, scope *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockCreatorMockRecorder, d *mock_async.MockDeleterMockRecorder, spec *mock_azure.MockResourceSpecGetter) error {
+				spec.EXPECT().ResourceName().Return("test-resource")
+				spec.EXPECT().ResourceGroupName().Return("test-group")
+				scope.GetLongRunningOperationState("test-resource", "test-service").Return(&validDeleteFuture).Times(2)
+				d.IsDone(gomockinternal.AContext(), gomock.AssignableToTypeOf(&azureautorest.Future{})).Return(true, nil)
+				d.Result(gomockinternal.AContext(), gomock.AssignableToTypeOf(&azureautorest.Future{}), infrav1.DeleteFuture).Return(nil, authorizerError)
+				_, err := s.DeleteResource(context.TODO(), spec, "test-service")
+				return err
+			},
+			expectedError: "failed to authorize checking on DELETE operation on resource test-group/test-resource (service: test-service): azure.BearerAuthorizer#WithAuthorization: Failed to refresh the Token: StatusCode=400 -- Original Error: adal: Refresh request failed. Object will be requeued after 15s",
+		},
+		{
+			name: "getting the existing resource fails to get a token",
+			run: func(s *Service, scope *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockCreatorMockRecorder, d *mock_async.MockDeleterMockRecorder, spec *mock_azure.MockResourceSpecGetter) error {
+				spec.EXPECT().ResourceName().Return("test-resource")
+				spec.EXPECT().ResourceGroupName().Return("test-group")
+				scope.GetLongRunningOperationState("test-resource", "test-service").Return(nil)
+				c.Get(gomockinternal.AContext(), spec).Return(nil, authorizerError)
+				_, _, err := s.CreateResource(context.TODO(), spec, "test-service")
+				return err
+			},
+			expectedError: "failed to get existing resource test-group/test-resource (service: test-service): azure.BearerAuthorizer#WithAuthorization: Failed to refresh the Token: StatusCode=400 -- Original Error: adal: Refresh request failed. Object will be requeued after 15s",
+		},
+		{
+			name: "create fails to get a token",
+			run: func(s *Service, scope *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockCreatorMockRecorder, d *mock_async.MockDeleterMockRecorder, spec *mock_azure.MockResourceSpecGetter) error {
+				spec.EXPECT().ResourceName().Return("test-resource")
+				spec.EXPECT().ResourceGroupName().Return("test-group")
+				scope.GetLongRunningOperationState("test-resource", "test-service").Return(nil)
+				c.Get(gomockinternal.AContext(), spec).Return(nil, fakeNotFoundError)
+				spec.EXPECT().Parameters(nil).Return(&fakeResourceParameters, nil)
+				c.CreateOrUpdateAsync(gomockinternal.AContext(), spec, &fakeResourceParameters).Return(nil, nil, authorizerError)
+				_, _, err := s.CreateResource(context.TODO(), spec, "test-service")
+				return err
+			},
+			expectedError: "failed to create resource test-group/test-resource (service: test-service): azure.BearerAuthorizer#WithAuthorization: Failed to refresh the Token: StatusCode=400 -- Original Error: adal: Refresh request failed. Object will be requeued after 15s",
+		},
+		{
+			name: "delete fails to get a token",
+			run: func(s *Service, scope *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockCreatorMockRecorder, d *mock_async.MockDeleterMockRecorder, spec *mock_azure.MockResourceSpecGetter) error {
+				spec.EXPECT().ResourceName().Return("test-resource")
+				spec.EXPECT().ResourceGroupName().Return("test-group")
+				scope.GetLongRunningOperationState("test-resource", "test-service").Return(nil)
+				d.DeleteAsync(gomockinternal.AContext(), spec).Return(nil, authorizerError)
+				_, err := s.DeleteResource(context.TODO(), spec, "test-service")
+				return err
+			},
+			expectedError: "failed to delete resource test-group/test-resource (service: test-service): azure.BearerAuthorizer#WithAuthorization: Failed to refresh the Token: StatusCode=400 -- Original Error: adal: Refresh request failed. Object will be requeued after 15s",
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			scopeMock := newMockFutureScope(mockCtrl)
+			creatorMock := mock_async.NewMockCreator(mockCtrl)
+			deleterMock := mock_async.NewMockDeleter(mockCtrl)
+			specMock := mock_azure.NewMockResourceSpecGetter(mockCtrl)
+
+			s := New(scopeMock, creatorMock, deleterMock, WithMaxAttempts(3))
+			s.operations = newOperationTracker()
+			err := tc.run(s, scopeMock.EXPECT(), creatorMock.EXPECT(), deleterMock.EXPECT(), specMock)
+			g.Expect(err).To(MatchError(tc.expectedError))
+			var recErr azure.ReconcileError
+			g.Expect(errors.As(err, &recErr)).To(BeTrue())
+			g.Expect(recErr.IsTransient()).To(BeTrue())
+			g.Expect(s.operations.polls(&validCreateFuture)).To(BeZero())
+			g.Expect(s.operations.polls(&validDeleteFuture)).To(BeZero())
+		})
+	}
+}
+
+// TestTerminalFailuresAreNotRequeued tests that requests failing with errors that retrying will not fix are not requeued.
+func TestTerminalFailuresAreNotRequeued(t *testing.T) {
+	testcases := []struct {
+		name          string
+		run           func(s *Service, scope *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockCreatorMockRecorder, d *mock_async.MockDeleterMockRecorder, spec *mock_azure.MockResourceSpecGetter) error
+		expectedError string
+	}{
+		{
+			name: "create is a bad request",
+			run: func(s *Service, scope *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockCreatorMockRecorder, d *mock_async.MockDeleterMockRecorder, spec *mock_azure.MockResourceSpecGetter) error {
+				spec.EXPECT().ResourceName().Return("test-resource")
+				spec.EXPECT().ResourceGroupName().Return("test-group")
+				scope.GetLongRunningOperationState("test-resource", "test-service").Return(nil)
+				c.Get(gomockinternal.AContext(), spec).Return(&fakeExistingResource, nil)
+				spec.EXPECT().Parameters(&fakeExistingResource).Return(&fakeResourceParameters, nil)
+				c.CreateOrUpdateAsync(gomockinternal.AContext(), spec, &fakeResourceParameters).Return(nil, nil, autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: http.StatusBadRequest}, "Bad Request"))
+				_, _, err := s.CreateResource(context.TODO(), spec, "test-service")
+				return err
+			},
+			expectedError: "reconcile error that cannot be recovered occurred: failed to create resource test-group/test-resource (service: test-service): #: Bad Request: StatusCode=400. Object will not be requeued",
+		},
+		{
+			name: "delete is forbidden",
+			run: func(s *Service, scope *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockCreatorMockRecorder, d *mock_async.MockDeleterMockRecorder, spec *mock_azure.MockResourceSpecGetter) error {
+				spec.EXPECT().ResourceName().Return("test-resource")
+				spec.EXPECT().ResourceGroupName().Return("test-group")
+				scope.GetLongRunningOperationState("test-resource", "test-service").Return(nil)
+				d.DeleteAsync(gomockinternal.AContext(), spec).Return(nil, autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: http.StatusForbidden}, "Forbidden"))
+				_, err := s.DeleteResource(context.TODO(), spec, "test-service")
+				return err
+			},
+			expectedError: "reconcile error that cannot be recovered occurred: failed to delete resource test-group/test-resource (service: test-service): #: Forbidden: StatusCode=403. Object will not be requeued",
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			scopeMock := newMockFutureScope(mockCtrl)
+			creatorMock := mock_async.NewMockCreator(mockCtrl)
+			deleterMock := mock_async.NewMockDeleter(mockCtrl)
+			specMock := mock_azure.NewMockResourceSpecGetter(mockCtrl)
+
+			s := New(scopeMock, creatorMock, deleterMock)
+			s.operations = newOperationTracker()
+			err := tc.run(s, scopeMock.EXPECT(), creatorMock.EXPECT(), deleterMock.EXPECT(), specMock)
+			g.Expect(err).To(MatchError(tc.expectedError))
+			var recErr azure.ReconcileError
+			g.Expect(errors.As(err, &recErr)).To(BeTrue())
+			g.Expect(recErr.IsTerminal()).To(BeTrue())
+		})
+	}
+}
+
+// TestOperationEvents tests that events are recorded when operations complete or fail.
+func TestOperationEvents(t *testing.T) {
+	testcases := []struct {
+		name          string
+		run           func(s *Service, scope *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockCreatorMockRecorder, spec *mock_azure.MockResourceSpecGetter) error
+		expectedEvent string
+	}{
+		{
+			name: "long running operation completes",
+			run: func(s *Service, scope *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockCreatorMockRecorder, spec *mock_azure.MockResourceSpecGetter) error {
+				scope.GetLongRunningOperationState("test-resource", "test-service").Return(&validCreateFuture)
+				c.IsDone(gomockinternal.AContext(), gomock.AssignableToTypeOf(&azureautorest.Future{})).Return(true, nil)
+				c.Result(gomockinternal.AContext(), gomock.AssignableToTypeOf(&azureautorest.Future{}), infrav1.PutFuture).Return(&fakeExistingResource, nil)
+				scope.DeleteLongRunningOperationState("test-resource", "test-service")
+				_, err := s.processOngoingOperation(context.TODO(), s.Creator, testSpec("test-resource"), "test-resource", "test-service")
+				return err
+			},
+			expectedEvent: "Normal OperationCompleted PUT operation on resource test-group/test-resource (service: test-service) completed",
+		},
+		{
+			name: "create fails",
+			run: func(s *Service, scope *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockCreatorMockRecorder, spec *mock_azure.MockResourceSpecGetter) error {
+				spec.EXPECT().ResourceName().Return("test-resource")
+				spec.EXPECT().ResourceGroupName().Return("test-group")
+				scope.GetLongRunningOperationState("test-resource", "test-service").Return(nil)
+				c.Get(gomockinternal.AContext(), spec).Return(&fakeExistingResource, nil)
+				spec.EXPECT().Parameters(&fakeExistingResource).Return(&fakeResourceParameters, nil)
+				c.CreateOrUpdateAsync(gomockinternal.AContext(), spec, &fakeResourceParameters).Return(nil, nil, fakeInternalError)
+				_, _, err := s.CreateResource(context.TODO(), spec, "test-service")
+				return err
+			},
+			expectedEvent: "Warning OperationFailed failed to create resource test-group/test-resource (service: test-service): #: Internal Server Error: StatusCode=500",
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			scopeMock := newMockFutureScope(mockCtrl)
+			creatorMock := mock_async.NewMockCreator(mockCtrl)
+			specMock := mock_azure.NewMockResourceSpecGetter(mockCtrl)
+			recorder := record.NewFakeRecorder(1)
+
+			s := New(scopeMock, creatorMock, nil, WithEventRecorder(recorder, &infrav1.AzureCluster{}))
+			s.operations = newOperationTracker()
+			_ = tc.run(s, scopeMock.EXPECT(), creatorMock.EXPECT(), specMock)
+			g.Expect(recorder.Events).To(Receive(Equal(tc.expectedEvent)))
+		})
+	}
+}
+
+// TestGetResource tests the GetResource function.
+func TestGetResource(t *testing.T) {
+	testcases := []struct {
+		name           string
+		expectedError  string
+		expectNotFound bool
+		expectedResult interface{}
+		expect         func(c *mock_async.MockCreatorMockRecorder, r *mock_azure.MockResourceSpecGetterMockRecorder)
+	}{
+		{
+			name:           "resource exists",
+			expectedResult: &fakeExistingResource,
+			expect: func(c *mock_async.MockCreatorMockRecorder, r *mock_azure.MockResourceSpecGetterMockRecorder) {
+				r.ResourceName().Return("test-resource")
+				r.ResourceGroupName().Return("test-group")
+				c.Get(gomockinternal.AContext(), gomock.AssignableToTypeOf(&mock_azure.MockResourceSpecGetter{})).Return(&fakeExistingResource, nil)
+			},
+		},
+		{
+			name:           "resource does not exist",
+			expectedError:  "resource test-group/test-resource (service: test-service): resource not found",
+			expectNotFound: true,
+			expect: func(c *mock_async.MockCreatorMockRecorder, r *mock_azure.MockResourceSpecGetterMockRecorder) {
+				r.ResourceName().Return("test-resource")
+				r.ResourceGroupName().Return("test-group")
+				c.Get(gomockinternal.AContext(), gomock.AssignableToTypeOf(&mock_azure.MockResourceSpecGetter{})).Return(nil, fakeNotFoundError)
+			},
+		},
+		{
+			name:          "error getting the resource",
+			expectedError: "failed to get resource test-group/test-resource (service: test-service): #: Internal Server Error: StatusCode=500",
+			expect: func(c *mock_async.MockCreatorMockRecorder, r *mock_azure.MockResourceSpecGetterMockRecorder) {
+				r.ResourceName().Return("test-resource")
+				r.ResourceGroupName().Return("test-group")
+				c.Get(gomockinternal.AContext(), gomock.AssignableToTypeOf(&mock_azure.MockResourceSpecGetter{})).Return(nil, fakeInternalError)
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			scopeMock := newMockFutureScope(mockCtrl)
+			creatorMock := mock_async.NewMockCreator(mockCtrl)
+			specMock := mock_azure.NewMockResourceSpecGetter(mockCtrl)
+
+			tc.expect(creatorMock.EXPECT(), specMock.EXPECT())
+
+			s := New(scopeMock, creatorMock, nil)
+			result, err := s.GetResource(context.TODO(), specMock, "test-service")
+			if tc.expectedError != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err).To(MatchError(tc.expectedError))
+				g.Expect(errors.Is(err, ErrResourceNotFound)).To(Equal(tc.expectNotFound))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(result).To(Equal(tc.expectedResult))
+			}
+		})
+	}
+}
+
+// cancelableDeleter is a Deleter that can cancel its delete operations.
+type cancelableDeleter struct {
+	*mock_async.MockDeleter
+	*mock_async.MockCanceler
+}
+
+// TestDeleteResourceCancellation tests that DeleteResource cancels the delete operation when the context is cancelled.
+func TestDeleteResourceCancellation(t *testing.T) {
+	testcases := []struct {
+		name          string
+		cancelable    bool
+		expectedError string
+		expect        func(s *mock_async.MockFutureScopeMockRecorder, d *mock_async.MockDeleterMockRecorder, c *mock_async.MockCancelerMockRecorder)
+	}{
+		{
+			name:          "delete is cancelled",
+			cancelable:    true,
+			expectedError: "delete of resource test-group/test-resource (service: test-service) was cancelled",
+			expect: func(s *mock_async.MockFutureScopeMockRecorder, d *mock_async.MockDeleterMockRecorder, c *mock_async.MockCancelerMockRecorder) {
+				d.DeleteAsync(gomock.Any(), gomock.AssignableToTypeOf(&mock_azure.MockResourceSpecGetter{})).Return(&azureautorest.Future{}, context.Canceled)
+				c.Cancel(gomockinternal.AContext(), gomock.AssignableToTypeOf(&azureautorest.Future{})).Return(nil)
+			},
+		},
+		{
+			name:          "cancelling the delete fails",
+			cancelable:    true,
+			expectedError: "operation type DELETE on Azure resource test-group/test-resource is not done. Object will be requeued after 15s",
+			expect: func(s *mock_async.MockFutureScopeMockRecorder, d *mock_async.MockDeleterMockRecorder, c *mock_async.MockCancelerMockRecorder) {
+				d.DeleteAsync(gomock.Any(), gomock.AssignableToTypeOf(&mock_azure.MockResourceSpecGetter{})).Return(&azureautorest.Future{}, context.Canceled)
+				c.Cancel(gomockinternal.AContext(), gomock.AssignableToTypeOf(&azureautorest.Future{})).Return(fakeInternalError)
+				s.SetLongRunningOperationState(gomock.AssignableToTypeOf(&infrav1.Future{}))
+			},
+		},
+		{
+			name:          "delete cannot be cancelled",
+			cancelable:    false,
+			expectedError: "operation type DELETE on Azure resource test-group/test-resource is not done. Object will be requeued after 15s",
+			expect: func(s *mock_async.MockFutureScopeMockRecorder, d *mock_async.MockDeleterMockRecorder, c *mock_async.MockCancelerMockRecorder) {
+				d.DeleteAsync(gomock.Any(), gomock.AssignableToTypeOf(&mock_azure.MockResourceSpecGetter{})).Return(&azureautorest.Future{}, context.Canceled)
+				s.SetLongRunningOperationState(gomock.AssignableToTypeOf(&infrav1.Future{}))
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			scopeMock := newMockFutureScope(mockCtrl)
+			deleterMock := mock_async.NewMockDeleter(mockCtrl)
+			cancelerMock := mock_async.NewMockCanceler(mockCtrl)
+			specMock := mock_azure.NewMockResourceSpecGetter(mockCtrl)
+
+			specMock.EXPECT().ResourceName().Return("test-resource")
+			specMock.EXPECT().ResourceGroupName().Return("test-group")
+			scopeMock.EXPECT().GetLongRunningOperationState("test-resource", "test-service").Return(nil)
+			tc.expect(scopeMock.EXPECT(), deleterMock.EXPECT(), cancelerMock.EXPECT())
+
+			var deleter Deleter = deleterMock
+			if tc.cancelable {
+				deleter = cancelableDeleter{MockDeleter: deleterMock, MockCanceler: cancelerMock}
+			}
+			s := New(scopeMock, nil, deleter)
+			s.operations = newOperationTracker()
+
+			ctx, cancel := context.WithCancel(context.TODO())
+			cancel()
+			_, err := s.DeleteResource(ctx, specMock, "test-service")
+			g.Expect(err).To(HaveOccurred())
+			g.Expect(err).To(MatchError(tc.expectedError))
+		})
+	}
+}
+
+// TestDryRun tests that a service in dry-run mode reports changes without creating, updating or deleting resources.
+func TestDryRun(t *testing.T) {
+	t.Run("create reports the change", func(t *testing.T) {
+		g := NewWithT(t)
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+		scopeMock := newMockFutureScope(mockCtrl)
+		creatorMock := mock_async.NewMockCreator(mockCtrl)
+		specMock := mock_azure.NewMockResourceSpecGetter(mockCtrl)
+
+		specMock.EXPECT().ResourceName().Return("test-resource")
+		specMock.EXPECT().ResourceGroupName().Return("test-group")
+		scopeMock.EXPECT().GetLongRunningOperationState("test-resource", "test-service").Return(nil)
+		creatorMock.EXPECT().Get(gomockinternal.AContext(), specMock).Return(&fakeExistingResource, nil)
+		specMock.EXPECT().Parameters(&fakeExistingResource).Return(&fakeResourceParameters, nil)
+
+		s := New(scopeMock, creatorMock, nil, WithDryRun())
+		result, _, err := s.CreateResource(context.TODO(), specMock, "test-service")
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(result).To(Equal(&Change{
+			ServiceName:   "test-service",
+			ResourceGroup: "test-group",
+			ResourceName:  "test-resource",
+			Type:          infrav1.PutFuture,
+			Existing:      &fakeExistingResource,
+			Parameters:    &fakeResourceParameters,
+		}))
+	})
+
+	t.Run("create of an up to date resource returns the resource", func(t *testing.T) {
+		g := NewWithT(t)
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+		scopeMock := newMockFutureScope(mockCtrl)
+		creatorMock := mock_async.NewMockCreator(mockCtrl)
+		specMock := mock_azure.NewMockResourceSpecGetter(mockCtrl)
+
+		specMock.EXPECT().ResourceName().Return("test-resource")
+		specMock.EXPECT().ResourceGroupName().Return("test-group")
+		scopeMock.EXPECT().GetLongRunningOperationState("test-resource", "test-service").Return(nil)
+		creatorMock.EXPECT().Get(gomockinternal.AContext(), specMock).Return(&fakeExistingResource, nil)
+		specMock.EXPECT().Parameters(&fakeExistingResource).Return(nil, nil)
+
+		s := New(scopeMock, creatorMock, nil, WithDryRun())
+		result, _, err := s.CreateResource(context.TODO(), specMock, "test-service")
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(result).To(Equal(&fakeExistingResource))
+	})
+
+	t.Run("delete does not delete the resource", func(t *testing.T) {
+		g := NewWithT(t)
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+		scopeMock := newMockFutureScope(mockCtrl)
+		deleterMock := mock_async.NewMockDeleter(mockCtrl)
+		specMock := mock_azure.NewMockResourceSpecGetter(mockCtrl)
+
+		specMock.EXPECT().ResourceName().Return("test-resource")
+		specMock.EXPECT().ResourceGroupName().Return("test-group")
+		scopeMock.EXPECT().GetLongRunningOperationState("test-resource", "test-service").Return(nil)
+
+		s := New(scopeMock, nil, deleterMock, WithDryRun())
+		_, err := s.DeleteResource(context.TODO(), specMock, "test-service")
+		g.Expect(err).NotTo(HaveOccurred())
+	})
+}
+
+// updatableCreator is a Creator that can update resources with a PATCH request.
+type updatableCreator struct {
+	*mock_async.MockCreator
+	*mock_async.MockUpdater
+}
+
+// TestCreateResourcePatch tests that CreateResource updates existing resources with a PATCH when the spec opts in.
+func TestCreateResourcePatch(t *testing.T) {
+	testcases := []struct {
+		name          string
+		updatable     bool
+		expectedError string
+		expect        func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockCreatorMockRecorder, u *mock_async.MockUpdaterMockRecorder, r *mock_azure.MockPatchSpecGetterMockRecorder)
+	}{
+		{
+			name:      "existing resource is patched",
+			updatable: true,
+			expect: func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockCreatorMockRecorder, u *mock_async.MockUpdaterMockRecorder, r *mock_azure.MockPatchSpecGetterMockRecorder) {
+				c.Get(gomockinternal.AContext(), gomock.Any()).Return(&fakeExistingResource, nil)
+				r.Parameters(&fakeExistingResource).Return(&fakeResourceParameters, nil)
+				r.UsePatch().Return(true)
+				u.UpdateAsync(gomockinternal.AContext(), gomock.Any(), &fakeResourceParameters).Return("test-resource", nil, nil)
+			},
+		},
+		{
+			name:          "ongoing patch is stored as a PATCH future",
+			updatable:     true,
+			expectedError: "operation type PATCH on Azure resource test-group/test-resource is not done. Object will be requeued after 15s",
+			expect: func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockCreatorMockRecorder, u *mock_async.MockUpdaterMockRecorder, r *mock_azure.MockPatchSpecGetterMockRecorder) {
+				c.Get(gomockinternal.AContext(), gomock.Any()).Return(&fakeExistingResource, nil)
+				r.Parameters(&fakeExistingResource).Return(&fakeResourceParameters, nil)
+				r.UsePatch().Return(true)
+				u.UpdateAsync(gomockinternal.AContext(), gomock.Any(), &fakeResourceParameters).Return(nil, &azureautorest.Future{}, errCtxExceeded)
+				s.SetLongRunningOperationState(gomock.AssignableToTypeOf(&infrav1.Future{})).Do(func(future *infrav1.Future) {
+					if future.Type != infrav1.PatchFuture {
+						t.Errorf("expected future of type %s, got %s", infrav1.PatchFuture, future.Type)
+					}
+				})
+			},
+		},
+		{
+			name:      "missing resource is created",
+			updatable: true,
+			expect: func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockCreatorMockRecorder, u *mock_async.MockUpdaterMockRecorder, r *mock_azure.MockPatchSpecGetterMockRecorder) {
+				c.Get(gomockinternal.AContext(), gomock.Any()).Return(nil, fakeNotFoundError)
+				r.Parameters(nil).Return(&fakeResourceParameters, nil)
+				c.CreateOrUpdateAsync(gomockinternal.AContext(), gomock.Any(), &fakeResourceParameters).Return("test-resource", nil, nil)
+			},
+		},
+		{
+			name:      "spec does not opt in to patching",
+			updatable: true,
+			expect: func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockCreatorMockRecorder, u *mock_async.MockUpdaterMockRecorder, r *mock_azure.MockPatchSpecGetterMockRecorder) {
+				c.Get(gomockinternal.AContext(), gomock.Any()).Return(&fakeExistingResource, nil)
+				r.Parameters(&fakeExistingResource).Return(&fakeResourceParameters, nil)
+				r.UsePatch().Return(false)
+				c.CreateOrUpdateAsync(gomockinternal.AContext(), gomock.Any(), &fakeResourceParameters).Return("test-resource", nil, nil)
+			},
+		},
+		{
+			name:      "client cannot patch",
+			updatable: false,
+			expect: func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockCreatorMockRecorder, u *mock_async.MockUpdaterMockRecorder, r *mock_azure.MockPatchSpecGetterMockRecorder) {
+				c.Get(gomockinternal.AContext(), gomock.Any()).Return(&fakeExistingResource, nil)
+				r.Parameters(&fakeExistingResource).Return(&fakeResourceParameters, nil)
+				c.CreateOrUpdateAsync(gomockinternal.AContext(), gomock.Any(), &fakeResourceParameters).Return("test-resource", nil, nil)
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			scopeMock := newMockFutureScope(mockCtrl)
+			creatorMock := mock_async.NewMockCreator(mockCtrl)
+			updaterMock := mock_async.NewMockUpdater(mockCtrl)
+			specMock := mock_azure.NewMockPatchSpecGetter(mockCtrl)
+
+			specMock.EXPECT().ResourceName().Return("test-resource")
+			specMock.EXPECT().ResourceGroupName().Return("test-group")
+			scopeMock.EXPECT().GetLongRunningOperationState("test-resource", "test-service").Return(nil)
+			tc.expect(scopeMock.EXPECT(), creatorMock.EXPECT(), updaterMock.EXPECT(), specMock.EXPECT())
+
+			var creator Creator = creatorMock
+			if tc.updatable {
+				creator = updatableCreator{MockCreator: creatorMock, MockUpdater: updaterMock}
+			}
+			s := New(scopeMock, creator, nil)
+			s.operations = newOperationTracker()
+
+			result, _, err := s.CreateResource(context.TODO(), specMock, "test-service")
+			if tc.expectedError != "" {
+				g.Expect(err).To(MatchError(tc.expectedError))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(result).To(Equal("test-resource"))
+			}
+		})
+	}
+}
+
+// TestCreateResourceTransformResult tests that the results of the operations on a resource are transformed when its
+// spec is a ResultTransformer.
+func TestCreateResourceTransformResult(t *testing.T) {
+	testcases := []struct {
+		name           string
+		expectedError  string
+		expectedResult interface{}
+		expect         func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockCreatorMockRecorder, r *mock_azure.MockResultTransformerMockRecorder)
+	}{
+		{
+			name:           "result of a resource created right away is transformed",
+			expectedResult: "Succeeded",
+			expect: func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockCreatorMockRecorder, r *mock_azure.MockResultTransformerMockRecorder) {
+				s.GetLongRunningOperationState("test-resource", "test-service").Return(nil)
+				c.Get(gomockinternal.AContext(), gomock.Any()).Return(nil, fakeNotFoundError)
+				r.Parameters(nil).Return(&fakeResourceParameters, nil)
+				c.CreateOrUpdateAsync(gomockinternal.AContext(), gomock.Any(), &fakeResourceParameters).Return(&fakeExistingResource, nil, nil)
+				r.TransformResult(&fakeExistingResource).Return("Succeeded", nil)
+			},
+		},
+		{
+			name:           "result of a completed long running operation is transformed",
+			expectedResult: "Succeeded",
+			expect: func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockCreatorMockRecorder, r *mock_azure.MockResultTransformerMockRecorder) {
+				s.GetLongRunningOperationState("test-resource", "test-service").Times(2).Return(&validCreateFuture)
+				c.IsDone(gomockinternal.AContext(), gomock.AssignableToTypeOf(&azureautorest.Future{})).Return(true, nil)
+				c.Result(gomockinternal.AContext(), gomock.AssignableToTypeOf(&azureautorest.Future{}), infrav1.PutFuture).Return(&fakeExistingResource, nil)
+				s.DeleteLongRunningOperationState("test-resource", "test-service")
+				r.TransformResult(&fakeExistingResource).Return("Succeeded", nil)
+			},
+		},
+		{
+			name:           "existing resource that is up to date is transformed",
+			expectedResult: "Succeeded",
+			expect: func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockCreatorMockRecorder, r *mock_azure.MockResultTransformerMockRecorder) {
+				s.GetLongRunningOperationState("test-resource", "test-service").Return(nil)
+				c.Get(gomockinternal.AContext(), gomock.Any()).Return(&fakeExistingResource, nil)
+				r.Parameters(&fakeExistingResource).Return(nil, nil)
+				r.TransformResult(&fakeExistingResource).Return("Succeeded", nil)
+			},
+		},
+		{
+			name:          "ongoing operation is not transformed",
+			expectedError: "operation type PUT on Azure resource test-group/test-resource is not done. Object will be requeued after 15s",
+			expect: func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockCreatorMockRecorder, r *mock_azure.MockResultTransformerMockRecorder) {
+				s.GetLongRunningOperationState("test-resource", "test-service").Return(nil)
+				c.Get(gomockinternal.AContext(), gomock.Any()).Return(nil, fakeNotFoundError)
+				r.Parameters(nil).Return(&fakeResourceParameters, nil)
+				c.CreateOrUpdateAsync(gomockinternal.AContext(), gomock.Any(), &fakeResourceParameters).Return(nil, &azureautorest.Future{}, errCtxExceeded)
+				s.SetLongRunningOperationState(gomock.AssignableToTypeOf(&infrav1.Future{}))
+			},
+		},
+		{
+			name:          "failure to transform the result is returned",
+			expectedError: "failed to transform result of resource test-group/test-resource (service: test-service): unexpected result",
+			expect: func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockCreatorMockRecorder, r *mock_azure.MockResultTransformerMockRecorder) {
+				s.GetLongRunningOperationState("test-resource", "test-service").Return(nil)
+				c.Get(gomockinternal.AContext(), gomock.Any()).Return(nil, fakeNotFoundError)
+				r.Parameters(nil).Return(&fakeResourceParameters, nil)
+				c.CreateOrUpdateAsync(gomockinternal.AContext(), gomock.Any(), &fakeResourceParameters).Return(&fakeExistingResource, nil, nil)
+				r.TransformResult(&fakeExistingResource).Return(nil, errors.New("unexpected result"))
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			scopeMock := newMockFutureScope(mockCtrl)
+			creatorMock := mock_async.NewMockCreator(mockCtrl)
+			specMock := mock_azure.NewMockResultTransformer(mockCtrl)
+
+			specMock.EXPECT().ResourceName().Return("test-resource").AnyTimes()
+			specMock.EXPECT().ResourceGroupName().Return("test-group").AnyTimes()
+			tc.expect(scopeMock.EXPECT(), creatorMock.EXPECT(), specMock.EXPECT())
+
+			s := New(scopeMock, creatorMock, nil)
+			s.operations = newOperationTracker()
+
+			result, _, err := s.CreateResource(context.TODO(), specMock, "test-service")
+			if tc.expectedError != "" {
+				g.Expect(err).To(MatchError(tc.expectedError))
+				g.Expect(result).To(BeNil())
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(result).To(Equal(tc.expectedResult))
+			}
+		})
+	}
+}
+
+// TestReconcileTimeout tests that the reconcile timeout of the service can be overridden.
+func TestReconcileTimeout(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(reconciler.ServiceReconcileTimeout(New(nil, nil, nil))).To(Equal(reconciler.DefaultAzureServiceReconcileTimeout))
+	g.Expect(reconciler.ServiceReconcileTimeout(New(nil, nil, nil, WithReconcileTimeout(30*time.Minute)))).To(Equal(30 * time.Minute))
+}
+
+// provisioningStateCreator is a creator that can tell the provisioning state of the resources it returns.
+type provisioningStateCreator struct {
+	*mock_async.MockCreator
+	*mock_async.MockProvisioningStateGetter
+}
+
+// TestProvisioningState tests that the provisioning state of a resource is only known if the client can tell it.
+func TestProvisioningState(t *testing.T) {
+	g := NewWithT(t)
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	creatorMock := mock_async.NewMockCreator(mockCtrl)
+	getterMock := mock_async.NewMockProvisioningStateGetter(mockCtrl)
+	getterMock.EXPECT().ProvisioningState("test-resource").Return("Updating", true)
+
+	state, ok := New(nil, creatorMock, nil).ProvisioningState("test-resource")
+	g.Expect(ok).To(BeFalse())
+	g.Expect(state).To(BeEmpty())
+
+	s := New(nil, provisioningStateCreator{creatorMock, getterMock}, nil)
+	state, ok = s.ProvisioningState("test-resource")
+	g.Expect(ok).To(BeTrue())
+	g.Expect(state).To(Equal("Updating"))
+
+	state, ok = s.ProvisioningState(nil)
+	g.Expect(ok).To(BeFalse())
+	g.Expect(state).To(BeEmpty())
+}
+
+// TestRestartOnSpecChange tests that an ongoing create operation is abandoned and restarted when the desired parameters
+// of the resource no longer match the ones it was started with.
+func TestRestartOnSpecChange(t *testing.T) {
+	hashedFuture := validCreateFuture
+	hashedFuture.ParametersHash = parametersHash("parameters")
+
+	testcases := []struct {
+		name          string
+		future        infrav1.Future
+		expectedError string
+		expect        func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockCreatorMockRecorder, r *mock_azure.MockResourceSpecGetterMockRecorder)
+	}{
+		{
+			name:          "desired parameters are unchanged, keeps polling the operation",
+			future:        hashedFuture,
+			expectedError: "operation type PUT on Azure resource test-group/test-resource is not done. Object will be requeued after 15s",
+			expect: func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockCreatorMockRecorder, r *mock_azure.MockResourceSpecGetterMockRecorder) {
+				c.Get(gomockinternal.AContext(), gomock.AssignableToTypeOf(&mock_azure.MockResourceSpecGetter{})).Return(nil, fakeNotFoundError)
+				r.Parameters(nil).Return("parameters", nil)
+				c.IsDone(gomockinternal.AContext(), gomock.AssignableToTypeOf(&azureautorest.Future{})).Return(false, nil)
+			},
+		},
+		{
+			name:          "resource already matches the spec, keeps polling the operation",
+			future:        hashedFuture,
+			expectedError: "operation type PUT on Azure resource test-group/test-resource is not done. Object will be requeued after 15s",
+			expect: func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockCreatorMockRecorder, r *mock_azure.MockResourceSpecGetterMockRecorder) {
+				c.Get(gomockinternal.AContext(), gomock.AssignableToTypeOf(&mock_azure.MockResourceSpecGetter{})).Return(&fakeExistingResource, nil)
+				r.Parameters(&fakeExistingResource).Return(nil, nil)
+				c.IsDone(gomockinternal.AContext(), gomock.AssignableToTypeOf(&azureautorest.Future{})).Return(false, nil)
+			},
+		},
+		{
+			name:          "desired parameters changed, restarts the operation",
+			future:        hashedFuture,
+			expectedError: "operation type PUT on Azure resource test-group/test-resource is not done. Object will be requeued after 15s",
+			expect: func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockCreatorMockRecorder, r *mock_azure.MockResourceSpecGetterMockRecorder) {
+				c.Get(gomockinternal.AContext(), gomock.AssignableToTypeOf(&mock_azure.MockResourceSpecGetter{})).Times(2).Return(&fakeExistingResource, nil)
+				r.Parameters(&fakeExistingResource).Times(2).Return("new-parameters", nil)
+				s.DeleteLongRunningOperationState("test-resource", "test-service")
+				c.CreateOrUpdateAsync(gomockinternal.AContext(), gomock.AssignableToTypeOf(&mock_azure.MockResourceSpecGetter{}), "new-parameters").Return(nil, &azureautorest.Future{}, errCtxExceeded)
+				s.SetLongRunningOperationState(gomock.Any()).Do(func(future *infrav1.Future) {
+					if future.ParametersHash != parametersHash("new-parameters") {
+						t.Errorf("expected the hash of the new parameters to be stored, got %q", future.ParametersHash)
+					}
+				})
+			},
+		},
+		{
+			name:          "future has no parameters hash, keeps polling the operation",
+			future:        validCreateFuture,
+			expectedError: "operation type PUT on Azure resource test-group/test-resource is not done. Object will be requeued after 15s",
+			expect: func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockCreatorMockRecorder, r *mock_azure.MockResourceSpecGetterMockRecorder) {
+				c.IsDone(gomockinternal.AContext(), gomock.AssignableToTypeOf(&azureautorest.Future{})).Return(false, nil)
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			scopeMock := newMockFutureScope(mockCtrl)
+			creatorMock := mock_async.NewMockCreator(mockCtrl)
+			specMock := mock_azure.NewMockResourceSpecGetter(mockCtrl)
+
+			future := tc.future
+			specMock.EXPECT().ResourceName().Return("test-resource").AnyTimes()
+			specMock.EXPECT().ResourceGroupName().Return("test-group").AnyTimes()
+			scopeMock.EXPECT().GetLongRunningOperationState("test-resource", "test-service").Return(&future).AnyTimes()
+			tc.expect(scopeMock.EXPECT(), creatorMock.EXPECT(), specMock.EXPECT())
+
+			s := New(scopeMock, creatorMock, nil, WithRestartOnSpecChange())
+			s.operations = newOperationTracker()
+			_, _, err := s.CreateResource(context.TODO(), specMock, "test-service")
+			g.Expect(err).To(MatchError(tc.expectedError))
+		})
+	}
+}
+
+// TestNotFoundGracePeriod tests that a resource that is not found right after a create operation was started by another
+// reconcile is not created a second time.
+func TestNotFoundGracePeriod(t *testing.T) {
+	testcases := []struct {
+		name          string
+		opts          []Option
+		expectedPUTs  int
+		expectedError string
+	}{
+		{
+			name:          "without grace period, the resource is created twice",
+			expectedPUTs:  2,
+			expectedError: "operation type PUT on Azure resource test-group/test-resource is not done. Object will be requeued after 15s",
+		},
+		{
+			name:          "with grace period, the resource is considered as being created",
+			opts:          []Option{WithNotFoundGracePeriod(time.Minute)},
+			expectedPUTs:  1,
+			expectedError: "operation type PUT on Azure resource test-group/test-resource is not done. Object will be requeued after 15s",
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			creatorMock := mock_async.NewMockCreator(mockCtrl)
+			specMock := mock_azure.NewMockResourceSpecGetter(mockCtrl)
+			// The second reconcile does not see the long-running operation state stored by the first one yet.
+			firstScopeMock := newMockFutureScope(mockCtrl)
+			secondScopeMock := newMockFutureScope(mockCtrl)
+
+			specMock.EXPECT().ResourceName().Return("test-resource").AnyTimes()
+			specMock.EXPECT().ResourceGroupName().Return("test-group").AnyTimes()
+			specMock.EXPECT().Parameters(nil).Return(&fakeResourceParameters, nil).Times(tc.expectedPUTs)
+			creatorMock.EXPECT().Get(gomockinternal.AContext(), specMock).Return(nil, fakeNotFoundError).Times(2)
+			creatorMock.EXPECT().CreateOrUpdateAsync(gomockinternal.AContext(), specMock, &fakeResourceParameters).Return(nil, &azureautorest.Future{}, errCtxExceeded).Times(tc.expectedPUTs)
+			firstScopeMock.EXPECT().GetLongRunningOperationState("test-resource", "test-service").Return(nil)
+			firstScopeMock.EXPECT().SetLongRunningOperationState(gomock.AssignableToTypeOf(&infrav1.Future{}))
+			secondScopeMock.EXPECT().GetLongRunningOperationState("test-resource", "test-service").Return(nil)
+			secondScopeMock.EXPECT().SetLongRunningOperationState(gomock.AssignableToTypeOf(&infrav1.Future{})).Times(tc.expectedPUTs - 1)
+
+			operations := newOperationTracker()
+			first := New(firstScopeMock, creatorMock, nil, tc.opts...)
+			first.operations = operations
+			second := New(secondScopeMock, creatorMock, nil, tc.opts...)
+			second.operations = operations
+
+			_, _, err := first.CreateResource(context.TODO(), specMock, "test-service")
+			g.Expect(azure.IsOperationNotDoneError(err)).To(BeTrue())
+			_, _, err = second.CreateResource(context.TODO(), specMock, "test-service")
+			g.Expect(azure.IsOperationNotDoneError(err)).To(BeTrue())
+			g.Expect(err).To(MatchError(tc.expectedError))
+		})
+	}
+}
+
+// TestServicePaused tests that the resources of a paused service are neither created, updated nor deleted, and that
+// their ongoing operations are left untouched.
+func TestServicePaused(t *testing.T) {
+	g := NewWithT(t)
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	scopeMock := mock_async.NewMockFutureScope(mockCtrl)
+	specMock := mock_azure.NewMockResourceSpecGetter(mockCtrl)
+
+	specMock.EXPECT().ResourceName().Return("test-resource").AnyTimes()
+	specMock.EXPECT().ResourceGroupName().Return("test-group").AnyTimes()
+	scopeMock.EXPECT().IsServicePaused("test-service").Return(true).Times(2)
+
+	s := New(scopeMock, mock_async.NewMockCreator(mockCtrl), mock_async.NewMockDeleter(mockCtrl))
+	result, changed, err := s.CreateResource(context.TODO(), specMock, "test-service")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result).To(BeNil())
+	g.Expect(changed).To(BeFalse())
+	notFound, err := s.DeleteResource(context.TODO(), specMock, "test-service")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(notFound).To(BeFalse())
+}
+
+// TestWaitForOperation tests that WaitForOperation checks on the ongoing operation until it completes.
+func TestWaitForOperation(t *testing.T) {
+	testcases := []struct {
+		name           string
+		timeout        time.Duration
+		expectedError  string
+		expectedResult interface{}
+		expect         func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockCreatorMockRecorder, d *mock_async.MockDeleterMockRecorder)
+	}{
+		{
+			name:           "no ongoing operation",
+			timeout:        time.Second,
+			expectedResult: nil,
+			expect: func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockCreatorMockRecorder, d *mock_async.MockDeleterMockRecorder) {
+				s.GetLongRunningOperationState("test-resource", "test-service").Return(nil)
+			},
+		},
+		{
+			name:           "create operation completes after being checked on",
+			timeout:        time.Second,
+			expectedResult: &fakeExistingResource,
+			expect: func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockCreatorMockRecorder, d *mock_async.MockDeleterMockRecorder) {
+				s.GetLongRunningOperationState("test-resource", "test-service").Return(&validCreateFuture).Times(6)
+				s.SetLongRunningOperationState(gomock.Any()).AnyTimes()
+				gomock.InOrder(
+					c.IsDone(gomockinternal.AContext(), gomock.AssignableToTypeOf(&azureautorest.Future{})).Return(false, nil).Times(2),
+					c.IsDone(gomockinternal.AContext(), gomock.AssignableToTypeOf(&azureautorest.Future{})).Return(true, nil),
+				)
+				c.Result(gomockinternal.AContext(), gomock.AssignableToTypeOf(&azureautorest.Future{}), infrav1.PutFuture).Return(&fakeExistingResource, nil)
+				s.DeleteLongRunningOperationState("test-resource", "test-service")
+			},
+		},
+		{
+			name:          "delete operation fails",
+			timeout:       time.Second,
+			expectedError: "#: Internal Server Error: StatusCode=500",
+			expect: func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockCreatorMockRecorder, d *mock_async.MockDeleterMockRecorder) {
+				s.GetLongRunningOperationState("test-resource", "test-service").Return(&validDeleteFuture).Times(2)
+				d.IsDone(gomockinternal.AContext(), gomock.AssignableToTypeOf(&azureautorest.Future{})).Return(true, nil)
+				d.Result(gomockinternal.AContext(), gomock.AssignableToTypeOf(&azureautorest.Future{}), infrav1.DeleteFuture).Return(nil, fakeInternalError)
+			},
+		},
+		{
+			name:          "delete operation does not complete before the deadline",
+			timeout:       50 * time.Millisecond,
+			expectedError: "waiting for operation on resource test-group/test-resource (service: test-service) to complete: context deadline exceeded",
+			expect: func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockCreatorMockRecorder, d *mock_async.MockDeleterMockRecorder) {
+				s.GetLongRunningOperationState("test-resource", "test-service").Return(&validDeleteFuture).MinTimes(2)
+				s.SetLongRunningOperationState(gomock.Any()).AnyTimes()
+				d.IsDone(gomockinternal.AContext(), gomock.AssignableToTypeOf(&azureautorest.Future{})).Return(false, nil).MinTimes(1)
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			scopeMock := newMockFutureScope(mockCtrl)
+			creatorMock := mock_async.NewMockCreator(mockCtrl)
+			deleterMock := mock_async.NewMockDeleter(mockCtrl)
+			specMock := mock_azure.NewMockResourceSpecGetter(mockCtrl)
+
+			specMock.EXPECT().ResourceName().Return("test-resource").AnyTimes()
+			specMock.EXPECT().ResourceGroupName().Return("test-group").AnyTimes()
+			tc.expect(scopeMock.EXPECT(), creatorMock.EXPECT(), deleterMock.EXPECT())
+
+			s := New(scopeMock, creatorMock, deleterMock, WithRequeueAfter(5*time.Millisecond))
+			s.operations = newOperationTracker()
+
+			ctx, cancel := context.WithTimeout(context.TODO(), tc.timeout)
+			defer cancel()
+			result, err := s.WaitForOperation(ctx, specMock, "test-service")
+			if tc.expectedError != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err).To(MatchError(tc.expectedError))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+				if tc.expectedResult == nil {
+					g.Expect(result).To(BeNil())
+				} else {
+					g.Expect(result).To(Equal(tc.expectedResult))
+				}
+			}
+		})
+	}
+}
+
+// TestThrottledRequestBacksOffRateLimiter tests that a throttled request backs off all the services sharing the rate limiter.
+func TestThrottledRequestBacksOffRateLimiter(t *testing.T) {
+	g := NewWithT(t)
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	scopeMock := newMockFutureScope(mockCtrl)
+	deleterMock := mock_async.NewMockDeleter(mockCtrl)
+	specMock := mock_azure.NewMockResourceSpecGetter(mockCtrl)
+
+	limiter := NewRateLimiter(0, 0, time.Minute)
+	throttled := autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}, "Too Many Requests")
+	specMock.EXPECT().ResourceName().Return("test-resource")
+	specMock.EXPECT().ResourceGroupName().Return("test-group")
+	scopeMock.EXPECT().GetLongRunningOperationState("test-resource", "test-service").Return(nil)
+	deleterMock.EXPECT().DeleteAsync(gomockinternal.AContext(), specMock).Return(nil, throttled)
+
+	s := New(scopeMock, nil, deleterMock, WithRateLimiter(limiter))
+	s.operations = newOperationTracker()
+	_, err := s.DeleteResource(context.TODO(), specMock, "test-service")
+	var recErr azure.ReconcileError
+	g.Expect(errors.As(err, &recErr)).To(BeTrue())
+	g.Expect(recErr.RequeueAfter()).To(Equal(reconciler.DefaultReconcilerRequeue))
+	g.Expect(limiter.Reserve()).To(BeNumerically(">", 0))
+
+	// Another service sharing the rate limiter does not make requests until the backoff is over.
+	other := New(scopeMock, nil, deleterMock, WithRateLimiter(limiter))
+	specMock.EXPECT().ResourceName().Return("other-resource")
+	specMock.EXPECT().ResourceGroupName().Return("test-group")
+	scopeMock.EXPECT().GetLongRunningOperationState("other-resource", "other-service").Return(nil)
+	_, err = other.DeleteResource(context.TODO(), specMock, "other-service")
+	g.Expect(err).To(MatchError(ContainSubstring("rate limited, waiting to delete resource test-group/other-resource (service: other-service)")))
+}
+
+// TestDeleteResourceChecksExistence tests that DeleteResource skips the delete of a resource that is not found when the
+// service checks the existence of resources before deleting them.
+func TestDeleteResourceChecksExistence(t *testing.T) {
+	testcases := []struct {
+		name             string
+		expect           func(c *mock_async.MockCreatorMockRecorder, d *mock_async.MockDeleterMockRecorder, spec *mock_azure.MockResourceSpecGetter)
+		expectedNotFound bool
+		expectedError    string
+	}{
+		{
+			name: "resource is not found, delete is skipped",
+			expect: func(c *mock_async.MockCreatorMockRecorder, d *mock_async.MockDeleterMockRecorder, spec *mock_azure.MockResourceSpecGetter) {
+				c.Get(gomockinternal.AContext(), spec).Return(nil, fakeNotFoundError)
+			},
+			expectedNotFound: true,
+		},
+		{
+			name: "resource exists, it is deleted",
+			expect: func(c *mock_async.MockCreatorMockRecorder, d *mock_async.MockDeleterMockRecorder, spec *mock_azure.MockResourceSpecGetter) {
+				c.Get(gomockinternal.AContext(), spec).Return(&fakeExistingResource, nil)
+				d.DeleteAsync(gomockinternal.AContext(), spec).Return(nil, nil)
+			},
+			expectedNotFound: false,
+		},
+		{
+			name: "getting the resource fails",
+			expect: func(c *mock_async.MockCreatorMockRecorder, d *mock_async.MockDeleterMockRecorder, spec *mock_azure.MockResourceSpecGetter) {
+				c.Get(gomockinternal.AContext(), spec).Return(nil, fakeInternalError)
+			},
+			expectedError: "failed to get existing resource test-group/test-resource (service: test-service): #: Internal Server Error: StatusCode=500",
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			scopeMock := newMockFutureScope(mockCtrl)
+			creatorMock := mock_async.NewMockCreator(mockCtrl)
+			deleterMock := mock_async.NewMockDeleter(mockCtrl)
+			specMock := mock_azure.NewMockResourceSpecGetter(mockCtrl)
+
+			specMock.EXPECT().ResourceName().Return("test-resource")
+			specMock.EXPECT().ResourceGroupName().Return("test-group")
+			scopeMock.EXPECT().GetLongRunningOperationState("test-resource", "test-service").Return(nil)
+			tc.expect(creatorMock.EXPECT(), deleterMock.EXPECT(), specMock)
+
+			s := New(scopeMock, creatorMock, deleterMock, WithExistenceCheckBeforeDelete())
+			notFound, err := s.DeleteResource(context.TODO(), specMock, "test-service")
+			if tc.expectedError != "" {
+				g.Expect(err).To(MatchError(tc.expectedError))
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(notFound).To(Equal(tc.expectedNotFound))
 		})
 	}
 }
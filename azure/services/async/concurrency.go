@@ -0,0 +1,66 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package async
+
+import (
+	"sync/atomic"
+
+	"golang.org/x/sync/semaphore"
+)
+
+// ConcurrencyLimiter is a weighted semaphore bounding the Azure requests in flight across all the services sharing it,
+// e.g. all the services of a manager, so that the reconciles of a fleet of clusters cannot saturate Azure Resource
+// Manager. Each request holds the weight of its service while it is being made, so that the requests of services whose
+// operations are more expensive can count for more. It never blocks: a request that cannot be made now should be
+// requeued instead.
+type ConcurrencyLimiter struct {
+	sem      *semaphore.Weighted
+	capacity int64
+	inFlight int64
+}
+
+// NewConcurrencyLimiter returns a ConcurrencyLimiter allowing requests with a total weight of up to capacity to be in
+// flight at the same time.
+func NewConcurrencyLimiter(capacity int64) *ConcurrencyLimiter {
+	return &ConcurrencyLimiter{sem: semaphore.NewWeighted(capacity), capacity: capacity}
+}
+
+// Capacity returns the total weight of the requests allowed in flight at the same time.
+func (l *ConcurrencyLimiter) Capacity() int64 {
+	return l.capacity
+}
+
+// TryAcquire returns true if a request with the given weight may be made now, in which case Release must be called
+// with the same weight once the request was made. A request heavier than the capacity is never allowed.
+func (l *ConcurrencyLimiter) TryAcquire(weight int64) bool {
+	if !l.sem.TryAcquire(weight) {
+		return false
+	}
+	atomic.AddInt64(&l.inFlight, weight)
+	return true
+}
+
+// Release frees the weight acquired with TryAcquire.
+func (l *ConcurrencyLimiter) Release(weight int64) {
+	atomic.AddInt64(&l.inFlight, -weight)
+	l.sem.Release(weight)
+}
+
+// InFlight returns the total weight of the requests in flight.
+func (l *ConcurrencyLimiter) InFlight() int64 {
+	return atomic.LoadInt64(&l.inFlight)
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package async
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/async/mock_async"
+	gomockinternal "sigs.k8s.io/cluster-api-provider-azure/internal/test/matchers/gomock"
+	"sigs.k8s.io/cluster-api-provider-azure/util/reconciler"
+)
+
+func TestConcurrencyLimiterBoundsWeightInFlight(t *testing.T) {
+	g := NewWithT(t)
+	limiter := NewConcurrencyLimiter(3)
+
+	g.Expect(limiter.TryAcquire(2)).To(BeTrue())
+	g.Expect(limiter.TryAcquire(2)).To(BeFalse())
+	g.Expect(limiter.TryAcquire(1)).To(BeTrue())
+	g.Expect(limiter.InFlight()).To(Equal(int64(3)))
+	g.Expect(limiter.TryAcquire(1)).To(BeFalse())
+
+	limiter.Release(2)
+	g.Expect(limiter.TryAcquire(2)).To(BeTrue())
+	g.Expect(limiter.TryAcquire(4)).To(BeFalse())
+
+	limiter.Release(3)
+	g.Expect(limiter.InFlight()).To(BeZero())
+	// A request heavier than the capacity is never allowed.
+	g.Expect(limiter.TryAcquire(4)).To(BeFalse())
+}
+
+// TestWithConcurrencyLimiterClampsWeight tests that the weight of a service is clamped to the capacity of the
+// concurrency limiter, so that its requests are not starved forever.
+func TestWithConcurrencyLimiterClampsWeight(t *testing.T) {
+	g := NewWithT(t)
+	limiter := NewConcurrencyLimiter(2)
+
+	s := New(nil, nil, nil, WithConcurrencyLimiter(limiter, 5))
+	g.Expect(s.concurrencyWeight).To(Equal(int64(2)))
+
+	release, ok := s.acquireRequest()
+	g.Expect(ok).To(BeTrue())
+	g.Expect(limiter.InFlight()).To(Equal(int64(2)))
+	release()
+	g.Expect(limiter.InFlight()).To(BeZero())
+
+	s = New(nil, nil, nil, WithConcurrencyLimiter(limiter, 0))
+	g.Expect(s.concurrencyWeight).To(Equal(int64(1)))
+}
+
+// TestCreateResourceConcurrencyLimited tests that CreateResource requeues rather than creating a resource while the
+// requests in flight of the services sharing the concurrency limiter leave no room for its request.
+func TestCreateResourceConcurrencyLimited(t *testing.T) {
+	g := NewWithT(t)
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	scopeMock := newMockFutureScope(mockCtrl)
+	creatorMock := mock_async.NewMockCreator(mockCtrl)
+	limiter := NewConcurrencyLimiter(2)
+	spec := &resourceGroupSpec{name: "test-resource", resourceGroup: "test-group"}
+
+	scopeMock.EXPECT().GetLongRunningOperationState("test-resource", "test-service").Return(nil).Times(2)
+	creatorMock.EXPECT().Get(gomockinternal.AContext(), spec).Return(nil, fakeNotFoundError).Times(2)
+
+	// Another service holds the whole capacity, so the resource is not created.
+	g.Expect(limiter.TryAcquire(2)).To(BeTrue())
+	s := New(scopeMock, creatorMock, nil, WithConcurrencyLimiter(limiter, 2))
+	_, changed, err := s.CreateResource(context.TODO(), spec, "test-service")
+	g.Expect(changed).To(BeFalse())
+	g.Expect(err).To(MatchError(ContainSubstring("too many Azure requests in flight, waiting to start PUT operation on resource test-group/test-resource (service: test-service)")))
+	g.Expect(azure.IsConcurrencyLimitedError(err)).To(BeTrue())
+	var reconcileErr azure.ReconcileError
+	g.Expect(errors.As(err, &reconcileErr)).To(BeTrue())
+	g.Expect(reconcileErr.IsTransient()).To(BeTrue())
+	g.Expect(reconcileErr.RequeueAfter()).To(Equal(reconciler.DefaultReconcilerRequeue))
+
+	// Once the capacity is released, the resource is created and the weight of the request is released again.
+	limiter.Release(2)
+	creatorMock.EXPECT().CreateOrUpdateAsync(gomockinternal.AContext(), spec, &fakeResourceParameters).DoAndReturn(
+		func(_ context.Context, _ azure.ResourceSpecGetter, _ interface{}) (interface{}, interface{}, error) {
+			g.Expect(limiter.InFlight()).To(Equal(int64(2)))
+			return "test-resource", nil, nil
+		})
+	result, changed, err := s.CreateResource(context.TODO(), spec, "test-service")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(changed).To(BeTrue())
+	g.Expect(result).To(Equal("test-resource"))
+	g.Expect(limiter.InFlight()).To(BeZero())
+}
+
+// TestDeleteResourceConcurrencyLimited tests that DeleteResource requeues rather than deleting a resource while the
+// requests in flight of the services sharing the concurrency limiter leave no room for its request.
+func TestDeleteResourceConcurrencyLimited(t *testing.T) {
+	g := NewWithT(t)
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	scopeMock := newMockFutureScope(mockCtrl)
+	deleterMock := mock_async.NewMockDeleter(mockCtrl)
+	limiter := NewConcurrencyLimiter(1)
+	spec := testSpec("test-resource")
+
+	scopeMock.EXPECT().GetLongRunningOperationState("test-resource", "test-service").Return(nil).Times(2)
+
+	g.Expect(limiter.TryAcquire(1)).To(BeTrue())
+	s := New(scopeMock, nil, deleterMock, WithConcurrencyLimiter(limiter, 0))
+	_, err := s.DeleteResource(context.TODO(), spec, "test-service")
+	g.Expect(err).To(MatchError(ContainSubstring("too many Azure requests in flight, waiting to start DELETE operation on resource test-group/test-resource (service: test-service)")))
+	g.Expect(azure.IsConcurrencyLimitedError(err)).To(BeTrue())
+
+	limiter.Release(1)
+	deleterMock.EXPECT().DeleteAsync(gomockinternal.AContext(), spec).Return(nil, nil)
+	_, err = s.DeleteResource(context.TODO(), spec, "test-service")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(limiter.InFlight()).To(BeZero())
+}
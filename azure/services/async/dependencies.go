@@ -0,0 +1,141 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package async
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/util/reconciler"
+)
+
+// OrderSpecs returns the specs sorted so that each spec comes after the specs of the list it depends on, see
+// azure.DependentSpecGetter. Specs keep their relative order otherwise. Dependencies on resources that are not in the
+// list, e.g. managed by another service, are ignored. It returns a terminal error if the specs have circular dependencies.
+func OrderSpecs(specs []azure.ResourceSpecGetter, serviceName string) ([]azure.ResourceSpecGetter, error) {
+	inList := specKeys(specs, serviceName)
+	ordered := make([]azure.ResourceSpecGetter, 0, len(specs))
+	placed := make(map[string]bool, len(specs))
+	remaining := specs
+	for len(remaining) > 0 {
+		// Place the first remaining spec whose dependencies are placed, so that a spec comes right after the specs it
+		// depends on.
+		next := -1
+		for i, spec := range remaining {
+			ready := true
+			for _, ref := range dependencies(spec) {
+				if key := strings.ToLower(ref); inList[key] && !placed[key] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			names := make([]string, len(remaining))
+			for i, spec := range remaining {
+				names[i] = spec.ResourceName()
+			}
+			return nil, azure.WithTerminalError(errors.Errorf("resources %s (service: %s) have circular dependencies", strings.Join(names, ", "), serviceName))
+		}
+		spec := remaining[next]
+		ordered = append(ordered, spec)
+		placed[dependencyKey(serviceName, spec.ResourceGroupName(), spec.ResourceName())] = true
+		remaining = append(remaining[:next:next], remaining[next+1:]...)
+	}
+	return ordered, nil
+}
+
+// ReconcileInOrder calls fn for each spec in the order returned by OrderSpecs and returns the most pressing error: an
+// error that is not an OperationNotDoneError takes precedence over an OperationNotDoneError. A spec is skipped with an
+// OperationNotDoneError until the specs of the list it depends on succeeded and the resources it depends on outside of
+// the list, e.g. managed by another service, have no long running operation in progress.
+func ReconcileInOrder(scope FutureScope, specs []azure.ResourceSpecGetter, serviceName string, fn func(azure.ResourceSpecGetter) error) error {
+	ordered, err := OrderSpecs(specs, serviceName)
+	if err != nil {
+		return err
+	}
+	inList := specKeys(specs, serviceName)
+	ready := make(map[string]bool, len(specs))
+	var result error
+	for _, spec := range ordered {
+		var pending []string
+		for _, ref := range dependencies(spec) {
+			if inList[strings.ToLower(ref)] {
+				if !ready[strings.ToLower(ref)] {
+					pending = append(pending, ref)
+				}
+			} else if operationInProgress(scope, ref) {
+				pending = append(pending, ref)
+			}
+		}
+
+		if len(pending) > 0 {
+			future := &infrav1.Future{Type: infrav1.PutFuture, ServiceName: serviceName, Name: spec.ResourceName(), ResourceGroup: spec.ResourceGroupName()}
+			err = azure.WithTransientError(errors.Wrapf(azure.NewOperationNotDoneError(future), "waiting for dependencies %s", strings.Join(pending, ", ")), reconciler.DefaultReconcilerRequeue)
+		} else {
+			err = fn(spec)
+		}
+		if err == nil {
+			ready[dependencyKey(serviceName, spec.ResourceGroupName(), spec.ResourceName())] = true
+		} else if !azure.IsOperationNotDoneError(err) || result == nil {
+			result = err
+		}
+	}
+	return result
+}
+
+// dependencies returns the references of the resources the spec depends on, or nil if it is not a DependentSpecGetter.
+func dependencies(spec azure.ResourceSpecGetter) []string {
+	if dependent, ok := spec.(azure.DependentSpecGetter); ok {
+		return dependent.DependsOn()
+	}
+	return nil
+}
+
+// operationInProgress returns true if a long running operation state is stored for the referenced resource. Resources
+// with the same name in different resource groups are only told apart if the scope is a ResourceGroupFutureScope.
+func operationInProgress(scope FutureScope, ref string) bool {
+	parts := strings.SplitN(ref, "/", 3)
+	if len(parts) != 3 {
+		return false
+	}
+	serviceName, rgName, resourceName := parts[0], parts[1], parts[2]
+	if rgScope, ok := scope.(ResourceGroupFutureScope); ok {
+		return rgScope.GetLongRunningOperationStateInResourceGroup(resourceName, serviceName, rgName) != nil
+	}
+	return scope.GetLongRunningOperationState(resourceName, serviceName) != nil
+}
+
+// specKeys returns the set of the keys of the resources of the specs.
+func specKeys(specs []azure.ResourceSpecGetter, serviceName string) map[string]bool {
+	keys := make(map[string]bool, len(specs))
+	for _, spec := range specs {
+		keys[dependencyKey(serviceName, spec.ResourceGroupName(), spec.ResourceName())] = true
+	}
+	return keys
+}
+
+// dependencyKey returns the key of a resource reference. Azure resource names are case-insensitive.
+func dependencyKey(serviceName, rgName, resourceName string) string {
+	return strings.ToLower(azure.DependencyRef(serviceName, rgName, resourceName))
+}
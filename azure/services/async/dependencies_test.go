@@ -0,0 +1,190 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package async
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+)
+
+// dependentSpec is a spec of the test-service service depending on the resources of dependsOn.
+type dependentSpec struct {
+	name      string
+	dependsOn []string
+}
+
+func (s *dependentSpec) ResourceName() string      { return s.name }
+func (s *dependentSpec) ResourceGroupName() string { return "test-group" }
+func (s *dependentSpec) OwnerResourceName() string { return "" }
+func (s *dependentSpec) Parameters(existing interface{}) (interface{}, error) {
+	return nil, nil
+}
+func (s *dependentSpec) DependsOn() []string { return s.dependsOn }
+
+func specNames(specs []azure.ResourceSpecGetter) []string {
+	names := make([]string, len(specs))
+	for i, spec := range specs {
+		names[i] = spec.ResourceName()
+	}
+	return names
+}
+
+func TestOrderSpecs(t *testing.T) {
+	testcases := []struct {
+		name          string
+		specs         []azure.ResourceSpecGetter
+		expected      []string
+		expectedError string
+	}{
+		{
+			name: "specs without dependencies keep their order",
+			specs: []azure.ResourceSpecGetter{
+				&dependentSpec{name: "b"},
+				&dependentSpec{name: "a"},
+			},
+			expected: []string{"b", "a"},
+		},
+		{
+			name: "specs come after their dependencies",
+			specs: []azure.ResourceSpecGetter{
+				&dependentSpec{name: "subnet", dependsOn: []string{"test-service/test-group/NSG"}},
+				&dependentSpec{name: "other"},
+				&dependentSpec{name: "nsg", dependsOn: []string{"test-service/test-group/rules"}},
+				&dependentSpec{name: "rules"},
+			},
+			expected: []string{"other", "rules", "nsg", "subnet"},
+		},
+		{
+			name: "dependencies on resources of other services are ignored",
+			specs: []azure.ResourceSpecGetter{
+				&dependentSpec{name: "subnet", dependsOn: []string{"securitygroups/test-group/nsg"}},
+				&dependentSpec{name: "nsg"},
+			},
+			expected: []string{"subnet", "nsg"},
+		},
+		{
+			name: "circular dependencies",
+			specs: []azure.ResourceSpecGetter{
+				&dependentSpec{name: "a", dependsOn: []string{"test-service/test-group/c"}},
+				&dependentSpec{name: "b"},
+				&dependentSpec{name: "c", dependsOn: []string{"test-service/test-group/a"}},
+			},
+			expectedError: "resources a, c (service: test-service) have circular dependencies",
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			ordered, err := OrderSpecs(tc.specs, "test-service")
+			if tc.expectedError != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tc.expectedError)))
+				g.Expect(azure.IsTerminalError(err)).To(BeTrue())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(specNames(ordered)).To(Equal(tc.expected))
+		})
+	}
+}
+
+func TestReconcileInOrder(t *testing.T) {
+	g := NewWithT(t)
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	scopeMock := newMockFutureScope(mockCtrl)
+
+	specs := []azure.ResourceSpecGetter{
+		&dependentSpec{name: "subnet", dependsOn: []string{"test-service/test-group/nsg"}},
+		&dependentSpec{name: "nsg"},
+		&dependentSpec{name: "other-subnet", dependsOn: []string{"routetables/test-group/rt"}},
+		&dependentSpec{name: "third-subnet", dependsOn: []string{"routetables/test-group/other-rt"}},
+	}
+	scopeMock.EXPECT().GetLongRunningOperationState("rt", "routetables").Return(&infrav1.Future{Type: infrav1.PutFuture})
+	scopeMock.EXPECT().GetLongRunningOperationState("other-rt", "routetables").Return(nil)
+
+	// The security group fails, so the subnet depending on it is skipped, as is the subnet whose route table is
+	// still being created. The error of the security group takes precedence.
+	var reconciled []string
+	err := ReconcileInOrder(scopeMock, specs, "test-service", func(spec azure.ResourceSpecGetter) error {
+		reconciled = append(reconciled, spec.ResourceName())
+		if spec.ResourceName() == "nsg" {
+			return errors.New("failed to create nsg")
+		}
+		return nil
+	})
+	g.Expect(err).To(MatchError("failed to create nsg"))
+	g.Expect(reconciled).To(Equal([]string{"nsg", "third-subnet"}))
+
+	// Once the security group succeeds, the subnet depending on it is reconciled after it.
+	scopeMock.EXPECT().GetLongRunningOperationState("rt", "routetables").Return(nil)
+	scopeMock.EXPECT().GetLongRunningOperationState("other-rt", "routetables").Return(nil)
+	reconciled = nil
+	err = ReconcileInOrder(scopeMock, specs, "test-service", func(spec azure.ResourceSpecGetter) error {
+		reconciled = append(reconciled, spec.ResourceName())
+		return nil
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(reconciled).To(Equal([]string{"nsg", "subnet", "other-subnet", "third-subnet"}))
+}
+
+func TestReconcileInOrderWaitsForDependencies(t *testing.T) {
+	g := NewWithT(t)
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	scopeMock := newMockFutureScope(mockCtrl)
+
+	specs := []azure.ResourceSpecGetter{&dependentSpec{name: "subnet", dependsOn: []string{"securitygroups/test-group/nsg"}}}
+	scopeMock.EXPECT().GetLongRunningOperationState("nsg", "securitygroups").Return(&infrav1.Future{Type: infrav1.PutFuture})
+
+	err := ReconcileInOrder(scopeMock, specs, "test-service", func(spec azure.ResourceSpecGetter) error {
+		return errors.New("should not be called")
+	})
+	g.Expect(azure.IsOperationNotDoneError(err)).To(BeTrue())
+	g.Expect(err.Error()).To(ContainSubstring("waiting for dependencies securitygroups/test-group/nsg"))
+}
+
+// TestReconcileInOrderWaitsForDependenciesInResourceGroup tests that a spec only waits for the resource it depends on in
+// the resource group of the reference, and not for a resource with the same name in another resource group.
+func TestReconcileInOrderWaitsForDependenciesInResourceGroup(t *testing.T) {
+	g := NewWithT(t)
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	scope := &resourceGroupFutureScope{MockFutureScope: newMockFutureScope(mockCtrl), cluster: &infrav1.AzureCluster{}}
+	scope.SetLongRunningOperationState(&infrav1.Future{Type: infrav1.PutFuture, ServiceName: "securitygroups", Name: "nsg", ResourceGroup: "network-group"})
+
+	var reconciled []string
+	fn := func(spec azure.ResourceSpecGetter) error {
+		reconciled = append(reconciled, spec.ResourceName())
+		return nil
+	}
+	specs := []azure.ResourceSpecGetter{&dependentSpec{name: "subnet", dependsOn: []string{"securitygroups/test-group/nsg"}}}
+	g.Expect(ReconcileInOrder(scope, specs, "test-service", fn)).To(Succeed())
+	g.Expect(reconciled).To(Equal([]string{"subnet"}))
+
+	specs = []azure.ResourceSpecGetter{&dependentSpec{name: "other-subnet", dependsOn: []string{"securitygroups/network-group/nsg"}}}
+	err := ReconcileInOrder(scope, specs, "test-service", fn)
+	g.Expect(azure.IsOperationNotDoneError(err)).To(BeTrue())
+	g.Expect(err.Error()).To(ContainSubstring("waiting for dependencies securitygroups/network-group/nsg"))
+	g.Expect(reconciled).To(Equal([]string{"subnet"}))
+}
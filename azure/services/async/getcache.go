@@ -0,0 +1,90 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package async
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+)
+
+type getCacheKey struct{}
+
+// getCache holds the resources got by the services of a single reconcile, keyed by service and resource.
+type getCache struct {
+	mu        sync.Mutex
+	resources map[string]interface{}
+}
+
+// WithGetCache returns a context with a cache of the resources got by the services using it, so that a resource read
+// several times by the same service during one reconcile, e.g. by GetResource and then by CreateResource, or by the
+// existence check before a delete, is only read from Azure once. Entries are keyed by service and resource, so reads
+// of different resources, or of the same resource by different services, are not deduplicated. The cache lives as
+// long as the context, which should be the context of a single reconcile so that resources are read again on the next
+// one. A resource is evicted from the cache once an operation is started or completed on it, and the cached resources
+// must not be modified.
+func WithGetCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, getCacheKey{}, &getCache{resources: make(map[string]interface{})})
+}
+
+// getCacheFromContext returns the cache of ctx, or nil if ctx has none.
+func getCacheFromContext(ctx context.Context) *getCache {
+	cache, _ := ctx.Value(getCacheKey{}).(*getCache)
+	return cache
+}
+
+// resourceID returns the key of the resource of the spec in the cache. The service name is part of it since resources
+// of different types can have the same name in the same resource group.
+func resourceID(spec azure.ResourceSpecGetter, serviceName string) string {
+	return strings.ToLower(serviceName) + "/" + resourceKey(spec)
+}
+
+// get gets the resource of the spec, from the cache of ctx if it was already got during the reconcile. Only the
+// resources that were found are cached.
+func (s *Service) get(ctx context.Context, spec azure.ResourceSpecGetter, serviceName string) (interface{}, error) {
+	cache := getCacheFromContext(ctx)
+	if cache == nil {
+		return s.getter().Get(ctx, spec)
+	}
+	id := resourceID(spec, serviceName)
+	cache.mu.Lock()
+	resource, ok := cache.resources[id]
+	cache.mu.Unlock()
+	if ok {
+		return resource, nil
+	}
+
+	resource, err := s.getter().Get(ctx, spec)
+	if err != nil {
+		return resource, err
+	}
+	cache.mu.Lock()
+	cache.resources[id] = resource
+	cache.mu.Unlock()
+	return resource, nil
+}
+
+// evict removes the resource of the spec from the cache of ctx, e.g. because an operation changed it.
+func evict(ctx context.Context, spec azure.ResourceSpecGetter, serviceName string) {
+	if cache := getCacheFromContext(ctx); cache != nil {
+		cache.mu.Lock()
+		delete(cache.resources, resourceID(spec, serviceName))
+		cache.mu.Unlock()
+	}
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package async
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/mock_azure"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/async/mock_async"
+	gomockinternal "sigs.k8s.io/cluster-api-provider-azure/internal/test/matchers/gomock"
+)
+
+func TestGetCache(t *testing.T) {
+	testcases := []struct {
+		name   string
+		ctx    func() context.Context
+		expect func(c *mock_async.MockCreatorMockRecorder, spec *mock_azure.MockResourceSpecGetter)
+		run    func(g *WithT, ctx context.Context, s *Service, spec *mock_azure.MockResourceSpecGetter)
+	}{
+		{
+			name: "resource is got once per reconcile",
+			ctx:  func() context.Context { return WithGetCache(context.TODO()) },
+			expect: func(c *mock_async.MockCreatorMockRecorder, spec *mock_azure.MockResourceSpecGetter) {
+				c.Get(gomockinternal.AContext(), spec).Return(&fakeExistingResource, nil).Times(1)
+			},
+			run: func(g *WithT, ctx context.Context, s *Service, spec *mock_azure.MockResourceSpecGetter) {
+				for i := 0; i < 2; i++ {
+					result, err := s.GetResource(ctx, spec, "test-service")
+					g.Expect(err).NotTo(HaveOccurred())
+					g.Expect(result).To(Equal(&fakeExistingResource))
+				}
+			},
+		},
+		{
+			name: "resource is got every time without a cache",
+			ctx:  context.TODO,
+			expect: func(c *mock_async.MockCreatorMockRecorder, spec *mock_azure.MockResourceSpecGetter) {
+				c.Get(gomockinternal.AContext(), spec).Return(&fakeExistingResource, nil).Times(2)
+			},
+			run: func(g *WithT, ctx context.Context, s *Service, spec *mock_azure.MockResourceSpecGetter) {
+				for i := 0; i < 2; i++ {
+					_, err := s.GetResource(ctx, spec, "test-service")
+					g.Expect(err).NotTo(HaveOccurred())
+				}
+			},
+		},
+		{
+			name: "resource that is not found is not cached",
+			ctx:  func() context.Context { return WithGetCache(context.TODO()) },
+			expect: func(c *mock_async.MockCreatorMockRecorder, spec *mock_azure.MockResourceSpecGetter) {
+				c.Get(gomockinternal.AContext(), spec).Return(nil, fakeNotFoundError).Times(2)
+			},
+			run: func(g *WithT, ctx context.Context, s *Service, spec *mock_azure.MockResourceSpecGetter) {
+				for i := 0; i < 2; i++ {
+					_, err := s.GetResource(ctx, spec, "test-service")
+					g.Expect(err).To(MatchError(ContainSubstring("resource not found")))
+				}
+			},
+		},
+		{
+			name: "resources of other services are cached separately",
+			ctx:  func() context.Context { return WithGetCache(context.TODO()) },
+			expect: func(c *mock_async.MockCreatorMockRecorder, spec *mock_azure.MockResourceSpecGetter) {
+				c.Get(gomockinternal.AContext(), spec).Return(&fakeExistingResource, nil).Times(2)
+			},
+			run: func(g *WithT, ctx context.Context, s *Service, spec *mock_azure.MockResourceSpecGetter) {
+				for _, serviceName := range []string{"test-service", "other-service"} {
+					_, err := s.GetResource(ctx, spec, serviceName)
+					g.Expect(err).NotTo(HaveOccurred())
+				}
+			},
+		},
+		{
+			name: "resource is evicted once it is updated",
+			ctx:  func() context.Context { return WithGetCache(context.TODO()) },
+			expect: func(c *mock_async.MockCreatorMockRecorder, spec *mock_azure.MockResourceSpecGetter) {
+				c.Get(gomockinternal.AContext(), spec).Return(&fakeExistingResource, nil).Times(2)
+				spec.EXPECT().Parameters(&fakeExistingResource).Return(&fakeResourceParameters, nil)
+				c.CreateOrUpdateAsync(gomockinternal.AContext(), spec, &fakeResourceParameters).Return(&fakeExistingResource, nil, nil)
+			},
+			run: func(g *WithT, ctx context.Context, s *Service, spec *mock_azure.MockResourceSpecGetter) {
+				_, changed, err := s.CreateResource(ctx, spec, "test-service")
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(changed).To(BeTrue())
+				_, err = s.GetResource(ctx, spec, "test-service")
+				g.Expect(err).NotTo(HaveOccurred())
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			scopeMock := newMockFutureScope(mockCtrl)
+			creatorMock := mock_async.NewMockCreator(mockCtrl)
+			specMock := mock_azure.NewMockResourceSpecGetter(mockCtrl)
+
+			specMock.EXPECT().ResourceName().Return("test-resource").AnyTimes()
+			specMock.EXPECT().ResourceGroupName().Return("test-group").AnyTimes()
+			specMock.EXPECT().OwnerResourceName().Return("").AnyTimes()
+			scopeMock.EXPECT().GetLongRunningOperationState("test-resource", "test-service").Return(nil).AnyTimes()
+			tc.expect(creatorMock.EXPECT(), specMock)
+
+			s := New(scopeMock, creatorMock, nil)
+			tc.run(g, tc.ctx(), s, specMock)
+		})
+	}
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package async
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
+)
+
+// PreOpHook is called right before a create, update or delete request is made for the resource of the spec, e.g. to
+// audit the operation. The operation type is infrav1.PutFuture, infrav1.PatchFuture or infrav1.DeleteFuture. A non-nil
+// error aborts the operation and is returned to the service.
+type PreOpHook func(ctx context.Context, spec azure.ResourceSpecGetter, serviceName, operationType string) error
+
+// PostOpHook is called once an operation on the resource of the spec completed, successfully or not, e.g. to notify an
+// external system. The result is nil for deletes. It is not called while a long-running operation is in progress.
+type PostOpHook func(ctx context.Context, spec azure.ResourceSpecGetter, serviceName, operationType string, result interface{}, err error)
+
+// runPreOpHook calls the pre-operation hook, if one is configured, and returns its error wrapped.
+func (s *Service) runPreOpHook(ctx context.Context, spec azure.ResourceSpecGetter, resourceName, rgName, serviceName, operationType string) error {
+	if s.preOpHook == nil {
+		return nil
+	}
+	if err := s.preOpHook(ctx, spec, serviceName, operationType); err != nil {
+		return errors.Wrapf(err, "pre-operation hook aborted %s operation on resource %s/%s (service: %s)", operationType, rgName, resourceName, serviceName)
+	}
+	return nil
+}
+
+// runPostOpHook calls the post-operation hook, if one is configured and an operation of the given type completed, i.e.
+// the operation type is set and err is not an OperationNotDoneError.
+func (s *Service) runPostOpHook(ctx context.Context, spec azure.ResourceSpecGetter, serviceName, operationType string, result interface{}, err error) {
+	if s.postOpHook == nil || operationType == "" || azure.IsOperationNotDoneError(err) {
+		return
+	}
+	s.postOpHook(ctx, spec, serviceName, operationType, result, err)
+}
+
+// AuditLogHooks returns operation hooks logging each create, update or delete request made for a resource and the
+// outcome of each operation, so that the controller logs keep an audit trail of the changes made to Azure resources.
+func AuditLogHooks() (PreOpHook, PostOpHook) {
+	pre := func(ctx context.Context, spec azure.ResourceSpecGetter, serviceName, operationType string) error {
+		_, log, done := tele.StartSpanWithLogger(ctx, "async.AuditLogHooks.pre")
+		defer done()
+
+		log.Info("starting operation", "operation", operationType, "service", serviceName, "resourceGroup", spec.ResourceGroupName(), "resource", spec.ResourceName())
+		return nil
+	}
+	post := func(ctx context.Context, spec azure.ResourceSpecGetter, serviceName, operationType string, _ interface{}, err error) {
+		_, log, done := tele.StartSpanWithLogger(ctx, "async.AuditLogHooks.post")
+		defer done()
+
+		if err != nil {
+			log.Info("operation failed", "operation", operationType, "service", serviceName, "resourceGroup", spec.ResourceGroupName(), "resource", spec.ResourceName(), "error", err.Error())
+			return
+		}
+		log.Info("operation completed", "operation", operationType, "service", serviceName, "resourceGroup", spec.ResourceGroupName(), "resource", spec.ResourceName())
+	}
+	return pre, post
+}
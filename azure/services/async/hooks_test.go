@@ -0,0 +1,161 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package async
+
+import (
+	"context"
+	"testing"
+
+	azureautorest "github.com/Azure/go-autorest/autorest/azure"
+	"github.com/go-logr/logr/funcr"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/mock_azure"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/async/mock_async"
+	gomockinternal "sigs.k8s.io/cluster-api-provider-azure/internal/test/matchers/gomock"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// hookCall records a call to an operation hook.
+type hookCall struct {
+	hook          string
+	serviceName   string
+	operationType string
+	result        interface{}
+	err           error
+}
+
+// recordingHooks returns operation hooks recording their calls, the pre-operation hook returning preErr.
+func recordingHooks(calls *[]hookCall, preErr error) (PreOpHook, PostOpHook) {
+	pre := func(_ context.Context, _ azure.ResourceSpecGetter, serviceName, operationType string) error {
+		*calls = append(*calls, hookCall{hook: "pre", serviceName: serviceName, operationType: operationType})
+		return preErr
+	}
+	post := func(_ context.Context, _ azure.ResourceSpecGetter, serviceName, operationType string, result interface{}, err error) {
+		*calls = append(*calls, hookCall{hook: "post", serviceName: serviceName, operationType: operationType, result: result, err: err})
+	}
+	return pre, post
+}
+
+// TestCreateResourceCallsOperationHooks tests that the operation hooks are called around a create that completes right away.
+func TestCreateResourceCallsOperationHooks(t *testing.T) {
+	g := NewWithT(t)
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	scopeMock := newMockFutureScope(mockCtrl)
+	creatorMock := mock_async.NewMockCreator(mockCtrl)
+	specMock := mock_azure.NewMockResourceSpecGetter(mockCtrl)
+
+	specMock.EXPECT().ResourceName().Return("test-resource")
+	specMock.EXPECT().ResourceGroupName().Return("test-group")
+	scopeMock.EXPECT().GetLongRunningOperationState("test-resource", "test-service").Return(nil)
+	creatorMock.EXPECT().Get(gomockinternal.AContext(), specMock).Return(nil, fakeNotFoundError)
+	specMock.EXPECT().Parameters(nil).Return(&fakeResourceParameters, nil)
+	creatorMock.EXPECT().CreateOrUpdateAsync(gomockinternal.AContext(), specMock, &fakeResourceParameters).Return("test-resource", nil, nil)
+
+	var calls []hookCall
+	s := New(scopeMock, creatorMock, nil, WithOperationHooks(recordingHooks(&calls, nil)))
+	_, _, err := s.CreateResource(context.TODO(), specMock, "test-service")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(calls).To(Equal([]hookCall{
+		{hook: "pre", serviceName: "test-service", operationType: infrav1.PutFuture},
+		{hook: "post", serviceName: "test-service", operationType: infrav1.PutFuture, result: "test-resource"},
+	}))
+}
+
+// TestPreOpHookAbortsOperation tests that an error of the pre-operation hook aborts the operation.
+func TestPreOpHookAbortsOperation(t *testing.T) {
+	g := NewWithT(t)
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	scopeMock := newMockFutureScope(mockCtrl)
+	deleterMock := mock_async.NewMockDeleter(mockCtrl)
+	specMock := mock_azure.NewMockResourceSpecGetter(mockCtrl)
+
+	specMock.EXPECT().ResourceName().Return("test-resource")
+	specMock.EXPECT().ResourceGroupName().Return("test-group")
+	scopeMock.EXPECT().GetLongRunningOperationState("test-resource", "test-service").Return(nil)
+
+	var calls []hookCall
+	s := New(scopeMock, nil, deleterMock, WithOperationHooks(recordingHooks(&calls, errors.New("change freeze"))))
+	_, err := s.DeleteResource(context.TODO(), specMock, "test-service")
+	g.Expect(err).To(MatchError("pre-operation hook aborted DELETE operation on resource test-group/test-resource (service: test-service): change freeze"))
+	g.Expect(calls).To(Equal([]hookCall{{hook: "pre", serviceName: "test-service", operationType: infrav1.DeleteFuture}}))
+}
+
+// TestPostOpHookWaitsForOperationCompletion tests that the post-operation hook is only called once a long-running
+// operation completed.
+func TestPostOpHookWaitsForOperationCompletion(t *testing.T) {
+	g := NewWithT(t)
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	scopeMock := newMockFutureScope(mockCtrl)
+	deleterMock := mock_async.NewMockDeleter(mockCtrl)
+	specMock := mock_azure.NewMockResourceSpecGetter(mockCtrl)
+
+	var calls []hookCall
+	s := New(scopeMock, nil, deleterMock, WithOperationHooks(recordingHooks(&calls, nil)))
+	s.operations = newOperationTracker()
+
+	specMock.EXPECT().ResourceName().Return("test-resource").Times(2)
+	specMock.EXPECT().ResourceGroupName().Return("test-group").Times(2)
+	scopeMock.EXPECT().GetLongRunningOperationState("test-resource", "test-service").Return(&validDeleteFuture).Times(4)
+	deleterMock.EXPECT().IsDone(gomockinternal.AContext(), gomock.AssignableToTypeOf(&azureautorest.Future{})).Return(false, nil)
+	scopeMock.EXPECT().SetLongRunningOperationState(gomock.Any()).AnyTimes()
+	_, err := s.DeleteResource(context.TODO(), specMock, "test-service")
+	g.Expect(azure.IsOperationNotDoneError(err)).To(BeTrue())
+	g.Expect(calls).To(BeEmpty())
+
+	deleterMock.EXPECT().IsDone(gomockinternal.AContext(), gomock.AssignableToTypeOf(&azureautorest.Future{})).Return(true, nil)
+	deleterMock.EXPECT().Result(gomockinternal.AContext(), gomock.AssignableToTypeOf(&azureautorest.Future{}), infrav1.DeleteFuture).Return(nil, nil)
+	scopeMock.EXPECT().DeleteLongRunningOperationState("test-resource", "test-service")
+	_, err = s.DeleteResource(context.TODO(), specMock, "test-service")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(calls).To(Equal([]hookCall{{hook: "post", serviceName: "test-service", operationType: infrav1.DeleteFuture}}))
+}
+
+// TestAuditLogHooks tests that the audit log hooks log the operations and their outcome.
+func TestAuditLogHooks(t *testing.T) {
+	g := NewWithT(t)
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	specMock := mock_azure.NewMockResourceSpecGetter(mockCtrl)
+	specMock.EXPECT().ResourceName().Return("test-resource").AnyTimes()
+	specMock.EXPECT().ResourceGroupName().Return("test-group").AnyTimes()
+
+	var logs []string
+	logger := funcr.New(func(prefix, args string) {
+		logs = append(logs, args)
+	}, funcr.Options{})
+	ctx := log.IntoContext(context.TODO(), logger)
+
+	pre, post := AuditLogHooks()
+	g.Expect(pre(ctx, specMock, "test-service", infrav1.PutFuture)).To(Succeed())
+	post(ctx, specMock, "test-service", infrav1.PutFuture, "test-resource", nil)
+	post(ctx, specMock, "test-service", infrav1.DeleteFuture, nil, errors.New("foo"))
+
+	g.Expect(logs).To(HaveLen(3))
+	g.Expect(logs[0]).To(ContainSubstring(`"msg"="starting operation"`))
+	g.Expect(logs[0]).To(ContainSubstring(`"operation"="PUT" "service"="test-service" "resourceGroup"="test-group" "resource"="test-resource"`))
+	g.Expect(logs[1]).To(ContainSubstring(`"msg"="operation completed"`))
+	g.Expect(logs[1]).To(ContainSubstring(`"operation"="PUT" "service"="test-service" "resourceGroup"="test-group" "resource"="test-resource"`))
+	g.Expect(logs[2]).To(ContainSubstring(`"msg"="operation failed"`))
+	g.Expect(logs[2]).To(ContainSubstring(`"operation"="DELETE" "service"="test-service" "resourceGroup"="test-group" "resource"="test-resource" "error"="foo"`))
+}
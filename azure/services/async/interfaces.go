@@ -20,6 +20,7 @@ import (
 	"context"
 
 	azureautorest "github.com/Azure/go-autorest/autorest/azure"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 	"sigs.k8s.io/cluster-api-provider-azure/azure"
 )
 
@@ -28,6 +29,35 @@ type FutureScope interface {
 	azure.AsyncStatusUpdater
 }
 
+// AppliedParametersScope is implemented by FutureScopes that can persist, across controller restarts, the parameters
+// last applied to each resource. Resources with the same name in different resource groups are recorded separately.
+type AppliedParametersScope interface {
+	// AppliedParameters returns the hash of the parameters last applied to the resource and the hash of the resource
+	// as Azure returned it then, or empty strings if they are unknown.
+	AppliedParameters(name, service, resourceGroup string) (parametersHash, resourceHash string)
+	// SetAppliedParameters records the hash of the parameters applied to the resource and the hash of the result.
+	SetAppliedParameters(name, service, resourceGroup, parametersHash, resourceHash string)
+	// DeleteAppliedParameters forgets the parameters applied to the resource.
+	DeleteAppliedParameters(name, service, resourceGroup string)
+}
+
+// ResourceGroupFutureScope is implemented by FutureScopes that can tell apart the long-running operation states of
+// resources with the same name in different resource groups, e.g. security groups in the resource group of the cluster
+// and in a networking resource group.
+type ResourceGroupFutureScope interface {
+	// GetLongRunningOperationStateInResourceGroup gets the future of the resource in the given resource group.
+	GetLongRunningOperationStateInResourceGroup(name, service, resourceGroup string) *infrav1.Future
+	// DeleteLongRunningOperationStateInResourceGroup deletes the future of the resource in the given resource group.
+	DeleteLongRunningOperationStateInResourceGroup(name, service, resourceGroup string)
+}
+
+// CompletedOperationScope is implemented by FutureScopes that can keep a short-lived record of how long their
+// long-running operations took to complete, for post-mortem inspection.
+type CompletedOperationScope interface {
+	// SetCompletedOperation records a completed long-running operation.
+	SetCompletedOperation(operation infrav1.CompletedOperation)
+}
+
 // FutureHandler is a client that can check on the progress of a future.
 type FutureHandler interface {
 	// IsDone returns true if the operation is complete.
@@ -36,6 +66,35 @@ type FutureHandler interface {
 	Result(ctx context.Context, future azureautorest.FutureAPI, futureType string) (result interface{}, err error)
 }
 
+// PollerHandler is a client of the track2 SDK that can check on the progress of a long-running operation. Track2
+// operations return a runtime.Poller instead of a future, so they are stored as the resume token of their poller.
+type PollerHandler interface {
+	// ResumePoller resumes the poller of the operation stored in future from its resume token and polls it once.
+	// It returns true and the result of the operation if it is complete, or true and the error of the operation if it
+	// failed.
+	ResumePoller(ctx context.Context, future infrav1.Future, resumeToken string) (isDone bool, result interface{}, err error)
+}
+
+// FutureMigrator can convert a future stored in a format that is no longer supported into the current format.
+type FutureMigrator interface {
+	// MigrateFuture returns the future converted to the current format, or an error if it cannot be migrated.
+	MigrateFuture(future infrav1.Future) (*infrav1.Future, error)
+}
+
+// FailureResolver can find out why an operation failed from the Azure Activity Log.
+type FailureResolver interface {
+	// ResolveFailure returns the reason of the failure of the operations with the given correlation ID, or an empty
+	// string if it is unknown.
+	ResolveFailure(ctx context.Context, correlationID string) (reason string, err error)
+}
+
+// QuotaChecker can read the usage of the quotas of the subscription, see azure.QuotaSpecGetter.
+type QuotaChecker interface {
+	// Usage returns the current usage and the limit of the quota with the given name in the location. It returns false
+	// if the quota is unknown.
+	Usage(ctx context.Context, location, name string) (current, limit int64, found bool, err error)
+}
+
 // Getter is an interface that can get a resource.
 type Getter interface {
 	Get(ctx context.Context, spec azure.ResourceSpecGetter) (result interface{}, err error)
@@ -48,14 +107,62 @@ type Creator interface {
 	CreateOrUpdateAsync(ctx context.Context, spec azure.ResourceSpecGetter, parameters interface{}) (result interface{}, future azureautorest.FutureAPI, err error)
 }
 
+// Updater is implemented by Creators that can update an existing resource with a PATCH request, merging the parameters
+// into the resource instead of replacing it.
+type Updater interface {
+	// UpdateAsync sends a PATCH request with the parameters. Futures of the operation have type infrav1.PatchFuture
+	// and must be handled by the Creator's Result.
+	UpdateAsync(ctx context.Context, spec azure.ResourceSpecGetter, parameters interface{}) (result interface{}, future azureautorest.FutureAPI, err error)
+}
+
 // Deleter is a client that can delete a resource asynchronously.
 type Deleter interface {
 	FutureHandler
 	DeleteAsync(ctx context.Context, spec azure.ResourceSpecGetter) (future azureautorest.FutureAPI, err error)
 }
 
+// PollerCreator is a client of the track2 SDK that can create or update a resource asynchronously.
+type PollerCreator interface {
+	PollerHandler
+	Getter
+	// BeginCreateOrUpdate starts creating or updating the resource. It returns the result if the operation completed
+	// right away, or the resume token of its poller otherwise.
+	BeginCreateOrUpdate(ctx context.Context, spec azure.ResourceSpecGetter, parameters interface{}) (result interface{}, resumeToken string, err error)
+}
+
+// PollerDeleter is a client of the track2 SDK that can delete a resource asynchronously.
+type PollerDeleter interface {
+	PollerHandler
+	// BeginDelete starts deleting the resource. It returns an empty resume token if the operation completed right away,
+	// or the resume token of its poller otherwise.
+	BeginDelete(ctx context.Context, spec azure.ResourceSpecGetter) (resumeToken string, err error)
+}
+
+// Canceler is implemented by Deleters whose delete operations can be cancelled through the ARM operation-cancel
+// endpoint.
+type Canceler interface {
+	// Cancel cancels the operation tracked by the future.
+	Cancel(ctx context.Context, future azureautorest.FutureAPI) error
+}
+
+// ProvisioningStateGetter is implemented by Creators and PollerCreators that can tell the provisioning state of the
+// resources they return, e.g. Updating or Succeeded.
+type ProvisioningStateGetter interface {
+	// ProvisioningState returns the provisioning state of the resource, or false if it is unknown, e.g. because the
+	// SDK type of the resource does not expose one.
+	ProvisioningState(resource interface{}) (state string, ok bool)
+}
+
 // Reconciler is a generic interface used to perform asynchronous reconciliation of Azure resources.
 type Reconciler interface {
-	CreateResource(ctx context.Context, spec azure.ResourceSpecGetter, serviceName string) (result interface{}, err error)
-	DeleteResource(ctx context.Context, spec azure.ResourceSpecGetter, serviceName string) (err error)
+	// CreateResource creates or updates the resource of the spec. It returns true if a create or update request was
+	// sent to Azure, and false if the resource was up to date or an ongoing operation was checked on.
+	CreateResource(ctx context.Context, spec azure.ResourceSpecGetter, serviceName string) (result interface{}, changed bool, err error)
+	// DeleteResource deletes the resource of the spec. It returns true if the resource was already deleted, in which
+	// case no error is returned either.
+	DeleteResource(ctx context.Context, spec azure.ResourceSpecGetter, serviceName string) (notFound bool, err error)
+	GetResource(ctx context.Context, spec azure.ResourceSpecGetter, serviceName string) (result interface{}, err error)
+	// RefreshOperation completes the ongoing operation of the resource of the spec if the resource shows that it is
+	// done, e.g. when its stored state is stale. It returns true and the resource if the operation was completed.
+	RefreshOperation(ctx context.Context, spec azure.ResourceSpecGetter, serviceName string) (result interface{}, done bool, err error)
 }
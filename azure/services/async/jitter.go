@@ -0,0 +1,57 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package async
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// maxJitterFactor is the largest jitter factor, which spreads intervals between zero and twice their value.
+const maxJitterFactor = 1.0
+
+// jitter spreads requeue intervals randomly around their value. It is safe for concurrent use.
+type jitter struct {
+	// factor is the largest fraction of the interval added to or removed from it.
+	factor float64
+	lock   sync.Mutex
+	random *rand.Rand
+}
+
+// newJitter returns a jitter of up to ±factor of the intervals drawing from source, or from a source seeded with the
+// current time if source is nil.
+func newJitter(factor float64, source rand.Source) *jitter {
+	if factor > maxJitterFactor {
+		factor = maxJitterFactor
+	}
+	if source == nil {
+		source = rand.NewSource(time.Now().UnixNano())
+	}
+	return &jitter{factor: factor, random: rand.New(source)} //nolint:gosec // Jitter does not need a cryptographically secure source.
+}
+
+// apply returns the interval moved randomly by up to ±factor of its value.
+func (j *jitter) apply(interval time.Duration) time.Duration {
+	if j == nil || j.factor <= 0 || interval <= 0 {
+		return interval
+	}
+	j.lock.Lock()
+	r := j.random.Float64()
+	j.lock.Unlock()
+	return time.Duration(float64(interval) * (1 + j.factor*(2*r-1)))
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package async
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+// fixedSource is a rand.Source that always returns the same value.
+type fixedSource int64
+
+func (s fixedSource) Int63() int64 { return int64(s) }
+
+func (s fixedSource) Seed(int64) {}
+
+func TestJitter(t *testing.T) {
+	testcases := []struct {
+		name     string
+		jitter   *jitter
+		interval time.Duration
+		expected time.Duration
+	}{
+		{
+			name:     "no jitter",
+			jitter:   nil,
+			interval: 20 * time.Second,
+			expected: 20 * time.Second,
+		},
+		{
+			name:     "zero factor",
+			jitter:   newJitter(0, fixedSource(0)),
+			interval: 20 * time.Second,
+			expected: 20 * time.Second,
+		},
+		{
+			name:     "smallest random value removes the full factor",
+			jitter:   newJitter(0.1, fixedSource(0)),
+			interval: 20 * time.Second,
+			expected: 18 * time.Second,
+		},
+		{
+			name:     "middle random value keeps the interval",
+			jitter:   newJitter(0.1, fixedSource(1<<62)),
+			interval: 20 * time.Second,
+			expected: 20 * time.Second,
+		},
+		{
+			name:     "large random value adds to the interval",
+			jitter:   newJitter(0.1, fixedSource(3<<61)),
+			interval: 20 * time.Second,
+			expected: 21 * time.Second,
+		},
+		{
+			name:     "factor is capped",
+			jitter:   newJitter(5, fixedSource(0)),
+			interval: 20 * time.Second,
+			expected: 0,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			g.Expect(tc.jitter.apply(tc.interval)).To(Equal(tc.expected))
+		})
+	}
+}
+
+func TestJitterBounds(t *testing.T) {
+	g := NewWithT(t)
+	j := newJitter(0.2, rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		g.Expect(j.apply(15 * time.Second)).To(And(BeNumerically(">=", 12*time.Second), BeNumerically("<=", 18*time.Second)))
+	}
+}
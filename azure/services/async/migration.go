@@ -0,0 +1,62 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package async
+
+import (
+	"encoding/base64"
+
+	azureautorest "github.com/Azure/go-autorest/autorest/azure"
+	"github.com/pkg/errors"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+)
+
+// dataEncodings are the encodings future data may have been stored with, other than base64.URLEncoding, e.g. by tools
+// editing the long-running operation states or by a controller that encoded them differently.
+var dataEncodings = []*base64.Encoding{base64.StdEncoding, base64.RawURLEncoding, base64.RawStdEncoding}
+
+// EncodingMigrator is a FutureMigrator converting futures whose data is not encoded with base64.URLEncoding, i.e.
+// encoded with another base64 alphabet or without padding, or not encoded at all, into the current format.
+type EncodingMigrator struct{}
+
+var _ FutureMigrator = EncodingMigrator{}
+
+// MigrateFuture returns a copy of the future with its data encoded with base64.URLEncoding, or an error if its data is
+// not the JSON of an SDK future in any of the known encodings.
+func (EncodingMigrator) MigrateFuture(future infrav1.Future) (*infrav1.Future, error) {
+	for _, encoding := range dataEncodings {
+		if data, err := encoding.DecodeString(future.Data); err == nil && isSDKFuture(data) {
+			return withData(future, data), nil
+		}
+	}
+	if data := []byte(future.Data); isSDKFuture(data) {
+		return withData(future, data), nil
+	}
+	return nil, errors.Errorf("data of future %s/%s is not in a known format", future.ServiceName, future.Name)
+}
+
+// isSDKFuture returns true if data is the JSON of an SDK future.
+func isSDKFuture(data []byte) bool {
+	var future azureautorest.Future
+	return future.UnmarshalJSON(data) == nil
+}
+
+// withData returns a copy of the future with the given data encoded in the current format.
+func withData(future infrav1.Future, data []byte) *infrav1.Future {
+	migrated := future.DeepCopy()
+	migrated.Data = base64.URLEncoding.EncodeToString(data)
+	return migrated
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package async
+
+import (
+	"encoding/base64"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/converters"
+)
+
+func TestEncodingMigrator(t *testing.T) {
+	data := `{"method":"DELETE","pollingMethod":"Location","lroState":"InProgress"}`
+	futureWithData := func(data string) infrav1.Future {
+		future := validDeleteFuture
+		future.Data = data
+		return future
+	}
+
+	testcases := []struct {
+		name          string
+		future        infrav1.Future
+		expectedError string
+	}{
+		{
+			name:   "standard base64 encoding",
+			future: futureWithData(base64.StdEncoding.EncodeToString([]byte(data))),
+		},
+		{
+			name:   "base64 encoding without padding",
+			future: futureWithData(base64.RawURLEncoding.EncodeToString([]byte(data))),
+		},
+		{
+			name:   "data that is not encoded",
+			future: futureWithData(data),
+		},
+		{
+			name:          "data that is not a future",
+			future:        invalidFuture,
+			expectedError: "data of future test-service/test-resource is not in a known format",
+		},
+		{
+			name:          "encoded JSON that is not a future",
+			future:        futureWithData(base64.StdEncoding.EncodeToString([]byte(`{"lroState":"InProgress"}`))),
+			expectedError: "data of future test-service/test-resource is not in a known format",
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			migrated, err := EncodingMigrator{}.MigrateFuture(tc.future)
+			if tc.expectedError != "" {
+				g.Expect(err).To(MatchError(tc.expectedError))
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(*migrated).To(Equal(futureWithData(base64.URLEncoding.EncodeToString([]byte(data)))))
+			_, err = converters.FutureToSDK(*migrated)
+			g.Expect(err).NotTo(HaveOccurred())
+		})
+	}
+}
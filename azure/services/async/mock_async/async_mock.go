@@ -80,6 +80,34 @@ func (mr *MockFutureScopeMockRecorder) GetLongRunningOperationState(arg0, arg1 i
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLongRunningOperationState", reflect.TypeOf((*MockFutureScope)(nil).GetLongRunningOperationState), arg0, arg1)
 }
 
+// GetLongRunningOperationStates mocks base method.
+func (m *MockFutureScope) GetLongRunningOperationStates() []*v1beta1.Future {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLongRunningOperationStates")
+	ret0, _ := ret[0].([]*v1beta1.Future)
+	return ret0
+}
+
+// GetLongRunningOperationStates indicates an expected call of GetLongRunningOperationStates.
+func (mr *MockFutureScopeMockRecorder) GetLongRunningOperationStates() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLongRunningOperationStates", reflect.TypeOf((*MockFutureScope)(nil).GetLongRunningOperationStates))
+}
+
+// IsServicePaused mocks base method.
+func (m *MockFutureScope) IsServicePaused(arg0 string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsServicePaused", arg0)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsServicePaused indicates an expected call of IsServicePaused.
+func (mr *MockFutureScopeMockRecorder) IsServicePaused(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsServicePaused", reflect.TypeOf((*MockFutureScope)(nil).IsServicePaused), arg0)
+}
+
 // SetLongRunningOperationState mocks base method.
 func (m *MockFutureScope) SetLongRunningOperationState(arg0 *v1beta1.Future) {
 	m.ctrl.T.Helper()
@@ -128,6 +156,152 @@ func (mr *MockFutureScopeMockRecorder) UpdatePutStatus(arg0, arg1, arg2 interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePutStatus", reflect.TypeOf((*MockFutureScope)(nil).UpdatePutStatus), arg0, arg1, arg2)
 }
 
+// MockAppliedParametersScope is a mock of AppliedParametersScope interface.
+type MockAppliedParametersScope struct {
+	ctrl     *gomock.Controller
+	recorder *MockAppliedParametersScopeMockRecorder
+}
+
+// MockAppliedParametersScopeMockRecorder is the mock recorder for MockAppliedParametersScope.
+type MockAppliedParametersScopeMockRecorder struct {
+	mock *MockAppliedParametersScope
+}
+
+// NewMockAppliedParametersScope creates a new mock instance.
+func NewMockAppliedParametersScope(ctrl *gomock.Controller) *MockAppliedParametersScope {
+	mock := &MockAppliedParametersScope{ctrl: ctrl}
+	mock.recorder = &MockAppliedParametersScopeMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAppliedParametersScope) EXPECT() *MockAppliedParametersScopeMockRecorder {
+	return m.recorder
+}
+
+// AppliedParameters mocks base method.
+func (m *MockAppliedParametersScope) AppliedParameters(name, service, resourceGroup string) (string, string) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AppliedParameters", name, service, resourceGroup)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(string)
+	return ret0, ret1
+}
+
+// AppliedParameters indicates an expected call of AppliedParameters.
+func (mr *MockAppliedParametersScopeMockRecorder) AppliedParameters(name, service, resourceGroup interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AppliedParameters", reflect.TypeOf((*MockAppliedParametersScope)(nil).AppliedParameters), name, service, resourceGroup)
+}
+
+// DeleteAppliedParameters mocks base method.
+func (m *MockAppliedParametersScope) DeleteAppliedParameters(name, service, resourceGroup string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "DeleteAppliedParameters", name, service, resourceGroup)
+}
+
+// DeleteAppliedParameters indicates an expected call of DeleteAppliedParameters.
+func (mr *MockAppliedParametersScopeMockRecorder) DeleteAppliedParameters(name, service, resourceGroup interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAppliedParameters", reflect.TypeOf((*MockAppliedParametersScope)(nil).DeleteAppliedParameters), name, service, resourceGroup)
+}
+
+// SetAppliedParameters mocks base method.
+func (m *MockAppliedParametersScope) SetAppliedParameters(name, service, resourceGroup, parametersHash, resourceHash string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetAppliedParameters", name, service, resourceGroup, parametersHash, resourceHash)
+}
+
+// SetAppliedParameters indicates an expected call of SetAppliedParameters.
+func (mr *MockAppliedParametersScopeMockRecorder) SetAppliedParameters(name, service, resourceGroup, parametersHash, resourceHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAppliedParameters", reflect.TypeOf((*MockAppliedParametersScope)(nil).SetAppliedParameters), name, service, resourceGroup, parametersHash, resourceHash)
+}
+
+// MockResourceGroupFutureScope is a mock of ResourceGroupFutureScope interface.
+type MockResourceGroupFutureScope struct {
+	ctrl     *gomock.Controller
+	recorder *MockResourceGroupFutureScopeMockRecorder
+}
+
+// MockResourceGroupFutureScopeMockRecorder is the mock recorder for MockResourceGroupFutureScope.
+type MockResourceGroupFutureScopeMockRecorder struct {
+	mock *MockResourceGroupFutureScope
+}
+
+// NewMockResourceGroupFutureScope creates a new mock instance.
+func NewMockResourceGroupFutureScope(ctrl *gomock.Controller) *MockResourceGroupFutureScope {
+	mock := &MockResourceGroupFutureScope{ctrl: ctrl}
+	mock.recorder = &MockResourceGroupFutureScopeMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockResourceGroupFutureScope) EXPECT() *MockResourceGroupFutureScopeMockRecorder {
+	return m.recorder
+}
+
+// DeleteLongRunningOperationStateInResourceGroup mocks base method.
+func (m *MockResourceGroupFutureScope) DeleteLongRunningOperationStateInResourceGroup(name, service, resourceGroup string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "DeleteLongRunningOperationStateInResourceGroup", name, service, resourceGroup)
+}
+
+// DeleteLongRunningOperationStateInResourceGroup indicates an expected call of DeleteLongRunningOperationStateInResourceGroup.
+func (mr *MockResourceGroupFutureScopeMockRecorder) DeleteLongRunningOperationStateInResourceGroup(name, service, resourceGroup interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteLongRunningOperationStateInResourceGroup", reflect.TypeOf((*MockResourceGroupFutureScope)(nil).DeleteLongRunningOperationStateInResourceGroup), name, service, resourceGroup)
+}
+
+// GetLongRunningOperationStateInResourceGroup mocks base method.
+func (m *MockResourceGroupFutureScope) GetLongRunningOperationStateInResourceGroup(name, service, resourceGroup string) *v1beta1.Future {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLongRunningOperationStateInResourceGroup", name, service, resourceGroup)
+	ret0, _ := ret[0].(*v1beta1.Future)
+	return ret0
+}
+
+// GetLongRunningOperationStateInResourceGroup indicates an expected call of GetLongRunningOperationStateInResourceGroup.
+func (mr *MockResourceGroupFutureScopeMockRecorder) GetLongRunningOperationStateInResourceGroup(name, service, resourceGroup interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLongRunningOperationStateInResourceGroup", reflect.TypeOf((*MockResourceGroupFutureScope)(nil).GetLongRunningOperationStateInResourceGroup), name, service, resourceGroup)
+}
+
+// MockCompletedOperationScope is a mock of CompletedOperationScope interface.
+type MockCompletedOperationScope struct {
+	ctrl     *gomock.Controller
+	recorder *MockCompletedOperationScopeMockRecorder
+}
+
+// MockCompletedOperationScopeMockRecorder is the mock recorder for MockCompletedOperationScope.
+type MockCompletedOperationScopeMockRecorder struct {
+	mock *MockCompletedOperationScope
+}
+
+// NewMockCompletedOperationScope creates a new mock instance.
+func NewMockCompletedOperationScope(ctrl *gomock.Controller) *MockCompletedOperationScope {
+	mock := &MockCompletedOperationScope{ctrl: ctrl}
+	mock.recorder = &MockCompletedOperationScopeMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCompletedOperationScope) EXPECT() *MockCompletedOperationScopeMockRecorder {
+	return m.recorder
+}
+
+// SetCompletedOperation mocks base method.
+func (m *MockCompletedOperationScope) SetCompletedOperation(operation v1beta1.CompletedOperation) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetCompletedOperation", operation)
+}
+
+// SetCompletedOperation indicates an expected call of SetCompletedOperation.
+func (mr *MockCompletedOperationScopeMockRecorder) SetCompletedOperation(operation interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCompletedOperation", reflect.TypeOf((*MockCompletedOperationScope)(nil).SetCompletedOperation), operation)
+}
+
 // MockFutureHandler is a mock of FutureHandler interface.
 type MockFutureHandler struct {
 	ctrl     *gomock.Controller
@@ -181,6 +355,161 @@ func (mr *MockFutureHandlerMockRecorder) Result(ctx, future, futureType interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Result", reflect.TypeOf((*MockFutureHandler)(nil).Result), ctx, future, futureType)
 }
 
+// MockPollerHandler is a mock of PollerHandler interface.
+type MockPollerHandler struct {
+	ctrl     *gomock.Controller
+	recorder *MockPollerHandlerMockRecorder
+}
+
+// MockPollerHandlerMockRecorder is the mock recorder for MockPollerHandler.
+type MockPollerHandlerMockRecorder struct {
+	mock *MockPollerHandler
+}
+
+// NewMockPollerHandler creates a new mock instance.
+func NewMockPollerHandler(ctrl *gomock.Controller) *MockPollerHandler {
+	mock := &MockPollerHandler{ctrl: ctrl}
+	mock.recorder = &MockPollerHandlerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPollerHandler) EXPECT() *MockPollerHandlerMockRecorder {
+	return m.recorder
+}
+
+// ResumePoller mocks base method.
+func (m *MockPollerHandler) ResumePoller(ctx context.Context, future v1beta1.Future, resumeToken string) (bool, interface{}, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResumePoller", ctx, future, resumeToken)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(interface{})
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ResumePoller indicates an expected call of ResumePoller.
+func (mr *MockPollerHandlerMockRecorder) ResumePoller(ctx, future, resumeToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResumePoller", reflect.TypeOf((*MockPollerHandler)(nil).ResumePoller), ctx, future, resumeToken)
+}
+
+// MockFutureMigrator is a mock of FutureMigrator interface.
+type MockFutureMigrator struct {
+	ctrl     *gomock.Controller
+	recorder *MockFutureMigratorMockRecorder
+}
+
+// MockFutureMigratorMockRecorder is the mock recorder for MockFutureMigrator.
+type MockFutureMigratorMockRecorder struct {
+	mock *MockFutureMigrator
+}
+
+// NewMockFutureMigrator creates a new mock instance.
+func NewMockFutureMigrator(ctrl *gomock.Controller) *MockFutureMigrator {
+	mock := &MockFutureMigrator{ctrl: ctrl}
+	mock.recorder = &MockFutureMigratorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFutureMigrator) EXPECT() *MockFutureMigratorMockRecorder {
+	return m.recorder
+}
+
+// MigrateFuture mocks base method.
+func (m *MockFutureMigrator) MigrateFuture(future v1beta1.Future) (*v1beta1.Future, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MigrateFuture", future)
+	ret0, _ := ret[0].(*v1beta1.Future)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MigrateFuture indicates an expected call of MigrateFuture.
+func (mr *MockFutureMigratorMockRecorder) MigrateFuture(future interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MigrateFuture", reflect.TypeOf((*MockFutureMigrator)(nil).MigrateFuture), future)
+}
+
+// MockFailureResolver is a mock of FailureResolver interface.
+type MockFailureResolver struct {
+	ctrl     *gomock.Controller
+	recorder *MockFailureResolverMockRecorder
+}
+
+// MockFailureResolverMockRecorder is the mock recorder for MockFailureResolver.
+type MockFailureResolverMockRecorder struct {
+	mock *MockFailureResolver
+}
+
+// NewMockFailureResolver creates a new mock instance.
+func NewMockFailureResolver(ctrl *gomock.Controller) *MockFailureResolver {
+	mock := &MockFailureResolver{ctrl: ctrl}
+	mock.recorder = &MockFailureResolverMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFailureResolver) EXPECT() *MockFailureResolverMockRecorder {
+	return m.recorder
+}
+
+// ResolveFailure mocks base method.
+func (m *MockFailureResolver) ResolveFailure(ctx context.Context, correlationID string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolveFailure", ctx, correlationID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResolveFailure indicates an expected call of ResolveFailure.
+func (mr *MockFailureResolverMockRecorder) ResolveFailure(ctx, correlationID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveFailure", reflect.TypeOf((*MockFailureResolver)(nil).ResolveFailure), ctx, correlationID)
+}
+
+// MockQuotaChecker is a mock of QuotaChecker interface.
+type MockQuotaChecker struct {
+	ctrl     *gomock.Controller
+	recorder *MockQuotaCheckerMockRecorder
+}
+
+// MockQuotaCheckerMockRecorder is the mock recorder for MockQuotaChecker.
+type MockQuotaCheckerMockRecorder struct {
+	mock *MockQuotaChecker
+}
+
+// NewMockQuotaChecker creates a new mock instance.
+func NewMockQuotaChecker(ctrl *gomock.Controller) *MockQuotaChecker {
+	mock := &MockQuotaChecker{ctrl: ctrl}
+	mock.recorder = &MockQuotaCheckerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockQuotaChecker) EXPECT() *MockQuotaCheckerMockRecorder {
+	return m.recorder
+}
+
+// Usage mocks base method.
+func (m *MockQuotaChecker) Usage(ctx context.Context, location, name string) (int64, int64, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Usage", ctx, location, name)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(bool)
+	ret3, _ := ret[3].(error)
+	return ret0, ret1, ret2, ret3
+}
+
+// Usage indicates an expected call of Usage.
+func (mr *MockQuotaCheckerMockRecorder) Usage(ctx, location, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Usage", reflect.TypeOf((*MockQuotaChecker)(nil).Usage), ctx, location, name)
+}
+
 // MockGetter is a mock of Getter interface.
 type MockGetter struct {
 	ctrl     *gomock.Controller
@@ -303,6 +632,45 @@ func (mr *MockCreatorMockRecorder) Result(ctx, future, futureType interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Result", reflect.TypeOf((*MockCreator)(nil).Result), ctx, future, futureType)
 }
 
+// MockUpdater is a mock of Updater interface.
+type MockUpdater struct {
+	ctrl     *gomock.Controller
+	recorder *MockUpdaterMockRecorder
+}
+
+// MockUpdaterMockRecorder is the mock recorder for MockUpdater.
+type MockUpdaterMockRecorder struct {
+	mock *MockUpdater
+}
+
+// NewMockUpdater creates a new mock instance.
+func NewMockUpdater(ctrl *gomock.Controller) *MockUpdater {
+	mock := &MockUpdater{ctrl: ctrl}
+	mock.recorder = &MockUpdaterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUpdater) EXPECT() *MockUpdaterMockRecorder {
+	return m.recorder
+}
+
+// UpdateAsync mocks base method.
+func (m *MockUpdater) UpdateAsync(ctx context.Context, spec azure0.ResourceSpecGetter, parameters interface{}) (interface{}, azure.FutureAPI, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAsync", ctx, spec, parameters)
+	ret0, _ := ret[0].(interface{})
+	ret1, _ := ret[1].(azure.FutureAPI)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// UpdateAsync indicates an expected call of UpdateAsync.
+func (mr *MockUpdaterMockRecorder) UpdateAsync(ctx, spec, parameters interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAsync", reflect.TypeOf((*MockUpdater)(nil).UpdateAsync), ctx, spec, parameters)
+}
+
 // MockDeleter is a mock of Deleter interface.
 type MockDeleter struct {
 	ctrl     *gomock.Controller
@@ -371,6 +739,205 @@ func (mr *MockDeleterMockRecorder) Result(ctx, future, futureType interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Result", reflect.TypeOf((*MockDeleter)(nil).Result), ctx, future, futureType)
 }
 
+// MockPollerCreator is a mock of PollerCreator interface.
+type MockPollerCreator struct {
+	ctrl     *gomock.Controller
+	recorder *MockPollerCreatorMockRecorder
+}
+
+// MockPollerCreatorMockRecorder is the mock recorder for MockPollerCreator.
+type MockPollerCreatorMockRecorder struct {
+	mock *MockPollerCreator
+}
+
+// NewMockPollerCreator creates a new mock instance.
+func NewMockPollerCreator(ctrl *gomock.Controller) *MockPollerCreator {
+	mock := &MockPollerCreator{ctrl: ctrl}
+	mock.recorder = &MockPollerCreatorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPollerCreator) EXPECT() *MockPollerCreatorMockRecorder {
+	return m.recorder
+}
+
+// BeginCreateOrUpdate mocks base method.
+func (m *MockPollerCreator) BeginCreateOrUpdate(ctx context.Context, spec azure0.ResourceSpecGetter, parameters interface{}) (interface{}, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BeginCreateOrUpdate", ctx, spec, parameters)
+	ret0, _ := ret[0].(interface{})
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// BeginCreateOrUpdate indicates an expected call of BeginCreateOrUpdate.
+func (mr *MockPollerCreatorMockRecorder) BeginCreateOrUpdate(ctx, spec, parameters interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginCreateOrUpdate", reflect.TypeOf((*MockPollerCreator)(nil).BeginCreateOrUpdate), ctx, spec, parameters)
+}
+
+// Get mocks base method.
+func (m *MockPollerCreator) Get(ctx context.Context, spec azure0.ResourceSpecGetter) (interface{}, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, spec)
+	ret0, _ := ret[0].(interface{})
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockPollerCreatorMockRecorder) Get(ctx, spec interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockPollerCreator)(nil).Get), ctx, spec)
+}
+
+// ResumePoller mocks base method.
+func (m *MockPollerCreator) ResumePoller(ctx context.Context, future v1beta1.Future, resumeToken string) (bool, interface{}, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResumePoller", ctx, future, resumeToken)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(interface{})
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ResumePoller indicates an expected call of ResumePoller.
+func (mr *MockPollerCreatorMockRecorder) ResumePoller(ctx, future, resumeToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResumePoller", reflect.TypeOf((*MockPollerCreator)(nil).ResumePoller), ctx, future, resumeToken)
+}
+
+// MockPollerDeleter is a mock of PollerDeleter interface.
+type MockPollerDeleter struct {
+	ctrl     *gomock.Controller
+	recorder *MockPollerDeleterMockRecorder
+}
+
+// MockPollerDeleterMockRecorder is the mock recorder for MockPollerDeleter.
+type MockPollerDeleterMockRecorder struct {
+	mock *MockPollerDeleter
+}
+
+// NewMockPollerDeleter creates a new mock instance.
+func NewMockPollerDeleter(ctrl *gomock.Controller) *MockPollerDeleter {
+	mock := &MockPollerDeleter{ctrl: ctrl}
+	mock.recorder = &MockPollerDeleterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPollerDeleter) EXPECT() *MockPollerDeleterMockRecorder {
+	return m.recorder
+}
+
+// BeginDelete mocks base method.
+func (m *MockPollerDeleter) BeginDelete(ctx context.Context, spec azure0.ResourceSpecGetter) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BeginDelete", ctx, spec)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BeginDelete indicates an expected call of BeginDelete.
+func (mr *MockPollerDeleterMockRecorder) BeginDelete(ctx, spec interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginDelete", reflect.TypeOf((*MockPollerDeleter)(nil).BeginDelete), ctx, spec)
+}
+
+// ResumePoller mocks base method.
+func (m *MockPollerDeleter) ResumePoller(ctx context.Context, future v1beta1.Future, resumeToken string) (bool, interface{}, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResumePoller", ctx, future, resumeToken)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(interface{})
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ResumePoller indicates an expected call of ResumePoller.
+func (mr *MockPollerDeleterMockRecorder) ResumePoller(ctx, future, resumeToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResumePoller", reflect.TypeOf((*MockPollerDeleter)(nil).ResumePoller), ctx, future, resumeToken)
+}
+
+// MockCanceler is a mock of Canceler interface.
+type MockCanceler struct {
+	ctrl     *gomock.Controller
+	recorder *MockCancelerMockRecorder
+}
+
+// MockCancelerMockRecorder is the mock recorder for MockCanceler.
+type MockCancelerMockRecorder struct {
+	mock *MockCanceler
+}
+
+// NewMockCanceler creates a new mock instance.
+func NewMockCanceler(ctrl *gomock.Controller) *MockCanceler {
+	mock := &MockCanceler{ctrl: ctrl}
+	mock.recorder = &MockCancelerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCanceler) EXPECT() *MockCancelerMockRecorder {
+	return m.recorder
+}
+
+// Cancel mocks base method.
+func (m *MockCanceler) Cancel(ctx context.Context, future azure.FutureAPI) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Cancel", ctx, future)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Cancel indicates an expected call of Cancel.
+func (mr *MockCancelerMockRecorder) Cancel(ctx, future interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Cancel", reflect.TypeOf((*MockCanceler)(nil).Cancel), ctx, future)
+}
+
+// MockProvisioningStateGetter is a mock of ProvisioningStateGetter interface.
+type MockProvisioningStateGetter struct {
+	ctrl     *gomock.Controller
+	recorder *MockProvisioningStateGetterMockRecorder
+}
+
+// MockProvisioningStateGetterMockRecorder is the mock recorder for MockProvisioningStateGetter.
+type MockProvisioningStateGetterMockRecorder struct {
+	mock *MockProvisioningStateGetter
+}
+
+// NewMockProvisioningStateGetter creates a new mock instance.
+func NewMockProvisioningStateGetter(ctrl *gomock.Controller) *MockProvisioningStateGetter {
+	mock := &MockProvisioningStateGetter{ctrl: ctrl}
+	mock.recorder = &MockProvisioningStateGetterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockProvisioningStateGetter) EXPECT() *MockProvisioningStateGetterMockRecorder {
+	return m.recorder
+}
+
+// ProvisioningState mocks base method.
+func (m *MockProvisioningStateGetter) ProvisioningState(resource interface{}) (string, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProvisioningState", resource)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// ProvisioningState indicates an expected call of ProvisioningState.
+func (mr *MockProvisioningStateGetterMockRecorder) ProvisioningState(resource interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProvisioningState", reflect.TypeOf((*MockProvisioningStateGetter)(nil).ProvisioningState), resource)
+}
+
 // MockReconciler is a mock of Reconciler interface.
 type MockReconciler struct {
 	ctrl     *gomock.Controller
@@ -395,12 +962,13 @@ func (m *MockReconciler) EXPECT() *MockReconcilerMockRecorder {
 }
 
 // CreateResource mocks base method.
-func (m *MockReconciler) CreateResource(ctx context.Context, spec azure0.ResourceSpecGetter, serviceName string) (interface{}, error) {
+func (m *MockReconciler) CreateResource(ctx context.Context, spec azure0.ResourceSpecGetter, serviceName string) (interface{}, bool, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "CreateResource", ctx, spec, serviceName)
 	ret0, _ := ret[0].(interface{})
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
 }
 
 // CreateResource indicates an expected call of CreateResource.
@@ -410,11 +978,12 @@ func (mr *MockReconcilerMockRecorder) CreateResource(ctx, spec, serviceName inte
 }
 
 // DeleteResource mocks base method.
-func (m *MockReconciler) DeleteResource(ctx context.Context, spec azure0.ResourceSpecGetter, serviceName string) error {
+func (m *MockReconciler) DeleteResource(ctx context.Context, spec azure0.ResourceSpecGetter, serviceName string) (bool, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "DeleteResource", ctx, spec, serviceName)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // DeleteResource indicates an expected call of DeleteResource.
@@ -422,3 +991,34 @@ func (mr *MockReconcilerMockRecorder) DeleteResource(ctx, spec, serviceName inte
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteResource", reflect.TypeOf((*MockReconciler)(nil).DeleteResource), ctx, spec, serviceName)
 }
+
+// GetResource mocks base method.
+func (m *MockReconciler) GetResource(ctx context.Context, spec azure0.ResourceSpecGetter, serviceName string) (interface{}, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetResource", ctx, spec, serviceName)
+	ret0, _ := ret[0].(interface{})
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetResource indicates an expected call of GetResource.
+func (mr *MockReconcilerMockRecorder) GetResource(ctx, spec, serviceName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetResource", reflect.TypeOf((*MockReconciler)(nil).GetResource), ctx, spec, serviceName)
+}
+
+// RefreshOperation mocks base method.
+func (m *MockReconciler) RefreshOperation(ctx context.Context, spec azure0.ResourceSpecGetter, serviceName string) (interface{}, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshOperation", ctx, spec, serviceName)
+	ret0, _ := ret[0].(interface{})
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// RefreshOperation indicates an expected call of RefreshOperation.
+func (mr *MockReconcilerMockRecorder) RefreshOperation(ctx, spec, serviceName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshOperation", reflect.TypeOf((*MockReconciler)(nil).RefreshOperation), ctx, spec, serviceName)
+}
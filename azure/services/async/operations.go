@@ -0,0 +1,231 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package async
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/global"
+	"go.opentelemetry.io/otel/metric/unit"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+)
+
+var (
+	// trackedOperations tracks the ongoing operations of all services. Services are created for every reconcile, so
+	// the tracked operations are shared by all of them. They are kept in memory since they are only used for
+	// observability and backoff: after a restart, operations that were already ongoing are tracked from scratch.
+	trackedOperations = newOperationTracker()
+
+	meter = metric.Must(global.Meter("capz"))
+
+	operationIterationsHistogram = meter.NewInt64Histogram(
+		"capz_async_operation_iterations",
+		metric.WithDescription("Number of reconcile iterations it took for a long-running operation to complete."),
+	)
+	operationDurationHistogram = meter.NewFloat64Histogram(
+		"capz_async_operation_duration_seconds",
+		metric.WithDescription("Time from when a long-running operation is stored until it is done."),
+		metric.WithUnit(unit.Unit("s")),
+	)
+	operationTimeoutsCounter = meter.NewInt64Counter(
+		"capz_async_operation_timeouts_total",
+		metric.WithDescription("Number of operations that did not complete within the reconcile timeout and became long-running operations."),
+	)
+)
+
+// trackedOperation is the state tracked for an operation.
+type trackedOperation struct {
+	// polls is the number of times the operation was polled.
+	polls int
+	// started is when the operation was stored, it is zero if the operation was already ongoing when it was first seen.
+	started time.Time
+}
+
+// operationTracker tracks the number of polls and the start time of long-running operations.
+type operationTracker struct {
+	mu         sync.Mutex
+	operations map[string]*trackedOperation
+	// creates is when the last create operation of each resource was stored, until the resource is deleted. It is
+	// kept after the operation is finished.
+	creates map[string]time.Time
+	now     func() time.Time
+}
+
+// newOperationTracker returns an empty operationTracker.
+func newOperationTracker() *operationTracker {
+	return &operationTracker{
+		operations: make(map[string]*trackedOperation),
+		creates:    make(map[string]time.Time),
+		now:        time.Now,
+	}
+}
+
+// operation returns the tracked state of the operation, creating it if needed. The caller must hold the lock.
+func (t *operationTracker) operation(future *infrav1.Future) *trackedOperation {
+	key := futureKey(future)
+	op, ok := t.operations[key]
+	if !ok {
+		op = &trackedOperation{}
+		t.operations[key] = op
+	}
+	return op
+}
+
+// start records that the operation was stored.
+func (t *operationTracker) start(future *infrav1.Future) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	t.operation(future).started = now
+	switch future.Type {
+	case infrav1.PutFuture:
+		t.creates[createKey(future.ServiceName, future.ResourceGroup, future.Name)] = now
+	case infrav1.DeleteFuture:
+		delete(t.creates, createKey(future.ServiceName, future.ResourceGroup, future.Name))
+	}
+}
+
+// createdWithin returns true if a create operation of the resource was stored less than window ago and the resource
+// was not deleted since.
+func (t *operationTracker) createdWithin(serviceName, rgName, resourceName string, window time.Duration) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	key := createKey(serviceName, rgName, resourceName)
+	created, ok := t.creates[key]
+	if !ok {
+		return false
+	}
+	if t.now().Sub(created) >= window {
+		delete(t.creates, key)
+		return false
+	}
+	return true
+}
+
+// poll records a poll of the operation and returns the number of polls so far.
+func (t *operationTracker) poll(future *infrav1.Future) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	op := t.operation(future)
+	op.polls++
+	return op.polls
+}
+
+// unpoll forgets the last poll of the operation, e.g. because it was never sent to Azure.
+func (t *operationTracker) unpoll(future *infrav1.Future) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if op, ok := t.operations[futureKey(future)]; ok && op.polls > 0 {
+		op.polls--
+	}
+}
+
+// polls returns the number of polls recorded for the operation.
+func (t *operationTracker) polls(future *infrav1.Future) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if op, ok := t.operations[futureKey(future)]; ok {
+		return op.polls
+	}
+	return 0
+}
+
+// finish forgets the operation and returns the number of polls recorded for it along with the time since it was
+// stored. The returned bool is false if it is unknown when the operation was stored.
+func (t *operationTracker) finish(future *infrav1.Future) (polls int, duration time.Duration, started bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	key := futureKey(future)
+	op, ok := t.operations[key]
+	if !ok {
+		return 0, 0, false
+	}
+	delete(t.operations, key)
+	if op.started.IsZero() {
+		return op.polls, 0, false
+	}
+	return op.polls, t.now().Sub(op.started), true
+}
+
+// operationAttributes returns the metric attributes of the operation.
+func operationAttributes(future *infrav1.Future) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("service", future.ServiceName),
+		attribute.String("type", future.Type),
+	}
+}
+
+// recordOperationTimeout emits that an operation did not complete within the reconcile timeout.
+func recordOperationTimeout(ctx context.Context, future *infrav1.Future) {
+	operationTimeoutsCounter.Add(ctx, 1, operationAttributes(future)...)
+}
+
+// recordOperationCompletion emits the number of iterations and, if known, the time a completed operation took.
+func recordOperationCompletion(ctx context.Context, future *infrav1.Future, iterations int, duration time.Duration, started bool) {
+	operationIterationsHistogram.Record(ctx, int64(iterations), operationAttributes(future)...)
+	if started {
+		operationDurationHistogram.Record(ctx, duration.Seconds(), operationAttributes(future)...)
+	}
+}
+
+// recordCompletedOperation logs how long a completed operation took and records it in the scope, if it is a
+// CompletedOperationScope, before its long-running operation state is deleted. The duration is measured from the start
+// time of the future, which survives controller restarts, or else from when the operation was first tracked. It is
+// unknown for futures stored without a start time that were already ongoing when the controller started.
+func (s *Service) recordCompletedOperation(log logr.Logger, future *infrav1.Future, iterations int, tracked time.Duration, started bool) {
+	now := time.Now()
+	duration, known := tracked, started
+	if future.StartTime != nil {
+		duration, known = now.Sub(future.StartTime.Time), true
+	}
+	kvs := []interface{}{"service", future.ServiceName, "resource", future.Name, "iterations", iterations}
+	if known {
+		kvs = append(kvs, "duration", duration.Round(time.Second).String())
+	}
+	log.V(4).Info("long running operation took reconcile iterations to complete", kvs...)
+
+	scope, ok := s.Scope.(CompletedOperationScope)
+	if !known || !ok {
+		return
+	}
+	scope.SetCompletedOperation(infrav1.CompletedOperation{
+		Type:           future.Type,
+		ResourceGroup:  future.ResourceGroup,
+		ServiceName:    future.ServiceName,
+		Name:           future.Name,
+		StartTime:      future.StartTime,
+		CompletionTime: metav1.NewTime(now),
+		Duration:       metav1.Duration{Duration: duration},
+	})
+}
+
+// futureKey returns a string uniquely identifying the operation a future refers to.
+func futureKey(future *infrav1.Future) string {
+	return strings.ToLower(strings.Join([]string{future.ServiceName, future.ResourceGroup, future.Name, future.Type}, "/"))
+}
+
+// resourceKey returns a string uniquely identifying a resource of a service.
+func createKey(serviceName, rgName, resourceName string) string {
+	return strings.ToLower(strings.Join([]string{serviceName, rgName, resourceName}, "/"))
+}
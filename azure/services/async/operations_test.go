@@ -0,0 +1,78 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package async
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestOperationTrackerDuration(t *testing.T) {
+	g := NewWithT(t)
+	now := time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC)
+	tracker := newOperationTracker()
+	tracker.now = func() time.Time { return now }
+
+	tracker.start(&validCreateFuture)
+	now = now.Add(15 * time.Second)
+	g.Expect(tracker.poll(&validCreateFuture)).To(Equal(1))
+	now = now.Add(15 * time.Second)
+	g.Expect(tracker.poll(&validCreateFuture)).To(Equal(2))
+
+	polls, duration, started := tracker.finish(&validCreateFuture)
+	g.Expect(polls).To(Equal(2))
+	g.Expect(duration).To(Equal(30 * time.Second))
+	g.Expect(started).To(BeTrue())
+
+	// An operation that was already ongoing when it was first seen has an unknown duration.
+	g.Expect(tracker.poll(&validDeleteFuture)).To(Equal(1))
+	polls, _, started = tracker.finish(&validDeleteFuture)
+	g.Expect(polls).To(Equal(1))
+	g.Expect(started).To(BeFalse())
+
+	// Finished operations are forgotten.
+	g.Expect(tracker.polls(&validCreateFuture)).To(BeZero())
+	_, _, started = tracker.finish(&validCreateFuture)
+	g.Expect(started).To(BeFalse())
+}
+
+func TestOperationTrackerCreatedWithin(t *testing.T) {
+	g := NewWithT(t)
+	now := time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC)
+	tracker := newOperationTracker()
+	tracker.now = func() time.Time { return now }
+
+	g.Expect(tracker.createdWithin("test-service", "test-group", "test-resource", time.Minute)).To(BeFalse())
+
+	tracker.start(&validCreateFuture)
+	now = now.Add(30 * time.Second)
+	g.Expect(tracker.createdWithin("test-service", "TEST-GROUP", "test-resource", time.Minute)).To(BeTrue())
+
+	// Create operations are remembered after they are finished.
+	tracker.finish(&validCreateFuture)
+	g.Expect(tracker.createdWithin("test-service", "test-group", "test-resource", time.Minute)).To(BeTrue())
+
+	now = now.Add(30 * time.Second)
+	g.Expect(tracker.createdWithin("test-service", "test-group", "test-resource", time.Minute)).To(BeFalse())
+
+	// Deleting the resource forgets that it was created.
+	tracker.start(&validCreateFuture)
+	tracker.start(&validDeleteFuture)
+	g.Expect(tracker.createdWithin("test-service", "test-group", "test-resource", time.Minute)).To(BeFalse())
+}
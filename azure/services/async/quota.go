@@ -0,0 +1,56 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package async
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
+)
+
+// checkQuota returns a terminal azure.QuotaExceededError if creating the resource of the spec would exceed the quota it
+// declares, see WithQuotaCheck. The check is best effort: the creation is requested as usual if the usage of the quota
+// cannot be read or the quota is unknown, so that a failing usage API never blocks a creation.
+func (s *Service) checkQuota(ctx context.Context, spec azure.ResourceSpecGetter, resourceName, rgName, serviceName string) error {
+	ctx, log, done := tele.StartSpanWithLogger(ctx, "async.Service.checkQuota")
+	defer done()
+
+	quotaSpec, ok := spec.(azure.QuotaSpecGetter)
+	if s.quotaChecker == nil || !ok {
+		return nil
+	}
+	requirement := quotaSpec.QuotaRequirement()
+	if requirement.Name == "" || requirement.Amount <= 0 {
+		return nil
+	}
+	current, limit, found, err := s.quotaChecker.Usage(ctx, requirement.Location, requirement.Name)
+	if err != nil {
+		log.Error(err, "failed to check quota before creating resource, creating it anyway", "service", serviceName, "resource", resourceName, "resourceGroup", rgName, "quota", requirement.Name, "location", requirement.Location)
+		return nil
+	}
+	if !found {
+		log.V(4).Info("quota not found, creating resource without checking it", "service", serviceName, "resource", resourceName, "resourceGroup", rgName, "quota", requirement.Name, "location", requirement.Location)
+		return nil
+	}
+	if limit >= 0 && current+requirement.Amount > limit {
+		quotaErr := azure.QuotaExceededError{Requirement: requirement, Current: current, Limit: limit}
+		return azure.WithTerminalError(errors.Wrapf(quotaErr, "cannot create resource %s/%s (service: %s)", rgName, resourceName, serviceName))
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package async
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/async/mock_async"
+	gomockinternal "sigs.k8s.io/cluster-api-provider-azure/internal/test/matchers/gomock"
+)
+
+// quotaSpec is a spec whose resource consumes one unit of a quota.
+type quotaSpec struct {
+	dependentSpec
+}
+
+func (s *quotaSpec) Parameters(existing interface{}) (interface{}, error) {
+	return &fakeResourceParameters, nil
+}
+
+func (s *quotaSpec) QuotaRequirement() azure.QuotaRequirement {
+	return azure.QuotaRequirement{Location: "eastus", Name: "NetworkSecurityGroups", Amount: 1}
+}
+
+// TestCreateResourceQuotaCheck tests that CreateResource does not create a resource that would exceed its quota.
+func TestCreateResourceQuotaCheck(t *testing.T) {
+	testcases := []struct {
+		name          string
+		spec          azure.ResourceSpecGetter
+		expectedError string
+		expect        func(c *mock_async.MockCreatorMockRecorder, q *mock_async.MockQuotaCheckerMockRecorder)
+	}{
+		{
+			name: "quota is not exceeded",
+			spec: &quotaSpec{dependentSpec{name: "test-resource"}},
+			expect: func(c *mock_async.MockCreatorMockRecorder, q *mock_async.MockQuotaCheckerMockRecorder) {
+				c.Get(gomockinternal.AContext(), gomock.Any()).Return(nil, fakeNotFoundError)
+				q.Usage(gomockinternal.AContext(), "eastus", "NetworkSecurityGroups").Return(int64(4999), int64(5000), true, nil)
+				c.CreateOrUpdateAsync(gomockinternal.AContext(), gomock.Any(), &fakeResourceParameters).Return("test-resource", nil, nil)
+			},
+		},
+		{
+			name:          "quota would be exceeded",
+			spec:          &quotaSpec{dependentSpec{name: "test-resource"}},
+			expectedError: "cannot create resource test-group/test-resource (service: test-service): quota NetworkSecurityGroups in eastus would be exceeded: 5000 of 5000 used, 1 more needed",
+			expect: func(c *mock_async.MockCreatorMockRecorder, q *mock_async.MockQuotaCheckerMockRecorder) {
+				c.Get(gomockinternal.AContext(), gomock.Any()).Return(nil, fakeNotFoundError)
+				q.Usage(gomockinternal.AContext(), "eastus", "NetworkSecurityGroups").Return(int64(5000), int64(5000), true, nil)
+			},
+		},
+		{
+			name: "usage cannot be read",
+			spec: &quotaSpec{dependentSpec{name: "test-resource"}},
+			expect: func(c *mock_async.MockCreatorMockRecorder, q *mock_async.MockQuotaCheckerMockRecorder) {
+				c.Get(gomockinternal.AContext(), gomock.Any()).Return(nil, fakeNotFoundError)
+				q.Usage(gomockinternal.AContext(), "eastus", "NetworkSecurityGroups").Return(int64(0), int64(0), false, fakeInternalError)
+				c.CreateOrUpdateAsync(gomockinternal.AContext(), gomock.Any(), &fakeResourceParameters).Return("test-resource", nil, nil)
+			},
+		},
+		{
+			name: "quota is unknown",
+			spec: &quotaSpec{dependentSpec{name: "test-resource"}},
+			expect: func(c *mock_async.MockCreatorMockRecorder, q *mock_async.MockQuotaCheckerMockRecorder) {
+				c.Get(gomockinternal.AContext(), gomock.Any()).Return(nil, fakeNotFoundError)
+				q.Usage(gomockinternal.AContext(), "eastus", "NetworkSecurityGroups").Return(int64(0), int64(0), false, nil)
+				c.CreateOrUpdateAsync(gomockinternal.AContext(), gomock.Any(), &fakeResourceParameters).Return("test-resource", nil, nil)
+			},
+		},
+		{
+			name: "existing resource is updated without checking the quota",
+			spec: &quotaSpec{dependentSpec{name: "test-resource"}},
+			expect: func(c *mock_async.MockCreatorMockRecorder, q *mock_async.MockQuotaCheckerMockRecorder) {
+				c.Get(gomockinternal.AContext(), gomock.Any()).Return(&fakeExistingResource, nil)
+				c.CreateOrUpdateAsync(gomockinternal.AContext(), gomock.Any(), &fakeResourceParameters).Return("test-resource", nil, nil)
+			},
+		},
+		{
+			name: "spec without quota requirement is not checked",
+			spec: &quotalessSpec{dependentSpec{name: "test-resource"}},
+			expect: func(c *mock_async.MockCreatorMockRecorder, q *mock_async.MockQuotaCheckerMockRecorder) {
+				c.Get(gomockinternal.AContext(), gomock.Any()).Return(nil, fakeNotFoundError)
+				c.CreateOrUpdateAsync(gomockinternal.AContext(), gomock.Any(), &fakeResourceParameters).Return("test-resource", nil, nil)
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			scopeMock := newMockFutureScope(mockCtrl)
+			creatorMock := mock_async.NewMockCreator(mockCtrl)
+			quotaMock := mock_async.NewMockQuotaChecker(mockCtrl)
+
+			scopeMock.EXPECT().GetLongRunningOperationState("test-resource", "test-service").Return(nil)
+			tc.expect(creatorMock.EXPECT(), quotaMock.EXPECT())
+
+			s := New(scopeMock, creatorMock, nil, WithQuotaCheck(quotaMock))
+			result, _, err := s.CreateResource(context.TODO(), tc.spec, "test-service")
+			if tc.expectedError != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tc.expectedError)))
+				g.Expect(azure.IsQuotaExceededError(err)).To(BeTrue())
+				var reconcileErr azure.ReconcileError
+				g.Expect(errors.As(err, &reconcileErr)).To(BeTrue())
+				g.Expect(reconcileErr.IsTerminal()).To(BeTrue())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(result).To(Equal("test-resource"))
+		})
+	}
+}
+
+// quotalessSpec is a spec whose resource does not consume any quota.
+type quotalessSpec struct {
+	dependentSpec
+}
+
+func (s *quotalessSpec) Parameters(existing interface{}) (interface{}, error) {
+	return &fakeResourceParameters, nil
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package async
+
+import (
+	"sync"
+	"time"
+)
+
+// defaultRateLimiterMinBackoff is the backoff after the first throttled request following a successful one.
+const defaultRateLimiterMinBackoff = 5 * time.Second
+
+// RateLimiter bounds the rate of the requests made to Azure by all the services sharing it with a token bucket, and
+// backs all of them off exponentially while Azure throttles requests (429), so that services retrying independently
+// cannot add up to more request pressure than Azure accepts. It never blocks: a request that has to wait should be
+// requeued after the returned delay instead.
+type RateLimiter struct {
+	mu           sync.Mutex
+	rate         float64
+	burst        float64
+	tokens       float64
+	last         time.Time
+	minBackoff   time.Duration
+	maxBackoff   time.Duration
+	backoff      time.Duration
+	blockedUntil time.Time
+	now          func() time.Time
+}
+
+// NewRateLimiter returns a RateLimiter allowing requestsPerSecond requests on average, with bursts of up to burst
+// requests. The backoff after throttled requests doubles with each consecutive throttled request, up to maxBackoff.
+// The rate is not limited if requestsPerSecond is zero, in which case requests only wait for the backoff.
+func NewRateLimiter(requestsPerSecond float64, burst int, maxBackoff time.Duration) *RateLimiter {
+	if maxBackoff < defaultRateLimiterMinBackoff {
+		maxBackoff = defaultRateLimiterMinBackoff
+	}
+	return &RateLimiter{
+		rate:       requestsPerSecond,
+		burst:      float64(burst),
+		tokens:     float64(burst),
+		minBackoff: defaultRateLimiterMinBackoff,
+		maxBackoff: maxBackoff,
+		now:        time.Now,
+	}
+}
+
+// Reserve takes a token for a request and returns zero if the request may be made now. Otherwise, it returns how long
+// to wait before trying again, either because all requests are backed off or because the bucket is empty.
+func (l *RateLimiter) Reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if now.Before(l.blockedUntil) {
+		return l.blockedUntil.Sub(now)
+	}
+	if l.rate <= 0 {
+		return 0
+	}
+	l.refill(now)
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+	return time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+}
+
+// Throttled records that Azure throttled a request, which backs off all the requests until the longest of the
+// backoff and the delay requested by the Retry-After header of the response. It returns how long requests are
+// backed off.
+func (l *RateLimiter) Throttled(retryAfter time.Duration) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.backoff == 0 {
+		l.backoff = l.minBackoff
+	} else if l.backoff *= 2; l.backoff > l.maxBackoff {
+		l.backoff = l.maxBackoff
+	}
+	delay := l.backoff
+	if retryAfter > delay {
+		delay = retryAfter
+	}
+	if until := l.now().Add(delay); until.After(l.blockedUntil) {
+		l.blockedUntil = until
+	}
+	return delay
+}
+
+// Succeeded records that a request was not throttled, which resets the backoff to its initial value.
+func (l *RateLimiter) Succeeded() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.backoff = 0
+}
+
+// refill adds the tokens accumulated since the last refill, up to the size of the bucket.
+func (l *RateLimiter) refill(now time.Time) {
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.last = now
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package async
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestRateLimiterBoundsRequestRate(t *testing.T) {
+	g := NewWithT(t)
+	now := time.Now()
+	limiter := NewRateLimiter(2, 2, time.Minute)
+	limiter.now = func() time.Time { return now }
+
+	g.Expect(limiter.Reserve()).To(BeZero())
+	g.Expect(limiter.Reserve()).To(BeZero())
+	g.Expect(limiter.Reserve()).To(Equal(500 * time.Millisecond))
+
+	// Tokens are added back at the rate of the limiter, up to the size of the bucket.
+	now = now.Add(500 * time.Millisecond)
+	g.Expect(limiter.Reserve()).To(BeZero())
+	g.Expect(limiter.Reserve()).To(Equal(500 * time.Millisecond))
+	now = now.Add(time.Hour)
+	g.Expect(limiter.Reserve()).To(BeZero())
+	g.Expect(limiter.Reserve()).To(BeZero())
+	g.Expect(limiter.Reserve()).NotTo(BeZero())
+}
+
+func TestRateLimiterBacksOffThrottledRequests(t *testing.T) {
+	g := NewWithT(t)
+	now := time.Now()
+	limiter := NewRateLimiter(0, 0, 15*time.Second)
+	limiter.now = func() time.Time { return now }
+
+	g.Expect(limiter.Reserve()).To(BeZero())
+
+	// The backoff doubles with each consecutive throttled request, up to the max backoff.
+	g.Expect(limiter.Throttled(time.Second)).To(Equal(defaultRateLimiterMinBackoff))
+	g.Expect(limiter.Reserve()).To(Equal(defaultRateLimiterMinBackoff))
+	g.Expect(limiter.Throttled(time.Second)).To(Equal(10 * time.Second))
+	g.Expect(limiter.Throttled(time.Second)).To(Equal(15 * time.Second))
+	g.Expect(limiter.Reserve()).To(Equal(15 * time.Second))
+
+	// A longer Retry-After header takes precedence over the backoff.
+	g.Expect(limiter.Throttled(time.Minute)).To(Equal(time.Minute))
+	now = now.Add(time.Minute)
+	g.Expect(limiter.Reserve()).To(BeZero())
+
+	// The backoff goes back to its initial value once a request is not throttled.
+	limiter.Succeeded()
+	g.Expect(limiter.Throttled(0)).To(Equal(defaultRateLimiterMinBackoff))
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package async
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
+)
+
+// RefreshOperation finds out from the resource of the spec itself, rather than from the stored state of its future or
+// poller, whether its ongoing operation is done, e.g. when the stored state is stale and can no longer be polled. A
+// create or update operation is done once the resource is provisioned successfully, and a delete operation once the
+// resource is not found. A done operation is completed as if it had been polled: its long-running operation state is
+// deleted and the resource is returned. It returns false if there is no ongoing operation or it is not done yet.
+func (s *Service) RefreshOperation(ctx context.Context, spec azure.ResourceSpecGetter, serviceName string) (result interface{}, done bool, err error) {
+	ctx = s.withCorrID(ctx)
+	ctx, log, end := tele.StartSpanWithLogger(ctx, "async.Service.RefreshOperation")
+	defer end()
+
+	resourceName := spec.ResourceName()
+	future := s.getFuture(spec, resourceName, serviceName)
+	if future == nil {
+		return nil, false, nil
+	}
+	result, done, err = s.operationDone(ctx, spec, future)
+	if err != nil || !done {
+		return nil, false, err
+	}
+	log.V(2).Info("resource shows the long running operation is done", "service", serviceName, "resource", resourceName, "resourceGroup", future.ResourceGroup, "type", future.Type)
+	s.completeOperation(ctx, log, future, resourceName, serviceName, s.operations.polls(future), result)
+	// The resource does not tell whether the operation applied its parameters, so they are checked again on update.
+	s.forgetAppliedParameters(resourceName, future.ResourceGroup, serviceName)
+	return result, true, nil
+}
+
+// refreshOperation completes the operation tracked by the future if the resource shows it is done, see
+// RefreshOperation. It is the fallback of operations that cannot be polled, either because their stored state cannot
+// be decoded or because polling failed with pollErr, e.g. because the polling URL is no longer known to Azure. It must
+// not be called for operations that reported a failure, see operationFailed. An error getting the resource is only
+// logged and the operation is then handled as if it was not done. The resource is not got if polling was throttled, to
+// not add to the requests Azure throttles. The state of the operation is stored under resourceName, e.g. the first
+// resource of a batch.
+func (s *Service) refreshOperation(ctx context.Context, spec azure.ResourceSpecGetter, resourceName string, future *infrav1.Future, pollErr error) (result interface{}, done bool) {
+	if _, throttled := azure.ThrottledRetryAfter(pollErr, s.requeueAfter); throttled {
+		return nil, false
+	}
+	ctx, log, end := tele.StartSpanWithLogger(ctx, "async.Service.refreshOperation")
+	defer end()
+
+	result, done, err := s.operationDone(ctx, spec, future)
+	if err != nil {
+		log.V(2).Info("failed to get resource to find out if the long running operation is done", "service", future.ServiceName, "resource", resourceName, "resourceGroup", future.ResourceGroup, "error", err.Error())
+		return nil, false
+	}
+	if !done {
+		return nil, false
+	}
+	log.Info("long running operation could not be polled but the resource shows it is done", "service", future.ServiceName, "resource", resourceName, "resourceGroup", future.ResourceGroup, "type", future.Type)
+	s.completeOperation(ctx, log, future, resourceName, future.ServiceName, s.operations.polls(future), result)
+	s.forgetAppliedParameters(resourceName, future.ResourceGroup, future.ServiceName)
+	return result, true
+}
+
+// operationDone gets the resource of the spec to find out whether the operation tracked by the future is done. The
+// resource is always got from Azure, so that a stale resource is never mistaken for the result of the operation.
+func (s *Service) operationDone(ctx context.Context, spec azure.ResourceSpecGetter, future *infrav1.Future) (result interface{}, done bool, err error) {
+	getter := s.getter()
+	if getter == nil {
+		return nil, false, nil
+	}
+	existing, err := getter.Get(ctx, spec)
+	if azure.ResourceNotFound(err) {
+		return nil, future.Type == infrav1.DeleteFuture, nil
+	} else if err != nil {
+		return nil, false, errors.Wrapf(withRequestID(err), "failed to get resource %s/%s (service: %s)", future.ResourceGroup, future.Name, future.ServiceName)
+	}
+	if future.Type == infrav1.DeleteFuture {
+		return nil, false, nil
+	}
+	state, ok := s.ProvisioningState(existing)
+	return existing, ok && state == string(infrav1.Succeeded), nil
+}
+
+// operationFailed returns true if the status of a polled SDK future shows that the operation itself failed or was
+// canceled, rather than the polling request.
+func operationFailed(status string) bool {
+	return strings.EqualFold(status, string(infrav1.Failed)) || strings.EqualFold(status, string(infrav1.Canceled))
+}
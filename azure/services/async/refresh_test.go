@@ -0,0 +1,221 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package async
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/Azure/go-autorest/autorest"
+	azureautorest "github.com/Azure/go-autorest/autorest/azure"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/async/mock_async"
+	gomockinternal "sigs.k8s.io/cluster-api-provider-azure/internal/test/matchers/gomock"
+)
+
+// TestProcessOngoingOperationRefresh tests that an operation that cannot be polled is completed if the resource shows
+// it is done, e.g. when its stored state is stale but the resource was provisioned successfully.
+func TestProcessOngoingOperationRefresh(t *testing.T) {
+	invalidCreateFuture := invalidFuture
+	invalidCreateFuture.Type = infrav1.PutFuture
+	// The state of a future once polling found that its operation failed.
+	failedCreateFuture := validCreateFuture
+	failedCreateFuture.Data = "eyJtZXRob2QiOiJQVVQiLCJwb2xsaW5nTWV0aG9kIjoiTG9jYXRpb24iLCJscm9TdGF0ZSI6IkZhaWxlZCJ9"
+	throttledError := autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}, "Too Many Requests")
+
+	testcases := []struct {
+		name           string
+		expectedError  string
+		expectedResult interface{}
+		expect         func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockCreatorMockRecorder, p *mock_async.MockProvisioningStateGetterMockRecorder)
+	}{
+		{
+			name:           "token is stale but the resource succeeded",
+			expectedResult: &fakeExistingResource,
+			expect: func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockCreatorMockRecorder, p *mock_async.MockProvisioningStateGetterMockRecorder) {
+				s.GetLongRunningOperationState("test-resource", "test-service").Return(&validCreateFuture)
+				c.IsDone(gomockinternal.AContext(), gomock.AssignableToTypeOf(&azureautorest.Future{})).Return(false, fakeInternalError)
+				c.Get(gomockinternal.AContext(), gomock.Any()).Return(&fakeExistingResource, nil)
+				p.ProvisioningState(&fakeExistingResource).Return(string(infrav1.Succeeded), true)
+				s.DeleteLongRunningOperationState("test-resource", "test-service")
+			},
+		},
+		{
+			name:          "token is stale and the resource is still provisioning",
+			expectedError: "failed checking if the operation was complete: #: Internal Server Error: StatusCode=500",
+			expect: func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockCreatorMockRecorder, p *mock_async.MockProvisioningStateGetterMockRecorder) {
+				s.GetLongRunningOperationState("test-resource", "test-service").Return(&validCreateFuture)
+				c.IsDone(gomockinternal.AContext(), gomock.AssignableToTypeOf(&azureautorest.Future{})).Return(false, fakeInternalError)
+				c.Get(gomockinternal.AContext(), gomock.Any()).Return(&fakeExistingResource, nil)
+				p.ProvisioningState(&fakeExistingResource).Return("Updating", true)
+			},
+		},
+		{
+			name:          "token is stale and the resource cannot be got",
+			expectedError: "failed checking if the operation was complete: #: Internal Server Error: StatusCode=500",
+			expect: func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockCreatorMockRecorder, p *mock_async.MockProvisioningStateGetterMockRecorder) {
+				s.GetLongRunningOperationState("test-resource", "test-service").Return(&validCreateFuture)
+				c.IsDone(gomockinternal.AContext(), gomock.AssignableToTypeOf(&azureautorest.Future{})).Return(false, fakeInternalError)
+				c.Get(gomockinternal.AContext(), gomock.Any()).Return(nil, fakeInternalError)
+			},
+		},
+		{
+			name:          "update LRO failed, resource still Succeeded",
+			expectedError: "failed checking if the operation was complete: #: Internal Server Error: StatusCode=500",
+			expect: func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockCreatorMockRecorder, p *mock_async.MockProvisioningStateGetterMockRecorder) {
+				// A failed update leaves the resource provisioned successfully with its previous state, so it is not got.
+				s.GetLongRunningOperationState("test-resource", "test-service").Return(&failedCreateFuture)
+				c.IsDone(gomockinternal.AContext(), gomock.AssignableToTypeOf(&azureautorest.Future{})).Return(false, fakeInternalError)
+			},
+		},
+		{
+			name:          "throttled poll does not get the resource",
+			expectedError: "failed checking if the operation was complete: #: Too Many Requests: StatusCode=429",
+			expect: func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockCreatorMockRecorder, p *mock_async.MockProvisioningStateGetterMockRecorder) {
+				s.GetLongRunningOperationState("test-resource", "test-service").Return(&validCreateFuture)
+				c.IsDone(gomockinternal.AContext(), gomock.AssignableToTypeOf(&azureautorest.Future{})).Return(false, throttledError)
+			},
+		},
+		{
+			name:           "undecodable token of a resource that succeeded",
+			expectedResult: &fakeExistingResource,
+			expect: func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockCreatorMockRecorder, p *mock_async.MockProvisioningStateGetterMockRecorder) {
+				s.GetLongRunningOperationState("test-resource", "test-service").Return(&invalidCreateFuture)
+				c.Get(gomockinternal.AContext(), gomock.Any()).Return(&fakeExistingResource, nil)
+				p.ProvisioningState(&fakeExistingResource).Return(string(infrav1.Succeeded), true)
+				s.DeleteLongRunningOperationState("test-resource", "test-service")
+			},
+		},
+		{
+			name: "undecodable token of a deleted resource",
+			expect: func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockCreatorMockRecorder, p *mock_async.MockProvisioningStateGetterMockRecorder) {
+				s.GetLongRunningOperationState("test-resource", "test-service").Return(&invalidFuture)
+				c.Get(gomockinternal.AContext(), gomock.Any()).Return(nil, fakeNotFoundError)
+				s.DeleteLongRunningOperationState("test-resource", "test-service")
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			scopeMock := newMockFutureScope(mockCtrl)
+			creatorMock := mock_async.NewMockCreator(mockCtrl)
+			stateGetterMock := mock_async.NewMockProvisioningStateGetter(mockCtrl)
+
+			tc.expect(scopeMock.EXPECT(), creatorMock.EXPECT(), stateGetterMock.EXPECT())
+
+			s := New(scopeMock, provisioningStateCreator{creatorMock, stateGetterMock}, nil)
+			s.operations = newOperationTracker()
+			result, err := s.processOngoingOperation(context.TODO(), creatorMock, testSpec("test-resource"), "test-resource", "test-service")
+			if tc.expectedError != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring(tc.expectedError))
+				g.Expect(azure.IsOperationNotDoneError(err)).To(BeFalse())
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+			if tc.expectedResult != nil {
+				g.Expect(result).To(Equal(tc.expectedResult))
+			} else {
+				g.Expect(result).To(BeNil())
+			}
+		})
+	}
+}
+
+// TestRefreshOperation tests that an ongoing operation is completed from the state of its resource.
+func TestRefreshOperation(t *testing.T) {
+	testcases := []struct {
+		name           string
+		expectedDone   bool
+		expectedResult interface{}
+		expectedError  string
+		expect         func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockCreatorMockRecorder, p *mock_async.MockProvisioningStateGetterMockRecorder)
+	}{
+		{
+			name: "no ongoing operation",
+			expect: func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockCreatorMockRecorder, p *mock_async.MockProvisioningStateGetterMockRecorder) {
+				s.GetLongRunningOperationState("test-resource", "test-service").Return(nil)
+			},
+		},
+		{
+			name:           "create operation of a resource that succeeded is done",
+			expectedDone:   true,
+			expectedResult: &fakeExistingResource,
+			expect: func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockCreatorMockRecorder, p *mock_async.MockProvisioningStateGetterMockRecorder) {
+				s.GetLongRunningOperationState("test-resource", "test-service").Return(&validCreateFuture)
+				c.Get(gomockinternal.AContext(), gomock.Any()).Return(&fakeExistingResource, nil)
+				p.ProvisioningState(&fakeExistingResource).Return(string(infrav1.Succeeded), true)
+				s.DeleteLongRunningOperationState("test-resource", "test-service")
+			},
+		},
+		{
+			name: "delete operation of a resource that still exists is not done",
+			expect: func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockCreatorMockRecorder, p *mock_async.MockProvisioningStateGetterMockRecorder) {
+				s.GetLongRunningOperationState("test-resource", "test-service").Return(&validDeleteFuture)
+				c.Get(gomockinternal.AContext(), gomock.Any()).Return(&fakeExistingResource, nil)
+			},
+		},
+		{
+			name:          "resource cannot be got",
+			expectedError: "failed to get resource test-group/test-resource (service: test-service): #: Internal Server Error: StatusCode=500",
+			expect: func(s *mock_async.MockFutureScopeMockRecorder, c *mock_async.MockCreatorMockRecorder, p *mock_async.MockProvisioningStateGetterMockRecorder) {
+				s.GetLongRunningOperationState("test-resource", "test-service").Return(&validCreateFuture)
+				c.Get(gomockinternal.AContext(), gomock.Any()).Return(nil, fakeInternalError)
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			scopeMock := newMockFutureScope(mockCtrl)
+			creatorMock := mock_async.NewMockCreator(mockCtrl)
+			stateGetterMock := mock_async.NewMockProvisioningStateGetter(mockCtrl)
+
+			tc.expect(scopeMock.EXPECT(), creatorMock.EXPECT(), stateGetterMock.EXPECT())
+
+			s := New(scopeMock, provisioningStateCreator{creatorMock, stateGetterMock}, nil)
+			s.operations = newOperationTracker()
+			result, done, err := s.RefreshOperation(context.TODO(), testSpec("test-resource"), "test-service")
+			if tc.expectedError != "" {
+				g.Expect(err).To(MatchError(tc.expectedError))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+			g.Expect(done).To(Equal(tc.expectedDone))
+			if tc.expectedResult != nil {
+				g.Expect(result).To(Equal(tc.expectedResult))
+			} else {
+				g.Expect(result).To(BeNil())
+			}
+		})
+	}
+}
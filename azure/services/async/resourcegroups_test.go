@@ -0,0 +1,105 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package async
+
+import (
+	"context"
+	"testing"
+
+	azureautorest "github.com/Azure/go-autorest/autorest/azure"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/async/mock_async"
+	gomockinternal "sigs.k8s.io/cluster-api-provider-azure/internal/test/matchers/gomock"
+	"sigs.k8s.io/cluster-api-provider-azure/util/futures"
+)
+
+// resourceGroupSpec is a spec of a resource in a given resource group.
+type resourceGroupSpec struct {
+	name          string
+	resourceGroup string
+}
+
+func (s *resourceGroupSpec) ResourceName() string      { return s.name }
+func (s *resourceGroupSpec) ResourceGroupName() string { return s.resourceGroup }
+func (s *resourceGroupSpec) OwnerResourceName() string { return "" }
+func (s *resourceGroupSpec) Parameters(existing interface{}) (interface{}, error) {
+	return &fakeResourceParameters, nil
+}
+
+// resourceGroupFutureScope is a ResourceGroupFutureScope storing the futures in the status of an AzureCluster.
+type resourceGroupFutureScope struct {
+	*mock_async.MockFutureScope
+	cluster *infrav1.AzureCluster
+}
+
+func (s *resourceGroupFutureScope) SetLongRunningOperationState(future *infrav1.Future) {
+	futures.Set(s.cluster, future)
+}
+
+func (s *resourceGroupFutureScope) GetLongRunningOperationState(name, service string) *infrav1.Future {
+	return futures.Get(s.cluster, name, service)
+}
+
+func (s *resourceGroupFutureScope) DeleteLongRunningOperationState(name, service string) {
+	futures.Delete(s.cluster, name, service)
+}
+
+func (s *resourceGroupFutureScope) GetLongRunningOperationStateInResourceGroup(name, service, resourceGroup string) *infrav1.Future {
+	return futures.GetInResourceGroup(s.cluster, name, service, resourceGroup)
+}
+
+func (s *resourceGroupFutureScope) DeleteLongRunningOperationStateInResourceGroup(name, service, resourceGroup string) {
+	futures.DeleteInResourceGroup(s.cluster, name, service, resourceGroup)
+}
+
+// TestCreateResourceInMultipleResourceGroups tests that resources with the same name in different resource groups are
+// reconciled in one pass without mixing up their long-running operation states.
+func TestCreateResourceInMultipleResourceGroups(t *testing.T) {
+	g := NewWithT(t)
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	creatorMock := mock_async.NewMockCreator(mockCtrl)
+
+	clusterSpec := &resourceGroupSpec{name: "test-resource", resourceGroup: "cluster-group"}
+	networkSpec := &resourceGroupSpec{name: "test-resource", resourceGroup: "network-group"}
+	networkFuture := validCreateFuture
+	networkFuture.ResourceGroup = "network-group"
+	scope := &resourceGroupFutureScope{MockFutureScope: newMockFutureScope(mockCtrl), cluster: &infrav1.AzureCluster{}}
+	scope.SetLongRunningOperationState(&networkFuture)
+
+	// The resource in the resource group of the cluster has no ongoing operation, so it is created.
+	creatorMock.EXPECT().Get(gomockinternal.AContext(), clusterSpec).Return(nil, fakeNotFoundError)
+	creatorMock.EXPECT().CreateOrUpdateAsync(gomockinternal.AContext(), clusterSpec, &fakeResourceParameters).Return("test-resource", nil, nil)
+	// The resource in the networking resource group is still being created.
+	creatorMock.EXPECT().IsDone(gomockinternal.AContext(), gomock.AssignableToTypeOf(&azureautorest.Future{})).Return(false, nil)
+
+	s := New(scope, creatorMock, nil)
+	result, changed, err := s.CreateResource(context.TODO(), clusterSpec, "test-service")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(changed).To(BeTrue())
+	g.Expect(result).To(Equal("test-resource"))
+
+	_, changed, err = s.CreateResource(context.TODO(), networkSpec, "test-service")
+	g.Expect(azure.IsOperationNotDoneError(err)).To(BeTrue())
+	g.Expect(changed).To(BeFalse())
+
+	g.Expect(scope.GetLongRunningOperationStateInResourceGroup("test-resource", "test-service", "cluster-group")).To(BeNil())
+	g.Expect(scope.GetLongRunningOperationStateInResourceGroup("test-resource", "test-service", "network-group")).NotTo(BeNil())
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package async
+
+import (
+	"sync"
+	"time"
+)
+
+// defaultSchedulerWaitExpiry is how long an owner keeps its place in the queue without asking for a slot again.
+// It is well above the default requeue interval so that owners polling at that interval keep their turn.
+const defaultSchedulerWaitExpiry = time.Minute
+
+// FairScheduler bounds the number of operations being started at the same time and hands out slots to owning objects
+// in round-robin order, so that an owner with many resources cannot starve the others of the shared budget.
+// It never blocks: an owner that is refused a slot is queued and should requeue and try again.
+type FairScheduler struct {
+	mu         sync.Mutex
+	capacity   int
+	inFlight   int
+	waiting    []schedulerWaiter
+	waitExpiry time.Duration
+	now        func() time.Time
+}
+
+type schedulerWaiter struct {
+	owner    string
+	lastSeen time.Time
+}
+
+// NewFairScheduler returns a FairScheduler that allows up to capacity operations to be started concurrently.
+func NewFairScheduler(capacity int) *FairScheduler {
+	return &FairScheduler{
+		capacity:   capacity,
+		waitExpiry: defaultSchedulerWaitExpiry,
+		now:        time.Now,
+	}
+}
+
+// TryAcquire returns true if the owner may start an operation now, in which case Release must be called once the
+// operation has been started. Free slots go to the owners that have waited the longest.
+func (f *FairScheduler) TryAcquire(owner string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.expireWaiters()
+	free := f.capacity - f.inFlight
+	position := f.position(owner)
+	switch {
+	case position >= 0 && position < free:
+		f.waiting = append(f.waiting[:position], f.waiting[position+1:]...)
+	case position < 0 && len(f.waiting) < free:
+	default:
+		f.enqueue(owner, position)
+		return false
+	}
+	f.inFlight++
+	return true
+}
+
+// Release frees a slot acquired with TryAcquire.
+func (f *FairScheduler) Release() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.inFlight > 0 {
+		f.inFlight--
+	}
+}
+
+// position returns the index of the owner in the queue, or -1 if it is not waiting.
+func (f *FairScheduler) position(owner string) int {
+	for i := range f.waiting {
+		if f.waiting[i].owner == owner {
+			return i
+		}
+	}
+	return -1
+}
+
+// enqueue adds the owner to the back of the queue, or refreshes its place if it is already waiting.
+func (f *FairScheduler) enqueue(owner string, position int) {
+	if position >= 0 {
+		f.waiting[position].lastSeen = f.now()
+		return
+	}
+	f.waiting = append(f.waiting, schedulerWaiter{owner: owner, lastSeen: f.now()})
+}
+
+// expireWaiters drops owners that stopped asking for a slot so they don't hold up the queue.
+func (f *FairScheduler) expireWaiters() {
+	active := f.waiting[:0]
+	for _, w := range f.waiting {
+		if f.now().Sub(w.lastSeen) < f.waitExpiry {
+			active = append(active, w)
+		}
+	}
+	f.waiting = active
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package async
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestFairSchedulerInterleavesOwners(t *testing.T) {
+	g := NewWithT(t)
+	scheduler := NewFairScheduler(1)
+
+	// Every round, owner "busy" asks for a slot before anybody else, and the slot stays taken until the next round.
+	// With first-come-first-served scheduling "busy" would start every operation: "a" and "b" must still get their turn.
+	var started []string
+	holding := false
+	for round := 0; round < 6; round++ {
+		if holding {
+			scheduler.Release()
+			holding = false
+		}
+		for _, owner := range []string{"busy", "a", "b"} {
+			if scheduler.TryAcquire(owner) {
+				started = append(started, owner)
+				holding = true
+			}
+		}
+	}
+	g.Expect(started).To(Equal([]string{"busy", "a", "b", "busy", "a", "b"}))
+}
+
+func TestFairSchedulerBoundsInFlightOperations(t *testing.T) {
+	g := NewWithT(t)
+	scheduler := NewFairScheduler(2)
+
+	g.Expect(scheduler.TryAcquire("a")).To(BeTrue())
+	g.Expect(scheduler.TryAcquire("a")).To(BeTrue())
+	g.Expect(scheduler.TryAcquire("b")).To(BeFalse())
+	g.Expect(scheduler.TryAcquire("a")).To(BeFalse())
+
+	// "b" asked first, so it gets the first free slot.
+	scheduler.Release()
+	g.Expect(scheduler.TryAcquire("a")).To(BeFalse())
+	g.Expect(scheduler.TryAcquire("b")).To(BeTrue())
+	scheduler.Release()
+	g.Expect(scheduler.TryAcquire("a")).To(BeTrue())
+}
+
+func TestFairSchedulerExpiresWaiters(t *testing.T) {
+	g := NewWithT(t)
+	now := time.Now()
+	scheduler := NewFairScheduler(1)
+	scheduler.now = func() time.Time { return now }
+
+	g.Expect(scheduler.TryAcquire("a")).To(BeTrue())
+	g.Expect(scheduler.TryAcquire("gone")).To(BeFalse())
+	scheduler.Release()
+
+	// "gone" never asks again, so once its place expires it does not hold up "a".
+	g.Expect(scheduler.TryAcquire("a")).To(BeFalse())
+	now = now.Add(defaultSchedulerWaitExpiry)
+	g.Expect(scheduler.TryAcquire("a")).To(BeTrue())
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package async
+
+import (
+	"sync"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// synchronizedScope is a FutureScope that serializes access to another FutureScope.
+type synchronizedScope struct {
+	mu    sync.Locker
+	scope FutureScope
+}
+
+// NewSynchronizedScope returns a FutureScope that can be used by services processing specs concurrently. Calls are
+// serialized with mu so that long-running operation states and conditions written in parallel are neither lost nor
+// corrupted. Services updating the scope themselves should hold mu while doing so, so that all the updates of the
+// scope are serialized by a single lock.
+func NewSynchronizedScope(scope FutureScope, mu sync.Locker) FutureScope {
+	return &synchronizedScope{scope: scope, mu: mu}
+}
+
+// SetLongRunningOperationState sets a future in the underlying scope.
+func (s *synchronizedScope) SetLongRunningOperationState(future *infrav1.Future) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scope.SetLongRunningOperationState(future)
+}
+
+// GetLongRunningOperationState gets a future from the underlying scope.
+func (s *synchronizedScope) GetLongRunningOperationState(name, service string) *infrav1.Future {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.scope.GetLongRunningOperationState(name, service)
+}
+
+// GetLongRunningOperationStates gets all the futures from the underlying scope.
+func (s *synchronizedScope) GetLongRunningOperationStates() []*infrav1.Future {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.scope.GetLongRunningOperationStates()
+}
+
+// DeleteLongRunningOperationState deletes a future from the underlying scope.
+func (s *synchronizedScope) DeleteLongRunningOperationState(name, service string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scope.DeleteLongRunningOperationState(name, service)
+}
+
+// UpdatePutStatus updates a condition of the underlying scope after a PUT operation.
+func (s *synchronizedScope) UpdatePutStatus(condition clusterv1.ConditionType, service string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scope.UpdatePutStatus(condition, service, err)
+}
+
+// UpdateDeleteStatus updates a condition of the underlying scope after a DELETE operation.
+func (s *synchronizedScope) UpdateDeleteStatus(condition clusterv1.ConditionType, service string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scope.UpdateDeleteStatus(condition, service, err)
+}
+
+// UpdatePatchStatus updates a condition of the underlying scope after a PATCH operation.
+func (s *synchronizedScope) UpdatePatchStatus(condition clusterv1.ConditionType, service string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scope.UpdatePatchStatus(condition, service, err)
+}
+
+// IsServicePaused returns true if the service is paused in the underlying scope.
+func (s *synchronizedScope) IsServicePaused(service string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.scope.IsServicePaused(service)
+}
+
+// AppliedParameters gets the parameters applied to a resource from the underlying scope, if it records them.
+func (s *synchronizedScope) AppliedParameters(name, service, resourceGroup string) (parametersHash, resourceHash string) {
+	scope, ok := s.scope.(AppliedParametersScope)
+	if !ok {
+		return "", ""
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return scope.AppliedParameters(name, service, resourceGroup)
+}
+
+// SetAppliedParameters records the parameters applied to a resource in the underlying scope, if it records them.
+func (s *synchronizedScope) SetAppliedParameters(name, service, resourceGroup, parametersHash, resourceHash string) {
+	scope, ok := s.scope.(AppliedParametersScope)
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	scope.SetAppliedParameters(name, service, resourceGroup, parametersHash, resourceHash)
+}
+
+// DeleteAppliedParameters drops the parameters applied to a resource from the underlying scope, if it records them.
+func (s *synchronizedScope) DeleteAppliedParameters(name, service, resourceGroup string) {
+	scope, ok := s.scope.(AppliedParametersScope)
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	scope.DeleteAppliedParameters(name, service, resourceGroup)
+}
+
+// SetCompletedOperation records a completed operation in the underlying scope, if it keeps such records.
+func (s *synchronizedScope) SetCompletedOperation(operation infrav1.CompletedOperation) {
+	scope, ok := s.scope.(CompletedOperationScope)
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	scope.SetCompletedOperation(operation)
+}
+
+// GetLongRunningOperationStateInResourceGroup gets the future of a resource in a resource group from the underlying
+// scope, or the future of the resource name if the underlying scope does not tell resource groups apart.
+func (s *synchronizedScope) GetLongRunningOperationStateInResourceGroup(name, service, resourceGroup string) *infrav1.Future {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if scope, ok := s.scope.(ResourceGroupFutureScope); ok {
+		return scope.GetLongRunningOperationStateInResourceGroup(name, service, resourceGroup)
+	}
+	return s.scope.GetLongRunningOperationState(name, service)
+}
+
+// DeleteLongRunningOperationStateInResourceGroup deletes the future of a resource in a resource group from the
+// underlying scope, or the future of the resource name if the underlying scope does not tell resource groups apart.
+func (s *synchronizedScope) DeleteLongRunningOperationStateInResourceGroup(name, service, resourceGroup string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if scope, ok := s.scope.(ResourceGroupFutureScope); ok {
+		scope.DeleteLongRunningOperationStateInResourceGroup(name, service, resourceGroup)
+		return
+	}
+	s.scope.DeleteLongRunningOperationState(name, service)
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package async
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/async/mock_async"
+)
+
+func TestSynchronizedScopeKeepsConcurrentWrites(t *testing.T) {
+	g := NewWithT(t)
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	futures := map[string]*infrav1.Future{}
+	scopeMock := mock_async.NewMockFutureScope(mockCtrl)
+	scopeMock.EXPECT().SetLongRunningOperationState(gomock.Any()).DoAndReturn(func(future *infrav1.Future) {
+		// Writing to a map concurrently is detected by the runtime, so this fails if calls are not serialized.
+		futures[future.Name] = future
+	}).Times(50)
+
+	s := NewSynchronizedScope(scopeMock, &sync.Mutex{})
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			s.SetLongRunningOperationState(&infrav1.Future{Name: fmt.Sprintf("test-resource-%d", i), ServiceName: "test-service"})
+		}(i)
+	}
+	wg.Wait()
+	g.Expect(futures).To(HaveLen(50))
+}
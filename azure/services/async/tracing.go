@@ -0,0 +1,53 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package async
+
+import (
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+)
+
+// resourceAttributes returns the span attributes identifying a resource, so that traces can be filtered by service,
+// resource group or resource name.
+func resourceAttributes(serviceName, rgName, resourceName string) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("serviceName", serviceName),
+		attribute.String("resourceGroup", rgName),
+		attribute.String("resourceName", resourceName),
+	}
+}
+
+// setRequeuedAttribute records on the span whether the operation is requeued, i.e. whether it returned an error that
+// is retried later rather than a failure.
+func setRequeuedAttribute(span trace.Span, err error) {
+	span.SetAttributes(attribute.Bool("requeued", isRequeued(err)))
+}
+
+// isRequeued returns true if the error requeues the operation, i.e. the operation is not done yet or the error is
+// transient.
+func isRequeued(err error) bool {
+	if err == nil {
+		return false
+	}
+	if azure.IsOperationNotDoneError(err) {
+		return true
+	}
+	var reconcileErr azure.ReconcileError
+	return errors.As(err, &reconcileErr) && reconcileErr.IsTransient()
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package async
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/mock_azure"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/async/mock_async"
+)
+
+func TestIsRequeued(t *testing.T) {
+	testcases := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{
+			name:     "no error",
+			err:      nil,
+			expected: false,
+		},
+		{
+			name:     "operation not done",
+			err:      azure.NewOperationNotDoneError(&validCreateFuture),
+			expected: true,
+		},
+		{
+			name:     "operation not done wrapped in a transient error",
+			err:      azure.WithTransientError(azure.NewOperationNotDoneError(&validCreateFuture), 15*time.Second),
+			expected: true,
+		},
+		{
+			name:     "transient error",
+			err:      azure.WithTransientError(errors.New("throttled"), time.Minute),
+			expected: true,
+		},
+		{
+			name:     "terminal error",
+			err:      azure.WithTerminalError(errors.New("invalid")),
+			expected: false,
+		},
+		{
+			name:     "other error",
+			err:      errors.New("this is an error"),
+			expected: false,
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			g.Expect(isRequeued(tc.err)).To(Equal(tc.expected))
+		})
+	}
+}
+
+// TestResourceSpanAttributes is not parallel since it records the spans of the global tracer provider.
+func TestResourceSpanAttributes(t *testing.T) {
+	g := NewWithT(t)
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(previous)
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	scopeMock := newMockFutureScope(mockCtrl)
+	deleterMock := mock_async.NewMockDeleter(mockCtrl)
+	specMock := mock_azure.NewMockResourceSpecGetter(mockCtrl)
+	specMock.EXPECT().ResourceName().Return("test-resource")
+	specMock.EXPECT().ResourceGroupName().Return("test-group")
+	scopeMock.EXPECT().GetLongRunningOperationState("test-resource", "test-service").Times(2).Return(&validDeleteFuture)
+	deleterMock.EXPECT().IsDone(gomock.Any(), gomock.Any()).Return(false, nil)
+
+	s := New(scopeMock, nil, deleterMock)
+	_, err := s.DeleteResource(context.TODO(), specMock, "test-service")
+	g.Expect(azure.IsOperationNotDoneError(err)).To(BeTrue())
+
+	var attributes []attribute.KeyValue
+	for _, span := range recorder.Ended() {
+		if span.Name() == "async.Service.DeleteResource" {
+			attributes = span.Attributes()
+		}
+	}
+	g.Expect(attributes).To(ContainElements(
+		attribute.String("serviceName", "test-service"),
+		attribute.String("resourceGroup", "test-group"),
+		attribute.String("resourceName", "test-resource"),
+		attribute.Bool("requeued", true),
+	))
+}
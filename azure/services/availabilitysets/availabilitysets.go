@@ -47,13 +47,13 @@ type Service struct {
 }
 
 // New creates a new availability sets service.
-func New(scope AvailabilitySetScope, skuCache *resourceskus.Cache) *Service {
+func New(scope AvailabilitySetScope, skuCache *resourceskus.Cache, opts ...async.Option) *Service {
 	client := NewClient(scope)
 	return &Service{
 		Scope:            scope,
 		Getter:           client,
 		resourceSKUCache: skuCache,
-		Reconciler:       async.New(scope, client, client),
+		Reconciler:       async.New(scope, client, client, opts...),
 	}
 }
 
@@ -62,12 +62,12 @@ func (s *Service) Reconcile(ctx context.Context) error {
 	ctx, log, done := tele.StartSpanWithLogger(ctx, "availabilitysets.Service.Reconcile")
 	defer done()
 
-	ctx, cancel := context.WithTimeout(ctx, reconciler.DefaultAzureServiceReconcileTimeout)
+	ctx, cancel := context.WithTimeout(ctx, reconciler.ServiceReconcileTimeout(s.Reconciler))
 	defer cancel()
 
 	var err error
 	if setSpec := s.Scope.AvailabilitySetSpec(); setSpec != nil {
-		_, err = s.CreateResource(ctx, setSpec, serviceName)
+		_, _, err = s.CreateResource(ctx, setSpec, serviceName)
 	} else {
 		log.V(2).Info("skip creation when no availability set spec is found")
 		return nil
@@ -82,7 +82,7 @@ func (s *Service) Delete(ctx context.Context) error {
 	ctx, log, done := tele.StartSpanWithLogger(ctx, "availabilitysets.Service.Delete")
 	defer done()
 
-	ctx, cancel := context.WithTimeout(ctx, reconciler.DefaultAzureServiceReconcileTimeout)
+	ctx, cancel := context.WithTimeout(ctx, reconciler.ServiceReconcileTimeout(s.Reconciler))
 	defer cancel()
 
 	var resultingErr error
@@ -106,7 +106,7 @@ func (s *Service) Delete(ctx context.Context) error {
 			if availabilitySet.AvailabilitySetProperties != nil && availabilitySet.VirtualMachines != nil && len(*availabilitySet.VirtualMachines) > 0 {
 				log.V(2).Info("skip deleting availability set with VMs", "availability set", setSpec.ResourceName())
 			} else {
-				resultingErr = s.DeleteResource(ctx, setSpec, serviceName)
+				_, resultingErr = s.DeleteResource(ctx, setSpec, serviceName)
 			}
 		}
 	}
@@ -84,7 +84,7 @@ func TestReconcileAvailabilitySets(t *testing.T) {
 			expectedError: "",
 			expect: func(s *mock_availabilitysets.MockAvailabilitySetScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				s.AvailabilitySetSpec().Return(&fakeSetSpec)
-				r.CreateResource(gomockinternal.AContext(), &fakeSetSpec, serviceName).Return(nil, nil)
+				r.CreateResource(gomockinternal.AContext(), &fakeSetSpec, serviceName).Return(nil, false, nil)
 				s.UpdatePutStatus(infrav1.AvailabilitySetReadyCondition, serviceName, nil)
 			},
 		},
@@ -100,7 +100,7 @@ func TestReconcileAvailabilitySets(t *testing.T) {
 			expectedError: "some error with parameters",
 			expect: func(s *mock_availabilitysets.MockAvailabilitySetScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				s.AvailabilitySetSpec().Return(&fakeSetSpecMissing)
-				r.CreateResource(gomockinternal.AContext(), &fakeSetSpecMissing, serviceName).Return(nil, parameterError)
+				r.CreateResource(gomockinternal.AContext(), &fakeSetSpecMissing, serviceName).Return(nil, false, parameterError)
 				s.UpdatePutStatus(infrav1.AvailabilitySetReadyCondition, serviceName, parameterError)
 			},
 		},
@@ -109,7 +109,7 @@ func TestReconcileAvailabilitySets(t *testing.T) {
 			expectedError: "#: Internal Server Error: StatusCode=500",
 			expect: func(s *mock_availabilitysets.MockAvailabilitySetScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				s.AvailabilitySetSpec().Return(&fakeSetSpec)
-				r.CreateResource(gomockinternal.AContext(), &fakeSetSpec, serviceName).Return(nil, internalError)
+				r.CreateResource(gomockinternal.AContext(), &fakeSetSpec, serviceName).Return(nil, false, internalError)
 				s.UpdatePutStatus(infrav1.AvailabilitySetReadyCondition, serviceName, internalError)
 			},
 		},
@@ -156,7 +156,7 @@ func TestDeleteAvailabilitySets(t *testing.T) {
 				s.AvailabilitySetSpec().Return(&fakeSetSpec)
 				gomock.InOrder(
 					m.Get(gomockinternal.AContext(), &fakeSetSpec).Return(compute.AvailabilitySet{}, nil),
-					r.DeleteResource(gomockinternal.AContext(), &fakeSetSpec, serviceName).Return(nil),
+					r.DeleteResource(gomockinternal.AContext(), &fakeSetSpec, serviceName).Return(false, nil),
 					s.UpdateDeleteStatus(infrav1.AvailabilitySetReadyCondition, serviceName, nil),
 				)
 			},
@@ -175,7 +175,7 @@ func TestDeleteAvailabilitySets(t *testing.T) {
 				s.AvailabilitySetSpec().Return(&fakeSetSpecMissing)
 				gomock.InOrder(
 					m.Get(gomockinternal.AContext(), &fakeSetSpecMissing).Return(compute.AvailabilitySet{}, nil),
-					r.DeleteResource(gomockinternal.AContext(), &fakeSetSpecMissing, serviceName).Return(nil),
+					r.DeleteResource(gomockinternal.AContext(), &fakeSetSpecMissing, serviceName).Return(false, nil),
 					s.UpdateDeleteStatus(infrav1.AvailabilitySetReadyCondition, serviceName, nil),
 				)
 			},
@@ -231,7 +231,7 @@ func TestDeleteAvailabilitySets(t *testing.T) {
 				s.AvailabilitySetSpec().Return(&fakeSetSpec)
 				gomock.InOrder(
 					m.Get(gomockinternal.AContext(), &fakeSetSpec).Return(compute.AvailabilitySet{}, nil),
-					r.DeleteResource(gomockinternal.AContext(), &fakeSetSpec, serviceName).Return(internalError),
+					r.DeleteResource(gomockinternal.AContext(), &fakeSetSpec, serviceName).Return(false, internalError),
 					s.UpdateDeleteStatus(infrav1.AvailabilitySetReadyCondition, serviceName, internalError),
 				)
 			},
@@ -233,6 +233,20 @@ func (mr *MockAvailabilitySetScopeMockRecorder) GetLongRunningOperationState(arg
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLongRunningOperationState", reflect.TypeOf((*MockAvailabilitySetScope)(nil).GetLongRunningOperationState), arg0, arg1)
 }
 
+// GetLongRunningOperationStates mocks base method.
+func (m *MockAvailabilitySetScope) GetLongRunningOperationStates() []*v1beta1.Future {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLongRunningOperationStates")
+	ret0, _ := ret[0].([]*v1beta1.Future)
+	return ret0
+}
+
+// GetLongRunningOperationStates indicates an expected call of GetLongRunningOperationStates.
+func (mr *MockAvailabilitySetScopeMockRecorder) GetLongRunningOperationStates() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLongRunningOperationStates", reflect.TypeOf((*MockAvailabilitySetScope)(nil).GetLongRunningOperationStates))
+}
+
 // HashKey mocks base method.
 func (m *MockAvailabilitySetScope) HashKey() string {
 	m.ctrl.T.Helper()
@@ -247,6 +261,20 @@ func (mr *MockAvailabilitySetScopeMockRecorder) HashKey() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HashKey", reflect.TypeOf((*MockAvailabilitySetScope)(nil).HashKey))
 }
 
+// IsServicePaused mocks base method.
+func (m *MockAvailabilitySetScope) IsServicePaused(arg0 string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsServicePaused", arg0)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsServicePaused indicates an expected call of IsServicePaused.
+func (mr *MockAvailabilitySetScopeMockRecorder) IsServicePaused(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsServicePaused", reflect.TypeOf((*MockAvailabilitySetScope)(nil).IsServicePaused), arg0)
+}
+
 // Location mocks base method.
 func (m *MockAvailabilitySetScope) Location() string {
 	m.ctrl.T.Helper()
@@ -42,11 +42,11 @@ type Service struct {
 }
 
 // New creates a new service.
-func New(scope BastionScope) *Service {
+func New(scope BastionScope, opts ...async.Option) *Service {
 	client := newClient(scope)
 	return &Service{
 		Scope:      scope,
-		Reconciler: async.New(scope, client, client),
+		Reconciler: async.New(scope, client, client, opts...),
 	}
 }
 
@@ -55,12 +55,12 @@ func (s *Service) Reconcile(ctx context.Context) error {
 	ctx, _, done := tele.StartSpanWithLogger(ctx, "bastionhosts.Service.Reconcile")
 	defer done()
 
-	ctx, cancel := context.WithTimeout(ctx, reconciler.DefaultAzureServiceReconcileTimeout)
+	ctx, cancel := context.WithTimeout(ctx, reconciler.ServiceReconcileTimeout(s.Reconciler))
 	defer cancel()
 
 	var resultingErr error
 	if bastionSpec := s.Scope.AzureBastionSpec(); bastionSpec != nil {
-		_, resultingErr = s.CreateResource(ctx, bastionSpec, serviceName)
+		_, _, resultingErr = s.CreateResource(ctx, bastionSpec, serviceName)
 	} else {
 		return nil
 	}
@@ -74,12 +74,12 @@ func (s *Service) Delete(ctx context.Context) error {
 	ctx, _, done := tele.StartSpanWithLogger(ctx, "bastionhosts.Service.Delete")
 	defer done()
 
-	ctx, cancel := context.WithTimeout(ctx, reconciler.DefaultAzureServiceReconcileTimeout)
+	ctx, cancel := context.WithTimeout(ctx, reconciler.ServiceReconcileTimeout(s.Reconciler))
 	defer cancel()
 
 	var resultingErr error
 	if bastionSpec := s.Scope.AzureBastionSpec(); bastionSpec != nil {
-		resultingErr = s.DeleteResource(ctx, bastionSpec, serviceName)
+		_, resultingErr = s.DeleteResource(ctx, bastionSpec, serviceName)
 	} else {
 		return nil
 	}
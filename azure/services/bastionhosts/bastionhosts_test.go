@@ -60,7 +60,7 @@ func TestReconcileBastionHosts(t *testing.T) {
 			expectedError: "",
 			expect: func(s *mock_bastionhosts.MockBastionScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				s.AzureBastionSpec().Return(&fakeAzureBastionSpec)
-				r.CreateResource(gomockinternal.AContext(), &fakeAzureBastionSpec, serviceName).Return(nil, nil)
+				r.CreateResource(gomockinternal.AContext(), &fakeAzureBastionSpec, serviceName).Return(nil, false, nil)
 				s.UpdatePutStatus(infrav1.BastionHostReadyCondition, serviceName, nil)
 			},
 		},
@@ -76,7 +76,7 @@ func TestReconcileBastionHosts(t *testing.T) {
 			expectedError: internalError.Error(),
 			expect: func(s *mock_bastionhosts.MockBastionScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				s.AzureBastionSpec().Return(&fakeAzureBastionSpec)
-				r.CreateResource(gomockinternal.AContext(), &fakeAzureBastionSpec, serviceName).Return(nil, internalError)
+				r.CreateResource(gomockinternal.AContext(), &fakeAzureBastionSpec, serviceName).Return(nil, false, internalError)
 				s.UpdatePutStatus(infrav1.BastionHostReadyCondition, serviceName, internalError)
 			},
 		},
@@ -121,7 +121,7 @@ func TestDeleteBastionHost(t *testing.T) {
 			expectedError: "",
 			expect: func(s *mock_bastionhosts.MockBastionScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				s.AzureBastionSpec().Return(&fakeAzureBastionSpec)
-				r.DeleteResource(gomockinternal.AContext(), &fakeAzureBastionSpec, serviceName).Return(nil)
+				r.DeleteResource(gomockinternal.AContext(), &fakeAzureBastionSpec, serviceName).Return(false, nil)
 				s.UpdateDeleteStatus(infrav1.BastionHostReadyCondition, serviceName, nil)
 			},
 		},
@@ -130,7 +130,7 @@ func TestDeleteBastionHost(t *testing.T) {
 			expectedError: internalError.Error(),
 			expect: func(s *mock_bastionhosts.MockBastionScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				s.AzureBastionSpec().Return(&fakeAzureBastionSpec)
-				r.DeleteResource(gomockinternal.AContext(), &fakeAzureBastionSpec, serviceName).Return(internalError)
+				r.DeleteResource(gomockinternal.AContext(), &fakeAzureBastionSpec, serviceName).Return(false, internalError)
 				s.UpdateDeleteStatus(infrav1.BastionHostReadyCondition, serviceName, internalError)
 			},
 		},
@@ -303,6 +303,20 @@ func (mr *MockBastionScopeMockRecorder) GetLongRunningOperationState(arg0, arg1
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLongRunningOperationState", reflect.TypeOf((*MockBastionScope)(nil).GetLongRunningOperationState), arg0, arg1)
 }
 
+// GetLongRunningOperationStates mocks base method.
+func (m *MockBastionScope) GetLongRunningOperationStates() []*v1beta1.Future {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLongRunningOperationStates")
+	ret0, _ := ret[0].([]*v1beta1.Future)
+	return ret0
+}
+
+// GetLongRunningOperationStates indicates an expected call of GetLongRunningOperationStates.
+func (mr *MockBastionScopeMockRecorder) GetLongRunningOperationStates() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLongRunningOperationStates", reflect.TypeOf((*MockBastionScope)(nil).GetLongRunningOperationStates))
+}
+
 // GetPrivateDNSZoneName mocks base method.
 func (m *MockBastionScope) GetPrivateDNSZoneName() string {
 	m.ctrl.T.Helper()
@@ -359,6 +373,20 @@ func (mr *MockBastionScopeMockRecorder) IsIPv6Enabled() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsIPv6Enabled", reflect.TypeOf((*MockBastionScope)(nil).IsIPv6Enabled))
 }
 
+// IsServicePaused mocks base method.
+func (m *MockBastionScope) IsServicePaused(arg0 string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsServicePaused", arg0)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsServicePaused indicates an expected call of IsServicePaused.
+func (mr *MockBastionScopeMockRecorder) IsServicePaused(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsServicePaused", reflect.TypeOf((*MockBastionScope)(nil).IsServicePaused), arg0)
+}
+
 // IsVnetManaged mocks base method.
 func (m *MockBastionScope) IsVnetManaged() bool {
 	m.ctrl.T.Helper()
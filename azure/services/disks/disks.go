@@ -42,11 +42,11 @@ type Service struct {
 }
 
 // New creates a new disks service.
-func New(scope DiskScope) *Service {
+func New(scope DiskScope, opts ...async.Option) *Service {
 	client := newClient(scope)
 	return &Service{
 		Scope:      scope,
-		Reconciler: async.New(scope, nil, client),
+		Reconciler: async.New(scope, nil, client, opts...),
 	}
 }
 
@@ -64,7 +64,7 @@ func (s *Service) Delete(ctx context.Context) error {
 	ctx, _, done := tele.StartSpanWithLogger(ctx, "disks.Service.Delete")
 	defer done()
 
-	ctx, cancel := context.WithTimeout(ctx, reconciler.DefaultAzureServiceReconcileTimeout)
+	ctx, cancel := context.WithTimeout(ctx, reconciler.ServiceReconcileTimeout(s.Reconciler))
 	defer cancel()
 
 	specs := s.Scope.DiskSpecs()
@@ -77,7 +77,7 @@ func (s *Service) Delete(ctx context.Context) error {
 	//  Order of precedence (highest -> lowest) is: error that is not an operationNotDoneError (i.e. error creating) -> operationNotDoneError (i.e. creating in progress) -> no error (i.e. created)
 	var result error
 	for _, diskSpec := range specs {
-		if err := s.DeleteResource(ctx, diskSpec, serviceName); err != nil {
+		if _, err := s.DeleteResource(ctx, diskSpec, serviceName); err != nil {
 			if !azure.IsOperationNotDoneError(err) || result == nil {
 				result = err
 			}
@@ -69,8 +69,8 @@ func TestDeleteDisk(t *testing.T) {
 			expect: func(s *mock_disks.MockDiskScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				s.DiskSpecs().Return(fakeDiskSpecs)
 				gomock.InOrder(
-					r.DeleteResource(gomockinternal.AContext(), &diskSpec1, serviceName).Return(nil),
-					r.DeleteResource(gomockinternal.AContext(), &diskSpec2, serviceName).Return(nil),
+					r.DeleteResource(gomockinternal.AContext(), &diskSpec1, serviceName).Return(false, nil),
+					r.DeleteResource(gomockinternal.AContext(), &diskSpec2, serviceName).Return(false, nil),
 					s.UpdateDeleteStatus(infrav1.DisksReadyCondition, serviceName, nil),
 				)
 			},
@@ -81,8 +81,8 @@ func TestDeleteDisk(t *testing.T) {
 			expect: func(s *mock_disks.MockDiskScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				s.DiskSpecs().Return(fakeDiskSpecs)
 				gomock.InOrder(
-					r.DeleteResource(gomockinternal.AContext(), &diskSpec1, serviceName).Return(nil),
-					r.DeleteResource(gomockinternal.AContext(), &diskSpec2, serviceName).Return(nil),
+					r.DeleteResource(gomockinternal.AContext(), &diskSpec1, serviceName).Return(false, nil),
+					r.DeleteResource(gomockinternal.AContext(), &diskSpec2, serviceName).Return(false, nil),
 					s.UpdateDeleteStatus(infrav1.DisksReadyCondition, serviceName, nil),
 				)
 			},
@@ -93,8 +93,8 @@ func TestDeleteDisk(t *testing.T) {
 			expect: func(s *mock_disks.MockDiskScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				s.DiskSpecs().Return(fakeDiskSpecs)
 				gomock.InOrder(
-					r.DeleteResource(gomockinternal.AContext(), &diskSpec1, serviceName).Return(internalError),
-					r.DeleteResource(gomockinternal.AContext(), &diskSpec2, serviceName).Return(nil),
+					r.DeleteResource(gomockinternal.AContext(), &diskSpec1, serviceName).Return(false, internalError),
+					r.DeleteResource(gomockinternal.AContext(), &diskSpec2, serviceName).Return(false, nil),
 					s.UpdateDeleteStatus(infrav1.DisksReadyCondition, serviceName, internalError),
 				)
 			},
@@ -233,6 +233,20 @@ func (mr *MockDiskScopeMockRecorder) GetLongRunningOperationState(arg0, arg1 int
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLongRunningOperationState", reflect.TypeOf((*MockDiskScope)(nil).GetLongRunningOperationState), arg0, arg1)
 }
 
+// GetLongRunningOperationStates mocks base method.
+func (m *MockDiskScope) GetLongRunningOperationStates() []*v1beta1.Future {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLongRunningOperationStates")
+	ret0, _ := ret[0].([]*v1beta1.Future)
+	return ret0
+}
+
+// GetLongRunningOperationStates indicates an expected call of GetLongRunningOperationStates.
+func (mr *MockDiskScopeMockRecorder) GetLongRunningOperationStates() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLongRunningOperationStates", reflect.TypeOf((*MockDiskScope)(nil).GetLongRunningOperationStates))
+}
+
 // HashKey mocks base method.
 func (m *MockDiskScope) HashKey() string {
 	m.ctrl.T.Helper()
@@ -247,6 +261,20 @@ func (mr *MockDiskScopeMockRecorder) HashKey() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HashKey", reflect.TypeOf((*MockDiskScope)(nil).HashKey))
 }
 
+// IsServicePaused mocks base method.
+func (m *MockDiskScope) IsServicePaused(arg0 string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsServicePaused", arg0)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsServicePaused indicates an expected call of IsServicePaused.
+func (mr *MockDiskScopeMockRecorder) IsServicePaused(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsServicePaused", reflect.TypeOf((*MockDiskScope)(nil).IsServicePaused), arg0)
+}
+
 // Location mocks base method.
 func (m *MockDiskScope) Location() string {
 	m.ctrl.T.Helper()
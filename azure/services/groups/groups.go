@@ -47,12 +47,12 @@ type GroupScope interface {
 }
 
 // New creates a new service.
-func New(scope GroupScope) *Service {
+func New(scope GroupScope, opts ...async.Option) *Service {
 	client := newClient(scope)
 	return &Service{
 		Scope:      scope,
 		client:     client,
-		Reconciler: async.New(scope, client, client),
+		Reconciler: async.New(scope, client, client, opts...),
 	}
 }
 
@@ -61,7 +61,7 @@ func (s *Service) Reconcile(ctx context.Context) error {
 	ctx, _, done := tele.StartSpanWithLogger(ctx, "groups.Service.Reconcile")
 	defer done()
 
-	ctx, cancel := context.WithTimeout(ctx, reconciler.DefaultAzureServiceReconcileTimeout)
+	ctx, cancel := context.WithTimeout(ctx, reconciler.ServiceReconcileTimeout(s.Reconciler))
 	defer cancel()
 
 	groupSpec := s.Scope.GroupSpec()
@@ -69,7 +69,7 @@ func (s *Service) Reconcile(ctx context.Context) error {
 		return nil
 	}
 
-	_, err := s.CreateResource(ctx, groupSpec, serviceName)
+	_, _, err := s.CreateResource(ctx, groupSpec, serviceName)
 	s.Scope.UpdatePutStatus(infrav1.ResourceGroupReadyCondition, serviceName, err)
 	return err
 }
@@ -79,7 +79,7 @@ func (s *Service) Delete(ctx context.Context) error {
 	ctx, log, done := tele.StartSpanWithLogger(ctx, "groups.Service.Delete")
 	defer done()
 
-	ctx, cancel := context.WithTimeout(ctx, reconciler.DefaultAzureServiceReconcileTimeout)
+	ctx, cancel := context.WithTimeout(ctx, reconciler.ServiceReconcileTimeout(s.Reconciler))
 	defer cancel()
 
 	groupSpec := s.Scope.GroupSpec()
@@ -103,7 +103,7 @@ func (s *Service) Delete(ctx context.Context) error {
 		return azure.ErrNotOwned
 	}
 
-	err = s.DeleteResource(ctx, groupSpec, serviceName)
+	_, err = s.DeleteResource(ctx, groupSpec, serviceName)
 	s.Scope.UpdateDeleteStatus(infrav1.ResourceGroupReadyCondition, serviceName, err)
 	return err
 }
@@ -74,7 +74,7 @@ func TestReconcileGroups(t *testing.T) {
 			expectedError: "",
 			expect: func(s *mock_groups.MockGroupScopeMockRecorder, m *mock_groups.MockclientMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				s.GroupSpec().Return(&fakeGroupSpec)
-				r.CreateResource(gomockinternal.AContext(), &fakeGroupSpec, serviceName).Return(nil, nil)
+				r.CreateResource(gomockinternal.AContext(), &fakeGroupSpec, serviceName).Return(nil, false, nil)
 				s.UpdatePutStatus(infrav1.ResourceGroupReadyCondition, serviceName, nil)
 			},
 		},
@@ -83,7 +83,7 @@ func TestReconcileGroups(t *testing.T) {
 			expectedError: "#: Internal Server Error: StatusCode=500",
 			expect: func(s *mock_groups.MockGroupScopeMockRecorder, m *mock_groups.MockclientMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				s.GroupSpec().Return(&fakeGroupSpec)
-				r.CreateResource(gomockinternal.AContext(), &fakeGroupSpec, serviceName).Return(nil, internalError)
+				r.CreateResource(gomockinternal.AContext(), &fakeGroupSpec, serviceName).Return(nil, false, internalError)
 				s.UpdatePutStatus(infrav1.ResourceGroupReadyCondition, serviceName, internalError)
 			},
 		},
@@ -140,7 +140,7 @@ func TestDeleteGroups(t *testing.T) {
 				s.GroupSpec().AnyTimes().Return(&fakeGroupSpec)
 				m.Get(gomockinternal.AContext(), &fakeGroupSpec).Return(sampleManagedGroup, nil)
 				s.ClusterName().Return("test-cluster")
-				r.DeleteResource(gomockinternal.AContext(), &fakeGroupSpec, serviceName).Return(nil)
+				r.DeleteResource(gomockinternal.AContext(), &fakeGroupSpec, serviceName).Return(false, nil)
 				s.UpdateDeleteStatus(infrav1.ResourceGroupReadyCondition, serviceName, nil)
 			},
 		},
@@ -178,7 +178,7 @@ func TestDeleteGroups(t *testing.T) {
 				s.GroupSpec().AnyTimes().Return(&fakeGroupSpec)
 				m.Get(gomockinternal.AContext(), &fakeGroupSpec).Return(sampleManagedGroup, nil)
 				s.ClusterName().Return("test-cluster")
-				r.DeleteResource(gomockinternal.AContext(), &fakeGroupSpec, serviceName).Return(internalError)
+				r.DeleteResource(gomockinternal.AContext(), &fakeGroupSpec, serviceName).Return(false, internalError)
 				s.UpdateDeleteStatus(infrav1.ResourceGroupReadyCondition, serviceName, gomockinternal.ErrStrEq("#: Internal Server Error: StatusCode=500"))
 			},
 		},
@@ -45,12 +45,12 @@ type Service struct {
 }
 
 // New creates a new service.
-func New(scope InboundNatScope) *Service {
+func New(scope InboundNatScope, opts ...async.Option) *Service {
 	client := newClient(scope)
 	return &Service{
 		Scope:      scope,
 		client:     client,
-		Reconciler: async.New(scope, client, client),
+		Reconciler: async.New(scope, client, client, opts...),
 	}
 }
 
@@ -65,7 +65,7 @@ func (s *Service) Reconcile(ctx context.Context) error {
 		return nil
 	}
 
-	ctx, cancel := context.WithTimeout(ctx, reconciler.DefaultAzureServiceReconcileTimeout)
+	ctx, cancel := context.WithTimeout(ctx, reconciler.ServiceReconcileTimeout(s.Reconciler))
 	defer cancel()
 
 	existingRules, err := s.client.List(ctx, s.Scope.ResourceGroup(), s.Scope.APIServerLBName())
@@ -93,7 +93,7 @@ func (s *Service) Reconcile(ctx context.Context) error {
 		// If we are creating multiple inbound NAT rules, we could have a collision in finding an available frontend port since the newly created rule takes an available port, and we do not update portsInUse in the specs.
 		// It doesn't matter in this case since we only create one rule per machine, but for multiple rules, we could end up restarting the Reconcile function each time to get the updated available ports.
 		// TODO: We can update the available ports and recompute the specs each time, or alternatively, we could deterministically calculate the ports we plan on using to avoid collisions, i.e. rule #1 uses the first available port, rule #2 uses the second available port, etc.
-		if _, err := s.CreateResource(ctx, natRule, serviceName); err != nil {
+		if _, _, err := s.CreateResource(ctx, natRule, serviceName); err != nil {
 			if !azure.IsOperationNotDoneError(err) || result == nil {
 				result = err
 			}
@@ -110,7 +110,7 @@ func (s *Service) Delete(ctx context.Context) error {
 	ctx, _, done := tele.StartSpanWithLogger(ctx, "inboundnatrules.Service.Delete")
 	defer done()
 
-	ctx, cancel := context.WithTimeout(ctx, reconciler.DefaultAzureServiceReconcileTimeout)
+	ctx, cancel := context.WithTimeout(ctx, reconciler.ServiceReconcileTimeout(s.Reconciler))
 	defer cancel()
 
 	specs := s.Scope.InboundNatSpecs(make(map[int32]struct{}))
@@ -123,7 +123,7 @@ func (s *Service) Delete(ctx context.Context) error {
 	//  Order of precedence (highest -> lowest) is: error that is not an operationNotDoneError (i.e. error deleting) -> operationNotDoneError (i.e. deleting in progress) -> no error (i.e. deleted)
 	var result error
 	for _, natRule := range specs {
-		if err := s.DeleteResource(ctx, natRule, serviceName); err != nil {
+		if _, err := s.DeleteResource(ctx, natRule, serviceName); err != nil {
 			if !azure.IsOperationNotDoneError(err) || result == nil {
 				result = err
 			}
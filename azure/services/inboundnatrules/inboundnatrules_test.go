@@ -115,7 +115,7 @@ func TestReconcileInboundNATRule(t *testing.T) {
 				m.List(gomockinternal.AContext(), fakeGroupName, fakeLBName).Return(noExistingRules, nil)
 				s.InboundNatSpecs(noPortsInUse).Return([]azure.ResourceSpecGetter{&fakeNatSpecWithNoExisting})
 				gomock.InOrder(
-					r.CreateResource(gomockinternal.AContext(), &fakeNatSpecWithNoExisting, serviceName).Return(nil, nil),
+					r.CreateResource(gomockinternal.AContext(), &fakeNatSpecWithNoExisting, serviceName).Return(nil, false, nil),
 					s.UpdatePutStatus(infrav1.InboundNATRulesReadyCondition, serviceName, nil),
 				)
 			},
@@ -131,7 +131,7 @@ func TestReconcileInboundNATRule(t *testing.T) {
 				m.List(gomockinternal.AContext(), fakeGroupName, "my-lb").Return(fakeExistingRules, nil)
 				s.InboundNatSpecs(somePortsInUse).Return([]azure.ResourceSpecGetter{&fakeNatSpec})
 				gomock.InOrder(
-					r.CreateResource(gomockinternal.AContext(), &fakeNatSpec, serviceName).Return(nil, nil),
+					r.CreateResource(gomockinternal.AContext(), &fakeNatSpec, serviceName).Return(nil, false, nil),
 					s.UpdatePutStatus(infrav1.InboundNATRulesReadyCondition, serviceName, nil),
 				)
 			},
@@ -168,7 +168,7 @@ func TestReconcileInboundNATRule(t *testing.T) {
 				m.List(gomockinternal.AContext(), fakeGroupName, "my-lb").Return(fakeExistingRules, nil)
 				s.InboundNatSpecs(somePortsInUse).Return([]azure.ResourceSpecGetter{&fakeNatSpec})
 				gomock.InOrder(
-					r.CreateResource(gomockinternal.AContext(), &fakeNatSpec, serviceName).Return(nil, internalError),
+					r.CreateResource(gomockinternal.AContext(), &fakeNatSpec, serviceName).Return(nil, false, internalError),
 					s.UpdatePutStatus(infrav1.InboundNATRulesReadyCondition, serviceName, internalError),
 				)
 			},
@@ -229,7 +229,7 @@ func TestDeleteNetworkInterface(t *testing.T) {
 				s.ResourceGroup().AnyTimes().Return(fakeGroupName)
 				s.APIServerLBName().AnyTimes().Return(fakeLBName)
 				gomock.InOrder(
-					r.DeleteResource(gomockinternal.AContext(), &fakeNatSpecWithNoExisting, serviceName).Return(nil),
+					r.DeleteResource(gomockinternal.AContext(), &fakeNatSpecWithNoExisting, serviceName).Return(false, nil),
 					s.UpdateDeleteStatus(infrav1.InboundNATRulesReadyCondition, serviceName, nil),
 				)
 			},
@@ -243,7 +243,7 @@ func TestDeleteNetworkInterface(t *testing.T) {
 				s.ResourceGroup().AnyTimes().Return(fakeGroupName)
 				s.APIServerLBName().AnyTimes().Return(fakeLBName)
 				gomock.InOrder(
-					r.DeleteResource(gomockinternal.AContext(), &fakeNatSpecWithNoExisting, serviceName).Return(internalError),
+					r.DeleteResource(gomockinternal.AContext(), &fakeNatSpecWithNoExisting, serviceName).Return(false, internalError),
 					s.UpdateDeleteStatus(infrav1.InboundNATRulesReadyCondition, serviceName, internalError),
 				)
 			},
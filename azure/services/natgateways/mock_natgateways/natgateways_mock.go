@@ -289,6 +289,20 @@ func (mr *MockNatGatewayScopeMockRecorder) GetLongRunningOperationState(arg0, ar
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLongRunningOperationState", reflect.TypeOf((*MockNatGatewayScope)(nil).GetLongRunningOperationState), arg0, arg1)
 }
 
+// GetLongRunningOperationStates mocks base method.
+func (m *MockNatGatewayScope) GetLongRunningOperationStates() []*v1beta1.Future {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLongRunningOperationStates")
+	ret0, _ := ret[0].([]*v1beta1.Future)
+	return ret0
+}
+
+// GetLongRunningOperationStates indicates an expected call of GetLongRunningOperationStates.
+func (mr *MockNatGatewayScopeMockRecorder) GetLongRunningOperationStates() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLongRunningOperationStates", reflect.TypeOf((*MockNatGatewayScope)(nil).GetLongRunningOperationStates))
+}
+
 // GetPrivateDNSZoneName mocks base method.
 func (m *MockNatGatewayScope) GetPrivateDNSZoneName() string {
 	m.ctrl.T.Helper()
@@ -345,6 +359,20 @@ func (mr *MockNatGatewayScopeMockRecorder) IsIPv6Enabled() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsIPv6Enabled", reflect.TypeOf((*MockNatGatewayScope)(nil).IsIPv6Enabled))
 }
 
+// IsServicePaused mocks base method.
+func (m *MockNatGatewayScope) IsServicePaused(arg0 string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsServicePaused", arg0)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsServicePaused indicates an expected call of IsServicePaused.
+func (mr *MockNatGatewayScopeMockRecorder) IsServicePaused(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsServicePaused", reflect.TypeOf((*MockNatGatewayScope)(nil).IsServicePaused), arg0)
+}
+
 // IsVnetManaged mocks base method.
 func (m *MockNatGatewayScope) IsVnetManaged() bool {
 	m.ctrl.T.Helper()
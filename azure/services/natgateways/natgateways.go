@@ -45,11 +45,11 @@ type Service struct {
 }
 
 // New creates a new service.
-func New(scope NatGatewayScope) *Service {
+func New(scope NatGatewayScope, opts ...async.Option) *Service {
 	client := newClient(scope)
 	return &Service{
 		Scope:      scope,
-		Reconciler: async.New(scope, client, client),
+		Reconciler: async.New(scope, client, client, opts...),
 	}
 }
 
@@ -59,7 +59,7 @@ func (s *Service) Reconcile(ctx context.Context) error {
 	ctx, log, done := tele.StartSpanWithLogger(ctx, "natgateways.Service.Reconcile")
 	defer done()
 
-	ctx, cancel := context.WithTimeout(ctx, reconciler.DefaultAzureServiceReconcileTimeout)
+	ctx, cancel := context.WithTimeout(ctx, reconciler.ServiceReconcileTimeout(s.Reconciler))
 	defer cancel()
 
 	if !s.Scope.Vnet().IsManaged(s.Scope.ClusterName()) {
@@ -79,7 +79,7 @@ func (s *Service) Reconcile(ctx context.Context) error {
 	//  Order of precedence (highest -> lowest) is: error that is not an operationNotDoneError (ie. error creating) -> operationNotDoneError (ie. creating in progress) -> no error (ie. created)
 	var resultingErr error
 	for _, natGatewaySpec := range specs {
-		result, err := s.CreateResource(ctx, natGatewaySpec, serviceName)
+		result, _, err := s.CreateResource(ctx, natGatewaySpec, serviceName)
 		if err != nil {
 			if !azure.IsOperationNotDoneError(err) || resultingErr == nil {
 				resultingErr = err
@@ -107,7 +107,7 @@ func (s *Service) Delete(ctx context.Context) error {
 	ctx, log, done := tele.StartSpanWithLogger(ctx, "natgateways.Service.Delete")
 	defer done()
 
-	ctx, cancel := context.WithTimeout(ctx, reconciler.DefaultAzureServiceReconcileTimeout)
+	ctx, cancel := context.WithTimeout(ctx, reconciler.ServiceReconcileTimeout(s.Reconciler))
 	defer cancel()
 
 	if !s.Scope.Vnet().IsManaged(s.Scope.ClusterName()) {
@@ -127,7 +127,7 @@ func (s *Service) Delete(ctx context.Context) error {
 	//  Order of precedence (highest -> lowest) is: error that is not an operationNotDoneError (ie. error creating) -> operationNotDoneError (ie. creating in progress) -> no error (ie. created)
 	var resultingErr error
 	for _, natGatewaySpec := range specs {
-		if err := s.DeleteResource(ctx, natGatewaySpec, serviceName); err != nil {
+		if _, err := s.DeleteResource(ctx, natGatewaySpec, serviceName); err != nil {
 			if !azure.IsOperationNotDoneError(err) || resultingErr == nil {
 				resultingErr = err
 			}
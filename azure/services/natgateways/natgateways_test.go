@@ -105,7 +105,7 @@ func TestReconcileNatGateways(t *testing.T) {
 				s.Vnet().Return(&ownedVNetSpec)
 				s.ClusterName()
 				s.NatGatewaySpecs().Return([]azure.ResourceSpecGetter{&natGatewaySpec1})
-				r.CreateResource(gomockinternal.AContext(), &natGatewaySpec1, serviceName).Return(natGateway1, nil)
+				r.CreateResource(gomockinternal.AContext(), &natGatewaySpec1, serviceName).Return(natGateway1, false, nil)
 				s.SetNatGatewayIDInSubnets(natGatewaySpec1.Name, *natGateway1.ID)
 				s.UpdatePutStatus(infrav1.NATGatewaysReadyCondition, serviceName, nil)
 			},
@@ -118,7 +118,7 @@ func TestReconcileNatGateways(t *testing.T) {
 				s.Vnet().Return(&ownedVNetSpec)
 				s.ClusterName()
 				s.NatGatewaySpecs().Return([]azure.ResourceSpecGetter{&natGatewaySpec1})
-				r.CreateResource(gomockinternal.AContext(), &natGatewaySpec1, serviceName).Return(nil, internalError)
+				r.CreateResource(gomockinternal.AContext(), &natGatewaySpec1, serviceName).Return(nil, false, internalError)
 				s.UpdatePutStatus(infrav1.NATGatewaysReadyCondition, serviceName, internalError)
 			},
 		},
@@ -130,7 +130,7 @@ func TestReconcileNatGateways(t *testing.T) {
 				s.Vnet().Return(&ownedVNetSpec)
 				s.ClusterName()
 				s.NatGatewaySpecs().Return([]azure.ResourceSpecGetter{&natGatewaySpec1})
-				r.CreateResource(gomockinternal.AContext(), &natGatewaySpec1, serviceName).Return("not a nat gateway", nil)
+				r.CreateResource(gomockinternal.AContext(), &natGatewaySpec1, serviceName).Return("not a nat gateway", false, nil)
 				s.UpdatePutStatus(infrav1.NATGatewaysReadyCondition, serviceName, gomockinternal.ErrStrEq("created resource string is not a network.NatGateway"))
 			},
 		},
@@ -199,7 +199,7 @@ func TestDeleteNatGateway(t *testing.T) {
 				s.Vnet().Return(&ownedVNetSpec)
 				s.ClusterName()
 				s.NatGatewaySpecs().Return([]azure.ResourceSpecGetter{&natGatewaySpec1})
-				r.DeleteResource(gomockinternal.AContext(), &natGatewaySpec1, serviceName).Return(nil)
+				r.DeleteResource(gomockinternal.AContext(), &natGatewaySpec1, serviceName).Return(false, nil)
 				s.UpdateDeleteStatus(infrav1.NATGatewaysReadyCondition, serviceName, nil)
 			},
 		},
@@ -211,7 +211,7 @@ func TestDeleteNatGateway(t *testing.T) {
 				s.Vnet().Return(&ownedVNetSpec)
 				s.ClusterName()
 				s.NatGatewaySpecs().Return([]azure.ResourceSpecGetter{&natGatewaySpec1})
-				r.DeleteResource(gomockinternal.AContext(), &natGatewaySpec1, serviceName).Return(internalError)
+				r.DeleteResource(gomockinternal.AContext(), &natGatewaySpec1, serviceName).Return(false, internalError)
 				s.UpdateDeleteStatus(infrav1.NATGatewaysReadyCondition, serviceName, internalError)
 			},
 		},
@@ -44,11 +44,11 @@ type Service struct {
 }
 
 // New creates a new service.
-func New(scope NICScope, skuCache *resourceskus.Cache) *Service {
+func New(scope NICScope, skuCache *resourceskus.Cache, opts ...async.Option) *Service {
 	Client := NewClient(scope)
 	return &Service{
 		Scope:            scope,
-		Reconciler:       async.New(scope, Client, Client),
+		Reconciler:       async.New(scope, Client, Client, opts...),
 		resourceSKUCache: skuCache,
 	}
 }
@@ -58,7 +58,7 @@ func (s *Service) Reconcile(ctx context.Context) error {
 	ctx, _, done := tele.StartSpanWithLogger(ctx, "networkinterfaces.Service.Reconcile")
 	defer done()
 
-	ctx, cancel := context.WithTimeout(ctx, reconciler.DefaultAzureServiceReconcileTimeout)
+	ctx, cancel := context.WithTimeout(ctx, reconciler.ServiceReconcileTimeout(s.Reconciler))
 	defer cancel()
 
 	specs := s.Scope.NICSpecs()
@@ -71,7 +71,7 @@ func (s *Service) Reconcile(ctx context.Context) error {
 	//  Order of precedence (highest -> lowest) is: error that is not an operationNotDoneError (i.e. error creating) -> operationNotDoneError (i.e. creating in progress) -> no error (i.e. created)
 	var result error
 	for _, nicSpec := range specs {
-		if _, err := s.CreateResource(ctx, nicSpec, serviceName); err != nil {
+		if _, _, err := s.CreateResource(ctx, nicSpec, serviceName); err != nil {
 			if !azure.IsOperationNotDoneError(err) || result == nil {
 				result = err
 			}
@@ -87,7 +87,7 @@ func (s *Service) Delete(ctx context.Context) error {
 	ctx, _, done := tele.StartSpanWithLogger(ctx, "networkinterfaces.Service.Delete")
 	defer done()
 
-	ctx, cancel := context.WithTimeout(ctx, reconciler.DefaultAzureServiceReconcileTimeout)
+	ctx, cancel := context.WithTimeout(ctx, reconciler.ServiceReconcileTimeout(s.Reconciler))
 	defer cancel()
 
 	specs := s.Scope.NICSpecs()
@@ -100,7 +100,7 @@ func (s *Service) Delete(ctx context.Context) error {
 	//  Order of precedence (highest -> lowest) is: error that is not an operationNotDoneError (i.e. error deleting) -> operationNotDoneError (i.e. deleting in progress) -> no error (i.e. deleted)
 	var result error
 	for _, nicSpec := range specs {
-		if err := s.DeleteResource(ctx, nicSpec, serviceName); err != nil {
+		if _, err := s.DeleteResource(ctx, nicSpec, serviceName); err != nil {
 			if !azure.IsOperationNotDoneError(err) || result == nil {
 				result = err
 			}
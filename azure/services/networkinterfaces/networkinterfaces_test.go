@@ -79,7 +79,7 @@ func TestReconcileNetworkInterface(t *testing.T) {
 			expectedError: "",
 			expect: func(s *mock_networkinterfaces.MockNICScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				s.NICSpecs().Return([]azure.ResourceSpecGetter{&fakeNICSpec1})
-				r.CreateResource(gomockinternal.AContext(), &fakeNICSpec1, serviceName).Return(nil, nil)
+				r.CreateResource(gomockinternal.AContext(), &fakeNICSpec1, serviceName).Return(nil, false, nil)
 				s.UpdatePutStatus(infrav1.NetworkInterfaceReadyCondition, serviceName, nil)
 			},
 		},
@@ -88,8 +88,8 @@ func TestReconcileNetworkInterface(t *testing.T) {
 			expectedError: "",
 			expect: func(s *mock_networkinterfaces.MockNICScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				s.NICSpecs().Return([]azure.ResourceSpecGetter{&fakeNICSpec1, &fakeNICSpec2})
-				r.CreateResource(gomockinternal.AContext(), &fakeNICSpec1, serviceName).Return(nil, nil)
-				r.CreateResource(gomockinternal.AContext(), &fakeNICSpec2, serviceName).Return(nil, nil)
+				r.CreateResource(gomockinternal.AContext(), &fakeNICSpec1, serviceName).Return(nil, false, nil)
+				r.CreateResource(gomockinternal.AContext(), &fakeNICSpec2, serviceName).Return(nil, false, nil)
 				s.UpdatePutStatus(infrav1.NetworkInterfaceReadyCondition, serviceName, nil)
 			},
 		},
@@ -98,8 +98,8 @@ func TestReconcileNetworkInterface(t *testing.T) {
 			expectedError: internalError.Error(),
 			expect: func(s *mock_networkinterfaces.MockNICScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				s.NICSpecs().Return([]azure.ResourceSpecGetter{&fakeNICSpec1, &fakeNICSpec2})
-				r.CreateResource(gomockinternal.AContext(), &fakeNICSpec1, serviceName).Return(nil, internalError)
-				r.CreateResource(gomockinternal.AContext(), &fakeNICSpec2, serviceName).Return(nil, nil)
+				r.CreateResource(gomockinternal.AContext(), &fakeNICSpec1, serviceName).Return(nil, false, internalError)
+				r.CreateResource(gomockinternal.AContext(), &fakeNICSpec2, serviceName).Return(nil, false, nil)
 				s.UpdatePutStatus(infrav1.NetworkInterfaceReadyCondition, serviceName, internalError)
 			},
 		},
@@ -153,7 +153,7 @@ func TestDeleteNetworkInterface(t *testing.T) {
 			expectedError: "",
 			expect: func(s *mock_networkinterfaces.MockNICScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				s.NICSpecs().Return([]azure.ResourceSpecGetter{&fakeNICSpec1})
-				r.DeleteResource(gomockinternal.AContext(), &fakeNICSpec1, serviceName).Return(nil)
+				r.DeleteResource(gomockinternal.AContext(), &fakeNICSpec1, serviceName).Return(false, nil)
 				s.UpdateDeleteStatus(infrav1.NetworkInterfaceReadyCondition, serviceName, nil)
 			},
 		},
@@ -162,8 +162,8 @@ func TestDeleteNetworkInterface(t *testing.T) {
 			expectedError: "",
 			expect: func(s *mock_networkinterfaces.MockNICScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				s.NICSpecs().Return([]azure.ResourceSpecGetter{&fakeNICSpec1, &fakeNICSpec2})
-				r.DeleteResource(gomockinternal.AContext(), &fakeNICSpec1, serviceName).Return(nil)
-				r.DeleteResource(gomockinternal.AContext(), &fakeNICSpec2, serviceName).Return(nil)
+				r.DeleteResource(gomockinternal.AContext(), &fakeNICSpec1, serviceName).Return(false, nil)
+				r.DeleteResource(gomockinternal.AContext(), &fakeNICSpec2, serviceName).Return(false, nil)
 				s.UpdateDeleteStatus(infrav1.NetworkInterfaceReadyCondition, serviceName, nil)
 			},
 		},
@@ -172,8 +172,8 @@ func TestDeleteNetworkInterface(t *testing.T) {
 			expectedError: internalError.Error(),
 			expect: func(s *mock_networkinterfaces.MockNICScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				s.NICSpecs().Return([]azure.ResourceSpecGetter{&fakeNICSpec1, &fakeNICSpec2})
-				r.DeleteResource(gomockinternal.AContext(), &fakeNICSpec1, serviceName).Return(nil)
-				r.DeleteResource(gomockinternal.AContext(), &fakeNICSpec2, serviceName).Return(internalError)
+				r.DeleteResource(gomockinternal.AContext(), &fakeNICSpec1, serviceName).Return(false, nil)
+				r.DeleteResource(gomockinternal.AContext(), &fakeNICSpec2, serviceName).Return(false, internalError)
 				s.UpdateDeleteStatus(infrav1.NetworkInterfaceReadyCondition, serviceName, internalError)
 			},
 		},
@@ -43,11 +43,11 @@ type Service struct {
 }
 
 // New creates a new service.
-func New(scope RouteTableScope) *Service {
+func New(scope RouteTableScope, opts ...async.Option) *Service {
 	client := newClient(scope)
 	return &Service{
 		Scope:      scope,
-		Reconciler: async.New(scope, client, client),
+		Reconciler: async.New(scope, client, client, opts...),
 	}
 }
 
@@ -56,7 +56,7 @@ func (s *Service) Reconcile(ctx context.Context) error {
 	ctx, log, done := tele.StartSpanWithLogger(ctx, "routetables.Service.Reconcile")
 	defer done()
 
-	ctx, cancel := context.WithTimeout(ctx, reconciler.DefaultAzureServiceReconcileTimeout)
+	ctx, cancel := context.WithTimeout(ctx, reconciler.ServiceReconcileTimeout(s.Reconciler))
 	defer cancel()
 
 	var resErr error
@@ -75,7 +75,7 @@ func (s *Service) Reconcile(ctx context.Context) error {
 	// If multiple errors occur, we return the most pressing one.
 	//  Order of precedence (highest -> lowest) is: error that is not an operationNotDoneError (i.e. error creating) -> operationNotDoneError (i.e. creating in progress) -> no error (i.e. created)
 	for _, rtSpec := range specs {
-		if _, err := s.CreateResource(ctx, rtSpec, serviceName); err != nil {
+		if _, _, err := s.CreateResource(ctx, rtSpec, serviceName); err != nil {
 			if !azure.IsOperationNotDoneError(err) || resErr == nil {
 				resErr = err
 			}
@@ -91,7 +91,7 @@ func (s *Service) Delete(ctx context.Context) error {
 	ctx, log, done := tele.StartSpanWithLogger(ctx, "routetables.Service.Delete")
 	defer done()
 
-	ctx, cancel := context.WithTimeout(ctx, reconciler.DefaultAzureServiceReconcileTimeout)
+	ctx, cancel := context.WithTimeout(ctx, reconciler.ServiceReconcileTimeout(s.Reconciler))
 	defer cancel()
 
 	// Only delete the route tables if their lifecycle is managed by this controller.
@@ -111,7 +111,7 @@ func (s *Service) Delete(ctx context.Context) error {
 	// order of precedence is: error deleting -> deleting in progress -> deleted (no error)
 	var result error
 	for _, rtSpec := range specs {
-		if err := s.DeleteResource(ctx, rtSpec, serviceName); err != nil {
+		if _, err := s.DeleteResource(ctx, rtSpec, serviceName); err != nil {
 			if !azure.IsOperationNotDoneError(err) || result == nil {
 				result = err
 			}
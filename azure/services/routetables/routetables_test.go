@@ -19,12 +19,15 @@ package routetables
 import (
 	"context"
 	"errors"
+	"net/http"
 	"testing"
 
+	"github.com/Azure/go-autorest/autorest"
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/gomega"
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/async"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/async/mock_async"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/routetables/mock_routetables"
 	gomockinternal "sigs.k8s.io/cluster-api-provider-azure/internal/test/matchers/gomock"
@@ -41,8 +44,9 @@ var (
 		ResourceGroup: "test-rg",
 		Location:      "fake-location",
 	}
-	errFake      = errors.New("this is an error")
-	notDoneError = azure.NewOperationNotDoneError(&infrav1.Future{})
+	errFake       = errors.New("this is an error")
+	notDoneError  = azure.NewOperationNotDoneError(&infrav1.Future{})
+	notFoundError = autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: http.StatusNotFound}, "Not Found")
 )
 
 func TestReconcileRouteTables(t *testing.T) {
@@ -66,8 +70,8 @@ func TestReconcileRouteTables(t *testing.T) {
 			expect: func(s *mock_routetables.MockRouteTableScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				s.IsVnetManaged().Return(true)
 				s.RouteTableSpecs().Return([]azure.ResourceSpecGetter{&fakeRT, &fakeRT2})
-				r.CreateResource(gomockinternal.AContext(), &fakeRT, serviceName).Return(nil, nil)
-				r.CreateResource(gomockinternal.AContext(), &fakeRT2, serviceName).Return(nil, nil)
+				r.CreateResource(gomockinternal.AContext(), &fakeRT, serviceName).Return(nil, false, nil)
+				r.CreateResource(gomockinternal.AContext(), &fakeRT2, serviceName).Return(nil, false, nil)
 				s.UpdatePutStatus(infrav1.RouteTablesReadyCondition, serviceName, nil)
 			},
 		},
@@ -77,8 +81,8 @@ func TestReconcileRouteTables(t *testing.T) {
 			expect: func(s *mock_routetables.MockRouteTableScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				s.IsVnetManaged().Return(true)
 				s.RouteTableSpecs().Return([]azure.ResourceSpecGetter{&fakeRT, &fakeRT2})
-				r.CreateResource(gomockinternal.AContext(), &fakeRT, serviceName).Return(nil, errFake)
-				r.CreateResource(gomockinternal.AContext(), &fakeRT2, serviceName).Return(nil, nil)
+				r.CreateResource(gomockinternal.AContext(), &fakeRT, serviceName).Return(nil, false, errFake)
+				r.CreateResource(gomockinternal.AContext(), &fakeRT2, serviceName).Return(nil, false, nil)
 				s.UpdatePutStatus(infrav1.RouteTablesReadyCondition, serviceName, errFake)
 			},
 		},
@@ -88,8 +92,8 @@ func TestReconcileRouteTables(t *testing.T) {
 			expect: func(s *mock_routetables.MockRouteTableScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				s.IsVnetManaged().Return(true)
 				s.RouteTableSpecs().Return([]azure.ResourceSpecGetter{&fakeRT, &fakeRT2})
-				r.CreateResource(gomockinternal.AContext(), &fakeRT, serviceName).Return(nil, errFake)
-				r.CreateResource(gomockinternal.AContext(), &fakeRT2, serviceName).Return(nil, notDoneError)
+				r.CreateResource(gomockinternal.AContext(), &fakeRT, serviceName).Return(nil, false, errFake)
+				r.CreateResource(gomockinternal.AContext(), &fakeRT2, serviceName).Return(nil, false, notDoneError)
 				s.UpdatePutStatus(infrav1.RouteTablesReadyCondition, serviceName, errFake)
 			},
 		},
@@ -151,8 +155,8 @@ func TestDeleteRouteTable(t *testing.T) {
 			expect: func(s *mock_routetables.MockRouteTableScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				s.IsVnetManaged().Return(true)
 				s.RouteTableSpecs().Return([]azure.ResourceSpecGetter{&fakeRT, &fakeRT2})
-				r.DeleteResource(gomockinternal.AContext(), &fakeRT, serviceName).Return(nil)
-				r.DeleteResource(gomockinternal.AContext(), &fakeRT2, serviceName).Return(nil)
+				r.DeleteResource(gomockinternal.AContext(), &fakeRT, serviceName).Return(false, nil)
+				r.DeleteResource(gomockinternal.AContext(), &fakeRT2, serviceName).Return(false, nil)
 				s.UpdateDeleteStatus(infrav1.RouteTablesReadyCondition, serviceName, nil)
 			},
 		},
@@ -162,8 +166,8 @@ func TestDeleteRouteTable(t *testing.T) {
 			expect: func(s *mock_routetables.MockRouteTableScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				s.IsVnetManaged().Return(true)
 				s.RouteTableSpecs().Return([]azure.ResourceSpecGetter{&fakeRT, &fakeRT2})
-				r.DeleteResource(gomockinternal.AContext(), &fakeRT, serviceName).Return(errFake)
-				r.DeleteResource(gomockinternal.AContext(), &fakeRT2, serviceName).Return(nil)
+				r.DeleteResource(gomockinternal.AContext(), &fakeRT, serviceName).Return(false, errFake)
+				r.DeleteResource(gomockinternal.AContext(), &fakeRT2, serviceName).Return(false, nil)
 				s.UpdateDeleteStatus(infrav1.RouteTablesReadyCondition, serviceName, errFake)
 			},
 		},
@@ -173,8 +177,8 @@ func TestDeleteRouteTable(t *testing.T) {
 			expect: func(s *mock_routetables.MockRouteTableScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				s.IsVnetManaged().Return(true)
 				s.RouteTableSpecs().Return([]azure.ResourceSpecGetter{&fakeRT, &fakeRT2})
-				r.DeleteResource(gomockinternal.AContext(), &fakeRT, serviceName).Return(errFake)
-				r.DeleteResource(gomockinternal.AContext(), &fakeRT2, serviceName).Return(notDoneError)
+				r.DeleteResource(gomockinternal.AContext(), &fakeRT, serviceName).Return(false, errFake)
+				r.DeleteResource(gomockinternal.AContext(), &fakeRT2, serviceName).Return(false, notDoneError)
 				s.UpdateDeleteStatus(infrav1.RouteTablesReadyCondition, serviceName, errFake)
 			},
 		},
@@ -214,3 +218,48 @@ func TestDeleteRouteTable(t *testing.T) {
 		})
 	}
 }
+
+// TestReconcileRouteTablesSharedConcurrencyLimiter tests that services sharing a concurrency limiter bound their
+// requests in flight together: while one service is creating a route table, the other one is requeued rather than
+// creating its own.
+func TestReconcileRouteTablesSharedConcurrencyLimiter(t *testing.T) {
+	g := NewWithT(t)
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	limiter := async.NewConcurrencyLimiter(1)
+
+	otherScopeMock := mock_routetables.NewMockRouteTableScope(mockCtrl)
+	otherCreatorMock := mock_async.NewMockCreator(mockCtrl)
+	other := &Service{
+		Scope:      otherScopeMock,
+		Reconciler: async.New(otherScopeMock, otherCreatorMock, nil, async.WithConcurrencyLimiter(limiter, 1)),
+	}
+	otherScopeMock.EXPECT().IsVnetManaged().Return(true)
+	otherScopeMock.EXPECT().RouteTableSpecs().Return([]azure.ResourceSpecGetter{&fakeRT2})
+	otherScopeMock.EXPECT().IsServicePaused(serviceName).Return(false)
+	otherScopeMock.EXPECT().GetLongRunningOperationState(fakeRT2.Name, serviceName).Return(nil)
+	otherCreatorMock.EXPECT().Get(gomockinternal.AContext(), &fakeRT2).Return(nil, notFoundError)
+	otherScopeMock.EXPECT().UpdatePutStatus(infrav1.RouteTablesReadyCondition, serviceName, gomock.Any())
+
+	scopeMock := mock_routetables.NewMockRouteTableScope(mockCtrl)
+	creatorMock := mock_async.NewMockCreator(mockCtrl)
+	s := &Service{
+		Scope:      scopeMock,
+		Reconciler: async.New(scopeMock, creatorMock, nil, async.WithConcurrencyLimiter(limiter, 1)),
+	}
+	scopeMock.EXPECT().IsVnetManaged().Return(true)
+	scopeMock.EXPECT().RouteTableSpecs().Return([]azure.ResourceSpecGetter{&fakeRT})
+	scopeMock.EXPECT().IsServicePaused(serviceName).Return(false)
+	scopeMock.EXPECT().GetLongRunningOperationState(fakeRT.Name, serviceName).Return(nil)
+	creatorMock.EXPECT().Get(gomockinternal.AContext(), &fakeRT).Return(nil, notFoundError)
+	creatorMock.EXPECT().CreateOrUpdateAsync(gomockinternal.AContext(), &fakeRT, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, _ azure.ResourceSpecGetter, _ interface{}) (interface{}, interface{}, error) {
+			err := other.Reconcile(ctx)
+			g.Expect(azure.IsConcurrencyLimitedError(err)).To(BeTrue())
+			return fakeRT.Name, nil, nil
+		})
+	scopeMock.EXPECT().UpdatePutStatus(infrav1.RouteTablesReadyCondition, serviceName, nil)
+
+	g.Expect(s.Reconcile(context.TODO())).To(Succeed())
+	g.Expect(limiter.InFlight()).To(BeZero())
+}
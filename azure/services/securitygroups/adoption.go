@@ -0,0 +1,68 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package securitygroups
+
+import (
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-02-01/network"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/converters"
+)
+
+// adopting returns true if the existing security group is not tagged as owned by the cluster yet, e.g. it was created
+// by the user before the cluster. Such a security group is adopted: the rules of the spec are merged into its rules
+// first, and it is tagged as owned by the cluster on the reconcile after a successful merge. A security group that was
+// already reconciled for the cluster is never adopted, even without the owned tag.
+func (s *NSGSpec) adopting(existing network.SecurityGroup) bool {
+	return s.ClusterName != "" && !s.Reconciled && !converters.MapToTags(existing.Tags).HasOwned(s.ClusterName)
+}
+
+// withOwnerTag returns a copy of the tags with the tag marking the security group as owned by the cluster.
+func withOwnerTag(tags map[string]*string, clusterName string) map[string]*string {
+	result := make(map[string]*string, len(tags)+1)
+	for key, value := range tags {
+		result[key] = value
+	}
+	result[infrav1.ClusterTagKey(clusterName)] = to.StringPtr(string(infrav1.ResourceLifecycleOwned))
+	return result
+}
+
+// mergeConflicts returns an aggregated error listing the additions that have the priority of an existing rule of the
+// same direction that is kept, e.g. a rule of the user at a priority assigned to a rule of the spec. Azure rejects
+// such a security group, so the merge is not attempted until the priority of either rule is changed.
+func mergeConflicts(existing network.SecurityGroup, changes RuleChanges) error {
+	if existing.SecurityGroupPropertiesFormat == nil || existing.SecurityRules == nil {
+		return nil
+	}
+	var errs []error
+	for _, addition := range changes.Additions {
+		for _, rule := range *existing.SecurityRules {
+			name := to.String(rule.Name)
+			if rule.SecurityRulePropertiesFormat == nil || strings.EqualFold(name, to.String(addition.Name)) || containsFold(changes.Deletions, name) {
+				continue
+			}
+			if rule.Direction == addition.Direction && to.Int32(rule.Priority) == to.Int32(addition.Priority) {
+				errs = append(errs, errors.Errorf("rule %s has the priority %d of existing rule %s in direction %s", to.String(addition.Name), to.Int32(addition.Priority), name, rule.Direction))
+			}
+		}
+	}
+	return kerrors.NewAggregate(errs)
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package securitygroups
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-02-01/network"
+	"github.com/Azure/go-autorest/autorest/to"
+	. "github.com/onsi/gomega"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/converters"
+)
+
+func TestParametersAdoption(t *testing.T) {
+	g := NewWithT(t)
+	spec := &NSGSpec{
+		Name:          "test-nsg",
+		Location:      "test-location",
+		ClusterName:   "test-cluster",
+		SecurityRules: infrav1.SecurityRules{sshRule},
+		ResourceGroup: "test-group",
+	}
+	// The security group was created by the user, in another location than the cluster, with a rule of their own.
+	existing := network.SecurityGroup{
+		Name:     to.StringPtr("test-nsg"),
+		Location: to.StringPtr("other-location"),
+		Etag:     to.StringPtr("fake-etag"),
+		Tags:     map[string]*string{"team": to.StringPtr("networking")},
+		SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
+			SecurityRules: &[]network.SecurityRule{foreignRule},
+		},
+	}
+
+	// The first reconcile merges the rules of the spec without tagging the security group as owned.
+	merged, err := spec.Parameters(existing)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(merged).To(Equal(network.SecurityGroup{
+		Location: to.StringPtr("other-location"),
+		Etag:     to.StringPtr("fake-etag"),
+		Tags:     map[string]*string{"team": to.StringPtr("networking")},
+		SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
+			SecurityRules: &[]network.SecurityRule{foreignRule, sdkRule(sshRule)},
+		},
+	}))
+
+	// Once the merge succeeded, the security group is tagged as owned and its rules are left as they are.
+	adopted, err := spec.Parameters(merged)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(adopted).To(Equal(network.SecurityGroup{
+		Location: to.StringPtr("other-location"),
+		Etag:     to.StringPtr("fake-etag"),
+		Tags: map[string]*string{
+			"sigs.k8s.io_cluster-api-provider-azure_cluster_test-cluster": to.StringPtr("owned"),
+			"team": to.StringPtr("networking"),
+		},
+		SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
+			SecurityRules: &[]network.SecurityRule{foreignRule, sdkRule(sshRule)},
+		},
+	}))
+
+	// Further reconciles do not update the adopted security group.
+	result, err := spec.Parameters(adopted)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result).To(BeNil())
+}
+
+func TestParametersReconciledWithoutOwnedTag(t *testing.T) {
+	g := NewWithT(t)
+	spec := &NSGSpec{
+		Name:          "test-nsg",
+		Location:      "test-location",
+		ClusterName:   "test-cluster",
+		SecurityRules: infrav1.SecurityRules{sshRule, otherRule},
+		ResourceGroup: "test-group",
+		Reconciled:    true,
+	}
+	// The security group was created by an earlier release, which neither tagged it as owned nor marked its rules.
+	existing := network.SecurityGroup{
+		Name:     to.StringPtr("test-nsg"),
+		Location: to.StringPtr("test-location"),
+		Etag:     to.StringPtr("fake-etag"),
+		SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
+			SecurityRules: &[]network.SecurityRule{converters.SecurityRuleToSDK(sshRule), converters.SecurityRuleToSDK(otherRule)},
+		},
+	}
+
+	// It is up to date rather than tagged as owned.
+	result, err := spec.Parameters(existing)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result).To(BeNil())
+
+	// A rule that changed is updated without tagging the security group as owned.
+	existing.SecurityRules = &[]network.SecurityRule{converters.SecurityRuleToSDK(sshRuleWithSource("10.0.0.0/16")), converters.SecurityRuleToSDK(otherRule)}
+	result, err = spec.Parameters(existing)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result).To(Equal(network.SecurityGroup{
+		Location: to.StringPtr("test-location"),
+		Etag:     to.StringPtr("fake-etag"),
+		SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
+			SecurityRules: &[]network.SecurityRule{converters.SecurityRuleToSDK(otherRule), sdkRule(sshRule)},
+		},
+	}))
+}
+
+func TestParametersAdoptionConflicts(t *testing.T) {
+	g := NewWithT(t)
+	spec := &NSGSpec{
+		Name:          "test-nsg",
+		Location:      "test-location",
+		ClusterName:   "test-cluster",
+		SecurityRules: infrav1.SecurityRules{sshRule, otherRule},
+		ResourceGroup: "test-group",
+	}
+	// The rules of the user have the priorities of the inbound rules of the spec.
+	userRule := func(name string, priority int32) network.SecurityRule {
+		rule := inboundAllowRule(name, network.SecurityRuleProtocolTCP, "8080")
+		rule.Priority = to.Int32Ptr(priority)
+		return rule
+	}
+	outboundRule := userRule("user_outbound", sshRule.Priority)
+	outboundRule.Direction = network.SecurityRuleDirectionOutbound
+	existing := network.SecurityGroup{
+		Name: to.StringPtr("test-nsg"),
+		SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
+			SecurityRules: &[]network.SecurityRule{
+				userRule("user_web", sshRule.Priority),
+				userRule("user_api", otherRule.Priority),
+				outboundRule,
+			},
+		},
+	}
+
+	result, err := spec.Parameters(existing)
+	g.Expect(err).To(MatchError("failed to merge the rules of the spec into security group test-nsg: [" +
+		"rule allow_ssh has the priority 2200 of existing rule user_web in direction Inbound, " +
+		"rule other_rule has the priority 500 of existing rule user_api in direction Inbound]"))
+	g.Expect(result).To(BeNil())
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package securitygroups
+
+import (
+	"context"
+
+	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-02-01/network"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/async"
+	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
+)
+
+// ownedRulesCleanupSpec is the spec of the update removing the rules owned by CAPZ from a security group that is not
+// owned by the cluster, instead of deleting the security group.
+type ownedRulesCleanupSpec struct {
+	*NSGSpec
+}
+
+// Parameters returns the existing security group without the rules owned by CAPZ, i.e. the rules marked as managed by
+// CAPZ or recorded in the owned rules tags, and without the owned rules tags. It returns nil if there is nothing left
+// to remove.
+func (s *ownedRulesCleanupSpec) Parameters(existing interface{}) (interface{}, error) {
+	if existing == nil {
+		return nil, nil
+	}
+	existingNSG, ok := existing.(network.SecurityGroup)
+	if !ok {
+		return nil, errors.Errorf("%T is not a network.SecurityGroup", existing)
+	}
+
+	owned := ownedRuleNamesFromTags(existingNSG.Tags, s.ClusterName)
+	securityRules := make([]network.SecurityRule, 0)
+	removed := false
+	if existingNSG.SecurityGroupPropertiesFormat != nil && existingNSG.SecurityRules != nil {
+		for _, rule := range *existingNSG.SecurityRules {
+			if ownsRule(rule, owned) {
+				removed = true
+				continue
+			}
+			securityRules = append(securityRules, rule)
+		}
+	}
+	tags, tagsChanged := withOwnedRulesTags(existingNSG.Tags, s.ClusterName, nil)
+	if !removed && !tagsChanged {
+		return nil, nil
+	}
+
+	return network.SecurityGroup{
+		Tags:     tags,
+		Location: existingNSG.Location,
+		SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
+			SecurityRules: &securityRules,
+		},
+		Etag: existingNSG.Etag,
+	}, nil
+}
+
+// removeOwnedRules removes the rules owned by CAPZ from the security group of the spec if it exists and is not tagged
+// as owned by the cluster, e.g. a security group brought by the user, so that it is left in place on delete. It returns
+// true if the security group is handled this way and must not be deleted.
+func (s *Service) removeOwnedRules(ctx context.Context, spec azure.ResourceSpecGetter) (bool, error) {
+	ctx, log, done := tele.StartSpanWithLogger(ctx, "securitygroups.Service.removeOwnedRules")
+	defer done()
+
+	nsgSpec, ok := spec.(*NSGSpec)
+	if !ok || nsgSpec.ClusterName == "" {
+		return false, nil
+	}
+	result, err := s.GetResource(ctx, nsgSpec, serviceName)
+	if errors.Is(err, async.ErrResourceNotFound) {
+		return false, nil
+	} else if err != nil {
+		return true, err
+	}
+	existing, ok := result.(network.SecurityGroup)
+	if !ok {
+		return true, errors.Errorf("%T is not a network.SecurityGroup", result)
+	}
+	if !nsgSpec.adopting(existing) {
+		return false, nil
+	}
+
+	log.V(2).Info("security group is not owned by the cluster, removing only its owned rules", "securityGroup", nsgSpec.Name)
+	_, _, err = s.CreateResource(ctx, &ownedRulesCleanupSpec{NSGSpec: nsgSpec}, serviceName)
+	return true, err
+}
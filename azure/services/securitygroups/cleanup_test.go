@@ -0,0 +1,257 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package securitygroups
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-02-01/network"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/async"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/async/mock_async"
+	gomockinternal "sigs.k8s.io/cluster-api-provider-azure/internal/test/matchers/gomock"
+)
+
+// userRule returns a rule with the given name that is not marked as managed by CAPZ.
+func userRule(name string) network.SecurityRule {
+	return network.SecurityRule{
+		Name: to.StringPtr(name),
+		SecurityRulePropertiesFormat: &network.SecurityRulePropertiesFormat{
+			Description: to.StringPtr("rule of the user"),
+			Protocol:    network.SecurityRuleProtocolTCP,
+			Priority:    to.Int32Ptr(100),
+			Direction:   network.SecurityRuleDirectionInbound,
+		},
+	}
+}
+
+func TestOwnedRulesCleanupSpecParameters(t *testing.T) {
+	ownedRulesTag := infrav1.OwnedSecurityRulesTagKey("my-cluster")
+	testcases := []struct {
+		name          string
+		existing      interface{}
+		expected      interface{}
+		expectedError string
+	}{
+		{
+			name:     "security group does not exist",
+			existing: nil,
+			expected: nil,
+		},
+		{
+			name: "managed rules are removed",
+			existing: network.SecurityGroup{
+				Location: to.StringPtr("test-location"),
+				Etag:     to.StringPtr("fake-etag"),
+				Tags:     map[string]*string{"team": to.StringPtr("network")},
+				SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
+					SecurityRules: &[]network.SecurityRule{managedRule(userRule("allow_ssh")), userRule("allow_vpn")},
+				},
+			},
+			expected: network.SecurityGroup{
+				Location: to.StringPtr("test-location"),
+				Etag:     to.StringPtr("fake-etag"),
+				Tags:     map[string]*string{"team": to.StringPtr("network")},
+				SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
+					SecurityRules: &[]network.SecurityRule{userRule("allow_vpn")},
+				},
+			},
+		},
+		{
+			name: "rules recorded in the owned rules tags are removed with the tags",
+			existing: network.SecurityGroup{
+				Location: to.StringPtr("test-location"),
+				Tags: map[string]*string{
+					"team":        to.StringPtr("network"),
+					ownedRulesTag: to.StringPtr("allow_http"),
+				},
+				SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
+					SecurityRules: &[]network.SecurityRule{userRule("allow_http"), userRule("allow_vpn")},
+				},
+			},
+			expected: network.SecurityGroup{
+				Location: to.StringPtr("test-location"),
+				Tags:     map[string]*string{"team": to.StringPtr("network")},
+				SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
+					SecurityRules: &[]network.SecurityRule{userRule("allow_vpn")},
+				},
+			},
+		},
+		{
+			name: "security group without owned rules is not updated",
+			existing: network.SecurityGroup{
+				Location: to.StringPtr("test-location"),
+				SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
+					SecurityRules: &[]network.SecurityRule{userRule("allow_vpn")},
+				},
+			},
+			expected: nil,
+		},
+		{
+			name:          "existing is not a security group",
+			existing:      struct{}{},
+			expectedError: "struct {} is not a network.SecurityGroup",
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			spec := &ownedRulesCleanupSpec{NSGSpec: &NSGSpec{Name: "test-nsg", ClusterName: "my-cluster"}}
+			result, err := spec.Parameters(tc.existing)
+			if tc.expectedError != "" {
+				g.Expect(err).To(MatchError(tc.expectedError))
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			if tc.expected == nil {
+				g.Expect(result).To(BeNil())
+				return
+			}
+			g.Expect(result).To(Equal(tc.expected))
+		})
+	}
+}
+
+func TestDeleteSecurityGroupsPreserveUnowned(t *testing.T) {
+	nsgSpec := &NSGSpec{Name: "test-nsg", ResourceGroup: "test-group", Location: "test-location", ClusterName: "my-cluster"}
+	ownedNSG := network.SecurityGroup{
+		Name: to.StringPtr("test-nsg"),
+		Tags: map[string]*string{infrav1.ClusterTagKey("my-cluster"): to.StringPtr(string(infrav1.ResourceLifecycleOwned))},
+	}
+	userNSG := network.SecurityGroup{
+		Name: to.StringPtr("test-nsg"),
+		SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
+			SecurityRules: &[]network.SecurityRule{managedRule(userRule("allow_ssh")), userRule("allow_vpn")},
+		},
+	}
+	cleanupSpec := &ownedRulesCleanupSpec{NSGSpec: nsgSpec}
+	reconciledSpec := &NSGSpec{Name: "test-nsg", ResourceGroup: "test-group", Location: "test-location", ClusterName: "my-cluster", Reconciled: true}
+	reconciledNSG := network.SecurityGroup{
+		Name: to.StringPtr("test-nsg"),
+		SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
+			SecurityRules: &[]network.SecurityRule{userRule("allow_ssh")},
+		},
+	}
+
+	testcases := []struct {
+		name            string
+		spec            *NSGSpec
+		expectedError   string
+		preserveUnowned bool
+		expect          func(r *mock_async.MockReconcilerMockRecorder)
+	}{
+		{
+			name: "security group created before the upgrade is deleted by default",
+			expect: func(r *mock_async.MockReconcilerMockRecorder) {
+				// Security groups created by earlier releases are not tagged as owned by the cluster.
+				r.DeleteResource(gomockinternal.AContext(), nsgSpec, serviceName).Return(false, nil)
+			},
+		},
+		{
+			name:            "security group owned by the cluster is deleted",
+			preserveUnowned: true,
+			expect: func(r *mock_async.MockReconcilerMockRecorder) {
+				r.GetResource(gomockinternal.AContext(), nsgSpec, serviceName).Return(ownedNSG, nil)
+				r.DeleteResource(gomockinternal.AContext(), nsgSpec, serviceName).Return(false, nil)
+			},
+		},
+		{
+			name:            "security group reconciled before the upgrade is deleted without the owned tag",
+			spec:            reconciledSpec,
+			preserveUnowned: true,
+			expect: func(r *mock_async.MockReconcilerMockRecorder) {
+				r.GetResource(gomockinternal.AContext(), reconciledSpec, serviceName).Return(reconciledNSG, nil)
+				r.DeleteResource(gomockinternal.AContext(), reconciledSpec, serviceName).Return(false, nil)
+			},
+		},
+		{
+			name:            "security group not owned by the cluster only has its owned rules removed",
+			preserveUnowned: true,
+			expect: func(r *mock_async.MockReconcilerMockRecorder) {
+				r.GetResource(gomockinternal.AContext(), nsgSpec, serviceName).Return(userNSG, nil)
+				r.CreateResource(gomockinternal.AContext(), cleanupSpec, serviceName).Return(nil, true, nil)
+			},
+		},
+		{
+			name:            "removing the owned rules is not done",
+			preserveUnowned: true,
+			expectedError:   notDoneError.Error(),
+			expect: func(r *mock_async.MockReconcilerMockRecorder) {
+				r.GetResource(gomockinternal.AContext(), nsgSpec, serviceName).Return(userNSG, nil)
+				r.CreateResource(gomockinternal.AContext(), cleanupSpec, serviceName).Return(nil, false, notDoneError)
+			},
+		},
+		{
+			name:            "security group that does not exist is deleted as usual",
+			preserveUnowned: true,
+			expect: func(r *mock_async.MockReconcilerMockRecorder) {
+				r.GetResource(gomockinternal.AContext(), nsgSpec, serviceName).Return(nil, errors.Wrap(async.ErrResourceNotFound, "resource test-group/test-nsg"))
+				r.DeleteResource(gomockinternal.AContext(), nsgSpec, serviceName).Return(true, nil)
+			},
+		},
+		{
+			name:            "security group cannot be read",
+			preserveUnowned: true,
+			expectedError:   errFake.Error(),
+			expect: func(r *mock_async.MockReconcilerMockRecorder) {
+				r.GetResource(gomockinternal.AContext(), nsgSpec, serviceName).Return(nil, errFake)
+			},
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			scopeMock := newMockNSGScope(mockCtrl)
+			reconcilerMock := mock_async.NewMockReconciler(mockCtrl)
+
+			spec := tc.spec
+			if spec == nil {
+				spec = nsgSpec
+			}
+			scopeMock.EXPECT().IsVnetManaged().Return(true)
+			scopeMock.EXPECT().NSGSpecs().Return([]azure.ResourceSpecGetter{spec})
+			scopeMock.EXPECT().UpdateDeleteStatus(infrav1.SecurityGroupsReadyCondition, serviceName, gomock.Any())
+			tc.expect(reconcilerMock.EXPECT())
+
+			s := &Service{
+				Scope:           scopeMock,
+				Reconciler:      reconcilerMock,
+				PreserveUnowned: tc.preserveUnowned,
+			}
+
+			err := s.Delete(context.TODO())
+			if tc.expectedError != "" {
+				g.Expect(err).To(MatchError(tc.expectedError))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
@@ -172,3 +172,12 @@ func (ac *azureClient) Result(ctx context.Context, future azureautorest.FutureAP
 		return nil, errors.Errorf("unknown future type %q", futureType)
 	}
 }
+
+// ProvisioningState returns the provisioning state of a security group returned by the client.
+func (ac *azureClient) ProvisioningState(resource interface{}) (state string, ok bool) {
+	sg, ok := resource.(network.SecurityGroup)
+	if !ok || sg.SecurityGroupPropertiesFormat == nil || sg.ProvisioningState == "" {
+		return "", false
+	}
+	return string(sg.ProvisioningState), true
+}
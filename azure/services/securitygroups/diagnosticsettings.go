@@ -0,0 +1,184 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package securitygroups
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/services/preview/monitor/mgmt/2019-06-01/insights"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/util/reconciler"
+	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
+)
+
+const diagnosticSettingsServiceName = "diagnosticsettings"
+
+// defaultDiagnosticLogCategories are the log categories of security groups, i.e. the events of the rules applied to
+// network interfaces and subnets, and the number of times each rule was applied.
+var defaultDiagnosticLogCategories = []string{"NetworkSecurityGroupEvent", "NetworkSecurityGroupRuleCounter"}
+
+// DiagnosticSettingsSpec defines the specification for the diagnostic settings of a security group, which ship its logs
+// to a Log Analytics workspace.
+type DiagnosticSettingsSpec struct {
+	// Name is the name of the diagnostic settings. It defaults to <security group>-diagnostics.
+	Name string
+	// SecurityGroupName is the name of the security group. It defaults to the name of the security group.
+	SecurityGroupName string
+	// ResourceGroup is the resource group of the security group. It defaults to the resource group of the security group.
+	ResourceGroup string
+	// WorkspaceID is the ID of the Log Analytics workspace the logs are sent to.
+	WorkspaceID string
+	// Categories are the log categories sent to the workspace. They default to all the log categories of security groups.
+	Categories []string
+}
+
+// ResourceName returns the name of the diagnostic settings.
+func (s *DiagnosticSettingsSpec) ResourceName() string {
+	return s.Name
+}
+
+// ResourceGroupName returns the name of the resource group of the security group.
+func (s *DiagnosticSettingsSpec) ResourceGroupName() string {
+	return s.ResourceGroup
+}
+
+// OwnerResourceName returns the name of the security group.
+func (s *DiagnosticSettingsSpec) OwnerResourceName() string {
+	return s.SecurityGroupName
+}
+
+// Parameters returns the parameters for the diagnostic settings.
+func (s *DiagnosticSettingsSpec) Parameters(existing interface{}) (interface{}, error) {
+	if existing != nil {
+		existingSettings, ok := existing.(insights.DiagnosticSettingsResource)
+		if !ok {
+			return nil, errors.Errorf("%T is not an insights.DiagnosticSettingsResource", existing)
+		}
+		if s.upToDate(existingSettings) {
+			return nil, nil
+		}
+	}
+
+	logs := make([]insights.LogSettings, len(s.Categories))
+	for i, category := range s.Categories {
+		logs[i] = insights.LogSettings{
+			Category: to.StringPtr(category),
+			Enabled:  to.BoolPtr(true),
+		}
+	}
+	return insights.DiagnosticSettingsResource{
+		DiagnosticSettings: &insights.DiagnosticSettings{
+			WorkspaceID: to.StringPtr(s.WorkspaceID),
+			Logs:        &logs,
+		},
+	}, nil
+}
+
+// upToDate returns true if the existing diagnostic settings send all the categories of the spec to its workspace.
+func (s *DiagnosticSettingsSpec) upToDate(existing insights.DiagnosticSettingsResource) bool {
+	settings := existing.DiagnosticSettings
+	if settings == nil || !strings.EqualFold(to.String(settings.WorkspaceID), s.WorkspaceID) {
+		return false
+	}
+	enabled := make(map[string]bool)
+	if settings.Logs != nil {
+		for _, log := range *settings.Logs {
+			if to.Bool(log.Enabled) {
+				enabled[strings.ToLower(to.String(log.Category))] = true
+			}
+		}
+	}
+	for _, category := range s.Categories {
+		if !enabled[strings.ToLower(category)] {
+			return false
+		}
+	}
+	return true
+}
+
+// DiagnosticSettingsDeleter deletes the diagnostic settings of security groups. Diagnostic settings outlive the
+// resource they belong to, so they must be deleted before the resource group of the cluster is.
+type DiagnosticSettingsDeleter interface {
+	DeleteDiagnosticSettings(ctx context.Context) error
+}
+
+// diagnosticSettingsSpec returns the spec of the diagnostic settings of a security group with its defaults filled in,
+// or nil if diagnostic settings are not enabled for the security group.
+func (s *Service) diagnosticSettingsSpec(spec azure.ResourceSpecGetter) *DiagnosticSettingsSpec {
+	nsgSpec, ok := spec.(*NSGSpec)
+	if !ok || nsgSpec.DiagnosticSettings == nil {
+		return nil
+	}
+	settings := *nsgSpec.DiagnosticSettings
+	if settings.Name == "" {
+		settings.Name = fmt.Sprintf("%s-diagnostics", nsgSpec.Name)
+	}
+	if settings.SecurityGroupName == "" {
+		settings.SecurityGroupName = nsgSpec.Name
+	}
+	if settings.ResourceGroup == "" {
+		settings.ResourceGroup = nsgSpec.ResourceGroup
+	}
+	if len(settings.Categories) == 0 {
+		settings.Categories = defaultDiagnosticLogCategories
+	}
+	return &settings
+}
+
+// reconcileDiagnosticSettings creates or updates the diagnostic settings of a reconciled security group, if they are
+// enabled for it.
+func (s *Service) reconcileDiagnosticSettings(ctx context.Context, nsgSpec azure.ResourceSpecGetter) error {
+	settings := s.diagnosticSettingsSpec(nsgSpec)
+	if settings == nil || s.DiagnosticSettingsReconciler == nil {
+		return nil
+	}
+	_, _, err := s.DiagnosticSettingsReconciler.CreateResource(ctx, settings, diagnosticSettingsServiceName)
+	return err
+}
+
+// deleteDiagnosticSettings deletes the diagnostic settings of a security group, if they are enabled for it.
+func (s *Service) deleteDiagnosticSettings(ctx context.Context, nsgSpec azure.ResourceSpecGetter) error {
+	settings := s.diagnosticSettingsSpec(nsgSpec)
+	if settings == nil || s.DiagnosticSettingsReconciler == nil {
+		return nil
+	}
+	_, err := s.DiagnosticSettingsReconciler.DeleteResource(ctx, settings, diagnosticSettingsServiceName)
+	return err
+}
+
+// DeleteDiagnosticSettings deletes the diagnostic settings of the security groups, leaving the security groups intact.
+func (s *Service) DeleteDiagnosticSettings(ctx context.Context) error {
+	ctx, log, done := tele.StartSpanWithLogger(ctx, "securitygroups.Service.DeleteDiagnosticSettings")
+	defer done()
+
+	ctx, cancel := context.WithTimeout(ctx, reconciler.ServiceReconcileTimeout(s.Reconciler))
+	defer cancel()
+
+	// Only delete the diagnostic settings if the lifecycle of the NSGs is managed by this controller.
+	if !s.Scope.IsVnetManaged() {
+		log.V(4).Info("Skipping diagnostic settings delete in custom VNet mode")
+		return nil
+	}
+
+	return s.forEachSpec(s.nsgSpecs(ctx), func(nsgSpec azure.ResourceSpecGetter) error {
+		return s.deleteDiagnosticSettings(ctx, nsgSpec)
+	})
+}
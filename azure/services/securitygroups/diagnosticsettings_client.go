@@ -0,0 +1,108 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package securitygroups
+
+import (
+	"context"
+
+	"github.com/Azure/azure-sdk-for-go/services/preview/monitor/mgmt/2019-06-01/insights"
+	"github.com/Azure/go-autorest/autorest"
+	azureautorest "github.com/Azure/go-autorest/autorest/azure"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
+)
+
+// diagnosticSettingsClient contains the Azure go-sdk Client for the diagnostic settings of security groups.
+// Diagnostic settings are created, updated and deleted synchronously, so its operations never return a future.
+type diagnosticSettingsClient struct {
+	subscriptionID     string
+	diagnosticSettings insights.DiagnosticSettingsClient
+}
+
+// newDiagnosticSettingsClient creates a new diagnostic settings client from subscription ID.
+func newDiagnosticSettingsClient(auth azure.Authorizer) *diagnosticSettingsClient {
+	c := newDiagnosticSettingsSDKClient(auth.SubscriptionID(), auth.BaseURI(), auth.Authorizer())
+	return &diagnosticSettingsClient{subscriptionID: auth.SubscriptionID(), diagnosticSettings: c}
+}
+
+// newDiagnosticSettingsSDKClient creates a new diagnostic settings SDK client from subscription ID.
+func newDiagnosticSettingsSDKClient(subscriptionID string, baseURI string, authorizer autorest.Authorizer) insights.DiagnosticSettingsClient {
+	diagnosticSettingsClient := insights.NewDiagnosticSettingsClientWithBaseURI(baseURI, subscriptionID)
+	azure.SetAutoRestClientDefaults(&diagnosticSettingsClient.Client, authorizer)
+	return diagnosticSettingsClient
+}
+
+// resourceURI returns the ID of the security group the diagnostic settings of the spec belong to.
+func (ac *diagnosticSettingsClient) resourceURI(spec azure.ResourceSpecGetter) string {
+	return azure.SecurityGroupID(ac.subscriptionID, spec.ResourceGroupName(), spec.OwnerResourceName())
+}
+
+// Get gets the specified diagnostic settings.
+func (ac *diagnosticSettingsClient) Get(ctx context.Context, spec azure.ResourceSpecGetter) (result interface{}, err error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "securitygroups.diagnosticSettingsClient.Get")
+	defer done()
+
+	return ac.diagnosticSettings.Get(ctx, ac.resourceURI(spec), spec.ResourceName())
+}
+
+// CreateOrUpdateAsync creates or updates the diagnostic settings of a security group. The operation completes right
+// away, so the returned future is always nil.
+func (ac *diagnosticSettingsClient) CreateOrUpdateAsync(ctx context.Context, spec azure.ResourceSpecGetter, parameters interface{}) (result interface{}, future azureautorest.FutureAPI, err error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "securitygroups.diagnosticSettingsClient.CreateOrUpdate")
+	defer done()
+
+	settings, ok := parameters.(insights.DiagnosticSettingsResource)
+	if !ok {
+		return nil, nil, errors.Errorf("%T is not an insights.DiagnosticSettingsResource", parameters)
+	}
+
+	result, err = ac.diagnosticSettings.CreateOrUpdate(ctx, ac.resourceURI(spec), settings, spec.ResourceName())
+	return result, nil, err
+}
+
+// DeleteAsync deletes the specified diagnostic settings. The operation completes right away, so the returned future is
+// always nil.
+func (ac *diagnosticSettingsClient) DeleteAsync(ctx context.Context, spec azure.ResourceSpecGetter) (future azureautorest.FutureAPI, err error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "securitygroups.diagnosticSettingsClient.Delete")
+	defer done()
+
+	_, err = ac.diagnosticSettings.Delete(ctx, ac.resourceURI(spec), spec.ResourceName())
+	return nil, err
+}
+
+// IsDone returns true if the long-running operation has completed.
+func (ac *diagnosticSettingsClient) IsDone(ctx context.Context, future azureautorest.FutureAPI) (isDone bool, err error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "securitygroups.diagnosticSettingsClient.IsDone")
+	defer done()
+
+	isDone, err = future.DoneWithContext(ctx, ac.diagnosticSettings)
+	if err != nil {
+		return false, errors.Wrap(err, "failed checking if the operation was complete")
+	}
+
+	return isDone, nil
+}
+
+// Result fetches the result of a long-running operation future. Diagnostic settings operations never return a future,
+// so there is no result to fetch.
+func (ac *diagnosticSettingsClient) Result(ctx context.Context, future azureautorest.FutureAPI, futureType string) (result interface{}, err error) {
+	_, _, done := tele.StartSpanWithLogger(ctx, "securitygroups.diagnosticSettingsClient.Result")
+	defer done()
+
+	return nil, errors.Errorf("diagnostic settings operations are synchronous, cannot get result from future of type %q", futureType)
+}
@@ -0,0 +1,201 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package securitygroups
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/preview/monitor/mgmt/2019-06-01/insights"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/async/mock_async"
+	gomockinternal "sigs.k8s.io/cluster-api-provider-azure/internal/test/matchers/gomock"
+)
+
+var (
+	fakeNSGWithDiagnostics = NSGSpec{
+		Name:          "test-nsg",
+		Location:      "test-location",
+		ResourceGroup: "test-group",
+		ClusterName:   "test-cluster",
+		DiagnosticSettings: &DiagnosticSettingsSpec{
+			WorkspaceID: "/subscriptions/123/resourceGroups/audit/providers/Microsoft.OperationalInsights/workspaces/logs",
+		},
+	}
+	fakeDiagnosticSettings = DiagnosticSettingsSpec{
+		Name:              "test-nsg-diagnostics",
+		SecurityGroupName: "test-nsg",
+		ResourceGroup:     "test-group",
+		WorkspaceID:       "/subscriptions/123/resourceGroups/audit/providers/Microsoft.OperationalInsights/workspaces/logs",
+		Categories:        []string{"NetworkSecurityGroupEvent", "NetworkSecurityGroupRuleCounter"},
+	}
+)
+
+func TestDiagnosticSettingsParameters(t *testing.T) {
+	desired := insights.DiagnosticSettingsResource{
+		DiagnosticSettings: &insights.DiagnosticSettings{
+			WorkspaceID: to.StringPtr(fakeDiagnosticSettings.WorkspaceID),
+			Logs: &[]insights.LogSettings{
+				{Category: to.StringPtr("NetworkSecurityGroupEvent"), Enabled: to.BoolPtr(true)},
+				{Category: to.StringPtr("NetworkSecurityGroupRuleCounter"), Enabled: to.BoolPtr(true)},
+			},
+		},
+	}
+
+	testcases := []struct {
+		name     string
+		existing interface{}
+		expected interface{}
+	}{
+		{
+			name:     "diagnostic settings do not exist",
+			existing: nil,
+			expected: desired,
+		},
+		{
+			name:     "diagnostic settings are up to date",
+			existing: desired,
+			expected: nil,
+		},
+		{
+			name: "diagnostic settings are up to date with categories in a different case",
+			existing: insights.DiagnosticSettingsResource{
+				DiagnosticSettings: &insights.DiagnosticSettings{
+					WorkspaceID: to.StringPtr("/SUBSCRIPTIONS/123/resourceGroups/audit/providers/Microsoft.OperationalInsights/workspaces/logs"),
+					Logs: &[]insights.LogSettings{
+						{Category: to.StringPtr("networksecuritygroupevent"), Enabled: to.BoolPtr(true)},
+						{Category: to.StringPtr("networksecuritygrouprulecounter"), Enabled: to.BoolPtr(true)},
+					},
+				},
+			},
+			expected: nil,
+		},
+		{
+			name: "diagnostic settings send logs to another workspace",
+			existing: insights.DiagnosticSettingsResource{
+				DiagnosticSettings: &insights.DiagnosticSettings{
+					WorkspaceID: to.StringPtr("/subscriptions/123/resourceGroups/audit/providers/Microsoft.OperationalInsights/workspaces/other"),
+					Logs:        desired.Logs,
+				},
+			},
+			expected: desired,
+		},
+		{
+			name: "diagnostic settings have a disabled log category",
+			existing: insights.DiagnosticSettingsResource{
+				DiagnosticSettings: &insights.DiagnosticSettings{
+					WorkspaceID: to.StringPtr(fakeDiagnosticSettings.WorkspaceID),
+					Logs: &[]insights.LogSettings{
+						{Category: to.StringPtr("NetworkSecurityGroupEvent"), Enabled: to.BoolPtr(true)},
+						{Category: to.StringPtr("NetworkSecurityGroupRuleCounter"), Enabled: to.BoolPtr(false)},
+					},
+				},
+			},
+			expected: desired,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			result, err := fakeDiagnosticSettings.Parameters(tc.existing)
+			g.Expect(err).NotTo(HaveOccurred())
+			if tc.expected != nil {
+				g.Expect(result).To(Equal(tc.expected))
+			} else {
+				g.Expect(result).To(BeNil())
+			}
+		})
+	}
+}
+
+func TestReconcileDiagnosticSettings(t *testing.T) {
+	t.Run("diagnostic settings are reconciled after their security group", func(t *testing.T) {
+		g := NewWithT(t)
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+		scopeMock := newMockNSGScope(mockCtrl)
+		reconcilerMock := mock_async.NewMockReconciler(mockCtrl)
+		diagnosticSettingsReconcilerMock := mock_async.NewMockReconciler(mockCtrl)
+
+		scopeMock.EXPECT().IsVnetManaged().Return(true)
+		scopeMock.EXPECT().NSGSpecs().Return([]azure.ResourceSpecGetter{&fakeNSGWithDiagnostics})
+		gomock.InOrder(
+			reconcilerMock.EXPECT().CreateResource(gomockinternal.AContext(), &fakeNSGWithDiagnostics, serviceName).Return(nil, false, nil),
+			diagnosticSettingsReconcilerMock.EXPECT().CreateResource(gomockinternal.AContext(), &fakeDiagnosticSettings, diagnosticSettingsServiceName).Return(nil, false, nil),
+		)
+		scopeMock.EXPECT().UpdatePutStatus(infrav1.SecurityGroupsReadyCondition, serviceName, nil)
+		scopeMock.EXPECT().SetSecurityGroupsProgress(infrav1.SecurityGroupsProgress{Total: 1, Ready: 1})
+
+		s := &Service{
+			Scope:                        scopeMock,
+			Reconciler:                   reconcilerMock,
+			DiagnosticSettingsReconciler: diagnosticSettingsReconcilerMock,
+		}
+		g.Expect(s.Reconcile(context.TODO())).To(Succeed())
+	})
+
+	t.Run("diagnostic settings are deleted before their security group", func(t *testing.T) {
+		g := NewWithT(t)
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+		scopeMock := newMockNSGScope(mockCtrl)
+		reconcilerMock := mock_async.NewMockReconciler(mockCtrl)
+		diagnosticSettingsReconcilerMock := mock_async.NewMockReconciler(mockCtrl)
+
+		scopeMock.EXPECT().IsVnetManaged().Return(true)
+		scopeMock.EXPECT().NSGSpecs().Return([]azure.ResourceSpecGetter{&fakeNSGWithDiagnostics})
+		gomock.InOrder(
+			diagnosticSettingsReconcilerMock.EXPECT().DeleteResource(gomockinternal.AContext(), &fakeDiagnosticSettings, diagnosticSettingsServiceName).Return(false, nil),
+			reconcilerMock.EXPECT().DeleteResource(gomockinternal.AContext(), &fakeNSGWithDiagnostics, serviceName).Return(false, nil),
+		)
+		scopeMock.EXPECT().UpdateDeleteStatus(infrav1.SecurityGroupsReadyCondition, serviceName, nil)
+
+		s := &Service{
+			Scope:                        scopeMock,
+			Reconciler:                   reconcilerMock,
+			DiagnosticSettingsReconciler: diagnosticSettingsReconcilerMock,
+		}
+		g.Expect(s.Delete(context.TODO())).To(Succeed())
+	})
+
+	t.Run("diagnostic settings are deleted without their security groups", func(t *testing.T) {
+		g := NewWithT(t)
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+		scopeMock := newMockNSGScope(mockCtrl)
+		reconcilerMock := mock_async.NewMockReconciler(mockCtrl)
+		diagnosticSettingsReconcilerMock := mock_async.NewMockReconciler(mockCtrl)
+
+		scopeMock.EXPECT().IsVnetManaged().Return(true)
+		scopeMock.EXPECT().NSGSpecs().Return([]azure.ResourceSpecGetter{&fakeNSGWithDiagnostics, &fakeNSG2})
+		diagnosticSettingsReconcilerMock.EXPECT().DeleteResource(gomockinternal.AContext(), &fakeDiagnosticSettings, diagnosticSettingsServiceName).Return(false, nil)
+
+		s := &Service{
+			Scope:                        scopeMock,
+			Reconciler:                   reconcilerMock,
+			DiagnosticSettingsReconciler: diagnosticSettingsReconcilerMock,
+		}
+		g.Expect(s.DeleteDiagnosticSettings(context.TODO())).To(Succeed())
+	})
+}
@@ -0,0 +1,164 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package securitygroups
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-02-01/network"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/util/reconciler"
+	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
+)
+
+// FirewallRule is a network rule of a filter rule collection of an Azure Firewall policy.
+type FirewallRule struct {
+	Name string
+	// Action is the action of the rule collection of the rule, i.e. Allow or Deny.
+	Action               network.FirewallPolicyFilterRuleCollectionActionType
+	Protocols            []network.FirewallPolicyRuleNetworkProtocol
+	SourceAddresses      []string
+	DestinationAddresses []string
+	DestinationPorts     []string
+}
+
+// FirewallPolicyReader reads the network rules of Azure Firewall policies.
+type FirewallPolicyReader interface {
+	// NetworkRules returns the network rules of the filter rule collections of the Azure Firewall policy with the given
+	// resource ID.
+	NetworkRules(ctx context.Context, policyID string) ([]FirewallRule, error)
+}
+
+// firewallProtocols are the protocols of the network rules of an Azure Firewall policy matching the protocol of a
+// security rule. The firewall does not filter the other protocols, e.g. ESP, so their rules are always created.
+var firewallProtocols = map[network.SecurityRuleProtocol][]network.FirewallPolicyRuleNetworkProtocol{
+	network.SecurityRuleProtocolAsterisk: {network.FirewallPolicyRuleNetworkProtocolAny},
+	network.SecurityRuleProtocolTCP:      {network.FirewallPolicyRuleNetworkProtocolTCP, network.FirewallPolicyRuleNetworkProtocolAny},
+	network.SecurityRuleProtocolUDP:      {network.FirewallPolicyRuleNetworkProtocolUDP, network.FirewallPolicyRuleNetworkProtocolAny},
+	network.SecurityRuleProtocolIcmp:     {network.FirewallPolicyRuleNetworkProtocolICMP, network.FirewallPolicyRuleNetworkProtocolAny},
+}
+
+// resolveFirewallRules reads the network rules of the Azure Firewall policy referenced by the spec into the
+// FirewallRules of the spec, and logs the rules of the spec that are not created because the firewall already enforces
+// them. They are read on every reconcile, so that the rules are created again when the policy changes. A policy that
+// cannot be read returns a transient error, so that the security group is left as is rather than updated with rules
+// the firewall may or may not enforce.
+func (s *Service) resolveFirewallRules(ctx context.Context, spec azure.ResourceSpecGetter) error {
+	ctx, log, done := tele.StartSpanWithLogger(ctx, "securitygroups.Service.resolveFirewallRules")
+	defer done()
+
+	nsgSpec, ok := spec.(*NSGSpec)
+	if !ok || nsgSpec.FirewallPolicyID == "" {
+		return nil
+	}
+	if s.FirewallPolicies == nil {
+		return errors.Errorf("cannot read Azure Firewall policy %s of security group %s, no firewall policy reader is configured", nsgSpec.FirewallPolicyID, nsgSpec.Name)
+	}
+	rules, err := s.FirewallPolicies.NetworkRules(ctx, nsgSpec.FirewallPolicyID)
+	if err != nil {
+		return azure.WithTransientError(errors.Wrapf(err, "failed to read the network rules of Azure Firewall policy %s of security group %s", nsgSpec.FirewallPolicyID, nsgSpec.Name), reconciler.DefaultReconcilerRequeue)
+	}
+	nsgSpec.FirewallRules = rules
+	if suppressed := nsgSpec.FirewallEnforcedRules(); len(suppressed) > 0 {
+		log.V(2).Info("skipping security rules enforced by the Azure Firewall policy", "securityGroup", nsgSpec.Name, "firewallPolicy", nsgSpec.FirewallPolicyID, "rules", suppressed)
+	}
+	return nil
+}
+
+// FirewallEnforcedRules returns the names of the rules of the spec that are not created because a network rule of the
+// Azure Firewall policy already allows or denies their traffic.
+func (s *NSGSpec) FirewallEnforcedRules() []string {
+	var names []string
+	for _, rule := range s.allDesiredRules() {
+		if enforcedByFirewall(s.FirewallRules, rule) {
+			names = append(names, to.String(rule.Name))
+		}
+	}
+	return names
+}
+
+// enforcedByFirewall returns true if one of the network rules of an Azure Firewall policy has the same action as the
+// security rule and covers all of its traffic: its protocol, sources, destinations and destination ports. The source
+// ports and direction of the security rule are not taken into account since the firewall filters neither. Rules with
+// application security groups are never enforced by the firewall, which does not know their members.
+func enforcedByFirewall(firewallRules []FirewallRule, rule network.SecurityRule) bool {
+	if len(firewallRules) == 0 || rule.SecurityRulePropertiesFormat == nil ||
+		rule.SourceApplicationSecurityGroups != nil || rule.DestinationApplicationSecurityGroups != nil {
+		return false
+	}
+	protocols, ok := firewallProtocols[rule.Protocol]
+	if !ok {
+		return false
+	}
+	sources := addressPrefixes(rule.SourceAddressPrefix, rule.SourceAddressPrefixes)
+	destinations := addressPrefixes(rule.DestinationAddressPrefix, rule.DestinationAddressPrefixes)
+	ports := portRanges(rule.DestinationPortRange, rule.DestinationPortRanges)
+	if len(sources) == 0 || len(destinations) == 0 || len(ports) == 0 {
+		return false
+	}
+	for _, firewallRule := range firewallRules {
+		if !strings.EqualFold(string(firewallRule.Action), string(rule.Access)) || !hasFirewallProtocol(firewallRule.Protocols, protocols) {
+			continue
+		}
+		if !addressesCovered(firewallRule.SourceAddresses, sources) || !addressesCovered(firewallRule.DestinationAddresses, destinations) {
+			continue
+		}
+		covered := true
+		for _, portRange := range ports {
+			if !portsCovered(firewallRule.DestinationPorts, portRange) {
+				covered = false
+				break
+			}
+		}
+		if covered {
+			return true
+		}
+	}
+	return false
+}
+
+// hasFirewallProtocol returns true if one of the protocols of a network rule of an Azure Firewall policy is one of the
+// wanted protocols.
+func hasFirewallProtocol(protocols []network.FirewallPolicyRuleNetworkProtocol, wanted []network.FirewallPolicyRuleNetworkProtocol) bool {
+	for _, protocol := range protocols {
+		for _, w := range wanted {
+			if strings.EqualFold(string(protocol), string(w)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// addressesCovered returns true if all the addresses are listed in the allowed addresses, or if any address is allowed.
+// Addresses are compared as written, so a CIDR is not covered by a larger CIDR of the firewall rule.
+func addressesCovered(allowed []string, addresses []string) bool {
+	for _, address := range addresses {
+		if !containsFold(allowed, "*") && !containsFold(allowed, address) {
+			return false
+		}
+	}
+	return true
+}
+
+// addressPrefixes returns the address prefixes of a rule, whether it uses the singular or the plural field.
+func addressPrefixes(prefix *string, prefixes *[]string) []string {
+	return portRanges(prefix, prefixes)
+}
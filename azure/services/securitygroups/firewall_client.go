@@ -0,0 +1,106 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package securitygroups
+
+import (
+	"context"
+
+	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-02-01/network"
+	"github.com/Azure/go-autorest/autorest"
+	azureautorest "github.com/Azure/go-autorest/autorest/azure"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
+)
+
+// firewallPolicyClient contains the Azure go-sdk Client for the rule collection groups of Azure Firewall policies.
+type firewallPolicyClient struct {
+	ruleCollectionGroups network.FirewallPolicyRuleCollectionGroupsClient
+}
+
+// newFirewallPolicyClient creates a new firewall policy client from subscription ID.
+func newFirewallPolicyClient(auth azure.Authorizer) *firewallPolicyClient {
+	c := newFirewallPolicyRuleCollectionGroupsClient(auth.SubscriptionID(), auth.BaseURI(), auth.Authorizer())
+	return &firewallPolicyClient{c}
+}
+
+// newFirewallPolicyRuleCollectionGroupsClient creates a new firewall policy rule collection groups client from
+// subscription ID.
+func newFirewallPolicyRuleCollectionGroupsClient(subscriptionID string, baseURI string, authorizer autorest.Authorizer) network.FirewallPolicyRuleCollectionGroupsClient {
+	ruleCollectionGroupsClient := network.NewFirewallPolicyRuleCollectionGroupsClientWithBaseURI(baseURI, subscriptionID)
+	azure.SetAutoRestClientDefaults(&ruleCollectionGroupsClient.Client, authorizer)
+	return ruleCollectionGroupsClient
+}
+
+// NetworkRules returns the network rules of the filter rule collections of the Azure Firewall policy with the given
+// resource ID. The rules of NAT rule collections and application rules are skipped since they do not allow or deny
+// network traffic by address and port.
+func (ac *firewallPolicyClient) NetworkRules(ctx context.Context, policyID string) ([]FirewallRule, error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "securitygroups.firewallPolicyClient.NetworkRules")
+	defer done()
+
+	resource, err := azureautorest.ParseResourceID(policyID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid Azure Firewall policy ID %s", policyID)
+	}
+	iter, err := ac.ruleCollectionGroups.ListComplete(ctx, resource.ResourceGroup, resource.ResourceName)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list the rule collection groups")
+	}
+
+	var rules []FirewallRule
+	for ; iter.NotDone(); err = iter.NextWithContext(ctx) {
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to iterate over the rule collection groups")
+		}
+		rules = append(rules, networkRules(iter.Value())...)
+	}
+	return rules, nil
+}
+
+// networkRules returns the network rules of the filter rule collections of a rule collection group.
+func networkRules(group network.FirewallPolicyRuleCollectionGroup) []FirewallRule {
+	if group.FirewallPolicyRuleCollectionGroupProperties == nil || group.RuleCollections == nil {
+		return nil
+	}
+	var rules []FirewallRule
+	for _, basicCollection := range *group.RuleCollections {
+		collection, ok := basicCollection.AsFirewallPolicyFilterRuleCollection()
+		if !ok || collection.Action == nil || collection.Rules == nil {
+			continue
+		}
+		for _, basicRule := range *collection.Rules {
+			rule, ok := basicRule.AsRule()
+			if !ok {
+				continue
+			}
+			firewallRule := FirewallRule{
+				Name:   to.String(rule.Name),
+				Action: collection.Action.Type,
+			}
+			if rule.IPProtocols != nil {
+				firewallRule.Protocols = *rule.IPProtocols
+			}
+			firewallRule.SourceAddresses = to.StringSlice(rule.SourceAddresses)
+			firewallRule.DestinationAddresses = to.StringSlice(rule.DestinationAddresses)
+			firewallRule.DestinationPorts = to.StringSlice(rule.DestinationPorts)
+			rules = append(rules, firewallRule)
+		}
+	}
+	return rules
+}
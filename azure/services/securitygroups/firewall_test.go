@@ -0,0 +1,241 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package securitygroups
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-02-01/network"
+	"github.com/Azure/go-autorest/autorest/to"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+)
+
+const fakeFirewallPolicyID = "/subscriptions/123/resourceGroups/hub-rg/providers/Microsoft.Network/firewallPolicies/hub-policy"
+
+// fakeFirewallPolicyReader returns the same network rules for any firewall policy.
+type fakeFirewallPolicyReader struct {
+	rules []FirewallRule
+	err   error
+}
+
+func (f *fakeFirewallPolicyReader) NetworkRules(_ context.Context, _ string) ([]FirewallRule, error) {
+	return f.rules, f.err
+}
+
+var allowSSHFirewallRule = FirewallRule{
+	Name:                 "allow-ssh",
+	Action:               network.FirewallPolicyFilterRuleCollectionActionTypeAllow,
+	Protocols:            []network.FirewallPolicyRuleNetworkProtocol{network.FirewallPolicyRuleNetworkProtocolTCP},
+	SourceAddresses:      []string{"*"},
+	DestinationAddresses: []string{"*"},
+	DestinationPorts:     []string{"22"},
+}
+
+func TestEnforcedByFirewall(t *testing.T) {
+	withFirewallRule := func(mutate func(*FirewallRule)) []FirewallRule {
+		rule := allowSSHFirewallRule
+		mutate(&rule)
+		return []FirewallRule{rule}
+	}
+	withRule := func(mutate func(*infrav1.SecurityRule)) infrav1.SecurityRule {
+		rule := sshRule
+		mutate(&rule)
+		return rule
+	}
+
+	testcases := []struct {
+		name          string
+		firewallRules []FirewallRule
+		rule          infrav1.SecurityRule
+		expected      bool
+	}{
+		{
+			name:     "no firewall rules",
+			rule:     sshRule,
+			expected: false,
+		},
+		{
+			name:          "firewall rule allows the same traffic",
+			firewallRules: []FirewallRule{allowSSHFirewallRule},
+			rule:          sshRule,
+			expected:      true,
+		},
+		{
+			name: "firewall rule allows any protocol on a port range",
+			firewallRules: withFirewallRule(func(r *FirewallRule) {
+				r.Protocols = []network.FirewallPolicyRuleNetworkProtocol{"Any"}
+				r.DestinationPorts = []string{"1-1024"}
+			}),
+			rule:     sshRule,
+			expected: true,
+		},
+		{
+			name:          "firewall rule denies the traffic the rule allows",
+			firewallRules: withFirewallRule(func(r *FirewallRule) { r.Action = network.FirewallPolicyFilterRuleCollectionActionTypeDeny }),
+			rule:          sshRule,
+			expected:      false,
+		},
+		{
+			name:          "firewall rule has another protocol",
+			firewallRules: withFirewallRule(func(r *FirewallRule) { r.Protocols = []network.FirewallPolicyRuleNetworkProtocol{"UDP"} }),
+			rule:          sshRule,
+			expected:      false,
+		},
+		{
+			name:          "firewall rule does not cover all the ports",
+			firewallRules: []FirewallRule{allowSSHFirewallRule},
+			rule: withRule(func(r *infrav1.SecurityRule) {
+				r.DestinationPorts = nil
+				r.DestinationPortRanges = []string{"22", "2222"}
+			}),
+			expected: false,
+		},
+		{
+			name:          "firewall rule only allows some sources",
+			firewallRules: withFirewallRule(func(r *FirewallRule) { r.SourceAddresses = []string{"10.0.0.0/16"} }),
+			rule:          sshRule,
+			expected:      false,
+		},
+		{
+			name:          "firewall rule allows the sources of the rule",
+			firewallRules: withFirewallRule(func(r *FirewallRule) { r.SourceAddresses = []string{"10.0.0.0/16", "192.168.0.0/24"} }),
+			rule:          withRule(func(r *infrav1.SecurityRule) { r.Source = nil; r.Sources = []string{"192.168.0.0/24", "10.0.0.0/16"} }),
+			expected:      true,
+		},
+		{
+			name: "protocol not filtered by the firewall",
+			firewallRules: withFirewallRule(func(r *FirewallRule) {
+				r.Protocols = []network.FirewallPolicyRuleNetworkProtocol{"Any"}
+				r.DestinationPorts = []string{"*"}
+			}),
+			rule:     withRule(func(r *infrav1.SecurityRule) { r.Protocol = infrav1.SecurityGroupProtocolESP }),
+			expected: false,
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			spec := &NSGSpec{Name: "test-nsg", SecurityRules: infrav1.SecurityRules{tc.rule}}
+			rules := spec.allDesiredRules()
+			g.Expect(enforcedByFirewall(tc.firewallRules, rules[0])).To(Equal(tc.expected))
+		})
+	}
+}
+
+func TestResolveFirewallRules(t *testing.T) {
+	testcases := []struct {
+		name               string
+		firewallPolicyID   string
+		reader             *fakeFirewallPolicyReader
+		expectedRules      []string
+		expectedSuppressed []string
+		expectedError      string
+	}{
+		{
+			name:          "no firewall policy, rules are unchanged",
+			expectedRules: []string{"allow_ssh", "other_rule"},
+		},
+		{
+			name:               "rules enforced by the firewall are skipped",
+			firewallPolicyID:   fakeFirewallPolicyID,
+			reader:             &fakeFirewallPolicyReader{rules: []FirewallRule{allowSSHFirewallRule}},
+			expectedRules:      []string{"other_rule"},
+			expectedSuppressed: []string{"allow_ssh"},
+		},
+		{
+			name:             "firewall policy without matching rules",
+			firewallPolicyID: fakeFirewallPolicyID,
+			reader:           &fakeFirewallPolicyReader{},
+			expectedRules:    []string{"allow_ssh", "other_rule"},
+		},
+		{
+			name:             "firewall policy cannot be read",
+			firewallPolicyID: fakeFirewallPolicyID,
+			reader:           &fakeFirewallPolicyReader{err: errors.New("#: Internal Server Error: StatusCode=500")},
+			expectedError:    "failed to read the network rules of Azure Firewall policy " + fakeFirewallPolicyID + " of security group test-nsg",
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			spec := &NSGSpec{Name: "test-nsg", SecurityRules: infrav1.SecurityRules{sshRule, otherRule}, FirewallPolicyID: tc.firewallPolicyID}
+			s := &Service{}
+			if tc.reader != nil {
+				s.FirewallPolicies = tc.reader
+			}
+			err := s.resolveFirewallRules(context.TODO(), spec)
+			if tc.expectedError != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tc.expectedError)))
+				var reconcileErr azure.ReconcileError
+				g.Expect(errors.As(err, &reconcileErr)).To(BeTrue())
+				g.Expect(reconcileErr.IsTransient()).To(BeTrue())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(ruleNames(spec.desiredRules())).To(Equal(tc.expectedRules))
+			g.Expect(spec.FirewallEnforcedRules()).To(Equal(tc.expectedSuppressed))
+		})
+	}
+}
+
+func TestFirewallEnforcedRulesKeepPriorities(t *testing.T) {
+	g := NewWithT(t)
+	first := sshRuleWithPriority(0)
+	second := otherRule
+	second.Priority = 0
+	spec := &NSGSpec{Name: "test-nsg", SecurityRules: infrav1.SecurityRules{first, second}, FirewallRules: []FirewallRule{allowSSHFirewallRule}}
+
+	rules := spec.desiredRules()
+	g.Expect(rules).To(HaveLen(1))
+	g.Expect(to.String(rules[0].Name)).To(Equal("other_rule"))
+	g.Expect(to.Int32(rules[0].Priority)).To(Equal(int32(110)))
+}
+
+func TestNetworkRules(t *testing.T) {
+	g := NewWithT(t)
+	group := network.FirewallPolicyRuleCollectionGroup{
+		FirewallPolicyRuleCollectionGroupProperties: &network.FirewallPolicyRuleCollectionGroupProperties{
+			RuleCollections: &[]network.BasicFirewallPolicyRuleCollection{
+				network.FirewallPolicyFilterRuleCollection{
+					Name:   to.StringPtr("platform"),
+					Action: &network.FirewallPolicyFilterRuleCollectionAction{Type: network.FirewallPolicyFilterRuleCollectionActionTypeAllow},
+					Rules: &[]network.BasicFirewallPolicyRule{
+						network.Rule{
+							Name:                 to.StringPtr("allow-ssh"),
+							IPProtocols:          &[]network.FirewallPolicyRuleNetworkProtocol{network.FirewallPolicyRuleNetworkProtocolTCP},
+							SourceAddresses:      &[]string{"*"},
+							DestinationAddresses: &[]string{"*"},
+							DestinationPorts:     &[]string{"22"},
+						},
+						network.ApplicationRule{Name: to.StringPtr("allow-web")},
+					},
+				},
+				network.FirewallPolicyNatRuleCollection{Name: to.StringPtr("dnat")},
+			},
+		},
+	}
+	g.Expect(networkRules(group)).To(Equal([]FirewallRule{allowSSHFirewallRule}))
+	g.Expect(networkRules(network.FirewallPolicyRuleCollectionGroup{})).To(BeEmpty())
+}
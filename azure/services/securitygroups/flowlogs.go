@@ -0,0 +1,207 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package securitygroups
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-02-01/network"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/pkg/errors"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/converters"
+	"sigs.k8s.io/cluster-api-provider-azure/util/reconciler"
+	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
+)
+
+const (
+	flowLogServiceName = "flowlogs"
+	// defaultNetworkWatcherResourceGroup is the resource group of the network watchers created by Azure.
+	defaultNetworkWatcherResourceGroup = "NetworkWatcherRG"
+)
+
+// FlowLogSpec defines the specification for the flow log of a security group. Flow logs are resources of the network
+// watcher of the region, so they live in the resource group of the network watcher rather than the one of the cluster.
+type FlowLogSpec struct {
+	// Name is the name of the flow log. It defaults to <security group>-<resource group>-flowlog, since a network watcher
+	// is shared by the security groups of all the clusters in its region.
+	Name string
+	// NetworkWatcherName is the name of the network watcher. It defaults to the one Azure creates, NetworkWatcher_<location>.
+	NetworkWatcherName string
+	// NetworkWatcherResourceGroup is the resource group of the network watcher. It defaults to NetworkWatcherRG.
+	NetworkWatcherResourceGroup string
+	// Location defaults to the location of the security group.
+	Location string
+	// TargetResourceID is the ID of the security group flow logs are enabled for. It defaults to the ID of the security group.
+	TargetResourceID string
+	// StorageAccountID is the ID of the storage account the flow log records are written to.
+	StorageAccountID string
+	// RetentionDays is the number of days the flow log records are kept for. Records are kept forever when zero.
+	RetentionDays int32
+	ClusterName   string
+	// AdditionalTags are added to the flow log when it is created.
+	AdditionalTags infrav1.Tags
+}
+
+// ResourceName returns the name of the flow log.
+func (s *FlowLogSpec) ResourceName() string {
+	return s.Name
+}
+
+// ResourceGroupName returns the name of the resource group of the network watcher.
+func (s *FlowLogSpec) ResourceGroupName() string {
+	return s.NetworkWatcherResourceGroup
+}
+
+// OwnerResourceName returns the name of the network watcher.
+func (s *FlowLogSpec) OwnerResourceName() string {
+	return s.NetworkWatcherName
+}
+
+// Parameters returns the parameters for the flow log.
+func (s *FlowLogSpec) Parameters(existing interface{}) (interface{}, error) {
+	var tags map[string]*string
+	if existing != nil {
+		existingFlowLog, ok := existing.(network.FlowLog)
+		if !ok {
+			return nil, errors.Errorf("%T is not a network.FlowLog", existing)
+		}
+		if s.upToDate(existingFlowLog) {
+			return nil, nil
+		}
+		tags = existingFlowLog.Tags
+	} else {
+		tags = converters.TagsToMap(infrav1.Build(infrav1.BuildParams{
+			ClusterName: s.ClusterName,
+			Lifecycle:   infrav1.ResourceLifecycleOwned,
+			Name:        to.StringPtr(s.Name),
+			Additional:  s.AdditionalTags,
+		}))
+	}
+
+	return network.FlowLog{
+		Tags:     tags,
+		Location: to.StringPtr(s.Location),
+		FlowLogPropertiesFormat: &network.FlowLogPropertiesFormat{
+			TargetResourceID: to.StringPtr(s.TargetResourceID),
+			StorageID:        to.StringPtr(s.StorageAccountID),
+			Enabled:          to.BoolPtr(true),
+			RetentionPolicy: &network.RetentionPolicyParameters{
+				Days:    to.Int32Ptr(s.RetentionDays),
+				Enabled: to.BoolPtr(s.RetentionDays > 0),
+			},
+		},
+	}, nil
+}
+
+// upToDate returns true if the existing flow log is enabled with the target, storage account and retention of the spec.
+func (s *FlowLogSpec) upToDate(existing network.FlowLog) bool {
+	props := existing.FlowLogPropertiesFormat
+	if props == nil || !to.Bool(props.Enabled) {
+		return false
+	}
+	if !strings.EqualFold(to.String(props.TargetResourceID), s.TargetResourceID) || !strings.EqualFold(to.String(props.StorageID), s.StorageAccountID) {
+		return false
+	}
+	var days int32
+	var retention bool
+	if props.RetentionPolicy != nil {
+		days = to.Int32(props.RetentionPolicy.Days)
+		retention = to.Bool(props.RetentionPolicy.Enabled)
+	}
+	return retention == (s.RetentionDays > 0) && (!retention || days == s.RetentionDays)
+}
+
+// FlowLogDeleter deletes the flow logs of security groups. Flow logs are not deleted with the resource group of the
+// cluster, so they must be deleted before it.
+type FlowLogDeleter interface {
+	DeleteFlowLogs(ctx context.Context) error
+}
+
+// flowLogSpec returns the spec of the flow log of a security group with its defaults filled in, or nil if flow logs are
+// not enabled for the security group.
+func (s *Service) flowLogSpec(spec azure.ResourceSpecGetter) *FlowLogSpec {
+	nsgSpec, ok := spec.(*NSGSpec)
+	if !ok || nsgSpec.FlowLog == nil {
+		return nil
+	}
+	flowLog := *nsgSpec.FlowLog
+	if flowLog.Name == "" {
+		flowLog.Name = fmt.Sprintf("%s-%s-flowlog", nsgSpec.Name, nsgSpec.ResourceGroup)
+	}
+	if flowLog.Location == "" {
+		flowLog.Location = nsgSpec.Location
+	}
+	if flowLog.NetworkWatcherName == "" {
+		flowLog.NetworkWatcherName = fmt.Sprintf("NetworkWatcher_%s", flowLog.Location)
+	}
+	if flowLog.NetworkWatcherResourceGroup == "" {
+		flowLog.NetworkWatcherResourceGroup = defaultNetworkWatcherResourceGroup
+	}
+	if flowLog.TargetResourceID == "" {
+		flowLog.TargetResourceID = azure.SecurityGroupID(s.Scope.SubscriptionID(), nsgSpec.ResourceGroup, nsgSpec.Name)
+	}
+	if flowLog.ClusterName == "" {
+		flowLog.ClusterName = nsgSpec.ClusterName
+	}
+	if flowLog.AdditionalTags == nil {
+		flowLog.AdditionalTags = nsgSpec.AdditionalTags
+	}
+	return &flowLog
+}
+
+// reconcileFlowLog creates or updates the flow log of a reconciled security group, if flow logs are enabled for it.
+func (s *Service) reconcileFlowLog(ctx context.Context, nsgSpec azure.ResourceSpecGetter) error {
+	flowLog := s.flowLogSpec(nsgSpec)
+	if flowLog == nil || s.FlowLogReconciler == nil {
+		return nil
+	}
+	_, _, err := s.FlowLogReconciler.CreateResource(ctx, flowLog, flowLogServiceName)
+	return err
+}
+
+// deleteFlowLog deletes the flow log of a security group, if flow logs are enabled for it.
+func (s *Service) deleteFlowLog(ctx context.Context, nsgSpec azure.ResourceSpecGetter) error {
+	flowLog := s.flowLogSpec(nsgSpec)
+	if flowLog == nil || s.FlowLogReconciler == nil {
+		return nil
+	}
+	_, err := s.FlowLogReconciler.DeleteResource(ctx, flowLog, flowLogServiceName)
+	return err
+}
+
+// DeleteFlowLogs deletes the flow logs of the security groups, leaving the security groups intact.
+func (s *Service) DeleteFlowLogs(ctx context.Context) error {
+	ctx, log, done := tele.StartSpanWithLogger(ctx, "securitygroups.Service.DeleteFlowLogs")
+	defer done()
+
+	ctx, cancel := context.WithTimeout(ctx, reconciler.ServiceReconcileTimeout(s.Reconciler))
+	defer cancel()
+
+	// Only delete the flow logs if the lifecycle of the NSGs is managed by this controller.
+	if !s.Scope.IsVnetManaged() {
+		log.V(4).Info("Skipping flow logs delete in custom VNet mode")
+		return nil
+	}
+
+	return s.forEachSpec(s.nsgSpecs(ctx), func(nsgSpec azure.ResourceSpecGetter) error {
+		return s.deleteFlowLog(ctx, nsgSpec)
+	})
+}
@@ -0,0 +1,239 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package securitygroups
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-02-01/network"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/async/mock_async"
+	gomockinternal "sigs.k8s.io/cluster-api-provider-azure/internal/test/matchers/gomock"
+)
+
+var (
+	fakeNSGWithFlowLog = NSGSpec{
+		Name:          "test-nsg",
+		Location:      "test-location",
+		ResourceGroup: "test-group",
+		ClusterName:   "test-cluster",
+		FlowLog: &FlowLogSpec{
+			StorageAccountID: "/subscriptions/123/resourceGroups/audit/providers/Microsoft.Storage/storageAccounts/flowlogs",
+			RetentionDays:    30,
+		},
+	}
+	fakeFlowLog = FlowLogSpec{
+		Name:                        "test-nsg-test-group-flowlog",
+		NetworkWatcherName:          "NetworkWatcher_test-location",
+		NetworkWatcherResourceGroup: "NetworkWatcherRG",
+		Location:                    "test-location",
+		TargetResourceID:            "/subscriptions/123/resourceGroups/test-group/providers/Microsoft.Network/networkSecurityGroups/test-nsg",
+		StorageAccountID:            "/subscriptions/123/resourceGroups/audit/providers/Microsoft.Storage/storageAccounts/flowlogs",
+		RetentionDays:               30,
+		ClusterName:                 "test-cluster",
+	}
+)
+
+func TestFlowLogParameters(t *testing.T) {
+	upToDate := network.FlowLog{
+		Tags: map[string]*string{"foo": to.StringPtr("bar")},
+		FlowLogPropertiesFormat: &network.FlowLogPropertiesFormat{
+			TargetResourceID: to.StringPtr(fakeFlowLog.TargetResourceID),
+			StorageID:        to.StringPtr(fakeFlowLog.StorageAccountID),
+			Enabled:          to.BoolPtr(true),
+			RetentionPolicy: &network.RetentionPolicyParameters{
+				Days:    to.Int32Ptr(30),
+				Enabled: to.BoolPtr(true),
+			},
+		},
+	}
+	disabled := upToDate
+	disabled.FlowLogPropertiesFormat = &network.FlowLogPropertiesFormat{
+		TargetResourceID: to.StringPtr(fakeFlowLog.TargetResourceID),
+		StorageID:        to.StringPtr(fakeFlowLog.StorageAccountID),
+		Enabled:          to.BoolPtr(false),
+	}
+
+	testcases := []struct {
+		name     string
+		spec     FlowLogSpec
+		existing interface{}
+		expected interface{}
+	}{
+		{
+			name:     "flow log does not exist",
+			spec:     fakeFlowLog,
+			existing: nil,
+			expected: network.FlowLog{
+				Tags: map[string]*string{
+					"sigs.k8s.io_cluster-api-provider-azure_cluster_test-cluster": to.StringPtr("owned"),
+					"Name": to.StringPtr("test-nsg-test-group-flowlog"),
+				},
+				Location: to.StringPtr("test-location"),
+				FlowLogPropertiesFormat: &network.FlowLogPropertiesFormat{
+					TargetResourceID: to.StringPtr(fakeFlowLog.TargetResourceID),
+					StorageID:        to.StringPtr(fakeFlowLog.StorageAccountID),
+					Enabled:          to.BoolPtr(true),
+					RetentionPolicy: &network.RetentionPolicyParameters{
+						Days:    to.Int32Ptr(30),
+						Enabled: to.BoolPtr(true),
+					},
+				},
+			},
+		},
+		{
+			name:     "flow log is up to date",
+			spec:     fakeFlowLog,
+			existing: upToDate,
+			expected: nil,
+		},
+		{
+			name:     "flow log is disabled, should be enabled and keep its tags",
+			spec:     fakeFlowLog,
+			existing: disabled,
+			expected: network.FlowLog{
+				Tags:     map[string]*string{"foo": to.StringPtr("bar")},
+				Location: to.StringPtr("test-location"),
+				FlowLogPropertiesFormat: &network.FlowLogPropertiesFormat{
+					TargetResourceID: to.StringPtr(fakeFlowLog.TargetResourceID),
+					StorageID:        to.StringPtr(fakeFlowLog.StorageAccountID),
+					Enabled:          to.BoolPtr(true),
+					RetentionPolicy: &network.RetentionPolicyParameters{
+						Days:    to.Int32Ptr(30),
+						Enabled: to.BoolPtr(true),
+					},
+				},
+			},
+		},
+		{
+			name: "flow log retention is removed",
+			spec: func() FlowLogSpec {
+				spec := fakeFlowLog
+				spec.RetentionDays = 0
+				return spec
+			}(),
+			existing: upToDate,
+			expected: network.FlowLog{
+				Tags:     map[string]*string{"foo": to.StringPtr("bar")},
+				Location: to.StringPtr("test-location"),
+				FlowLogPropertiesFormat: &network.FlowLogPropertiesFormat{
+					TargetResourceID: to.StringPtr(fakeFlowLog.TargetResourceID),
+					StorageID:        to.StringPtr(fakeFlowLog.StorageAccountID),
+					Enabled:          to.BoolPtr(true),
+					RetentionPolicy: &network.RetentionPolicyParameters{
+						Days:    to.Int32Ptr(0),
+						Enabled: to.BoolPtr(false),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			result, err := tc.spec.Parameters(tc.existing)
+			g.Expect(err).NotTo(HaveOccurred())
+			if tc.expected != nil {
+				g.Expect(result).To(Equal(tc.expected))
+			} else {
+				g.Expect(result).To(BeNil())
+			}
+		})
+	}
+}
+
+func TestReconcileFlowLogs(t *testing.T) {
+	t.Run("flow log is reconciled after its security group", func(t *testing.T) {
+		g := NewWithT(t)
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+		scopeMock := newMockNSGScope(mockCtrl)
+		reconcilerMock := mock_async.NewMockReconciler(mockCtrl)
+		flowLogReconcilerMock := mock_async.NewMockReconciler(mockCtrl)
+
+		scopeMock.EXPECT().IsVnetManaged().Return(true)
+		scopeMock.EXPECT().NSGSpecs().Return([]azure.ResourceSpecGetter{&fakeNSGWithFlowLog})
+		scopeMock.EXPECT().SubscriptionID().Return("123")
+		gomock.InOrder(
+			reconcilerMock.EXPECT().CreateResource(gomockinternal.AContext(), &fakeNSGWithFlowLog, serviceName).Return(nil, false, nil),
+			flowLogReconcilerMock.EXPECT().CreateResource(gomockinternal.AContext(), &fakeFlowLog, flowLogServiceName).Return(nil, false, nil),
+		)
+		scopeMock.EXPECT().UpdatePutStatus(infrav1.SecurityGroupsReadyCondition, serviceName, nil)
+		scopeMock.EXPECT().SetSecurityGroupsProgress(infrav1.SecurityGroupsProgress{Total: 1, Ready: 1})
+
+		s := &Service{
+			Scope:             scopeMock,
+			Reconciler:        reconcilerMock,
+			FlowLogReconciler: flowLogReconcilerMock,
+		}
+		g.Expect(s.Reconcile(context.TODO())).To(Succeed())
+	})
+
+	t.Run("flow log is deleted before its security group", func(t *testing.T) {
+		g := NewWithT(t)
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+		scopeMock := newMockNSGScope(mockCtrl)
+		reconcilerMock := mock_async.NewMockReconciler(mockCtrl)
+		flowLogReconcilerMock := mock_async.NewMockReconciler(mockCtrl)
+
+		scopeMock.EXPECT().IsVnetManaged().Return(true)
+		scopeMock.EXPECT().NSGSpecs().Return([]azure.ResourceSpecGetter{&fakeNSGWithFlowLog})
+		scopeMock.EXPECT().SubscriptionID().Return("123")
+		gomock.InOrder(
+			flowLogReconcilerMock.EXPECT().DeleteResource(gomockinternal.AContext(), &fakeFlowLog, flowLogServiceName).Return(false, nil),
+			reconcilerMock.EXPECT().DeleteResource(gomockinternal.AContext(), &fakeNSGWithFlowLog, serviceName).Return(false, nil),
+		)
+		scopeMock.EXPECT().UpdateDeleteStatus(infrav1.SecurityGroupsReadyCondition, serviceName, nil)
+
+		s := &Service{
+			Scope:             scopeMock,
+			Reconciler:        reconcilerMock,
+			FlowLogReconciler: flowLogReconcilerMock,
+		}
+		g.Expect(s.Delete(context.TODO())).To(Succeed())
+	})
+
+	t.Run("flow logs are deleted without their security groups", func(t *testing.T) {
+		g := NewWithT(t)
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+		scopeMock := newMockNSGScope(mockCtrl)
+		reconcilerMock := mock_async.NewMockReconciler(mockCtrl)
+		flowLogReconcilerMock := mock_async.NewMockReconciler(mockCtrl)
+
+		scopeMock.EXPECT().IsVnetManaged().Return(true)
+		scopeMock.EXPECT().NSGSpecs().Return([]azure.ResourceSpecGetter{&fakeNSGWithFlowLog, &fakeNSG2})
+		scopeMock.EXPECT().SubscriptionID().Return("123")
+		flowLogReconcilerMock.EXPECT().DeleteResource(gomockinternal.AContext(), &fakeFlowLog, flowLogServiceName).Return(false, nil)
+
+		s := &Service{
+			Scope:             scopeMock,
+			Reconciler:        reconcilerMock,
+			FlowLogReconciler: flowLogReconcilerMock,
+		}
+		g.Expect(s.DeleteFlowLogs(context.TODO())).To(Succeed())
+	})
+}
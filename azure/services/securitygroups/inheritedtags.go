@@ -0,0 +1,78 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package securitygroups
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/util/reconciler"
+	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
+)
+
+// ResourceGroupTagsReader reads the tags of resource groups.
+type ResourceGroupTagsReader interface {
+	// ResourceGroupTags returns the tags of the resource group with the given name.
+	ResourceGroupTags(ctx context.Context, resourceGroup string) (infrav1.Tags, error)
+}
+
+// resolveInheritedTags reads the tags of the resource group of the security group into the InheritedTags of the spec,
+// keeping only the tags whose key is in InheritedTagKeys. They are read on every reconcile, so that the security group
+// is updated when the tags of its resource group change; as changes to the resource group do not trigger a reconcile,
+// they only arrive on the next resync of the cluster. A resource group that does not exist, e.g. while it is being
+// created, or that has none of the tags, is not an error: the security group simply inherits no tags. A resource group
+// that cannot be read returns a transient error, so that the inherited tags are not removed from the security group.
+func (s *Service) resolveInheritedTags(ctx context.Context, spec azure.ResourceSpecGetter) error {
+	ctx, log, done := tele.StartSpanWithLogger(ctx, "securitygroups.Service.resolveInheritedTags")
+	defer done()
+
+	nsgSpec, ok := spec.(*NSGSpec)
+	if !ok || len(nsgSpec.InheritedTagKeys) == 0 {
+		return nil
+	}
+	if s.ResourceGroupTags == nil {
+		return errors.Errorf("cannot read the tags of resource group %s of security group %s, no resource group tags reader is configured", nsgSpec.ResourceGroup, nsgSpec.Name)
+	}
+	groupTags, err := s.ResourceGroupTags.ResourceGroupTags(ctx, nsgSpec.ResourceGroup)
+	if azure.ResourceNotFound(err) {
+		log.V(2).Info("resource group of the security group not found, no tags are inherited", "securityGroup", nsgSpec.Name, "resourceGroup", nsgSpec.ResourceGroup)
+		nsgSpec.InheritedTags = nil
+		return nil
+	} else if err != nil {
+		return azure.WithTransientError(errors.Wrapf(err, "failed to read the tags of resource group %s of security group %s", nsgSpec.ResourceGroup, nsgSpec.Name), reconciler.DefaultReconcilerRequeue)
+	}
+	nsgSpec.InheritedTags = inheritedTags(groupTags, nsgSpec.InheritedTagKeys)
+	return nil
+}
+
+// inheritedTags returns the tags of the resource group whose key is one of the given keys.
+func inheritedTags(groupTags infrav1.Tags, keys []string) infrav1.Tags {
+	var tags infrav1.Tags
+	for _, key := range keys {
+		value, ok := groupTags[key]
+		if !ok {
+			continue
+		}
+		if tags == nil {
+			tags = make(infrav1.Tags, len(keys))
+		}
+		tags[key] = value
+	}
+	return tags
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package securitygroups
+
+import (
+	"context"
+
+	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2019-05-01/resources"
+	"github.com/Azure/go-autorest/autorest"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/converters"
+	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
+)
+
+// resourceGroupTagsClient contains the Azure go-sdk Client for resource groups.
+type resourceGroupTagsClient struct {
+	groups resources.GroupsClient
+}
+
+// newResourceGroupTagsClient creates a new resource group tags client from subscription ID.
+func newResourceGroupTagsClient(auth azure.Authorizer) *resourceGroupTagsClient {
+	c := newGroupsClient(auth.SubscriptionID(), auth.BaseURI(), auth.Authorizer())
+	return &resourceGroupTagsClient{c}
+}
+
+// newGroupsClient creates a new groups client from subscription ID.
+func newGroupsClient(subscriptionID string, baseURI string, authorizer autorest.Authorizer) resources.GroupsClient {
+	groupsClient := resources.NewGroupsClientWithBaseURI(baseURI, subscriptionID)
+	azure.SetAutoRestClientDefaults(&groupsClient.Client, authorizer)
+	return groupsClient
+}
+
+// ResourceGroupTags returns the tags of the resource group with the given name.
+func (ac *resourceGroupTagsClient) ResourceGroupTags(ctx context.Context, resourceGroup string) (infrav1.Tags, error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "securitygroups.resourceGroupTagsClient.ResourceGroupTags")
+	defer done()
+
+	group, err := ac.groups.Get(ctx, resourceGroup)
+	if err != nil {
+		return nil, err
+	}
+	return converters.MapToTags(group.Tags), nil
+}
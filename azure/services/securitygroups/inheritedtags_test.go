@@ -0,0 +1,203 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package securitygroups
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-02-01/network"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/to"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+)
+
+// fakeResourceGroupTagsReader returns the same tags for any resource group.
+type fakeResourceGroupTagsReader struct {
+	tags infrav1.Tags
+	err  error
+}
+
+func (f *fakeResourceGroupTagsReader) ResourceGroupTags(_ context.Context, _ string) (infrav1.Tags, error) {
+	return f.tags, f.err
+}
+
+func TestResolveInheritedTags(t *testing.T) {
+	testcases := []struct {
+		name          string
+		keys          []string
+		reader        *fakeResourceGroupTagsReader
+		expectedTags  infrav1.Tags
+		expectedError string
+	}{
+		{
+			name:         "no inherited tags",
+			expectedTags: nil,
+		},
+		{
+			name:         "only the tags of the allowlist are inherited",
+			keys:         []string{"costCenter", "owner"},
+			reader:       &fakeResourceGroupTagsReader{tags: infrav1.Tags{"costCenter": "1234", "owner": "network", "env": "prod"}},
+			expectedTags: infrav1.Tags{"costCenter": "1234", "owner": "network"},
+		},
+		{
+			name:         "resource group without the tags",
+			keys:         []string{"costCenter"},
+			reader:       &fakeResourceGroupTagsReader{tags: infrav1.Tags{"env": "prod"}},
+			expectedTags: nil,
+		},
+		{
+			name:         "resource group without tags",
+			keys:         []string{"costCenter"},
+			reader:       &fakeResourceGroupTagsReader{},
+			expectedTags: nil,
+		},
+		{
+			name:         "resource group does not exist",
+			keys:         []string{"costCenter"},
+			reader:       &fakeResourceGroupTagsReader{err: autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: http.StatusNotFound}, "Not Found")},
+			expectedTags: nil,
+		},
+		{
+			name:          "resource group cannot be read",
+			keys:          []string{"costCenter"},
+			reader:        &fakeResourceGroupTagsReader{err: errors.New("#: Internal Server Error: StatusCode=500")},
+			expectedError: "failed to read the tags of resource group test-group of security group test-nsg",
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			spec := &NSGSpec{Name: "test-nsg", ResourceGroup: "test-group", InheritedTagKeys: tc.keys}
+			s := &Service{}
+			if tc.reader != nil {
+				s.ResourceGroupTags = tc.reader
+			}
+			err := s.resolveInheritedTags(context.TODO(), spec)
+			if tc.expectedError != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tc.expectedError)))
+				var reconcileErr azure.ReconcileError
+				g.Expect(errors.As(err, &reconcileErr)).To(BeTrue())
+				g.Expect(reconcileErr.IsTransient()).To(BeTrue())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(spec.InheritedTags).To(Equal(tc.expectedTags))
+		})
+	}
+}
+
+func TestParametersInheritedTags(t *testing.T) {
+	existingNSG := network.SecurityGroup{
+		Name:     to.StringPtr("test-nsg"),
+		Location: to.StringPtr("test-location"),
+		Tags: map[string]*string{
+			"sigs.k8s.io_cluster-api-provider-azure_cluster_my-cluster": to.StringPtr("owned"),
+			"costCenter": to.StringPtr("1234"),
+		},
+		SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{SecurityRules: &[]network.SecurityRule{}},
+	}
+	testcases := []struct {
+		name          string
+		existing      interface{}
+		inheritedTags infrav1.Tags
+		additional    infrav1.Tags
+		expectedTags  map[string]*string
+	}{
+		{
+			name:          "new security group inherits the tags",
+			existing:      nil,
+			inheritedTags: infrav1.Tags{"costCenter": "1234"},
+			expectedTags: map[string]*string{
+				"sigs.k8s.io_cluster-api-provider-azure_cluster_my-cluster": to.StringPtr("owned"),
+				"Name":       to.StringPtr("test-nsg"),
+				"costCenter": to.StringPtr("1234"),
+			},
+		},
+		{
+			name:          "additional tags take precedence over the inherited tags",
+			existing:      nil,
+			inheritedTags: infrav1.Tags{"costCenter": "1234"},
+			additional:    infrav1.Tags{"costCenter": "5678"},
+			expectedTags: map[string]*string{
+				"sigs.k8s.io_cluster-api-provider-azure_cluster_my-cluster": to.StringPtr("owned"),
+				"Name":       to.StringPtr("test-nsg"),
+				"costCenter": to.StringPtr("5678"),
+			},
+		},
+		{
+			name:          "security group is not updated while the tags of the resource group are unchanged",
+			existing:      existingNSG,
+			inheritedTags: infrav1.Tags{"costCenter": "1234"},
+			expectedTags:  nil,
+		},
+		{
+			name:          "security group is updated when a tag of the resource group changes",
+			existing:      existingNSG,
+			inheritedTags: infrav1.Tags{"costCenter": "5678"},
+			expectedTags: map[string]*string{
+				"sigs.k8s.io_cluster-api-provider-azure_cluster_my-cluster": to.StringPtr("owned"),
+				"costCenter": to.StringPtr("5678"),
+			},
+		},
+		{
+			name:          "inherited tag is removed once the resource group no longer has it",
+			existing:      existingNSG,
+			inheritedTags: nil,
+			expectedTags: map[string]*string{
+				"sigs.k8s.io_cluster-api-provider-azure_cluster_my-cluster": to.StringPtr("owned"),
+			},
+		},
+		{
+			name:          "inherited tag that is also an additional tag is kept once the resource group no longer has it",
+			existing:      existingNSG,
+			inheritedTags: nil,
+			additional:    infrav1.Tags{"costCenter": "1234"},
+			expectedTags:  nil,
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			spec := &NSGSpec{
+				Name:             "test-nsg",
+				Location:         "test-location",
+				ClusterName:      "my-cluster",
+				AdditionalTags:   tc.additional,
+				InheritedTagKeys: []string{"costCenter", "owner"},
+				InheritedTags:    tc.inheritedTags,
+			}
+			result, err := spec.Parameters(tc.existing)
+			g.Expect(err).NotTo(HaveOccurred())
+			if tc.expectedTags == nil {
+				g.Expect(result).To(BeNil())
+				return
+			}
+			nsg, ok := result.(network.SecurityGroup)
+			g.Expect(ok).To(BeTrue())
+			g.Expect(nsg.Tags).To(Equal(tc.expectedTags))
+		})
+	}
+}
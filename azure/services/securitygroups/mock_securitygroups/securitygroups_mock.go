@@ -21,6 +21,7 @@ limitations under the License.
 package mock_securitygroups
 
 import (
+	context "context"
 	reflect "reflect"
 
 	autorest "github.com/Azure/go-autorest/autorest"
@@ -135,6 +136,18 @@ func (mr *MockNSGScopeMockRecorder) DeleteLongRunningOperationState(arg0, arg1 i
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteLongRunningOperationState", reflect.TypeOf((*MockNSGScope)(nil).DeleteLongRunningOperationState), arg0, arg1)
 }
 
+// DeleteSecurityGroupPendingChanges mocks base method.
+func (m *MockNSGScope) DeleteSecurityGroupPendingChanges(name string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "DeleteSecurityGroupPendingChanges", name)
+}
+
+// DeleteSecurityGroupPendingChanges indicates an expected call of DeleteSecurityGroupPendingChanges.
+func (mr *MockNSGScopeMockRecorder) DeleteSecurityGroupPendingChanges(name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSecurityGroupPendingChanges", reflect.TypeOf((*MockNSGScope)(nil).DeleteSecurityGroupPendingChanges), name)
+}
+
 // GetLongRunningOperationState mocks base method.
 func (m *MockNSGScope) GetLongRunningOperationState(arg0, arg1 string) *v1beta1.Future {
 	m.ctrl.T.Helper()
@@ -149,6 +162,20 @@ func (mr *MockNSGScopeMockRecorder) GetLongRunningOperationState(arg0, arg1 inte
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLongRunningOperationState", reflect.TypeOf((*MockNSGScope)(nil).GetLongRunningOperationState), arg0, arg1)
 }
 
+// GetLongRunningOperationStates mocks base method.
+func (m *MockNSGScope) GetLongRunningOperationStates() []*v1beta1.Future {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLongRunningOperationStates")
+	ret0, _ := ret[0].([]*v1beta1.Future)
+	return ret0
+}
+
+// GetLongRunningOperationStates indicates an expected call of GetLongRunningOperationStates.
+func (mr *MockNSGScopeMockRecorder) GetLongRunningOperationStates() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLongRunningOperationStates", reflect.TypeOf((*MockNSGScope)(nil).GetLongRunningOperationStates))
+}
+
 // HashKey mocks base method.
 func (m *MockNSGScope) HashKey() string {
 	m.ctrl.T.Helper()
@@ -163,6 +190,20 @@ func (mr *MockNSGScopeMockRecorder) HashKey() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HashKey", reflect.TypeOf((*MockNSGScope)(nil).HashKey))
 }
 
+// IsServicePaused mocks base method.
+func (m *MockNSGScope) IsServicePaused(arg0 string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsServicePaused", arg0)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsServicePaused indicates an expected call of IsServicePaused.
+func (mr *MockNSGScopeMockRecorder) IsServicePaused(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsServicePaused", reflect.TypeOf((*MockNSGScope)(nil).IsServicePaused), arg0)
+}
+
 // IsVnetManaged mocks base method.
 func (m *MockNSGScope) IsVnetManaged() bool {
 	m.ctrl.T.Helper()
@@ -191,6 +232,21 @@ func (mr *MockNSGScopeMockRecorder) NSGSpecs() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NSGSpecs", reflect.TypeOf((*MockNSGScope)(nil).NSGSpecs))
 }
 
+// SecurityRuleSources mocks base method.
+func (m *MockNSGScope) SecurityRuleSources(ctx context.Context, ref v1beta1.SecurityRuleSourcesReference) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SecurityRuleSources", ctx, ref)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SecurityRuleSources indicates an expected call of SecurityRuleSources.
+func (mr *MockNSGScopeMockRecorder) SecurityRuleSources(ctx, ref interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SecurityRuleSources", reflect.TypeOf((*MockNSGScope)(nil).SecurityRuleSources), ctx, ref)
+}
+
 // SetLongRunningOperationState mocks base method.
 func (m *MockNSGScope) SetLongRunningOperationState(arg0 *v1beta1.Future) {
 	m.ctrl.T.Helper()
@@ -203,6 +259,42 @@ func (mr *MockNSGScopeMockRecorder) SetLongRunningOperationState(arg0 interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLongRunningOperationState", reflect.TypeOf((*MockNSGScope)(nil).SetLongRunningOperationState), arg0)
 }
 
+// SetSecurityGroupPendingChanges mocks base method.
+func (m *MockNSGScope) SetSecurityGroupPendingChanges(changes v1beta1.SecurityGroupPendingChanges) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetSecurityGroupPendingChanges", changes)
+}
+
+// SetSecurityGroupPendingChanges indicates an expected call of SetSecurityGroupPendingChanges.
+func (mr *MockNSGScopeMockRecorder) SetSecurityGroupPendingChanges(changes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSecurityGroupPendingChanges", reflect.TypeOf((*MockNSGScope)(nil).SetSecurityGroupPendingChanges), changes)
+}
+
+// SetSecurityGroupStatus mocks base method.
+func (m *MockNSGScope) SetSecurityGroupStatus(status v1beta1.SecurityGroupStatus) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetSecurityGroupStatus", status)
+}
+
+// SetSecurityGroupStatus indicates an expected call of SetSecurityGroupStatus.
+func (mr *MockNSGScopeMockRecorder) SetSecurityGroupStatus(status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSecurityGroupStatus", reflect.TypeOf((*MockNSGScope)(nil).SetSecurityGroupStatus), status)
+}
+
+// SetSecurityGroupsProgress mocks base method.
+func (m *MockNSGScope) SetSecurityGroupsProgress(progress v1beta1.SecurityGroupsProgress) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetSecurityGroupsProgress", progress)
+}
+
+// SetSecurityGroupsProgress indicates an expected call of SetSecurityGroupsProgress.
+func (mr *MockNSGScopeMockRecorder) SetSecurityGroupsProgress(progress interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSecurityGroupsProgress", reflect.TypeOf((*MockNSGScope)(nil).SetSecurityGroupsProgress), progress)
+}
+
 // SubscriptionID mocks base method.
 func (m *MockNSGScope) SubscriptionID() string {
 	m.ctrl.T.Helper()
@@ -0,0 +1,170 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package securitygroups
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-02-01/network"
+	"github.com/Azure/go-autorest/autorest/to"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
+)
+
+// maxTagValueLength is the maximum length of a tag value accepted by Azure.
+const maxTagValueLength = 256
+
+// ownedRuleNames returns the names, in lower case, of the rules recorded as owned by the cluster in the tags of the
+// existing security group. It returns nil unless the spec is in shared ownership mode.
+func (s *NSGSpec) ownedRuleNames(existing network.SecurityGroup) map[string]bool {
+	if !s.SharedOwnership {
+		return nil
+	}
+	return ownedRuleNamesFromTags(existing.Tags, s.ClusterName)
+}
+
+// ownedRuleNamesFromTags returns the names, in lower case, of the rules recorded as owned by the cluster in the tags.
+func ownedRuleNamesFromTags(tags map[string]*string, clusterName string) map[string]bool {
+	owned := make(map[string]bool)
+	for key, value := range tags {
+		if !isOwnedRulesTagKey(key, clusterName) {
+			continue
+		}
+		for _, name := range strings.Split(to.String(value), ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				owned[strings.ToLower(name)] = true
+			}
+		}
+	}
+	return owned
+}
+
+// ownedRulesAfter returns the names of the rules owned by the cluster once the changes are applied to the existing
+// security group: the owned rules that are kept and the rules of the spec that are not owned by another writer.
+func (s *NSGSpec) ownedRulesAfter(existing network.SecurityGroup, changes RuleChanges) []string {
+	owned := s.ownedRuleNames(existing)
+	var names []string
+	if existing.SecurityGroupPropertiesFormat != nil && existing.SecurityRules != nil {
+		for _, rule := range *existing.SecurityRules {
+			name := to.String(rule.Name)
+			if ownsRule(rule, owned) && !containsFold(changes.Deletions, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	for _, name := range ruleNames(s.desiredRules()) {
+		if !containsFold(changes.Conflicts, name) && !containsFold(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// ownsRule returns true if the existing rule is owned by CAPZ, i.e. it is marked as managed by CAPZ or its name is
+// one of the owned rule names recorded in the tags of the security group.
+func ownsRule(rule network.SecurityRule, owned map[string]bool) bool {
+	return isManagedRule(rule) || owned[strings.ToLower(to.String(rule.Name))]
+}
+
+// ruleOfOtherWriter returns true if one of the existing rules has the given name and is not owned by CAPZ.
+func ruleOfOtherWriter(rules []network.SecurityRule, name string, owned map[string]bool) bool {
+	for _, rule := range rules {
+		if strings.EqualFold(to.String(rule.Name), name) && !ownsRule(rule, owned) {
+			return true
+		}
+	}
+	return false
+}
+
+// isOwnedRulesTagKey returns true if the tag key is one of the owned rules tag keys of the cluster.
+func isOwnedRulesTagKey(key, clusterName string) bool {
+	base := infrav1.OwnedSecurityRulesTagKey(clusterName)
+	return key == base || strings.HasPrefix(key, base+"_")
+}
+
+// withOwnedRulesTags returns a copy of the tags recording the names of the rules owned by the cluster, and whether the
+// recorded names changed. The names are sorted so that the tags only change with the set of owned rules, and split
+// over multiple tags when they do not fit in a single tag value.
+func withOwnedRulesTags(tags map[string]*string, clusterName string, names []string) (map[string]*string, bool) {
+	desired := make(map[string]string)
+	base := infrav1.OwnedSecurityRulesTagKey(clusterName)
+	var value string
+	for _, name := range sortedLower(names) {
+		if value != "" && len(value)+1+len(name) > maxTagValueLength {
+			desired[ownedRulesTagKey(base, len(desired))] = value
+			value = ""
+		}
+		if value != "" {
+			value += ","
+		}
+		value += name
+	}
+	if value != "" {
+		desired[ownedRulesTagKey(base, len(desired))] = value
+	}
+
+	updated := make(map[string]*string, len(tags)+len(desired))
+	changed := false
+	for key, value := range tags {
+		if !isOwnedRulesTagKey(key, clusterName) {
+			updated[key] = value
+			continue
+		}
+		if desiredValue, ok := desired[key]; !ok || desiredValue != to.String(value) {
+			changed = true
+		}
+	}
+	for key, value := range desired {
+		if existing, ok := tags[key]; !ok || to.String(existing) != value {
+			changed = true
+		}
+		updated[key] = to.StringPtr(value)
+	}
+	return updated, changed
+}
+
+// ownedRulesTagKey returns the key of the owned rules tag with the given index.
+func ownedRulesTagKey(base string, index int) string {
+	if index == 0 {
+		return base
+	}
+	return fmt.Sprintf("%s_%d", base, index)
+}
+
+// warnConflicts logs the rules of the spec that are skipped in shared ownership mode because a rule of another writer
+// of the security group has the same name.
+func (s *Service) warnConflicts(ctx context.Context, spec azure.ResourceSpecGetter, result interface{}) {
+	_, log, done := tele.StartSpanWithLogger(ctx, "securitygroups.Service.warnConflicts")
+	defer done()
+
+	nsgSpec, ok := spec.(*NSGSpec)
+	if !ok || !nsgSpec.SharedOwnership {
+		return
+	}
+	nsg, ok := result.(network.SecurityGroup)
+	if !ok {
+		return
+	}
+	if conflicts := nsgSpec.RuleChanges(nsg).Conflicts; len(conflicts) > 0 {
+		sort.Strings(conflicts)
+		log.Info("WARNING: security rules are owned by another writer of the security group and are not applied", "securityGroup", nsgSpec.Name, "rules", conflicts)
+	}
+}
@@ -0,0 +1,204 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package securitygroups
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-02-01/network"
+	"github.com/Azure/go-autorest/autorest/to"
+	. "github.com/onsi/gomega"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/converters"
+)
+
+// sharedNSG returns a security group shared with another writer: allow_ssh is marked as managed by CAPZ, custom_rule is
+// owned by CAPZ according to the tags but was rewritten without its marker, and other_rule belongs to the other writer.
+func sharedNSG() network.SecurityGroup {
+	return network.SecurityGroup{
+		Name: to.StringPtr("test-nsg"),
+		Etag: to.StringPtr("fake-etag"),
+		Tags: map[string]*string{
+			"sigs.k8s.io_cluster-api-provider-azure_cluster_test-cluster":              to.StringPtr("owned"),
+			"sigs.k8s.io_cluster-api-provider-azure_owned-security-rules_test-cluster": to.StringPtr("allow_ssh,custom_rule"),
+		},
+		SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
+			SecurityRules: &[]network.SecurityRule{
+				sdkRule(sshRule),
+				converters.SecurityRuleToSDK(customRule),
+				converters.SecurityRuleToSDK(otherRule),
+			},
+		},
+	}
+}
+
+func otherRuleWithPriority(priority int32) infrav1.SecurityRule {
+	rule := otherRule
+	rule.Priority = priority
+	return rule
+}
+
+func customRuleWithPriority(priority int32) infrav1.SecurityRule {
+	rule := customRule
+	rule.Priority = priority
+	return rule
+}
+
+func TestSharedOwnershipRuleChanges(t *testing.T) {
+	testcases := []struct {
+		name     string
+		spec     *NSGSpec
+		expected RuleChanges
+	}{
+		{
+			name:     "rules of other writers matching the spec are managed without shared ownership",
+			spec:     &NSGSpec{ClusterName: "test-cluster", SecurityRules: infrav1.SecurityRules{sshRule, otherRule}, PruneRules: true},
+			expected: RuleChanges{},
+		},
+		{
+			name: "rules of other writers are taken over without shared ownership",
+			spec: &NSGSpec{ClusterName: "test-cluster", SecurityRules: infrav1.SecurityRules{sshRule, otherRuleWithPriority(600)}, PruneRules: true},
+			expected: RuleChanges{
+				Additions: []network.SecurityRule{sdkRule(otherRuleWithPriority(600))},
+			},
+		},
+		{
+			name: "rules of other writers are left untouched and rules owned according to the tags are pruned",
+			spec: &NSGSpec{ClusterName: "test-cluster", SecurityRules: infrav1.SecurityRules{sshRule, otherRule}, PruneRules: true, SharedOwnership: true},
+			expected: RuleChanges{
+				Deletions: []string{"custom_rule"},
+				Conflicts: []string{"other_rule"},
+			},
+		},
+		{
+			name:     "rules owned according to the tags that match the spec are up to date",
+			spec:     &NSGSpec{ClusterName: "test-cluster", SecurityRules: infrav1.SecurityRules{sshRule, customRule}, SharedOwnership: true},
+			expected: RuleChanges{},
+		},
+		{
+			name: "rules owned by another cluster are not owned",
+			spec: &NSGSpec{ClusterName: "other-cluster", SecurityRules: infrav1.SecurityRules{sshRule, customRule}, SharedOwnership: true},
+			expected: RuleChanges{
+				Conflicts: []string{"custom_rule"},
+			},
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			g.Expect(tc.spec.RuleChanges(sharedNSG())).To(Equal(tc.expected))
+		})
+	}
+}
+
+func TestSharedOwnershipParameters(t *testing.T) {
+	testcases := []struct {
+		name     string
+		spec     *NSGSpec
+		existing func(nsg *network.SecurityGroup)
+		expect   func(g *WithT, result interface{})
+	}{
+		{
+			name: "rules of other writers are kept and owned rules are recorded",
+			spec: &NSGSpec{Name: "test-nsg", ClusterName: "test-cluster", SecurityRules: infrav1.SecurityRules{sshRule, customRuleWithPriority(600), otherRule}, SharedOwnership: true},
+			expect: func(g *WithT, result interface{}) {
+				g.Expect(result).To(BeAssignableToTypeOf(network.SecurityGroup{}))
+				nsg := result.(network.SecurityGroup)
+				g.Expect(*nsg.SecurityRules).To(ConsistOf(sdkRule(sshRule), converters.SecurityRuleToSDK(otherRule), sdkRule(customRuleWithPriority(600))))
+				g.Expect(nsg.Tags).To(HaveKeyWithValue("sigs.k8s.io_cluster-api-provider-azure_owned-security-rules_test-cluster", to.StringPtr("allow_ssh,custom_rule")))
+				g.Expect(nsg.Etag).To(Equal(to.StringPtr("fake-etag")))
+			},
+		},
+		{
+			name: "up to date rules are updated to record their ownership",
+			spec: &NSGSpec{Name: "test-nsg", ClusterName: "test-cluster", SecurityRules: infrav1.SecurityRules{sshRule}, SharedOwnership: true},
+			existing: func(nsg *network.SecurityGroup) {
+				delete(nsg.Tags, "sigs.k8s.io_cluster-api-provider-azure_owned-security-rules_test-cluster")
+				nsg.SecurityRules = &[]network.SecurityRule{sdkRule(sshRule), converters.SecurityRuleToSDK(otherRule)}
+			},
+			expect: func(g *WithT, result interface{}) {
+				g.Expect(result).To(BeAssignableToTypeOf(network.SecurityGroup{}))
+				nsg := result.(network.SecurityGroup)
+				g.Expect(*nsg.SecurityRules).To(ConsistOf(sdkRule(sshRule), converters.SecurityRuleToSDK(otherRule)))
+				g.Expect(nsg.Tags).To(HaveKeyWithValue("sigs.k8s.io_cluster-api-provider-azure_owned-security-rules_test-cluster", to.StringPtr("allow_ssh")))
+			},
+		},
+		{
+			name: "security group is not updated when the owned rules and their record are up to date",
+			spec: &NSGSpec{Name: "test-nsg", ClusterName: "test-cluster", SecurityRules: infrav1.SecurityRules{sshRule, otherRule}, SharedOwnership: true},
+			existing: func(nsg *network.SecurityGroup) {
+				nsg.Tags["sigs.k8s.io_cluster-api-provider-azure_owned-security-rules_test-cluster"] = to.StringPtr("allow_ssh")
+				nsg.SecurityRules = &[]network.SecurityRule{sdkRule(sshRule), converters.SecurityRuleToSDK(otherRule)}
+			},
+			expect: func(g *WithT, result interface{}) {
+				g.Expect(result).To(BeNil())
+			},
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			existing := sharedNSG()
+			if tc.existing != nil {
+				tc.existing(&existing)
+			}
+			result, err := tc.spec.Parameters(existing)
+			g.Expect(err).NotTo(HaveOccurred())
+			tc.expect(g, result)
+		})
+	}
+}
+
+func TestWithOwnedRulesTags(t *testing.T) {
+	g := NewWithT(t)
+
+	var names []string
+	for i := 0; i < 30; i++ {
+		names = append(names, fmt.Sprintf("allow_port_%02d", i))
+	}
+	tags := map[string]*string{
+		"Name": to.StringPtr("test-nsg"),
+		"sigs.k8s.io_cluster-api-provider-azure_owned-security-rules_other-cluster":  to.StringPtr("allow_ssh"),
+		"sigs.k8s.io_cluster-api-provider-azure_owned-security-rules_test-cluster_3": to.StringPtr("stale"),
+	}
+
+	updated, changed := withOwnedRulesTags(tags, "test-cluster", names)
+	g.Expect(changed).To(BeTrue())
+	g.Expect(updated).To(HaveKeyWithValue("Name", to.StringPtr("test-nsg")))
+	g.Expect(updated).To(HaveKeyWithValue("sigs.k8s.io_cluster-api-provider-azure_owned-security-rules_other-cluster", to.StringPtr("allow_ssh")))
+	g.Expect(updated).NotTo(HaveKey("sigs.k8s.io_cluster-api-provider-azure_owned-security-rules_test-cluster_3"))
+	g.Expect(updated).To(HaveKey("sigs.k8s.io_cluster-api-provider-azure_owned-security-rules_test-cluster_1"))
+	g.Expect(tags).To(HaveKey("sigs.k8s.io_cluster-api-provider-azure_owned-security-rules_test-cluster_3"))
+
+	// The owned rule names are split over tags that Azure accepts and are read back as a whole.
+	for _, value := range updated {
+		g.Expect(len(to.String(value))).To(BeNumerically("<=", maxTagValueLength))
+	}
+	spec := &NSGSpec{ClusterName: "test-cluster", SharedOwnership: true}
+	owned := spec.ownedRuleNames(network.SecurityGroup{Tags: updated})
+	g.Expect(owned).To(HaveLen(len(names)))
+	g.Expect(owned).To(HaveKey(strings.ToLower(names[29])))
+
+	_, changed = withOwnedRulesTags(updated, "test-cluster", names)
+	g.Expect(changed).To(BeFalse())
+}
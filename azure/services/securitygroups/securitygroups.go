@@ -18,7 +18,13 @@ package securitygroups
 
 import (
 	"context"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-02-01/network"
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 	"sigs.k8s.io/cluster-api-provider-azure/azure"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/async"
@@ -28,27 +34,77 @@ import (
 
 const serviceName = "securitygroups"
 
+// NotFoundGracePeriod is how long after it started creating a security group the service should wait for the security
+// group to be found before creating it again, see async.WithNotFoundGracePeriod.
+const NotFoundGracePeriod = time.Minute
+
 // NSGScope defines the scope interface for a security groups service.
 type NSGScope interface {
 	azure.Authorizer
 	azure.AsyncStatusUpdater
 	NSGSpecs() []azure.ResourceSpecGetter
 	IsVnetManaged() bool
+	SetSecurityGroupStatus(status infrav1.SecurityGroupStatus)
+	// SetSecurityGroupsProgress records how many security groups are ready, in progress or failed after a reconcile.
+	// It is called after the SecurityGroupsReady condition is updated so that the summary can be added to its message.
+	SetSecurityGroupsProgress(progress infrav1.SecurityGroupsProgress)
+	// SetSecurityGroupPendingChanges records the rule changes of a security group computed in dry-run mode.
+	SetSecurityGroupPendingChanges(changes infrav1.SecurityGroupPendingChanges)
+	// DeleteSecurityGroupPendingChanges clears the pending rule changes of a security group once they are applied.
+	DeleteSecurityGroupPendingChanges(name string)
+	// SecurityRuleSources returns the data of the key of the ConfigMap or Secret referenced by a security rule to read
+	// its sources from.
+	SecurityRuleSources(ctx context.Context, ref infrav1.SecurityRuleSourcesReference) (string, error)
 }
 
 // Service provides operations on Azure resources.
 type Service struct {
 	Scope NSGScope
 	async.Reconciler
+	// FlowLogReconciler creates and deletes the flow logs of the security groups whose spec enables them.
+	FlowLogReconciler async.Reconciler
+	// DiagnosticSettingsReconciler creates and deletes the diagnostic settings of the security groups whose spec enables them.
+	DiagnosticSettingsReconciler async.Reconciler
+	// FirewallPolicies reads the network rules of the Azure Firewall policies referenced by the security groups, whose
+	// rules already enforced by the firewall are not created.
+	FirewallPolicies FirewallPolicyReader
+	// ResourceGroupTags reads the tags of the resource groups of the security groups inheriting some of them.
+	ResourceGroupTags ResourceGroupTagsReader
+	// ValidateCustomVNet, when true, checks in custom VNet mode that the existing security groups allow the inbound
+	// traffic required by their spec, without modifying them. Security groups are not reconciled at all otherwise.
+	ValidateCustomVNet bool
+	// PreserveUnowned, when true, does not delete the security groups that are not tagged as owned by the cluster, e.g.
+	// security groups brought by the user, but only removes the rules owned by CAPZ from them. It is off by default
+	// since the security groups created by earlier releases are not tagged as owned and would be left behind.
+	PreserveUnowned bool
+	// Concurrency is the number of security groups processed in parallel. Security groups are processed
+	// sequentially when it is lower than 2, which is the default.
+	Concurrency int
+	// scopeLock serializes the updates of the scope made while security groups are processed concurrently. The async
+	// services update the scope through a synchronized scope holding the same lock.
+	scopeLock sync.Mutex
 }
 
-// New creates a new service.
-func New(scope NSGScope) *Service {
+// New creates a new service. The options configure the async services that create and delete the security groups,
+// their flow logs and their diagnostic settings, e.g. async.WithRestartOnSpecChange to restart the operations on
+// security groups whose rules are edited while they are in progress. The security group specs declare their quota
+// requirement, so that async.WithQuotaCheck only creates them if the quota of their region is not exhausted.
+func New(scope NSGScope, opts ...async.Option) *Service {
 	client := newClient(scope)
-	return &Service{
-		Scope:      scope,
-		Reconciler: async.New(scope, client, client),
+	flowLogClient := newFlowLogClient(scope)
+	diagnosticSettingsClient := newDiagnosticSettingsClient(scope)
+	s := &Service{
+		Scope:             scope,
+		FirewallPolicies:  newFirewallPolicyClient(scope),
+		ResourceGroupTags: newResourceGroupTagsClient(scope),
 	}
+	// The async scope is synchronized with the lock of the service so that long-running operation states can be
+	// written safely when security groups are processed concurrently.
+	asyncScope := async.NewSynchronizedScope(scope, &s.scopeLock)
+	s.Reconciler = async.New(asyncScope, client, client, opts...)
+	s.FlowLogReconciler = async.New(asyncScope, flowLogClient, flowLogClient, opts...)
+	s.DiagnosticSettingsReconciler = async.New(asyncScope, diagnosticSettingsClient, diagnosticSettingsClient, opts...)
+	return s
 }
 
 // Reconcile gets/creates/updates network security groups.
@@ -56,69 +112,277 @@ func (s *Service) Reconcile(ctx context.Context) error {
 	ctx, log, done := tele.StartSpanWithLogger(ctx, "securitygroups.Service.Reconcile")
 	defer done()
 
-	ctx, cancel := context.WithTimeout(ctx, reconciler.DefaultAzureServiceReconcileTimeout)
+	reconcileTimeout := reconciler.ServiceReconcileTimeout(s.Reconciler)
+	ctx, cancel := context.WithTimeout(ctx, reconcileTimeout)
 	defer cancel()
 
+	// Security groups are left untouched while the service is paused, e.g. during a maintenance window.
+	if s.Scope.IsServicePaused(serviceName) {
+		log.Info("Skipping network security groups reconcile, the service is paused")
+		return nil
+	}
+
 	// Only create the NSGs if their lifecycle is managed by this controller.
 	if !s.Scope.IsVnetManaged() {
-		log.V(4).Info("Skipping network security groups reconcile in custom VNet mode")
-		return nil
+		if !s.ValidateCustomVNet {
+			log.V(4).Info("Skipping network security groups reconcile in custom VNet mode")
+			return nil
+		}
+		err := s.validateCustomVNet(ctx)
+		s.Scope.UpdatePutStatus(infrav1.SecurityGroupsReadyCondition, serviceName, err)
+		return err
 	}
 
-	specs := s.Scope.NSGSpecs()
+	specs := s.nsgSpecs(ctx)
 	if len(specs) == 0 {
 		return nil
 	}
-
-	var resErr error
+	opTimeout := s.operationTimeout(reconcileTimeout, len(specs))
 
 	// We go through the list of security groups to reconcile each one, independently of the result of the previous one.
 	// If multiple errors occur, we return the most pressing one.
 	//  Order of precedence (highest -> lowest) is: error that is not an operationNotDoneError (i.e. error creating) -> operationNotDoneError (i.e. creating in progress) -> no error (i.e. created)
-	for _, nsgSpec := range specs {
-		if _, err := s.CreateResource(ctx, nsgSpec, serviceName); err != nil {
-			if !azure.IsOperationNotDoneError(err) || resErr == nil {
-				resErr = err
-			}
+	progress, resErr := s.forEachSpecWithProgress(specs, func(nsgSpec azure.ResourceSpecGetter) error {
+		if err := s.resolveRuleSources(ctx, nsgSpec); err != nil {
+			return err
 		}
-	}
+		if err := s.resolveFirewallRules(ctx, nsgSpec); err != nil {
+			return err
+		}
+		if err := s.resolveInheritedTags(ctx, nsgSpec); err != nil {
+			return err
+		}
+		// Invalid rules are reported up front since Azure would only reject them after a full long-running operation.
+		if err := validateSpec(nsgSpec); err != nil {
+			return err
+		}
+		result, changed, err := s.createResource(ctx, nsgSpec, opTimeout)
+		if change, ok := result.(*async.Change); ok {
+			// In dry-run mode nothing was changed, so there is nothing to record.
+			s.reportChange(ctx, nsgSpec, change)
+			return err
+		}
+		if changed && err == nil {
+			log.V(2).Info("security group was created or updated", "securityGroup", nsgSpec.ResourceName())
+		}
+		if err == nil {
+			err = s.checkProvisioningState(nsgSpec, result)
+		}
+		if err == nil {
+			s.warnConflicts(ctx, nsgSpec, result)
+			err = s.setStatus(nsgSpec, result)
+		}
+		if err == nil {
+			err = s.reconcileFlowLog(ctx, nsgSpec)
+		}
+		if err == nil {
+			err = s.reconcileDiagnosticSettings(ctx, nsgSpec)
+		}
+		return err
+	})
 
 	s.Scope.UpdatePutStatus(infrav1.SecurityGroupsReadyCondition, serviceName, resErr)
+	s.Scope.SetSecurityGroupsProgress(progress)
 	return resErr
 }
 
+// operationTimeout returns the timeout of the create or update of a single security group, so that a slow security group
+// cannot use up the reconcile timeout of the security groups processed after it. The reconcile timeout is shared equally
+// between the batches of Concurrency security groups, but each operation gets at least reconciler.DefaultAzureCallTimeout.
+func (s *Service) operationTimeout(reconcileTimeout time.Duration, specCount int) time.Duration {
+	concurrency := s.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	batches := (specCount + concurrency - 1) / concurrency
+	timeout := reconcileTimeout / time.Duration(batches)
+	if timeout < reconciler.DefaultAzureCallTimeout {
+		return reconciler.DefaultAzureCallTimeout
+	}
+	return timeout
+}
+
+// createResource creates or updates a security group within the operation timeout, which still ends at the reconcile
+// deadline of ctx at the latest. An operation cut short by its own timeout is reported as not done so that the security
+// group is requeued rather than failed.
+func (s *Service) createResource(ctx context.Context, nsgSpec azure.ResourceSpecGetter, timeout time.Duration) (result interface{}, changed bool, err error) {
+	opCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	result, changed, err = s.CreateResource(opCtx, nsgSpec, serviceName)
+	if err != nil && !azure.IsOperationNotDoneError(err) && errors.Is(opCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+		future := &infrav1.Future{Type: infrav1.PutFuture, ServiceName: serviceName, Name: nsgSpec.ResourceName(), ResourceGroup: nsgSpec.ResourceGroupName()}
+		return result, changed, azure.WithTransientError(azure.NewOperationNotDoneError(future), reconciler.DefaultReconcilerRequeue)
+	}
+	return result, changed, err
+}
+
+// nsgSpecs returns the security group specs from the scope, keeping a single spec per Azure security group so that
+// overlapping specs do not start competing operations on the same resource.
+func (s *Service) nsgSpecs(ctx context.Context) []azure.ResourceSpecGetter {
+	_, log, done := tele.StartSpanWithLogger(ctx, "securitygroups.Service.nsgSpecs")
+	defer done()
+
+	specs, duplicates := async.CoalesceSpecs(s.Scope.NSGSpecs())
+	for _, duplicate := range duplicates {
+		log.Info("WARNING: multiple security group specs refer to the same security group, only the first one will be reconciled", "securityGroup", duplicate.ResourceName(), "resourceGroup", duplicate.ResourceGroupName())
+	}
+	return specs
+}
+
+// validateSpec returns a terminal error if the rules of a security group spec would be rejected by Azure.
+func validateSpec(spec azure.ResourceSpecGetter) error {
+	nsgSpec, ok := spec.(*NSGSpec)
+	if !ok {
+		return nil
+	}
+	if err := nsgSpec.Validate(); err != nil {
+		return azure.WithTerminalError(errors.Wrapf(err, "invalid security rules for security group %s", nsgSpec.Name))
+	}
+	return nil
+}
+
+// reportChange logs the rule changes a security group would get from a reconcile in dry-run mode and records them as
+// the pending changes of the security group, so that they can be reviewed from the status of the cluster.
+func (s *Service) reportChange(ctx context.Context, spec azure.ResourceSpecGetter, change *async.Change) {
+	_, log, done := tele.StartSpanWithLogger(ctx, "securitygroups.Service.reportChange")
+	defer done()
+
+	nsgSpec, ok := spec.(*NSGSpec)
+	if !ok {
+		return
+	}
+	// A security group that would be created has no existing rules, so all its rules are additions.
+	existing, _ := change.Existing.(network.SecurityGroup)
+	changes := nsgSpec.RuleChanges(existing)
+	log.Info("dry run: security group would be changed", "securityGroup", nsgSpec.Name, "created", change.Existing == nil,
+		"addedRules", ruleNames(changes.Additions), "deletedRules", changes.Deletions, "heldDeletions", changes.HeldDeletions)
+
+	s.scopeLock.Lock()
+	defer s.scopeLock.Unlock()
+	s.Scope.SetSecurityGroupPendingChanges(pendingChanges(nsgSpec.Name, existing, changes))
+}
+
+// checkProvisioningState returns an error if Azure reports a reconciled security group in a provisioning state other
+// than Succeeded, so that the condition of the security groups tells whether it is still being provisioned or failed.
+// The provisioning state is not checked if the reconciler cannot tell it.
+func (s *Service) checkProvisioningState(nsgSpec azure.ResourceSpecGetter, result interface{}) error {
+	getter, ok := s.Reconciler.(async.ProvisioningStateGetter)
+	if !ok {
+		return nil
+	}
+	state, ok := getter.ProvisioningState(result)
+	if !ok || strings.EqualFold(state, string(infrav1.Succeeded)) {
+		return nil
+	}
+	err := azure.ProvisioningStateError{ResourceName: nsgSpec.ResourceName(), State: infrav1.ProvisioningState(state)}
+	if err.InProgress() {
+		return azure.WithTransientError(err, reconciler.DefaultReconcilerRequeue)
+	}
+	return err
+}
+
 // Delete deletes network security groups.
 func (s *Service) Delete(ctx context.Context) error {
 	ctx, log, done := tele.StartSpanWithLogger(ctx, "securitygroups.Service.Delete")
 	defer done()
 
-	ctx, cancel := context.WithTimeout(ctx, reconciler.DefaultAzureServiceReconcileTimeout)
+	ctx, cancel := context.WithTimeout(ctx, reconciler.ServiceReconcileTimeout(s.Reconciler))
 	defer cancel()
 
+	if s.Scope.IsServicePaused(serviceName) {
+		log.Info("Skipping network security groups delete, the service is paused")
+		return nil
+	}
+
 	// Only delete the NSG if its lifecycle is managed by this controller.
 	if !s.Scope.IsVnetManaged() {
 		log.V(4).Info("Skipping network security groups delete in custom VNet mode")
 		return nil
 	}
 
-	specs := s.Scope.NSGSpecs()
+	specs := s.nsgSpecs(ctx)
 	if len(specs) == 0 {
 		return nil
 	}
 
-	var result error
-
 	// We go through the list of security groups to delete each one, independently of the result of the previous one.
 	// If multiple errors occur, we return the most pressing one.
 	//  Order of precedence (highest -> lowest) is: error that is not an operationNotDoneError (i.e. error deleting) -> operationNotDoneError (i.e. deleting in progress) -> no error (i.e. deleted)
-	for _, nsgSpec := range specs {
-		if err := s.DeleteResource(ctx, nsgSpec, serviceName); err != nil {
-			if !azure.IsOperationNotDoneError(err) || result == nil {
-				result = err
+	result := s.forEachSpec(specs, func(nsgSpec azure.ResourceSpecGetter) error {
+		// The flow log and diagnostic settings are deleted first since they would otherwise outlive their security group.
+		if err := s.deleteFlowLog(ctx, nsgSpec); err != nil {
+			return err
+		}
+		if err := s.deleteDiagnosticSettings(ctx, nsgSpec); err != nil {
+			return err
+		}
+		if s.PreserveUnowned {
+			if preserved, err := s.removeOwnedRules(ctx, nsgSpec); preserved || err != nil {
+				return err
 			}
 		}
-	}
+		notFound, err := s.DeleteResource(ctx, nsgSpec, serviceName)
+		if azure.ResourceInUse(err) {
+			// Retrying will fail the same way until the references are removed, so say what is blocking the delete.
+			return errors.Wrapf(err, "security group %s is still associated with subnets or network interfaces, dissociate them so that it can be deleted", nsgSpec.ResourceName())
+		}
+		if notFound {
+			log.V(4).Info("security group was already deleted", "securityGroup", nsgSpec.ResourceName())
+		}
+		return err
+	})
 
 	s.Scope.UpdateDeleteStatus(infrav1.SecurityGroupsReadyCondition, serviceName, result)
 	return result
 }
+
+// forEachSpec calls fn for each spec, running up to Concurrency calls in parallel, and returns the most pressing error.
+// Errors are aggregated in the order of the specs so that the result is the same as when specs are processed sequentially.
+func (s *Service) forEachSpec(specs []azure.ResourceSpecGetter, fn func(azure.ResourceSpecGetter) error) error {
+	_, err := s.forEachSpecWithProgress(specs, fn)
+	return err
+}
+
+// forEachSpecWithProgress is forEachSpec that also counts the specs whose call succeeded, is still in progress or
+// failed, while aggregating their errors.
+func (s *Service) forEachSpecWithProgress(specs []azure.ResourceSpecGetter, fn func(azure.ResourceSpecGetter) error) (infrav1.SecurityGroupsProgress, error) {
+	errs := make([]error, len(specs))
+	if s.Concurrency < 2 {
+		for i, spec := range specs {
+			errs[i] = fn(spec)
+		}
+	} else {
+		// The errors are collected rather than returned to the group, so that every spec is processed whatever the
+		// outcome of the others.
+		var g errgroup.Group
+		g.SetLimit(s.Concurrency)
+		for i, spec := range specs {
+			i, spec := i, spec
+			g.Go(func() error {
+				errs[i] = fn(spec)
+				return nil
+			})
+		}
+		_ = g.Wait()
+	}
+
+	progress := infrav1.SecurityGroupsProgress{Total: int32(len(errs))}
+	var result error
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			progress.Ready++
+		case azure.IsOperationNotDoneError(err):
+			progress.InProgress++
+		default:
+			progress.Failed++
+		}
+		if err != nil {
+			if !azure.IsOperationNotDoneError(err) || result == nil {
+				result = err
+			}
+		}
+	}
+	return progress, result
+}
@@ -18,15 +18,22 @@ package securitygroups
 
 import (
 	"context"
+	"fmt"
+	"net/http"
 	"testing"
+	"time"
 
 	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-02-01/network"
+	"github.com/Azure/go-autorest/autorest"
+	azureautorest "github.com/Azure/go-autorest/autorest/azure"
 	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/gomega"
 	"github.com/pkg/errors"
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/converters"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/async"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/async/mock_async"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/securitygroups/mock_securitygroups"
 	gomockinternal "sigs.k8s.io/cluster-api-provider-azure/internal/test/matchers/gomock"
@@ -68,10 +75,46 @@ var (
 		SecurityRules: infrav1.SecurityRules{},
 		ResourceGroup: "test-group",
 	}
+	fakeNSGInvalid = NSGSpec{
+		Name:     "test-nsg-invalid",
+		Location: "test-location",
+		SecurityRules: infrav1.SecurityRules{
+			{
+				Name:             "allow_ssh",
+				Priority:         500,
+				Protocol:         infrav1.SecurityGroupProtocolTCP,
+				Direction:        infrav1.SecurityRuleDirectionInbound,
+				DestinationPorts: to.StringPtr("22"),
+			},
+			{
+				Name:             "allow_http",
+				Priority:         500,
+				Protocol:         infrav1.SecurityGroupProtocolTCP,
+				Direction:        infrav1.SecurityRuleDirectionInbound,
+				DestinationPorts: to.StringPtr("80"),
+			},
+		},
+		ResourceGroup: "test-group",
+	}
+	fakeNSGDuplicate = NSGSpec{
+		Name:          "TEST-NSG",
+		Location:      "test-location",
+		SecurityRules: infrav1.SecurityRules{},
+		ResourceGroup: "test-group",
+	}
 	errFake      = errors.New("this is an error")
 	notDoneError = azure.NewOperationNotDoneError(&infrav1.Future{})
+	// invalidNSGError is the terminal error returned for fakeNSGInvalid.
+	invalidNSGError = "reconcile error that cannot be recovered occurred: invalid security rules for security group test-nsg-invalid: securityRules[1].priority: Invalid value: 500: rules allow_ssh and allow_http have the same priority 500 in direction Inbound. Object will not be requeued"
 )
 
+// newMockNSGScope returns a mock NSGScope whose security groups service is not paused.
+func newMockNSGScope(mockCtrl *gomock.Controller) *mock_securitygroups.MockNSGScope {
+	scopeMock := mock_securitygroups.NewMockNSGScope(mockCtrl)
+	scopeMock.EXPECT().IsServicePaused(serviceName).Return(false).AnyTimes()
+	return scopeMock
+}
+
 func TestReconcileSecurityGroups(t *testing.T) {
 	testcases := []struct {
 		name          string
@@ -84,9 +127,71 @@ func TestReconcileSecurityGroups(t *testing.T) {
 			expect: func(s *mock_securitygroups.MockNSGScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				s.IsVnetManaged().Return(true)
 				s.NSGSpecs().Return([]azure.ResourceSpecGetter{&fakeNSG, &fakeNSG2})
-				r.CreateResource(gomockinternal.AContext(), &fakeNSG, serviceName).Return(nil, nil)
-				r.CreateResource(gomockinternal.AContext(), &fakeNSG2, serviceName).Return(nil, nil)
+				r.CreateResource(gomockinternal.AContext(), &fakeNSG, serviceName).Return(nil, false, nil)
+				r.CreateResource(gomockinternal.AContext(), &fakeNSG2, serviceName).Return(nil, false, nil)
+				s.UpdatePutStatus(infrav1.SecurityGroupsReadyCondition, serviceName, nil)
+				s.SetSecurityGroupsProgress(infrav1.SecurityGroupsProgress{Total: 2, Ready: 2})
+			},
+		},
+		{
+			name:          "create security groups with the same name in different resource groups, should reconcile both",
+			expectedError: "",
+			expect: func(s *mock_securitygroups.MockNSGScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
+				networkNSG := fakeNSG2
+				networkNSG.ResourceGroup = "network-group"
+				s.IsVnetManaged().Return(true)
+				s.NSGSpecs().Return([]azure.ResourceSpecGetter{&fakeNSG2, &networkNSG})
+				r.CreateResource(gomockinternal.AContext(), &fakeNSG2, serviceName).Return(nil, false, nil)
+				r.CreateResource(gomockinternal.AContext(), &networkNSG, serviceName).Return(nil, false, nil)
+				s.UpdatePutStatus(infrav1.SecurityGroupsReadyCondition, serviceName, nil)
+				s.SetSecurityGroupsProgress(infrav1.SecurityGroupsProgress{Total: 2, Ready: 2})
+			},
+		},
+		{
+			name:          "create security group succeeds, should record the rules found on the security group",
+			expectedError: "",
+			expect: func(s *mock_securitygroups.MockNSGScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
+				s.IsVnetManaged().Return(true)
+				s.NSGSpecs().Return([]azure.ResourceSpecGetter{&fakeNSG})
+				r.CreateResource(gomockinternal.AContext(), &fakeNSG, serviceName).Return(network.SecurityGroup{
+					Name: to.StringPtr("test-nsg"),
+					SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
+						SecurityRules: &[]network.SecurityRule{managedRule(converters.SecurityRuleToSDK(fakeNSG.SecurityRules[0]))},
+					},
+				}, false, nil)
+				s.SetSecurityGroupStatus(infrav1.SecurityGroupStatus{
+					Name: "test-nsg",
+					Rules: []infrav1.SecurityRuleStatus{
+						{
+							Name:             "allow_ssh",
+							Priority:         2200,
+							Direction:        infrav1.SecurityRuleDirectionInbound,
+							Protocol:         infrav1.SecurityGroupProtocolTCP,
+							SourcePorts:      []string{"*"},
+							DestinationPorts: []string{"22"},
+						},
+					},
+				})
+				s.DeleteSecurityGroupPendingChanges("test-nsg")
+				s.UpdatePutStatus(infrav1.SecurityGroupsReadyCondition, serviceName, nil)
+				s.SetSecurityGroupsProgress(infrav1.SecurityGroupsProgress{Total: 1, Ready: 1})
+			},
+		},
+		{
+			name:          "security group change in dry-run mode, should not record the rules",
+			expectedError: "",
+			expect: func(s *mock_securitygroups.MockNSGScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
+				s.IsVnetManaged().Return(true)
+				s.NSGSpecs().Return([]azure.ResourceSpecGetter{&fakeNSG})
+				r.CreateResource(gomockinternal.AContext(), &fakeNSG, serviceName).Return(&async.Change{
+					ServiceName:   serviceName,
+					ResourceGroup: "test-group",
+					ResourceName:  "test-nsg",
+					Type:          infrav1.PutFuture,
+				}, false, nil)
+				s.SetSecurityGroupPendingChanges(infrav1.SecurityGroupPendingChanges{Name: "test-nsg", AddedRules: []string{"allow_ssh", "other_rule"}})
 				s.UpdatePutStatus(infrav1.SecurityGroupsReadyCondition, serviceName, nil)
+				s.SetSecurityGroupsProgress(infrav1.SecurityGroupsProgress{Total: 1, Ready: 1})
 			},
 		},
 		{
@@ -95,9 +200,10 @@ func TestReconcileSecurityGroups(t *testing.T) {
 			expect: func(s *mock_securitygroups.MockNSGScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				s.IsVnetManaged().Return(true)
 				s.NSGSpecs().Return([]azure.ResourceSpecGetter{&fakeNSG, &fakeNSG2})
-				r.CreateResource(gomockinternal.AContext(), &fakeNSG, serviceName).Return(nil, errFake)
-				r.CreateResource(gomockinternal.AContext(), &fakeNSG2, serviceName).Return(nil, nil)
+				r.CreateResource(gomockinternal.AContext(), &fakeNSG, serviceName).Return(nil, false, errFake)
+				r.CreateResource(gomockinternal.AContext(), &fakeNSG2, serviceName).Return(nil, false, nil)
 				s.UpdatePutStatus(infrav1.SecurityGroupsReadyCondition, serviceName, errFake)
+				s.SetSecurityGroupsProgress(infrav1.SecurityGroupsProgress{Total: 2, Ready: 1, Failed: 1})
 			},
 		},
 		{
@@ -106,9 +212,10 @@ func TestReconcileSecurityGroups(t *testing.T) {
 			expect: func(s *mock_securitygroups.MockNSGScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				s.IsVnetManaged().Return(true)
 				s.NSGSpecs().Return([]azure.ResourceSpecGetter{&fakeNSG, &fakeNSG2})
-				r.CreateResource(gomockinternal.AContext(), &fakeNSG, serviceName).Return(nil, errFake)
-				r.CreateResource(gomockinternal.AContext(), &fakeNSG2, serviceName).Return(nil, notDoneError)
+				r.CreateResource(gomockinternal.AContext(), &fakeNSG, serviceName).Return(nil, false, errFake)
+				r.CreateResource(gomockinternal.AContext(), &fakeNSG2, serviceName).Return(nil, false, notDoneError)
 				s.UpdatePutStatus(infrav1.SecurityGroupsReadyCondition, serviceName, errFake)
+				s.SetSecurityGroupsProgress(infrav1.SecurityGroupsProgress{Total: 2, InProgress: 1, Failed: 1})
 			},
 		},
 		{
@@ -117,8 +224,31 @@ func TestReconcileSecurityGroups(t *testing.T) {
 			expect: func(s *mock_securitygroups.MockNSGScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				s.IsVnetManaged().Return(true)
 				s.NSGSpecs().Return([]azure.ResourceSpecGetter{&fakeNSG})
-				r.CreateResource(gomockinternal.AContext(), &fakeNSG, serviceName).Return(nil, notDoneError)
+				r.CreateResource(gomockinternal.AContext(), &fakeNSG, serviceName).Return(nil, false, notDoneError)
+				s.UpdatePutStatus(infrav1.SecurityGroupsReadyCondition, serviceName, notDoneError)
+				s.SetSecurityGroupsProgress(infrav1.SecurityGroupsProgress{Total: 1, InProgress: 1})
+			},
+		},
+		{
+			name:          "equivalent security group specs, should reconcile the security group once",
+			expectedError: notDoneError.Error(),
+			expect: func(s *mock_securitygroups.MockNSGScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
+				s.IsVnetManaged().Return(true)
+				s.NSGSpecs().Return([]azure.ResourceSpecGetter{&fakeNSG, &fakeNSGDuplicate})
+				r.CreateResource(gomockinternal.AContext(), &fakeNSG, serviceName).Return(nil, false, notDoneError)
 				s.UpdatePutStatus(infrav1.SecurityGroupsReadyCondition, serviceName, notDoneError)
+				s.SetSecurityGroupsProgress(infrav1.SecurityGroupsProgress{Total: 1, InProgress: 1})
+			},
+		},
+		{
+			name:          "security group with conflicting rule priorities, should fail without calling Azure",
+			expectedError: invalidNSGError,
+			expect: func(s *mock_securitygroups.MockNSGScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
+				s.IsVnetManaged().Return(true)
+				s.NSGSpecs().Return([]azure.ResourceSpecGetter{&fakeNSGInvalid, &fakeNSG2})
+				r.CreateResource(gomockinternal.AContext(), &fakeNSG2, serviceName).Return(nil, false, nil)
+				s.UpdatePutStatus(infrav1.SecurityGroupsReadyCondition, serviceName, gomockinternal.ErrStrEq(invalidNSGError))
+				s.SetSecurityGroupsProgress(infrav1.SecurityGroupsProgress{Total: 2, Ready: 1, Failed: 1})
 			},
 		},
 		{
@@ -137,7 +267,7 @@ func TestReconcileSecurityGroups(t *testing.T) {
 			mockCtrl := gomock.NewController(t)
 			defer mockCtrl.Finish()
 
-			scopeMock := mock_securitygroups.NewMockNSGScope(mockCtrl)
+			scopeMock := newMockNSGScope(mockCtrl)
 			reconcilerMock := mock_async.NewMockReconciler(mockCtrl)
 
 			tc.expect(scopeMock.EXPECT(), reconcilerMock.EXPECT())
@@ -158,7 +288,106 @@ func TestReconcileSecurityGroups(t *testing.T) {
 	}
 }
 
+// provisioningStateReconciler is a reconciler that can tell the provisioning state of the security groups it returns.
+type provisioningStateReconciler struct {
+	*mock_async.MockReconciler
+	*mock_async.MockProvisioningStateGetter
+}
+
+func TestReconcileSecurityGroupsProvisioningState(t *testing.T) {
+	nsg := network.SecurityGroup{Name: to.StringPtr("test-nsg")}
+	testcases := []struct {
+		name          string
+		expectedError string
+		expect        func(s *mock_securitygroups.MockNSGScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder, p *mock_async.MockProvisioningStateGetterMockRecorder)
+	}{
+		{
+			name:          "security group provisioning succeeded, should return no error",
+			expectedError: "",
+			expect: func(s *mock_securitygroups.MockNSGScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder, p *mock_async.MockProvisioningStateGetterMockRecorder) {
+				s.IsVnetManaged().Return(true)
+				s.NSGSpecs().Return([]azure.ResourceSpecGetter{&fakeNSG})
+				r.CreateResource(gomockinternal.AContext(), &fakeNSG, serviceName).Return(nsg, false, nil)
+				p.ProvisioningState(nsg).Return("Succeeded", true)
+				s.SetSecurityGroupStatus(gomock.Any())
+				s.DeleteSecurityGroupPendingChanges("test-nsg")
+				s.UpdatePutStatus(infrav1.SecurityGroupsReadyCondition, serviceName, nil)
+				s.SetSecurityGroupsProgress(infrav1.SecurityGroupsProgress{Total: 1, Ready: 1})
+			},
+		},
+		{
+			name:          "security group provisioning state unknown, should return no error",
+			expectedError: "",
+			expect: func(s *mock_securitygroups.MockNSGScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder, p *mock_async.MockProvisioningStateGetterMockRecorder) {
+				s.IsVnetManaged().Return(true)
+				s.NSGSpecs().Return([]azure.ResourceSpecGetter{&fakeNSG})
+				r.CreateResource(gomockinternal.AContext(), &fakeNSG, serviceName).Return(nsg, false, nil)
+				p.ProvisioningState(nsg).Return("", false)
+				s.SetSecurityGroupStatus(gomock.Any())
+				s.DeleteSecurityGroupPendingChanges("test-nsg")
+				s.UpdatePutStatus(infrav1.SecurityGroupsReadyCondition, serviceName, nil)
+				s.SetSecurityGroupsProgress(infrav1.SecurityGroupsProgress{Total: 1, Ready: 1})
+			},
+		},
+		{
+			name:          "security group still updating, should return transient error",
+			expectedError: "resource test-nsg is in provisioning state Updating. Object will be requeued after 15s",
+			expect: func(s *mock_securitygroups.MockNSGScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder, p *mock_async.MockProvisioningStateGetterMockRecorder) {
+				s.IsVnetManaged().Return(true)
+				s.NSGSpecs().Return([]azure.ResourceSpecGetter{&fakeNSG})
+				r.CreateResource(gomockinternal.AContext(), &fakeNSG, serviceName).Return(nsg, false, nil)
+				p.ProvisioningState(nsg).Return("Updating", true)
+				s.UpdatePutStatus(infrav1.SecurityGroupsReadyCondition, serviceName, gomock.Any())
+				s.SetSecurityGroupsProgress(infrav1.SecurityGroupsProgress{Total: 1, Failed: 1})
+			},
+		},
+		{
+			name:          "security group provisioning failed, should return error",
+			expectedError: "resource test-nsg is in provisioning state Failed",
+			expect: func(s *mock_securitygroups.MockNSGScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder, p *mock_async.MockProvisioningStateGetterMockRecorder) {
+				s.IsVnetManaged().Return(true)
+				s.NSGSpecs().Return([]azure.ResourceSpecGetter{&fakeNSG})
+				r.CreateResource(gomockinternal.AContext(), &fakeNSG, serviceName).Return(nsg, false, nil)
+				p.ProvisioningState(nsg).Return("Failed", true)
+				s.UpdatePutStatus(infrav1.SecurityGroupsReadyCondition, serviceName, azure.ProvisioningStateError{ResourceName: "test-nsg", State: infrav1.Failed})
+				s.SetSecurityGroupsProgress(infrav1.SecurityGroupsProgress{Total: 1, Failed: 1})
+			},
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			scopeMock := newMockNSGScope(mockCtrl)
+			reconcilerMock := mock_async.NewMockReconciler(mockCtrl)
+			getterMock := mock_async.NewMockProvisioningStateGetter(mockCtrl)
+
+			tc.expect(scopeMock.EXPECT(), reconcilerMock.EXPECT(), getterMock.EXPECT())
+
+			s := &Service{
+				Scope:      scopeMock,
+				Reconciler: provisioningStateReconciler{reconcilerMock, getterMock},
+			}
+
+			err := s.Reconcile(context.TODO())
+			if tc.expectedError != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err).To(MatchError(tc.expectedError))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
+
 func TestDeleteSecurityGroups(t *testing.T) {
+	inUseErr := autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: http.StatusBadRequest}, "")
+	inUseErr.Original = &azureautorest.ServiceError{Code: "InUseNetworkSecurityGroupCannotBeDeleted"}
+
 	testcases := []struct {
 		name          string
 		expectedError string
@@ -170,8 +399,8 @@ func TestDeleteSecurityGroups(t *testing.T) {
 			expect: func(s *mock_securitygroups.MockNSGScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				s.IsVnetManaged().Return(true)
 				s.NSGSpecs().Return([]azure.ResourceSpecGetter{&fakeNSG, &fakeNSG2})
-				r.DeleteResource(gomockinternal.AContext(), &fakeNSG, serviceName).Return(nil)
-				r.DeleteResource(gomockinternal.AContext(), &fakeNSG2, serviceName).Return(nil)
+				r.DeleteResource(gomockinternal.AContext(), &fakeNSG, serviceName).Return(false, nil)
+				r.DeleteResource(gomockinternal.AContext(), &fakeNSG2, serviceName).Return(false, nil)
 				s.UpdateDeleteStatus(infrav1.SecurityGroupsReadyCondition, serviceName, nil)
 			},
 		},
@@ -181,8 +410,8 @@ func TestDeleteSecurityGroups(t *testing.T) {
 			expect: func(s *mock_securitygroups.MockNSGScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				s.IsVnetManaged().Return(true)
 				s.NSGSpecs().Return([]azure.ResourceSpecGetter{&fakeNSG, &fakeNSG2})
-				r.DeleteResource(gomockinternal.AContext(), &fakeNSG, serviceName).Return(errFake)
-				r.DeleteResource(gomockinternal.AContext(), &fakeNSG2, serviceName).Return(nil)
+				r.DeleteResource(gomockinternal.AContext(), &fakeNSG, serviceName).Return(false, errFake)
+				r.DeleteResource(gomockinternal.AContext(), &fakeNSG2, serviceName).Return(false, nil)
 				s.UpdateDeleteStatus(infrav1.SecurityGroupsReadyCondition, serviceName, errFake)
 			},
 		},
@@ -192,8 +421,8 @@ func TestDeleteSecurityGroups(t *testing.T) {
 			expect: func(s *mock_securitygroups.MockNSGScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				s.IsVnetManaged().Return(true)
 				s.NSGSpecs().Return([]azure.ResourceSpecGetter{&fakeNSG, &fakeNSG2})
-				r.DeleteResource(gomockinternal.AContext(), &fakeNSG, serviceName).Return(errFake)
-				r.DeleteResource(gomockinternal.AContext(), &fakeNSG2, serviceName).Return(notDoneError)
+				r.DeleteResource(gomockinternal.AContext(), &fakeNSG, serviceName).Return(false, errFake)
+				r.DeleteResource(gomockinternal.AContext(), &fakeNSG2, serviceName).Return(false, notDoneError)
 				s.UpdateDeleteStatus(infrav1.SecurityGroupsReadyCondition, serviceName, errFake)
 			},
 		},
@@ -203,10 +432,24 @@ func TestDeleteSecurityGroups(t *testing.T) {
 			expect: func(s *mock_securitygroups.MockNSGScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				s.IsVnetManaged().Return(true)
 				s.NSGSpecs().Return([]azure.ResourceSpecGetter{&fakeNSG})
-				r.DeleteResource(gomockinternal.AContext(), &fakeNSG, serviceName).Return(notDoneError)
+				r.DeleteResource(gomockinternal.AContext(), &fakeNSG, serviceName).Return(false, notDoneError)
 				s.UpdateDeleteStatus(infrav1.SecurityGroupsReadyCondition, serviceName, notDoneError)
 			},
 		},
+		{
+			name:          "security group still in use, should return an actionable error",
+			expectedError: "security group test-nsg is still associated with subnets or network interfaces, dissociate them so that it can be deleted: failed to delete resource: " + inUseErr.Error(),
+			expect: func(s *mock_securitygroups.MockNSGScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
+				s.IsVnetManaged().Return(true)
+				s.NSGSpecs().Return([]azure.ResourceSpecGetter{&fakeNSG})
+				r.DeleteResource(gomockinternal.AContext(), &fakeNSG, serviceName).Return(false, errors.Wrap(inUseErr, "failed to delete resource"))
+				s.UpdateDeleteStatus(infrav1.SecurityGroupsReadyCondition, serviceName, gomock.Any()).Do(func(_, _ interface{}, err error) {
+					if !azure.ResourceInUse(err) {
+						t.Errorf("expected a resource in use error, got %v", err)
+					}
+				})
+			},
+		},
 		{
 			name:          "vnet is not managed, should skip delete",
 			expectedError: "",
@@ -223,7 +466,7 @@ func TestDeleteSecurityGroups(t *testing.T) {
 			mockCtrl := gomock.NewController(t)
 			defer mockCtrl.Finish()
 
-			scopeMock := mock_securitygroups.NewMockNSGScope(mockCtrl)
+			scopeMock := newMockNSGScope(mockCtrl)
 			reconcilerMock := mock_async.NewMockReconciler(mockCtrl)
 
 			tc.expect(scopeMock.EXPECT(), reconcilerMock.EXPECT())
@@ -285,3 +528,166 @@ var (
 		},
 	}
 )
+
+func TestSecurityGroupsConcurrencyPreservesErrorPrecedence(t *testing.T) {
+	errFake2 := errors.New("this is another error")
+	notDoneError2 := azure.NewOperationNotDoneError(&infrav1.Future{Name: "other"})
+
+	testcases := []struct {
+		name             string
+		results          []error
+		expectedError    error
+		expectedProgress infrav1.SecurityGroupsProgress
+	}{
+		{
+			name:             "all security groups succeed",
+			results:          []error{nil, nil, nil, nil, nil},
+			expectedError:    nil,
+			expectedProgress: infrav1.SecurityGroupsProgress{Total: 5, Ready: 5},
+		},
+		{
+			name:             "some security groups are not done",
+			results:          []error{nil, notDoneError, nil, notDoneError2, nil},
+			expectedError:    notDoneError,
+			expectedProgress: infrav1.SecurityGroupsProgress{Total: 5, Ready: 3, InProgress: 2},
+		},
+		{
+			name:             "errors take precedence over operations not done",
+			results:          []error{notDoneError, errFake, nil, notDoneError2, errFake2, nil},
+			expectedError:    errFake2,
+			expectedProgress: infrav1.SecurityGroupsProgress{Total: 6, Ready: 2, InProgress: 2, Failed: 2},
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+
+			specs := make([]azure.ResourceSpecGetter, len(tc.results))
+			for i := range specs {
+				specs[i] = &NSGSpec{Name: fmt.Sprintf("test-nsg-%d", i), ResourceGroup: "test-group"}
+			}
+			run := func(concurrency int, reconcile bool) error {
+				mockCtrl := gomock.NewController(t)
+				defer mockCtrl.Finish()
+
+				scopeMock := newMockNSGScope(mockCtrl)
+				reconcilerMock := mock_async.NewMockReconciler(mockCtrl)
+				scopeMock.EXPECT().IsVnetManaged().Return(true)
+				scopeMock.EXPECT().NSGSpecs().Return(specs)
+				for i, spec := range specs {
+					if reconcile {
+						reconcilerMock.EXPECT().CreateResource(gomockinternal.AContext(), spec, serviceName).Return(nil, false, tc.results[i])
+					} else {
+						reconcilerMock.EXPECT().DeleteResource(gomockinternal.AContext(), spec, serviceName).Return(false, tc.results[i])
+					}
+				}
+				s := &Service{
+					Scope:       scopeMock,
+					Reconciler:  reconcilerMock,
+					Concurrency: concurrency,
+				}
+				if reconcile {
+					scopeMock.EXPECT().UpdatePutStatus(infrav1.SecurityGroupsReadyCondition, serviceName, tc.expectedError)
+					scopeMock.EXPECT().SetSecurityGroupsProgress(tc.expectedProgress)
+					return s.Reconcile(context.TODO())
+				}
+				scopeMock.EXPECT().UpdateDeleteStatus(infrav1.SecurityGroupsReadyCondition, serviceName, tc.expectedError)
+				return s.Delete(context.TODO())
+			}
+
+			for _, reconcile := range []bool{true, false} {
+				sequentialErr := run(1, reconcile)
+				concurrentErr := run(3, reconcile)
+				if tc.expectedError == nil {
+					g.Expect(sequentialErr).NotTo(HaveOccurred())
+					g.Expect(concurrentErr).NotTo(HaveOccurred())
+				} else {
+					g.Expect(sequentialErr).To(Equal(tc.expectedError))
+					g.Expect(concurrentErr).To(Equal(sequentialErr))
+				}
+			}
+		})
+	}
+}
+
+func TestSecurityGroupsOperationTimeout(t *testing.T) {
+	testcases := []struct {
+		name        string
+		concurrency int
+		specCount   int
+		expected    time.Duration
+	}{
+		{
+			name:        "single security group gets the whole reconcile timeout",
+			concurrency: 4,
+			specCount:   1,
+			expected:    12 * time.Second,
+		},
+		{
+			name:        "sequential security groups share the reconcile timeout",
+			concurrency: 1,
+			specCount:   3,
+			expected:    4 * time.Second,
+		},
+		{
+			name:        "concurrent security groups share the reconcile timeout by batch",
+			concurrency: 2,
+			specCount:   3,
+			expected:    6 * time.Second,
+		},
+		{
+			name:        "operation timeout is at least the Azure call timeout",
+			concurrency: 0,
+			specCount:   20,
+			expected:    2 * time.Second,
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+
+			s := &Service{Concurrency: tc.concurrency}
+			g.Expect(s.operationTimeout(12*time.Second, tc.specCount)).To(Equal(tc.expected))
+		})
+	}
+}
+
+func TestSecurityGroupsOperationTimeoutRequeues(t *testing.T) {
+	g := NewWithT(t)
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	reconcilerMock := mock_async.NewMockReconciler(mockCtrl)
+	reconcilerMock.EXPECT().CreateResource(gomockinternal.AContext(), &fakeNSG, serviceName).DoAndReturn(
+		func(ctx context.Context, _ azure.ResourceSpecGetter, _ string) (interface{}, bool, error) {
+			<-ctx.Done()
+			return nil, false, errors.Wrap(ctx.Err(), "failed to get existing resource")
+		})
+	s := &Service{Reconciler: reconcilerMock}
+
+	_, _, err := s.createResource(context.TODO(), &fakeNSG, 10*time.Millisecond)
+	g.Expect(azure.IsOperationNotDoneError(err)).To(BeTrue())
+	g.Expect(err.Error()).To(ContainSubstring("test-nsg"))
+}
+
+func TestSecurityGroupsPaused(t *testing.T) {
+	g := NewWithT(t)
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	// Neither Azure nor the status are touched while the service is paused.
+	scopeMock := mock_securitygroups.NewMockNSGScope(mockCtrl)
+	scopeMock.EXPECT().IsServicePaused(serviceName).Return(true).Times(2)
+	s := &Service{
+		Scope:      scopeMock,
+		Reconciler: mock_async.NewMockReconciler(mockCtrl),
+	}
+
+	g.Expect(s.Reconcile(context.TODO())).To(Succeed())
+	g.Expect(s.Delete(context.TODO())).To(Succeed())
+}
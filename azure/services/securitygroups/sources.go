@@ -0,0 +1,86 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package securitygroups
+
+import (
+	"context"
+	"net"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/util/reconciler"
+)
+
+// resolveRuleSources reads the sources of the rules of the spec referencing a ConfigMap or Secret with SourcesFrom into
+// the RuleSources of the spec. They are read on every reconcile, so that the rules are updated when the list changes.
+// A list that cannot be read or is malformed returns a transient error, so that the security group is left as is and
+// the failure is reported in the SecurityGroupsReady condition until the list is fixed.
+func (s *Service) resolveRuleSources(ctx context.Context, spec azure.ResourceSpecGetter) error {
+	nsgSpec, ok := spec.(*NSGSpec)
+	if !ok {
+		return nil
+	}
+	for _, rule := range nsgSpec.securityRules() {
+		ref := rule.SourcesFrom
+		if ref == nil {
+			continue
+		}
+		data, err := s.Scope.SecurityRuleSources(ctx, *ref)
+		if err != nil {
+			return azure.WithTransientError(errors.Wrapf(err, "failed to read the sources of rule %s of security group %s from %s %s", rule.Name, nsgSpec.Name, ref.Kind, ref.Name), reconciler.DefaultReconcilerRequeue)
+		}
+		sources, err := parseSources(data)
+		if err != nil {
+			return azure.WithTransientError(errors.Wrapf(err, "invalid sources of rule %s of security group %s in %s %s", rule.Name, nsgSpec.Name, ref.Kind, ref.Name), reconciler.DefaultReconcilerRequeue)
+		}
+		if nsgSpec.RuleSources == nil {
+			nsgSpec.RuleSources = make(map[string][]string)
+		}
+		nsgSpec.RuleSources[rule.Name] = sources
+	}
+	return nil
+}
+
+// parseSources parses a list of CIDRs or IP addresses separated by commas, spaces or new lines. Text after a '#' on a
+// line is a comment. The sources are sorted and deduplicated so that reordering the list does not update the rule.
+// Errors only give the position of an invalid source since the list may be read from a Secret.
+func parseSources(data string) ([]string, error) {
+	seen := make(map[string]bool)
+	var sources []string
+	for i, line := range strings.Split(data, "\n") {
+		line = strings.SplitN(line, "#", 2)[0]
+		for j, source := range strings.FieldsFunc(line, func(r rune) bool { return r == ',' || unicode.IsSpace(r) }) {
+			if net.ParseIP(source) == nil {
+				if _, _, err := net.ParseCIDR(source); err != nil {
+					return nil, errors.Errorf("source %d of line %d is not a CIDR or an IP address", j+1, i+1)
+				}
+			}
+			if !seen[source] {
+				seen[source] = true
+				sources = append(sources, source)
+			}
+		}
+	}
+	if len(sources) == 0 {
+		return nil, errors.New("no sources are listed")
+	}
+	sort.Strings(sources)
+	return sources, nil
+}
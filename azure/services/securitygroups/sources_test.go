@@ -0,0 +1,140 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package securitygroups
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	gomockinternal "sigs.k8s.io/cluster-api-provider-azure/internal/test/matchers/gomock"
+)
+
+func TestParseSources(t *testing.T) {
+	testcases := []struct {
+		name          string
+		data          string
+		expected      []string
+		expectedError string
+	}{
+		{
+			name:     "sources separated by commas, spaces and new lines",
+			data:     "10.0.0.0/16, 192.168.1.1\n2001:db8::/32 172.16.0.0/12\n",
+			expected: []string{"10.0.0.0/16", "172.16.0.0/12", "192.168.1.1", "2001:db8::/32"},
+		},
+		{
+			name:     "comments and duplicates are skipped",
+			data:     "# admins\n10.0.0.0/16 # office\n10.0.0.0/16\n",
+			expected: []string{"10.0.0.0/16"},
+		},
+		{
+			name:          "malformed source",
+			data:          "10.0.0.0/16\n10.0.0.0/8, s3cr3t",
+			expectedError: "source 2 of line 2 is not a CIDR or an IP address",
+		},
+		{
+			name:          "no sources",
+			data:          "# nothing yet\n",
+			expectedError: "no sources are listed",
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			sources, err := parseSources(tc.data)
+			if tc.expectedError != "" {
+				g.Expect(err).To(MatchError(tc.expectedError))
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(sources).To(Equal(tc.expected))
+		})
+	}
+}
+
+func TestResolveRuleSources(t *testing.T) {
+	ref := infrav1.SecurityRuleSourcesReference{Kind: infrav1.SecurityRuleSourcesFromConfigMap, Name: "admin-allowlist", Key: "cidrs"}
+	adminRule := sshRuleWithPriority(2200)
+	adminRule.Name = "allow_admins"
+	adminRule.Source = nil
+	adminRule.SourcesFrom = &ref
+
+	testcases := []struct {
+		name             string
+		data             string
+		err              error
+		expectedPrefix   *string
+		expectedPrefixes *[]string
+		expectedError    string
+	}{
+		{
+			name:           "single source",
+			data:           "10.0.0.0/16",
+			expectedPrefix: to.StringPtr("10.0.0.0/16"),
+		},
+		{
+			name:             "multiple sources make an augmented rule",
+			data:             "10.0.0.0/16\n192.168.0.0/24",
+			expectedPrefixes: &[]string{"10.0.0.0/16", "192.168.0.0/24"},
+		},
+		{
+			name:          "list cannot be read",
+			err:           errors.New("configmaps \"admin-allowlist\" not found"),
+			expectedError: "failed to read the sources of rule allow_admins of security group test-nsg from ConfigMap admin-allowlist",
+		},
+		{
+			name:          "malformed list",
+			data:          "10.0.0.0/16\nnot-a-cidr",
+			expectedError: "invalid sources of rule allow_admins of security group test-nsg in ConfigMap admin-allowlist",
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			scopeMock := newMockNSGScope(mockCtrl)
+			scopeMock.EXPECT().SecurityRuleSources(gomockinternal.AContext(), ref).Return(tc.data, tc.err)
+
+			spec := &NSGSpec{Name: "test-nsg", SecurityRules: infrav1.SecurityRules{adminRule}}
+			s := &Service{Scope: scopeMock}
+			err := s.resolveRuleSources(context.TODO(), spec)
+			if tc.expectedError != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tc.expectedError)))
+				g.Expect(azure.IsOperationNotDoneError(err)).To(BeFalse())
+				var reconcileErr azure.ReconcileError
+				g.Expect(errors.As(err, &reconcileErr)).To(BeTrue())
+				g.Expect(reconcileErr.IsTransient()).To(BeTrue())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			rules := spec.desiredRules()
+			g.Expect(rules).To(HaveLen(1))
+			g.Expect(rules[0].SourceAddressPrefix).To(Equal(tc.expectedPrefix))
+			g.Expect(rules[0].SourceAddressPrefixes).To(Equal(tc.expectedPrefixes))
+		})
+	}
+}
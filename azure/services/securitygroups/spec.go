@@ -17,21 +17,120 @@ limitations under the License.
 package securitygroups
 
 import (
+	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-02-01/network"
 	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/converters"
 )
 
+const (
+	// denyAllOutboundRuleName is the name of the rule injected when default-deny-outbound is enabled.
+	denyAllOutboundRuleName = "deny_all_outbound"
+	// highestUserRulePriority is the lowest priority number (i.e. the highest precedence) Azure accepts for user-defined rules.
+	highestUserRulePriority int32 = 100
+	// lowestUserRulePriority is the highest priority number (i.e. the lowest precedence) Azure accepts for user-defined rules.
+	lowestUserRulePriority int32 = 4096
+	// firstAutoRulePriority is the priority assigned to the first rule without a priority in each direction.
+	firstAutoRulePriority int32 = 100
+	// autoRulePriorityStep is the gap between the priorities assigned to consecutive rules without a priority.
+	autoRulePriorityStep int32 = 10
+	// managedRuleTag ends the description of the rules managed by CAPZ. Rules without it, e.g. added manually by an
+	// administrator, are foreign and are never deleted.
+	managedRuleTag = "(managed by capz)"
+	// securityGroupsQuotaName is the name of the quota of security groups per region reported by the network usages API.
+	securityGroupsQuotaName = "NetworkSecurityGroups"
+)
+
+// ruleNamePrefixRegex matches the valid rule name prefixes.
+var ruleNamePrefixRegex = regexp.MustCompile(infrav1.SecurityRuleNamePrefixRegex)
+
 // NSGSpec defines the specification for a security group.
 type NSGSpec struct {
 	Name          string
 	SecurityRules infrav1.SecurityRules
 	Location      string
 	ResourceGroup string
+	ClusterName   string
+	// Reconciled is true if the security group was already reconciled for the cluster, e.g. created by an earlier
+	// release that did not tag security groups as owned. It is owned by the cluster even without the owned tag rather
+	// than adopted, see adopting.
+	Reconciled bool
+	// AdditionalTags are added to the security group. They are re-added if removed from an existing security group.
+	AdditionalTags infrav1.Tags
+	// IgnoreTagDrift, when true, does not update an existing security group only because its additional tags were
+	// removed or changed, e.g. edited out of band. They are added back once the security group is updated for its
+	// rules, so that only drift of the rules managed by CAPZ causes a long-running operation.
+	IgnoreTagDrift bool
+	// DefaultDenyOutbound, when true, adds a rule denying all outbound traffic at infrav1.DenyAllOutboundRulePriority
+	// so that only explicitly allowed egress is permitted.
+	DefaultDenyOutbound bool
+	// PruneRules, when true, deletes the existing rules managed by CAPZ that are not part of the spec.
+	// Foreign rules are always left intact.
+	PruneRules bool
+	// AdditiveSafeMode, when true, holds the deletions made by PruneRules until they are listed in
+	// ConfirmedRuleDeletions, so that a reconcile only ever adds or updates rules on its own.
+	AdditiveSafeMode bool
+	// ConfirmedRuleDeletions are the names of the rules whose deletion is confirmed in additive safe mode.
+	ConfirmedRuleDeletions []string
+	// FlowLog, if set, enables flow logs for the security group. The flow log is deleted with the security group.
+	FlowLog *FlowLogSpec
+	// DiagnosticSettings, if set, ships the logs of the security group to a Log Analytics workspace. The diagnostic
+	// settings are deleted with the security group.
+	DiagnosticSettings *DiagnosticSettingsSpec
+	// SubnetCIDRs are the CIDR blocks of the subnets of the cluster by name. They are the destination of the rules
+	// referencing a subnet with DestinationSubnet.
+	SubnetCIDRs map[string][]string
+	// SharedOwnership, when true, coordinates with the other writers of the security group, e.g. other controllers.
+	// Only the rules owned by CAPZ are updated and pruned, and a rule of another writer is never taken over even if it
+	// has the name of a rule of the spec. The names of the owned rules are recorded in the tags of the security group,
+	// see infrav1.OwnedSecurityRulesTagKey, so that ownership survives other writers rewriting the rules.
+	SharedOwnership bool
+	// RuleSets are the rule sets shared with other security groups whose rules are added to the security group, in
+	// order. A rule of a later rule set, or of SecurityRules, overrides an earlier rule with the same name.
+	RuleSets []infrav1.SecurityRuleSet
+	// PriorityAssignment, if set, overrides the start and step of the priorities assigned to the rules without a
+	// priority.
+	PriorityAssignment *infrav1.SecurityRulePriorityAssignment
+	// RuleNamePrefix is prepended to the names of the rules of the spec in Azure. Rules are recognized as managed by
+	// CAPZ from the marker at the end of their description, not from their name, so that the managed rules named with
+	// a previous prefix are renamed rather than left behind, see RuleChanges.
+	RuleNamePrefix string
+	// RuleSources are the source CIDRs or IP ranges of the rules reading them from a ConfigMap or Secret with
+	// SourcesFrom, by rule name. They are read when the security group is reconciled, see Service.resolveRuleSources.
+	RuleSources map[string][]string
+	// FirewallPolicyID, if set, is the resource ID of the Azure Firewall policy filtering the traffic of the security
+	// group. The rules whose traffic is already allowed or denied by a network rule of the policy are not created.
+	FirewallPolicyID string
+	// FirewallRules are the network rules of the Azure Firewall policy. They are read when the security group is
+	// reconciled, see Service.resolveFirewallRules.
+	FirewallRules []FirewallRule
+	// InheritedTagKeys are the keys of the tags of the resource group that are added to the security group.
+	InheritedTagKeys []string
+	// InheritedTags are the tags of the resource group whose key is in InheritedTagKeys. They are read when the security
+	// group is reconciled, see Service.resolveInheritedTags, and are overridden by the AdditionalTags with the same key.
+	// A tag whose key is in InheritedTagKeys but not in InheritedTags is removed from an existing security group.
+	InheritedTags infrav1.Tags
+}
+
+// RuleChanges categorizes the rule changes needed to bring an existing security group to its spec.
+type RuleChanges struct {
+	// Additions are the rules of the spec that are missing or out of date in the security group.
+	Additions []network.SecurityRule
+	// Deletions are the names of the rules that are deleted from the security group.
+	Deletions []string
+	// HeldDeletions are the names of the rules that would be deleted but are pending confirmation.
+	HeldDeletions []string
+	// Conflicts are the names of the rules of the spec that are skipped in shared ownership mode because a rule of
+	// another writer has the same name.
+	Conflicts []string
 }
 
 // ResourceName returns the name of the security group.
@@ -49,10 +148,56 @@ func (s *NSGSpec) OwnerResourceName() string {
 	return ""
 }
 
+// QuotaRequirement returns the quota of security groups in the location of the security group, of which it consumes one.
+func (s *NSGSpec) QuotaRequirement() azure.QuotaRequirement {
+	return azure.QuotaRequirement{Location: s.Location, Name: securityGroupsQuotaName, Amount: 1}
+}
+
+// Validate returns an aggregated error listing the rules Azure would reject. The rules of the spec are first validated
+// as the webhooks do, see infrav1.ValidateSecurityRules. Then, once names are prefixed and priorities are assigned, the
+// rules with a name that is too long, with a priority outside of the user range and the rules sharing a priority with
+// another rule of the same direction, e.g. the deny all outbound rule, are listed.
+func (s *NSGSpec) Validate() error {
+	if errs := infrav1.ValidateSecurityRules(s.securityRules(), field.NewPath("securityRules")); len(errs) > 0 {
+		return errs.ToAggregate()
+	}
+
+	var errs []error
+	seen := make(map[network.SecurityRuleDirection]map[int32]string)
+	for _, rule := range s.allDesiredRules() {
+		name := to.String(rule.Name)
+		priority := to.Int32(rule.Priority)
+		if len(name) > infrav1.MaxSecurityRuleNameLength {
+			errs = append(errs, errors.Errorf("rule %s has a name longer than %d characters", name, infrav1.MaxSecurityRuleNameLength))
+			continue
+		}
+		if priority < highestUserRulePriority || priority > lowestUserRulePriority {
+			errs = append(errs, errors.Errorf("rule %s has priority %d, priorities must be between %d and %d", name, priority, highestUserRulePriority, lowestUserRulePriority))
+			continue
+		}
+		if seen[rule.Direction] == nil {
+			seen[rule.Direction] = make(map[int32]string)
+		}
+		if other, ok := seen[rule.Direction][priority]; ok {
+			errs = append(errs, errors.Errorf("rules %s and %s have the same priority %d in direction %s", other, name, priority, rule.Direction))
+			continue
+		}
+		seen[rule.Direction][priority] = name
+	}
+	for _, rule := range s.securityRules() {
+		if rule.DestinationSubnet != "" && len(s.SubnetCIDRs[rule.DestinationSubnet]) == 0 {
+			errs = append(errs, errors.Errorf("rule %s has destination subnet %s, which is not a subnet of the cluster with CIDR blocks", rule.Name, rule.DestinationSubnet))
+		}
+	}
+	return kerrors.NewAggregate(errs)
+}
+
 // Parameters returns the parameters for the security group.
 func (s *NSGSpec) Parameters(existing interface{}) (interface{}, error) {
 	securityRules := make([]network.SecurityRule, 0)
 	var etag *string
+	var tags map[string]*string
+	location := to.StringPtr(s.Location)
 
 	if existing != nil {
 		existingNSG, ok := existing.(network.SecurityGroup)
@@ -62,29 +207,73 @@ func (s *NSGSpec) Parameters(existing interface{}) (interface{}, error) {
 		// security group already exists
 		// We append the existing NSG etag to the header to ensure we only apply the updates if the NSG has not been modified.
 		etag = existingNSG.Etag
+		// The location of an existing security group cannot change, so it is kept, e.g. for an adopted security group
+		// created by the user in another location than the cluster.
+		if existingNSG.Location != nil {
+			location = existingNSG.Location
+		}
+		// Existing tags, including the CAPZ ownership tag, are kept and the additional tags that were removed or
+		// changed are added back.
+		tags = existingNSG.Tags
+		missingTags := s.additionalTags().Difference(converters.MapToTags(existingNSG.Tags))
+		if len(missingTags) > 0 {
+			merged := converters.MapToTags(existingNSG.Tags)
+			merged.Merge(missingTags)
+			tags = converters.TagsToMap(merged)
+		}
+		// Tags that were inherited from the resource group are removed once the resource group no longer has them.
+		staleTags := s.staleInheritedTagKeys(converters.MapToTags(existingNSG.Tags))
+		if len(staleTags) > 0 {
+			tags = withoutTags(tags, staleTags)
+		}
 		// Check if the expected rules are present
-		update := false
-		securityRules = *existingNSG.SecurityRules
-		for _, rule := range s.SecurityRules {
-			sdkRule := converters.SecurityRuleToSDK(rule)
-			if !ruleExists(securityRules, sdkRule) {
-				update = true
-				securityRules = append(securityRules, sdkRule)
-			}
+		changes := s.RuleChanges(existingNSG)
+		if err := mergeConflicts(existingNSG, changes); err != nil {
+			return nil, errors.Wrapf(err, "failed to merge the rules of the spec into security group %s", s.Name)
+		}
+		ownershipChanged := false
+		if s.SharedOwnership {
+			tags, ownershipChanged = withOwnedRulesTags(tags, s.ClusterName, s.ownedRulesAfter(existingNSG, changes))
 		}
-		if !update {
-			// Skip update for NSG as the required default rules are present
+		// A security group being adopted is only tagged as owned once the rules of the spec are merged into it, so
+		// that a failed merge leaves it as the user created it.
+		adopted := false
+		if s.adopting(existingNSG) && len(changes.Additions) == 0 && len(changes.Deletions) == 0 {
+			tags = withOwnerTag(tags, s.ClusterName)
+			adopted = true
+		}
+		tagsDrifted := len(missingTags) > 0 && !s.IgnoreTagDrift
+		if len(changes.Additions) == 0 && len(changes.Deletions) == 0 && !tagsDrifted && len(staleTags) == 0 && !ownershipChanged && !adopted {
+			// Skip update for NSG as the required default rules and tags are present, or tag drift is ignored
 			return nil, nil
 		}
+		if existingNSG.SecurityRules != nil {
+			for _, rule := range *existingNSG.SecurityRules {
+				name := to.String(rule.Name)
+				// Out of date rules are replaced by their addition rather than sent twice.
+				if !containsFold(changes.Deletions, name) && !ruleNamed(changes.Additions, name) {
+					securityRules = append(securityRules, rule)
+				}
+			}
+		}
+		securityRules = append(securityRules, changes.Additions...)
 	} else {
 		// new security group
-		for _, rule := range s.SecurityRules {
-			securityRules = append(securityRules, converters.SecurityRuleToSDK(rule))
+		securityRules = append(securityRules, s.desiredRules()...)
+		tags = converters.TagsToMap(infrav1.Build(infrav1.BuildParams{
+			ClusterName: s.ClusterName,
+			Lifecycle:   infrav1.ResourceLifecycleOwned,
+			Name:        to.StringPtr(s.Name),
+			Additional:  s.additionalTags(),
+		}))
+		if s.SharedOwnership {
+			tags, _ = withOwnedRulesTags(tags, s.ClusterName, ruleNames(securityRules))
 		}
 	}
 
 	return network.SecurityGroup{
-		Location: to.StringPtr(s.Location),
+		Tags:     tags,
+		Location: location,
 		SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
 			SecurityRules: &securityRules,
 		},
@@ -92,26 +281,425 @@ func (s *NSGSpec) Parameters(existing interface{}) (interface{}, error) {
 	}, nil
 }
 
-// TODO: review this logic and make sure it is what we want. It seems incorrect to skip rules that don't have a certain protocol, etc.
+// additionalTags returns the tags added to the security group: the inherited tags of its resource group and the
+// additional tags, which take precedence.
+func (s *NSGSpec) additionalTags() infrav1.Tags {
+	if len(s.InheritedTags) == 0 {
+		return s.AdditionalTags
+	}
+	tags := make(infrav1.Tags, len(s.InheritedTags)+len(s.AdditionalTags))
+	tags.Merge(s.InheritedTags)
+	tags.Merge(s.AdditionalTags)
+	return tags
+}
+
+// staleInheritedTagKeys returns the keys of the tags of the existing security group that were inherited from its
+// resource group, i.e. whose key is in InheritedTagKeys, but that the resource group no longer has. A key that is also
+// an additional tag is kept.
+func (s *NSGSpec) staleInheritedTagKeys(existing infrav1.Tags) []string {
+	var keys []string
+	for _, key := range s.InheritedTagKeys {
+		if _, ok := existing[key]; !ok {
+			continue
+		}
+		if _, ok := s.InheritedTags[key]; ok {
+			continue
+		}
+		if _, ok := s.AdditionalTags[key]; ok {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// withoutTags returns a copy of the tags without the given keys.
+func withoutTags(tags map[string]*string, keys []string) map[string]*string {
+	updated := make(map[string]*string, len(tags))
+	for key, value := range tags {
+		updated[key] = value
+	}
+	for _, key := range keys {
+		delete(updated, key)
+	}
+	return updated
+}
+
+// RuleChanges returns the rule changes needed to bring the existing security group to the spec.
+func (s *NSGSpec) RuleChanges(existing network.SecurityGroup) RuleChanges {
+	var changes RuleChanges
+	var existingRules []network.SecurityRule
+	if existing.SecurityGroupPropertiesFormat != nil && existing.SecurityRules != nil {
+		existingRules = *existing.SecurityRules
+	}
+
+	owned := s.ownedRuleNames(existing)
+	desired := s.desiredRules()
+	for _, sdkRule := range desired {
+		if s.SharedOwnership && ruleOfOtherWriter(existingRules, to.String(sdkRule.Name), owned) {
+			changes.Conflicts = append(changes.Conflicts, to.String(sdkRule.Name))
+			continue
+		}
+		if !ruleExists(existingRules, sdkRule) {
+			changes.Additions = append(changes.Additions, sdkRule)
+		}
+	}
+
+	for _, rule := range existingRules {
+		name := to.String(rule.Name)
+		if ruleNamed(desired, name) || !ownsRule(rule, owned) {
+			continue
+		}
+		// A managed rule of the spec named with another prefix is replaced by the rule named with the current prefix,
+		// even if rules are not pruned, so that both rules do not compete for the same priority.
+		if s.renamedRule(rule, desired) {
+			changes.Deletions = append(changes.Deletions, name)
+			continue
+		}
+		if !s.PruneRules {
+			continue
+		}
+		if s.AdditiveSafeMode && !containsFold(s.ConfirmedRuleDeletions, name) {
+			changes.HeldDeletions = append(changes.HeldDeletions, name)
+			continue
+		}
+		changes.Deletions = append(changes.Deletions, name)
+	}
+	return changes
+}
+
+// desiredRules returns the SDK representation of the rules this spec enforces on the security group. The rules already
+// enforced by the Azure Firewall policy of the spec, if any, are left out.
+func (s *NSGSpec) desiredRules() []network.SecurityRule {
+	rules := s.allDesiredRules()
+	if len(s.FirewallRules) == 0 {
+		return rules
+	}
+	desired := make([]network.SecurityRule, 0, len(rules))
+	for _, rule := range rules {
+		if !enforcedByFirewall(s.FirewallRules, rule) {
+			desired = append(desired, rule)
+		}
+	}
+	return desired
+}
+
+// allDesiredRules returns the SDK representation of all the rules of the spec. Priorities are assigned before the rules
+// enforced by the Azure Firewall policy are left out, so that the priorities of the other rules do not depend on it.
+func (s *NSGSpec) allDesiredRules() []network.SecurityRule {
+	securityRules := s.securityRules()
+	rules := make([]network.SecurityRule, 0, len(securityRules)+1)
+	for _, rule := range securityRules {
+		sdkRule := converters.SecurityRuleToSDK(rule)
+		if rule.DestinationSubnet != "" {
+			sdkRule = s.withSubnetDestination(sdkRule, rule.DestinationSubnet)
+		}
+		if rule.SourcesFrom != nil {
+			sdkRule = withSources(sdkRule, s.RuleSources[rule.Name])
+		}
+		rules = append(rules, managedRule(sdkRule))
+	}
+	if s.DefaultDenyOutbound {
+		rules = append(rules, managedRule(denyAllOutboundRule()))
+	}
+	if s.RuleNamePrefix != "" {
+		for i := range rules {
+			rules[i].Name = to.StringPtr(s.RuleNamePrefix + to.String(rules[i].Name))
+		}
+	}
+	assignPriorities(rules, s.PriorityAssignment)
+	return rules
+}
+
+// securityRules returns the rules of the rule sets followed by the rules of the spec. A rule overrides the earlier rule
+// with the same name in place, so the order of the rules only depends on the order of the rule sets and their rules.
+func (s *NSGSpec) securityRules() infrav1.SecurityRules {
+	if len(s.RuleSets) == 0 {
+		return s.SecurityRules
+	}
+	var rules infrav1.SecurityRules
+	add := func(rule infrav1.SecurityRule) {
+		for i := range rules {
+			if strings.EqualFold(rules[i].Name, rule.Name) {
+				rules[i] = rule
+				return
+			}
+		}
+		rules = append(rules, rule)
+	}
+	for _, ruleSet := range s.RuleSets {
+		for _, rule := range ruleSet.SecurityRules {
+			add(rule)
+		}
+	}
+	for _, rule := range s.SecurityRules {
+		add(rule)
+	}
+	return rules
+}
+
+// renamedRule returns true if the existing rule is marked as managed by CAPZ and is one of the desired rules named with
+// another prefix than the rule name prefix of the spec, including no prefix. Only the prefixes that end with a
+// separator, i.e. a hyphen, an underscore or a period, are recognized so that a rule whose name merely ends with the
+// name of a rule of the spec is not taken for it.
+func (s *NSGSpec) renamedRule(rule network.SecurityRule, desired []network.SecurityRule) bool {
+	if !isManagedRule(rule) {
+		return false
+	}
+	name := strings.ToLower(to.String(rule.Name))
+	prefix := strings.ToLower(s.RuleNamePrefix)
+	for _, desiredRule := range desired {
+		base := strings.TrimPrefix(strings.ToLower(to.String(desiredRule.Name)), prefix)
+		if base == "" || !strings.HasSuffix(name, base) {
+			continue
+		}
+		otherPrefix := strings.TrimSuffix(name, base)
+		if otherPrefix == prefix {
+			continue
+		}
+		if otherPrefix == "" || (strings.ContainsAny(otherPrefix[len(otherPrefix)-1:], "-_.") && ruleNamePrefixRegex.MatchString(otherPrefix)) {
+			return true
+		}
+	}
+	return false
+}
+
+// withSubnetDestination sets the CIDR blocks of the subnet as the destination of the rule. A subnet with multiple CIDR
+// blocks, e.g. a dual-stack subnet, makes it an augmented rule. The CIDR blocks are resolved from the spec on every
+// reconcile so that the rule is updated when the CIDR blocks of the subnet change.
+func (s *NSGSpec) withSubnetDestination(rule network.SecurityRule, subnetName string) network.SecurityRule {
+	cidrs := s.SubnetCIDRs[subnetName]
+	switch {
+	case len(cidrs) == 1:
+		rule.DestinationAddressPrefix = to.StringPtr(cidrs[0])
+		rule.DestinationAddressPrefixes = nil
+	case len(cidrs) > 1:
+		rule.DestinationAddressPrefix = nil
+		rule.DestinationAddressPrefixes = to.StringSlicePtr(append([]string{}, cidrs...))
+	}
+	return rule
+}
+
+// withSources sets the sources read from the ConfigMap or Secret referenced by the rule as its sources. Multiple sources
+// make it an augmented rule.
+func withSources(rule network.SecurityRule, sources []string) network.SecurityRule {
+	switch {
+	case len(sources) == 1:
+		rule.SourceAddressPrefix = to.StringPtr(sources[0])
+		rule.SourceAddressPrefixes = nil
+	case len(sources) > 1:
+		rule.SourceAddressPrefix = nil
+		rule.SourceAddressPrefixes = to.StringSlicePtr(append([]string{}, sources...))
+	}
+	return rule
+}
+
+// assignPriorities assigns a priority to the rules without one, in declaration order within each direction, starting at
+// the start of the direction in steps of the step of the assignment, firstAutoRulePriority and autoRulePriorityStep by
+// default. Priorities set explicitly are skipped, including the one reserved for the deny all outbound rule. The
+// priorities between the assigned ones are left to rules with an explicit priority, so that such rules can be inserted
+// without renumbering the others. The assignment only depends on the rules so that it is the same on every reconcile.
+func assignPriorities(rules []network.SecurityRule, assignment *infrav1.SecurityRulePriorityAssignment) {
+	step := autoRulePriorityStep
+	start := map[network.SecurityRuleDirection]int32{
+		network.SecurityRuleDirectionInbound:  firstAutoRulePriority,
+		network.SecurityRuleDirectionOutbound: firstAutoRulePriority,
+	}
+	if assignment != nil {
+		if assignment.Step > 0 {
+			step = assignment.Step
+		}
+		if assignment.InboundStart != 0 {
+			start[network.SecurityRuleDirectionInbound] = assignment.InboundStart
+		}
+		if assignment.OutboundStart != 0 {
+			start[network.SecurityRuleDirectionOutbound] = assignment.OutboundStart
+		}
+	}
+
+	taken := make(map[network.SecurityRuleDirection]map[int32]bool)
+	for _, rule := range rules {
+		if priority := to.Int32(rule.Priority); priority != 0 {
+			if taken[rule.Direction] == nil {
+				taken[rule.Direction] = make(map[int32]bool)
+			}
+			taken[rule.Direction][priority] = true
+		}
+	}
+
+	next := make(map[network.SecurityRuleDirection]int32)
+	for i := range rules {
+		rule := &rules[i]
+		if to.Int32(rule.Priority) != 0 {
+			continue
+		}
+		priority, ok := next[rule.Direction]
+		if !ok {
+			priority = start[rule.Direction]
+		}
+		for taken[rule.Direction][priority] {
+			priority += step
+		}
+		rule.Priority = to.Int32Ptr(priority)
+		next[rule.Direction] = priority + step
+	}
+}
+
+// managedRule marks the rule as managed by CAPZ by adding the managed rule tag to its description. Descriptions longer
+// than infrav1.MaxSecurityRuleDescriptionLength are rejected by Validate, so that the tagged description stays within
+// the length accepted by Azure.
+func managedRule(rule network.SecurityRule) network.SecurityRule {
+	if rule.SecurityRulePropertiesFormat == nil {
+		rule.SecurityRulePropertiesFormat = &network.SecurityRulePropertiesFormat{}
+	}
+	description := strings.TrimSpace(to.String(rule.Description))
+	if description != "" {
+		description += " "
+	}
+	rule.Description = to.StringPtr(description + managedRuleTag)
+	return rule
+}
+
+// isManagedRule returns true if the rule is marked as managed by CAPZ.
+func isManagedRule(rule network.SecurityRule) bool {
+	return rule.SecurityRulePropertiesFormat != nil && strings.HasSuffix(to.String(rule.Description), managedRuleTag)
+}
+
+// denyAllOutboundRule returns a rule that denies all outbound traffic. It uses the priority reserved for it, the lowest
+// user priority, so that any outbound allow rule takes precedence over it. A deny rule with a higher precedence would
+// shadow the allow rules, since Azure stops at the first matching rule.
+func denyAllOutboundRule() network.SecurityRule {
+	return network.SecurityRule{
+		Name: to.StringPtr(denyAllOutboundRuleName),
+		SecurityRulePropertiesFormat: &network.SecurityRulePropertiesFormat{
+			Description:              to.StringPtr("Deny all outbound traffic"),
+			Protocol:                 network.SecurityRuleProtocolAsterisk,
+			SourceAddressPrefix:      to.StringPtr("*"),
+			SourcePortRange:          to.StringPtr("*"),
+			DestinationAddressPrefix: to.StringPtr("*"),
+			DestinationPortRange:     to.StringPtr("*"),
+			Access:                   network.SecurityRuleAccessDeny,
+			Priority:                 to.Int32Ptr(infrav1.DenyAllOutboundRulePriority),
+			Direction:                network.SecurityRuleDirectionOutbound,
+		},
+	}
+}
+
+// ruleExists returns true if one of the rules is up to date with the given rule, i.e. it has the same name, priority,
+// protocol, access, direction, port ranges, address prefixes, application security groups and description. A rule
+// whose description is not marked as managed by CAPZ, e.g. created by an earlier release, is up to date if its
+// description is the one of the given rule without the marker, so that upgrading does not update every rule. A foreign
+// rule with the same name and other properties is out of date so that CAPZ takes it over.
 func ruleExists(rules []network.SecurityRule, rule network.SecurityRule) bool {
 	for _, existingRule := range rules {
-		if !strings.EqualFold(to.String(existingRule.Name), to.String(rule.Name)) {
+		if !strings.EqualFold(to.String(existingRule.Name), to.String(rule.Name)) || existingRule.SecurityRulePropertiesFormat == nil {
+			continue
+		}
+		if to.Int32(existingRule.Priority) != to.Int32(rule.Priority) ||
+			existingRule.Protocol != rule.Protocol ||
+			existingRule.Access != rule.Access ||
+			existingRule.Direction != rule.Direction {
+			continue
+		}
+		if !strings.EqualFold(to.String(existingRule.SourcePortRange), to.String(rule.SourcePortRange)) ||
+			!strings.EqualFold(to.String(existingRule.DestinationPortRange), to.String(rule.DestinationPortRange)) ||
+			!strings.EqualFold(to.String(existingRule.SourceAddressPrefix), to.String(rule.SourceAddressPrefix)) ||
+			!strings.EqualFold(to.String(existingRule.DestinationAddressPrefix), to.String(rule.DestinationAddressPrefix)) {
 			continue
 		}
-		if !strings.EqualFold(to.String(existingRule.DestinationPortRange), to.String(rule.DestinationPortRange)) {
+		if !sameValues(existingRule.SourcePortRanges, rule.SourcePortRanges) ||
+			!sameValues(existingRule.DestinationPortRanges, rule.DestinationPortRanges) ||
+			!sameValues(existingRule.SourceAddressPrefixes, rule.SourceAddressPrefixes) ||
+			!sameValues(existingRule.DestinationAddressPrefixes, rule.DestinationAddressPrefixes) ||
+			!sameValues(applicationSecurityGroupIDs(existingRule.SourceApplicationSecurityGroups), applicationSecurityGroupIDs(rule.SourceApplicationSecurityGroups)) ||
+			!sameValues(applicationSecurityGroupIDs(existingRule.DestinationApplicationSecurityGroups), applicationSecurityGroupIDs(rule.DestinationApplicationSecurityGroups)) {
 			continue
 		}
-		if existingRule.Protocol != network.SecurityRuleProtocolTCP &&
-			existingRule.Access != network.SecurityRuleAccessAllow &&
-			existingRule.Direction != network.SecurityRuleDirectionInbound {
+		if isManagedRule(existingRule) && to.String(existingRule.Description) != to.String(rule.Description) {
 			continue
 		}
-		if !strings.EqualFold(to.String(existingRule.SourcePortRange), "*") &&
-			!strings.EqualFold(to.String(existingRule.SourceAddressPrefix), "*") &&
-			!strings.EqualFold(to.String(existingRule.DestinationAddressPrefix), "*") {
+		if !isManagedRule(existingRule) && strings.TrimSpace(to.String(existingRule.Description)) != unmarkedDescription(rule) {
 			continue
 		}
 		return true
 	}
 	return false
 }
+
+// unmarkedDescription returns the description of a rule managed by CAPZ without the managed rule tag.
+func unmarkedDescription(rule network.SecurityRule) string {
+	return strings.TrimSpace(strings.TrimSuffix(to.String(rule.Description), managedRuleTag))
+}
+
+// applicationSecurityGroupIDs returns the IDs of the application security groups.
+func applicationSecurityGroupIDs(groups *[]network.ApplicationSecurityGroup) *[]string {
+	if groups == nil {
+		return nil
+	}
+	ids := make([]string, len(*groups))
+	for i, group := range *groups {
+		ids[i] = to.String(group.ID)
+	}
+	return &ids
+}
+
+// sameValues returns true if both lists hold the same values in any order, ignoring case.
+// A nil list is the same as an empty one.
+func sameValues(a, b *[]string) bool {
+	var x, y []string
+	if a != nil {
+		x = *a
+	}
+	if b != nil {
+		y = *b
+	}
+	if len(x) != len(y) {
+		return false
+	}
+	x, y = sortedLower(x), sortedLower(y)
+	for i := range x {
+		if x[i] != y[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// sortedLower returns a sorted copy of the values in lower case.
+func sortedLower(values []string) []string {
+	lower := make([]string, len(values))
+	for i, value := range values {
+		lower[i] = strings.ToLower(value)
+	}
+	sort.Strings(lower)
+	return lower
+}
+
+// ruleNames returns the names of the rules.
+func ruleNames(rules []network.SecurityRule) []string {
+	names := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		names = append(names, to.String(rule.Name))
+	}
+	return names
+}
+
+// ruleNamed returns true if one of the rules has the given name.
+func ruleNamed(rules []network.SecurityRule, name string) bool {
+	for _, rule := range rules {
+		if strings.EqualFold(to.String(rule.Name), name) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsFold returns true if the list contains the given name, ignoring case.
+func containsFold(names []string, name string) bool {
+	for _, n := range names {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
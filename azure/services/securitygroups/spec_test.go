@@ -17,12 +17,14 @@ limitations under the License.
 package securitygroups
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-02-01/network"
 	"github.com/Azure/go-autorest/autorest/to"
 	. "github.com/onsi/gomega"
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/converters"
 )
 
@@ -49,6 +51,18 @@ var (
 		Destination:      to.StringPtr("*"),
 		DestinationPorts: to.StringPtr("80"),
 	}
+	// foreignRule is a rule added to the security group by other means than CAPZ, e.g. manually by an administrator.
+	foreignRule = converters.SecurityRuleToSDK(infrav1.SecurityRule{
+		Name:             "allow_admin_rdp",
+		Description:      "Allow RDP from the admin network",
+		Priority:         1000,
+		Protocol:         infrav1.SecurityGroupProtocolTCP,
+		Direction:        infrav1.SecurityRuleDirectionInbound,
+		Source:           to.StringPtr("10.10.0.0/16"),
+		SourcePorts:      to.StringPtr("*"),
+		Destination:      to.StringPtr("*"),
+		DestinationPorts: to.StringPtr("3389"),
+	})
 	customRule = infrav1.SecurityRule{
 		Name:             "custom_rule",
 		Description:      "Test Rule",
@@ -60,6 +74,20 @@ var (
 		Destination:      to.StringPtr("*"),
 		DestinationPorts: to.StringPtr("80"),
 	}
+	// nodeSubnetRule is a rule whose destination is the node subnet of the cluster.
+	nodeSubnetRule = infrav1.SecurityRule{
+		Name:              "allow_node_subnet",
+		Description:       "Allow HTTPS to the nodes",
+		Priority:          2400,
+		Protocol:          infrav1.SecurityGroupProtocolTCP,
+		Direction:         infrav1.SecurityRuleDirectionInbound,
+		Source:            to.StringPtr("*"),
+		SourcePorts:       to.StringPtr("*"),
+		DestinationPorts:  to.StringPtr("443"),
+		DestinationSubnet: "node-subnet",
+	}
+	// nodePortsRule is an augmented rule allowing multiple ports from multiple prefixes.
+	nodePortsRule = nodePortsRuleWith([]string{"80", "443"}, []string{"10.0.0.0/16", "10.1.0.0/16"})
 )
 
 func TestParameters(t *testing.T) {
@@ -85,8 +113,8 @@ func TestParameters(t *testing.T) {
 				Name: to.StringPtr("test-nsg"),
 				SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
 					SecurityRules: &[]network.SecurityRule{
-						converters.SecurityRuleToSDK(sshRule),
-						converters.SecurityRuleToSDK(otherRule),
+						sdkRule(sshRule),
+						sdkRule(otherRule),
 					},
 				},
 			},
@@ -111,8 +139,8 @@ func TestParameters(t *testing.T) {
 				Etag:     to.StringPtr("fake-etag"),
 				SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
 					SecurityRules: &[]network.SecurityRule{
-						converters.SecurityRuleToSDK(sshRule),
-						converters.SecurityRuleToSDK(customRule),
+						sdkRule(sshRule),
+						sdkRule(customRule),
 					},
 				},
 			},
@@ -123,19 +151,91 @@ func TestParameters(t *testing.T) {
 					Etag:     to.StringPtr("fake-etag"),
 					SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
 						SecurityRules: &[]network.SecurityRule{
-							converters.SecurityRuleToSDK(sshRule),
-							converters.SecurityRuleToSDK(customRule),
-							converters.SecurityRuleToSDK(otherRule),
+							sdkRule(sshRule),
+							sdkRule(customRule),
+							sdkRule(otherRule),
 						},
 					},
 				}))
 			},
 		},
 		{
-			name: "NSG does not exist",
+			name: "NSG already exists with an out of date rule",
+			spec: &NSGSpec{
+				Name:     "test-nsg",
+				Location: "test-location",
+				SecurityRules: infrav1.SecurityRules{
+					sshRuleWithPriority(2300),
+					otherRule,
+				},
+				ResourceGroup: "test-group",
+			},
+			existing: network.SecurityGroup{
+				Name:     to.StringPtr("test-nsg"),
+				Location: to.StringPtr("test-location"),
+				Etag:     to.StringPtr("fake-etag"),
+				SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
+					SecurityRules: &[]network.SecurityRule{
+						sdkRule(sshRule),
+						sdkRule(otherRule),
+					},
+				},
+			},
+			expect: func(g *WithT, result interface{}) {
+				g.Expect(result).To(Equal(network.SecurityGroup{
+					Location: to.StringPtr("test-location"),
+					Etag:     to.StringPtr("fake-etag"),
+					SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
+						SecurityRules: &[]network.SecurityRule{
+							sdkRule(otherRule),
+							sdkRule(sshRuleWithPriority(2300)),
+						},
+					},
+				}))
+			},
+		},
+		{
+			name: "NSG already exists with a rule whose description changed",
 			spec: &NSGSpec{
 				Name:     "test-nsg",
 				Location: "test-location",
+				SecurityRules: infrav1.SecurityRules{
+					sshRuleWithDescription("Allow SSH from the bastion"),
+					otherRule,
+				},
+				ResourceGroup: "test-group",
+			},
+			existing: network.SecurityGroup{
+				Name:     to.StringPtr("test-nsg"),
+				Location: to.StringPtr("test-location"),
+				Etag:     to.StringPtr("fake-etag"),
+				SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
+					SecurityRules: &[]network.SecurityRule{
+						sdkRule(sshRule),
+						sdkRule(otherRule),
+					},
+				},
+			},
+			expect: func(g *WithT, result interface{}) {
+				g.Expect(result).To(Equal(network.SecurityGroup{
+					Location: to.StringPtr("test-location"),
+					Etag:     to.StringPtr("fake-etag"),
+					SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
+						SecurityRules: &[]network.SecurityRule{
+							sdkRule(otherRule),
+							sdkRule(sshRuleWithDescription("Allow SSH from the bastion")),
+						},
+					},
+				}))
+			},
+		},
+		{
+			name: "NSG does not exist",
+			spec: &NSGSpec{
+				Name:           "test-nsg",
+				Location:       "test-location",
+				ClusterName:    "test-cluster",
+				AdditionalTags: infrav1.Tags{"cost-center": "1234"},
 				SecurityRules: infrav1.SecurityRules{
 					sshRule,
 					otherRule,
@@ -148,67 +248,1268 @@ func TestParameters(t *testing.T) {
 				g.Expect(result).To(Equal(network.SecurityGroup{
 					SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
 						SecurityRules: &[]network.SecurityRule{
-							converters.SecurityRuleToSDK(sshRule),
-							converters.SecurityRuleToSDK(otherRule),
+							sdkRule(sshRule),
+							sdkRule(otherRule),
 						},
 					},
+					Tags:     ownedNSGTagsWith(map[string]*string{"cost-center": to.StringPtr("1234")}),
 					Location: to.StringPtr("test-location"),
 				}))
 			},
 		},
-	}
-
-	for _, tc := range testcases {
-		tc := tc
-		t.Run(tc.name, func(t *testing.T) {
-			g := NewWithT(t)
-			t.Parallel()
-
-			result, err := tc.spec.Parameters(tc.existing)
-			if tc.expectedError != "" {
-				g.Expect(err).To(HaveOccurred())
-				g.Expect(err).To(MatchError(tc.expectedError))
-			} else {
-				g.Expect(err).NotTo(HaveOccurred())
-			}
-			tc.expect(g, result)
-		})
-	}
-}
-
-func TestRuleExists(t *testing.T) {
-	testcases := []struct {
-		name     string
-		rules    []network.SecurityRule
-		rule     network.SecurityRule
-		expected bool
-	}{
 		{
-			name:     "rule doesn't exitst",
-			rules:    []network.SecurityRule{ruleA},
-			rule:     ruleB,
-			expected: false,
+			name: "NSG does not exist and has an ICMP rule",
+			spec: &NSGSpec{
+				Name:        "test-nsg",
+				Location:    "test-location",
+				ClusterName: "test-cluster",
+				SecurityRules: infrav1.SecurityRules{
+					{
+						Name:             "allow_ping",
+						Description:      "Allow ping",
+						Priority:         2300,
+						Protocol:         infrav1.SecurityGroupProtocolICMP,
+						Direction:        infrav1.SecurityRuleDirectionInbound,
+						Source:           to.StringPtr("10.0.0.0/16"),
+						Destination:      to.StringPtr("*"),
+						DestinationPorts: to.StringPtr("*"),
+					},
+				},
+				ResourceGroup: "test-group",
+			},
+			existing: nil,
+			expect: func(g *WithT, result interface{}) {
+				g.Expect(result).To(BeAssignableToTypeOf(network.SecurityGroup{}))
+				g.Expect(result).To(Equal(network.SecurityGroup{
+					SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
+						SecurityRules: &[]network.SecurityRule{
+							{
+								Name: to.StringPtr("allow_ping"),
+								SecurityRulePropertiesFormat: &network.SecurityRulePropertiesFormat{
+									Description:              to.StringPtr("Allow ping (managed by capz)"),
+									Protocol:                 network.SecurityRuleProtocolIcmp,
+									SourceAddressPrefix:      to.StringPtr("10.0.0.0/16"),
+									SourcePortRange:          to.StringPtr("*"),
+									DestinationAddressPrefix: to.StringPtr("*"),
+									DestinationPortRange:     to.StringPtr("*"),
+									Access:                   network.SecurityRuleAccessAllow,
+									Priority:                 to.Int32Ptr(2300),
+									Direction:                network.SecurityRuleDirectionInbound,
+								},
+							},
+						},
+					},
+					Tags:     ownedNSGTagsWith(nil),
+					Location: to.StringPtr("test-location"),
+				}))
+			},
 		},
 		{
-			name:     "rule exists",
-			rules:    []network.SecurityRule{ruleA, ruleB},
-			rule:     ruleB,
-			expected: true,
+			name: "NSG does not exist and has IPsec rules",
+			spec: &NSGSpec{
+				Name:        "test-nsg",
+				Location:    "test-location",
+				ClusterName: "test-cluster",
+				SecurityRules: infrav1.SecurityRules{
+					{
+						Name:        "allow_ipsec_esp",
+						Description: "Allow IPsec ESP",
+						Priority:    2300,
+						Protocol:    infrav1.SecurityGroupProtocolESP,
+						Direction:   infrav1.SecurityRuleDirectionInbound,
+						Source:      to.StringPtr("10.0.0.0/16"),
+						Destination: to.StringPtr("*"),
+					},
+					{
+						Name:        "allow_ipsec_ah",
+						Description: "Allow IPsec AH",
+						Priority:    2310,
+						Protocol:    infrav1.SecurityGroupProtocolAH,
+						Direction:   infrav1.SecurityRuleDirectionInbound,
+						Source:      to.StringPtr("10.0.0.0/16"),
+						Destination: to.StringPtr("*"),
+					},
+				},
+				ResourceGroup: "test-group",
+			},
+			existing: nil,
+			expect: func(g *WithT, result interface{}) {
+				g.Expect(result).To(BeAssignableToTypeOf(network.SecurityGroup{}))
+				g.Expect(result).To(Equal(network.SecurityGroup{
+					SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
+						SecurityRules: &[]network.SecurityRule{
+							{
+								Name: to.StringPtr("allow_ipsec_esp"),
+								SecurityRulePropertiesFormat: &network.SecurityRulePropertiesFormat{
+									Description:              to.StringPtr("Allow IPsec ESP (managed by capz)"),
+									Protocol:                 network.SecurityRuleProtocolEsp,
+									SourceAddressPrefix:      to.StringPtr("10.0.0.0/16"),
+									SourcePortRange:          to.StringPtr("*"),
+									DestinationAddressPrefix: to.StringPtr("*"),
+									DestinationPortRange:     to.StringPtr("*"),
+									Access:                   network.SecurityRuleAccessAllow,
+									Priority:                 to.Int32Ptr(2300),
+									Direction:                network.SecurityRuleDirectionInbound,
+								},
+							},
+							{
+								Name: to.StringPtr("allow_ipsec_ah"),
+								SecurityRulePropertiesFormat: &network.SecurityRulePropertiesFormat{
+									Description:              to.StringPtr("Allow IPsec AH (managed by capz)"),
+									Protocol:                 network.SecurityRuleProtocolAh,
+									SourceAddressPrefix:      to.StringPtr("10.0.0.0/16"),
+									SourcePortRange:          to.StringPtr("*"),
+									DestinationAddressPrefix: to.StringPtr("*"),
+									DestinationPortRange:     to.StringPtr("*"),
+									Access:                   network.SecurityRuleAccessAllow,
+									Priority:                 to.Int32Ptr(2310),
+									Direction:                network.SecurityRuleDirectionInbound,
+								},
+							},
+						},
+					},
+					Tags:     ownedNSGTagsWith(nil),
+					Location: to.StringPtr("test-location"),
+				}))
+			},
 		},
 		{
-			name:     "rule exists but has been modified",
-			rules:    []network.SecurityRule{ruleA, ruleB},
-			rule:     ruleBModified,
-			expected: false,
+			name: "NSG does not exist and has a dual-stack rule",
+			spec: &NSGSpec{
+				Name:     "test-nsg",
+				Location: "test-location",
+				SecurityRules: infrav1.SecurityRules{
+					nodePortsRuleWith([]string{"80", "443"}, []string{"10.0.0.0/16", "2001:db8::/64"}),
+				},
+				ResourceGroup: "test-group",
+			},
+			existing: nil,
+			expect: func(g *WithT, result interface{}) {
+				g.Expect(result).To(BeAssignableToTypeOf(network.SecurityGroup{}))
+				nsg := result.(network.SecurityGroup)
+				g.Expect(*nsg.SecurityRules).To(HaveLen(1))
+				rule := (*nsg.SecurityRules)[0]
+				g.Expect(rule.SourceAddressPrefix).To(BeNil())
+				g.Expect(*rule.SourceAddressPrefixes).To(Equal([]string{"10.0.0.0/16", "2001:db8::/64"}))
+			},
 		},
+		{
+			name: "NSG does not exist and default deny outbound is enabled",
+			spec: &NSGSpec{
+				Name:        "test-nsg",
+				Location:    "test-location",
+				ClusterName: "test-cluster",
+				SecurityRules: infrav1.SecurityRules{
+					sshRule,
+					customRule,
+				},
+				ResourceGroup:       "test-group",
+				DefaultDenyOutbound: true,
+			},
+			existing: nil,
+			expect: func(g *WithT, result interface{}) {
+				g.Expect(result).To(BeAssignableToTypeOf(network.SecurityGroup{}))
+				g.Expect(result).To(Equal(network.SecurityGroup{
+					SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
+						SecurityRules: &[]network.SecurityRule{
+							sdkRule(sshRule),
+							sdkRule(customRule),
+							managedRule(denyAllOutboundRule()),
+						},
+					},
+					Tags:     ownedNSGTagsWith(nil),
+					Location: to.StringPtr("test-location"),
+				}))
+			},
+		},
+		{
+			name: "NSG already exists without the deny outbound rule and default deny outbound is enabled",
+			spec: &NSGSpec{
+				Name:     "test-nsg",
+				Location: "test-location",
+				SecurityRules: infrav1.SecurityRules{
+					sshRule,
+				},
+				ResourceGroup:       "test-group",
+				DefaultDenyOutbound: true,
+			},
+			existing: network.SecurityGroup{
+				Name:     to.StringPtr("test-nsg"),
+				Location: to.StringPtr("test-location"),
+				Etag:     to.StringPtr("fake-etag"),
+				SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
+					SecurityRules: &[]network.SecurityRule{
+						sdkRule(sshRule),
+					},
+				},
+			},
+			expect: func(g *WithT, result interface{}) {
+				g.Expect(result).To(Equal(network.SecurityGroup{
+					Location: to.StringPtr("test-location"),
+					Etag:     to.StringPtr("fake-etag"),
+					SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
+						SecurityRules: &[]network.SecurityRule{
+							sdkRule(sshRule),
+							managedRule(denyAllOutboundRule()),
+						},
+					},
+				}))
+			},
+		},
+		{
+			name: "NSG already exists with the deny outbound rule and default deny outbound is enabled",
+			spec: &NSGSpec{
+				Name:     "test-nsg",
+				Location: "test-location",
+				SecurityRules: infrav1.SecurityRules{
+					sshRule,
+				},
+				ResourceGroup:       "test-group",
+				DefaultDenyOutbound: true,
+			},
+			existing: network.SecurityGroup{
+				Name: to.StringPtr("test-nsg"),
+				SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
+					SecurityRules: &[]network.SecurityRule{
+						sdkRule(sshRule),
+						managedRule(denyAllOutboundRule()),
+					},
+				},
+			},
+			expect: func(g *WithT, result interface{}) {
+				g.Expect(result).To(BeNil())
+			},
+		},
+		{
+			name: "NSG already exists with all rules present but an additional tag was removed",
+			spec: &NSGSpec{
+				Name:           "test-nsg",
+				Location:       "test-location",
+				ClusterName:    "test-cluster",
+				AdditionalTags: infrav1.Tags{"cost-center": "1234", "environment": "prod"},
+				SecurityRules: infrav1.SecurityRules{
+					sshRule,
+				},
+				ResourceGroup: "test-group",
+			},
+			existing: network.SecurityGroup{
+				Name:     to.StringPtr("test-nsg"),
+				Location: to.StringPtr("test-location"),
+				Etag:     to.StringPtr("fake-etag"),
+				Tags: map[string]*string{
+					"sigs.k8s.io_cluster-api-provider-azure_cluster_test-cluster": to.StringPtr("owned"),
+					"environment": to.StringPtr("dev"),
+					"team":        to.StringPtr("networking"),
+				},
+				SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
+					SecurityRules: &[]network.SecurityRule{
+						sdkRule(sshRule),
+					},
+				},
+			},
+			expect: func(g *WithT, result interface{}) {
+				g.Expect(result).To(Equal(network.SecurityGroup{
+					Tags: map[string]*string{
+						"sigs.k8s.io_cluster-api-provider-azure_cluster_test-cluster": to.StringPtr("owned"),
+						"cost-center": to.StringPtr("1234"),
+						"environment": to.StringPtr("prod"),
+						"team":        to.StringPtr("networking"),
+					},
+					Location: to.StringPtr("test-location"),
+					SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
+						SecurityRules: &[]network.SecurityRule{
+							sdkRule(sshRule),
+						},
+					},
+					Etag: to.StringPtr("fake-etag"),
+				}))
+			},
+		},
+		{
+			name: "NSG already exists with all rules present, an additional tag was changed and tag drift is ignored",
+			spec: &NSGSpec{
+				Name:           "test-nsg",
+				Location:       "test-location",
+				ClusterName:    "test-cluster",
+				AdditionalTags: infrav1.Tags{"cost-center": "1234", "environment": "prod"},
+				IgnoreTagDrift: true,
+				SecurityRules: infrav1.SecurityRules{
+					sshRule,
+				},
+				ResourceGroup: "test-group",
+			},
+			existing: network.SecurityGroup{
+				Name: to.StringPtr("test-nsg"),
+				Tags: map[string]*string{
+					"sigs.k8s.io_cluster-api-provider-azure_cluster_test-cluster": to.StringPtr("owned"),
+					"cost-center": to.StringPtr("1234"),
+					"environment": to.StringPtr("dev"),
+				},
+				SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
+					SecurityRules: &[]network.SecurityRule{
+						sdkRule(sshRule),
+					},
+				},
+			},
+			expect: func(g *WithT, result interface{}) {
+				g.Expect(result).To(BeNil())
+			},
+		},
+		{
+			name: "NSG already exists missing a rule, an additional tag was changed and tag drift is ignored",
+			spec: &NSGSpec{
+				Name:           "test-nsg",
+				Location:       "test-location",
+				ClusterName:    "test-cluster",
+				AdditionalTags: infrav1.Tags{"environment": "prod"},
+				IgnoreTagDrift: true,
+				SecurityRules: infrav1.SecurityRules{
+					sshRule,
+				},
+				ResourceGroup: "test-group",
+			},
+			existing: network.SecurityGroup{
+				Name:     to.StringPtr("test-nsg"),
+				Location: to.StringPtr("test-location"),
+				Etag:     to.StringPtr("fake-etag"),
+				Tags: map[string]*string{
+					"sigs.k8s.io_cluster-api-provider-azure_cluster_test-cluster": to.StringPtr("owned"),
+					"environment": to.StringPtr("dev"),
+				},
+				SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
+					SecurityRules: &[]network.SecurityRule{},
+				},
+			},
+			expect: func(g *WithT, result interface{}) {
+				g.Expect(result).To(Equal(network.SecurityGroup{
+					Tags: map[string]*string{
+						"sigs.k8s.io_cluster-api-provider-azure_cluster_test-cluster": to.StringPtr("owned"),
+						"environment": to.StringPtr("prod"),
+					},
+					Location: to.StringPtr("test-location"),
+					SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
+						SecurityRules: &[]network.SecurityRule{
+							sdkRule(sshRule),
+						},
+					},
+					Etag: to.StringPtr("fake-etag"),
+				}))
+			},
+		},
+		{
+			name: "NSG already exists with all rules and additional tags present",
+			spec: &NSGSpec{
+				Name:           "test-nsg",
+				Location:       "test-location",
+				ClusterName:    "test-cluster",
+				AdditionalTags: infrav1.Tags{"cost-center": "1234"},
+				SecurityRules: infrav1.SecurityRules{
+					sshRule,
+				},
+				ResourceGroup: "test-group",
+			},
+			existing: network.SecurityGroup{
+				Name: to.StringPtr("test-nsg"),
+				Tags: map[string]*string{
+					"sigs.k8s.io_cluster-api-provider-azure_cluster_test-cluster": to.StringPtr("owned"),
+					"cost-center": to.StringPtr("1234"),
+					"team":        to.StringPtr("networking"),
+				},
+				SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
+					SecurityRules: &[]network.SecurityRule{
+						sdkRule(sshRule),
+					},
+				},
+			},
+			expect: func(g *WithT, result interface{}) {
+				g.Expect(result).To(BeNil())
+			},
+		},
+		{
+			name: "NSG does not exist and default deny outbound is disabled",
+			spec: &NSGSpec{
+				Name:        "test-nsg",
+				Location:    "test-location",
+				ClusterName: "test-cluster",
+				SecurityRules: infrav1.SecurityRules{
+					customRule,
+				},
+				ResourceGroup: "test-group",
+			},
+			existing: nil,
+			expect: func(g *WithT, result interface{}) {
+				g.Expect(result).To(Equal(network.SecurityGroup{
+					SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
+						SecurityRules: &[]network.SecurityRule{
+							sdkRule(customRule),
+						},
+					},
+					Tags:     ownedNSGTagsWith(nil),
+					Location: to.StringPtr("test-location"),
+				}))
+			},
+		},
+		{
+			name: "NSG already exists with a rule that is not in the spec and rules are pruned",
+			spec: &NSGSpec{
+				Name:     "test-nsg",
+				Location: "test-location",
+				SecurityRules: infrav1.SecurityRules{
+					sshRule,
+					otherRule,
+				},
+				ResourceGroup: "test-group",
+				PruneRules:    true,
+			},
+			existing: network.SecurityGroup{
+				Name:     to.StringPtr("test-nsg"),
+				Location: to.StringPtr("test-location"),
+				Etag:     to.StringPtr("fake-etag"),
+				SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
+					SecurityRules: &[]network.SecurityRule{
+						sdkRule(sshRule),
+						sdkRule(customRule),
+					},
+				},
+			},
+			expect: func(g *WithT, result interface{}) {
+				g.Expect(result).To(Equal(network.SecurityGroup{
+					Location: to.StringPtr("test-location"),
+					Etag:     to.StringPtr("fake-etag"),
+					SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
+						SecurityRules: &[]network.SecurityRule{
+							sdkRule(sshRule),
+							sdkRule(otherRule),
+						},
+					},
+				}))
+			},
+		},
+		{
+			name: "NSG already exists with owned and foreign rules that are not in the spec and rules are pruned",
+			spec: &NSGSpec{
+				Name:     "test-nsg",
+				Location: "test-location",
+				SecurityRules: infrav1.SecurityRules{
+					sshRule,
+					otherRule,
+				},
+				ResourceGroup: "test-group",
+				PruneRules:    true,
+			},
+			existing: network.SecurityGroup{
+				Name:     to.StringPtr("test-nsg"),
+				Location: to.StringPtr("test-location"),
+				Etag:     to.StringPtr("fake-etag"),
+				SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
+					SecurityRules: &[]network.SecurityRule{
+						sdkRule(sshRule),
+						foreignRule,
+						sdkRule(customRule),
+					},
+				},
+			},
+			expect: func(g *WithT, result interface{}) {
+				g.Expect(result).To(Equal(network.SecurityGroup{
+					Location: to.StringPtr("test-location"),
+					Etag:     to.StringPtr("fake-etag"),
+					SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
+						SecurityRules: &[]network.SecurityRule{
+							sdkRule(sshRule),
+							foreignRule,
+							sdkRule(otherRule),
+						},
+					},
+				}))
+			},
+		},
+		{
+			name: "NSG already exists with only foreign rules in addition to the spec and rules are pruned",
+			spec: &NSGSpec{
+				Name:     "test-nsg",
+				Location: "test-location",
+				SecurityRules: infrav1.SecurityRules{
+					sshRule,
+				},
+				ResourceGroup: "test-group",
+				PruneRules:    true,
+			},
+			existing: network.SecurityGroup{
+				Name:     to.StringPtr("test-nsg"),
+				Location: to.StringPtr("test-location"),
+				Etag:     to.StringPtr("fake-etag"),
+				SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
+					SecurityRules: &[]network.SecurityRule{
+						foreignRule,
+						sdkRule(sshRule),
+					},
+				},
+			},
+			expect: func(g *WithT, result interface{}) {
+				g.Expect(result).To(BeNil())
+			},
+		},
+		{
+			name: "NSG already exists with a foreign rule named like a rule of the spec",
+			spec: &NSGSpec{
+				Name:     "test-nsg",
+				Location: "test-location",
+				SecurityRules: infrav1.SecurityRules{
+					sshRule,
+				},
+				ResourceGroup: "test-group",
+			},
+			existing: network.SecurityGroup{
+				Name:     to.StringPtr("test-nsg"),
+				Location: to.StringPtr("test-location"),
+				Etag:     to.StringPtr("fake-etag"),
+				SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
+					SecurityRules: &[]network.SecurityRule{
+						foreignRule,
+						converters.SecurityRuleToSDK(sshRuleWithSource("10.0.0.0/16")),
+					},
+				},
+			},
+			expect: func(g *WithT, result interface{}) {
+				g.Expect(result).To(Equal(network.SecurityGroup{
+					Location: to.StringPtr("test-location"),
+					Etag:     to.StringPtr("fake-etag"),
+					SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
+						SecurityRules: &[]network.SecurityRule{
+							foreignRule,
+							sdkRule(sshRule),
+						},
+					},
+				}))
+			},
+		},
+		{
+			name: "NSG already exists with a rule that is not in the spec and additive safe mode holds its deletion",
+			spec: &NSGSpec{
+				Name:     "test-nsg",
+				Location: "test-location",
+				SecurityRules: infrav1.SecurityRules{
+					sshRule,
+					otherRule,
+				},
+				ResourceGroup:    "test-group",
+				PruneRules:       true,
+				AdditiveSafeMode: true,
+			},
+			existing: network.SecurityGroup{
+				Name:     to.StringPtr("test-nsg"),
+				Location: to.StringPtr("test-location"),
+				Etag:     to.StringPtr("fake-etag"),
+				SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
+					SecurityRules: &[]network.SecurityRule{
+						sdkRule(sshRule),
+						sdkRule(customRule),
+					},
+				},
+			},
+			expect: func(g *WithT, result interface{}) {
+				g.Expect(result).To(Equal(network.SecurityGroup{
+					Location: to.StringPtr("test-location"),
+					Etag:     to.StringPtr("fake-etag"),
+					SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
+						SecurityRules: &[]network.SecurityRule{
+							sdkRule(sshRule),
+							sdkRule(customRule),
+							sdkRule(otherRule),
+						},
+					},
+				}))
+			},
+		},
+		{
+			name: "NSG already exists with a rule that is not in the spec and its deletion is confirmed in additive safe mode",
+			spec: &NSGSpec{
+				Name:     "test-nsg",
+				Location: "test-location",
+				SecurityRules: infrav1.SecurityRules{
+					sshRule,
+					otherRule,
+				},
+				ResourceGroup:          "test-group",
+				PruneRules:             true,
+				AdditiveSafeMode:       true,
+				ConfirmedRuleDeletions: []string{"custom_rule"},
+			},
+			existing: network.SecurityGroup{
+				Name:     to.StringPtr("test-nsg"),
+				Location: to.StringPtr("test-location"),
+				Etag:     to.StringPtr("fake-etag"),
+				SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
+					SecurityRules: &[]network.SecurityRule{
+						sdkRule(sshRule),
+						sdkRule(customRule),
+					},
+				},
+			},
+			expect: func(g *WithT, result interface{}) {
+				g.Expect(result).To(Equal(network.SecurityGroup{
+					Location: to.StringPtr("test-location"),
+					Etag:     to.StringPtr("fake-etag"),
+					SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
+						SecurityRules: &[]network.SecurityRule{
+							sdkRule(sshRule),
+							sdkRule(otherRule),
+						},
+					},
+				}))
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+
+			result, err := tc.spec.Parameters(tc.existing)
+			if tc.expectedError != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err).To(MatchError(tc.expectedError))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+			tc.expect(g, result)
+		})
+	}
+}
+
+func TestRuleChanges(t *testing.T) {
+	existing := network.SecurityGroup{
+		SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
+			SecurityRules: &[]network.SecurityRule{
+				sdkRule(sshRule),
+				foreignRule,
+				sdkRule(customRule),
+			},
+		},
+	}
+	testcases := []struct {
+		name     string
+		spec     *NSGSpec
+		expected RuleChanges
+	}{
+		{
+			name: "rules are not pruned",
+			spec: &NSGSpec{SecurityRules: infrav1.SecurityRules{sshRule, otherRule}},
+			expected: RuleChanges{
+				Additions: []network.SecurityRule{sdkRule(otherRule)},
+			},
+		},
+		{
+			name: "rules are pruned",
+			spec: &NSGSpec{SecurityRules: infrav1.SecurityRules{sshRule, otherRule}, PruneRules: true},
+			expected: RuleChanges{
+				Additions: []network.SecurityRule{sdkRule(otherRule)},
+				Deletions: []string{"custom_rule"},
+			},
+		},
+		{
+			name: "additions apply immediately while deletions are held for confirmation in additive safe mode",
+			spec: &NSGSpec{SecurityRules: infrav1.SecurityRules{sshRule, otherRule}, PruneRules: true, AdditiveSafeMode: true},
+			expected: RuleChanges{
+				Additions:     []network.SecurityRule{sdkRule(otherRule)},
+				HeldDeletions: []string{"custom_rule"},
+			},
+		},
+		{
+			name: "confirmed deletions are applied in additive safe mode",
+			spec: &NSGSpec{SecurityRules: infrav1.SecurityRules{sshRule}, PruneRules: true, AdditiveSafeMode: true, ConfirmedRuleDeletions: []string{"Custom_Rule"}},
+			expected: RuleChanges{
+				Deletions: []string{"custom_rule"},
+			},
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			g.Expect(tc.spec.RuleChanges(existing)).To(Equal(tc.expected))
+		})
+	}
+}
+
+func TestRuleNamePrefix(t *testing.T) {
+	renamed := func(rule infrav1.SecurityRule, name string) network.SecurityRule {
+		rule.Name = name
+		return sdkRule(rule)
+	}
+	securityGroup := func(rules ...network.SecurityRule) network.SecurityGroup {
+		return network.SecurityGroup{
+			SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{SecurityRules: &rules},
+		}
+	}
+	testcases := []struct {
+		name     string
+		prefix   string
+		existing network.SecurityGroup
+		expected RuleChanges
+	}{
+		{
+			name:     "rules of a new security group are named with the prefix",
+			prefix:   "corp-",
+			existing: securityGroup(),
+			expected: RuleChanges{Additions: []network.SecurityRule{renamed(sshRule, "corp-allow_ssh")}},
+		},
+		{
+			name:     "rules named with the prefix are up to date",
+			prefix:   "corp-",
+			existing: securityGroup(renamed(sshRule, "corp-allow_ssh"), foreignRule),
+		},
+		{
+			name:     "rules without a prefix are renamed",
+			prefix:   "corp-",
+			existing: securityGroup(sdkRule(sshRule), foreignRule),
+			expected: RuleChanges{
+				Additions: []network.SecurityRule{renamed(sshRule, "corp-allow_ssh")},
+				Deletions: []string{"allow_ssh"},
+			},
+		},
+		{
+			name:     "rules named with another prefix are renamed",
+			prefix:   "corp-",
+			existing: securityGroup(renamed(sshRule, "k8s.allow_ssh")),
+			expected: RuleChanges{
+				Additions: []network.SecurityRule{renamed(sshRule, "corp-allow_ssh")},
+				Deletions: []string{"k8s.allow_ssh"},
+			},
+		},
+		{
+			name:     "rules named with a prefix are renamed when the prefix is removed",
+			existing: securityGroup(renamed(sshRule, "corp-allow_ssh")),
+			expected: RuleChanges{
+				Additions: []network.SecurityRule{sdkRule(sshRule)},
+				Deletions: []string{"corp-allow_ssh"},
+			},
+		},
+		{
+			name:     "managed rules whose name only ends with the name of a rule of the spec are kept",
+			prefix:   "corp-",
+			existing: securityGroup(renamed(sshRule, "xallow_ssh")),
+			expected: RuleChanges{Additions: []network.SecurityRule{renamed(sshRule, "corp-allow_ssh")}},
+		},
+		{
+			name:     "rules of other writers are never renamed",
+			prefix:   "corp-",
+			existing: securityGroup(converters.SecurityRuleToSDK(sshRule)),
+			expected: RuleChanges{Additions: []network.SecurityRule{renamed(sshRule, "corp-allow_ssh")}},
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			spec := &NSGSpec{Name: "test-nsg", SecurityRules: infrav1.SecurityRules{sshRule}, RuleNamePrefix: tc.prefix}
+			g.Expect(spec.RuleChanges(tc.existing)).To(Equal(tc.expected))
+		})
+	}
+}
+
+func TestParametersRenamesRules(t *testing.T) {
+	g := NewWithT(t)
+	spec := &NSGSpec{Name: "test-nsg", Location: "test-location", SecurityRules: infrav1.SecurityRules{sshRule}, RuleNamePrefix: "corp-"}
+	rules := []network.SecurityRule{sdkRule(sshRule), foreignRule}
+	existing := network.SecurityGroup{
+		Location:                      to.StringPtr("test-location"),
+		SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{SecurityRules: &rules},
+	}
+
+	// The renamed rule has the priority of the rule it replaces, which is not a merge conflict.
+	result, err := spec.Parameters(existing)
+	g.Expect(err).NotTo(HaveOccurred())
+	nsg, ok := result.(network.SecurityGroup)
+	g.Expect(ok).To(BeTrue())
+	renamedRule := sdkRule(sshRule)
+	renamedRule.Name = to.StringPtr("corp-allow_ssh")
+	g.Expect(*nsg.SecurityRules).To(Equal([]network.SecurityRule{foreignRule, renamedRule}))
+}
+
+func TestParametersUpgradeFromUnmarkedRules(t *testing.T) {
+	// NSGs created before CAPZ marked the rules it manages hold the rules of the spec without the managed rule tag.
+	unmarkedNSG := func(rules ...infrav1.SecurityRule) network.SecurityGroup {
+		sdkRules := make([]network.SecurityRule, 0, len(rules))
+		for _, rule := range rules {
+			sdkRules = append(sdkRules, converters.SecurityRuleToSDK(rule))
+		}
+		return network.SecurityGroup{
+			Location:                      to.StringPtr("test-location"),
+			SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{SecurityRules: &sdkRules},
+		}
+	}
+	spec := &NSGSpec{Name: "test-nsg", Location: "test-location", SecurityRules: infrav1.SecurityRules{sshRule, otherRule}}
+
+	t.Run("unmarked rules matching the spec are up to date", func(t *testing.T) {
+		g := NewWithT(t)
+		result, err := spec.Parameters(unmarkedNSG(sshRule, otherRule))
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(result).To(BeNil())
+	})
+
+	t.Run("unmarked rules that changed are updated and marked", func(t *testing.T) {
+		g := NewWithT(t)
+		result, err := spec.Parameters(unmarkedNSG(sshRuleWithSource("10.0.0.0/16"), otherRule))
+		g.Expect(err).NotTo(HaveOccurred())
+		nsg, ok := result.(network.SecurityGroup)
+		g.Expect(ok).To(BeTrue())
+		g.Expect(*nsg.SecurityRules).To(ConsistOf(sdkRule(sshRule), converters.SecurityRuleToSDK(otherRule)))
+	})
+}
+
+func TestSubnetDestination(t *testing.T) {
+	testcases := []struct {
+		name              string
+		subnetCIDRs       map[string][]string
+		expectedPrefix    *string
+		expectedPrefixes  *[]string
+		expectedAdditions int
+	}{
+		{
+			name:             "subnet with a single CIDR block",
+			subnetCIDRs:      map[string][]string{"node-subnet": {"10.1.0.0/16"}},
+			expectedPrefix:   to.StringPtr("10.1.0.0/16"),
+			expectedPrefixes: nil,
+		},
+		{
+			name:              "subnet with multiple CIDR blocks makes an augmented rule",
+			subnetCIDRs:       map[string][]string{"node-subnet": {"10.1.0.0/16", "2001:1234:5678:9a40::/58"}},
+			expectedPrefix:    nil,
+			expectedPrefixes:  &[]string{"10.1.0.0/16", "2001:1234:5678:9a40::/58"},
+			expectedAdditions: 1,
+		},
+		{
+			name:              "subnet with changed CIDR blocks updates the rule",
+			subnetCIDRs:       map[string][]string{"node-subnet": {"10.2.0.0/16"}},
+			expectedPrefix:    to.StringPtr("10.2.0.0/16"),
+			expectedPrefixes:  nil,
+			expectedAdditions: 1,
+		},
+	}
+	// The existing rule was created when the node subnet had the 10.1.0.0/16 CIDR block.
+	existing := network.SecurityGroup{
+		SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
+			SecurityRules: &[]network.SecurityRule{
+				(&NSGSpec{SecurityRules: infrav1.SecurityRules{nodeSubnetRule}, SubnetCIDRs: map[string][]string{"node-subnet": {"10.1.0.0/16"}}}).desiredRules()[0],
+			},
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			spec := &NSGSpec{SecurityRules: infrav1.SecurityRules{nodeSubnetRule}, SubnetCIDRs: tc.subnetCIDRs}
+			g.Expect(spec.Validate()).To(Succeed())
+			rules := spec.desiredRules()
+			g.Expect(rules).To(HaveLen(1))
+			g.Expect(rules[0].DestinationAddressPrefix).To(Equal(tc.expectedPrefix))
+			g.Expect(rules[0].DestinationAddressPrefixes).To(Equal(tc.expectedPrefixes))
+			g.Expect(spec.RuleChanges(existing).Additions).To(HaveLen(tc.expectedAdditions))
+		})
+	}
+}
+
+func TestSecurityRulesFromRuleSets(t *testing.T) {
+	sshOverride := sshRule
+	sshOverride.Priority = 2300
+	customOverride := customRule
+	customOverride.Description = "Override of the custom rule"
+	baseline := infrav1.SecurityRuleSet{Name: "baseline", SecurityRules: infrav1.SecurityRules{sshRule, otherRule}}
+	monitoring := infrav1.SecurityRuleSet{Name: "monitoring", SecurityRules: infrav1.SecurityRules{customRule, sshOverride}}
+	testcases := []struct {
+		name     string
+		spec     *NSGSpec
+		expected infrav1.SecurityRules
+	}{
+		{
+			name:     "spec without rule sets",
+			spec:     &NSGSpec{SecurityRules: infrav1.SecurityRules{customRule}},
+			expected: infrav1.SecurityRules{customRule},
+		},
+		{
+			name:     "rules of the rule set come before the rules of the spec",
+			spec:     &NSGSpec{SecurityRules: infrav1.SecurityRules{customRule}, RuleSets: []infrav1.SecurityRuleSet{baseline}},
+			expected: infrav1.SecurityRules{sshRule, otherRule, customRule},
+		},
+		{
+			name:     "later rule set overrides a rule in place",
+			spec:     &NSGSpec{RuleSets: []infrav1.SecurityRuleSet{baseline, monitoring}},
+			expected: infrav1.SecurityRules{sshOverride, otherRule, customRule},
+		},
+		{
+			name:     "rule of the spec overrides a rule of the rule sets",
+			spec:     &NSGSpec{SecurityRules: infrav1.SecurityRules{customOverride}, RuleSets: []infrav1.SecurityRuleSet{baseline, monitoring}},
+			expected: infrav1.SecurityRules{sshOverride, otherRule, customOverride},
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			g.Expect(tc.spec.securityRules()).To(Equal(tc.expected))
+			g.Expect(tc.spec.securityRules()).To(Equal(tc.expected))
+			g.Expect(tc.spec.desiredRules()).To(HaveLen(len(tc.expected)))
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	outboundRule := customRule
+	outboundRule.Priority = 500
+	lowestOutboundRule := customRule
+	lowestOutboundRule.Priority = 4096
+	testcases := []struct {
+		name          string
+		spec          *NSGSpec
+		expectedError string
+	}{
+		{
+			name: "valid rules",
+			spec: &NSGSpec{SecurityRules: infrav1.SecurityRules{sshRule, otherRule, outboundRule}, DefaultDenyOutbound: true},
+		},
+		{
+			name:          "priority out of range",
+			spec:          &NSGSpec{SecurityRules: infrav1.SecurityRules{sshRuleWithPriority(99)}},
+			expectedError: "securityRules[0]: Invalid value: 99: security rule priorities should be between 100 and 4096",
+		},
+		{
+			name:          "duplicate priority in the same direction",
+			spec:          &NSGSpec{SecurityRules: infrav1.SecurityRules{otherRule, sshRuleWithPriority(500)}},
+			expectedError: "securityRules[1].priority: Invalid value: 500: rules other_rule and allow_ssh have the same priority 500 in direction Inbound",
+		},
+		{
+			name:          "user rule collides with the deny all outbound rule",
+			spec:          &NSGSpec{SecurityRules: infrav1.SecurityRules{lowestOutboundRule}, DefaultDenyOutbound: true},
+			expectedError: "rules custom_rule and deny_all_outbound have the same priority 4096 in direction Outbound",
+		},
+		{
+			name:          "prefixed name too long",
+			spec:          &NSGSpec{SecurityRules: infrav1.SecurityRules{sshRule}, RuleNamePrefix: strings.Repeat("a", 75)},
+			expectedError: "rule " + strings.Repeat("a", 75) + "allow_ssh has a name longer than 80 characters",
+		},
+		{
+			name:          "description too long to be tagged",
+			spec:          &NSGSpec{SecurityRules: infrav1.SecurityRules{sshRuleWithDescription(strings.Repeat("a", 123))}},
+			expectedError: "securityRules[0].description: Too long: must have at most 122 bytes",
+		},
+		{
+			name:          "destination subnet is not a subnet of the cluster",
+			spec:          &NSGSpec{SecurityRules: infrav1.SecurityRules{nodeSubnetRule}, SubnetCIDRs: map[string][]string{"control-plane-subnet": {"10.0.0.0/16"}}},
+			expectedError: "rule allow_node_subnet has destination subnet node-subnet, which is not a subnet of the cluster with CIDR blocks",
+		},
+		{
+			name:          "all offending rules are listed",
+			spec:          &NSGSpec{SecurityRules: infrav1.SecurityRules{otherRule, sshRuleWithPriority(500), sshRuleWithPriority(4097)}},
+			expectedError: "[securityRules[1].priority: Invalid value: 500: rules other_rule and allow_ssh have the same priority 500 in direction Inbound, securityRules[2]: Invalid value: 4097: security rule priorities should be between 100 and 4096]",
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			err := tc.spec.Validate()
+			if tc.expectedError != "" {
+				g.Expect(err).To(MatchError(tc.expectedError))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
+
+func TestAssignPriorities(t *testing.T) {
+	unset := func(name string, direction infrav1.SecurityRuleDirection) infrav1.SecurityRule {
+		rule := sshRuleWithPriority(0)
+		rule.Name = name
+		rule.Direction = direction
+		return rule
+	}
+	testcases := []struct {
+		name     string
+		spec     *NSGSpec
+		expected map[string]int32
+	}{
+		{
+			name: "priorities are assigned in declaration order within each direction",
+			spec: &NSGSpec{SecurityRules: infrav1.SecurityRules{
+				unset("in_a", infrav1.SecurityRuleDirectionInbound),
+				unset("out_a", infrav1.SecurityRuleDirectionOutbound),
+				unset("in_b", infrav1.SecurityRuleDirectionInbound),
+			}},
+			expected: map[string]int32{"in_a": 100, "out_a": 100, "in_b": 110},
+		},
+		{
+			name: "explicit priorities are kept and skipped",
+			spec: &NSGSpec{SecurityRules: infrav1.SecurityRules{
+				unset("in_a", infrav1.SecurityRuleDirectionInbound),
+				unset("in_b", infrav1.SecurityRuleDirectionInbound),
+				sshRuleWithPriority(110),
+				unset("in_c", infrav1.SecurityRuleDirectionInbound),
+			}},
+			expected: map[string]int32{"in_a": 100, "in_b": 120, "allow_ssh": 110, "in_c": 130},
+		},
+		{
+			name: "the deny all outbound rule keeps its priority",
+			spec: &NSGSpec{SecurityRules: infrav1.SecurityRules{
+				unset("out_a", infrav1.SecurityRuleDirectionOutbound),
+			}, DefaultDenyOutbound: true},
+			expected: map[string]int32{"out_a": 100, "deny_all_outbound": 4096},
+		},
+		{
+			name: "priorities are spaced by the step of the assignment",
+			spec: &NSGSpec{SecurityRules: infrav1.SecurityRules{
+				unset("in_a", infrav1.SecurityRuleDirectionInbound),
+				unset("in_b", infrav1.SecurityRuleDirectionInbound),
+				unset("out_a", infrav1.SecurityRuleDirectionOutbound),
+			}, PriorityAssignment: &infrav1.SecurityRulePriorityAssignment{Step: 100}},
+			expected: map[string]int32{"in_a": 100, "in_b": 200, "out_a": 100},
+		},
+		{
+			name: "priorities start at the start of each direction",
+			spec: &NSGSpec{SecurityRules: infrav1.SecurityRules{
+				unset("in_a", infrav1.SecurityRuleDirectionInbound),
+				unset("out_a", infrav1.SecurityRuleDirectionOutbound),
+				unset("out_b", infrav1.SecurityRuleDirectionOutbound),
+			}, PriorityAssignment: &infrav1.SecurityRulePriorityAssignment{Step: 100, InboundStart: 1000, OutboundStart: 2000}},
+			expected: map[string]int32{"in_a": 1000, "out_a": 2000, "out_b": 2100},
+		},
+		{
+			name: "rules inserted between assigned priorities do not renumber the other rules",
+			spec: &NSGSpec{SecurityRules: infrav1.SecurityRules{
+				unset("in_a", infrav1.SecurityRuleDirectionInbound),
+				sshRuleWithPriority(150),
+				unset("in_b", infrav1.SecurityRuleDirectionInbound),
+			}, PriorityAssignment: &infrav1.SecurityRulePriorityAssignment{Step: 100}},
+			expected: map[string]int32{"in_a": 100, "allow_ssh": 150, "in_b": 200},
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			priorities := make(map[string]int32)
+			for _, rule := range tc.spec.desiredRules() {
+				priorities[to.String(rule.Name)] = to.Int32(rule.Priority)
+			}
+			g.Expect(priorities).To(Equal(tc.expected))
+			g.Expect(tc.spec.desiredRules()).To(Equal(tc.spec.desiredRules()))
+			g.Expect(tc.spec.Validate()).To(Succeed())
+		})
+	}
+}
+
+func TestRuleExists(t *testing.T) {
+	testcases := []struct {
+		name     string
+		rules    []network.SecurityRule
+		rule     network.SecurityRule
+		expected bool
+	}{
+		{
+			name:     "rule doesn't exitst",
+			rules:    []network.SecurityRule{managedRule(ruleA)},
+			rule:     managedRule(ruleB),
+			expected: false,
+		},
+		{
+			name:     "rule exists",
+			rules:    []network.SecurityRule{managedRule(ruleA), managedRule(ruleB)},
+			rule:     managedRule(ruleB),
+			expected: true,
+		},
+		{
+			name:     "rule exists but has been modified",
+			rules:    []network.SecurityRule{managedRule(ruleA), managedRule(ruleB)},
+			rule:     managedRule(ruleBModified),
+			expected: false,
+		},
+		{
+			name:     "rule exists with a different priority",
+			rules:    []network.SecurityRule{managedRule(ruleA), managedRule(ruleB)},
+			rule:     sdkRule(sshRuleWithPriority(4000)),
+			expected: false,
+		},
+		{
+			name:     "rule exists with a different source",
+			rules:    []network.SecurityRule{sdkRule(sshRule)},
+			rule:     sdkRule(sshRuleWithSource("10.0.0.0/16")),
+			expected: false,
+		},
+		{
+			name:     "augmented rule exists with port ranges and prefixes in a different order",
+			rules:    []network.SecurityRule{sdkRule(nodePortsRule)},
+			rule:     sdkRule(nodePortsRuleWith([]string{"443", "80"}, []string{"10.1.0.0/16", "10.0.0.0/16"})),
+			expected: true,
+		},
+		{
+			name:     "augmented rule exists with different port ranges",
+			rules:    []network.SecurityRule{sdkRule(nodePortsRule)},
+			rule:     sdkRule(nodePortsRuleWith([]string{"80", "8443"}, []string{"10.0.0.0/16", "10.1.0.0/16"})),
+			expected: false,
+		},
+		{
+			name:     "rule with application security groups exists with the groups in a different order",
+			rules:    []network.SecurityRule{sdkRule(webToDBRuleWith("web", "api"))},
+			rule:     sdkRule(webToDBRuleWith("api", "web")),
+			expected: true,
+		},
+		{
+			name:     "rule with application security groups exists with different groups",
+			rules:    []network.SecurityRule{sdkRule(webToDBRuleWith("web", "api"))},
+			rule:     sdkRule(webToDBRuleWith("web")),
+			expected: false,
+		},
+		{
+			name:     "rule exists with a different description",
+			rules:    []network.SecurityRule{sdkRule(sshRule)},
+			rule:     sdkRule(sshRuleWithDescription("Allow SSH from anywhere")),
+			expected: false,
+		},
+		{
+			name:     "rule exists without a description",
+			rules:    []network.SecurityRule{sdkRule(sshRuleWithDescription(""))},
+			rule:     sdkRule(sshRule),
+			expected: false,
+		},
+		{
+			name:     "rule exists without the managed rule tag",
+			rules:    []network.SecurityRule{converters.SecurityRuleToSDK(sshRule)},
+			rule:     sdkRule(sshRule),
+			expected: true,
+		},
+		{
+			name:     "rule exists without the managed rule tag and with a different description",
+			rules:    []network.SecurityRule{converters.SecurityRuleToSDK(sshRuleWithDescription("Allow SSH from anywhere"))},
+			rule:     sdkRule(sshRule),
+			expected: false,
+		},
+		{
+			name:     "rule exists without the managed rule tag and with a different priority",
+			rules:    []network.SecurityRule{converters.SecurityRuleToSDK(sshRuleWithPriority(4000))},
+			rule:     sdkRule(sshRule),
+			expected: false,
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			result := ruleExists(tc.rules, tc.rule)
+			g.Expect(result).To(Equal(tc.expected))
+		})
+	}
+}
+
+func sshRuleWithPriority(priority int32) infrav1.SecurityRule {
+	rule := sshRule
+	rule.Priority = priority
+	return rule
+}
+
+func sshRuleWithSource(source string) infrav1.SecurityRule {
+	rule := sshRule
+	rule.Source = to.StringPtr(source)
+	return rule
+}
+
+func sshRuleWithDescription(description string) infrav1.SecurityRule {
+	rule := sshRule
+	rule.Description = description
+	return rule
+}
+
+func TestManagedRule(t *testing.T) {
+	testcases := []struct {
+		name        string
+		description string
+		expected    string
+	}{
+		{
+			name:        "description is tagged",
+			description: "Allow SSH",
+			expected:    "Allow SSH (managed by capz)",
+		},
+		{
+			name:        "empty description is tagged",
+			description: "",
+			expected:    "(managed by capz)",
+		},
+		{
+			name:        "longest description is tagged within the length accepted by Azure",
+			description: strings.Repeat("a", infrav1.MaxSecurityRuleDescriptionLength),
+			expected:    strings.Repeat("a", infrav1.MaxSecurityRuleDescriptionLength) + " (managed by capz)",
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			rule := managedRule(converters.SecurityRuleToSDK(infrav1.SecurityRule{Name: "test-rule", Description: tc.description}))
+			g.Expect(to.String(rule.Description)).To(Equal(tc.expected))
+			// Azure accepts descriptions of up to 140 characters.
+			g.Expect(len(to.String(rule.Description))).To(BeNumerically("<=", 140))
+			g.Expect(isManagedRule(rule)).To(BeTrue())
+		})
+	}
+}
+
+// sdkRule returns the SDK representation of a rule managed by CAPZ.
+func sdkRule(rule infrav1.SecurityRule) network.SecurityRule {
+	return managedRule(converters.SecurityRuleToSDK(rule))
+}
+
+func nodePortsRuleWith(destinationPortRanges []string, sources []string) infrav1.SecurityRule {
+	return infrav1.SecurityRule{
+		Name:                  "allow_node_ports",
+		Description:           "Allow node ports",
+		Priority:              2300,
+		Protocol:              infrav1.SecurityGroupProtocolTCP,
+		Direction:             infrav1.SecurityRuleDirectionInbound,
+		Sources:               sources,
+		SourcePorts:           to.StringPtr("*"),
+		Destination:           to.StringPtr("*"),
+		DestinationPortRanges: destinationPortRanges,
+	}
+}
+
+// webToDBRuleWith returns a rule allowing traffic from the network interfaces of the given application security
+// groups to the ones of the db application security group.
+func webToDBRuleWith(sourceGroups ...string) infrav1.SecurityRule {
+	rule := infrav1.SecurityRule{
+		Name:                                 "allow_web_to_db",
+		Description:                          "Allow web to db",
+		Priority:                             2400,
+		Protocol:                             infrav1.SecurityGroupProtocolTCP,
+		Direction:                            infrav1.SecurityRuleDirectionInbound,
+		SourcePorts:                          to.StringPtr("*"),
+		DestinationPorts:                     to.StringPtr("5432"),
+		DestinationApplicationSecurityGroups: []string{azure.ApplicationSecurityGroupID("123", "test-group", "db")},
+	}
+	for _, group := range sourceGroups {
+		rule.SourceApplicationSecurityGroups = append(rule.SourceApplicationSecurityGroups, azure.ApplicationSecurityGroupID("123", "test-group", group))
+	}
+	return rule
+}
+
+// ownedNSGTagsWith returns the tags of a new security group named test-nsg owned by test-cluster, along with the given tags.
+func ownedNSGTagsWith(additional map[string]*string) map[string]*string {
+	tags := map[string]*string{
+		"sigs.k8s.io_cluster-api-provider-azure_cluster_test-cluster": to.StringPtr("owned"),
+		"Name": to.StringPtr("test-nsg"),
+	}
+	for k, v := range additional {
+		tags[k] = v
 	}
-	for _, tc := range testcases {
-		tc := tc
-		t.Run(tc.name, func(t *testing.T) {
-			g := NewWithT(t)
-			t.Parallel()
-			result := ruleExists(tc.rules, tc.rule)
-			g.Expect(result).To(Equal(tc.expected))
-		})
-	}
+	return tags
 }
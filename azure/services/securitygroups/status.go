@@ -0,0 +1,99 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package securitygroups
+
+import (
+	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-02-01/network"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/pkg/errors"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+)
+
+// securityGroupStatus summarizes the rules managed by CAPZ found on a security group returned by Azure.
+func securityGroupStatus(name string, nsg network.SecurityGroup) infrav1.SecurityGroupStatus {
+	status := infrav1.SecurityGroupStatus{Name: name}
+	if nsg.SecurityGroupPropertiesFormat == nil || nsg.SecurityRules == nil {
+		return status
+	}
+	for _, rule := range *nsg.SecurityRules {
+		if !isManagedRule(rule) {
+			continue
+		}
+		status.Rules = append(status.Rules, infrav1.SecurityRuleStatus{
+			Name:             to.String(rule.Name),
+			Priority:         to.Int32(rule.Priority),
+			Direction:        infrav1.SecurityRuleDirection(rule.Direction),
+			Protocol:         infrav1.SecurityGroupProtocol(rule.Protocol),
+			SourcePorts:      portRanges(rule.SourcePortRange, rule.SourcePortRanges),
+			DestinationPorts: portRanges(rule.DestinationPortRange, rule.DestinationPortRanges),
+			ETag:             to.String(rule.Etag),
+		})
+	}
+	return status
+}
+
+// portRanges returns the port ranges of a rule, whether it uses the singular or the plural field.
+func portRanges(portRange *string, portRanges *[]string) []string {
+	if portRanges != nil && len(*portRanges) > 0 {
+		return append([]string{}, *portRanges...)
+	}
+	if portRange != nil {
+		return []string{*portRange}
+	}
+	return nil
+}
+
+// setStatus records the rules found on a reconciled security group in the scope. The security group returned by
+// CreateResource is the live one, either read from Azure when it is up to date or returned by the update, so the
+// etags of its rules identify the rules changed by the update.
+func (s *Service) setStatus(nsgSpec azure.ResourceSpecGetter, result interface{}) error {
+	if result == nil {
+		return nil
+	}
+	nsg, ok := result.(network.SecurityGroup)
+	if !ok {
+		return errors.Errorf("%T is not a network.SecurityGroup", result)
+	}
+	s.scopeLock.Lock()
+	defer s.scopeLock.Unlock()
+	s.Scope.SetSecurityGroupStatus(securityGroupStatus(nsgSpec.ResourceName(), nsg))
+	// The security group is up to date, so the changes computed by a previous reconcile in dry-run mode are applied.
+	s.Scope.DeleteSecurityGroupPendingChanges(nsgSpec.ResourceName())
+	return nil
+}
+
+// pendingChanges returns the rule diff of a security group from the rule changes computed in dry-run mode. Additions
+// of rules that already exist in the security group are updates of these rules.
+func pendingChanges(name string, existing network.SecurityGroup, changes RuleChanges) infrav1.SecurityGroupPendingChanges {
+	var existingRules []network.SecurityRule
+	if existing.SecurityGroupPropertiesFormat != nil && existing.SecurityRules != nil {
+		existingRules = *existing.SecurityRules
+	}
+	pending := infrav1.SecurityGroupPendingChanges{Name: name}
+	for _, rule := range changes.Additions {
+		if ruleNamed(existingRules, to.String(rule.Name)) {
+			pending.ModifiedRules = append(pending.ModifiedRules, to.String(rule.Name))
+		} else {
+			pending.AddedRules = append(pending.AddedRules, to.String(rule.Name))
+		}
+	}
+	if len(changes.Deletions) > 0 {
+		pending.RemovedRules = append([]string{}, changes.Deletions...)
+	}
+	return pending
+}
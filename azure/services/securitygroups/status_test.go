@@ -0,0 +1,137 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package securitygroups
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-02-01/network"
+	"github.com/Azure/go-autorest/autorest/to"
+	. "github.com/onsi/gomega"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+)
+
+func TestSecurityGroupStatus(t *testing.T) {
+	testcases := []struct {
+		name     string
+		nsg      network.SecurityGroup
+		expected infrav1.SecurityGroupStatus
+	}{
+		{
+			name:     "security group without rules",
+			nsg:      network.SecurityGroup{Name: to.StringPtr("test-nsg")},
+			expected: infrav1.SecurityGroupStatus{Name: "test-nsg"},
+		},
+		{
+			name: "only the rules managed by CAPZ are reported",
+			nsg: network.SecurityGroup{
+				Name: to.StringPtr("test-nsg"),
+				SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
+					SecurityRules: &[]network.SecurityRule{
+						sdkRule(sshRule),
+						foreignRule,
+						sdkRule(nodePortsRule),
+					},
+				},
+			},
+			expected: infrav1.SecurityGroupStatus{
+				Name: "test-nsg",
+				Rules: []infrav1.SecurityRuleStatus{
+					{
+						Name:             "allow_ssh",
+						Priority:         2200,
+						Direction:        infrav1.SecurityRuleDirectionInbound,
+						Protocol:         infrav1.SecurityGroupProtocolTCP,
+						SourcePorts:      []string{"*"},
+						DestinationPorts: []string{"22"},
+					},
+					{
+						Name:             "allow_node_ports",
+						Priority:         2300,
+						Direction:        infrav1.SecurityRuleDirectionInbound,
+						Protocol:         infrav1.SecurityGroupProtocolTCP,
+						SourcePorts:      []string{"*"},
+						DestinationPorts: []string{"80", "443"},
+					},
+				},
+			},
+		},
+		{
+			name: "the etags of the rules are reported",
+			nsg: network.SecurityGroup{
+				Name: to.StringPtr("test-nsg"),
+				SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
+					SecurityRules: &[]network.SecurityRule{
+						withEtag(sdkRule(sshRule), "W/\"etag-1\""),
+					},
+				},
+			},
+			expected: infrav1.SecurityGroupStatus{
+				Name: "test-nsg",
+				Rules: []infrav1.SecurityRuleStatus{
+					{
+						Name:             "allow_ssh",
+						Priority:         2200,
+						Direction:        infrav1.SecurityRuleDirectionInbound,
+						Protocol:         infrav1.SecurityGroupProtocolTCP,
+						SourcePorts:      []string{"*"},
+						DestinationPorts: []string{"22"},
+						ETag:             "W/\"etag-1\"",
+					},
+				},
+			},
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			g.Expect(securityGroupStatus("test-nsg", tc.nsg)).To(Equal(tc.expected))
+		})
+	}
+}
+
+func withEtag(rule network.SecurityRule, etag string) network.SecurityRule {
+	rule.Etag = to.StringPtr(etag)
+	return rule
+}
+
+func TestPendingChanges(t *testing.T) {
+	g := NewWithT(t)
+	existing := network.SecurityGroup{
+		SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
+			SecurityRules: &[]network.SecurityRule{{Name: to.StringPtr("allow_ssh")}, {Name: to.StringPtr("allow_vpn")}},
+		},
+	}
+	changes := RuleChanges{
+		Additions: []network.SecurityRule{{Name: to.StringPtr("allow_ssh")}, {Name: to.StringPtr("allow_http")}},
+		Deletions: []string{"allow_vpn"},
+	}
+
+	g.Expect(pendingChanges("test-nsg", existing, changes)).To(Equal(infrav1.SecurityGroupPendingChanges{
+		Name:          "test-nsg",
+		AddedRules:    []string{"allow_http"},
+		RemovedRules:  []string{"allow_vpn"},
+		ModifiedRules: []string{"allow_ssh"},
+	}))
+	// A security group that would be created only gets additions.
+	g.Expect(pendingChanges("test-nsg", network.SecurityGroup{}, RuleChanges{Additions: changes.Additions})).To(Equal(infrav1.SecurityGroupPendingChanges{
+		Name:       "test-nsg",
+		AddedRules: []string{"allow_ssh", "allow_http"},
+	}))
+}
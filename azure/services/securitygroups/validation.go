@@ -0,0 +1,148 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package securitygroups
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-02-01/network"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/async"
+	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
+)
+
+// validateCustomVNet checks that the security groups of a custom VNet exist and allow the inbound traffic required by
+// their spec, e.g. to the API server of the control plane. The security groups are only read, never modified.
+func (s *Service) validateCustomVNet(ctx context.Context) error {
+	ctx, log, done := tele.StartSpanWithLogger(ctx, "securitygroups.Service.validateCustomVNet")
+	defer done()
+
+	specs := s.nsgSpecs(ctx)
+	return s.forEachSpec(specs, func(spec azure.ResourceSpecGetter) error {
+		nsgSpec, ok := spec.(*NSGSpec)
+		if !ok {
+			return nil
+		}
+		if err := s.resolveRuleSources(ctx, nsgSpec); err != nil {
+			return err
+		}
+		if err := s.resolveFirewallRules(ctx, nsgSpec); err != nil {
+			return err
+		}
+		result, err := s.GetResource(ctx, nsgSpec, serviceName)
+		if errors.Is(err, async.ErrResourceNotFound) {
+			return errors.Errorf("security group %s of the custom VNet does not exist", nsgSpec.Name)
+		} else if err != nil {
+			return err
+		}
+		existing, ok := result.(network.SecurityGroup)
+		if !ok {
+			return errors.Errorf("%T is not a network.SecurityGroup", result)
+		}
+		if missing := nsgSpec.MissingInboundRules(existing); len(missing) > 0 {
+			return errors.Errorf("security group %s of the custom VNet does not allow the inbound traffic of rules %s", nsgSpec.Name, strings.Join(missing, ", "))
+		}
+		log.V(4).Info("security group of the custom VNet allows the required inbound traffic", "securityGroup", nsgSpec.Name)
+		return nil
+	})
+}
+
+// MissingInboundRules returns the names of the inbound allow rules of the spec whose traffic is not allowed by any
+// rule of the existing security group, regardless of the names and priorities of its rules. Deny rules of the existing
+// security group are not taken into account.
+func (s *NSGSpec) MissingInboundRules(existing network.SecurityGroup) []string {
+	var existingRules []network.SecurityRule
+	if existing.SecurityGroupPropertiesFormat != nil && existing.SecurityRules != nil {
+		existingRules = *existing.SecurityRules
+	}
+
+	var missing []string
+	for _, rule := range s.desiredRules() {
+		if rule.Direction != network.SecurityRuleDirectionInbound || rule.Access == network.SecurityRuleAccessDeny {
+			continue
+		}
+		if !trafficAllowed(existingRules, rule) {
+			missing = append(missing, to.String(rule.Name))
+		}
+	}
+	return missing
+}
+
+// trafficAllowed returns true if one of the rules allows the inbound traffic of the given rule, i.e. it allows the same
+// or any protocol on destination ports covering all the destination ports of the given rule.
+func trafficAllowed(rules []network.SecurityRule, rule network.SecurityRule) bool {
+	wanted := portRanges(rule.DestinationPortRange, rule.DestinationPortRanges)
+	for _, existingRule := range rules {
+		if existingRule.SecurityRulePropertiesFormat == nil ||
+			existingRule.Access != network.SecurityRuleAccessAllow ||
+			existingRule.Direction != network.SecurityRuleDirectionInbound {
+			continue
+		}
+		if existingRule.Protocol != network.SecurityRuleProtocolAsterisk && existingRule.Protocol != rule.Protocol {
+			continue
+		}
+		allowed := portRanges(existingRule.DestinationPortRange, existingRule.DestinationPortRanges)
+		covered := true
+		for _, ports := range wanted {
+			if !portsCovered(allowed, ports) {
+				covered = false
+				break
+			}
+		}
+		if covered {
+			return true
+		}
+	}
+	return false
+}
+
+// portsCovered returns true if the port or port range is within one of the allowed port ranges.
+func portsCovered(allowed []string, ports string) bool {
+	low, high, ok := parsePortRange(ports)
+	for _, allowedPorts := range allowed {
+		if allowedPorts == "*" {
+			return true
+		}
+		if !ok {
+			continue
+		}
+		if allowedLow, allowedHigh, allowedOK := parsePortRange(allowedPorts); allowedOK && allowedLow <= low && high <= allowedHigh {
+			return true
+		}
+	}
+	return false
+}
+
+// parsePortRange parses a port, e.g. "22", or a port range, e.g. "30000-32767". It returns false for any port.
+func parsePortRange(ports string) (low, high int, ok bool) {
+	bounds := strings.SplitN(strings.TrimSpace(ports), "-", 2)
+	low, err := strconv.Atoi(strings.TrimSpace(bounds[0]))
+	if err != nil {
+		return 0, 0, false
+	}
+	high = low
+	if len(bounds) == 2 {
+		if high, err = strconv.Atoi(strings.TrimSpace(bounds[1])); err != nil {
+			return 0, 0, false
+		}
+	}
+	return low, high, true
+}
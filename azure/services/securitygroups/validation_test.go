@@ -0,0 +1,193 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package securitygroups
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-02-01/network"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/async"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/async/mock_async"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/securitygroups/mock_securitygroups"
+	gomockinternal "sigs.k8s.io/cluster-api-provider-azure/internal/test/matchers/gomock"
+)
+
+// inboundAllowRule returns an inbound rule allowing the protocol on the destination ports, as found on a security
+// group not managed by CAPZ.
+func inboundAllowRule(name string, protocol network.SecurityRuleProtocol, destinationPorts string) network.SecurityRule {
+	return network.SecurityRule{
+		Name: to.StringPtr(name),
+		SecurityRulePropertiesFormat: &network.SecurityRulePropertiesFormat{
+			Protocol:             protocol,
+			Access:               network.SecurityRuleAccessAllow,
+			Direction:            network.SecurityRuleDirectionInbound,
+			Priority:             to.Int32Ptr(100),
+			DestinationPortRange: to.StringPtr(destinationPorts),
+		},
+	}
+}
+
+func TestMissingInboundRules(t *testing.T) {
+	testcases := []struct {
+		name     string
+		existing []network.SecurityRule
+		expected []string
+	}{
+		{
+			name:     "security group without rules",
+			existing: nil,
+			expected: []string{"allow_ssh", "other_rule"},
+		},
+		{
+			name: "rules with other names and priorities allow the traffic",
+			existing: []network.SecurityRule{
+				inboundAllowRule("ssh", network.SecurityRuleProtocolTCP, "22"),
+				inboundAllowRule("web", network.SecurityRuleProtocolTCP, "80"),
+			},
+			expected: nil,
+		},
+		{
+			name: "rules allowing any protocol on port ranges allow the traffic",
+			existing: []network.SecurityRule{
+				inboundAllowRule("low-ports", network.SecurityRuleProtocolAsterisk, "1-1024"),
+			},
+			expected: nil,
+		},
+		{
+			name: "rule allowing any port allows the traffic",
+			existing: []network.SecurityRule{
+				inboundAllowRule("all", network.SecurityRuleProtocolTCP, "*"),
+			},
+			expected: nil,
+		},
+		{
+			name: "rule allowing another protocol does not allow the traffic",
+			existing: []network.SecurityRule{
+				inboundAllowRule("ssh", network.SecurityRuleProtocolUDP, "22"),
+				inboundAllowRule("web", network.SecurityRuleProtocolTCP, "80"),
+			},
+			expected: []string{"allow_ssh"},
+		},
+		{
+			name: "deny and outbound rules do not allow the traffic",
+			existing: []network.SecurityRule{
+				func() network.SecurityRule {
+					rule := inboundAllowRule("ssh", network.SecurityRuleProtocolTCP, "22")
+					rule.Access = network.SecurityRuleAccessDeny
+					return rule
+				}(),
+				func() network.SecurityRule {
+					rule := inboundAllowRule("web", network.SecurityRuleProtocolTCP, "80")
+					rule.Direction = network.SecurityRuleDirectionOutbound
+					return rule
+				}(),
+			},
+			expected: []string{"allow_ssh", "other_rule"},
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+
+			existing := network.SecurityGroup{SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{SecurityRules: &tc.existing}}
+			g.Expect(fakeNSG.MissingInboundRules(existing)).To(Equal(tc.expected))
+		})
+	}
+}
+
+func TestReconcileSecurityGroupsCustomVNetValidation(t *testing.T) {
+	testcases := []struct {
+		name          string
+		expectedError string
+		expect        func(s *mock_securitygroups.MockNSGScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder)
+	}{
+		{
+			name:          "security group allows the required traffic, should return no error",
+			expectedError: "",
+			expect: func(s *mock_securitygroups.MockNSGScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
+				s.IsVnetManaged().Return(false)
+				s.NSGSpecs().Return([]azure.ResourceSpecGetter{&fakeNSG})
+				r.GetResource(gomockinternal.AContext(), &fakeNSG, serviceName).Return(network.SecurityGroup{
+					SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
+						SecurityRules: &[]network.SecurityRule{inboundAllowRule("all", network.SecurityRuleProtocolAsterisk, "*")},
+					},
+				}, nil)
+				s.UpdatePutStatus(infrav1.SecurityGroupsReadyCondition, serviceName, nil)
+			},
+		},
+		{
+			name:          "security group misses required traffic, should return error",
+			expectedError: "security group test-nsg of the custom VNet does not allow the inbound traffic of rules other_rule",
+			expect: func(s *mock_securitygroups.MockNSGScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
+				s.IsVnetManaged().Return(false)
+				s.NSGSpecs().Return([]azure.ResourceSpecGetter{&fakeNSG})
+				r.GetResource(gomockinternal.AContext(), &fakeNSG, serviceName).Return(network.SecurityGroup{
+					SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
+						SecurityRules: &[]network.SecurityRule{inboundAllowRule("ssh", network.SecurityRuleProtocolTCP, "22")},
+					},
+				}, nil)
+				s.UpdatePutStatus(infrav1.SecurityGroupsReadyCondition, serviceName, gomock.Any())
+			},
+		},
+		{
+			name:          "security group does not exist, should return error",
+			expectedError: "security group test-nsg of the custom VNet does not exist",
+			expect: func(s *mock_securitygroups.MockNSGScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
+				s.IsVnetManaged().Return(false)
+				s.NSGSpecs().Return([]azure.ResourceSpecGetter{&fakeNSG})
+				r.GetResource(gomockinternal.AContext(), &fakeNSG, serviceName).Return(nil, errors.Wrap(async.ErrResourceNotFound, "resource test-group/test-nsg"))
+				s.UpdatePutStatus(infrav1.SecurityGroupsReadyCondition, serviceName, gomock.Any())
+			},
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			scopeMock := newMockNSGScope(mockCtrl)
+			reconcilerMock := mock_async.NewMockReconciler(mockCtrl)
+
+			tc.expect(scopeMock.EXPECT(), reconcilerMock.EXPECT())
+
+			s := &Service{
+				Scope:              scopeMock,
+				Reconciler:         reconcilerMock,
+				ValidateCustomVNet: true,
+			}
+
+			err := s.Reconcile(context.TODO())
+			if tc.expectedError != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err).To(MatchError(tc.expectedError))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
@@ -26,17 +26,18 @@ import (
 
 // SubnetSpec defines the specification for a Subnet.
 type SubnetSpec struct {
-	Name              string
-	ResourceGroup     string
-	SubscriptionID    string
-	CIDRs             []string
-	VNetName          string
-	VNetResourceGroup string
-	IsVNetManaged     bool
-	RouteTableName    string
-	SecurityGroupName string
-	Role              infrav1.SubnetRole
-	NatGatewayName    string
+	Name                       string
+	ResourceGroup              string
+	SubscriptionID             string
+	CIDRs                      []string
+	VNetName                   string
+	VNetResourceGroup          string
+	IsVNetManaged              bool
+	RouteTableName             string
+	SecurityGroupName          string
+	SecurityGroupResourceGroup string
+	Role                       infrav1.SubnetRole
+	NatGatewayName             string
 }
 
 // ResourceName returns the name of the subnet.
@@ -54,6 +55,22 @@ func (s *SubnetSpec) OwnerResourceName() string {
 	return s.VNetName
 }
 
+// DependsOn returns the references of the security group, route table and NAT gateway of the subnet, which must be
+// ready before the subnet references them.
+func (s *SubnetSpec) DependsOn() []string {
+	var refs []string
+	if s.SecurityGroupName != "" {
+		refs = append(refs, azure.DependencyRef("securitygroups", s.SecurityGroupResourceGroup, s.SecurityGroupName))
+	}
+	if s.RouteTableName != "" {
+		refs = append(refs, azure.DependencyRef("routetables", s.ResourceGroup, s.RouteTableName))
+	}
+	if s.NatGatewayName != "" {
+		refs = append(refs, azure.DependencyRef("natgateways", s.ResourceGroup, s.NatGatewayName))
+	}
+	return refs
+}
+
 // Parameters returns the parameters for the subnet.
 func (s *SubnetSpec) Parameters(existing interface{}) (parameters interface{}, err error) {
 	if existing != nil {
@@ -93,8 +110,12 @@ func (s *SubnetSpec) Parameters(existing interface{}) (parameters interface{}, e
 	}
 
 	if s.SecurityGroupName != "" {
+		securityGroupResourceGroup := s.SecurityGroupResourceGroup
+		if securityGroupResourceGroup == "" {
+			securityGroupResourceGroup = s.ResourceGroup
+		}
 		subnetProperties.NetworkSecurityGroup = &network.SecurityGroup{
-			ID: to.StringPtr(azure.SecurityGroupID(s.SubscriptionID, s.ResourceGroup, s.SecurityGroupName)),
+			ID: to.StringPtr(azure.SecurityGroupID(s.SubscriptionID, securityGroupResourceGroup, s.SecurityGroupName)),
 		}
 	}
 
@@ -47,11 +47,11 @@ type Service struct {
 }
 
 // New creates a new service.
-func New(scope SubnetScope) *Service {
+func New(scope SubnetScope, opts ...async.Option) *Service {
 	Client := NewClient(scope)
 	return &Service{
 		Scope:      scope,
-		Reconciler: async.New(scope, Client, Client),
+		Reconciler: async.New(scope, Client, Client, opts...),
 	}
 }
 
@@ -60,7 +60,7 @@ func (s *Service) Reconcile(ctx context.Context) error {
 	ctx, _, done := tele.StartSpanWithLogger(ctx, "subnets.Service.Reconcile")
 	defer done()
 
-	ctx, cancel := context.WithTimeout(ctx, reconciler.DefaultAzureServiceReconcileTimeout)
+	ctx, cancel := context.WithTimeout(ctx, reconciler.ServiceReconcileTimeout(s.Reconciler))
 	defer cancel()
 
 	specs := s.Scope.SubnetSpecs()
@@ -68,32 +68,29 @@ func (s *Service) Reconcile(ctx context.Context) error {
 		return nil
 	}
 
-	// We go through the list of SubnetSpecs to reconcile each one, independently of the result of the previous one.
-	// If multiple errors occur, we return the most pressing one.
+	// We go through the list of SubnetSpecs to reconcile each one, independently of the result of the previous one,
+	// once the resources it depends on are ready. If multiple errors occur, we return the most pressing one.
 	//  Order of precedence (highest -> lowest) is: error that is not an operationNotDoneError (i.e. error creating) -> operationNotDoneError (i.e. creating in progress) -> no error (i.e. created)
-	var resultErr error
-	for _, subnetSpec := range specs {
-		result, err := s.CreateResource(ctx, subnetSpec, serviceName)
+	resultErr := async.ReconcileInOrder(s.Scope, specs, serviceName, func(subnetSpec azure.ResourceSpecGetter) error {
+		result, _, err := s.CreateResource(ctx, subnetSpec, serviceName)
 		if err != nil {
-			if !azure.IsOperationNotDoneError(err) || resultErr == nil {
-				resultErr = err
-			}
-		} else {
-			subnet, ok := result.(network.Subnet)
-			if !ok {
-				return errors.Errorf("%T is not a network.Subnet", result)
-			}
-			var addresses []string
-			if subnet.SubnetPropertiesFormat != nil && subnet.SubnetPropertiesFormat.AddressPrefix != nil {
-				addresses = []string{to.String(subnet.SubnetPropertiesFormat.AddressPrefix)}
-			} else if subnet.SubnetPropertiesFormat != nil && subnet.SubnetPropertiesFormat.AddressPrefixes != nil {
-				addresses = to.StringSlice(subnet.SubnetPropertiesFormat.AddressPrefixes)
-			}
-
-			s.Scope.UpdateSubnetID(subnetSpec.ResourceName(), to.String(subnet.ID))
-			s.Scope.UpdateSubnetCIDRs(subnetSpec.ResourceName(), addresses)
+			return err
 		}
-	}
+		subnet, ok := result.(network.Subnet)
+		if !ok {
+			return errors.Errorf("%T is not a network.Subnet", result)
+		}
+		var addresses []string
+		if subnet.SubnetPropertiesFormat != nil && subnet.SubnetPropertiesFormat.AddressPrefix != nil {
+			addresses = []string{to.String(subnet.SubnetPropertiesFormat.AddressPrefix)}
+		} else if subnet.SubnetPropertiesFormat != nil && subnet.SubnetPropertiesFormat.AddressPrefixes != nil {
+			addresses = to.StringSlice(subnet.SubnetPropertiesFormat.AddressPrefixes)
+		}
+
+		s.Scope.UpdateSubnetID(subnetSpec.ResourceName(), to.String(subnet.ID))
+		s.Scope.UpdateSubnetCIDRs(subnetSpec.ResourceName(), addresses)
+		return nil
+	})
 
 	if s.Scope.IsVnetManaged() {
 		s.Scope.UpdatePutStatus(infrav1.SubnetsReadyCondition, serviceName, resultErr)
@@ -107,7 +104,7 @@ func (s *Service) Delete(ctx context.Context) error {
 	ctx, log, done := tele.StartSpanWithLogger(ctx, "subnets.Service.Delete")
 	defer done()
 
-	ctx, cancel := context.WithTimeout(ctx, reconciler.DefaultAzureServiceReconcileTimeout)
+	ctx, cancel := context.WithTimeout(ctx, reconciler.ServiceReconcileTimeout(s.Reconciler))
 	defer cancel()
 
 	if !s.Scope.IsVnetManaged() {
@@ -125,7 +122,7 @@ func (s *Service) Delete(ctx context.Context) error {
 	//  Order of precedence (highest -> lowest) is: error that is not an operationNotDoneError (i.e. error deleting) -> operationNotDoneError (i.e. deleting in progress) -> no error (i.e. deleted)
 	var result error
 	for _, subnetSpec := range specs {
-		if err := s.DeleteResource(ctx, subnetSpec, serviceName); err != nil {
+		if _, err := s.DeleteResource(ctx, subnetSpec, serviceName); err != nil {
 			if !azure.IsOperationNotDoneError(err) || result == nil {
 				result = err
 			}
@@ -36,16 +36,17 @@ import (
 
 var (
 	fakeSubnetSpec1 = SubnetSpec{
-		Name:              "my-subnet-1",
-		ResourceGroup:     "my-rg",
-		SubscriptionID:    "123",
-		CIDRs:             []string{"10.0.0.0/16"},
-		IsVNetManaged:     true,
-		VNetName:          "my-vnet",
-		VNetResourceGroup: "my-rg",
-		RouteTableName:    "my-subnet_route_table",
-		SecurityGroupName: "my-sg-1",
-		Role:              infrav1.SubnetNode,
+		Name:                       "my-subnet-1",
+		ResourceGroup:              "my-rg",
+		SubscriptionID:             "123",
+		CIDRs:                      []string{"10.0.0.0/16"},
+		IsVNetManaged:              true,
+		VNetName:                   "my-vnet",
+		VNetResourceGroup:          "my-rg",
+		RouteTableName:             "my-subnet_route_table",
+		SecurityGroupName:          "my-sg-1",
+		SecurityGroupResourceGroup: "my-rg",
+		Role:                       infrav1.SubnetNode,
 	}
 
 	fakeSubnet1 = network.Subnet{
@@ -210,7 +211,7 @@ func TestReconcileSubnets(t *testing.T) {
 			expect: func(s *mock_subnets.MockSubnetScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				s.SubnetSpecs().Return([]azure.ResourceSpecGetter{&fakeSubnetSpec1})
 
-				r.CreateResource(gomockinternal.AContext(), &fakeSubnetSpec1, serviceName).Return(fakeSubnet1, nil)
+				r.CreateResource(gomockinternal.AContext(), &fakeSubnetSpec1, serviceName).Return(fakeSubnet1, false, nil)
 				s.UpdateSubnetID(fakeSubnetSpec1.Name, to.String(fakeSubnet1.ID))
 				s.UpdateSubnetCIDRs(fakeSubnetSpec1.Name, []string{to.String(fakeSubnet1.AddressPrefix)})
 
@@ -218,17 +219,32 @@ func TestReconcileSubnets(t *testing.T) {
 				s.UpdatePutStatus(infrav1.SubnetsReadyCondition, serviceName, nil)
 			},
 		},
+		{
+			name:          "skip subnet until its security group is created",
+			expectedError: "waiting for dependencies securitygroups/my-rg/my-sg-1: operation type PUT on Azure resource my-rg/my-subnet-1 is not done. Object will be requeued after 15s",
+			expect: func(s *mock_subnets.MockSubnetScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
+				s.SubnetSpecs().Return([]azure.ResourceSpecGetter{&fakeSubnetSpec1, &fakeSubnetSpec2})
+				s.GetLongRunningOperationState("my-sg-1", "securitygroups").Return(&infrav1.Future{Type: infrav1.PutFuture, ServiceName: "securitygroups", Name: "my-sg-1"})
+
+				r.CreateResource(gomockinternal.AContext(), &fakeSubnetSpec2, serviceName).Return(fakeSubnet2, false, nil)
+				s.UpdateSubnetID(fakeSubnetSpec2.Name, to.String(fakeSubnet2.ID))
+				s.UpdateSubnetCIDRs(fakeSubnetSpec2.Name, []string{to.String(fakeSubnet2.AddressPrefix)})
+
+				s.IsVnetManaged().AnyTimes().Return(true)
+				s.UpdatePutStatus(infrav1.SubnetsReadyCondition, serviceName, gomockinternal.ErrStrEq("waiting for dependencies securitygroups/my-rg/my-sg-1: operation type PUT on Azure resource my-rg/my-subnet-1 is not done. Object will be requeued after 15s"))
+			},
+		},
 		{
 			name:          "create multiple subnets",
 			expectedError: "",
 			expect: func(s *mock_subnets.MockSubnetScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				s.SubnetSpecs().Return([]azure.ResourceSpecGetter{&fakeSubnetSpec1, &fakeSubnetSpec2})
 
-				r.CreateResource(gomockinternal.AContext(), &fakeSubnetSpec1, serviceName).Return(fakeSubnet1, nil)
+				r.CreateResource(gomockinternal.AContext(), &fakeSubnetSpec1, serviceName).Return(fakeSubnet1, false, nil)
 				s.UpdateSubnetID(fakeSubnetSpec1.Name, to.String(fakeSubnet1.ID))
 				s.UpdateSubnetCIDRs(fakeSubnetSpec1.Name, []string{to.String(fakeSubnet1.AddressPrefix)})
 
-				r.CreateResource(gomockinternal.AContext(), &fakeSubnetSpec2, serviceName).Return(fakeSubnet2, nil)
+				r.CreateResource(gomockinternal.AContext(), &fakeSubnetSpec2, serviceName).Return(fakeSubnet2, false, nil)
 				s.UpdateSubnetID(fakeSubnetSpec2.Name, to.String(fakeSubnet2.ID))
 				s.UpdateSubnetCIDRs(fakeSubnetSpec2.Name, []string{to.String(fakeSubnet2.AddressPrefix)})
 
@@ -242,7 +258,7 @@ func TestReconcileSubnets(t *testing.T) {
 			expect: func(s *mock_subnets.MockSubnetScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				s.SubnetSpecs().Return([]azure.ResourceSpecGetter{&fakeSubnetSpecNotManaged})
 
-				r.CreateResource(gomockinternal.AContext(), &fakeSubnetSpecNotManaged, serviceName).Return(fakeSubnetNotManaged, nil)
+				r.CreateResource(gomockinternal.AContext(), &fakeSubnetSpecNotManaged, serviceName).Return(fakeSubnetNotManaged, false, nil)
 				s.UpdateSubnetID(fakeSubnetSpecNotManaged.Name, to.String(fakeSubnetNotManaged.ID))
 				s.UpdateSubnetCIDRs(fakeSubnetSpecNotManaged.Name, []string{to.String(fakeSubnetNotManaged.AddressPrefix)})
 
@@ -255,7 +271,7 @@ func TestReconcileSubnets(t *testing.T) {
 			expect: func(s *mock_subnets.MockSubnetScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				s.SubnetSpecs().Return([]azure.ResourceSpecGetter{&fakeIpv6SubnetSpec})
 
-				r.CreateResource(gomockinternal.AContext(), &fakeIpv6SubnetSpec, serviceName).Return(fakeIpv6Subnet, nil)
+				r.CreateResource(gomockinternal.AContext(), &fakeIpv6SubnetSpec, serviceName).Return(fakeIpv6Subnet, false, nil)
 				s.UpdateSubnetID(fakeIpv6SubnetSpec.Name, to.String(fakeIpv6Subnet.ID))
 				s.UpdateSubnetCIDRs(fakeIpv6SubnetSpec.Name, to.StringSlice(fakeIpv6Subnet.AddressPrefixes))
 
@@ -269,11 +285,11 @@ func TestReconcileSubnets(t *testing.T) {
 			expect: func(s *mock_subnets.MockSubnetScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				s.SubnetSpecs().Return([]azure.ResourceSpecGetter{&fakeIpv6SubnetSpec, &fakeIpv6SubnetSpecCP})
 
-				r.CreateResource(gomockinternal.AContext(), &fakeIpv6SubnetSpec, serviceName).Return(fakeIpv6Subnet, nil)
+				r.CreateResource(gomockinternal.AContext(), &fakeIpv6SubnetSpec, serviceName).Return(fakeIpv6Subnet, false, nil)
 				s.UpdateSubnetID(fakeIpv6SubnetSpec.Name, to.String(fakeIpv6Subnet.ID))
 				s.UpdateSubnetCIDRs(fakeIpv6SubnetSpec.Name, to.StringSlice(fakeIpv6Subnet.AddressPrefixes))
 
-				r.CreateResource(gomockinternal.AContext(), &fakeIpv6SubnetSpecCP, serviceName).Return(fakeIpv6SubnetCP, nil)
+				r.CreateResource(gomockinternal.AContext(), &fakeIpv6SubnetSpecCP, serviceName).Return(fakeIpv6SubnetCP, false, nil)
 				s.UpdateSubnetID(fakeIpv6SubnetSpecCP.Name, to.String(fakeIpv6SubnetCP.ID))
 				s.UpdateSubnetCIDRs(fakeIpv6SubnetSpecCP.Name, to.StringSlice(fakeIpv6SubnetCP.AddressPrefixes))
 
@@ -286,7 +302,7 @@ func TestReconcileSubnets(t *testing.T) {
 			expectedError: "#: Internal Server Error: StatusCode=500",
 			expect: func(s *mock_subnets.MockSubnetScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				s.SubnetSpecs().Return([]azure.ResourceSpecGetter{&fakeSubnetSpec1})
-				r.CreateResource(gomockinternal.AContext(), &fakeSubnetSpec1, serviceName).Return(nil, internalError)
+				r.CreateResource(gomockinternal.AContext(), &fakeSubnetSpec1, serviceName).Return(nil, false, internalError)
 
 				s.IsVnetManaged().AnyTimes().Return(true)
 				s.UpdatePutStatus(infrav1.SubnetsReadyCondition, serviceName, internalError)
@@ -297,7 +313,9 @@ func TestReconcileSubnets(t *testing.T) {
 			expectedError: notASubnetErr.Error(),
 			expect: func(s *mock_subnets.MockSubnetScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				s.SubnetSpecs().Return([]azure.ResourceSpecGetter{&fakeSubnetSpec1})
-				r.CreateResource(gomockinternal.AContext(), &fakeSubnetSpec1, serviceName).Return(notASubnet, nil)
+				r.CreateResource(gomockinternal.AContext(), &fakeSubnetSpec1, serviceName).Return(notASubnet, false, nil)
+				s.IsVnetManaged().AnyTimes().Return(true)
+				s.UpdatePutStatus(infrav1.SubnetsReadyCondition, serviceName, gomockinternal.ErrStrEq(notASubnetErr.Error()))
 			},
 		},
 		{
@@ -305,9 +323,9 @@ func TestReconcileSubnets(t *testing.T) {
 			expectedError: "#: Internal Server Error: StatusCode=500",
 			expect: func(s *mock_subnets.MockSubnetScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				s.SubnetSpecs().Return([]azure.ResourceSpecGetter{&fakeSubnetSpec1, &fakeSubnetSpec2})
-				r.CreateResource(gomockinternal.AContext(), &fakeSubnetSpec1, serviceName).Return(nil, internalError)
+				r.CreateResource(gomockinternal.AContext(), &fakeSubnetSpec1, serviceName).Return(nil, false, internalError)
 
-				r.CreateResource(gomockinternal.AContext(), &fakeSubnetSpec2, serviceName).Return(fakeSubnet2, nil)
+				r.CreateResource(gomockinternal.AContext(), &fakeSubnetSpec2, serviceName).Return(fakeSubnet2, false, nil)
 				s.UpdateSubnetID(fakeSubnetSpec2.Name, to.String(fakeSubnet2.ID))
 				s.UpdateSubnetCIDRs(fakeSubnetSpec2.Name, []string{to.String(fakeSubnet2.AddressPrefix)})
 
@@ -329,6 +347,8 @@ func TestReconcileSubnets(t *testing.T) {
 			asyncMock := mock_async.NewMockReconciler(mockCtrl)
 
 			tc.expect(scopeMock.EXPECT(), asyncMock.EXPECT())
+			// The resources the subnets depend on have no operation in progress unless the test case expects otherwise.
+			scopeMock.EXPECT().GetLongRunningOperationState(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 
 			s := &Service{
 				Scope:      scopeMock,
@@ -366,8 +386,8 @@ func TestDeleteSubnets(t *testing.T) {
 			expect: func(s *mock_subnets.MockSubnetScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				s.IsVnetManaged().AnyTimes().Return(true)
 				s.SubnetSpecs().Return([]azure.ResourceSpecGetter{&fakeSubnetSpec1, &fakeSubnetSpec2})
-				r.DeleteResource(gomockinternal.AContext(), &fakeSubnetSpec1, serviceName).Return(nil)
-				r.DeleteResource(gomockinternal.AContext(), &fakeSubnetSpec2, serviceName).Return(nil)
+				r.DeleteResource(gomockinternal.AContext(), &fakeSubnetSpec1, serviceName).Return(false, nil)
+				r.DeleteResource(gomockinternal.AContext(), &fakeSubnetSpec2, serviceName).Return(false, nil)
 				s.UpdateDeleteStatus(infrav1.SubnetsReadyCondition, serviceName, nil)
 			},
 		},
@@ -377,8 +397,8 @@ func TestDeleteSubnets(t *testing.T) {
 			expect: func(s *mock_subnets.MockSubnetScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				s.IsVnetManaged().AnyTimes().Return(true)
 				s.SubnetSpecs().Return([]azure.ResourceSpecGetter{&fakeSubnetSpec1, &fakeCtrlPlaneSubnetSpec})
-				r.DeleteResource(gomockinternal.AContext(), &fakeSubnetSpec1, serviceName).Return(nil)
-				r.DeleteResource(gomockinternal.AContext(), &fakeCtrlPlaneSubnetSpec, serviceName).Return(nil)
+				r.DeleteResource(gomockinternal.AContext(), &fakeSubnetSpec1, serviceName).Return(false, nil)
+				r.DeleteResource(gomockinternal.AContext(), &fakeCtrlPlaneSubnetSpec, serviceName).Return(false, nil)
 				s.UpdateDeleteStatus(infrav1.SubnetsReadyCondition, serviceName, nil)
 			},
 		},
@@ -395,7 +415,7 @@ func TestDeleteSubnets(t *testing.T) {
 			expect: func(s *mock_subnets.MockSubnetScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				s.IsVnetManaged().AnyTimes().Return(true)
 				s.SubnetSpecs().Return([]azure.ResourceSpecGetter{&fakeSubnetSpec1})
-				r.DeleteResource(gomockinternal.AContext(), &fakeSubnetSpec1, serviceName).Return(internalError)
+				r.DeleteResource(gomockinternal.AContext(), &fakeSubnetSpec1, serviceName).Return(false, internalError)
 				s.UpdateDeleteStatus(infrav1.SubnetsReadyCondition, serviceName, internalError)
 			},
 		},
@@ -0,0 +1,82 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package usages
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-02-01/network"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
+)
+
+// NetworkClient reads the usage of the network quotas of a subscription, e.g. the number of security groups per region.
+type NetworkClient struct {
+	usages network.UsagesClient
+}
+
+// NewNetworkClient creates a new network usages client from subscription ID.
+func NewNetworkClient(auth azure.Authorizer) *NetworkClient {
+	return &NetworkClient{
+		usages: newUsagesClient(auth.SubscriptionID(), auth.BaseURI(), auth.Authorizer()),
+	}
+}
+
+// newUsagesClient creates a new network usages client from subscription ID.
+func newUsagesClient(subscriptionID string, baseURI string, authorizer autorest.Authorizer) network.UsagesClient {
+	c := network.NewUsagesClientWithBaseURI(baseURI, subscriptionID)
+	azure.SetAutoRestClientDefaults(&c.Client, authorizer)
+	return c
+}
+
+// Usage returns the current usage and the limit of the network quota with the given name in the location, e.g.
+// "NetworkSecurityGroups". It returns false if the quota is unknown.
+func (ac *NetworkClient) Usage(ctx context.Context, location, name string) (current, limit int64, found bool, err error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "usages.NetworkClient.Usage")
+	defer done()
+
+	iter, err := ac.usages.ListComplete(ctx, location)
+	if err != nil {
+		return 0, 0, false, errors.Wrapf(err, "could not list network usages in %s", location)
+	}
+
+	var usages []network.Usage
+	for iter.NotDone() {
+		usages = append(usages, iter.Value())
+		if err := iter.NextWithContext(ctx); err != nil {
+			return 0, 0, false, errors.Wrapf(err, "could not iterate network usages in %s", location)
+		}
+	}
+
+	current, limit, found = findUsage(usages, name)
+	return current, limit, found, nil
+}
+
+// findUsage returns the current usage and the limit of the quota with the given name, compared case-insensitively.
+func findUsage(usages []network.Usage, name string) (current, limit int64, found bool) {
+	for _, usage := range usages {
+		if usage.Name == nil || !strings.EqualFold(to.String(usage.Name.Value), name) {
+			continue
+		}
+		return to.Int64(usage.CurrentValue), to.Int64(usage.Limit), true
+	}
+	return 0, 0, false
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package usages
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-02-01/network"
+	"github.com/Azure/go-autorest/autorest/to"
+	. "github.com/onsi/gomega"
+)
+
+func TestFindUsage(t *testing.T) {
+	usages := []network.Usage{
+		{Name: &network.UsageName{Value: to.StringPtr("VirtualNetworks")}, CurrentValue: to.Int64Ptr(3), Limit: to.Int64Ptr(1000)},
+		{Name: nil, CurrentValue: to.Int64Ptr(1), Limit: to.Int64Ptr(1)},
+		{Name: &network.UsageName{Value: to.StringPtr("NetworkSecurityGroups")}, CurrentValue: to.Int64Ptr(4999), Limit: to.Int64Ptr(5000)},
+	}
+
+	testcases := []struct {
+		name            string
+		quota           string
+		expectedCurrent int64
+		expectedLimit   int64
+		expectedFound   bool
+	}{
+		{
+			name:            "quota is found",
+			quota:           "NetworkSecurityGroups",
+			expectedCurrent: 4999,
+			expectedLimit:   5000,
+			expectedFound:   true,
+		},
+		{
+			name:            "quota name is case-insensitive",
+			quota:           "virtualnetworks",
+			expectedCurrent: 3,
+			expectedLimit:   1000,
+			expectedFound:   true,
+		},
+		{
+			name:  "unknown quota",
+			quota: "RouteTables",
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			current, limit, found := findUsage(usages, tc.quota)
+			g.Expect(found).To(Equal(tc.expectedFound))
+			g.Expect(current).To(Equal(tc.expectedCurrent))
+			g.Expect(limit).To(Equal(tc.expectedLimit))
+		})
+	}
+}
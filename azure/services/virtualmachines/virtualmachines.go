@@ -57,13 +57,13 @@ type Service struct {
 }
 
 // New creates a new service.
-func New(scope VMScope) *Service {
+func New(scope VMScope, opts ...async.Option) *Service {
 	Client := NewClient(scope)
 	return &Service{
 		Scope:            scope,
 		interfacesGetter: networkinterfaces.NewClient(scope),
 		publicIPsClient:  publicips.NewClient(scope),
-		Reconciler:       async.New(scope, Client, Client),
+		Reconciler:       async.New(scope, Client, Client, opts...),
 	}
 }
 
@@ -72,7 +72,7 @@ func (s *Service) Reconcile(ctx context.Context) error {
 	ctx, _, done := tele.StartSpanWithLogger(ctx, "virtualmachines.Service.Reconcile")
 	defer done()
 
-	ctx, cancel := context.WithTimeout(ctx, reconciler.DefaultAzureServiceReconcileTimeout)
+	ctx, cancel := context.WithTimeout(ctx, reconciler.ServiceReconcileTimeout(s.Reconciler))
 	defer cancel()
 
 	vmSpec := s.Scope.VMSpec()
@@ -80,7 +80,7 @@ func (s *Service) Reconcile(ctx context.Context) error {
 		return nil
 	}
 
-	result, err := s.CreateResource(ctx, vmSpec, serviceName)
+	result, _, err := s.CreateResource(ctx, vmSpec, serviceName)
 	s.Scope.UpdatePutStatus(infrav1.VMRunningCondition, serviceName, err)
 	// Set the DiskReady condition here since the disk gets created with the VM.
 	s.Scope.UpdatePutStatus(infrav1.DisksReadyCondition, serviceName, err)
@@ -112,7 +112,7 @@ func (s *Service) Delete(ctx context.Context) error {
 	ctx, _, done := tele.StartSpanWithLogger(ctx, "virtualmachines.Service.Delete")
 	defer done()
 
-	ctx, cancel := context.WithTimeout(ctx, reconciler.DefaultAzureServiceReconcileTimeout)
+	ctx, cancel := context.WithTimeout(ctx, reconciler.ServiceReconcileTimeout(s.Reconciler))
 	defer cancel()
 
 	vmSpec := s.Scope.VMSpec()
@@ -120,7 +120,7 @@ func (s *Service) Delete(ctx context.Context) error {
 		return nil
 	}
 
-	err := s.DeleteResource(ctx, vmSpec, serviceName)
+	_, err := s.DeleteResource(ctx, vmSpec, serviceName)
 	if err != nil {
 		s.Scope.SetVMState(infrav1.Deleting)
 	} else {
@@ -124,7 +124,7 @@ func TestReconcileVM(t *testing.T) {
 			expectedError: "",
 			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, mnic *mock_async.MockGetterMockRecorder, mpip *mock_publicips.MockClientMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				s.VMSpec().Return(&fakeVMSpec)
-				r.CreateResource(gomockinternal.AContext(), &fakeVMSpec, serviceName).Return(fakeExistingVM, nil)
+				r.CreateResource(gomockinternal.AContext(), &fakeVMSpec, serviceName).Return(fakeExistingVM, false, nil)
 				s.UpdatePutStatus(infrav1.VMRunningCondition, serviceName, nil)
 				s.UpdatePutStatus(infrav1.DisksReadyCondition, serviceName, nil)
 				s.SetProviderID("azure://test-vm-id")
@@ -140,7 +140,7 @@ func TestReconcileVM(t *testing.T) {
 			expectedError: "#: Internal Server Error: StatusCode=500",
 			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, mnic *mock_async.MockGetterMockRecorder, mpip *mock_publicips.MockClientMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				s.VMSpec().Return(&fakeVMSpec)
-				r.CreateResource(gomockinternal.AContext(), &fakeVMSpec, serviceName).Return(nil, internalError)
+				r.CreateResource(gomockinternal.AContext(), &fakeVMSpec, serviceName).Return(nil, false, internalError)
 				s.UpdatePutStatus(infrav1.VMRunningCondition, serviceName, internalError)
 				s.UpdatePutStatus(infrav1.DisksReadyCondition, serviceName, internalError)
 			},
@@ -150,7 +150,7 @@ func TestReconcileVM(t *testing.T) {
 			expectedError: "failed to fetch VM addresses: #: Internal Server Error: StatusCode=500",
 			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, mnic *mock_async.MockGetterMockRecorder, mpip *mock_publicips.MockClientMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				s.VMSpec().Return(&fakeVMSpec)
-				r.CreateResource(gomockinternal.AContext(), &fakeVMSpec, serviceName).Return(fakeExistingVM, nil)
+				r.CreateResource(gomockinternal.AContext(), &fakeVMSpec, serviceName).Return(fakeExistingVM, false, nil)
 				s.UpdatePutStatus(infrav1.VMRunningCondition, serviceName, nil)
 				s.UpdatePutStatus(infrav1.DisksReadyCondition, serviceName, nil)
 				s.SetProviderID("azure://test-vm-id")
@@ -163,7 +163,7 @@ func TestReconcileVM(t *testing.T) {
 			expectedError: "failed to fetch VM addresses: #: Internal Server Error: StatusCode=500",
 			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, mnic *mock_async.MockGetterMockRecorder, mpip *mock_publicips.MockClientMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				s.VMSpec().Return(&fakeVMSpec)
-				r.CreateResource(gomockinternal.AContext(), &fakeVMSpec, serviceName).Return(fakeExistingVM, nil)
+				r.CreateResource(gomockinternal.AContext(), &fakeVMSpec, serviceName).Return(fakeExistingVM, false, nil)
 				s.UpdatePutStatus(infrav1.VMRunningCondition, serviceName, nil)
 				s.UpdatePutStatus(infrav1.DisksReadyCondition, serviceName, nil)
 				s.SetProviderID("azure://test-vm-id")
@@ -225,7 +225,7 @@ func TestDeleteVM(t *testing.T) {
 			expectedError: "",
 			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				s.VMSpec().AnyTimes().Return(&fakeVMSpec)
-				r.DeleteResource(gomockinternal.AContext(), &fakeVMSpec, serviceName).Return(nil)
+				r.DeleteResource(gomockinternal.AContext(), &fakeVMSpec, serviceName).Return(false, nil)
 				s.SetVMState(infrav1.Deleted)
 				s.UpdateDeleteStatus(infrav1.VMRunningCondition, serviceName, nil)
 			},
@@ -235,7 +235,7 @@ func TestDeleteVM(t *testing.T) {
 			expectedError: "#: Internal Server Error: StatusCode=500",
 			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				s.VMSpec().AnyTimes().Return(&fakeVMSpec)
-				r.DeleteResource(gomockinternal.AContext(), &fakeVMSpec, serviceName).Return(internalError)
+				r.DeleteResource(gomockinternal.AContext(), &fakeVMSpec, serviceName).Return(false, internalError)
 				s.SetVMState(infrav1.Deleting)
 				s.UpdateDeleteStatus(infrav1.VMRunningCondition, serviceName, internalError)
 			},
@@ -245,7 +245,7 @@ func TestDeleteVM(t *testing.T) {
 			expectedError: "",
 			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				s.VMSpec().AnyTimes().Return(&fakeVMSpec)
-				r.DeleteResource(gomockinternal.AContext(), &fakeVMSpec, serviceName).Return(nil)
+				r.DeleteResource(gomockinternal.AContext(), &fakeVMSpec, serviceName).Return(false, nil)
 				s.SetVMState(infrav1.Deleted)
 				s.UpdateDeleteStatus(infrav1.VMRunningCondition, serviceName, nil)
 			},
@@ -50,12 +50,12 @@ type Service struct {
 }
 
 // New creates a new service.
-func New(scope VNetScope) *Service {
+func New(scope VNetScope, opts ...async.Option) *Service {
 	client := newClient(scope)
 	return &Service{
 		Scope:      scope,
 		Getter:     client,
-		Reconciler: async.New(scope, client, client),
+		Reconciler: async.New(scope, client, client, opts...),
 	}
 }
 
@@ -63,7 +63,7 @@ func (s *Service) Reconcile(ctx context.Context) error {
 	ctx, _, done := tele.StartSpanWithLogger(ctx, "virtualnetworks.Service.Reconcile")
 	defer done()
 
-	ctx, cancel := context.WithTimeout(ctx, reconciler.DefaultAzureServiceReconcileTimeout)
+	ctx, cancel := context.WithTimeout(ctx, reconciler.ServiceReconcileTimeout(s.Reconciler))
 	defer cancel()
 
 	vnetSpec := s.Scope.VNetSpec()
@@ -71,7 +71,7 @@ func (s *Service) Reconcile(ctx context.Context) error {
 		return nil
 	}
 
-	result, err := s.CreateResource(ctx, vnetSpec, serviceName)
+	result, _, err := s.CreateResource(ctx, vnetSpec, serviceName)
 	if err == nil && result != nil {
 		existingVnet, ok := result.(network.VirtualNetwork)
 		if !ok {
@@ -100,7 +100,7 @@ func (s *Service) Delete(ctx context.Context) error {
 	ctx, log, done := tele.StartSpanWithLogger(ctx, "virtualnetworks.Service.Delete")
 	defer done()
 
-	ctx, cancel := context.WithTimeout(ctx, reconciler.DefaultAzureServiceReconcileTimeout)
+	ctx, cancel := context.WithTimeout(ctx, reconciler.ServiceReconcileTimeout(s.Reconciler))
 	defer cancel()
 
 	vnetSpec := s.Scope.VNetSpec()
@@ -124,7 +124,7 @@ func (s *Service) Delete(ctx context.Context) error {
 		return nil
 	}
 
-	err = s.DeleteResource(ctx, vnetSpec, serviceName)
+	_, err = s.DeleteResource(ctx, vnetSpec, serviceName)
 	s.Scope.UpdateDeleteStatus(infrav1.VNetReadyCondition, serviceName, err)
 	return err
 }
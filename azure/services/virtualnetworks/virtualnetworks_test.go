@@ -79,7 +79,7 @@ func TestReconcileVnet(t *testing.T) {
 			expectedError: "",
 			expect: func(s *mock_virtualnetworks.MockVNetScopeMockRecorder, m *mock_async.MockGetterMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				s.VNetSpec().Return(&fakeVNetSpec)
-				r.CreateResource(gomockinternal.AContext(), &fakeVNetSpec, serviceName).Return(nil, nil)
+				r.CreateResource(gomockinternal.AContext(), &fakeVNetSpec, serviceName).Return(nil, false, nil)
 				s.IsVnetManaged().Return(false)
 			},
 		},
@@ -88,7 +88,7 @@ func TestReconcileVnet(t *testing.T) {
 			expectedError: "",
 			expect: func(s *mock_virtualnetworks.MockVNetScopeMockRecorder, m *mock_async.MockGetterMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				s.VNetSpec().Return(&fakeVNetSpec)
-				r.CreateResource(gomockinternal.AContext(), &fakeVNetSpec, serviceName).Return(nil, nil)
+				r.CreateResource(gomockinternal.AContext(), &fakeVNetSpec, serviceName).Return(nil, false, nil)
 				s.IsVnetManaged().Return(true)
 				s.UpdatePutStatus(infrav1.VNetReadyCondition, serviceName, nil)
 			},
@@ -98,7 +98,7 @@ func TestReconcileVnet(t *testing.T) {
 			expectedError: internalError.Error(),
 			expect: func(s *mock_virtualnetworks.MockVNetScopeMockRecorder, m *mock_async.MockGetterMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				s.VNetSpec().Return(&fakeVNetSpec)
-				r.CreateResource(gomockinternal.AContext(), &fakeVNetSpec, serviceName).Return(nil, internalError)
+				r.CreateResource(gomockinternal.AContext(), &fakeVNetSpec, serviceName).Return(nil, false, internalError)
 				s.IsVnetManaged().Return(true)
 				s.UpdatePutStatus(infrav1.VNetReadyCondition, serviceName, internalError)
 			},
@@ -156,7 +156,7 @@ func TestDeleteVnet(t *testing.T) {
 				s.VNetSpec().Return(&fakeVNetSpec)
 				m.Get(gomockinternal.AContext(), &fakeVNetSpec).Return(managedVnet, nil)
 				s.ClusterName().Return("test-cluster")
-				r.DeleteResource(gomockinternal.AContext(), &fakeVNetSpec, serviceName).Return(nil)
+				r.DeleteResource(gomockinternal.AContext(), &fakeVNetSpec, serviceName).Return(false, nil)
 				s.UpdateDeleteStatus(infrav1.VNetReadyCondition, serviceName, nil)
 			},
 		},
@@ -167,7 +167,7 @@ func TestDeleteVnet(t *testing.T) {
 				s.VNetSpec().Return(&fakeVNetSpec)
 				m.Get(gomockinternal.AContext(), &fakeVNetSpec).Return(managedVnet, nil)
 				s.ClusterName().Return("test-cluster")
-				r.DeleteResource(gomockinternal.AContext(), &fakeVNetSpec, serviceName).Return(internalError)
+				r.DeleteResource(gomockinternal.AContext(), &fakeVNetSpec, serviceName).Return(false, internalError)
 				s.UpdateDeleteStatus(infrav1.VNetReadyCondition, serviceName, internalError)
 			},
 		},
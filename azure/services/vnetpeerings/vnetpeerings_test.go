@@ -90,7 +90,7 @@ func TestReconcileVnetPeerings(t *testing.T) {
 			expectedError: "",
 			expect: func(p *mock_vnetpeerings.MockVnetPeeringScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				p.VnetPeeringSpecs().Return(fakePeeringSpecs[:1])
-				r.CreateResource(gomockinternal.AContext(), &fakePeering1To2, serviceName).Return(&fakePeering1To2, nil)
+				r.CreateResource(gomockinternal.AContext(), &fakePeering1To2, serviceName).Return(&fakePeering1To2, false, nil)
 				p.UpdatePutStatus(infrav1.VnetPeeringReadyCondition, serviceName, nil)
 			},
 		},
@@ -106,8 +106,8 @@ func TestReconcileVnetPeerings(t *testing.T) {
 			expectedError: "",
 			expect: func(p *mock_vnetpeerings.MockVnetPeeringScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				p.VnetPeeringSpecs().Return(fakePeeringSpecs[:2])
-				r.CreateResource(gomockinternal.AContext(), &fakePeering1To2, serviceName).Return(&fakePeering1To2, nil)
-				r.CreateResource(gomockinternal.AContext(), &fakePeering2To1, serviceName).Return(&fakePeering2To1, nil)
+				r.CreateResource(gomockinternal.AContext(), &fakePeering1To2, serviceName).Return(&fakePeering1To2, false, nil)
+				r.CreateResource(gomockinternal.AContext(), &fakePeering2To1, serviceName).Return(&fakePeering2To1, false, nil)
 				p.UpdatePutStatus(infrav1.VnetPeeringReadyCondition, serviceName, nil)
 			},
 		},
@@ -116,9 +116,9 @@ func TestReconcileVnetPeerings(t *testing.T) {
 			expectedError: "",
 			expect: func(p *mock_vnetpeerings.MockVnetPeeringScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				p.VnetPeeringSpecs().Return(fakePeeringExtraSpecs)
-				r.CreateResource(gomockinternal.AContext(), &fakePeering1To2, serviceName).Return(&fakePeering1To2, nil)
-				r.CreateResource(gomockinternal.AContext(), &fakePeering2To1, serviceName).Return(&fakePeering2To1, nil)
-				r.CreateResource(gomockinternal.AContext(), &fakePeeringExtra, serviceName).Return(&fakePeeringExtra, nil)
+				r.CreateResource(gomockinternal.AContext(), &fakePeering1To2, serviceName).Return(&fakePeering1To2, false, nil)
+				r.CreateResource(gomockinternal.AContext(), &fakePeering2To1, serviceName).Return(&fakePeering2To1, false, nil)
+				r.CreateResource(gomockinternal.AContext(), &fakePeeringExtra, serviceName).Return(&fakePeeringExtra, false, nil)
 				p.UpdatePutStatus(infrav1.VnetPeeringReadyCondition, serviceName, nil)
 			},
 		},
@@ -127,10 +127,10 @@ func TestReconcileVnetPeerings(t *testing.T) {
 			expectedError: "",
 			expect: func(p *mock_vnetpeerings.MockVnetPeeringScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				p.VnetPeeringSpecs().Return(fakePeeringSpecs)
-				r.CreateResource(gomockinternal.AContext(), &fakePeering1To2, serviceName).Return(&fakePeering1To2, nil)
-				r.CreateResource(gomockinternal.AContext(), &fakePeering2To1, serviceName).Return(&fakePeering2To1, nil)
-				r.CreateResource(gomockinternal.AContext(), &fakePeering1To3, serviceName).Return(&fakePeering1To3, nil)
-				r.CreateResource(gomockinternal.AContext(), &fakePeering3To1, serviceName).Return(&fakePeering3To1, nil)
+				r.CreateResource(gomockinternal.AContext(), &fakePeering1To2, serviceName).Return(&fakePeering1To2, false, nil)
+				r.CreateResource(gomockinternal.AContext(), &fakePeering2To1, serviceName).Return(&fakePeering2To1, false, nil)
+				r.CreateResource(gomockinternal.AContext(), &fakePeering1To3, serviceName).Return(&fakePeering1To3, false, nil)
+				r.CreateResource(gomockinternal.AContext(), &fakePeering3To1, serviceName).Return(&fakePeering3To1, false, nil)
 				p.UpdatePutStatus(infrav1.VnetPeeringReadyCondition, serviceName, nil)
 			},
 		},
@@ -139,10 +139,10 @@ func TestReconcileVnetPeerings(t *testing.T) {
 			expectedError: "#: Internal Server Error: StatusCode=500",
 			expect: func(p *mock_vnetpeerings.MockVnetPeeringScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				p.VnetPeeringSpecs().Return(fakePeeringSpecs)
-				r.CreateResource(gomockinternal.AContext(), &fakePeering1To2, serviceName).Return(&fakePeering1To2, nil)
-				r.CreateResource(gomockinternal.AContext(), &fakePeering2To1, serviceName).Return(&fakePeering2To1, nil)
-				r.CreateResource(gomockinternal.AContext(), &fakePeering1To3, serviceName).Return(nil, internalError)
-				r.CreateResource(gomockinternal.AContext(), &fakePeering3To1, serviceName).Return(&fakePeering3To1, nil)
+				r.CreateResource(gomockinternal.AContext(), &fakePeering1To2, serviceName).Return(&fakePeering1To2, false, nil)
+				r.CreateResource(gomockinternal.AContext(), &fakePeering2To1, serviceName).Return(&fakePeering2To1, false, nil)
+				r.CreateResource(gomockinternal.AContext(), &fakePeering1To3, serviceName).Return(nil, false, internalError)
+				r.CreateResource(gomockinternal.AContext(), &fakePeering3To1, serviceName).Return(&fakePeering3To1, false, nil)
 				p.UpdatePutStatus(infrav1.VnetPeeringReadyCondition, serviceName, internalError)
 			},
 		},
@@ -151,10 +151,10 @@ func TestReconcileVnetPeerings(t *testing.T) {
 			expectedError: "#: Internal Server Error: StatusCode=500",
 			expect: func(p *mock_vnetpeerings.MockVnetPeeringScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				p.VnetPeeringSpecs().Return(fakePeeringSpecs)
-				r.CreateResource(gomockinternal.AContext(), &fakePeering1To2, serviceName).Return(&fakePeering1To2, nil)
-				r.CreateResource(gomockinternal.AContext(), &fakePeering2To1, serviceName).Return(&fakePeering2To1, nil)
-				r.CreateResource(gomockinternal.AContext(), &fakePeering1To3, serviceName).Return(nil, internalError)
-				r.CreateResource(gomockinternal.AContext(), &fakePeering3To1, serviceName).Return(&fakePeering3To1, nil)
+				r.CreateResource(gomockinternal.AContext(), &fakePeering1To2, serviceName).Return(&fakePeering1To2, false, nil)
+				r.CreateResource(gomockinternal.AContext(), &fakePeering2To1, serviceName).Return(&fakePeering2To1, false, nil)
+				r.CreateResource(gomockinternal.AContext(), &fakePeering1To3, serviceName).Return(nil, false, internalError)
+				r.CreateResource(gomockinternal.AContext(), &fakePeering3To1, serviceName).Return(&fakePeering3To1, false, nil)
 				p.UpdatePutStatus(infrav1.VnetPeeringReadyCondition, serviceName, internalError)
 			},
 		},
@@ -163,10 +163,10 @@ func TestReconcileVnetPeerings(t *testing.T) {
 			expectedError: "#: Internal Server Error: StatusCode=500",
 			expect: func(p *mock_vnetpeerings.MockVnetPeeringScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				p.VnetPeeringSpecs().Return(fakePeeringSpecs)
-				r.CreateResource(gomockinternal.AContext(), &fakePeering1To2, serviceName).Return(&fakePeering1To2, nil)
-				r.CreateResource(gomockinternal.AContext(), &fakePeering2To1, serviceName).Return(nil, internalError)
-				r.CreateResource(gomockinternal.AContext(), &fakePeering1To3, serviceName).Return(nil, notDoneError)
-				r.CreateResource(gomockinternal.AContext(), &fakePeering3To1, serviceName).Return(&fakePeering3To1, nil)
+				r.CreateResource(gomockinternal.AContext(), &fakePeering1To2, serviceName).Return(&fakePeering1To2, false, nil)
+				r.CreateResource(gomockinternal.AContext(), &fakePeering2To1, serviceName).Return(nil, false, internalError)
+				r.CreateResource(gomockinternal.AContext(), &fakePeering1To3, serviceName).Return(nil, false, notDoneError)
+				r.CreateResource(gomockinternal.AContext(), &fakePeering3To1, serviceName).Return(&fakePeering3To1, false, nil)
 				p.UpdatePutStatus(infrav1.VnetPeeringReadyCondition, serviceName, internalError)
 			},
 		},
@@ -175,10 +175,10 @@ func TestReconcileVnetPeerings(t *testing.T) {
 			expectedError: "#: Internal Server Error: StatusCode=500",
 			expect: func(p *mock_vnetpeerings.MockVnetPeeringScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				p.VnetPeeringSpecs().Return(fakePeeringSpecs)
-				r.CreateResource(gomockinternal.AContext(), &fakePeering1To2, serviceName).Return(&fakePeering1To2, nil)
-				r.CreateResource(gomockinternal.AContext(), &fakePeering2To1, serviceName).Return(&fakePeering2To1, nil)
-				r.CreateResource(gomockinternal.AContext(), &fakePeering1To3, serviceName).Return(nil, notDoneError)
-				r.CreateResource(gomockinternal.AContext(), &fakePeering3To1, serviceName).Return(nil, internalError)
+				r.CreateResource(gomockinternal.AContext(), &fakePeering1To2, serviceName).Return(&fakePeering1To2, false, nil)
+				r.CreateResource(gomockinternal.AContext(), &fakePeering2To1, serviceName).Return(&fakePeering2To1, false, nil)
+				r.CreateResource(gomockinternal.AContext(), &fakePeering1To3, serviceName).Return(nil, false, notDoneError)
+				r.CreateResource(gomockinternal.AContext(), &fakePeering3To1, serviceName).Return(nil, false, internalError)
 				p.UpdatePutStatus(infrav1.VnetPeeringReadyCondition, serviceName, internalError)
 			},
 		},
@@ -187,10 +187,10 @@ func TestReconcileVnetPeerings(t *testing.T) {
 			expectedError: "operation type  on Azure resource / is not done",
 			expect: func(p *mock_vnetpeerings.MockVnetPeeringScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				p.VnetPeeringSpecs().Return(fakePeeringSpecs)
-				r.CreateResource(gomockinternal.AContext(), &fakePeering1To2, serviceName).Return(&fakePeering1To2, nil)
-				r.CreateResource(gomockinternal.AContext(), &fakePeering2To1, serviceName).Return(&fakePeering2To1, nil)
-				r.CreateResource(gomockinternal.AContext(), &fakePeering1To3, serviceName).Return(nil, notDoneError)
-				r.CreateResource(gomockinternal.AContext(), &fakePeering3To1, serviceName).Return(&fakePeering3To1, nil)
+				r.CreateResource(gomockinternal.AContext(), &fakePeering1To2, serviceName).Return(&fakePeering1To2, false, nil)
+				r.CreateResource(gomockinternal.AContext(), &fakePeering2To1, serviceName).Return(&fakePeering2To1, false, nil)
+				r.CreateResource(gomockinternal.AContext(), &fakePeering1To3, serviceName).Return(nil, false, notDoneError)
+				r.CreateResource(gomockinternal.AContext(), &fakePeering3To1, serviceName).Return(&fakePeering3To1, false, nil)
 				p.UpdatePutStatus(infrav1.VnetPeeringReadyCondition, serviceName, notDoneError)
 			},
 		},
@@ -236,7 +236,7 @@ func TestDeleteVnetPeerings(t *testing.T) {
 			expectedError: "",
 			expect: func(p *mock_vnetpeerings.MockVnetPeeringScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				p.VnetPeeringSpecs().Return(fakePeeringSpecs[:1])
-				r.DeleteResource(gomockinternal.AContext(), &fakePeering1To2, serviceName).Return(nil)
+				r.DeleteResource(gomockinternal.AContext(), &fakePeering1To2, serviceName).Return(false, nil)
 				p.UpdateDeleteStatus(infrav1.VnetPeeringReadyCondition, serviceName, nil)
 			},
 		},
@@ -252,8 +252,8 @@ func TestDeleteVnetPeerings(t *testing.T) {
 			expectedError: "",
 			expect: func(p *mock_vnetpeerings.MockVnetPeeringScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				p.VnetPeeringSpecs().Return(fakePeeringSpecs[:2])
-				r.DeleteResource(gomockinternal.AContext(), &fakePeering1To2, serviceName).Return(nil)
-				r.DeleteResource(gomockinternal.AContext(), &fakePeering2To1, serviceName).Return(nil)
+				r.DeleteResource(gomockinternal.AContext(), &fakePeering1To2, serviceName).Return(false, nil)
+				r.DeleteResource(gomockinternal.AContext(), &fakePeering2To1, serviceName).Return(false, nil)
 				p.UpdateDeleteStatus(infrav1.VnetPeeringReadyCondition, serviceName, nil)
 			},
 		},
@@ -262,9 +262,9 @@ func TestDeleteVnetPeerings(t *testing.T) {
 			expectedError: "",
 			expect: func(p *mock_vnetpeerings.MockVnetPeeringScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				p.VnetPeeringSpecs().Return(fakePeeringExtraSpecs)
-				r.DeleteResource(gomockinternal.AContext(), &fakePeering1To2, serviceName).Return(nil)
-				r.DeleteResource(gomockinternal.AContext(), &fakePeering2To1, serviceName).Return(nil)
-				r.DeleteResource(gomockinternal.AContext(), &fakePeeringExtra, serviceName).Return(nil)
+				r.DeleteResource(gomockinternal.AContext(), &fakePeering1To2, serviceName).Return(false, nil)
+				r.DeleteResource(gomockinternal.AContext(), &fakePeering2To1, serviceName).Return(false, nil)
+				r.DeleteResource(gomockinternal.AContext(), &fakePeeringExtra, serviceName).Return(false, nil)
 				p.UpdateDeleteStatus(infrav1.VnetPeeringReadyCondition, serviceName, nil)
 			},
 		},
@@ -273,10 +273,10 @@ func TestDeleteVnetPeerings(t *testing.T) {
 			expectedError: "",
 			expect: func(p *mock_vnetpeerings.MockVnetPeeringScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				p.VnetPeeringSpecs().Return(fakePeeringSpecs)
-				r.DeleteResource(gomockinternal.AContext(), &fakePeering1To2, serviceName).Return(nil)
-				r.DeleteResource(gomockinternal.AContext(), &fakePeering2To1, serviceName).Return(nil)
-				r.DeleteResource(gomockinternal.AContext(), &fakePeering1To3, serviceName).Return(nil)
-				r.DeleteResource(gomockinternal.AContext(), &fakePeering3To1, serviceName).Return(nil)
+				r.DeleteResource(gomockinternal.AContext(), &fakePeering1To2, serviceName).Return(false, nil)
+				r.DeleteResource(gomockinternal.AContext(), &fakePeering2To1, serviceName).Return(false, nil)
+				r.DeleteResource(gomockinternal.AContext(), &fakePeering1To3, serviceName).Return(false, nil)
+				r.DeleteResource(gomockinternal.AContext(), &fakePeering3To1, serviceName).Return(false, nil)
 				p.UpdateDeleteStatus(infrav1.VnetPeeringReadyCondition, serviceName, nil)
 			},
 		},
@@ -285,10 +285,10 @@ func TestDeleteVnetPeerings(t *testing.T) {
 			expectedError: "#: Internal Server Error: StatusCode=500",
 			expect: func(p *mock_vnetpeerings.MockVnetPeeringScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				p.VnetPeeringSpecs().Return(fakePeeringSpecs)
-				r.DeleteResource(gomockinternal.AContext(), &fakePeering1To2, serviceName).Return(nil)
-				r.DeleteResource(gomockinternal.AContext(), &fakePeering2To1, serviceName).Return(nil)
-				r.DeleteResource(gomockinternal.AContext(), &fakePeering1To3, serviceName).Return(internalError)
-				r.DeleteResource(gomockinternal.AContext(), &fakePeering3To1, serviceName).Return(nil)
+				r.DeleteResource(gomockinternal.AContext(), &fakePeering1To2, serviceName).Return(false, nil)
+				r.DeleteResource(gomockinternal.AContext(), &fakePeering2To1, serviceName).Return(false, nil)
+				r.DeleteResource(gomockinternal.AContext(), &fakePeering1To3, serviceName).Return(false, internalError)
+				r.DeleteResource(gomockinternal.AContext(), &fakePeering3To1, serviceName).Return(false, nil)
 				p.UpdateDeleteStatus(infrav1.VnetPeeringReadyCondition, serviceName, internalError)
 			},
 		},
@@ -297,10 +297,10 @@ func TestDeleteVnetPeerings(t *testing.T) {
 			expectedError: "#: Internal Server Error: StatusCode=500",
 			expect: func(p *mock_vnetpeerings.MockVnetPeeringScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				p.VnetPeeringSpecs().Return(fakePeeringSpecs)
-				r.DeleteResource(gomockinternal.AContext(), &fakePeering1To2, serviceName).Return(nil)
-				r.DeleteResource(gomockinternal.AContext(), &fakePeering2To1, serviceName).Return(internalError)
-				r.DeleteResource(gomockinternal.AContext(), &fakePeering1To3, serviceName).Return(notDoneError)
-				r.DeleteResource(gomockinternal.AContext(), &fakePeering3To1, serviceName).Return(nil)
+				r.DeleteResource(gomockinternal.AContext(), &fakePeering1To2, serviceName).Return(false, nil)
+				r.DeleteResource(gomockinternal.AContext(), &fakePeering2To1, serviceName).Return(false, internalError)
+				r.DeleteResource(gomockinternal.AContext(), &fakePeering1To3, serviceName).Return(false, notDoneError)
+				r.DeleteResource(gomockinternal.AContext(), &fakePeering3To1, serviceName).Return(false, nil)
 				p.UpdateDeleteStatus(infrav1.VnetPeeringReadyCondition, serviceName, internalError)
 			},
 		},
@@ -309,10 +309,10 @@ func TestDeleteVnetPeerings(t *testing.T) {
 			expectedError: "#: Internal Server Error: StatusCode=500",
 			expect: func(p *mock_vnetpeerings.MockVnetPeeringScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				p.VnetPeeringSpecs().Return(fakePeeringSpecs)
-				r.DeleteResource(gomockinternal.AContext(), &fakePeering1To2, serviceName).Return(nil)
-				r.DeleteResource(gomockinternal.AContext(), &fakePeering2To1, serviceName).Return(nil)
-				r.DeleteResource(gomockinternal.AContext(), &fakePeering1To3, serviceName).Return(notDoneError)
-				r.DeleteResource(gomockinternal.AContext(), &fakePeering3To1, serviceName).Return(internalError)
+				r.DeleteResource(gomockinternal.AContext(), &fakePeering1To2, serviceName).Return(false, nil)
+				r.DeleteResource(gomockinternal.AContext(), &fakePeering2To1, serviceName).Return(false, nil)
+				r.DeleteResource(gomockinternal.AContext(), &fakePeering1To3, serviceName).Return(false, notDoneError)
+				r.DeleteResource(gomockinternal.AContext(), &fakePeering3To1, serviceName).Return(false, internalError)
 				p.UpdateDeleteStatus(infrav1.VnetPeeringReadyCondition, serviceName, internalError)
 			},
 		},
@@ -321,10 +321,10 @@ func TestDeleteVnetPeerings(t *testing.T) {
 			expectedError: "operation type  on Azure resource / is not done",
 			expect: func(p *mock_vnetpeerings.MockVnetPeeringScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				p.VnetPeeringSpecs().Return(fakePeeringSpecs)
-				r.DeleteResource(gomockinternal.AContext(), &fakePeering1To2, serviceName).Return(nil)
-				r.DeleteResource(gomockinternal.AContext(), &fakePeering2To1, serviceName).Return(nil)
-				r.DeleteResource(gomockinternal.AContext(), &fakePeering1To3, serviceName).Return(notDoneError)
-				r.DeleteResource(gomockinternal.AContext(), &fakePeering3To1, serviceName).Return(nil)
+				r.DeleteResource(gomockinternal.AContext(), &fakePeering1To2, serviceName).Return(false, nil)
+				r.DeleteResource(gomockinternal.AContext(), &fakePeering2To1, serviceName).Return(false, nil)
+				r.DeleteResource(gomockinternal.AContext(), &fakePeering1To3, serviceName).Return(false, notDoneError)
+				r.DeleteResource(gomockinternal.AContext(), &fakePeering3To1, serviceName).Return(false, nil)
 				p.UpdateDeleteStatus(infrav1.VnetPeeringReadyCondition, serviceName, notDoneError)
 			},
 		},
@@ -0,0 +1,123 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/activitylogs"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/async"
+	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// AsyncOptions configures the async services the reconcilers use to create and delete Azure resources. The same
+// AsyncOptions should be passed to all the reconcilers of a manager, so that the limiters it holds bound the requests
+// of all the clusters rather than of each one. The zero value configures no option.
+type AsyncOptions struct {
+	// RateLimiter, if set, bounds the rate of the create and delete requests of all the services, and backs all of them
+	// off while Azure throttles requests.
+	RateLimiter *async.RateLimiter
+	// ConcurrencyLimiter, if set, bounds the create and delete requests of all the services in flight at the same time.
+	ConcurrencyLimiter *async.ConcurrencyLimiter
+	// FairScheduler, if set, bounds the operations of all the services being started at the same time, and shares the
+	// slots fairly between the clusters.
+	FairScheduler *async.FairScheduler
+	// SecurityGroupsConcurrency is the number of security groups of a cluster the security groups service processes in
+	// parallel. They are processed sequentially when it is lower than 2.
+	SecurityGroupsConcurrency int
+	// ResolveFailures, when true, looks up in the Azure Activity Log why the operations of the services failed, e.g.
+	// denied by Azure Policy, and adds the reason to the reported error. Each lookup costs extra Azure API calls.
+	ResolveFailures bool
+	// MigrateFutures, when true, converts the long-running operation states whose data cannot be decoded but is in a
+	// known format into the current format rather than resetting them, see async.EncodingMigrator.
+	MigrateFutures bool
+	// MaxRequeueAfter, if set, doubles the requeue interval of the resources with an ongoing operation each time the
+	// operation is found not done, up to MaxRequeueAfter, so that long operations are polled less often.
+	MaxRequeueAfter time.Duration
+	// RequeueJitter, if set, moves the requeue intervals of the resources with an ongoing operation randomly by up to
+	// ±RequeueJitter of their value, e.g. 0.1 for ±10%, so that the operations started together are not polled together.
+	RequeueJitter float64
+	// MaxAttempts, if set, is the number of times a long-running operation is checked on before giving up on it and
+	// reporting its last error as terminal.
+	MaxAttempts int
+	// EventRecorder, if set, records events on the object owning the resources when their long-running operations
+	// complete and when their operations fail with a terminal error.
+	EventRecorder record.EventRecorder
+	// AuditOperations, when true, logs each create, update or delete request made to Azure and the outcome of each
+	// operation, see async.AuditLogHooks.
+	AuditOperations bool
+	// StableCorrelationID, when true, sends a correlation ID derived from the UID and generation of the object owning the
+	// resources with every Azure request, so that the requests made for the same generation of the object can be looked
+	// up by the same ID, rather than the correlation ID of each reconcile.
+	StableCorrelationID bool
+	// RequeueAfter, if set, is the interval after which the resources with an ongoing operation are reconciled again,
+	// rather than reconciler.DefaultReconcilerRequeue.
+	RequeueAfter time.Duration
+	// ServiceReconcileTimeout, if set, is the timeout of the Reconcile and Delete of each service, rather than
+	// reconciler.DefaultAzureServiceReconcileTimeout, for subscriptions where Azure is slow to accept requests.
+	ServiceReconcileTimeout time.Duration
+}
+
+// ServiceOptions returns the options of the async services reconciling the resources of the given cluster with the
+// given credentials, on behalf of the given owner, e.g. the AzureCluster.
+func (o AsyncOptions) ServiceOptions(cluster types.NamespacedName, auth azure.Authorizer, owner client.Object) []async.Option {
+	var opts []async.Option
+	if o.RateLimiter != nil {
+		opts = append(opts, async.WithRateLimiter(o.RateLimiter))
+	}
+	if o.ConcurrencyLimiter != nil {
+		opts = append(opts, async.WithConcurrencyLimiter(o.ConcurrencyLimiter, 1))
+	}
+	if o.FairScheduler != nil {
+		opts = append(opts, async.WithFairScheduler(o.FairScheduler, cluster.String()))
+	}
+	if o.ResolveFailures {
+		opts = append(opts, async.WithFailureResolver(activitylogs.NewResolver(auth)))
+	}
+	if o.MigrateFutures {
+		opts = append(opts, async.WithFutureMigrator(async.EncodingMigrator{}))
+	}
+	if o.MaxRequeueAfter > 0 {
+		opts = append(opts, async.WithBackoff(o.MaxRequeueAfter))
+	}
+	if o.RequeueJitter > 0 {
+		opts = append(opts, async.WithJitter(o.RequeueJitter, nil))
+	}
+	if o.MaxAttempts > 0 {
+		opts = append(opts, async.WithMaxAttempts(o.MaxAttempts))
+	}
+	if o.EventRecorder != nil {
+		opts = append(opts, async.WithEventRecorder(o.EventRecorder, owner))
+	}
+	if o.AuditOperations {
+		opts = append(opts, async.WithOperationHooks(async.AuditLogHooks()))
+	}
+	if o.StableCorrelationID {
+		opts = append(opts, async.WithCorrelationID(tele.CorrIDFromObject(owner.GetUID(), owner.GetGeneration())))
+	}
+	if o.RequeueAfter > 0 {
+		opts = append(opts, async.WithRequeueAfter(o.RequeueAfter))
+	}
+	if o.ServiceReconcileTimeout > 0 {
+		opts = append(opts, async.WithReconcileTimeout(o.ServiceReconcileTimeout))
+	}
+	return opts
+}
@@ -50,18 +50,23 @@ type AzureClusterReconciler struct {
 	Recorder                  record.EventRecorder
 	ReconcileTimeout          time.Duration
 	WatchFilterValue          string
+	ConditionSeverities       scope.ConditionSeverities
+	AsyncOptions              AsyncOptions
 	createAzureClusterService azureClusterServiceCreator
 }
 
-type azureClusterServiceCreator func(clusterScope *scope.ClusterScope) (*azureClusterService, error)
+type azureClusterServiceCreator func(clusterScope *scope.ClusterScope, asyncOptions AsyncOptions) (*azureClusterService, error)
 
-// NewAzureClusterReconciler returns a new AzureClusterReconciler instance.
-func NewAzureClusterReconciler(client client.Client, recorder record.EventRecorder, reconcileTimeout time.Duration, watchFilterValue string) *AzureClusterReconciler {
+// NewAzureClusterReconciler returns a new AzureClusterReconciler instance. The condition severities override the
+// severities of the conditions reporting the outcomes of the reconciles of the services, and may be nil.
+func NewAzureClusterReconciler(client client.Client, recorder record.EventRecorder, reconcileTimeout time.Duration, watchFilterValue string, conditionSeverities scope.ConditionSeverities, asyncOptions AsyncOptions) *AzureClusterReconciler {
 	acr := &AzureClusterReconciler{
-		Client:           client,
-		Recorder:         recorder,
-		ReconcileTimeout: reconcileTimeout,
-		WatchFilterValue: watchFilterValue,
+		Client:              client,
+		Recorder:            recorder,
+		ReconcileTimeout:    reconcileTimeout,
+		WatchFilterValue:    watchFilterValue,
+		ConditionSeverities: conditionSeverities,
+		AsyncOptions:        asyncOptions,
 	}
 
 	acr.createAzureClusterService = newAzureClusterService
@@ -111,6 +116,7 @@ func (acr *AzureClusterReconciler) SetupWithManager(ctx context.Context, mgr ctr
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=azuremachinetemplates;azuremachinetemplates/status,verbs=get;list;watch
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=azureclusteridentities;azureclusteridentities/status,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=namespaces,verbs=list;
+// +kubebuilder:rbac:groups="",resources=configmaps;secrets,verbs=get;list;watch
 
 // Reconcile idempotently gets, creates, and updates a cluster.
 func (acr *AzureClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, reterr error) {
@@ -174,9 +180,10 @@ func (acr *AzureClusterReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 
 	// Create the scope.
 	clusterScope, err := scope.NewClusterScope(ctx, scope.ClusterScopeParams{
-		Client:       acr.Client,
-		Cluster:      cluster,
-		AzureCluster: azureCluster,
+		Client:              acr.Client,
+		Cluster:             cluster,
+		AzureCluster:        azureCluster,
+		ConditionSeverities: acr.ConditionSeverities,
 	})
 	if err != nil {
 		err = errors.Wrap(err, "failed to create scope")
@@ -214,7 +221,10 @@ func (acr *AzureClusterReconciler) reconcileNormal(ctx context.Context, clusterS
 		return reconcile.Result{}, err
 	}
 
-	acs, err := acr.createAzureClusterService(clusterScope)
+	// Drop the long running operation states an administrator asked to reset before the services resume them.
+	clusterScope.ResetOperations(ctx)
+
+	acs, err := acr.createAzureClusterService(clusterScope, acr.AsyncOptions)
 	if err != nil {
 		return reconcile.Result{}, errors.Wrap(err, "failed to create a new AzureClusterReconciler")
 	}
@@ -231,7 +241,8 @@ func (acr *AzureClusterReconciler) reconcileNormal(ctx context.Context, clusterS
 			}
 			if reconcileError.IsTransient() {
 				if azure.IsOperationNotDoneError(reconcileError) {
-					log.V(2).Info(fmt.Sprintf("AzureCluster reconcile not done: %s", reconcileError.Error()))
+					notDoneErr, _ := azure.AsOperationNotDoneError(reconcileError)
+					log.V(2).Info(fmt.Sprintf("AzureCluster reconcile not done: %s", reconcileError.Error()), "operationType", notDoneErr.OperationType(), "resourceGroup", notDoneErr.ResourceGroup(), "resourceName", notDoneErr.ResourceName(), "serviceName", notDoneErr.ServiceName(), "requeueAfter", notDoneErr.RequeueAfter())
 				} else {
 					log.V(2).Info(fmt.Sprintf("transient failure to reconcile AzureCluster, retrying: %s", reconcileError.Error()))
 				}
@@ -272,23 +283,22 @@ func (acr *AzureClusterReconciler) reconcileDelete(ctx context.Context, clusterS
 		return reconcile.Result{}, err
 	}
 
-	acs, err := acr.createAzureClusterService(clusterScope)
+	clusterScope.ResetOperations(ctx)
+
+	acs, err := acr.createAzureClusterService(clusterScope, acr.AsyncOptions)
 	if err != nil {
 		return reconcile.Result{}, errors.Wrap(err, "failed to create a new AzureClusterReconciler")
 	}
 
 	if err := acs.Delete(ctx); err != nil {
 		// Handle transient errors
-		var reconcileError azure.ReconcileError
-		if errors.As(err, &reconcileError) {
-			if reconcileError.IsTransient() {
-				if azure.IsOperationNotDoneError(reconcileError) {
-					log.V(2).Info(fmt.Sprintf("AzureCluster delete not done: %s", reconcileError.Error()))
-				} else {
-					log.V(2).Info("transient failure to delete AzureCluster, retrying")
-				}
-				return reconcile.Result{RequeueAfter: reconcileError.RequeueAfter()}, nil
+		if requeueAfter, ok := azure.RequeueAfter(err); ok {
+			if azure.IsOperationNotDoneError(err) {
+				log.V(2).Info(fmt.Sprintf("AzureCluster delete not done: %s", err.Error()), "requeueAfter", requeueAfter)
+			} else {
+				log.V(2).Info("transient failure to delete AzureCluster, retrying", "requeueAfter", requeueAfter)
 			}
+			return reconcile.Result{RequeueAfter: requeueAfter}, nil
 		}
 
 		wrappedErr := errors.Wrapf(err, "error deleting AzureCluster %s/%s", azureCluster.Namespace, azureCluster.Name)
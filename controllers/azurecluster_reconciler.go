@@ -20,8 +20,10 @@ import (
 	"context"
 
 	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/cluster-api-provider-azure/azure"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/scope"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/async"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/bastionhosts"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/groups"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/loadbalancers"
@@ -33,8 +35,10 @@ import (
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/securitygroups"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/subnets"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/tags"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/usages"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/virtualnetworks"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/vnetpeerings"
+	"sigs.k8s.io/cluster-api-provider-azure/feature"
 	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 )
@@ -58,26 +62,51 @@ type azureClusterService struct {
 }
 
 // newAzureClusterService populates all the services based on input scope.
-func newAzureClusterService(scope *scope.ClusterScope) (*azureClusterService, error) {
+func newAzureClusterService(scope *scope.ClusterScope, asyncOptions AsyncOptions) (*azureClusterService, error) {
 	skuCache, err := resourceskus.GetCache(scope, scope.Location())
 	if err != nil {
 		return nil, errors.Wrap(err, "failed creating a NewCache")
 	}
+	cluster := types.NamespacedName{Namespace: scope.Namespace(), Name: scope.ClusterName()}
+	opts := asyncOptions.ServiceOptions(cluster, scope, scope.AzureCluster)
+	securityGroupOpts := append(asyncOptions.ServiceOptions(cluster, scope, scope.AzureCluster),
+		// Operations are restarted when the rules of a security group are edited while they are in progress.
+		async.WithRestartOnSpecChange(),
+		// Security groups that were just created are not created again while Azure does not report them yet.
+		async.WithNotFoundGracePeriod(securitygroups.NotFoundGracePeriod),
+		// Security groups whose rules were already applied are not updated again, e.g. after a controller restart.
+		async.WithAppliedParametersGuard(),
+		// Security groups that are already gone are not deleted again, e.g. while their subnets are deleted on teardown.
+		async.WithExistenceCheckBeforeDelete(),
+		// Updates of security groups usually complete within seconds, so they are checked on right after they start.
+		async.WithPollAfterCreate(),
+	)
+	if scope.SecurityGroupsQuotaCheck() {
+		// Security groups are only created if the quota of security groups of their region is not exhausted.
+		securityGroupOpts = append(securityGroupOpts, async.WithQuotaCheck(usages.NewNetworkClient(scope)))
+	}
+	if scope.SecurityGroupsDryRun() {
+		securityGroupOpts = append(securityGroupOpts, async.WithDryRun())
+	}
+	securityGroupSvc := securitygroups.New(scope, securityGroupOpts...)
+	securityGroupSvc.ValidateCustomVNet = feature.Gates.Enabled(feature.CustomVNetNSGValidation)
+	securityGroupSvc.PreserveUnowned = feature.Gates.Enabled(feature.PreserveUnownedSecurityGroups)
+	securityGroupSvc.Concurrency = asyncOptions.SecurityGroupsConcurrency
 
 	return &azureClusterService{
 		scope:            scope,
-		groupsSvc:        groups.New(scope),
-		vnetSvc:          virtualnetworks.New(scope),
-		securityGroupSvc: securitygroups.New(scope),
-		routeTableSvc:    routetables.New(scope),
-		natGatewaySvc:    natgateways.New(scope),
-		subnetsSvc:       subnets.New(scope),
+		groupsSvc:        groups.New(scope, opts...),
+		vnetSvc:          virtualnetworks.New(scope, opts...),
+		securityGroupSvc: securityGroupSvc,
+		routeTableSvc:    routetables.New(scope, opts...),
+		natGatewaySvc:    natgateways.New(scope, opts...),
+		subnetsSvc:       subnets.New(scope, opts...),
 		publicIPSvc:      publicips.New(scope),
-		loadBalancerSvc:  loadbalancers.New(scope),
+		loadBalancerSvc:  loadbalancers.New(scope, opts...),
 		privateDNSSvc:    privatedns.New(scope),
-		bastionSvc:       bastionhosts.New(scope),
+		bastionSvc:       bastionhosts.New(scope, opts...),
 		skuCache:         skuCache,
-		peeringsSvc:      vnetpeerings.New(scope),
+		peeringsSvc:      vnetpeerings.New(scope, opts...),
 		tagsSvc:          tags.New(scope),
 	}, nil
 }
@@ -89,6 +118,9 @@ func (s *azureClusterService) Reconcile(ctx context.Context) error {
 	ctx, _, done := tele.StartSpanWithLogger(ctx, "controllers.azureClusterService.Reconcile")
 	defer done()
 
+	// Resources read several times by a service are only read once per reconcile.
+	ctx = async.WithGetCache(ctx)
+
 	if err := s.setFailureDomainsForLocation(ctx); err != nil {
 		return errors.Wrap(err, "failed to get availability zones")
 	}
@@ -152,6 +184,23 @@ func (s *azureClusterService) Delete(ctx context.Context) error {
 	ctx, _, done := tele.StartSpanWithLogger(ctx, "controllers.azureClusterService.Delete")
 	defer done()
 
+	// Resources read several times by a service are only read once per reconcile.
+	ctx = async.WithGetCache(ctx)
+
+	// Flow logs live in the resource group of the network watcher, so they are not deleted with the cluster's.
+	if flowLogDeleter, ok := s.securityGroupSvc.(securitygroups.FlowLogDeleter); ok && s.scope.SecurityGroupFlowLogsEnabled() {
+		if err := flowLogDeleter.DeleteFlowLogs(ctx); err != nil {
+			return errors.Wrap(err, "failed to delete network security group flow logs")
+		}
+	}
+
+	// Diagnostic settings outlive their security groups, so they are not deleted with the cluster's resource group either.
+	if diagnosticSettingsDeleter, ok := s.securityGroupSvc.(securitygroups.DiagnosticSettingsDeleter); ok && s.scope.SecurityGroupDiagnosticSettingsEnabled() {
+		if err := diagnosticSettingsDeleter.DeleteDiagnosticSettings(ctx); err != nil {
+			return errors.Wrap(err, "failed to delete network security group diagnostic settings")
+		}
+	}
+
 	if err := s.groupsSvc.Delete(ctx); err != nil {
 		if errors.Is(err, azure.ErrNotOwned) {
 			if err := s.bastionSvc.Delete(ctx); err != nil {
@@ -196,6 +245,12 @@ func (s *azureClusterService) Delete(ctx context.Context) error {
 		} else {
 			return errors.Wrap(err, "failed to delete resource group")
 		}
+	} else if s.scope.HasSecurityGroupsOutsideResourceGroup() {
+		// Security groups in another resource group are not deleted with the resource group of the cluster. The ones
+		// that were in it are already gone and are skipped as not found.
+		if err := s.securityGroupSvc.Delete(ctx); err != nil {
+			return errors.Wrap(err, "failed to delete network security group")
+		}
 	}
 
 	return nil
@@ -37,6 +37,7 @@ type expect func(grp *mock_azure.MockReconcilerMockRecorder, vnet *mock_azure.Mo
 func TestAzureClusterReconcilerDelete(t *testing.T) {
 	cases := map[string]struct {
 		expectedError string
+		subnets       infrav1.Subnets
 		expect        expect
 	}{
 		"Resource Group is deleted successfully": {
@@ -46,6 +47,17 @@ func TestAzureClusterReconcilerDelete(t *testing.T) {
 					grp.Delete(gomockinternal.AContext()).Return(nil))
 			},
 		},
+		"Security groups outside of the Resource Group are deleted after it": {
+			expectedError: "",
+			subnets: infrav1.Subnets{
+				{Name: "node-subnet", SecurityGroup: infrav1.SecurityGroup{Name: "node-nsg", SecurityGroupClass: infrav1.SecurityGroupClass{ResourceGroup: "network-rg"}}},
+			},
+			expect: func(grp *mock_azure.MockReconcilerMockRecorder, vnet *mock_azure.MockReconcilerMockRecorder, sg *mock_azure.MockReconcilerMockRecorder, rt *mock_azure.MockReconcilerMockRecorder, sn *mock_azure.MockReconcilerMockRecorder, natg *mock_azure.MockReconcilerMockRecorder, pip *mock_azure.MockReconcilerMockRecorder, lb *mock_azure.MockReconcilerMockRecorder, dns *mock_azure.MockReconcilerMockRecorder, bastion *mock_azure.MockReconcilerMockRecorder, peer *mock_azure.MockReconcilerMockRecorder) {
+				gomock.InOrder(
+					grp.Delete(gomockinternal.AContext()).Return(nil),
+					sg.Delete(gomockinternal.AContext()))
+			},
+		},
 		"Resource Group delete fails": {
 			expectedError: "failed to delete resource group: internal error",
 			expect: func(grp *mock_azure.MockReconcilerMockRecorder, vnet *mock_azure.MockReconcilerMockRecorder, sg *mock_azure.MockReconcilerMockRecorder, rt *mock_azure.MockReconcilerMockRecorder, sn *mock_azure.MockReconcilerMockRecorder, natg *mock_azure.MockReconcilerMockRecorder, pip *mock_azure.MockReconcilerMockRecorder, lb *mock_azure.MockReconcilerMockRecorder, dns *mock_azure.MockReconcilerMockRecorder, bastion *mock_azure.MockReconcilerMockRecorder, peer *mock_azure.MockReconcilerMockRecorder) {
@@ -124,7 +136,11 @@ func TestAzureClusterReconcilerDelete(t *testing.T) {
 
 			s := &azureClusterService{
 				scope: &scope.ClusterScope{
-					AzureCluster: &infrav1.AzureCluster{},
+					AzureCluster: &infrav1.AzureCluster{
+						Spec: infrav1.AzureClusterSpec{
+							NetworkSpec: infrav1.NetworkSpec{Subnets: tc.subnets},
+						},
+					},
 				},
 				groupsSvc:        groupsMock,
 				vnetSvc:          vnetMock,
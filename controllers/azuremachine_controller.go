@@ -51,18 +51,20 @@ type AzureMachineReconciler struct {
 	Recorder                  record.EventRecorder
 	ReconcileTimeout          time.Duration
 	WatchFilterValue          string
+	AsyncOptions              AsyncOptions
 	createAzureMachineService azureMachineServiceCreator
 }
 
-type azureMachineServiceCreator func(machineScope *scope.MachineScope) (*azureMachineService, error)
+type azureMachineServiceCreator func(machineScope *scope.MachineScope, asyncOptions AsyncOptions) (*azureMachineService, error)
 
 // NewAzureMachineReconciler returns a new AzureMachineReconciler instance.
-func NewAzureMachineReconciler(client client.Client, recorder record.EventRecorder, reconcileTimeout time.Duration, watchFilterValue string) *AzureMachineReconciler {
+func NewAzureMachineReconciler(client client.Client, recorder record.EventRecorder, reconcileTimeout time.Duration, watchFilterValue string, asyncOptions AsyncOptions) *AzureMachineReconciler {
 	amr := &AzureMachineReconciler{
 		Client:           client,
 		Recorder:         recorder,
 		ReconcileTimeout: reconcileTimeout,
 		WatchFilterValue: watchFilterValue,
+		AsyncOptions:     asyncOptions,
 	}
 
 	amr.createAzureMachineService = newAzureMachineService
@@ -284,7 +286,7 @@ func (amr *AzureMachineReconciler) reconcileNormal(ctx context.Context, machineS
 		return reconcile.Result{}, errors.Wrap(err, "failed to init machine scope cache")
 	}
 
-	ams, err := amr.createAzureMachineService(machineScope)
+	ams, err := amr.createAzureMachineService(machineScope, amr.AsyncOptions)
 	if err != nil {
 		return reconcile.Result{}, errors.Wrap(err, "failed to create azure machine service")
 	}
@@ -315,7 +317,8 @@ func (amr *AzureMachineReconciler) reconcileNormal(ctx context.Context, machineS
 
 			if reconcileError.IsTransient() {
 				if azure.IsOperationNotDoneError(reconcileError) {
-					log.V(2).Info(fmt.Sprintf("AzureMachine reconcile not done: %s", reconcileError.Error()))
+					notDoneErr, _ := azure.AsOperationNotDoneError(reconcileError)
+					log.V(2).Info(fmt.Sprintf("AzureMachine reconcile not done: %s", reconcileError.Error()), "operationType", notDoneErr.OperationType(), "resourceGroup", notDoneErr.ResourceGroup(), "resourceName", notDoneErr.ResourceName(), "serviceName", notDoneErr.ServiceName(), "requeueAfter", notDoneErr.RequeueAfter())
 				} else {
 					log.V(2).Info(fmt.Sprintf("transient failure to reconcile AzureMachine, retrying: %s", reconcileError.Error()))
 				}
@@ -343,23 +346,20 @@ func (amr *AzureMachineReconciler) reconcileDelete(ctx context.Context, machineS
 
 	if ShouldDeleteIndividualResources(ctx, clusterScope) {
 		log.Info("Deleting AzureMachine")
-		ams, err := amr.createAzureMachineService(machineScope)
+		ams, err := amr.createAzureMachineService(machineScope, amr.AsyncOptions)
 		if err != nil {
 			return reconcile.Result{}, errors.Wrap(err, "failed to create azure machine service")
 		}
 
 		if err := ams.Delete(ctx); err != nil {
 			// Handle transient errors
-			var reconcileError azure.ReconcileError
-			if errors.As(err, &reconcileError) {
-				if reconcileError.IsTransient() {
-					if azure.IsOperationNotDoneError(reconcileError) {
-						log.V(2).Info(fmt.Sprintf("AzureMachine delete not done: %s", reconcileError.Error()))
-					} else {
-						log.V(2).Info("transient failure to delete AzureMachine, retrying")
-					}
-					return reconcile.Result{RequeueAfter: reconcileError.RequeueAfter()}, nil
+			if requeueAfter, ok := azure.RequeueAfter(err); ok {
+				if azure.IsOperationNotDoneError(err) {
+					log.V(2).Info(fmt.Sprintf("AzureMachine delete not done: %s", err.Error()), "requeueAfter", requeueAfter)
+				} else {
+					log.V(2).Info("transient failure to delete AzureMachine, retrying", "requeueAfter", requeueAfter)
 				}
+				return reconcile.Result{RequeueAfter: requeueAfter}, nil
 			}
 
 			amr.Recorder.Eventf(machineScope.AzureMachine, corev1.EventTypeWarning, "Error deleting AzureMachine", errors.Wrapf(err, "error deleting AzureMachine %s/%s", machineScope.Namespace(), machineScope.Name()).Error())
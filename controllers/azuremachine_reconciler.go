@@ -20,8 +20,10 @@ import (
 	"context"
 
 	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/cluster-api-provider-azure/azure"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/scope"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/async"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/availabilitysets"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/disks"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/inboundnatrules"
@@ -53,23 +55,24 @@ type azureMachineService struct {
 var _ azure.Reconciler = (*azureMachineService)(nil)
 
 // newAzureMachineService populates all the services based on input scope.
-func newAzureMachineService(machineScope *scope.MachineScope) (*azureMachineService, error) {
+func newAzureMachineService(machineScope *scope.MachineScope, asyncOptions AsyncOptions) (*azureMachineService, error) {
 	cache, err := resourceskus.GetCache(machineScope, machineScope.Location())
 	if err != nil {
 		return nil, errors.Wrap(err, "failed creating a NewCache")
 	}
+	opts := asyncOptions.ServiceOptions(types.NamespacedName{Namespace: machineScope.Namespace(), Name: machineScope.ClusterName()}, machineScope, machineScope.AzureMachine)
 
 	return &azureMachineService{
 		scope:                machineScope,
-		inboundNatRulesSvc:   inboundnatrules.New(machineScope),
-		networkInterfacesSvc: networkinterfaces.New(machineScope, cache),
-		virtualMachinesSvc:   virtualmachines.New(machineScope),
+		inboundNatRulesSvc:   inboundnatrules.New(machineScope, opts...),
+		networkInterfacesSvc: networkinterfaces.New(machineScope, cache, opts...),
+		virtualMachinesSvc:   virtualmachines.New(machineScope, opts...),
 		roleAssignmentsSvc:   roleassignments.New(machineScope),
-		disksSvc:             disks.New(machineScope),
+		disksSvc:             disks.New(machineScope, opts...),
 		publicIPsSvc:         publicips.New(machineScope),
 		tagsSvc:              tags.New(machineScope),
 		vmExtensionsSvc:      vmextensions.New(machineScope),
-		availabilitySetsSvc:  availabilitysets.New(machineScope, cache),
+		availabilitySetsSvc:  availabilitysets.New(machineScope, cache, opts...),
 		skuCache:             cache,
 	}, nil
 }
@@ -79,6 +82,9 @@ func (s *azureMachineService) Reconcile(ctx context.Context) error {
 	ctx, _, done := tele.StartSpanWithLogger(ctx, "controllers.azureMachineService.Reconcile")
 	defer done()
 
+	// Resources read several times by a service are only read once per reconcile.
+	ctx = async.WithGetCache(ctx)
+
 	if err := s.scope.SetSubnetName(); err != nil {
 		return errors.Wrap(err, "failed defaulting subnet name")
 	}
@@ -123,6 +129,9 @@ func (s *azureMachineService) Delete(ctx context.Context) error {
 	ctx, _, done := tele.StartSpanWithLogger(ctx, "controllers.azureMachineService.Delete")
 	defer done()
 
+	// Resources read several times by a service are only read once per reconcile.
+	ctx = async.WithGetCache(ctx)
+
 	if err := s.virtualMachinesSvc.Delete(ctx); err != nil {
 		return errors.Wrap(err, "failed to delete machine")
 	}
@@ -79,6 +79,9 @@ func (src *AzureMachinePool) ConvertTo(dstRaw conversion.Hub) error {
 	for i, r := range restored.Status.LongRunningOperationStates {
 		if r.Name == dst.Status.LongRunningOperationStates[i].Name {
 			dst.Status.LongRunningOperationStates[i].ServiceName = r.ServiceName
+			dst.Status.LongRunningOperationStates[i].StartTime = r.StartTime
+			dst.Status.LongRunningOperationStates[i].Status = r.Status
+			dst.Status.LongRunningOperationStates[i].ParametersHash = r.ParametersHash
 		}
 	}
 
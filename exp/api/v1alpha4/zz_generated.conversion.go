@@ -585,7 +585,17 @@ func Convert_v1beta1_AzureMachinePoolMachine_To_v1alpha4_AzureMachinePoolMachine
 
 func autoConvert_v1alpha4_AzureMachinePoolMachineList_To_v1beta1_AzureMachinePoolMachineList(in *AzureMachinePoolMachineList, out *v1beta1.AzureMachinePoolMachineList, s conversion.Scope) error {
 	out.ListMeta = in.ListMeta
-	out.Items = *(*[]v1beta1.AzureMachinePoolMachine)(unsafe.Pointer(&in.Items))
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]v1beta1.AzureMachinePoolMachine, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha4_AzureMachinePoolMachine_To_v1beta1_AzureMachinePoolMachine(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Items = nil
+	}
 	return nil
 }
 
@@ -596,7 +606,17 @@ func Convert_v1alpha4_AzureMachinePoolMachineList_To_v1beta1_AzureMachinePoolMac
 
 func autoConvert_v1beta1_AzureMachinePoolMachineList_To_v1alpha4_AzureMachinePoolMachineList(in *v1beta1.AzureMachinePoolMachineList, out *AzureMachinePoolMachineList, s conversion.Scope) error {
 	out.ListMeta = in.ListMeta
-	out.Items = *(*[]AzureMachinePoolMachine)(unsafe.Pointer(&in.Items))
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]AzureMachinePoolMachine, len(*in))
+		for i := range *in {
+			if err := Convert_v1beta1_AzureMachinePoolMachine_To_v1alpha4_AzureMachinePoolMachine(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Items = nil
+	}
 	return nil
 }
 
@@ -635,7 +655,17 @@ func autoConvert_v1alpha4_AzureMachinePoolMachineStatus_To_v1beta1_AzureMachineP
 	out.FailureReason = (*errors.MachineStatusError)(unsafe.Pointer(in.FailureReason))
 	out.FailureMessage = (*string)(unsafe.Pointer(in.FailureMessage))
 	out.Conditions = *(*apiv1beta1.Conditions)(unsafe.Pointer(&in.Conditions))
-	out.LongRunningOperationStates = *(*clusterapiproviderazureapiv1beta1.Futures)(unsafe.Pointer(&in.LongRunningOperationStates))
+	if in.LongRunningOperationStates != nil {
+		in, out := &in.LongRunningOperationStates, &out.LongRunningOperationStates
+		*out = make(clusterapiproviderazureapiv1beta1.Futures, len(*in))
+		for i := range *in {
+			if err := clusterapiproviderazureapiv1alpha4.Convert_v1alpha4_Future_To_v1beta1_Future(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.LongRunningOperationStates = nil
+	}
 	out.LatestModelApplied = in.LatestModelApplied
 	out.Ready = in.Ready
 	return nil
@@ -654,7 +684,17 @@ func autoConvert_v1beta1_AzureMachinePoolMachineStatus_To_v1alpha4_AzureMachineP
 	out.FailureReason = (*errors.MachineStatusError)(unsafe.Pointer(in.FailureReason))
 	out.FailureMessage = (*string)(unsafe.Pointer(in.FailureMessage))
 	out.Conditions = *(*apiv1alpha4.Conditions)(unsafe.Pointer(&in.Conditions))
-	out.LongRunningOperationStates = *(*clusterapiproviderazureapiv1alpha4.Futures)(unsafe.Pointer(&in.LongRunningOperationStates))
+	if in.LongRunningOperationStates != nil {
+		in, out := &in.LongRunningOperationStates, &out.LongRunningOperationStates
+		*out = make(clusterapiproviderazureapiv1alpha4.Futures, len(*in))
+		for i := range *in {
+			if err := clusterapiproviderazureapiv1alpha4.Convert_v1beta1_Future_To_v1alpha4_Future(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.LongRunningOperationStates = nil
+	}
 	out.LatestModelApplied = in.LatestModelApplied
 	out.Ready = in.Ready
 	return nil
@@ -787,7 +827,17 @@ func autoConvert_v1alpha4_AzureMachinePoolStatus_To_v1beta1_AzureMachinePoolStat
 	out.FailureReason = (*errors.MachineStatusError)(unsafe.Pointer(in.FailureReason))
 	out.FailureMessage = (*string)(unsafe.Pointer(in.FailureMessage))
 	out.Conditions = *(*apiv1beta1.Conditions)(unsafe.Pointer(&in.Conditions))
-	out.LongRunningOperationStates = *(*clusterapiproviderazureapiv1beta1.Futures)(unsafe.Pointer(&in.LongRunningOperationStates))
+	if in.LongRunningOperationStates != nil {
+		in, out := &in.LongRunningOperationStates, &out.LongRunningOperationStates
+		*out = make(clusterapiproviderazureapiv1beta1.Futures, len(*in))
+		for i := range *in {
+			if err := clusterapiproviderazureapiv1alpha4.Convert_v1alpha4_Future_To_v1beta1_Future(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.LongRunningOperationStates = nil
+	}
 	return nil
 }
 
@@ -814,7 +864,17 @@ func autoConvert_v1beta1_AzureMachinePoolStatus_To_v1alpha4_AzureMachinePoolStat
 	out.FailureReason = (*errors.MachineStatusError)(unsafe.Pointer(in.FailureReason))
 	out.FailureMessage = (*string)(unsafe.Pointer(in.FailureMessage))
 	out.Conditions = *(*apiv1alpha4.Conditions)(unsafe.Pointer(&in.Conditions))
-	out.LongRunningOperationStates = *(*clusterapiproviderazureapiv1alpha4.Futures)(unsafe.Pointer(&in.LongRunningOperationStates))
+	if in.LongRunningOperationStates != nil {
+		in, out := &in.LongRunningOperationStates, &out.LongRunningOperationStates
+		*out = make(clusterapiproviderazureapiv1alpha4.Futures, len(*in))
+		for i := range *in {
+			if err := clusterapiproviderazureapiv1alpha4.Convert_v1beta1_Future_To_v1alpha4_Future(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.LongRunningOperationStates = nil
+	}
 	return nil
 }
 
@@ -1080,7 +1140,17 @@ func Convert_v1beta1_AzureManagedControlPlaneSpec_To_v1alpha4_AzureManagedContro
 func autoConvert_v1alpha4_AzureManagedControlPlaneStatus_To_v1beta1_AzureManagedControlPlaneStatus(in *AzureManagedControlPlaneStatus, out *v1beta1.AzureManagedControlPlaneStatus, s conversion.Scope) error {
 	out.Ready = in.Ready
 	out.Initialized = in.Initialized
-	out.LongRunningOperationStates = *(*clusterapiproviderazureapiv1beta1.Futures)(unsafe.Pointer(&in.LongRunningOperationStates))
+	if in.LongRunningOperationStates != nil {
+		in, out := &in.LongRunningOperationStates, &out.LongRunningOperationStates
+		*out = make(clusterapiproviderazureapiv1beta1.Futures, len(*in))
+		for i := range *in {
+			if err := clusterapiproviderazureapiv1alpha4.Convert_v1alpha4_Future_To_v1beta1_Future(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.LongRunningOperationStates = nil
+	}
 	return nil
 }
 
@@ -1093,7 +1163,17 @@ func autoConvert_v1beta1_AzureManagedControlPlaneStatus_To_v1alpha4_AzureManaged
 	out.Ready = in.Ready
 	out.Initialized = in.Initialized
 	// WARNING: in.Conditions requires manual conversion: does not exist in peer-type
-	out.LongRunningOperationStates = *(*clusterapiproviderazureapiv1alpha4.Futures)(unsafe.Pointer(&in.LongRunningOperationStates))
+	if in.LongRunningOperationStates != nil {
+		in, out := &in.LongRunningOperationStates, &out.LongRunningOperationStates
+		*out = make(clusterapiproviderazureapiv1alpha4.Futures, len(*in))
+		for i := range *in {
+			if err := clusterapiproviderazureapiv1alpha4.Convert_v1beta1_Future_To_v1alpha4_Future(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.LongRunningOperationStates = nil
+	}
 	return nil
 }
 
@@ -20,13 +20,16 @@ import (
 	"context"
 
 	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/cluster-api-provider-azure/azure"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/scope"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/async"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/groups"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/managedclusters"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/subnets"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/tags"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/virtualnetworks"
+	infracontroller "sigs.k8s.io/cluster-api-provider-azure/controllers"
 	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
 	"sigs.k8s.io/cluster-api/util/secret"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -45,14 +48,15 @@ type azureManagedControlPlaneService struct {
 }
 
 // newAzureManagedControlPlaneReconciler populates all the services based on input scope.
-func newAzureManagedControlPlaneReconciler(scope *scope.ManagedControlPlaneScope) *azureManagedControlPlaneService {
+func newAzureManagedControlPlaneReconciler(scope *scope.ManagedControlPlaneScope, asyncOptions infracontroller.AsyncOptions) *azureManagedControlPlaneService {
+	opts := asyncOptions.ServiceOptions(types.NamespacedName{Namespace: scope.Cluster.Namespace, Name: scope.ClusterName()}, scope, scope.ControlPlane)
 	return &azureManagedControlPlaneService{
 		kubeclient:         scope.Client,
 		scope:              scope,
 		managedClustersSvc: managedclusters.New(scope),
-		groupsSvc:          groups.New(scope),
-		vnetSvc:            virtualnetworks.New(scope),
-		subnetsSvc:         subnets.New(scope),
+		groupsSvc:          groups.New(scope, opts...),
+		vnetSvc:            virtualnetworks.New(scope, opts...),
+		subnetsSvc:         subnets.New(scope, opts...),
 		tagsSvc:            tags.New(scope),
 	}
 }
@@ -62,6 +66,9 @@ func (r *azureManagedControlPlaneService) Reconcile(ctx context.Context) error {
 	ctx, _, done := tele.StartSpanWithLogger(ctx, "controllers.azureManagedControlPlaneService.Reconcile")
 	defer done()
 
+	// Resources read several times by a service are only read once per reconcile.
+	ctx = async.WithGetCache(ctx)
+
 	if err := r.groupsSvc.Reconcile(ctx); err != nil {
 		return errors.Wrap(err, "failed to reconcile managed cluster resource group")
 	}
@@ -95,6 +102,9 @@ func (r *azureManagedControlPlaneService) Delete(ctx context.Context) error {
 	ctx, _, done := tele.StartSpanWithLogger(ctx, "controllers.azureManagedControlPlaneService.Delete")
 	defer done()
 
+	// Resources read several times by a service are only read once per reconcile.
+	ctx = async.WithGetCache(ctx)
+
 	if err := r.managedClustersSvc.Delete(ctx); err != nil {
 		return errors.Wrapf(err, "failed to delete managed cluster")
 	}
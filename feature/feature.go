@@ -34,6 +34,18 @@ const (
 	// owner: @alexeldeib
 	// alpha: v0.4
 	AKS featuregate.Feature = "AKS"
+
+	// CustomVNetNSGValidation is the feature gate for validating the security groups of custom VNets, which are
+	// otherwise not reconciled at all.
+	// owner: @sayantani11
+	// alpha: v1.2
+	CustomVNetNSGValidation featuregate.Feature = "CustomVNetNSGValidation"
+
+	// PreserveUnownedSecurityGroups is the feature gate for keeping the security groups that are not tagged as owned by
+	// the cluster on delete, only removing the rules owned by CAPZ from them.
+	// owner: @sayantani11
+	// alpha: v1.2
+	PreserveUnownedSecurityGroups featuregate.Feature = "PreserveUnownedSecurityGroups"
 )
 
 func init() {
@@ -44,5 +56,7 @@ func init() {
 // To add a new feature, define a key for it above and add it here.
 var defaultCAPZFeatureGates = map[featuregate.Feature]featuregate.FeatureSpec{
 	// Every feature should be initiated here:
-	AKS: {Default: false, PreRelease: featuregate.Alpha},
+	AKS:                           {Default: false, PreRelease: featuregate.Alpha},
+	CustomVNetNSGValidation:       {Default: false, PreRelease: featuregate.Alpha},
+	PreserveUnownedSecurityGroups: {Default: false, PreRelease: featuregate.Alpha},
 }
@@ -0,0 +1,288 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package replay provides a client of the async service that replays recorded Azure responses, so that creates and
+// deletes of resources can be tested without Azure.
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Azure/go-autorest/autorest"
+	azureautorest "github.com/Azure/go-autorest/autorest/azure"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+)
+
+// pollingURLPrefix is the prefix of the polling URLs of the futures returned by a Client, followed by the index of the
+// replayed operation.
+const pollingURLPrefix = "https://replay.invalid/operations/"
+
+// Poll is the recorded response of a poll of a long-running operation.
+type Poll struct {
+	// Done is true if the operation was complete.
+	Done bool `json:"done"`
+
+	// Error, if set, is the error the poll failed with.
+	// +optional
+	Error string `json:"error,omitempty"`
+}
+
+// Operation is a recorded long-running operation on a resource.
+type Operation struct {
+	// ResourceGroup is the resource group of the resource.
+	ResourceGroup string `json:"resourceGroup"`
+
+	// ResourceName is the name of the resource.
+	ResourceName string `json:"resourceName"`
+
+	// Method is the HTTP method of the request that started the operation, i.e. PUT, PATCH or DELETE.
+	Method string `json:"method"`
+
+	// Polls are the responses of the successive polls of the operation. The last response is replayed again once all
+	// the responses were replayed. An operation without polls completed right away.
+	// +optional
+	Polls []Poll `json:"polls,omitempty"`
+
+	// Result is the body of the resource returned once a create or update operation is complete.
+	// +optional
+	Result json.RawMessage `json:"result,omitempty"`
+}
+
+// Fixtures are the recorded responses replayed by a Client.
+type Fixtures struct {
+	// Resources are the bodies of the resources that exist before the first operation, keyed by their resource group
+	// and name, e.g. "my-rg/my-nsg".
+	// +optional
+	Resources map[string]json.RawMessage `json:"resources,omitempty"`
+
+	// Operations are the operations replayed, in order, when a resource is created, updated or deleted.
+	// +optional
+	Operations []Operation `json:"operations,omitempty"`
+}
+
+// LoadFixtures reads fixtures from a JSON file.
+func LoadFixtures(path string) (Fixtures, error) {
+	var fixtures Fixtures
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fixtures, errors.Wrapf(err, "failed to read fixtures %s", path)
+	}
+	if err := json.Unmarshal(data, &fixtures); err != nil {
+		return fixtures, errors.Wrapf(err, "failed to unmarshal fixtures %s", path)
+	}
+	return fixtures, nil
+}
+
+// Client is a Creator and Deleter of the async service that replays recorded responses instead of sending requests to
+// Azure. Starting an operation on a resource replays the next recorded operation on the resource with the same HTTP
+// method, and the resources are updated as the operations complete, so that a Client can be passed to async.New in
+// place of the client of a service. A Client is safe for concurrent use.
+type Client struct {
+	// newResource returns a pointer to a new value of the SDK type of the resources, e.g. &network.SecurityGroup{}.
+	newResource func() interface{}
+
+	lock      sync.Mutex
+	resources map[string]json.RawMessage
+	// operations are the recorded operations, started is true for the operations that were started and polls is the
+	// number of polls replayed for each operation.
+	operations []Operation
+	started    []bool
+	polls      []int
+}
+
+// New creates a Client replaying the fixtures. newResource returns a pointer to a new value of the SDK type of the
+// resources, which are returned by value.
+func New(fixtures Fixtures, newResource func() interface{}) *Client {
+	resources := make(map[string]json.RawMessage, len(fixtures.Resources))
+	for key, body := range fixtures.Resources {
+		resources[strings.ToLower(key)] = body
+	}
+	return &Client{
+		newResource: newResource,
+		resources:   resources,
+		operations:  fixtures.Operations,
+		started:     make([]bool, len(fixtures.Operations)),
+		polls:       make([]int, len(fixtures.Operations)),
+	}
+}
+
+// Get returns the resource of the spec, or a 404 error if it does not exist.
+func (c *Client) Get(ctx context.Context, spec azure.ResourceSpecGetter) (result interface{}, err error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	body, ok := c.resources[resourceKey(spec.ResourceGroupName(), spec.ResourceName())]
+	if !ok {
+		return nil, autorest.DetailedError{
+			StatusCode: http.StatusNotFound,
+			Message:    fmt.Sprintf("resource %s/%s not found", spec.ResourceGroupName(), spec.ResourceName()),
+		}
+	}
+	return c.decode(body)
+}
+
+// CreateOrUpdateAsync replays the next recorded PUT operation on the resource of the spec.
+func (c *Client) CreateOrUpdateAsync(ctx context.Context, spec azure.ResourceSpecGetter, parameters interface{}) (result interface{}, future azureautorest.FutureAPI, err error) {
+	return c.start(spec, http.MethodPut)
+}
+
+// UpdateAsync replays the next recorded PATCH operation on the resource of the spec.
+func (c *Client) UpdateAsync(ctx context.Context, spec azure.ResourceSpecGetter, parameters interface{}) (result interface{}, future azureautorest.FutureAPI, err error) {
+	return c.start(spec, http.MethodPatch)
+}
+
+// DeleteAsync replays the next recorded DELETE operation on the resource of the spec.
+func (c *Client) DeleteAsync(ctx context.Context, spec azure.ResourceSpecGetter) (future azureautorest.FutureAPI, err error) {
+	_, future, err = c.start(spec, http.MethodDelete)
+	return future, err
+}
+
+// IsDone replays the next recorded poll of the operation of the future.
+func (c *Client) IsDone(ctx context.Context, future azureautorest.FutureAPI) (isDone bool, err error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	i, err := c.operationIndex(future)
+	if err != nil {
+		return false, err
+	}
+	operation := c.operations[i]
+	if len(operation.Polls) == 0 {
+		return true, nil
+	}
+	poll := operation.Polls[len(operation.Polls)-1]
+	if c.polls[i] < len(operation.Polls) {
+		poll = operation.Polls[c.polls[i]]
+		c.polls[i]++
+	}
+	if poll.Error != "" {
+		return false, errors.Wrap(errors.New(poll.Error), "failed checking if the operation was complete")
+	}
+	if poll.Done {
+		c.complete(operation)
+	}
+	return poll.Done, nil
+}
+
+// Result returns the recorded result of the operation of the future. Delete operations have no result.
+func (c *Client) Result(ctx context.Context, future azureautorest.FutureAPI, futureType string) (result interface{}, err error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if future == nil {
+		return nil, errors.Errorf("cannot get result from nil future")
+	}
+	i, err := c.operationIndex(future)
+	if err != nil {
+		return nil, err
+	}
+	return c.result(c.operations[i])
+}
+
+// start starts the next recorded operation with the given method on the resource of the spec. It returns the result
+// of the operation if it completed right away, or a future to poll it otherwise.
+func (c *Client) start(spec azure.ResourceSpecGetter, method string) (result interface{}, future azureautorest.FutureAPI, err error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	key := resourceKey(spec.ResourceGroupName(), spec.ResourceName())
+	for i, operation := range c.operations {
+		if c.started[i] || !strings.EqualFold(operation.Method, method) || resourceKey(operation.ResourceGroup, operation.ResourceName) != key {
+			continue
+		}
+		c.started[i] = true
+		if len(operation.Polls) == 0 {
+			c.complete(operation)
+			result, err := c.result(operation)
+			return result, nil, err
+		}
+		future, err := newFuture(method, i)
+		return nil, future, err
+	}
+	return nil, nil, errors.Errorf("no recorded %s operation on resource %s/%s", method, spec.ResourceGroupName(), spec.ResourceName())
+}
+
+// complete updates the resources with the outcome of a completed operation.
+func (c *Client) complete(operation Operation) {
+	key := resourceKey(operation.ResourceGroup, operation.ResourceName)
+	if strings.EqualFold(operation.Method, http.MethodDelete) {
+		delete(c.resources, key)
+		return
+	}
+	if len(operation.Result) > 0 {
+		c.resources[key] = operation.Result
+	}
+}
+
+// result returns the result of an operation.
+func (c *Client) result(operation Operation) (interface{}, error) {
+	if strings.EqualFold(operation.Method, http.MethodDelete) || len(operation.Result) == 0 {
+		return nil, nil
+	}
+	return c.decode(operation.Result)
+}
+
+// decode returns the resource with the given body.
+func (c *Client) decode(body json.RawMessage) (interface{}, error) {
+	resource := c.newResource()
+	if err := json.Unmarshal(body, resource); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal recorded resource")
+	}
+	return reflect.ValueOf(resource).Elem().Interface(), nil
+}
+
+// operationIndex returns the index of the operation polled by a future returned by the Client.
+func (c *Client) operationIndex(future azureautorest.FutureAPI) (int, error) {
+	url := future.PollingURL()
+	i, err := strconv.Atoi(strings.TrimPrefix(url, pollingURLPrefix))
+	if !strings.HasPrefix(url, pollingURLPrefix) || err != nil || i < 0 || i >= len(c.operations) {
+		return 0, errors.Errorf("future polling %s was not returned by the replay client", url)
+	}
+	return i, nil
+}
+
+// newFuture returns an in-progress future of the operation at the given index, which can be stored and restored like
+// the futures of Azure.
+func newFuture(method string, i int) (azureautorest.FutureAPI, error) {
+	data, err := json.Marshal(map[string]string{
+		"method":        method,
+		"pollingMethod": string(azureautorest.PollingAsyncOperation),
+		"pollingURI":    fmt.Sprintf("%s%d", pollingURLPrefix, i),
+		"lroState":      "InProgress",
+	})
+	if err != nil {
+		return nil, err
+	}
+	var future azureautorest.Future
+	if err := future.UnmarshalJSON(data); err != nil {
+		return nil, errors.Wrap(err, "failed to create future")
+	}
+	return &future, nil
+}
+
+// resourceKey returns the key of a resource in the resources of a Client.
+func resourceKey(resourceGroup, name string) string {
+	return strings.ToLower(resourceGroup + "/" + name)
+}
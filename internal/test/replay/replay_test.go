@@ -0,0 +1,160 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-02-01/network"
+	"github.com/Azure/go-autorest/autorest/to"
+	. "github.com/onsi/gomega"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/async"
+	"sigs.k8s.io/cluster-api-provider-azure/util/futures"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// futureScope is a FutureScope storing the futures in the status of an AzureCluster.
+type futureScope struct {
+	cluster *infrav1.AzureCluster
+}
+
+func (s *futureScope) SetLongRunningOperationState(future *infrav1.Future) {
+	futures.Set(s.cluster, future)
+}
+
+func (s *futureScope) GetLongRunningOperationState(name, service string) *infrav1.Future {
+	return futures.Get(s.cluster, name, service)
+}
+
+func (s *futureScope) GetLongRunningOperationStates() []*infrav1.Future {
+	states := make([]*infrav1.Future, len(s.cluster.Status.LongRunningOperationStates))
+	for i := range s.cluster.Status.LongRunningOperationStates {
+		states[i] = &s.cluster.Status.LongRunningOperationStates[i]
+	}
+	return states
+}
+
+func (s *futureScope) DeleteLongRunningOperationState(name, service string) {
+	futures.Delete(s.cluster, name, service)
+}
+
+func (s *futureScope) UpdatePutStatus(clusterv1.ConditionType, string, error)    {}
+func (s *futureScope) UpdateDeleteStatus(clusterv1.ConditionType, string, error) {}
+func (s *futureScope) UpdatePatchStatus(clusterv1.ConditionType, string, error)  {}
+func (s *futureScope) IsServicePaused(string) bool                               { return false }
+
+// nsgSpec is the spec of the security group of the fixtures.
+type nsgSpec struct{}
+
+func (s *nsgSpec) ResourceName() string      { return "test-nsg" }
+func (s *nsgSpec) ResourceGroupName() string { return "test-group" }
+func (s *nsgSpec) OwnerResourceName() string { return "" }
+func (s *nsgSpec) Parameters(existing interface{}) (interface{}, error) {
+	if existing != nil {
+		return nil, nil
+	}
+	return network.SecurityGroup{Location: to.StringPtr("eastus")}, nil
+}
+
+func newSecurityGroup() interface{} {
+	return &network.SecurityGroup{}
+}
+
+func TestCreateAndDeleteResource(t *testing.T) {
+	g := NewWithT(t)
+	fixtures, err := LoadFixtures("testdata/securitygroup.json")
+	g.Expect(err).NotTo(HaveOccurred())
+	client := New(fixtures, newSecurityGroup)
+	scope := &futureScope{cluster: &infrav1.AzureCluster{}}
+	s := async.New(scope, client, client)
+	spec := &nsgSpec{}
+
+	// The create is started, then the first poll of the stored future reports it is still in progress.
+	for i := 0; i < 2; i++ {
+		_, _, err = s.CreateResource(context.TODO(), spec, "securitygroups")
+		g.Expect(azure.IsOperationNotDoneError(err)).To(BeTrue())
+		g.Expect(scope.GetLongRunningOperationState("test-nsg", "securitygroups")).NotTo(BeNil())
+	}
+
+	// The stored future is polled again and the create completes with the recorded security group.
+	result, _, err := s.CreateResource(context.TODO(), spec, "securitygroups")
+	g.Expect(err).NotTo(HaveOccurred())
+	nsg, ok := result.(network.SecurityGroup)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(to.String(nsg.Name)).To(Equal("test-nsg"))
+	g.Expect(*nsg.SecurityRules).To(HaveLen(1))
+	g.Expect(scope.GetLongRunningOperationState("test-nsg", "securitygroups")).To(BeNil())
+
+	// The security group now exists, so it is up to date.
+	result, changed, err := s.CreateResource(context.TODO(), spec, "securitygroups")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(changed).To(BeFalse())
+	g.Expect(result).To(Equal(nsg))
+
+	// The delete is started, then the stored future is polled and the delete completes.
+	_, err = s.DeleteResource(context.TODO(), spec, "securitygroups")
+	g.Expect(azure.IsOperationNotDoneError(err)).To(BeTrue())
+	notFound, err := s.DeleteResource(context.TODO(), spec, "securitygroups")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(notFound).To(BeFalse())
+	_, err = client.Get(context.TODO(), spec)
+	g.Expect(azure.ResourceNotFound(err)).To(BeTrue())
+}
+
+func TestReplayPolls(t *testing.T) {
+	g := NewWithT(t)
+	client := New(Fixtures{
+		Resources: map[string]json.RawMessage{"Test-Group/test-nsg": json.RawMessage(`{"name": "test-nsg"}`)},
+		Operations: []Operation{
+			{ResourceGroup: "test-group", ResourceName: "test-nsg", Method: "PUT", Polls: []Poll{{Error: "connection reset"}, {Done: true}}},
+			{ResourceGroup: "test-group", ResourceName: "test-nsg", Method: "PUT", Result: json.RawMessage(`{"name": "updated-nsg"}`)},
+		},
+	}, newSecurityGroup)
+	spec := &nsgSpec{}
+
+	existing, err := client.Get(context.TODO(), spec)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(existing).To(Equal(network.SecurityGroup{Name: to.StringPtr("test-nsg")}))
+
+	_, future, err := client.CreateOrUpdateAsync(context.TODO(), spec, nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(future).NotTo(BeNil())
+	_, err = client.IsDone(context.TODO(), future)
+	g.Expect(err).To(MatchError("failed checking if the operation was complete: connection reset"))
+	done, err := client.IsDone(context.TODO(), future)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(done).To(BeTrue())
+	// The last poll is replayed again.
+	done, err = client.IsDone(context.TODO(), future)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(done).To(BeTrue())
+
+	// The second operation completes right away.
+	result, future, err := client.CreateOrUpdateAsync(context.TODO(), spec, nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(future).To(BeNil())
+	g.Expect(result).To(Equal(network.SecurityGroup{Name: to.StringPtr("updated-nsg")}))
+
+	_, _, err = client.CreateOrUpdateAsync(context.TODO(), spec, nil)
+	g.Expect(err).To(MatchError("no recorded PUT operation on resource test-group/test-nsg"))
+	_, err = client.DeleteAsync(context.TODO(), spec)
+	g.Expect(err).To(MatchError("no recorded DELETE operation on resource test-group/test-nsg"))
+}
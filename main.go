@@ -39,6 +39,8 @@ import (
 	infrav1alpha3 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha3"
 	infrav1alpha4 "sigs.k8s.io/cluster-api-provider-azure/api/v1alpha4"
 	infrav1beta1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/scope"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/async"
 	"sigs.k8s.io/cluster-api-provider-azure/controllers"
 	infrav1alpha3exp "sigs.k8s.io/cluster-api-provider-azure/exp/api/v1alpha3"
 	infrav1alpha4exp "sigs.k8s.io/cluster-api-provider-azure/exp/api/v1alpha4"
@@ -114,6 +116,23 @@ var (
 	healthAddr                         string
 	webhookPort                        int
 	reconcileTimeout                   time.Duration
+	conditionSeverities                map[string]string
+	azureRequestsPerSecond             float64
+	azureRequestsBurst                 int
+	azureThrottlingMaxBackoff          time.Duration
+	azureMaxRequestsInFlight           int64
+	azureMaxOperationsStarting         int
+	nsgReconcileConcurrency            int
+	resolveOperationFailures           bool
+	migrateOperationStates             bool
+	azureOperationMaxRequeueAfter      time.Duration
+	azureOperationRequeueJitter        float64
+	azureOperationMaxAttempts          int
+	recordOperationEvents              bool
+	auditAzureOperations               bool
+	stableCorrelationID                bool
+	azureOperationRequeueAfter         time.Duration
+	azureServiceReconcileTimeout       time.Duration
 	enableTracing                      bool
 )
 
@@ -234,6 +253,108 @@ func InitFlags(fs *pflag.FlagSet) {
 		"The maximum duration a reconcile loop can run (e.g. 90m)",
 	)
 
+	fs.StringToStringVar(&conditionSeverities,
+		"condition-severities",
+		nil,
+		"Severities of the AzureCluster conditions reporting the outcomes of the reconciles of its services, e.g. Throttled=Info,Failed=Warning. The outcomes are InProgress, Failed, Deleted and Throttled, and the severities Info, Warning and Error.",
+	)
+
+	fs.Float64Var(&azureRequestsPerSecond,
+		"azure-requests-per-second",
+		0,
+		"The average rate of the create and delete requests made to Azure by all the clusters. The rate is not limited when 0, but the requests of all the clusters are still backed off while Azure throttles them.",
+	)
+
+	fs.IntVar(&azureRequestsBurst,
+		"azure-requests-burst",
+		20,
+		"The number of create and delete requests that can be made to Azure at once above the average rate set by --azure-requests-per-second.",
+	)
+
+	fs.DurationVar(&azureThrottlingMaxBackoff,
+		"azure-throttling-max-backoff",
+		5*time.Minute,
+		"The maximum time the requests of all the clusters are backed off for while Azure throttles them (e.g. 5m)",
+	)
+
+	fs.Int64Var(&azureMaxRequestsInFlight,
+		"azure-max-requests-in-flight",
+		0,
+		"The maximum number of create and delete requests made to Azure by all the clusters at the same time. The number is not limited when 0.",
+	)
+
+	fs.IntVar(&azureMaxOperationsStarting,
+		"azure-max-operations-starting",
+		0,
+		"The maximum number of create and delete operations being started on Azure at the same time, shared fairly between the clusters so that a cluster with many resources cannot starve the others. The number is not limited when 0.",
+	)
+
+	fs.IntVar(&nsgReconcileConcurrency,
+		"nsg-reconcile-concurrency",
+		1,
+		"Number of network security groups of a cluster to reconcile in parallel. They are reconciled sequentially when lower than 2.",
+	)
+
+	fs.BoolVar(&resolveOperationFailures,
+		"resolve-operation-failures",
+		false,
+		"Look up in the Azure Activity Log why the operations on Azure resources failed, e.g. denied by Azure Policy, and report the reason in the conditions. Each lookup makes additional requests to Azure.",
+	)
+
+	fs.BoolVar(&migrateOperationStates,
+		"migrate-operation-states",
+		true,
+		"Convert the long-running operation states that cannot be decoded but are in a known format, e.g. edited by hand, into the current format rather than resetting them and losing track of their operations.",
+	)
+
+	fs.DurationVar(&azureOperationMaxRequeueAfter,
+		"azure-operation-max-requeue-after",
+		0,
+		"The maximum interval the resources with an ongoing operation on Azure are requeued after. The interval is doubled each time an operation is found not done, up to this maximum, so that long operations are polled less often (e.g. 2m). The interval is not increased when 0.",
+	)
+
+	fs.Float64Var(&azureOperationRequeueJitter,
+		"azure-operation-requeue-jitter",
+		0,
+		"The largest fraction of their value the requeue intervals of the resources with an ongoing operation on Azure are randomly moved by, e.g. 0.1 for ±10%, so that the operations of clusters created at the same time are not all polled at the same time. The intervals are not moved when 0.",
+	)
+
+	fs.IntVar(&azureOperationMaxAttempts,
+		"azure-operation-max-attempts",
+		0,
+		"The number of times a long-running operation on Azure is checked on before giving up on it, e.g. because of a quota error that never clears. Its state is then reset and its last error is reported as terminal in the conditions. There is no limit when 0.",
+	)
+
+	fs.BoolVar(&recordOperationEvents,
+		"record-operation-events",
+		false,
+		"Record events on the AzureClusters, AzureMachines and AzureManagedControlPlanes when the long-running operations on their Azure resources complete, and when their operations fail with a terminal error.",
+	)
+
+	fs.BoolVar(&auditAzureOperations,
+		"audit-azure-operations",
+		false,
+		"Log each create, update or delete request made to Azure and the outcome of each operation, to keep an audit trail of the changes made to Azure resources.",
+	)
+
+	fs.BoolVar(&stableCorrelationID,
+		"stable-correlation-id",
+		false,
+		"Send a correlation ID derived from the UID and generation of the object owning the Azure resources with the requests made to Azure, rather than a correlation ID per reconcile, so that all the requests made for a generation of the object can be looked up by the same x-ms-correlation-request-id.",
+	)
+
+	fs.DurationVar(&azureOperationRequeueAfter,
+		"azure-operation-requeue-after",
+		reconciler.DefaultReconcilerRequeue,
+		"The interval after which the resources with an ongoing operation on Azure are reconciled again (e.g. 30s).",
+	)
+
+	fs.DurationVar(&azureServiceReconcileTimeout,
+		"azure-service-reconcile-timeout",
+		reconciler.DefaultAzureServiceReconcileTimeout,
+		"The timeout of the reconcile and delete of each Azure service, e.g. virtual networks or security groups, of a cluster or machine (e.g. 30s).",
+	)
+
 	fs.BoolVar(
 		&enableTracing,
 		"enable-tracing",
@@ -322,6 +443,31 @@ func main() {
 }
 
 func registerControllers(ctx context.Context, mgr manager.Manager) {
+	// The async services of all the reconcilers share the same limiters, so that they bound the requests made to Azure
+	// by all the clusters.
+	asyncOptions := controllers.AsyncOptions{
+		RateLimiter:               async.NewRateLimiter(azureRequestsPerSecond, azureRequestsBurst, azureThrottlingMaxBackoff),
+		SecurityGroupsConcurrency: nsgReconcileConcurrency,
+		ResolveFailures:           resolveOperationFailures,
+		MigrateFutures:            migrateOperationStates,
+		MaxRequeueAfter:           azureOperationMaxRequeueAfter,
+		RequeueJitter:             azureOperationRequeueJitter,
+		MaxAttempts:               azureOperationMaxAttempts,
+		AuditOperations:           auditAzureOperations,
+		StableCorrelationID:       stableCorrelationID,
+		RequeueAfter:              azureOperationRequeueAfter,
+		ServiceReconcileTimeout:   azureServiceReconcileTimeout,
+	}
+	if azureMaxRequestsInFlight > 0 {
+		asyncOptions.ConcurrencyLimiter = async.NewConcurrencyLimiter(azureMaxRequestsInFlight)
+	}
+	if azureMaxOperationsStarting > 0 {
+		asyncOptions.FairScheduler = async.NewFairScheduler(azureMaxOperationsStarting)
+	}
+	if recordOperationEvents {
+		asyncOptions.EventRecorder = mgr.GetEventRecorderFor("azure-operations")
+	}
+
 	machineCache, err := coalescing.NewRequestCache(debouncingTimer)
 	if err != nil {
 		setupLog.Error(err, "failed to build machineCache ReconcileCache")
@@ -330,6 +476,7 @@ func registerControllers(ctx context.Context, mgr manager.Manager) {
 		mgr.GetEventRecorderFor("azuremachine-reconciler"),
 		reconcileTimeout,
 		watchFilterValue,
+		asyncOptions,
 	).SetupWithManager(ctx, mgr, controllers.Options{Options: controller.Options{MaxConcurrentReconciles: azureMachineConcurrency}, Cache: machineCache}); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "AzureMachine")
 		os.Exit(1)
@@ -339,11 +486,18 @@ func registerControllers(ctx context.Context, mgr manager.Manager) {
 	if err != nil {
 		setupLog.Error(err, "failed to build clusterCache ReconcileCache")
 	}
+	clusterConditionSeverities, err := scope.ParseConditionSeverities(conditionSeverities)
+	if err != nil {
+		setupLog.Error(err, "invalid condition severities")
+		os.Exit(1)
+	}
 	if err := controllers.NewAzureClusterReconciler(
 		mgr.GetClient(),
 		mgr.GetEventRecorderFor("azurecluster-reconciler"),
 		reconcileTimeout,
 		watchFilterValue,
+		clusterConditionSeverities,
+		asyncOptions,
 	).SetupWithManager(ctx, mgr, controllers.Options{Options: controller.Options{MaxConcurrentReconciles: azureClusterConcurrency}, Cache: clusterCache}); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "AzureCluster")
 		os.Exit(1)
@@ -463,6 +617,7 @@ func registerControllers(ctx context.Context, mgr manager.Manager) {
 				Recorder:         mgr.GetEventRecorderFor("azuremanagedcontrolplane-reconciler"),
 				ReconcileTimeout: reconcileTimeout,
 				WatchFilterValue: watchFilterValue,
+				AsyncOptions:     asyncOptions,
 			}).SetupWithManager(ctx, mgr, controllers.Options{Options: controller.Options{MaxConcurrentReconciles: azureClusterConcurrency}, Cache: mcpCache}); err != nil {
 				setupLog.Error(err, "unable to create controller", "controller", "AzureManagedControlPlane")
 				os.Exit(1)
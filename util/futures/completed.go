@@ -0,0 +1,65 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package futures
+
+import (
+	"strings"
+	"time"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+)
+
+const (
+	// CompletedOperationTTL is how long a completed operation is kept after it completed.
+	CompletedOperationTTL = time.Hour
+	// MaxCompletedOperations is the number of most recent completed operations kept.
+	MaxCompletedOperations = 10
+)
+
+// CompletedSetter interface defines methods that an object should implement in order to keep a record of its recently
+// completed long-running operations.
+type CompletedSetter interface {
+	GetCompletedOperations() []infrav1.CompletedOperation
+	SetCompletedOperations([]infrav1.CompletedOperation)
+}
+
+// SetCompleted records a completed operation, replacing the previous record of the same resource. Records that
+// completed more than CompletedOperationTTL before now are dropped, as well as the oldest records beyond
+// MaxCompletedOperations, so that only recent operations are kept.
+func SetCompleted(to CompletedSetter, operation infrav1.CompletedOperation, now time.Time) {
+	if to == nil {
+		return
+	}
+
+	var operations []infrav1.CompletedOperation
+	for _, op := range to.GetCompletedOperations() {
+		// A record stored before the resource group was recorded is replaced as well, as it expires soon anyway.
+		if op.Name == operation.Name && op.ServiceName == operation.ServiceName && (op.ResourceGroup == "" || strings.EqualFold(op.ResourceGroup, operation.ResourceGroup)) {
+			continue
+		}
+		if now.Sub(op.CompletionTime.Time) > CompletedOperationTTL {
+			continue
+		}
+		operations = append(operations, op)
+	}
+	operations = append(operations, operation)
+	if len(operations) > MaxCompletedOperations {
+		operations = operations[len(operations)-MaxCompletedOperations:]
+	}
+
+	to.SetCompletedOperations(operations)
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package futures
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+)
+
+func TestSetCompleted(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	testService := "test-service"
+	a := fakeCompletedOperation("a", testService, now.Add(-time.Minute))
+	b := fakeCompletedOperation("b", testService, now.Add(-time.Minute))
+	newA := fakeCompletedOperation("a", testService, now)
+	expired := fakeCompletedOperation("expired", testService, now.Add(-2*CompletedOperationTTL))
+	var many []infrav1.CompletedOperation
+	for i := 0; i < MaxCompletedOperations; i++ {
+		many = append(many, fakeCompletedOperation(fmt.Sprintf("op-%d", i), testService, now.Add(-time.Minute)))
+	}
+
+	tests := []struct {
+		name      string
+		existing  []infrav1.CompletedOperation
+		operation infrav1.CompletedOperation
+		want      []infrav1.CompletedOperation
+	}{
+		{
+			name:      "SetCompleted adds a completed operation",
+			existing:  nil,
+			operation: a,
+			want:      []infrav1.CompletedOperation{a},
+		},
+		{
+			name:      "SetCompleted appends the most recent completed operation",
+			existing:  []infrav1.CompletedOperation{a},
+			operation: b,
+			want:      []infrav1.CompletedOperation{a, b},
+		},
+		{
+			name:      "SetCompleted replaces the completed operation of the same resource",
+			existing:  []infrav1.CompletedOperation{a, b},
+			operation: newA,
+			want:      []infrav1.CompletedOperation{b, newA},
+		},
+		{
+			name:      "SetCompleted drops the expired completed operations",
+			existing:  []infrav1.CompletedOperation{expired, a},
+			operation: b,
+			want:      []infrav1.CompletedOperation{a, b},
+		},
+		{
+			name:      "SetCompleted drops the oldest completed operations beyond the maximum",
+			existing:  many,
+			operation: a,
+			want:      append(append([]infrav1.CompletedOperation{}, many[1:]...), a),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			obj := &infrav1.AzureCluster{}
+			obj.SetCompletedOperations(tt.existing)
+			SetCompleted(obj, tt.operation, now)
+			g.Expect(obj.GetCompletedOperations()).To(Equal(tt.want))
+		})
+	}
+}
+
+func fakeCompletedOperation(name string, service string, completionTime time.Time) infrav1.CompletedOperation {
+	startTime := metav1.NewTime(completionTime.Add(-time.Minute))
+	return infrav1.CompletedOperation{
+		Type:           "PUT",
+		Name:           name,
+		ResourceGroup:  "test-rg",
+		ServiceName:    service,
+		StartTime:      &startTime,
+		CompletionTime: metav1.NewTime(completionTime),
+		Duration:       metav1.Duration{Duration: time.Minute},
+	}
+}
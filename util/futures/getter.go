@@ -17,6 +17,8 @@ limitations under the License.
 package futures
 
 import (
+	"strings"
+
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -46,7 +48,50 @@ func Get(from Getter, name, service string) *infrav1.Future {
 	return nil
 }
 
+// GetInResourceGroup returns the future with the given name of the resource in the given resource group, so that the
+// futures of resources with the same name in different resource groups are told apart. If the future does not exist,
+// it returns nil.
+func GetInResourceGroup(from Getter, name, service, resourceGroup string) *infrav1.Future {
+	futures := from.GetFutures()
+	if i := indexInResourceGroup(futures, name, service, resourceGroup); i >= 0 {
+		return &futures[i]
+	}
+	return nil
+}
+
+// indexInResourceGroup returns the index of the future with the given name of the resource in the given resource
+// group, or -1 if there is none. A future looked up without a resource group matches any resource group. Futures
+// stored before the resource group was recorded have no resource group: such a legacy future is only returned if there
+// is no future in the given resource group, so that it does not shadow the future of another resource with the same
+// name, and it is migrated to the resource group when it is next set.
+func indexInResourceGroup(futures infrav1.Futures, name, service, resourceGroup string) int {
+	legacy := -1
+	for i, f := range futures {
+		if f.Name != name || f.ServiceName != service {
+			continue
+		}
+		switch {
+		case resourceGroup == "", strings.EqualFold(f.ResourceGroup, resourceGroup):
+			return i
+		case f.ResourceGroup == "" && legacy < 0:
+			legacy = i
+		}
+	}
+	return legacy
+}
+
 // Has returns true if a future with the given name exists.
 func Has(from Getter, name, service string) bool {
 	return Get(from, name, service) != nil
 }
+
+// List returns a copy of every future on the object, in the order they appear in its status.
+func List(from Getter) []*infrav1.Future {
+	futures := from.GetFutures()
+	list := make([]*infrav1.Future, 0, len(futures))
+	for i := range futures {
+		f := futures[i]
+		list = append(list, &f)
+	}
+	return list
+}
@@ -45,6 +45,34 @@ func TestGet(t *testing.T) {
 	g.Expect(Get(azurecluster, vnetName, vnet)).To(Equal(&vnetFuture))
 }
 
+func TestGetInResourceGroup(t *testing.T) {
+	g := NewWithT(t)
+
+	azurecluster := &infrav1.AzureCluster{}
+
+	nsg := "securitygroups"
+	clusterFuture := fakeFuture("my-nsg", nsg)
+	networkFuture := fakeFuture("my-nsg", nsg)
+	networkFuture.ResourceGroup = "network-rg"
+	legacyFuture := fakeFuture("my-legacy-nsg", nsg)
+	legacyFuture.ResourceGroup = ""
+
+	azurecluster.SetFutures(infrav1.Futures{clusterFuture, networkFuture, legacyFuture})
+
+	g.Expect(GetInResourceGroup(azurecluster, "my-nsg", nsg, "test-rg")).To(Equal(&clusterFuture))
+	g.Expect(GetInResourceGroup(azurecluster, "my-nsg", nsg, "NETWORK-RG")).To(Equal(&networkFuture))
+	g.Expect(GetInResourceGroup(azurecluster, "my-nsg", nsg, "other-rg")).To(BeNil())
+	// Futures stored before the resource group was recorded have none and match any resource group, unless there is a
+	// future in the resource group.
+	g.Expect(GetInResourceGroup(azurecluster, "my-legacy-nsg", nsg, "other-rg")).To(Equal(&legacyFuture))
+	legacyClusterFuture := fakeFuture("my-nsg", nsg)
+	legacyClusterFuture.ResourceGroup = ""
+	legacyClusterFuture.Data = "legacy"
+	azurecluster.SetFutures(infrav1.Futures{legacyClusterFuture, networkFuture})
+	g.Expect(GetInResourceGroup(azurecluster, "my-nsg", nsg, "network-rg")).To(Equal(&networkFuture))
+	g.Expect(GetInResourceGroup(azurecluster, "my-nsg", nsg, "test-rg")).To(Equal(&legacyClusterFuture))
+}
+
 func TestHas(t *testing.T) {
 	g := NewWithT(t)
 
@@ -65,6 +93,26 @@ func TestHas(t *testing.T) {
 	g.Expect(Has(azurecluster, vmName, vnet)).To(BeFalse())
 }
 
+func TestList(t *testing.T) {
+	g := NewWithT(t)
+
+	azurecluster := &infrav1.AzureCluster{}
+
+	vmFuture := fakeFuture("my-vm", "virtualmachines")
+	vnetFuture := fakeFuture("my-vnet", "virtualnetworks")
+
+	g.Expect(List(azurecluster)).To(BeEmpty())
+
+	azurecluster.SetFutures(infrav1.Futures{vmFuture, vnetFuture})
+
+	list := List(azurecluster)
+	g.Expect(list).To(Equal([]*infrav1.Future{&vmFuture, &vnetFuture}))
+
+	// Modifying the returned futures doesn't modify the object's status.
+	list[0].Data = "modified"
+	g.Expect(azurecluster.GetFutures()[0].Data).To(BeEmpty())
+}
+
 func fakeFuture(name string, service string) infrav1.Future {
 	return infrav1.Future{
 		Type:          "PUT",
@@ -0,0 +1,82 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package futures
+
+import (
+	"reflect"
+	"time"
+)
+
+// PendingOperation describes a long-running operation in progress on an Azure resource, as recorded by a future in the
+// status of an object.
+type PendingOperation struct {
+	// Kind, Namespace and ObjectName identify the object the future is stored on, e.g. an AzureCluster.
+	Kind       string
+	Namespace  string
+	ObjectName string
+	// ServiceName is the name of the Azure service reconciling the resource.
+	ServiceName string
+	// ResourceGroup and Name identify the Azure resource.
+	ResourceGroup string
+	Name          string
+	// Type is the type of the request the operation was started with, e.g. PUT or DELETE.
+	Type string
+	// Status is the last known state of the operation, if any.
+	Status string
+	// Age is how long ago the operation was started. It is zero if the start time of the future is not known.
+	Age time.Duration
+}
+
+// Pending returns the long-running operations in progress on the given objects, e.g. all the AzureClusters and
+// AzureMachines of a management cluster, flattened in the order of the objects and of their futures. It only reads the
+// futures of the objects.
+func Pending(objects []Getter, now time.Time) []PendingOperation {
+	var pending []PendingOperation
+	for _, object := range objects {
+		kind := kindOf(object)
+		for _, future := range List(object) {
+			operation := PendingOperation{
+				Kind:          kind,
+				Namespace:     object.GetNamespace(),
+				ObjectName:    object.GetName(),
+				ServiceName:   future.ServiceName,
+				ResourceGroup: future.ResourceGroup,
+				Name:          future.Name,
+				Type:          future.Type,
+				Status:        future.Status,
+			}
+			if future.StartTime != nil {
+				operation.Age = now.Sub(future.StartTime.Time)
+			}
+			pending = append(pending, operation)
+		}
+	}
+	return pending
+}
+
+// kindOf returns the kind of the object, falling back to the name of its type since the type meta of typed objects is
+// usually empty.
+func kindOf(object Getter) string {
+	if kind := object.GetObjectKind().GroupVersionKind().Kind; kind != "" {
+		return kind
+	}
+	t := reflect.TypeOf(object)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Name()
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package futures
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+)
+
+func TestPending(t *testing.T) {
+	g := NewWithT(t)
+
+	now := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	started := metav1.NewTime(now.Add(-5 * time.Minute))
+
+	vnetFuture := fakeFuture("my-vnet", "virtualnetworks")
+	vnetFuture.StartTime = &started
+	vnetFuture.Status = "InProgress"
+	azurecluster := &infrav1.AzureCluster{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "my-cluster"}}
+	azurecluster.SetFutures(infrav1.Futures{vnetFuture})
+
+	vmFuture := fakeFuture("my-vm", "virtualmachines")
+	vmFuture.Type = infrav1.DeleteFuture
+	azuremachine := &infrav1.AzureMachine{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "my-machine"}}
+	azuremachine.SetFutures(infrav1.Futures{vmFuture})
+
+	idle := &infrav1.AzureMachine{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "idle-machine"}}
+
+	g.Expect(Pending(nil, now)).To(BeEmpty())
+	g.Expect(Pending([]Getter{azurecluster, idle, azuremachine}, now)).To(Equal([]PendingOperation{
+		{
+			Kind:          "AzureCluster",
+			Namespace:     "default",
+			ObjectName:    "my-cluster",
+			ServiceName:   "virtualnetworks",
+			ResourceGroup: "test-rg",
+			Name:          "my-vnet",
+			Type:          infrav1.PutFuture,
+			Status:        "InProgress",
+			Age:           5 * time.Minute,
+		},
+		{
+			Kind:          "AzureMachine",
+			Namespace:     "default",
+			ObjectName:    "my-machine",
+			ServiceName:   "virtualmachines",
+			ResourceGroup: "test-rg",
+			Name:          "my-vm",
+			Type:          infrav1.DeleteFuture,
+		},
+	}))
+}
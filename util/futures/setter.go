@@ -17,6 +17,7 @@ limitations under the License.
 package futures
 
 import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 )
 
@@ -29,7 +30,9 @@ type Setter interface {
 
 // Set sets the given future.
 //
-// NOTE: If a future already exists, we update it.
+// NOTE: If a future already exists for the resource, i.e. with the same name, service and resource group, we update it.
+// The start time of the future is set to now if it is not set, unless it updates an existing future of the same type,
+// in which case the start time of the existing future is kept.
 func Set(to Setter, future *infrav1.Future) {
 	if to == nil || future == nil {
 		return
@@ -37,23 +40,29 @@ func Set(to Setter, future *infrav1.Future) {
 
 	// Check if the new future already exists, and update it if it does.
 	futures := to.GetFutures()
-	exists := false
-	for i, f := range futures {
-		if f.Name == future.Name && f.ServiceName == future.ServiceName {
-			exists = true
-			futures[i] = *future
-			break
+	if i := indexInResourceGroup(futures, future.Name, future.ServiceName, future.ResourceGroup); i >= 0 {
+		updated := *future
+		if updated.StartTime == nil && futures[i].Type == future.Type {
+			updated.StartTime = futures[i].StartTime
 		}
-	}
-
-	// If the future does not exist, add it.
-	if !exists {
-		futures = append(futures, *future)
+		futures[i] = withStartTime(updated)
+	} else {
+		// If the future does not exist, add it.
+		futures = append(futures, withStartTime(*future))
 	}
 
 	to.SetFutures(futures)
 }
 
+// withStartTime returns the future with its start time set to now if it is not set.
+func withStartTime(future infrav1.Future) infrav1.Future {
+	if future.StartTime == nil {
+		now := metav1.Now()
+		future.StartTime = &now
+	}
+	return future
+}
+
 // Delete deletes the specified future.
 func Delete(to Setter, name, service string) {
 	if to == nil || name == "" || service == "" {
@@ -70,3 +79,18 @@ func Delete(to Setter, name, service string) {
 
 	to.SetFutures(futures)
 }
+
+// DeleteInResourceGroup deletes the specified future of the resource in the given resource group, leaving the futures
+// of resources with the same name in other resource groups.
+func DeleteInResourceGroup(to Setter, name, service, resourceGroup string) {
+	if to == nil || name == "" || service == "" {
+		return
+	}
+
+	futures := to.GetFutures()
+	if i := indexInResourceGroup(futures, name, service, resourceGroup); i >= 0 {
+		futures = append(futures[:i], futures[i+1:]...)
+	}
+
+	to.SetFutures(futures)
+}
@@ -18,17 +18,30 @@ package futures
 
 import (
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 )
 
 func TestSet(t *testing.T) {
 	testService := "test-service"
+	startTime := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
 	a := fakeFuture("a", testService)
+	a.StartTime = &startTime
 	b := fakeFuture("b", testService)
+	b.StartTime = &startTime
 	newA := a
 	newA.Data = "new"
+	newAWithoutStartTime := newA
+	newAWithoutStartTime.StartTime = nil
+	deleteA := fakeFuture("a", testService)
+	deleteA.Type = "DELETE"
+	otherGroupA := a
+	otherGroupA.ResourceGroup = "other-rg"
+	legacyA := a
+	legacyA.ResourceGroup = ""
 
 	tests := []struct {
 		name   string
@@ -60,6 +73,30 @@ func TestSet(t *testing.T) {
 			future: &newA,
 			want:   infrav1.Futures{newA, b},
 		},
+		{
+			name:   "Set adds a future of a resource with the same name in another resource group",
+			to:     setterWithFutures(infrav1.Futures{a, b}),
+			future: &otherGroupA,
+			want:   infrav1.Futures{a, b, otherGroupA},
+		},
+		{
+			name:   "Set migrates a legacy future stored without a resource group",
+			to:     setterWithFutures(infrav1.Futures{legacyA, b}),
+			future: &otherGroupA,
+			want:   infrav1.Futures{otherGroupA, b},
+		},
+		{
+			name:   "Set prefers the future in the same resource group over a legacy future",
+			to:     setterWithFutures(infrav1.Futures{legacyA, otherGroupA}),
+			future: &otherGroupA,
+			want:   infrav1.Futures{legacyA, otherGroupA},
+		},
+		{
+			name:   "Set keeps the start time when updating a future of the same type",
+			to:     setterWithFutures(infrav1.Futures{a, b}),
+			future: &newAWithoutStartTime,
+			want:   infrav1.Futures{newA, b},
+		},
 	}
 
 	for _, tt := range tests {
@@ -71,6 +108,32 @@ func TestSet(t *testing.T) {
 			g.Expect(tt.to.GetFutures()).To(Equal(tt.want))
 		})
 	}
+
+	t.Run("Set sets the start time of a new future", func(t *testing.T) {
+		g := NewWithT(t)
+		to := setterWithFutures(infrav1.Futures{b})
+		c := fakeFuture("c", testService)
+
+		Set(to, &c)
+
+		got := to.GetFutures()
+		g.Expect(got).To(HaveLen(2))
+		g.Expect(got[1].StartTime).NotTo(BeNil())
+		g.Expect(got[1].StartTime.Time).To(BeTemporally("~", time.Now(), time.Minute))
+		g.Expect(c.StartTime).To(BeNil())
+	})
+
+	t.Run("Set resets the start time when the future type changes", func(t *testing.T) {
+		g := NewWithT(t)
+		to := setterWithFutures(infrav1.Futures{a, b})
+
+		Set(to, &deleteA)
+
+		got := to.GetFutures()
+		g.Expect(got[0].Type).To(Equal("DELETE"))
+		g.Expect(got[0].StartTime).NotTo(BeNil())
+		g.Expect(got[0].StartTime.Time).To(BeTemporally(">", startTime.Time))
+	})
 }
 
 func TestDelete(t *testing.T) {
@@ -111,6 +174,32 @@ func TestDelete(t *testing.T) {
 	}
 }
 
+func TestDeleteInResourceGroup(t *testing.T) {
+	g := NewWithT(t)
+	testService := "test-service"
+	a := fakeFuture("a", testService)
+	otherGroupA := fakeFuture("a", testService)
+	otherGroupA.ResourceGroup = "other-rg"
+	to := setterWithFutures(infrav1.Futures{a, otherGroupA})
+
+	DeleteInResourceGroup(to, "a", testService, "other-rg")
+	g.Expect(to.GetFutures()).To(Equal(infrav1.Futures{a}))
+
+	DeleteInResourceGroup(to, "a", testService, "other-rg")
+	g.Expect(to.GetFutures()).To(Equal(infrav1.Futures{a}))
+
+	// A legacy future stored without a resource group is only deleted if there is no future in the resource group.
+	legacyA := fakeFuture("a", testService)
+	legacyA.ResourceGroup = ""
+	to = setterWithFutures(infrav1.Futures{legacyA, otherGroupA})
+
+	DeleteInResourceGroup(to, "a", testService, "other-rg")
+	g.Expect(to.GetFutures()).To(Equal(infrav1.Futures{legacyA}))
+
+	DeleteInResourceGroup(to, "a", testService, "other-rg")
+	g.Expect(to.GetFutures()).To(BeEmpty())
+}
+
 func setterWithFutures(futures infrav1.Futures) Setter {
 	obj := &infrav1.AzureCluster{}
 	obj.SetFutures(futures)
@@ -41,3 +41,22 @@ func DefaultedLoopTimeout(timeout time.Duration) time.Duration {
 
 	return timeout
 }
+
+// ReconcileTimeouter is implemented by service reconcilers that override DefaultAzureServiceReconcileTimeout, e.g.
+// because their operations legitimately take longer.
+type ReconcileTimeouter interface {
+	// ReconcileTimeout returns the timeout of the service reconcile, or zero to use the default.
+	ReconcileTimeout() time.Duration
+}
+
+// ServiceReconcileTimeout returns the reconcile timeout of the service reconciler r if it overrides it, and
+// DefaultAzureServiceReconcileTimeout otherwise.
+func ServiceReconcileTimeout(r interface{}) time.Duration {
+	if t, ok := r.(ReconcileTimeouter); ok {
+		if timeout := t.ReconcileTimeout(); timeout > 0 {
+			return timeout
+		}
+	}
+
+	return DefaultAzureServiceReconcileTimeout
+}
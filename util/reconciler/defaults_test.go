@@ -56,3 +56,47 @@ func TestDefaultedTimeout(t *testing.T) {
 		})
 	}
 }
+
+type fakeTimeouter time.Duration
+
+func (f fakeTimeouter) ReconcileTimeout() time.Duration {
+	return time.Duration(f)
+}
+
+func TestServiceReconcileTimeout(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Subject  interface{}
+		Expected time.Duration
+	}{
+		{
+			Name:     "WithoutOverrideDefaults",
+			Subject:  struct{}{},
+			Expected: reconciler.DefaultAzureServiceReconcileTimeout,
+		},
+		{
+			Name:     "WithNilDefaults",
+			Subject:  nil,
+			Expected: reconciler.DefaultAzureServiceReconcileTimeout,
+		},
+		{
+			Name:     "WithZeroValueDefaults",
+			Subject:  fakeTimeouter(0),
+			Expected: reconciler.DefaultAzureServiceReconcileTimeout,
+		},
+		{
+			Name:     "WithRealValue",
+			Subject:  fakeTimeouter(30 * time.Minute),
+			Expected: 30 * time.Minute,
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.Name, func(t *testing.T) {
+			t.Parallel()
+			g := gomega.NewWithT(t)
+			g.Expect(reconciler.ServiceReconcileTimeout(c.Subject)).To(gomega.Equal(c.Expected))
+		})
+	}
+}
@@ -18,9 +18,13 @@ package tele
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/go-logr/logr"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 // CorrIDKey is the type of the key used to store correlation
@@ -81,6 +85,25 @@ func CorrIDFromCtx(ctx context.Context) (CorrID, bool) {
 	return CorrID(""), false
 }
 
+// CorrIDFromObject returns a correlation ID derived from the UID and
+// generation of an object, so that all the requests made to reconcile
+// the same generation of the object carry the same correlation ID.
+func CorrIDFromObject(uid types.UID, generation int64) CorrID {
+	name := fmt.Sprintf("%s/%d", uid, generation)
+	return CorrID(uuid.NewSHA1(uuid.NameSpaceOID, []byte(name)).String())
+}
+
+// WithCorrID returns a new context.Context with corrID in it, replacing
+// the correlation ID of ctx if there was one. The correlation ID is also
+// recorded on the span of ctx so that it is visible in traces.
+func WithCorrID(ctx context.Context, corrID CorrID) context.Context {
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String(
+		string(CorrIDKeyVal),
+		string(corrID),
+	))
+	return context.WithValue(ctx, CorrIDKeyVal, corrID)
+}
+
 // corrIDLogger attempts to fetch the correlation ID from the
 // given ctx using CorrIDFromCtx. If it finds one, this function
 // uses lggr.WithValues to return a new logr.Logger with the